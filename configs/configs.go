@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -21,7 +22,10 @@ type GEConfig struct {
 
 	Cloudinary CloudinaryConfig
 	Midtrans   MidtransConfig
-	OpenAi     OpenAi
+	LLM        LLMConfig
+	Chatbot    ChatbotConfig
+	Knowledge  KnowledgeConfig
+	Proof      ProofVerificationConfig
 	Google     Google
 	SMTP       SMTPConfig
 }
@@ -43,8 +47,49 @@ type Google struct {
 	CallbackURL string
 }
 
-type OpenAi struct {
-	ApiKey string
+// LLMConfig selects the chat model. BaseURL points the openai provider at
+// an OpenAI compatible server, such as a local model runner.
+// EmbeddingProvider is openai or local; it defaults to local with the fake
+// chat provider and to openai otherwise.
+type LLMConfig struct {
+	Provider          string
+	BaseURL           string
+	ApiKey            string
+	Model             string
+	EmbeddingProvider string
+	EmbeddingModel    string
+	Temperature       float32
+	MaxTokens         int
+	Timeout           time.Duration
+	MaxRetries        int
+	BreakerThreshold  int
+	BreakerCooldown   time.Duration
+}
+
+// ChatbotConfig limits what the chatbot sends to the model. ContextTokens is
+// the prompt budget of one request; older turns beyond it are summarized.
+// The daily quotas apply per user; zero turns a limit off.
+type ChatbotConfig struct {
+	ContextTokens     int
+	DailyMessageQuota int
+	DailyTokenQuota   int
+}
+
+// KnowledgeConfig tunes retrieval from the knowledge base: how many passages
+// a question gets at most and how similar they must be to it, from 0 to 1.
+type KnowledgeConfig struct {
+	Results  int
+	MinScore float64
+}
+
+// ProofVerificationConfig turns on checking challenge proof photos with a
+// vision model. Proofs the model is at least AutoApproveConfidence sure of,
+// from 0 to 1, are approved; the rest wait for an admin. Model defaults to
+// the chat model.
+type ProofVerificationConfig struct {
+	Enabled               bool
+	Model                 string
+	AutoApproveConfidence float64
 }
 
 type SMTPConfig struct {
@@ -80,7 +125,39 @@ func InitConfig() *GEConfig {
 	res.Midtrans.ClientKey = os.Getenv("MIDTRANS_CLIENT_KEY")
 	res.Midtrans.ServerKey = os.Getenv("MIDTRANS_SERVER_KEY")
 
-	res.OpenAi.ApiKey = os.Getenv("OPENAI_API_KEY")
+	res.LLM.Provider = os.Getenv("LLM_PROVIDER")
+	res.LLM.BaseURL = os.Getenv("LLM_BASE_URL")
+	res.LLM.ApiKey = os.Getenv("LLM_API_KEY")
+	if res.LLM.ApiKey == "" {
+		res.LLM.ApiKey = os.Getenv("OPENAI_API_KEY")
+	}
+	res.LLM.Model = os.Getenv("LLM_MODEL")
+	if res.LLM.Model == "" {
+		res.LLM.Model = "gpt-4"
+	}
+	temperature, _ := strconv.ParseFloat(os.Getenv("LLM_TEMPERATURE"), 32)
+	res.LLM.Temperature = float32(temperature)
+	res.LLM.MaxTokens, _ = strconv.Atoi(os.Getenv("LLM_MAX_TOKENS"))
+	res.LLM.EmbeddingProvider = os.Getenv("LLM_EMBEDDING_PROVIDER")
+	res.LLM.EmbeddingModel = os.Getenv("LLM_EMBEDDING_MODEL")
+	if res.LLM.EmbeddingModel == "" {
+		res.LLM.EmbeddingModel = "text-embedding-3-small"
+	}
+	res.LLM.Timeout = durationEnv("LLM_TIMEOUT", 60*time.Second)
+	res.LLM.MaxRetries = intEnv("LLM_MAX_RETRIES", 2)
+	res.LLM.BreakerThreshold = intEnv("LLM_BREAKER_THRESHOLD", 5)
+	res.LLM.BreakerCooldown = durationEnv("LLM_BREAKER_COOLDOWN", 30*time.Second)
+
+	res.Chatbot.ContextTokens = intEnv("CHATBOT_CONTEXT_TOKENS", 6000)
+	res.Chatbot.DailyMessageQuota = intEnv("CHATBOT_DAILY_MESSAGES", 100)
+	res.Chatbot.DailyTokenQuota = intEnv("CHATBOT_DAILY_TOKENS", 200000)
+
+	res.Knowledge.Results = intEnv("KNOWLEDGE_RESULTS", 3)
+	res.Knowledge.MinScore = floatEnv("KNOWLEDGE_MIN_SCORE", 0.3)
+
+	res.Proof.Enabled, _ = strconv.ParseBool(os.Getenv("PROOF_VERIFICATION_ENABLED"))
+	res.Proof.Model = os.Getenv("PROOF_VERIFICATION_MODEL")
+	res.Proof.AutoApproveConfidence = floatEnv("PROOF_AUTO_APPROVE_CONFIDENCE", 0.85)
 
 	res.Google.ClientID = os.Getenv("GOOGLE_CLIENT_ID")
 	res.Google.ClientKey = os.Getenv("GOOGLE_CLIENT_SECRET")
@@ -93,3 +170,28 @@ func InitConfig() *GEConfig {
 
 	return res
 }
+
+func intEnv(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func floatEnv(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// durationEnv reads a duration such as 90s or 2m.
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
@@ -15,6 +15,8 @@ var ErrInvalidInput = errors.New("Invalid input")
 // Users Errors
 var ErrInvalidUsername = errors.New("Username formating not valid")
 var ErrInvalidPhone = errors.New("Phone formating not valid")
+var ErrInvalidTimezone = errors.New("Timezone not valid")
+var ErrInvalidLanguage = errors.New("Language not supported")
 var ErrEmptyLogin = errors.New("Email or Password cannot be empty")
 var UserNotFound = errors.New("User not found")
 var ErrLoginIncorrectPassword = errors.New("Incorrect password")
@@ -69,6 +71,15 @@ var ErrDeleteProduct = errors.New("failed to delete product")
 var ErrImpactCategoryNotFound = errors.New("failed to get impact category")
 var ErrCreateImpactCategory = errors.New("failed to create impact category")
 var ErrDeleteImpactCategory = errors.New("failed to delete impact category")
+var ErrUpdateImpactCategory = errors.New("failed to update impact category")
+var ErrImpactCategoryInUse = errors.New("impact category is still used by products, challenges or child categories")
+var ErrInvalidImpactCategoryParent = errors.New("impact category parent must be another existing category that is not one of its descendants")
+var ErrInvalidImpactCategoryColor = errors.New("impact category color must be a hex color such as #2E7D32")
+var ErrInvalidReassignTarget = errors.New("impact category can only be reassigned to another existing category")
+var ErrGetUserImpact = errors.New("failed to get user impact")
+var ErrGetConversionFactor = errors.New("failed to get impact conversion factors")
+var ErrUpdateConversionFactor = errors.New("failed to update impact conversion factors")
+var ErrInvalidConversionFactor = errors.New("conversion factors must be co2_kg, plastic_kg or water_liters with a value of at least 0")
 
 var ErrFieldType = errors.New("field type error")
 
@@ -106,5 +117,117 @@ var ErrUpdateChallengeLog = errors.New("Failed to update challenge log")
 var ErrChallengeTaskNotFound = errors.New("Challenge Task not found")
 var ErrRewardAlreadyClaimed = errors.New("Rewards already claimed")
 var ErrChallengeAlreadyTaken = errors.New("challenge already taken by user")
+var ErrChallengeNotCompleted = errors.New("Challenge is not completed yet")
+var ErrChallengeConfirmationAlreadySubmitted = errors.New("challenge task already submitted")
+var ErrChallengeTaskDeadlinePassed = errors.New("challenge task deadline has passed")
+var ErrChallengeConfirmationNotPending = errors.New("challenge confirmation is not pending review")
+var ErrInvalidReviewAction = errors.New("review action must be approve or reject")
+var ErrRejectionReasonRequired = errors.New("rejection reason is required")
+var ErrChallengeNotAvailable = errors.New("Challenge is not open for enrollment")
+var ErrChallengeEnrollmentFull = errors.New("Challenge enrollment is full")
+var ErrInvalidChallengeSchedule = errors.New("Challenge end date must be after its start date")
+var ErrInvalidChallengeStatus = errors.New("Challenge status must be Draft or Published")
+var ErrChallengeEventEnded = errors.New("Challenge event has already ended")
+var ErrChallengeLocked = errors.New("Complete an easier challenge in the same impact category to unlock this challenge")
+
+var ErrChallengeSeasonNotFound = errors.New("Challenge season not found")
+var ErrCreateChallengeSeason = errors.New("Failed to create challenge season")
+var ErrUpdateChallengeSeason = errors.New("Failed to update challenge season")
+var ErrDeleteChallengeSeason = errors.New("Failed to delete challenge season")
+var ErrChallengeSeasonField = errors.New("Season name, start date and end date are required")
+var ErrInvalidBonusMultiplier = errors.New("Bonus multiplier must be at least 1")
 
 var ErrTransactionEmpty = errors.New("transaction is empty")
+
+// Team Error
+var ErrTeamNotFound = errors.New("Team not found")
+var ErrCreateTeam = errors.New("Failed to create team")
+var ErrGetTeam = errors.New("Failed to get team")
+var ErrDeleteTeam = errors.New("Failed to delete team")
+var ErrJoinTeam = errors.New("Failed to join team")
+var ErrLeaveTeam = errors.New("Failed to leave team")
+var ErrTeamField = errors.New("Team name cannot be empty")
+var ErrUserAlreadyInTeam = errors.New("User already belongs to a team")
+var ErrNotTeamMember = errors.New("User is not a member of this team")
+var ErrTeamFull = errors.New("Team has reached the maximum number of members")
+var ErrTeamLeaderCannotLeave = errors.New("Team leader cannot leave while other members remain")
+var ErrNotTeamLeader = errors.New("Only the team leader can perform this action")
+var ErrNotTeamChallenge = errors.New("Challenge is not available for teams")
+var ErrTeamChallengeAlreadyEnrolled = errors.New("Team already enrolled in this challenge")
+var ErrEnrollTeamChallenge = errors.New("Failed to enroll team challenge")
+var ErrUpdateTeamChallenge = errors.New("Failed to update team challenge")
+
+// Dashboard Error
+var ErrInvalidReportRange = errors.New("Report range must use YYYY-MM-DD dates with from before to and span at most 3 years")
+var ErrInvalidExportFormat = errors.New("Export format must be csv or pdf")
+var ErrGetImpactReport = errors.New("failed to get impact report")
+
+// Forum Error
+var ErrForumNotFound = errors.New("Forum not found")
+var ErrForumMessageNotFound = errors.New("Forum message not found")
+var ErrInvalidParentMessage = errors.New("Reply must point to a message in the same forum")
+var ErrInvalidForumSort = errors.New("Sort must be latest or most_liked")
+var ErrForumAlreadyLiked = errors.New("Already liked")
+var ErrForumLikeNotFound = errors.New("Like not found")
+var ErrForumLocked = errors.New("Forum is locked and no longer accepts replies")
+var ErrUserBanned = errors.New("You are banned from posting in the forum")
+var ErrContentBlocked = errors.New("Content contains words that are not allowed")
+var ErrContentSpam = errors.New("Content looks like spam")
+var ErrReportField = errors.New("Report needs a target type of forum or message, a target ID and a reason")
+var ErrReportExists = errors.New("You have already reported this content")
+var ErrReportNotFound = errors.New("Report not found")
+var ErrReportNotPending = errors.New("Report has already been handled")
+var ErrInvalidReportStatus = errors.New("Report status must be Pending, Resolved or Dismissed")
+var ErrInvalidModerationAction = errors.New("Moderation action is not valid for this target")
+var ErrBanNotFound = errors.New("Ban not found")
+var ErrBanField = errors.New("Ban needs a user ID, a reason and a duration of 0 (permanent) or more hours")
+var ErrBlockedWordField = errors.New("Blocked word cannot be empty")
+var ErrBlockedWordExists = errors.New("Blocked word already exists")
+var ErrBlockedWordNotFound = errors.New("Blocked word not found")
+var ErrForumCategoryNotFound = errors.New("Forum category not found")
+var ErrForumCategoryField = errors.New("Forum category needs a name and a slug of lowercase letters, numbers and dashes")
+var ErrForumCategoryExists = errors.New("Forum category with this slug already exists")
+var ErrInvalidForumTags = errors.New("Forum can have at most 5 tags of up to 30 letters, numbers or dashes")
+
+// Notification Error
+var ErrNotificationNotFound = errors.New("Notification not found")
+var ErrInvalidNotificationType = errors.New("Notification type must be transaction, forum_reply, forum_mention, challenge_deadline, reward, announcement, new_follower or account")
+var ErrAnnouncementField = errors.New("Announcement needs a title and a message")
+
+// Chatbot Error
+var ErrConversationNotFound = errors.New("Conversation not found")
+var ErrConversationTitle = errors.New("Conversation title must be between 1 and 100 characters")
+var ErrChatbotNoAnswer = errors.New("Chatbot did not return an answer")
+var ErrChatbotMessageQuota = errors.New("Daily chatbot message limit reached, try again tomorrow")
+var ErrChatbotTokenQuota = errors.New("Daily chatbot token limit reached, try again tomorrow")
+
+// Knowledge Error
+var ErrKnowledgeNotFound = errors.New("Knowledge document not found")
+var ErrKnowledgeField = errors.New("Knowledge document needs a title and some content")
+var ErrKnowledgeFormat = errors.New("Knowledge document must be a markdown (.md) or text (.txt) file")
+var ErrKnowledgeTooLarge = errors.New("Knowledge document cannot be larger than 1 MB")
+var ErrKnowledgeQuery = errors.New("Search query cannot be empty")
+
+// Article Error
+var ErrArticleNotFound = errors.New("Article not found")
+var ErrArticleField = errors.New("Article needs a title and some content")
+var ErrInvalidArticleStatus = errors.New("Article status must be Draft or Published")
+var ErrArticleImpactCategory = errors.New("Article impact category not found")
+var ErrEcoTipNotFound = errors.New("Eco tip not found")
+var ErrEcoTipField = errors.New("Eco tip must be between 1 and 500 characters")
+
+// Profile Error
+var ErrFollowSelf = errors.New("You cannot follow yourself")
+var ErrAlreadyFollowing = errors.New("Already following this user")
+var ErrNotFollowing = errors.New("Not following this user")
+var ErrFollowRequestPending = errors.New("Follow request already sent")
+var ErrFollowRequestNotFound = errors.New("Follow request not found")
+var ErrInvalidVisibility = errors.New("Visibility must be public, followers or private")
+
+// Account Error
+var ErrExportNotFound = errors.New("Data export not found")
+var ErrExportInProgress = errors.New("A data export is already being prepared")
+var ErrExportNotReady = errors.New("Data export is not ready yet")
+var ErrExportExpired = errors.New("Data export has expired, request a new one")
+var ErrDeletionNotFound = errors.New("No account deletion is scheduled")
+var ErrDeletionScheduled = errors.New("Account deletion is already scheduled")
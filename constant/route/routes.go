@@ -18,6 +18,19 @@ const UserProfile = UserPath + "/profile"
 const UserUpdateAvatar = UserPath + "/avatar"
 const UserRequestUpdateOTP = UserUpdate + "/request-otp"
 const UserUpdatePassword = UserUpdate + "/password"
+const UserPrivacy = UserPath + "/privacy"
+const UserFeed = UserPath + "/feed"
+const UserFollowRequests = UserPath + "/follow-requests"
+const UserFollowRequest = UserFollowRequests + "/:username"
+const UserDataExport = UserPath + "/data-export"
+const UserDataExportDownload = UserDataExport + "/:id/download"
+const UserDeletion = UserPath + "/deletion"
+
+const ProfilePath = BasePath + "/profiles"
+const ProfileByUsername = ProfilePath + "/:username"
+const ProfileFollowers = ProfileByUsername + "/followers"
+const ProfileFollowing = ProfileByUsername + "/following"
+const ProfileFollow = ProfileByUsername + "/follow"
 
 const AdminPath = BasePath + "/admin"
 const AdminLogin = AdminPath + "/login"
@@ -33,6 +46,8 @@ const ProductByID = ProductPath + "/:id"
 
 const ImpactCategoryPath = BasePath + "/impacts"
 const ImpactCategoryByID = ImpactCategoryPath + "/:id"
+const MyImpact = ImpactCategoryPath + "/me"
+const ImpactConversionFactor = ImpactCategoryByID + "/conversions"
 
 const CartPath = BasePath + "/cart"
 const CartByID = CartPath + "/:id"
@@ -45,6 +60,20 @@ const ReviewProductByID = ReviewProduct + "/products/:id"
 
 const ChatbotPath = BasePath + "/chatbots"
 const ChatbotPathByID = ChatbotPath + "/:chatID"
+const ChatbotStreamPath = ChatbotPath + "/stream"
+const ChatbotUsagePath = ChatbotPath + "/usage"
+
+const AdminKnowledgePath = AdminPath + "/knowledge"
+const AdminKnowledgeByID = AdminKnowledgePath + "/:id"
+const AdminKnowledgeSearch = AdminKnowledgePath + "/search"
+
+const ArticlePath = BasePath + "/articles"
+const ArticleBySlug = ArticlePath + "/:slug"
+const DailyTipPath = BasePath + "/tips/daily"
+const AdminArticlePath = AdminPath + "/articles"
+const AdminArticleByID = AdminArticlePath + "/:id"
+const AdminTipPath = AdminPath + "/tips"
+const AdminTipByID = AdminTipPath + "/:id"
 
 const ForumPath = BasePath + "/forums"
 const ForumByID = ForumPath + "/:id"
@@ -53,6 +82,23 @@ const GetForumByUserID = ForumPath + "/user"
 const ForumMessagePath = BasePath + "/message" + "/:id"
 const ForumMessage = ForumPath + "/message"
 const ForumMessageByID = ForumMessage + "/:id"
+const ForumLike = ForumByID + "/like"
+const ForumMessageLike = ForumMessageByID + "/like"
+const ForumReport = ForumPath + "/report"
+const AdminForumPath = AdminPath + "/forums"
+const AdminForumReport = AdminForumPath + "/reports"
+const AdminForumReportByID = AdminForumReport + "/:id"
+const AdminForumModeration = AdminForumPath + "/:id/moderation"
+const AdminForumMessageModeration = AdminForumPath + "/message/:id/moderation"
+const AdminForumBan = AdminForumPath + "/bans"
+const AdminForumBanByID = AdminForumBan + "/:id"
+const AdminForumBlockedWord = AdminForumPath + "/blocked-words"
+const AdminForumBlockedWordByID = AdminForumBlockedWord + "/:id"
+const AdminForumModerationLog = AdminForumPath + "/moderation-logs"
+const ForumCategory = ForumPath + "/categories"
+const ForumTags = ForumPath + "/tags"
+const AdminForumCategory = AdminForumPath + "/categories"
+const AdminForumCategoryByID = AdminForumCategory + "/:id"
 
 const ChallengePath = BasePath + "/challenges"
 const AdminChallengePath = AdminPath + "/challenges"
@@ -60,6 +106,13 @@ const AdminChallengeByID = AdminChallengePath + "/:id"
 const AdminChallengeTask = AdminChallengePath + "/tasks"
 const AdminChallengeTaskbyChallengeID = AdminChallengePath + "/:challenge_id/tasks"
 const AdminChallengeTaskByID = AdminChallengeTask + "/:task_id"
+const AdminChallengeConfirmation = AdminChallengePath + "/confirmations"
+const AdminPendingConfirmation = AdminChallengeConfirmation + "/pending"
+const AdminReviewConfirmation = AdminChallengeConfirmation + "/:id/review"
+const AdminPublishChallenge = AdminChallengeByID + "/publish"
+const AdminUnpublishChallenge = AdminChallengeByID + "/unpublish"
+const AdminChallengeSeason = AdminChallengePath + "/seasons"
+const AdminChallengeSeasonByID = AdminChallengeSeason + "/:id"
 const TakeChallenge = ChallengePath + "/logs"
 const TaskConfirmation = ChallengePath + "/confirmations"
 const TaskConfirmationProgress = TaskConfirmation + "/progress"
@@ -67,10 +120,30 @@ const TaskConfirmationProgress = TaskConfirmation + "/progress"
 const ClaimRewards = ChallengePath + "/rewards"
 const ActiveChallenge = ChallengePath + "/active"
 const UnclaimedChallenge = ChallengePath + "/unclaimed"
+const RecommendedChallenge = ChallengePath + "/recommendations"
 const UserChallengeDetails = ChallengePath + "/details"
 const UserUnclaimedChallengeDetails = ChallengePath + "/:challengeID/details"
 
 
 const AdminDashboard = AdminPath + "/dashboard"
-
-const LeaderboardPath = BasePath + "/leaderboard"
\ No newline at end of file
+const AdminImpactReport = AdminDashboard + "/impact"
+const AdminImpactReportExport = AdminImpactReport + "/export"
+
+const LeaderboardPath = BasePath + "/leaderboard"
+
+const TeamPath = BasePath + "/teams"
+const MyTeam = TeamPath + "/me"
+const TeamLeaderboard = TeamPath + "/leaderboard"
+const TeamByID = TeamPath + "/:id"
+const TeamJoin = TeamByID + "/join"
+const TeamLeave = TeamByID + "/leave"
+const TeamChallenges = TeamByID + "/challenges"
+
+const NotificationPath = BasePath + "/notifications"
+const NotificationUnreadCount = NotificationPath + "/unread-count"
+const NotificationReadAll = NotificationPath + "/read-all"
+const NotificationRead = NotificationPath + "/:id/read"
+const NotificationPreferences = NotificationPath + "/preferences"
+const AdminAnnouncement = AdminPath + "/notifications/announcements"
+
+const RealtimeStream = BasePath + "/realtime/stream"
@@ -9,7 +9,6 @@ const UserSuccessGetUser = "Get User Success"
 const UserSuccessForgotPassword = "Code has been sent to your email"
 const UserSuccessOTPValidation = "OTP Validation Success"
 const UserSuccessResetPassword = "Password has been changed"
-const UserSuccessDelete = "Account has been deleted"
 
 // Admin Success User
 const AdminSuccessGetUser = "Successfull Get User"
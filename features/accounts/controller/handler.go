@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"greenenvironment/constant"
+	"greenenvironment/features/accounts"
+	"greenenvironment/helper"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AccountHandler struct {
+	accountService accounts.AccountServiceInterface
+	jwt            helper.JWTInterface
+}
+
+func NewAccountController(s accounts.AccountServiceInterface, j helper.JWTInterface) accounts.AccountControllerInterface {
+	return &AccountHandler{
+		accountService: s,
+		jwt:            j,
+	}
+}
+
+// userID returns the ID of the user making the request, or false when the
+// request is not from a logged in user.
+func (h *AccountHandler) userID(c echo.Context) (string, bool) {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return "", false
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return "", false
+	}
+
+	userData := h.jwt.ExtractUserToken(token)
+	id, _ := userData[constant.JWT_ID].(string)
+	return id, id != "" && userData[constant.JWT_ROLE] != constant.RoleAdmin
+}
+
+// Request Data Export
+// @Summary      Request a copy of my data
+// @Description  Queue an export of everything stored about the caller. The ZIP of JSON files is built in the background; the caller is notified once it can be downloaded.
+// @Tags         Accounts
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Success      202  {object}  helper.Response{data=ExportResponse} "Data export requested successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      409  {object}  helper.Response{data=string} "A data export is already being prepared"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /users/data-export [post]
+func (h *AccountHandler) RequestExport(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	export, err := h.accountService.RequestExport(userID)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusAccepted, helper.FormatResponse(true, "data export requested successfully", new(ExportResponse).ToResponse(export)))
+}
+
+// Get Latest Data Export
+// @Summary      Get my latest data export
+// @Description  Get the status of the caller's most recent data export.
+// @Tags         Accounts
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Success      200  {object}  helper.Response{data=ExportResponse} "Data export retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Data export not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /users/data-export [get]
+func (h *AccountHandler) GetLatestExport(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	export, err := h.accountService.GetLatestExport(userID)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "data export retrieved successfully", new(ExportResponse).ToResponse(export)))
+}
+
+// Download Data Export
+// @Summary      Download my data export
+// @Description  Download a ready data export as a ZIP of JSON files, one per feature, with an export.json listing them.
+// @Tags         Accounts
+// @Produce      application/zip
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Data export ID"
+// @Success      200  {file}    file "Data export archive"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Data export not found"
+// @Failure      409  {object}  helper.Response{data=string} "Data export is not ready yet"
+// @Failure      410  {object}  helper.Response{data=string} "Data export has expired"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /users/data-export/{id}/download [get]
+func (h *AccountHandler) DownloadExport(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	export, err := h.accountService.DownloadExport(userID, c.Param("id"))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	filename := "greenenvironment-data-" + export.CreatedAt.Format("20060102") + ".zip"
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	return c.Blob(http.StatusOK, "application/zip", export.Archive)
+}
+
+// Request Account Deletion
+// @Summary      Delete my account
+// @Description  Schedule the caller's account for deletion after a 30 day grace period and email them about it. Until then the deletion can be cancelled. Orders are kept without personal details; everything else is removed.
+// @Tags         Accounts
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Success      202  {object}  helper.Response{data=DeletionResponse} "Account deletion scheduled successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "User not found"
+// @Failure      409  {object}  helper.Response{data=string} "Account deletion is already scheduled"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /users [delete]
+func (h *AccountHandler) RequestDeletion(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	deletion, err := h.accountService.RequestDeletion(userID)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusAccepted, helper.FormatResponse(true, "account deletion scheduled successfully", new(DeletionResponse).ToResponse(deletion)))
+}
+
+// Get Account Deletion
+// @Summary      Get my scheduled account deletion
+// @Description  Get when the caller's account is scheduled to be deleted.
+// @Tags         Accounts
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Success      200  {object}  helper.Response{data=DeletionResponse} "Account deletion retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "No account deletion is scheduled"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /users/deletion [get]
+func (h *AccountHandler) GetDeletion(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	deletion, err := h.accountService.GetDeletion(userID)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "account deletion retrieved successfully", new(DeletionResponse).ToResponse(deletion)))
+}
+
+// Cancel Account Deletion
+// @Summary      Cancel my account deletion
+// @Description  Cancel the scheduled deletion of the caller's account during the grace period.
+// @Tags         Accounts
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Success      200  {object}  helper.Response{data=string} "Account deletion cancelled successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "No account deletion is scheduled"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /users/deletion [delete]
+func (h *AccountHandler) CancelDeletion(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := h.accountService.CancelDeletion(userID); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "account deletion cancelled successfully", nil))
+}
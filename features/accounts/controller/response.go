@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"greenenvironment/features/accounts"
+	"time"
+)
+
+type ExportResponse struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	Size        int        `json:"size"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+func (er ExportResponse) ToResponse(export accounts.Export) ExportResponse {
+	return ExportResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		Size:        export.Size,
+		Error:       export.Error,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+}
+
+type DeletionResponse struct {
+	ID           string    `json:"id"`
+	Status       string    `json:"status"`
+	RequestedAt  time.Time `json:"requested_at"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+}
+
+func (dr DeletionResponse) ToResponse(deletion accounts.Deletion) DeletionResponse {
+	return DeletionResponse{
+		ID:           deletion.ID,
+		Status:       deletion.Status,
+		RequestedAt:  deletion.RequestedAt,
+		ScheduledFor: deletion.ScheduledFor,
+	}
+}
@@ -0,0 +1,123 @@
+package accounts
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Data export statuses. Exports are built in the background and downloaded
+// once they are ready.
+const (
+	ExportPending    = "Pending"
+	ExportProcessing = "Processing"
+	ExportReady      = "Ready"
+	ExportFailed     = "Failed"
+)
+
+// Account deletion statuses.
+const (
+	DeletionScheduled = "Scheduled"
+	DeletionCancelled = "Cancelled"
+	DeletionCompleted = "Completed"
+)
+
+// DeletionGracePeriod is how long a user can change their mind before their
+// account is purged.
+const DeletionGracePeriod = 30 * 24 * time.Hour
+
+// ExportRetention is how long a ready export can be downloaded before it is
+// removed.
+const ExportRetention = 7 * 24 * time.Hour
+
+// ExportBatchSize is how many pending exports one run builds.
+const ExportBatchSize = 5
+
+// ExportClaimTimeout is how long an export can stay in processing. Past it
+// the run that claimed it is assumed to have crashed and the export fails.
+const ExportClaimTimeout = 30 * time.Minute
+
+// Export is a "download my data" archive. Archive holds the ZIP once the
+// export is ready. ClaimedAt is when a run started building it.
+type Export struct {
+	ID          string
+	UserID      string
+	Status      string
+	Archive     []byte
+	Size        int
+	Error       string
+	CreatedAt   time.Time
+	ClaimedAt   *time.Time
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time
+}
+
+// ExportFile is one JSON file of an export, holding the rows a feature
+// stores about the user.
+type ExportFile struct {
+	Name string
+	Rows []map[string]interface{}
+}
+
+// Account is the part of a user the deletion workflow needs.
+type Account struct {
+	ID       string
+	Name     string
+	Username string
+	Email    string
+}
+
+// Deletion is a request to delete an account. The account is purged once
+// ScheduledFor passes, unless the user cancels first. Email is cleared when
+// the purge completes.
+type Deletion struct {
+	ID           string
+	UserID       string
+	Email        string
+	Status       string
+	RequestedAt  time.Time
+	ScheduledFor time.Time
+	CompletedAt  *time.Time
+	CancelledAt  *time.Time
+}
+
+type AccountControllerInterface interface {
+	RequestExport(c echo.Context) error
+	GetLatestExport(c echo.Context) error
+	DownloadExport(c echo.Context) error
+
+	RequestDeletion(c echo.Context) error
+	GetDeletion(c echo.Context) error
+	CancelDeletion(c echo.Context) error
+}
+
+type AccountServiceInterface interface {
+	RequestExport(userID string) (Export, error)
+	GetLatestExport(userID string) (Export, error)
+	DownloadExport(userID, exportID string) (Export, error)
+	ProcessPendingExports() error
+
+	RequestDeletion(userID string) (Deletion, error)
+	GetDeletion(userID string) (Deletion, error)
+	CancelDeletion(userID string) error
+	ExecuteDueDeletions() error
+}
+
+type AccountRepositoryInterface interface {
+	CreateExport(export Export) error
+	GetLatestExport(userID string) (Export, error)
+	GetExport(userID, exportID string) (Export, error)
+	GetPendingExports(limit int) ([]Export, error)
+	ClaimExport(exportID string, claimedAt time.Time) (bool, error)
+	GetStaleExports(claimedBefore time.Time) ([]Export, error)
+	SaveExport(export Export) error
+	DeleteExpiredExports(now time.Time) error
+	CollectUserData(userID string) ([]ExportFile, error)
+
+	GetAccount(userID string) (Account, error)
+	CreateDeletion(deletion Deletion) error
+	GetActiveDeletion(userID string) (Deletion, error)
+	CancelDeletion(deletionID string, cancelledAt time.Time) error
+	GetDueDeletions(now time.Time) ([]Deletion, error)
+	PurgeUser(deletion Deletion, completedAt time.Time) ([]string, error)
+}
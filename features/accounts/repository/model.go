@@ -0,0 +1,44 @@
+package repository
+
+import (
+	users "greenenvironment/features/users/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DataExport struct {
+	*gorm.Model
+	ID          string     `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	UserID      string     `gorm:"type:varchar(50);not null;index;column:user_id"`
+	Status      string     `gorm:"type:enum('Pending','Processing','Ready','Failed');not null;default:'Pending';index;column:status"`
+	Archive     []byte     `gorm:"type:longblob;column:archive"`
+	Size        int        `gorm:"type:int;not null;default:0;column:size"`
+	Error       string     `gorm:"type:text;column:error"`
+	ClaimedAt   *time.Time `gorm:"type:datetime;column:claimed_at"`
+	CompletedAt *time.Time `gorm:"type:datetime;column:completed_at"`
+	ExpiresAt   *time.Time `gorm:"type:datetime;index;column:expires_at"`
+	User        users.User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// AccountDeletion outlives the account it deletes as a record that the
+// deletion happened, so it has no foreign key.
+type AccountDeletion struct {
+	*gorm.Model
+	ID           string     `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	UserID       string     `gorm:"type:varchar(50);not null;index;column:user_id"`
+	Email        string     `gorm:"type:varchar(255);column:email"`
+	Status       string     `gorm:"type:enum('Scheduled','Cancelled','Completed');not null;default:'Scheduled';index:idx_account_deletion_due;column:status"`
+	RequestedAt  time.Time  `gorm:"type:datetime;not null;column:requested_at"`
+	ScheduledFor time.Time  `gorm:"type:datetime;not null;index:idx_account_deletion_due;column:scheduled_for"`
+	CompletedAt  *time.Time `gorm:"type:datetime;column:completed_at"`
+	CancelledAt  *time.Time `gorm:"type:datetime;column:cancelled_at"`
+}
+
+func (DataExport) TableName() string {
+	return "data_exports"
+}
+
+func (AccountDeletion) TableName() string {
+	return "account_deletions"
+}
@@ -0,0 +1,518 @@
+package repository
+
+import (
+	"errors"
+	"greenenvironment/constant"
+	"greenenvironment/features/accounts"
+	users "greenenvironment/features/users/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// exportSources lists where each file of an export comes from. @user is the
+// ID of the user and @email their email address.
+var exportSources = []struct {
+	name  string
+	query string
+}{
+	{"account.json", "SELECT id, username, name, email, address, gender, phone, exp, coin, avatar_url, is_membership, timezone, language, created_at, updated_at FROM users WHERE id = @user"},
+	{"transactions.json", "SELECT * FROM transactions WHERE user_id = @user"},
+	{"transaction_items.json", "SELECT transaction_items.* FROM transaction_items JOIN transactions ON transactions.id = transaction_items.transaction_id WHERE transactions.user_id = @user"},
+	{"cart.json", "SELECT * FROM carts WHERE user_id = @user"},
+	{"product_reviews.json", "SELECT * FROM review_products WHERE user_id = @user"},
+	{"product_views.json", "SELECT * FROM product_logs WHERE user_id = @user"},
+	{"forum_threads.json", "SELECT * FROM forums WHERE user_id = @user"},
+	{"forum_messages.json", "SELECT * FROM message_forums WHERE user_id = @user"},
+	{"forum_likes.json", "SELECT * FROM forum_likes WHERE user_id = @user"},
+	{"forum_mentions.json", "SELECT * FROM forum_mentions WHERE mentioned_user_id = @user OR mentioned_by_id = @user"},
+	{"forum_reports.json", "SELECT * FROM forum_reports WHERE reporter_id = @user"},
+	{"forum_views.json", "SELECT * FROM forum_views WHERE viewer_key = @user"},
+	{"forum_bans.json", "SELECT * FROM user_bans WHERE user_id = @user"},
+	{"challenges.json", "SELECT * FROM challenge_logs WHERE user_id = @user"},
+	{"challenge_confirmations.json", "SELECT * FROM challenge_confirmations WHERE user_id = @user"},
+	{"impact.json", "SELECT * FROM impact_ledgers WHERE user_id = @user"},
+	{"teams.json", "SELECT teams.id AS team_id, teams.name, team_members.role, team_members.joined_at FROM team_members JOIN teams ON teams.id = team_members.team_id WHERE team_members.user_id = @user AND team_members.deleted_at IS NULL"},
+	{"chatbot_conversations.json", "SELECT * FROM chatbot_conversations WHERE user_id = @user"},
+	{"chatbot_messages.json", "SELECT * FROM chatbots WHERE user_id = @user"},
+	{"chatbot_usage.json", "SELECT * FROM chatbot_usages WHERE user_id = @user"},
+	{"notifications.json", "SELECT * FROM notifications WHERE user_id = @user"},
+	{"notification_preferences.json", "SELECT * FROM notification_preferences WHERE user_id = @user"},
+	{"followers.json", "SELECT users.username, user_follows.created_at AS followed_at FROM user_follows JOIN users ON users.id = user_follows.follower_id WHERE user_follows.following_id = @user AND user_follows.deleted_at IS NULL"},
+	{"following.json", "SELECT users.username, user_follows.created_at AS followed_at FROM user_follows JOIN users ON users.id = user_follows.following_id WHERE user_follows.follower_id = @user AND user_follows.deleted_at IS NULL"},
+	{"privacy.json", "SELECT * FROM profile_privacies WHERE user_id = @user"},
+	{"emails.json", "SELECT id, template, language, subject, status, sent_at, created_at FROM email_outbox WHERE recipient = @email"},
+}
+
+// userRows lists the rows a purge removes outright, by the column holding
+// the user ID.
+var userRows = []struct {
+	table  string
+	column string
+}{
+	{"carts", "user_id"},
+	{"review_products", "user_id"},
+	{"product_logs", "user_id"},
+	{"forum_likes", "user_id"},
+	{"forum_mentions", "mentioned_user_id"},
+	{"forum_mentions", "mentioned_by_id"},
+	{"forum_reports", "reporter_id"},
+	{"forum_views", "viewer_key"},
+	{"user_bans", "user_id"},
+	{"challenge_confirmations", "user_id"},
+	{"challenge_logs", "user_id"},
+	{"team_members", "user_id"},
+	{"chatbots", "user_id"},
+	{"chatbot_conversations", "user_id"},
+	{"chatbot_usages", "user_id"},
+	{"notifications", "user_id"},
+	{"notification_preferences", "user_id"},
+	{"user_follows", "follower_id"},
+	{"user_follows", "following_id"},
+	{"profile_privacies", "user_id"},
+	{"data_exports", "user_id"},
+}
+
+// emailRows lists the rows a purge removes by the email address of the user.
+var emailRows = []struct {
+	table  string
+	column string
+}{
+	{"email_outbox", "recipient"},
+	{"verify_otps", "email"},
+	{"temporary_users", "email"},
+}
+
+// forumPurge removes forum threads and messages and everything attached to
+// them. @threads and @messages are the IDs of the threads and messages.
+var forumPurge = []string{
+	"DELETE FROM forum_likes WHERE (target_type = 'forum' AND target_id IN @threads) OR (target_type = 'message' AND target_id IN @messages)",
+	"DELETE FROM forum_reports WHERE (target_type = 'forum' AND target_id IN @threads) OR (target_type = 'message' AND target_id IN @messages)",
+	"DELETE FROM forum_mentions WHERE forum_id IN @threads OR message_id IN @messages",
+	"DELETE FROM forum_views WHERE forum_id IN @threads",
+	"DELETE FROM forum_tags WHERE forum_id IN @threads",
+	"DELETE FROM message_forums WHERE id IN @messages",
+	"DELETE FROM forums WHERE id IN @threads",
+}
+
+// userImages lists the columns holding the URLs of images the user
+// uploaded, by the column holding the user ID.
+var userImages = []struct {
+	table  string
+	column string
+	user   string
+}{
+	{"users", "avatar_url", "id"},
+	{"challenge_confirmations", "challenge_img", "user_id"},
+	{"forums", "topic_image", "user_id"},
+	{"message_forums", "message_image", "user_id"},
+}
+
+type AccountRepository struct {
+	DB *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) accounts.AccountRepositoryInterface {
+	return &AccountRepository{
+		DB: db,
+	}
+}
+
+func (ar *AccountRepository) CreateExport(export accounts.Export) error {
+	return ar.DB.Create(&DataExport{
+		ID:     export.ID,
+		UserID: export.UserID,
+		Status: export.Status,
+	}).Error
+}
+
+func (ar *AccountRepository) GetLatestExport(userID string) (accounts.Export, error) {
+	var export DataExport
+	err := ar.DB.Omit("archive").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		First(&export).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return accounts.Export{}, constant.ErrExportNotFound
+	}
+	if err != nil {
+		return accounts.Export{}, err
+	}
+	return toExport(export), nil
+}
+
+func (ar *AccountRepository) GetExport(userID, exportID string) (accounts.Export, error) {
+	var export DataExport
+	err := ar.DB.Where("id = ? AND user_id = ?", exportID, userID).First(&export).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return accounts.Export{}, constant.ErrExportNotFound
+	}
+	if err != nil {
+		return accounts.Export{}, err
+	}
+	return toExport(export), nil
+}
+
+func (ar *AccountRepository) GetPendingExports(limit int) ([]accounts.Export, error) {
+	var exports []DataExport
+	err := ar.DB.Omit("archive").
+		Where("status = ?", accounts.ExportPending).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&exports).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]accounts.Export, 0, len(exports))
+	for _, export := range exports {
+		result = append(result, toExport(export))
+	}
+	return result, nil
+}
+
+// ClaimExport moves a pending export to processing. It reports false when
+// another run claimed it first.
+func (ar *AccountRepository) ClaimExport(exportID string, claimedAt time.Time) (bool, error) {
+	result := ar.DB.Model(&DataExport{}).
+		Where("id = ? AND status = ?", exportID, accounts.ExportPending).
+		Updates(map[string]interface{}{
+			"status":     accounts.ExportProcessing,
+			"claimed_at": claimedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetStaleExports lists the exports still processing that were claimed
+// before the given time. Exports claimed before claims were timed have no
+// claim time and are always stale.
+func (ar *AccountRepository) GetStaleExports(claimedBefore time.Time) ([]accounts.Export, error) {
+	var exports []DataExport
+	err := ar.DB.Omit("archive").
+		Where("status = ? AND (claimed_at < ? OR claimed_at IS NULL)", accounts.ExportProcessing, claimedBefore).
+		Find(&exports).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]accounts.Export, 0, len(exports))
+	for _, export := range exports {
+		result = append(result, toExport(export))
+	}
+	return result, nil
+}
+
+func (ar *AccountRepository) SaveExport(export accounts.Export) error {
+	return ar.DB.Model(&DataExport{}).
+		Where("id = ?", export.ID).
+		Updates(map[string]interface{}{
+			"status":       export.Status,
+			"archive":      export.Archive,
+			"size":         export.Size,
+			"error":        export.Error,
+			"completed_at": export.CompletedAt,
+			"expires_at":   export.ExpiresAt,
+		}).Error
+}
+
+// DeleteExpiredExports removes the exports that can no longer be downloaded,
+// archive included.
+func (ar *AccountRepository) DeleteExpiredExports(now time.Time) error {
+	return ar.DB.Unscoped().Where("expires_at < ?", now).Delete(&DataExport{}).Error
+}
+
+// CollectUserData reads everything stored about a user, one file per source.
+// Sources without rows give an empty file so the export shows they were
+// looked at.
+func (ar *AccountRepository) CollectUserData(userID string) ([]accounts.ExportFile, error) {
+	account, err := ar.GetAccount(userID)
+	if err != nil {
+		return nil, err
+	}
+	args := map[string]interface{}{"user": account.ID, "email": account.Email}
+
+	files := make([]accounts.ExportFile, 0, len(exportSources))
+	for _, source := range exportSources {
+		rows := []map[string]interface{}{}
+		if err := ar.DB.Raw(source.query, args).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		files = append(files, accounts.ExportFile{Name: source.name, Rows: rows})
+	}
+	return files, nil
+}
+
+func (ar *AccountRepository) GetAccount(userID string) (accounts.Account, error) {
+	var user users.User
+	err := ar.DB.Select("id", "name", "username", "email").
+		Where("id = ?", userID).
+		First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return accounts.Account{}, constant.UserNotFound
+	}
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	return accounts.Account{
+		ID:       user.ID,
+		Name:     user.Name,
+		Username: user.Username,
+		Email:    user.Email,
+	}, nil
+}
+
+func (ar *AccountRepository) CreateDeletion(deletion accounts.Deletion) error {
+	return ar.DB.Create(&AccountDeletion{
+		ID:           deletion.ID,
+		UserID:       deletion.UserID,
+		Email:        deletion.Email,
+		Status:       deletion.Status,
+		RequestedAt:  deletion.RequestedAt,
+		ScheduledFor: deletion.ScheduledFor,
+	}).Error
+}
+
+func (ar *AccountRepository) GetActiveDeletion(userID string) (accounts.Deletion, error) {
+	var deletion AccountDeletion
+	err := ar.DB.Where("user_id = ? AND status = ?", userID, accounts.DeletionScheduled).First(&deletion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return accounts.Deletion{}, constant.ErrDeletionNotFound
+	}
+	if err != nil {
+		return accounts.Deletion{}, err
+	}
+	return toDeletion(deletion), nil
+}
+
+func (ar *AccountRepository) CancelDeletion(deletionID string, cancelledAt time.Time) error {
+	result := ar.DB.Model(&AccountDeletion{}).
+		Where("id = ? AND status = ?", deletionID, accounts.DeletionScheduled).
+		Updates(map[string]interface{}{
+			"status":       accounts.DeletionCancelled,
+			"cancelled_at": cancelledAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrDeletionNotFound
+	}
+	return nil
+}
+
+func (ar *AccountRepository) GetDueDeletions(now time.Time) ([]accounts.Deletion, error) {
+	var deletions []AccountDeletion
+	err := ar.DB.Where("status = ? AND scheduled_for <= ?", accounts.DeletionScheduled, now).
+		Order("scheduled_for ASC").
+		Find(&deletions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]accounts.Deletion, 0, len(deletions))
+	for _, deletion := range deletions {
+		result = append(result, toDeletion(deletion))
+	}
+	return result, nil
+}
+
+// PurgeUser deletes an account in one transaction and returns the URLs of
+// the images the user uploaded, which are stored outside the database.
+// Orders and the impact ledger are kept for the books and the platform
+// totals, and forum threads other users replied to are kept for the sake of
+// the replies, but the user row they point to is stripped of everything that
+// identifies the user and shows as "Deleted user". The rest of the user's
+// data is removed.
+func (ar *AccountRepository) PurgeUser(deletion accounts.Deletion, completedAt time.Time) ([]string, error) {
+	var images []string
+	err := ar.DB.Transaction(func(tx *gorm.DB) error {
+		var user users.User
+		err := tx.Unscoped().Select("id", "email").Where("id = ?", deletion.UserID).First(&user).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		images = nil
+		for _, source := range userImages {
+			var urls []string
+			err := tx.Table(source.table).Where(source.user+" = ? AND "+source.column+" <> ''", deletion.UserID).Pluck(source.column, &urls).Error
+			if err != nil {
+				return err
+			}
+			images = append(images, urls...)
+		}
+
+		if err := handOverTeams(tx, deletion.UserID); err != nil {
+			return err
+		}
+
+		if err := purgeForum(tx, deletion.UserID); err != nil {
+			return err
+		}
+
+		for _, row := range userRows {
+			if err := tx.Exec("DELETE FROM "+row.table+" WHERE "+row.column+" = ?", deletion.UserID).Error; err != nil {
+				return err
+			}
+		}
+
+		// The user may have changed their email since asking for the deletion.
+		emails := []string{deletion.Email}
+		if user.Email != deletion.Email {
+			emails = append(emails, user.Email)
+		}
+		for _, email := range emails {
+			if email == "" {
+				continue
+			}
+			for _, row := range emailRows {
+				if err := tx.Exec("DELETE FROM "+row.table+" WHERE "+row.column+" = ?", email).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		err = tx.Exec("UPDATE transactions SET address = '' WHERE user_id = ?", deletion.UserID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Model(&users.User{}).
+			Where("id = ?", deletion.UserID).
+			Updates(map[string]interface{}{
+				"username":      "deleted-" + deletion.UserID,
+				"name":          "Deleted user",
+				"email":         deletion.UserID + "@deleted.invalid",
+				"password":      "",
+				"address":       "",
+				"gender":        "",
+				"phone":         "",
+				"avatar_url":    "",
+				"exp":           0,
+				"coin":          0,
+				"is_membership": false,
+				"deleted_at":    completedAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&AccountDeletion{}).
+			Where("id = ?", deletion.ID).
+			Updates(map[string]interface{}{
+				"status":       accounts.DeletionCompleted,
+				"email":        "",
+				"completed_at": completedAt,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// purgeForum removes the forum messages of the user and the threads nobody
+// else replied to. Threads with replies of other users stay with the
+// anonymized user row, without their image.
+func purgeForum(tx *gorm.DB, userID string) error {
+	var threads []string
+	err := tx.Table("forums").
+		Where("user_id = ?", userID).
+		Where("NOT EXISTS (SELECT 1 FROM message_forums WHERE message_forums.forum_id = forums.id AND message_forums.user_id <> ?)", userID).
+		Pluck("id", &threads).Error
+	if err != nil {
+		return err
+	}
+
+	var messages []string
+	err = tx.Table("message_forums").Where("user_id = ?", userID).Pluck("id", &messages).Error
+	if err != nil {
+		return err
+	}
+
+	args := map[string]interface{}{"threads": threads, "messages": messages}
+	for _, query := range forumPurge {
+		if err := tx.Exec(query, args).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Exec("UPDATE forums SET topic_image = '' WHERE user_id = ?", userID).Error
+}
+
+// handOverTeams passes each team the user leads to its longest standing
+// member. Teams without other members are deleted.
+func handOverTeams(tx *gorm.DB, userID string) error {
+	var teamIDs []string
+	if err := tx.Table("teams").Where("leader_id = ?", userID).Pluck("id", &teamIDs).Error; err != nil {
+		return err
+	}
+
+	for _, teamID := range teamIDs {
+		var successors []string
+		err := tx.Table("team_members").
+			Where("team_id = ? AND user_id <> ? AND deleted_at IS NULL", teamID, userID).
+			Order("joined_at ASC").
+			Limit(1).
+			Pluck("user_id", &successors).Error
+		if err != nil {
+			return err
+		}
+
+		if len(successors) == 0 {
+			for _, query := range []string{
+				"DELETE FROM team_challenges WHERE team_id = ?",
+				"DELETE FROM team_members WHERE team_id = ?",
+				"DELETE FROM teams WHERE id = ?",
+			} {
+				if err := tx.Exec(query, teamID).Error; err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := tx.Exec("UPDATE teams SET leader_id = ? WHERE id = ?", successors[0], teamID).Error; err != nil {
+			return err
+		}
+		err = tx.Exec("UPDATE team_members SET role = 'Leader' WHERE team_id = ? AND user_id = ? AND deleted_at IS NULL", teamID, successors[0]).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toExport(export DataExport) accounts.Export {
+	result := accounts.Export{
+		ID:          export.ID,
+		UserID:      export.UserID,
+		Status:      export.Status,
+		Archive:     export.Archive,
+		Size:        export.Size,
+		Error:       export.Error,
+		ClaimedAt:   export.ClaimedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+	if export.Model != nil {
+		result.CreatedAt = export.CreatedAt
+	}
+	return result
+}
+
+func toDeletion(deletion AccountDeletion) accounts.Deletion {
+	return accounts.Deletion{
+		ID:           deletion.ID,
+		UserID:       deletion.UserID,
+		Email:        deletion.Email,
+		Status:       deletion.Status,
+		RequestedAt:  deletion.RequestedAt,
+		ScheduledFor: deletion.ScheduledFor,
+		CompletedAt:  deletion.CompletedAt,
+		CancelledAt:  deletion.CancelledAt,
+	}
+}
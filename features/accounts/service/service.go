@@ -0,0 +1,314 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"greenenvironment/constant"
+	"greenenvironment/features/accounts"
+	"greenenvironment/features/notifications"
+	"greenenvironment/helper"
+	"greenenvironment/utils/storages"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// exportManifest is the export.json at the root of every archive.
+type exportManifest struct {
+	UserID      string         `json:"user_id"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Files       []manifestFile `json:"files"`
+}
+
+type manifestFile struct {
+	Name string `json:"name"`
+	Rows int    `json:"rows"`
+}
+
+type AccountService struct {
+	accountRepo accounts.AccountRepositoryInterface
+	mailer      helper.MailerInterface
+	notifier    notifications.Notifier
+	storage     storages.StorageInterface
+	now         func() time.Time
+}
+
+func NewAccountService(ar accounts.AccountRepositoryInterface, mailer helper.MailerInterface, notifier notifications.Notifier, storage storages.StorageInterface) accounts.AccountServiceInterface {
+	return &AccountService{
+		accountRepo: ar,
+		mailer:      mailer,
+		notifier:    notifier,
+		storage:     storage,
+		now:         time.Now,
+	}
+}
+
+// RequestExport queues a new export. The archive is built in the background
+// by ProcessPendingExports.
+func (as *AccountService) RequestExport(userID string) (accounts.Export, error) {
+	latest, err := as.accountRepo.GetLatestExport(userID)
+	if err != nil && !errors.Is(err, constant.ErrExportNotFound) {
+		return accounts.Export{}, err
+	}
+	if err == nil && (latest.Status == accounts.ExportPending || latest.Status == accounts.ExportProcessing) {
+		return accounts.Export{}, constant.ErrExportInProgress
+	}
+
+	export := accounts.Export{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Status:    accounts.ExportPending,
+		CreatedAt: as.now(),
+	}
+	if err := as.accountRepo.CreateExport(export); err != nil {
+		return accounts.Export{}, err
+	}
+	return export, nil
+}
+
+func (as *AccountService) GetLatestExport(userID string) (accounts.Export, error) {
+	return as.accountRepo.GetLatestExport(userID)
+}
+
+func (as *AccountService) DownloadExport(userID, exportID string) (accounts.Export, error) {
+	export, err := as.accountRepo.GetExport(userID, exportID)
+	if err != nil {
+		return accounts.Export{}, err
+	}
+	if export.Status != accounts.ExportReady {
+		return accounts.Export{}, constant.ErrExportNotReady
+	}
+	if export.ExpiresAt != nil && as.now().After(*export.ExpiresAt) {
+		return accounts.Export{}, constant.ErrExportExpired
+	}
+	return export, nil
+}
+
+// ProcessPendingExports removes the expired exports, fails the ones left
+// processing by a crashed run and builds the next batch of pending ones.
+// Each export is claimed first so two instances never build the same one.
+func (as *AccountService) ProcessPendingExports() error {
+	if err := as.accountRepo.DeleteExpiredExports(as.now()); err != nil {
+		return err
+	}
+
+	stale, err := as.accountRepo.GetStaleExports(as.now().Add(-accounts.ExportClaimTimeout))
+	if err != nil {
+		return err
+	}
+	for _, export := range stale {
+		as.failStaleExport(export)
+	}
+
+	pending, err := as.accountRepo.GetPendingExports(accounts.ExportBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range pending {
+		claimed, err := as.accountRepo.ClaimExport(export.ID, as.now())
+		if err != nil {
+			log.Printf("failed to claim data export %s: %v", export.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		as.buildExport(export)
+	}
+	return nil
+}
+
+func (as *AccountService) buildExport(export accounts.Export) {
+	now := as.now()
+	expiresAt := now.Add(accounts.ExportRetention)
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+
+	archive, err := as.archive(export.UserID, now)
+	if err != nil {
+		log.Printf("failed to build data export %s: %v", export.ID, err)
+		export.Status = accounts.ExportFailed
+		export.Error = err.Error()
+	} else {
+		export.Status = accounts.ExportReady
+		export.Archive = archive
+		export.Size = len(archive)
+	}
+
+	as.finishExport(export)
+}
+
+// failStaleExport fails an export whose build never finished rather than
+// building it again, so an export that crashes the run cannot do so on
+// every run. The user can request a new one.
+func (as *AccountService) failStaleExport(export accounts.Export) {
+	now := as.now()
+	expiresAt := now.Add(accounts.ExportRetention)
+	export.Status = accounts.ExportFailed
+	export.Error = "the export took too long to build"
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+	as.finishExport(export)
+}
+
+// finishExport saves a built or failed export and tells the user about it.
+func (as *AccountService) finishExport(export accounts.Export) {
+	if err := as.accountRepo.SaveExport(export); err != nil {
+		log.Printf("failed to save data export %s: %v", export.ID, err)
+		return
+	}
+
+	notification := notifications.Notification{
+		UserID:  export.UserID,
+		Type:    notifications.TypeAccount,
+		Title:   "Your data export is ready",
+		Message: fmt.Sprintf("Your data export can be downloaded until %s.", export.ExpiresAt.Format("2 January 2006")),
+		Link:    "/users/data-export",
+	}
+	if export.Status == accounts.ExportFailed {
+		notification.Title = "Your data export failed"
+		notification.Message = "We could not prepare your data export. Please request a new one."
+	}
+	if err := as.notifier.Notify(notification); err != nil {
+		log.Printf("failed to notify %s about data export %s: %v", export.UserID, export.ID, err)
+	}
+}
+
+// archive zips everything stored about a user as one JSON file per source,
+// listed in export.json.
+func (as *AccountService) archive(userID string, generatedAt time.Time) ([]byte, error) {
+	files, err := as.accountRepo.CollectUserData(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := exportManifest{
+		UserID:      userID,
+		GeneratedAt: generatedAt,
+		Files:       make([]manifestFile, 0, len(files)),
+	}
+	for _, file := range files {
+		manifest.Files = append(manifest.Files, manifestFile{Name: file.Name, Rows: len(file.Rows)})
+	}
+
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	write := func(name string, content interface{}) error {
+		data, err := json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			return err
+		}
+		file, err := writer.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: generatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(data)
+		return err
+	}
+
+	if err := write("export.json", manifest); err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if err := write(file.Name, file.Rows); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// RequestDeletion schedules the account for deletion after the grace
+// period and lets the user know by email.
+func (as *AccountService) RequestDeletion(userID string) (accounts.Deletion, error) {
+	account, err := as.accountRepo.GetAccount(userID)
+	if err != nil {
+		return accounts.Deletion{}, err
+	}
+
+	_, err = as.accountRepo.GetActiveDeletion(userID)
+	if err == nil {
+		return accounts.Deletion{}, constant.ErrDeletionScheduled
+	}
+	if !errors.Is(err, constant.ErrDeletionNotFound) {
+		return accounts.Deletion{}, err
+	}
+
+	now := as.now()
+	deletion := accounts.Deletion{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		Email:        account.Email,
+		Status:       accounts.DeletionScheduled,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(accounts.DeletionGracePeriod),
+	}
+	if err := as.accountRepo.CreateDeletion(deletion); err != nil {
+		return accounts.Deletion{}, err
+	}
+
+	message := fmt.Sprintf("Your account %s will be deleted on %s, together with your forum posts, uploaded images, challenges, chatbot history and reviews. "+
+		"Your orders, and threads other users replied to, are kept without your personal details. If you did not ask for this or changed your mind, log in and cancel the deletion before then.",
+		account.Username, deletion.ScheduledFor.Format("2 January 2006"))
+	if err := as.mailer.SendNotification(account.Email, "Your account will be deleted", message); err != nil {
+		log.Printf("failed to email %s about the deletion of their account: %v", userID, err)
+	}
+	return deletion, nil
+}
+
+func (as *AccountService) GetDeletion(userID string) (accounts.Deletion, error) {
+	return as.accountRepo.GetActiveDeletion(userID)
+}
+
+func (as *AccountService) CancelDeletion(userID string) error {
+	deletion, err := as.accountRepo.GetActiveDeletion(userID)
+	if err != nil {
+		return err
+	}
+	if err := as.accountRepo.CancelDeletion(deletion.ID, as.now()); err != nil {
+		return err
+	}
+
+	err = as.mailer.SendNotification(deletion.Email, "Your account will not be deleted", "The deletion of your account was cancelled. You can keep using it as before.")
+	if err != nil {
+		log.Printf("failed to email %s about the cancelled deletion of their account: %v", userID, err)
+	}
+	return nil
+}
+
+// ExecuteDueDeletions purges the accounts whose grace period is over. A
+// failed purge is rolled back and tried again on the next run. The images
+// of the user are removed from storage once the purge is committed; one that
+// cannot be removed is only logged, since nothing points to it any more.
+func (as *AccountService) ExecuteDueDeletions() error {
+	now := as.now()
+	due, err := as.accountRepo.GetDueDeletions(now)
+	if err != nil {
+		return err
+	}
+
+	for _, deletion := range due {
+		images, err := as.accountRepo.PurgeUser(deletion, now)
+		if err != nil {
+			log.Printf("failed to delete account %s: %v", deletion.UserID, err)
+			continue
+		}
+		for _, image := range images {
+			if err := as.storage.DeleteImageFromCloudinary(image); err != nil {
+				log.Printf("failed to delete image %s of account %s: %v", image, deletion.UserID, err)
+			}
+		}
+	}
+	return nil
+}
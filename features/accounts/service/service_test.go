@@ -0,0 +1,513 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"greenenvironment/constant"
+	"greenenvironment/features/accounts"
+	"greenenvironment/features/notifications"
+	"io"
+	"mime/multipart"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccountRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountRepository) CreateExport(export accounts.Export) error {
+	args := m.Called(export)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) GetLatestExport(userID string) (accounts.Export, error) {
+	args := m.Called(userID)
+	return args.Get(0).(accounts.Export), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetExport(userID, exportID string) (accounts.Export, error) {
+	args := m.Called(userID, exportID)
+	return args.Get(0).(accounts.Export), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetPendingExports(limit int) ([]accounts.Export, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]accounts.Export), args.Error(1)
+}
+
+func (m *MockAccountRepository) ClaimExport(exportID string, claimedAt time.Time) (bool, error) {
+	args := m.Called(exportID, claimedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetStaleExports(claimedBefore time.Time) ([]accounts.Export, error) {
+	args := m.Called(claimedBefore)
+	return args.Get(0).([]accounts.Export), args.Error(1)
+}
+
+func (m *MockAccountRepository) SaveExport(export accounts.Export) error {
+	args := m.Called(export)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) DeleteExpiredExports(now time.Time) error {
+	args := m.Called(now)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) CollectUserData(userID string) ([]accounts.ExportFile, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]accounts.ExportFile), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetAccount(userID string) (accounts.Account, error) {
+	args := m.Called(userID)
+	return args.Get(0).(accounts.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) CreateDeletion(deletion accounts.Deletion) error {
+	args := m.Called(deletion)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) GetActiveDeletion(userID string) (accounts.Deletion, error) {
+	args := m.Called(userID)
+	return args.Get(0).(accounts.Deletion), args.Error(1)
+}
+
+func (m *MockAccountRepository) CancelDeletion(deletionID string, cancelledAt time.Time) error {
+	args := m.Called(deletionID, cancelledAt)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) GetDueDeletions(now time.Time) ([]accounts.Deletion, error) {
+	args := m.Called(now)
+	return args.Get(0).([]accounts.Deletion), args.Error(1)
+}
+
+func (m *MockAccountRepository) PurgeUser(deletion accounts.Deletion, completedAt time.Time) ([]string, error) {
+	args := m.Called(deletion, completedAt)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(to string, code string, subject string) error {
+	args := m.Called(to, code, subject)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendNotification(to string, subject string, message string) error {
+	args := m.Called(to, subject, message)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendTemplate(to string, template string, data map[string]interface{}) error {
+	args := m.Called(to, template, data)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendTemplateOnce(to string, template string, key string, data map[string]interface{}) error {
+	args := m.Called(to, template, key, data)
+	return args.Error(0)
+}
+
+type MockStorage struct {
+	mock.Mock
+}
+
+func (m *MockStorage) ImageValidation(file *multipart.FileHeader) (multipart.File, error) {
+	args := m.Called(file)
+	return args.Get(0).(multipart.File), args.Error(1)
+}
+
+func (m *MockStorage) UploadImageToCloudinary(file interface{}, folderPath string) (string, error) {
+	args := m.Called(file, folderPath)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorage) DeleteImageFromCloudinary(imageURL string) error {
+	args := m.Called(imageURL)
+	return args.Error(0)
+}
+
+func (m *MockStorage) UploadFileHandler(c echo.Context) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(notification notifications.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+var testNow = time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+func newTestService(accountRepo *MockAccountRepository, mailer *MockMailer, notifier *MockNotifier) *AccountService {
+	service := NewAccountService(accountRepo, mailer, notifier, new(MockStorage)).(*AccountService)
+	service.now = func() time.Time { return testNow }
+	return service
+}
+
+func TestRequestExport_Success(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	service := newTestService(accountRepo, new(MockMailer), new(MockNotifier))
+
+	accountRepo.On("GetLatestExport", "user1").Return(accounts.Export{Status: accounts.ExportReady}, nil)
+	accountRepo.On("CreateExport", mock.MatchedBy(func(export accounts.Export) bool {
+		return export.ID != "" && export.UserID == "user1" && export.Status == accounts.ExportPending
+	})).Return(nil)
+
+	export, err := service.RequestExport("user1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, accounts.ExportPending, export.Status)
+	assert.Equal(t, testNow, export.CreatedAt)
+	accountRepo.AssertExpectations(t)
+}
+
+func TestRequestExport_FirstExport(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	service := newTestService(accountRepo, new(MockMailer), new(MockNotifier))
+
+	accountRepo.On("GetLatestExport", "user1").Return(accounts.Export{}, constant.ErrExportNotFound)
+	accountRepo.On("CreateExport", mock.Anything).Return(nil)
+
+	_, err := service.RequestExport("user1")
+
+	assert.NoError(t, err)
+	accountRepo.AssertExpectations(t)
+}
+
+func TestRequestExport_InProgress(t *testing.T) {
+	for _, status := range []string{accounts.ExportPending, accounts.ExportProcessing} {
+		accountRepo := new(MockAccountRepository)
+		service := newTestService(accountRepo, new(MockMailer), new(MockNotifier))
+
+		accountRepo.On("GetLatestExport", "user1").Return(accounts.Export{Status: status}, nil)
+
+		_, err := service.RequestExport("user1")
+
+		assert.Equal(t, constant.ErrExportInProgress, err)
+		accountRepo.AssertNotCalled(t, "CreateExport", mock.Anything)
+	}
+}
+
+func TestDownloadExport_Ready(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	service := newTestService(accountRepo, new(MockMailer), new(MockNotifier))
+
+	expiresAt := testNow.Add(time.Hour)
+	accountRepo.On("GetExport", "user1", "export1").Return(accounts.Export{ID: "export1", Status: accounts.ExportReady, Archive: []byte("zip"), ExpiresAt: &expiresAt}, nil)
+
+	export, err := service.DownloadExport("user1", "export1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("zip"), export.Archive)
+}
+
+func TestDownloadExport_NotReady(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	service := newTestService(accountRepo, new(MockMailer), new(MockNotifier))
+
+	accountRepo.On("GetExport", "user1", "export1").Return(accounts.Export{ID: "export1", Status: accounts.ExportProcessing}, nil)
+
+	_, err := service.DownloadExport("user1", "export1")
+
+	assert.Equal(t, constant.ErrExportNotReady, err)
+}
+
+func TestDownloadExport_Expired(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	service := newTestService(accountRepo, new(MockMailer), new(MockNotifier))
+
+	expiresAt := testNow.Add(-time.Minute)
+	accountRepo.On("GetExport", "user1", "export1").Return(accounts.Export{ID: "export1", Status: accounts.ExportReady, ExpiresAt: &expiresAt}, nil)
+
+	_, err := service.DownloadExport("user1", "export1")
+
+	assert.Equal(t, constant.ErrExportExpired, err)
+}
+
+func TestDownloadExport_OtherUser(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	service := newTestService(accountRepo, new(MockMailer), new(MockNotifier))
+
+	accountRepo.On("GetExport", "user2", "export1").Return(accounts.Export{}, constant.ErrExportNotFound)
+
+	_, err := service.DownloadExport("user2", "export1")
+
+	assert.Equal(t, constant.ErrExportNotFound, err)
+}
+
+// readArchive unzips an export into its files, keyed by name.
+func readArchive(t *testing.T, archive []byte) map[string][]byte {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	assert.NoError(t, err)
+
+	files := make(map[string][]byte)
+	for _, file := range reader.File {
+		content, err := file.Open()
+		assert.NoError(t, err)
+		data, err := io.ReadAll(content)
+		assert.NoError(t, err)
+		content.Close()
+		files[file.Name] = data
+	}
+	return files
+}
+
+func TestProcessPendingExports_BuildsArchive(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	notifier := new(MockNotifier)
+	service := newTestService(accountRepo, new(MockMailer), notifier)
+
+	accountRepo.On("DeleteExpiredExports", testNow).Return(nil)
+	accountRepo.On("GetStaleExports", testNow.Add(-accounts.ExportClaimTimeout)).Return([]accounts.Export{}, nil)
+	accountRepo.On("GetPendingExports", accounts.ExportBatchSize).Return([]accounts.Export{{ID: "export1", UserID: "user1", Status: accounts.ExportPending}}, nil)
+	accountRepo.On("ClaimExport", "export1", testNow).Return(true, nil)
+	accountRepo.On("CollectUserData", "user1").Return([]accounts.ExportFile{
+		{Name: "account.json", Rows: []map[string]interface{}{{"id": "user1", "username": "greeny"}}},
+		{Name: "forum_threads.json", Rows: []map[string]interface{}{}},
+	}, nil)
+
+	var saved accounts.Export
+	accountRepo.On("SaveExport", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(accounts.Export)
+	}).Return(nil)
+	notifier.On("Notify", mock.MatchedBy(func(notification notifications.Notification) bool {
+		return notification.UserID == "user1" && notification.Type == notifications.TypeAccount && notification.Title == "Your data export is ready"
+	})).Return(nil)
+
+	err := service.ProcessPendingExports()
+
+	assert.NoError(t, err)
+	assert.Equal(t, accounts.ExportReady, saved.Status)
+	assert.Equal(t, len(saved.Archive), saved.Size)
+	assert.Equal(t, testNow.Add(accounts.ExportRetention), *saved.ExpiresAt)
+
+	files := readArchive(t, saved.Archive)
+	assert.Len(t, files, 3)
+
+	var manifest exportManifest
+	assert.NoError(t, json.Unmarshal(files["export.json"], &manifest))
+	assert.Equal(t, "user1", manifest.UserID)
+	assert.Equal(t, []manifestFile{{Name: "account.json", Rows: 1}, {Name: "forum_threads.json", Rows: 0}}, manifest.Files)
+
+	var account []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(files["account.json"], &account))
+	assert.Equal(t, "greeny", account[0]["username"])
+	assert.JSONEq(t, "[]", string(files["forum_threads.json"]))
+	notifier.AssertExpectations(t)
+}
+
+func TestProcessPendingExports_SkipsClaimedExports(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	service := newTestService(accountRepo, new(MockMailer), new(MockNotifier))
+
+	accountRepo.On("DeleteExpiredExports", testNow).Return(nil)
+	accountRepo.On("GetStaleExports", testNow.Add(-accounts.ExportClaimTimeout)).Return([]accounts.Export{}, nil)
+	accountRepo.On("GetPendingExports", accounts.ExportBatchSize).Return([]accounts.Export{{ID: "export1", UserID: "user1"}}, nil)
+	accountRepo.On("ClaimExport", "export1", testNow).Return(false, nil)
+
+	err := service.ProcessPendingExports()
+
+	assert.NoError(t, err)
+	accountRepo.AssertNotCalled(t, "CollectUserData", mock.Anything)
+	accountRepo.AssertNotCalled(t, "SaveExport", mock.Anything)
+}
+
+func TestProcessPendingExports_MarksFailedExports(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	notifier := new(MockNotifier)
+	service := newTestService(accountRepo, new(MockMailer), notifier)
+
+	accountRepo.On("DeleteExpiredExports", testNow).Return(nil)
+	accountRepo.On("GetStaleExports", testNow.Add(-accounts.ExportClaimTimeout)).Return([]accounts.Export{}, nil)
+	accountRepo.On("GetPendingExports", accounts.ExportBatchSize).Return([]accounts.Export{{ID: "export1", UserID: "user1"}}, nil)
+	accountRepo.On("ClaimExport", "export1", testNow).Return(true, nil)
+	accountRepo.On("CollectUserData", "user1").Return([]accounts.ExportFile{}, errors.New("db down"))
+	accountRepo.On("SaveExport", mock.MatchedBy(func(export accounts.Export) bool {
+		return export.Status == accounts.ExportFailed && export.Error == "db down" && export.Archive == nil && export.ExpiresAt != nil
+	})).Return(nil)
+	notifier.On("Notify", mock.MatchedBy(func(notification notifications.Notification) bool {
+		return notification.Title == "Your data export failed"
+	})).Return(nil)
+
+	err := service.ProcessPendingExports()
+
+	assert.NoError(t, err)
+	accountRepo.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestProcessPendingExports_FailsStaleExports(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	notifier := new(MockNotifier)
+	service := newTestService(accountRepo, new(MockMailer), notifier)
+
+	claimedAt := testNow.Add(-time.Hour)
+	accountRepo.On("DeleteExpiredExports", testNow).Return(nil)
+	accountRepo.On("GetStaleExports", testNow.Add(-accounts.ExportClaimTimeout)).Return([]accounts.Export{
+		{ID: "export1", UserID: "user1", Status: accounts.ExportProcessing, ClaimedAt: &claimedAt},
+	}, nil)
+	accountRepo.On("SaveExport", mock.MatchedBy(func(export accounts.Export) bool {
+		return export.ID == "export1" && export.Status == accounts.ExportFailed && *export.ExpiresAt == testNow.Add(accounts.ExportRetention)
+	})).Return(nil)
+	accountRepo.On("GetPendingExports", accounts.ExportBatchSize).Return([]accounts.Export{}, nil)
+	notifier.On("Notify", mock.MatchedBy(func(notification notifications.Notification) bool {
+		return notification.UserID == "user1" && notification.Title == "Your data export failed"
+	})).Return(nil)
+
+	err := service.ProcessPendingExports()
+
+	assert.NoError(t, err)
+	accountRepo.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+	accountRepo.AssertNotCalled(t, "CollectUserData", mock.Anything)
+}
+
+func TestRequestDeletion_SchedulesAfterGracePeriod(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	mailer := new(MockMailer)
+	service := newTestService(accountRepo, mailer, new(MockNotifier))
+
+	accountRepo.On("GetAccount", "user1").Return(accounts.Account{ID: "user1", Username: "greeny", Email: "greeny@example.com"}, nil)
+	accountRepo.On("GetActiveDeletion", "user1").Return(accounts.Deletion{}, constant.ErrDeletionNotFound)
+	accountRepo.On("CreateDeletion", mock.MatchedBy(func(deletion accounts.Deletion) bool {
+		return deletion.ID != "" && deletion.UserID == "user1" && deletion.Email == "greeny@example.com" &&
+			deletion.Status == accounts.DeletionScheduled && deletion.RequestedAt.Equal(testNow) &&
+			deletion.ScheduledFor.Equal(testNow.Add(accounts.DeletionGracePeriod))
+	})).Return(nil)
+	mailer.On("SendNotification", "greeny@example.com", "Your account will be deleted", mock.MatchedBy(func(message string) bool {
+		return strings.Contains(message, "1 July 2024")
+	})).Return(nil)
+
+	deletion, err := service.RequestDeletion("user1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, testNow.Add(accounts.DeletionGracePeriod), deletion.ScheduledFor)
+	accountRepo.AssertExpectations(t)
+	mailer.AssertExpectations(t)
+}
+
+func TestRequestDeletion_AlreadyScheduled(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	mailer := new(MockMailer)
+	service := newTestService(accountRepo, mailer, new(MockNotifier))
+
+	accountRepo.On("GetAccount", "user1").Return(accounts.Account{ID: "user1", Email: "greeny@example.com"}, nil)
+	accountRepo.On("GetActiveDeletion", "user1").Return(accounts.Deletion{ID: "deletion1", Status: accounts.DeletionScheduled}, nil)
+
+	_, err := service.RequestDeletion("user1")
+
+	assert.Equal(t, constant.ErrDeletionScheduled, err)
+	accountRepo.AssertNotCalled(t, "CreateDeletion", mock.Anything)
+	mailer.AssertNotCalled(t, "SendNotification", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRequestDeletion_MailFailureDoesNotFail(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	mailer := new(MockMailer)
+	service := newTestService(accountRepo, mailer, new(MockNotifier))
+
+	accountRepo.On("GetAccount", "user1").Return(accounts.Account{ID: "user1", Email: "greeny@example.com"}, nil)
+	accountRepo.On("GetActiveDeletion", "user1").Return(accounts.Deletion{}, constant.ErrDeletionNotFound)
+	accountRepo.On("CreateDeletion", mock.Anything).Return(nil)
+	mailer.On("SendNotification", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("smtp down"))
+
+	_, err := service.RequestDeletion("user1")
+
+	assert.NoError(t, err)
+}
+
+func TestCancelDeletion_Success(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	mailer := new(MockMailer)
+	service := newTestService(accountRepo, mailer, new(MockNotifier))
+
+	accountRepo.On("GetActiveDeletion", "user1").Return(accounts.Deletion{ID: "deletion1", UserID: "user1", Email: "greeny@example.com", Status: accounts.DeletionScheduled}, nil)
+	accountRepo.On("CancelDeletion", "deletion1", testNow).Return(nil)
+	mailer.On("SendNotification", "greeny@example.com", "Your account will not be deleted", mock.Anything).Return(nil)
+
+	err := service.CancelDeletion("user1")
+
+	assert.NoError(t, err)
+	accountRepo.AssertExpectations(t)
+	mailer.AssertExpectations(t)
+}
+
+func TestCancelDeletion_NothingScheduled(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	service := newTestService(accountRepo, new(MockMailer), new(MockNotifier))
+
+	accountRepo.On("GetActiveDeletion", "user1").Return(accounts.Deletion{}, constant.ErrDeletionNotFound)
+
+	err := service.CancelDeletion("user1")
+
+	assert.Equal(t, constant.ErrDeletionNotFound, err)
+	accountRepo.AssertNotCalled(t, "CancelDeletion", mock.Anything, mock.Anything)
+}
+
+func TestExecuteDueDeletions_PurgesEachAccount(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	service := newTestService(accountRepo, new(MockMailer), new(MockNotifier))
+
+	first := accounts.Deletion{ID: "deletion1", UserID: "user1"}
+	second := accounts.Deletion{ID: "deletion2", UserID: "user2"}
+	accountRepo.On("GetDueDeletions", testNow).Return([]accounts.Deletion{first, second}, nil)
+	accountRepo.On("PurgeUser", first, testNow).Return([]string(nil), errors.New("lock wait timeout"))
+	accountRepo.On("PurgeUser", second, testNow).Return([]string{}, nil)
+
+	err := service.ExecuteDueDeletions()
+
+	assert.NoError(t, err)
+	accountRepo.AssertExpectations(t)
+}
+
+func TestExecuteDueDeletions_RemovesImages(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	storage := new(MockStorage)
+	service := newTestService(accountRepo, new(MockMailer), new(MockNotifier))
+	service.storage = storage
+
+	first := accounts.Deletion{ID: "deletion1", UserID: "user1"}
+	second := accounts.Deletion{ID: "deletion2", UserID: "user2"}
+	accountRepo.On("GetDueDeletions", testNow).Return([]accounts.Deletion{first, second}, nil)
+	accountRepo.On("PurgeUser", first, testNow).Return([]string{"avatar.jpg", "proof.jpg"}, nil)
+	accountRepo.On("PurgeUser", second, testNow).Return([]string(nil), errors.New("lock wait timeout"))
+	storage.On("DeleteImageFromCloudinary", "avatar.jpg").Return(errors.New("not found"))
+	storage.On("DeleteImageFromCloudinary", "proof.jpg").Return(nil)
+
+	err := service.ExecuteDueDeletions()
+
+	assert.NoError(t, err)
+	storage.AssertExpectations(t)
+	storage.AssertNumberOfCalls(t, "DeleteImageFromCloudinary", 2)
+}
+
+func TestExecuteDueDeletions_RepositoryError(t *testing.T) {
+	accountRepo := new(MockAccountRepository)
+	service := newTestService(accountRepo, new(MockMailer), new(MockNotifier))
+
+	accountRepo.On("GetDueDeletions", testNow).Return([]accounts.Deletion{}, errors.New("db down"))
+
+	err := service.ExecuteDueDeletions()
+
+	assert.Error(t, err)
+	accountRepo.AssertNotCalled(t, "PurgeUser", mock.Anything, mock.Anything)
+}
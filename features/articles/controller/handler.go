@@ -0,0 +1,452 @@
+package controller
+
+import (
+	"errors"
+	"greenenvironment/constant"
+	"greenenvironment/features/articles"
+	"greenenvironment/helper"
+	"greenenvironment/utils/storages"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// errUploadCover is returned by bindArticle when the cover image was valid
+// but could not be stored.
+var errUploadCover = errors.New("Failed to upload cover image")
+
+type ArticleHandler struct {
+	articleService articles.ArticleServiceInterface
+	jwt            helper.JWTInterface
+	storage        storages.StorageInterface
+}
+
+func NewArticleController(s articles.ArticleServiceInterface, j helper.JWTInterface, storage storages.StorageInterface) articles.ArticleControllerInterface {
+	return &ArticleHandler{
+		articleService: s,
+		jwt:            j,
+		storage:        storage,
+	}
+}
+
+// adminID returns the ID of the admin making the request, or false when the
+// request is not from an admin.
+func (h *ArticleHandler) adminID(c echo.Context) (string, bool) {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return "", false
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return "", false
+	}
+
+	adminData := h.jwt.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return "", false
+	}
+	adminID, _ := adminData[constant.JWT_ID].(string)
+	return adminID, adminID != ""
+}
+
+func pageParam(c echo.Context) int {
+	page, err := strconv.Atoi(c.QueryParam("pages"))
+	if err != nil || page < 1 {
+		return 1
+	}
+	return page
+}
+
+// bindArticle reads the article form and uploads the cover image when one is
+// attached.
+func (h *ArticleHandler) bindArticle(c echo.Context) (articles.Article, error) {
+	var request ArticleRequest
+	if err := c.Bind(&request); err != nil {
+		return articles.Article{}, errors.New("Error bad request")
+	}
+
+	if err := c.Validate(request); err != nil {
+		return articles.Article{}, err
+	}
+
+	article := articles.Article{
+		Title:   request.Title,
+		Summary: request.Summary,
+		Content: request.Content,
+		Status:  request.Status,
+	}
+	if request.PublishAt != "" {
+		publishAt, err := time.Parse(time.RFC3339, request.PublishAt)
+		if err != nil {
+			return articles.Article{}, errors.New("publish_at must be in RFC3339 format")
+		}
+		article.PublishedAt = &publishAt
+	}
+	for _, category := range request.ImpactCategories {
+		article.ImpactCategories = append(article.ImpactCategories, articles.ArticleImpactCategory{
+			ImpactCategoryID: category,
+		})
+	}
+
+	file, err := c.FormFile("cover_image")
+	if err == nil {
+		src, err := h.storage.ImageValidation(file)
+		if err != nil {
+			return articles.Article{}, err
+		}
+
+		article.CoverImage, err = h.storage.UploadImageToCloudinary(src, "ecomate/articles/covers/")
+		if err != nil {
+			return articles.Article{}, errUploadCover
+		}
+	}
+	return article, nil
+}
+
+func bindErrorCode(err error) int {
+	if err == errUploadCover {
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
+
+// Get Articles
+// @Summary      Get published articles
+// @Description  List the published articles, newest first, without their content.
+// @Tags         Articles
+// @Accept       json
+// @Produce      json
+// @Param        pages            query     int     false  "Page number"
+// @Param        search           query     string  false  "Search in titles and summaries"
+// @Param        impact_category  query     string  false  "Impact category ID"
+// @Success      200  {object}  helper.MetadataResponse{data=[]ArticleResponse} "Articles retrieved successfully"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /articles [get]
+func (h *ArticleHandler) GetAll(c echo.Context) error {
+	page := pageParam(c)
+	articleList, totalPages, err := h.articleService.GetPublished(articles.ArticleFilter{
+		Page:             page,
+		Search:           c.QueryParam("search"),
+		ImpactCategoryID: c.QueryParam("impact_category"),
+	})
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []ArticleResponse{}
+	for _, article := range articleList {
+		response = append(response, new(ArticleResponse).ToResponse(article))
+	}
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "articles retrieved successfully", metadata, response))
+}
+
+// Get Article
+// @Summary      Get a published article
+// @Description  Get a published article with its content. Every read adds to its view count.
+// @Tags         Articles
+// @Accept       json
+// @Produce      json
+// @Param        slug  path  string  true  "Article slug"
+// @Success      200  {object}  helper.Response{data=ArticleDetailResponse} "Article retrieved successfully"
+// @Failure      404  {object}  helper.Response{data=string} "Article not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /articles/{slug} [get]
+func (h *ArticleHandler) GetBySlug(c echo.Context) error {
+	article, err := h.articleService.GetBySlug(c.Param("slug"))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "article retrieved successfully", new(ArticleDetailResponse).ToResponse(article)))
+}
+
+// Get Daily Tip
+// @Summary      Get the eco tip of the day
+// @Description  Everyone gets the same tip during a day, and every tip is shown once before any repeats.
+// @Tags         Articles
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  helper.Response{data=EcoTipResponse} "Tip retrieved successfully"
+// @Failure      404  {object}  helper.Response{data=string} "Eco tip not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /tips/daily [get]
+func (h *ArticleHandler) GetDailyTip(c echo.Context) error {
+	tip, err := h.articleService.GetDailyTip()
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "tip retrieved successfully", new(EcoTipResponse).ToResponse(tip)))
+}
+
+// Create Article
+// @Summary      Create an article
+// @Description  Create an article. Content is rich text as HTML. A status of Published makes it live at publish_at, or right away without one; a future publish_at makes it Scheduled until then. The default status is Draft.
+// @Tags         Articles (Admin)
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        Authorization      header    string  true   "Bearer Token"
+// @Param        title              formData  string  true   "Title"
+// @Param        summary            formData  string  false  "Summary, taken from the content when empty"
+// @Param        content            formData  string  true   "Content as HTML"
+// @Param        status             formData  string  false  "Draft (default) or Published"
+// @Param        publish_at         formData  string  false  "When to publish, in RFC3339 format"
+// @Param        impact_categories  formData  []string  false  "Impact category IDs"
+// @Param        cover_image        formData  file    false  "Cover image"
+// @Success      201  {object}  helper.Response{data=ArticleDetailResponse} "Article created successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Bad request"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/articles [post]
+func (h *ArticleHandler) Create(c echo.Context) error {
+	adminID, ok := h.adminID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	article, err := h.bindArticle(c)
+	if err != nil {
+		return c.JSON(bindErrorCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+	article.AuthorID = adminID
+
+	created, err := h.articleService.Create(article)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusCreated, helper.FormatResponse(true, "article created successfully", new(ArticleDetailResponse).ToResponse(created)))
+}
+
+// Update Article
+// @Summary      Update an article
+// @Description  Update an article. The slug never changes. Without a cover image or publish_at the current ones are kept; set status to Draft to unpublish.
+// @Tags         Articles (Admin)
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        Authorization      header    string  true   "Bearer Token"
+// @Param        id                 path      string  true   "Article ID"
+// @Param        title              formData  string  true   "Title"
+// @Param        summary            formData  string  false  "Summary, taken from the content when empty"
+// @Param        content            formData  string  true   "Content as HTML"
+// @Param        status             formData  string  false  "Draft (default) or Published"
+// @Param        publish_at         formData  string  false  "When to publish, in RFC3339 format"
+// @Param        impact_categories  formData  []string  false  "Impact category IDs"
+// @Param        cover_image        formData  file    false  "Cover image"
+// @Success      200  {object}  helper.Response{data=ArticleDetailResponse} "Article updated successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Bad request"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Article not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/articles/{id} [put]
+func (h *ArticleHandler) Update(c echo.Context) error {
+	if _, ok := h.adminID(c); !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	article, err := h.bindArticle(c)
+	if err != nil {
+		return c.JSON(bindErrorCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+	article.ID = c.Param("id")
+
+	updated, err := h.articleService.Update(article)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "article updated successfully", new(ArticleDetailResponse).ToResponse(updated)))
+}
+
+// Delete Article
+// @Summary      Delete an article
+// @Description  Delete an article. Its slug is not reused.
+// @Tags         Articles (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Article ID"
+// @Success      200  {object}  helper.Response{data=string} "Article deleted successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Article not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/articles/{id} [delete]
+func (h *ArticleHandler) Delete(c echo.Context) error {
+	if _, ok := h.adminID(c); !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := h.articleService.Delete(c.Param("id")); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "article deleted successfully", nil))
+}
+
+// Get Articles (Admin)
+// @Summary      Get all articles
+// @Description  List articles of every status, including drafts and scheduled ones.
+// @Tags         Articles (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization    header    string  true   "Bearer Token"
+// @Param        pages            query     int     false  "Page number"
+// @Param        status           query     string  false  "Draft, Scheduled or Published"
+// @Param        search           query     string  false  "Search in titles and summaries"
+// @Param        impact_category  query     string  false  "Impact category ID"
+// @Success      200  {object}  helper.MetadataResponse{data=[]ArticleResponse} "Articles retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/articles [get]
+func (h *ArticleHandler) GetAllForAdmin(c echo.Context) error {
+	if _, ok := h.adminID(c); !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	page := pageParam(c)
+	articleList, totalPages, err := h.articleService.GetAll(articles.ArticleFilter{
+		Page:             page,
+		Search:           c.QueryParam("search"),
+		ImpactCategoryID: c.QueryParam("impact_category"),
+		Status:           c.QueryParam("status"),
+	})
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []ArticleResponse{}
+	for _, article := range articleList {
+		response = append(response, new(ArticleResponse).ToResponse(article))
+	}
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "articles retrieved successfully", metadata, response))
+}
+
+// Get Article (Admin)
+// @Summary      Get an article
+// @Description  Get an article of any status with its content, without counting a view.
+// @Tags         Articles (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Article ID"
+// @Success      200  {object}  helper.Response{data=ArticleDetailResponse} "Article retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Article not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/articles/{id} [get]
+func (h *ArticleHandler) GetByID(c echo.Context) error {
+	if _, ok := h.adminID(c); !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	article, err := h.articleService.GetByID(c.Param("id"))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "article retrieved successfully", new(ArticleDetailResponse).ToResponse(article)))
+}
+
+// Get Eco Tips
+// @Summary      Get all eco tips
+// @Description  List the eco tips in the order the tip of the day goes through them.
+// @Tags         Articles (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Success      200  {object}  helper.Response{data=[]EcoTipResponse} "Tips retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/tips [get]
+func (h *ArticleHandler) GetTips(c echo.Context) error {
+	if _, ok := h.adminID(c); !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	tips, err := h.articleService.GetTips()
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []EcoTipResponse{}
+	for _, tip := range tips {
+		response = append(response, new(EcoTipResponse).ToResponse(tip))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "tips retrieved successfully", response))
+}
+
+// Create Eco Tip
+// @Summary      Create an eco tip
+// @Description  Add a tip of at most 500 characters to the tips of the day.
+// @Tags         Articles (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string         true  "Bearer Token"
+// @Param        request        body      EcoTipRequest  true  "Tip"
+// @Success      201  {object}  helper.Response{data=EcoTipResponse} "Tip created successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Bad request"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/tips [post]
+func (h *ArticleHandler) CreateTip(c echo.Context) error {
+	if _, ok := h.adminID(c); !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	var request EcoTipRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "Error bad request", nil))
+	}
+
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	tip, err := h.articleService.CreateTip(articles.EcoTip{Content: request.Content})
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusCreated, helper.FormatResponse(true, "tip created successfully", new(EcoTipResponse).ToResponse(tip)))
+}
+
+// Delete Eco Tip
+// @Summary      Delete an eco tip
+// @Description  Remove a tip from the tips of the day.
+// @Tags         Articles (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Tip ID"
+// @Success      200  {object}  helper.Response{data=string} "Tip deleted successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Eco tip not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/tips/{id} [delete]
+func (h *ArticleHandler) DeleteTip(c echo.Context) error {
+	if _, ok := h.adminID(c); !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := h.articleService.DeleteTip(c.Param("id")); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "tip deleted successfully", nil))
+}
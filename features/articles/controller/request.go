@@ -0,0 +1,16 @@
+package controller
+
+// ArticleRequest holds the form fields of an article. PublishAt is in
+// RFC3339 format.
+type ArticleRequest struct {
+	Title            string   `form:"title" validate:"required"`
+	Summary          string   `form:"summary"`
+	Content          string   `form:"content" validate:"required"`
+	Status           string   `form:"status"`
+	PublishAt        string   `form:"publish_at"`
+	ImpactCategories []string `form:"impact_categories"`
+}
+
+type EcoTipRequest struct {
+	Content string `json:"content" validate:"required"`
+}
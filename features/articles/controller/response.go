@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"greenenvironment/features/articles"
+	"time"
+)
+
+type ImpactCategoryResponse struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Icon  string `json:"icon"`
+	Color string `json:"color"`
+}
+
+type ArticleResponse struct {
+	ID               string                   `json:"id"`
+	Title            string                   `json:"title"`
+	Slug             string                   `json:"slug"`
+	Summary          string                   `json:"summary"`
+	CoverImage       string                   `json:"cover_image"`
+	Author           string                   `json:"author"`
+	Status           string                   `json:"status"`
+	PublishedAt      *time.Time               `json:"published_at"`
+	ViewCount        int                      `json:"view_count"`
+	ImpactCategories []ImpactCategoryResponse `json:"impact_categories"`
+	UpdatedAt        time.Time                `json:"updated_at"`
+}
+
+func (a ArticleResponse) ToResponse(article articles.Article) ArticleResponse {
+	response := ArticleResponse{
+		ID:               article.ID,
+		Title:            article.Title,
+		Slug:             article.Slug,
+		Summary:          article.Summary,
+		CoverImage:       article.CoverImage,
+		Author:           article.AuthorName,
+		Status:           article.Status,
+		PublishedAt:      article.PublishedAt,
+		ViewCount:        article.ViewCount,
+		ImpactCategories: []ImpactCategoryResponse{},
+		UpdatedAt:        article.UpdatedAt,
+	}
+	for _, category := range article.ImpactCategories {
+		response.ImpactCategories = append(response.ImpactCategories, ImpactCategoryResponse{
+			ID:    category.ImpactCategory.ID,
+			Name:  category.ImpactCategory.Name,
+			Icon:  category.ImpactCategory.Icon,
+			Color: category.ImpactCategory.Color,
+		})
+	}
+	return response
+}
+
+type ArticleDetailResponse struct {
+	ArticleResponse
+	Content string `json:"content"`
+}
+
+func (a ArticleDetailResponse) ToResponse(article articles.Article) ArticleDetailResponse {
+	return ArticleDetailResponse{
+		ArticleResponse: new(ArticleResponse).ToResponse(article),
+		Content:         article.Content,
+	}
+}
+
+type EcoTipResponse struct {
+	ID        string `json:"id"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (e EcoTipResponse) ToResponse(tip articles.EcoTip) EcoTipResponse {
+	return EcoTipResponse{
+		ID:        tip.ID,
+		Content:   tip.Content,
+		CreatedAt: tip.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,113 @@
+package articles
+
+import (
+	"greenenvironment/features/impacts"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Article statuses. A published article with a publish date in the future is
+// Scheduled until the date passes.
+const (
+	StatusDraft     = "Draft"
+	StatusScheduled = "Scheduled"
+	StatusPublished = "Published"
+)
+
+// MaxSummaryLength is how long a summary can be, and how much of the content
+// is used as the summary when none is given.
+const MaxSummaryLength = 300
+
+// Article is an educational post written by an admin. Content is rich text
+// as HTML from the admin editor and is returned as is. PublishedAt is when
+// the article went live, or is planned to while it is Scheduled.
+type Article struct {
+	ID               string
+	Title            string
+	Slug             string
+	Summary          string
+	Content          string
+	CoverImage       string
+	AuthorID         string
+	AuthorName       string
+	Status           string
+	PublishedAt      *time.Time
+	ViewCount        int
+	ImpactCategories []ArticleImpactCategory
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+type ArticleImpactCategory struct {
+	ID               string
+	ArticleID        string
+	ImpactCategoryID string
+	ImpactCategory   impacts.ImpactCategory
+}
+
+// ArticleFilter narrows GetAll. An empty Status lists every status, which
+// only admins get to do.
+type ArticleFilter struct {
+	Page             int
+	Search           string
+	ImpactCategoryID string
+	Status           string
+}
+
+// EcoTip is a short piece of advice. One of them is the tip of the day.
+type EcoTip struct {
+	ID        string
+	Content   string
+	CreatedAt time.Time
+}
+
+type ArticleControllerInterface interface {
+	GetAll(c echo.Context) error
+	GetBySlug(c echo.Context) error
+	GetDailyTip(c echo.Context) error
+
+	// Admin
+	Create(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+	GetAllForAdmin(c echo.Context) error
+	GetByID(c echo.Context) error
+	GetTips(c echo.Context) error
+	CreateTip(c echo.Context) error
+	DeleteTip(c echo.Context) error
+}
+
+type ArticleServiceInterface interface {
+	Create(article Article) (Article, error)
+	Update(article Article) (Article, error)
+	Delete(articleID string) error
+	GetAll(filter ArticleFilter) ([]Article, int, error)
+	GetPublished(filter ArticleFilter) ([]Article, int, error)
+	GetByID(articleID string) (Article, error)
+	GetBySlug(slug string) (Article, error)
+	PublishScheduled() error
+
+	GetDailyTip() (EcoTip, error)
+	GetTips() ([]EcoTip, error)
+	CreateTip(tip EcoTip) (EcoTip, error)
+	DeleteTip(tipID string) error
+}
+
+type ArticleRepositoryInterface interface {
+	Create(article Article) error
+	Update(article Article) error
+	Delete(articleID string) error
+	GetAll(filter ArticleFilter) ([]Article, int, error)
+	GetByID(articleID string) (Article, error)
+	GetBySlug(slug string) (Article, error)
+	SlugExists(slug string) (bool, error)
+	IncrementViews(articleID string) error
+	PublishScheduled(now time.Time) error
+
+	GetTips() ([]EcoTip, error)
+	CountTips() (int, error)
+	GetTipAt(offset int) (EcoTip, error)
+	CreateTip(tip EcoTip) error
+	DeleteTip(tipID string) error
+}
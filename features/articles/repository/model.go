@@ -0,0 +1,52 @@
+package repository
+
+import (
+	admin "greenenvironment/features/admin/repository"
+	impactcategory "greenenvironment/features/impacts/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Article struct {
+	*gorm.Model
+	ID               string                  `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	Title            string                  `gorm:"type:varchar(255);not null;column:title"`
+	Slug             string                  `gorm:"type:varchar(255);not null;uniqueIndex;column:slug"`
+	Summary          string                  `gorm:"type:varchar(300);column:summary"`
+	Content          string                  `gorm:"type:mediumtext;not null;column:content"`
+	CoverImage       string                  `gorm:"type:varchar(255);column:cover_image"`
+	AuthorID         string                  `gorm:"type:varchar(50);not null;column:author_id"`
+	Status           string                  `gorm:"type:enum('Draft','Scheduled','Published');not null;default:'Draft';index:idx_article_status_published;column:status"`
+	PublishedAt      *time.Time              `gorm:"type:datetime;index:idx_article_status_published;column:published_at"`
+	ViewCount        int                     `gorm:"type:int;not null;default:0;column:view_count"`
+	Author           admin.Admin             `gorm:"foreignKey:AuthorID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ImpactCategories []ArticleImpactCategory `gorm:"foreignKey:ArticleID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type ArticleImpactCategory struct {
+	*gorm.Model
+	ID               string                        `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	ArticleID        string                        `gorm:"type:varchar(50);not null;column:article_id"`
+	ImpactCategoryID string                        `gorm:"type:varchar(50);not null;column:impact_category_id"`
+	Article          Article                       `gorm:"foreignKey:ArticleID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ImpactCategory   impactcategory.ImpactCategory `gorm:"foreignKey:ImpactCategoryID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+}
+
+type EcoTip struct {
+	*gorm.Model
+	ID      string `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	Content string `gorm:"type:varchar(500);not null;column:content"`
+}
+
+func (Article) TableName() string {
+	return "articles"
+}
+
+func (ArticleImpactCategory) TableName() string {
+	return "article_impact_categories"
+}
+
+func (EcoTip) TableName() string {
+	return "eco_tips"
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"errors"
+	"greenenvironment/constant"
+	"greenenvironment/features/articles"
+	"greenenvironment/features/impacts"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// articlesPerPage sizes the pages of the article lists.
+const articlesPerPage = 12
+
+type ArticleRepository struct {
+	DB *gorm.DB
+}
+
+func NewArticleRepository(db *gorm.DB) articles.ArticleRepositoryInterface {
+	return &ArticleRepository{
+		DB: db,
+	}
+}
+
+func (ar *ArticleRepository) Create(article articles.Article) error {
+	articleData := toArticleModel(article)
+	return ar.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("ImpactCategories", "Author").Create(&articleData).Error; err != nil {
+			return err
+		}
+		return saveImpactCategories(tx, articleData.ImpactCategories)
+	})
+}
+
+// Update saves every field except the slug, author and view count, and
+// replaces the impact categories.
+func (ar *ArticleRepository) Update(article articles.Article) error {
+	articleData := toArticleModel(article)
+	return ar.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Article{}).Where("id = ?", article.ID).Updates(map[string]interface{}{
+			"title":        articleData.Title,
+			"summary":      articleData.Summary,
+			"content":      articleData.Content,
+			"cover_image":  articleData.CoverImage,
+			"status":       articleData.Status,
+			"published_at": articleData.PublishedAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return constant.ErrArticleNotFound
+		}
+
+		if err := tx.Where("article_id = ?", article.ID).Delete(&ArticleImpactCategory{}).Error; err != nil {
+			return err
+		}
+		return saveImpactCategories(tx, articleData.ImpactCategories)
+	})
+}
+
+func saveImpactCategories(tx *gorm.DB, categories []ArticleImpactCategory) error {
+	if len(categories) == 0 {
+		return nil
+	}
+	return tx.Omit("Article", "ImpactCategory").Create(&categories).Error
+}
+
+func (ar *ArticleRepository) Delete(articleID string) error {
+	return ar.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("article_id = ?", articleID).Delete(&ArticleImpactCategory{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", articleID).Delete(&Article{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return constant.ErrArticleNotFound
+		}
+		return nil
+	})
+}
+
+// GetAll lists articles without their content, the newest published first
+// and drafts by when they were last edited.
+func (ar *ArticleRepository) GetAll(filter articles.ArticleFilter) ([]articles.Article, int, error) {
+	var total int64
+	if err := ar.filterArticles(ar.DB.Model(&Article{}), filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	totalPages := int((total + articlesPerPage - 1) / articlesPerPage)
+
+	var articleData []Article
+	err := ar.filterArticles(ar.DB.Omit("content"), filter).
+		Preload("Author").
+		Preload("ImpactCategories.ImpactCategory").
+		Order("published_at IS NULL, published_at DESC, updated_at DESC").
+		Offset((filter.Page - 1) * articlesPerPage).
+		Limit(articlesPerPage).
+		Find(&articleData).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]articles.Article, 0, len(articleData))
+	for _, article := range articleData {
+		result = append(result, toArticleEntity(article))
+	}
+	return result, totalPages, nil
+}
+
+// filterArticles applies the filters shared by the page query and its count.
+func (ar *ArticleRepository) filterArticles(tx *gorm.DB, filter articles.ArticleFilter) *gorm.DB {
+	if filter.Status != "" {
+		tx = tx.Where("status = ?", filter.Status)
+	}
+	if filter.Search != "" {
+		search := "%" + filter.Search + "%"
+		tx = tx.Where("title LIKE ? OR summary LIKE ?", search, search)
+	}
+	if filter.ImpactCategoryID != "" {
+		tx = tx.Where("id IN (?)", ar.DB.Model(&ArticleImpactCategory{}).
+			Select("article_id").
+			Where("impact_category_id = ?", filter.ImpactCategoryID))
+	}
+	return tx
+}
+
+func (ar *ArticleRepository) GetByID(articleID string) (articles.Article, error) {
+	return ar.getArticle("id = ?", articleID)
+}
+
+func (ar *ArticleRepository) GetBySlug(slug string) (articles.Article, error) {
+	return ar.getArticle("slug = ?", slug)
+}
+
+func (ar *ArticleRepository) getArticle(condition string, value string) (articles.Article, error) {
+	var article Article
+	err := ar.DB.Preload("Author").
+		Preload("ImpactCategories.ImpactCategory").
+		Where(condition, value).
+		First(&article).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return articles.Article{}, constant.ErrArticleNotFound
+	}
+	if err != nil {
+		return articles.Article{}, err
+	}
+	return toArticleEntity(article), nil
+}
+
+// SlugExists also looks at deleted articles, since their slugs still hold
+// the unique index.
+func (ar *ArticleRepository) SlugExists(slug string) (bool, error) {
+	var count int64
+	err := ar.DB.Unscoped().Model(&Article{}).Where("slug = ?", slug).Count(&count).Error
+	return count > 0, err
+}
+
+func (ar *ArticleRepository) IncrementViews(articleID string) error {
+	return ar.DB.Model(&Article{}).Where("id = ?", articleID).
+		UpdateColumn("view_count", gorm.Expr("view_count + 1")).Error
+}
+
+func (ar *ArticleRepository) PublishScheduled(now time.Time) error {
+	return ar.DB.Model(&Article{}).
+		Where("status = ? AND published_at <= ?", articles.StatusScheduled, now).
+		Update("status", articles.StatusPublished).Error
+}
+
+func (ar *ArticleRepository) GetTips() ([]articles.EcoTip, error) {
+	var tipData []EcoTip
+	if err := ar.DB.Order("created_at ASC, id ASC").Find(&tipData).Error; err != nil {
+		return nil, err
+	}
+
+	tips := make([]articles.EcoTip, 0, len(tipData))
+	for _, tip := range tipData {
+		tips = append(tips, toEcoTipEntity(tip))
+	}
+	return tips, nil
+}
+
+func (ar *ArticleRepository) CountTips() (int, error) {
+	var count int64
+	err := ar.DB.Model(&EcoTip{}).Count(&count).Error
+	return int(count), err
+}
+
+// GetTipAt returns the tip at offset in the order GetTips lists them.
+func (ar *ArticleRepository) GetTipAt(offset int) (articles.EcoTip, error) {
+	var tip EcoTip
+	err := ar.DB.Order("created_at ASC, id ASC").Offset(offset).Limit(1).Take(&tip).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return articles.EcoTip{}, constant.ErrEcoTipNotFound
+	}
+	if err != nil {
+		return articles.EcoTip{}, err
+	}
+	return toEcoTipEntity(tip), nil
+}
+
+func (ar *ArticleRepository) CreateTip(tip articles.EcoTip) error {
+	return ar.DB.Create(&EcoTip{
+		ID:      tip.ID,
+		Content: tip.Content,
+	}).Error
+}
+
+func (ar *ArticleRepository) DeleteTip(tipID string) error {
+	result := ar.DB.Where("id = ?", tipID).Delete(&EcoTip{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrEcoTipNotFound
+	}
+	return nil
+}
+
+func toArticleModel(article articles.Article) Article {
+	articleData := Article{
+		ID:          article.ID,
+		Title:       article.Title,
+		Slug:        article.Slug,
+		Summary:     article.Summary,
+		Content:     article.Content,
+		CoverImage:  article.CoverImage,
+		AuthorID:    article.AuthorID,
+		Status:      article.Status,
+		PublishedAt: article.PublishedAt,
+	}
+	for _, category := range article.ImpactCategories {
+		articleData.ImpactCategories = append(articleData.ImpactCategories, ArticleImpactCategory{
+			ID:               category.ID,
+			ArticleID:        article.ID,
+			ImpactCategoryID: category.ImpactCategoryID,
+		})
+	}
+	return articleData
+}
+
+func toArticleEntity(article Article) articles.Article {
+	articleEntity := articles.Article{
+		ID:          article.ID,
+		Title:       article.Title,
+		Slug:        article.Slug,
+		Summary:     article.Summary,
+		Content:     article.Content,
+		CoverImage:  article.CoverImage,
+		AuthorID:    article.AuthorID,
+		AuthorName:  article.Author.Name,
+		Status:      article.Status,
+		PublishedAt: article.PublishedAt,
+		ViewCount:   article.ViewCount,
+	}
+	if article.Model != nil {
+		articleEntity.CreatedAt = article.CreatedAt
+		articleEntity.UpdatedAt = article.UpdatedAt
+	}
+	for _, category := range article.ImpactCategories {
+		articleEntity.ImpactCategories = append(articleEntity.ImpactCategories, articles.ArticleImpactCategory{
+			ID:               category.ID,
+			ArticleID:        category.ArticleID,
+			ImpactCategoryID: category.ImpactCategoryID,
+			ImpactCategory: impacts.ImpactCategory{
+				ID:          category.ImpactCategory.ID,
+				Name:        category.ImpactCategory.Name,
+				ImpactPoint: category.ImpactCategory.ImpactPoint,
+				Description: category.ImpactCategory.Description,
+				Icon:        category.ImpactCategory.Icon,
+				Color:       category.ImpactCategory.Color,
+			},
+		})
+	}
+	return articleEntity
+}
+
+func toEcoTipEntity(tip EcoTip) articles.EcoTip {
+	tipEntity := articles.EcoTip{
+		ID:      tip.ID,
+		Content: tip.Content,
+	}
+	if tip.Model != nil {
+		tipEntity.CreatedAt = tip.CreatedAt
+	}
+	return tipEntity
+}
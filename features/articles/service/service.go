@@ -0,0 +1,262 @@
+package service
+
+import (
+	"greenenvironment/constant"
+	"greenenvironment/features/articles"
+	"greenenvironment/features/impacts"
+	"greenenvironment/helper"
+	"html"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// maxTipLength is how long an eco tip can be, in characters.
+const maxTipLength = 500
+
+var (
+	htmlTags          = regexp.MustCompile(`<[^>]*>`)
+	nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+type ArticleService struct {
+	articleRepo articles.ArticleRepositoryInterface
+	impactRepo  impacts.ImpactRepositoryInterface
+	now         func() time.Time
+}
+
+func NewArticleService(ar articles.ArticleRepositoryInterface, ir impacts.ImpactRepositoryInterface) articles.ArticleServiceInterface {
+	return &ArticleService{
+		articleRepo: ar,
+		impactRepo:  ir,
+		now:         time.Now,
+	}
+}
+
+// resolvePublishStatus turns the requested status into the stored one. An
+// article published without a date goes live now and one with a future date
+// is Scheduled until then.
+func resolvePublishStatus(article articles.Article, now time.Time) (string, *time.Time, error) {
+	switch article.Status {
+	case "", articles.StatusDraft:
+		return articles.StatusDraft, article.PublishedAt, nil
+	case articles.StatusPublished, articles.StatusScheduled:
+		if article.PublishedAt == nil {
+			return articles.StatusPublished, &now, nil
+		}
+		if article.PublishedAt.After(now) {
+			return articles.StatusScheduled, article.PublishedAt, nil
+		}
+		return articles.StatusPublished, article.PublishedAt, nil
+	default:
+		return "", nil, constant.ErrInvalidArticleStatus
+	}
+}
+
+func (as *ArticleService) Create(article articles.Article) (articles.Article, error) {
+	if err := as.prepare(&article); err != nil {
+		return articles.Article{}, err
+	}
+
+	article.ID = uuid.New().String()
+	slug, err := as.uniqueSlug(article.Title, article.ID)
+	if err != nil {
+		return articles.Article{}, err
+	}
+	article.Slug = slug
+
+	if err := as.articleRepo.Create(article); err != nil {
+		return articles.Article{}, err
+	}
+	return as.articleRepo.GetByID(article.ID)
+}
+
+// Update keeps the slug so links to the article stay valid. An empty cover
+// image or a nil publish date keeps the current one.
+func (as *ArticleService) Update(article articles.Article) (articles.Article, error) {
+	existing, err := as.articleRepo.GetByID(article.ID)
+	if err != nil {
+		return articles.Article{}, err
+	}
+
+	if article.CoverImage == "" {
+		article.CoverImage = existing.CoverImage
+	}
+	if article.PublishedAt == nil {
+		article.PublishedAt = existing.PublishedAt
+	}
+	if err := as.prepare(&article); err != nil {
+		return articles.Article{}, err
+	}
+
+	if err := as.articleRepo.Update(article); err != nil {
+		return articles.Article{}, err
+	}
+	return as.articleRepo.GetByID(article.ID)
+}
+
+// prepare validates an article about to be saved and fills in its status,
+// summary and impact category IDs.
+func (as *ArticleService) prepare(article *articles.Article) error {
+	article.Title = strings.TrimSpace(article.Title)
+	article.Summary = strings.TrimSpace(article.Summary)
+	text := plainText(article.Content)
+	if article.Title == "" || text == "" {
+		return constant.ErrArticleField
+	}
+	if article.Summary == "" {
+		article.Summary = truncate(text, articles.MaxSummaryLength)
+	}
+	article.Summary = truncate(article.Summary, articles.MaxSummaryLength)
+
+	status, publishedAt, err := resolvePublishStatus(*article, as.now())
+	if err != nil {
+		return err
+	}
+	article.Status = status
+	article.PublishedAt = publishedAt
+
+	seen := make(map[string]bool)
+	var categories []articles.ArticleImpactCategory
+	for _, category := range article.ImpactCategories {
+		if seen[category.ImpactCategoryID] {
+			continue
+		}
+		seen[category.ImpactCategoryID] = true
+
+		data, _ := as.impactRepo.GetByID(category.ImpactCategoryID)
+		if data.ID == "" {
+			return constant.ErrArticleImpactCategory
+		}
+		categories = append(categories, articles.ArticleImpactCategory{
+			ID:               uuid.New().String(),
+			ImpactCategoryID: category.ImpactCategoryID,
+		})
+	}
+	article.ImpactCategories = categories
+	return nil
+}
+
+// uniqueSlug derives the slug from the title, adding part of the article ID
+// when another article already uses it.
+func (as *ArticleService) uniqueSlug(title, articleID string) (string, error) {
+	slug := strings.Trim(nonSlugCharacters.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(slug) > 200 {
+		slug = strings.TrimRight(slug[:200], "-")
+	}
+	if slug == "" {
+		slug = "article"
+	}
+
+	exists, err := as.articleRepo.SlugExists(slug)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		slug += "-" + articleID[:8]
+	}
+	return slug, nil
+}
+
+func (as *ArticleService) Delete(articleID string) error {
+	return as.articleRepo.Delete(articleID)
+}
+
+func (as *ArticleService) GetAll(filter articles.ArticleFilter) ([]articles.Article, int, error) {
+	return as.articleRepo.GetAll(filter)
+}
+
+func (as *ArticleService) GetPublished(filter articles.ArticleFilter) ([]articles.Article, int, error) {
+	filter.Status = articles.StatusPublished
+	return as.articleRepo.GetAll(filter)
+}
+
+func (as *ArticleService) GetByID(articleID string) (articles.Article, error) {
+	return as.articleRepo.GetByID(articleID)
+}
+
+// GetBySlug returns a published article and counts the read.
+func (as *ArticleService) GetBySlug(slug string) (articles.Article, error) {
+	article, err := as.articleRepo.GetBySlug(slug)
+	if err != nil {
+		return articles.Article{}, err
+	}
+	if article.Status != articles.StatusPublished {
+		return articles.Article{}, constant.ErrArticleNotFound
+	}
+
+	if err := as.articleRepo.IncrementViews(article.ID); err != nil {
+		log.Printf("failed to count a view of article %s: %v", article.ID, err)
+	} else {
+		article.ViewCount++
+	}
+	return article, nil
+}
+
+// PublishScheduled publishes the scheduled articles whose date has come.
+func (as *ArticleService) PublishScheduled() error {
+	return as.articleRepo.PublishScheduled(as.now())
+}
+
+// GetDailyTip picks the same tip for everyone during a day in the default
+// timezone and walks through all tips before repeating one.
+func (as *ArticleService) GetDailyTip() (articles.EcoTip, error) {
+	count, err := as.articleRepo.CountTips()
+	if err != nil {
+		return articles.EcoTip{}, err
+	}
+	if count == 0 {
+		return articles.EcoTip{}, constant.ErrEcoTipNotFound
+	}
+
+	now := as.now().In(helper.LoadTimezone(helper.DefaultTimezone))
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+	return as.articleRepo.GetTipAt(int(day % int64(count)))
+}
+
+func (as *ArticleService) GetTips() ([]articles.EcoTip, error) {
+	return as.articleRepo.GetTips()
+}
+
+func (as *ArticleService) CreateTip(tip articles.EcoTip) (articles.EcoTip, error) {
+	tip.Content = strings.TrimSpace(tip.Content)
+	if tip.Content == "" || utf8.RuneCountInString(tip.Content) > maxTipLength {
+		return articles.EcoTip{}, constant.ErrEcoTipField
+	}
+
+	tip.ID = uuid.New().String()
+	tip.CreatedAt = as.now()
+	if err := as.articleRepo.CreateTip(tip); err != nil {
+		return articles.EcoTip{}, err
+	}
+	return tip, nil
+}
+
+func (as *ArticleService) DeleteTip(tipID string) error {
+	return as.articleRepo.DeleteTip(tipID)
+}
+
+// plainText strips the markup from rich text content.
+func plainText(content string) string {
+	text := html.UnescapeString(htmlTags.ReplaceAllString(content, " "))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// truncate cuts text to at most limit characters, at a word boundary when
+// there is one.
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	cut := string(runes[:limit-1])
+	if space := strings.LastIndex(cut, " "); space > 0 {
+		cut = cut[:space]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
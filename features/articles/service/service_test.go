@@ -0,0 +1,432 @@
+package service
+
+import (
+	"errors"
+	"greenenvironment/constant"
+	"greenenvironment/features/articles"
+	"greenenvironment/features/impacts"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockArticleRepository struct {
+	mock.Mock
+}
+
+func (m *MockArticleRepository) Create(article articles.Article) error {
+	args := m.Called(article)
+	return args.Error(0)
+}
+
+func (m *MockArticleRepository) Update(article articles.Article) error {
+	args := m.Called(article)
+	return args.Error(0)
+}
+
+func (m *MockArticleRepository) Delete(articleID string) error {
+	args := m.Called(articleID)
+	return args.Error(0)
+}
+
+func (m *MockArticleRepository) GetAll(filter articles.ArticleFilter) ([]articles.Article, int, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]articles.Article), args.Int(1), args.Error(2)
+}
+
+func (m *MockArticleRepository) GetByID(articleID string) (articles.Article, error) {
+	args := m.Called(articleID)
+	return args.Get(0).(articles.Article), args.Error(1)
+}
+
+func (m *MockArticleRepository) GetBySlug(slug string) (articles.Article, error) {
+	args := m.Called(slug)
+	return args.Get(0).(articles.Article), args.Error(1)
+}
+
+func (m *MockArticleRepository) SlugExists(slug string) (bool, error) {
+	args := m.Called(slug)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockArticleRepository) IncrementViews(articleID string) error {
+	args := m.Called(articleID)
+	return args.Error(0)
+}
+
+func (m *MockArticleRepository) PublishScheduled(now time.Time) error {
+	args := m.Called(now)
+	return args.Error(0)
+}
+
+func (m *MockArticleRepository) GetTips() ([]articles.EcoTip, error) {
+	args := m.Called()
+	return args.Get(0).([]articles.EcoTip), args.Error(1)
+}
+
+func (m *MockArticleRepository) CountTips() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockArticleRepository) GetTipAt(offset int) (articles.EcoTip, error) {
+	args := m.Called(offset)
+	return args.Get(0).(articles.EcoTip), args.Error(1)
+}
+
+func (m *MockArticleRepository) CreateTip(tip articles.EcoTip) error {
+	args := m.Called(tip)
+	return args.Error(0)
+}
+
+func (m *MockArticleRepository) DeleteTip(tipID string) error {
+	args := m.Called(tipID)
+	return args.Error(0)
+}
+
+type MockImpactRepo struct {
+	mock.Mock
+}
+
+func (m *MockImpactRepo) GetAll() ([]impacts.ImpactCategory, error) {
+	args := m.Called()
+	return args.Get(0).([]impacts.ImpactCategory), args.Error(1)
+}
+
+func (m *MockImpactRepo) GetByID(ID string) (impacts.ImpactCategory, error) {
+	args := m.Called(ID)
+	return args.Get(0).(impacts.ImpactCategory), args.Error(1)
+}
+
+func (m *MockImpactRepo) Create(category impacts.ImpactCategory) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockImpactRepo) Delete(category impacts.ImpactCategory) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockImpactRepo) GetUserPointsByCategory(userID string) ([]impacts.CategoryPoints, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]impacts.CategoryPoints), args.Error(1)
+}
+
+func (m *MockImpactRepo) GetUserMonthlyPoints(userID string, since time.Time) ([]impacts.CategoryPoints, error) {
+	args := m.Called(userID, since)
+	return args.Get(0).([]impacts.CategoryPoints), args.Error(1)
+}
+
+func (m *MockImpactRepo) GetConversionFactors(categoryID string) ([]impacts.ConversionFactor, error) {
+	args := m.Called(categoryID)
+	return args.Get(0).([]impacts.ConversionFactor), args.Error(1)
+}
+
+func (m *MockImpactRepo) SaveConversionFactors(categoryID string, factors []impacts.ConversionFactor) error {
+	args := m.Called(categoryID, factors)
+	return args.Error(0)
+}
+
+func (m *MockImpactRepo) Update(category impacts.ImpactCategory) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockImpactRepo) GetChildren(parentID string) ([]impacts.ImpactCategory, error) {
+	args := m.Called(parentID)
+	return args.Get(0).([]impacts.ImpactCategory), args.Error(1)
+}
+
+func (m *MockImpactRepo) GetUsage(categoryID string) (impacts.CategoryUsage, error) {
+	args := m.Called(categoryID)
+	return args.Get(0).(impacts.CategoryUsage), args.Error(1)
+}
+
+func (m *MockImpactRepo) ReassignAndDelete(categoryID, targetID string) error {
+	args := m.Called(categoryID, targetID)
+	return args.Error(0)
+}
+
+var testNow = time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+func newTestService(articleRepo *MockArticleRepository, impactRepo *MockImpactRepo) *ArticleService {
+	service := NewArticleService(articleRepo, impactRepo).(*ArticleService)
+	service.now = func() time.Time { return testNow }
+	return service
+}
+
+func TestCreate_Draft(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	impactRepo := new(MockImpactRepo)
+	service := newTestService(articleRepo, impactRepo)
+
+	impactRepo.On("GetByID", "impact1").Return(impacts.ImpactCategory{ID: "impact1"}, nil)
+	articleRepo.On("SlugExists", "why-compost-matters").Return(false, nil)
+	articleRepo.On("Create", mock.MatchedBy(func(article articles.Article) bool {
+		return article.ID != "" && article.Slug == "why-compost-matters" && article.AuthorID == "admin1" &&
+			article.Status == articles.StatusDraft && article.PublishedAt == nil &&
+			article.Summary == "Intro Compost turns scraps into soil & feeds your garden." &&
+			len(article.ImpactCategories) == 1 && article.ImpactCategories[0].ID != ""
+	})).Return(nil)
+	articleRepo.On("GetByID", mock.Anything).Return(articles.Article{ID: "article1"}, nil)
+
+	created, err := service.Create(articles.Article{
+		Title:    "  Why Compost Matters! ",
+		Content:  "<h2>Intro</h2><p>Compost turns scraps into soil &amp; feeds your garden.</p>",
+		AuthorID: "admin1",
+		ImpactCategories: []articles.ArticleImpactCategory{
+			{ImpactCategoryID: "impact1"},
+			{ImpactCategoryID: "impact1"},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "article1", created.ID)
+	articleRepo.AssertExpectations(t)
+}
+
+func TestCreate_PublishStatus(t *testing.T) {
+	future := testNow.Add(48 * time.Hour)
+	past := testNow.Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		publishedAt *time.Time
+		status      string
+		want        time.Time
+	}{
+		{"published now without a date", nil, articles.StatusPublished, testNow},
+		{"published with a past date", &past, articles.StatusPublished, past},
+		{"scheduled with a future date", &future, articles.StatusScheduled, future},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articleRepo := new(MockArticleRepository)
+			service := newTestService(articleRepo, new(MockImpactRepo))
+
+			articleRepo.On("SlugExists", "plastic-free-july").Return(false, nil)
+			articleRepo.On("Create", mock.MatchedBy(func(article articles.Article) bool {
+				return article.Status == tt.status && article.PublishedAt != nil && article.PublishedAt.Equal(tt.want)
+			})).Return(nil)
+			articleRepo.On("GetByID", mock.Anything).Return(articles.Article{}, nil)
+
+			_, err := service.Create(articles.Article{
+				Title:       "Plastic Free July",
+				Content:     "<p>Skip single-use plastic for a month.</p>",
+				Status:      articles.StatusPublished,
+				PublishedAt: tt.publishedAt,
+			})
+
+			assert.NoError(t, err)
+			articleRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreate_Invalid(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	impactRepo := new(MockImpactRepo)
+	service := newTestService(articleRepo, impactRepo)
+	impactRepo.On("GetByID", "missing").Return(impacts.ImpactCategory{}, constant.ErrImpactCategoryNotFound)
+
+	_, err := service.Create(articles.Article{Title: "Empty", Content: "<p> </p>"})
+	assert.Equal(t, constant.ErrArticleField, err)
+
+	_, err = service.Create(articles.Article{Title: "Status", Content: "Text", Status: "Archived"})
+	assert.Equal(t, constant.ErrInvalidArticleStatus, err)
+
+	_, err = service.Create(articles.Article{Title: "Category", Content: "Text",
+		ImpactCategories: []articles.ArticleImpactCategory{{ImpactCategoryID: "missing"}}})
+	assert.Equal(t, constant.ErrArticleImpactCategory, err)
+
+	articleRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCreate_SlugTaken(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	service := newTestService(articleRepo, new(MockImpactRepo))
+
+	articleRepo.On("SlugExists", "recycling-101").Return(true, nil)
+	articleRepo.On("Create", mock.MatchedBy(func(article articles.Article) bool {
+		return article.Slug == "recycling-101-"+article.ID[:8]
+	})).Return(nil)
+	articleRepo.On("GetByID", mock.Anything).Return(articles.Article{}, nil)
+
+	_, err := service.Create(articles.Article{Title: "Recycling 101", Content: "Sort your waste."})
+
+	assert.NoError(t, err)
+	articleRepo.AssertExpectations(t)
+}
+
+func TestCreate_LongSummary(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	service := newTestService(articleRepo, new(MockImpactRepo))
+	content := "<p>" + strings.Repeat("reuse ", 100) + "</p>"
+
+	articleRepo.On("SlugExists", mock.Anything).Return(false, nil)
+	articleRepo.On("Create", mock.MatchedBy(func(article articles.Article) bool {
+		return len([]rune(article.Summary)) <= articles.MaxSummaryLength &&
+			strings.HasSuffix(article.Summary, "reuse…")
+	})).Return(nil)
+	articleRepo.On("GetByID", mock.Anything).Return(articles.Article{}, nil)
+
+	_, err := service.Create(articles.Article{Title: "Reuse", Content: content})
+
+	assert.NoError(t, err)
+	articleRepo.AssertExpectations(t)
+}
+
+func TestUpdate_KeepsCoverAndSchedule(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	service := newTestService(articleRepo, new(MockImpactRepo))
+	scheduled := testNow.Add(24 * time.Hour)
+
+	articleRepo.On("GetByID", "article1").Return(articles.Article{
+		ID:          "article1",
+		Slug:        "old-title",
+		CoverImage:  "http://example.com/cover.png",
+		Status:      articles.StatusScheduled,
+		PublishedAt: &scheduled,
+	}, nil)
+	articleRepo.On("Update", mock.MatchedBy(func(article articles.Article) bool {
+		return article.ID == "article1" && article.Title == "New Title" &&
+			article.CoverImage == "http://example.com/cover.png" &&
+			article.Status == articles.StatusScheduled && article.PublishedAt.Equal(scheduled)
+	})).Return(nil)
+
+	_, err := service.Update(articles.Article{
+		ID:      "article1",
+		Title:   "New Title",
+		Content: "<p>Updated</p>",
+		Status:  articles.StatusPublished,
+	})
+
+	assert.NoError(t, err)
+	articleRepo.AssertExpectations(t)
+}
+
+func TestUpdate_NotFound(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	service := newTestService(articleRepo, new(MockImpactRepo))
+	articleRepo.On("GetByID", "missing").Return(articles.Article{}, constant.ErrArticleNotFound)
+
+	_, err := service.Update(articles.Article{ID: "missing", Title: "Title", Content: "Text"})
+
+	assert.Equal(t, constant.ErrArticleNotFound, err)
+	articleRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestGetPublished(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	service := newTestService(articleRepo, new(MockImpactRepo))
+
+	articleRepo.On("GetAll", articles.ArticleFilter{Page: 2, Search: "water", Status: articles.StatusPublished}).
+		Return([]articles.Article{{ID: "article1"}}, 3, nil)
+
+	result, totalPages, err := service.GetPublished(articles.ArticleFilter{Page: 2, Search: "water", Status: articles.StatusDraft})
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, 3, totalPages)
+}
+
+func TestGetBySlug(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	service := newTestService(articleRepo, new(MockImpactRepo))
+
+	articleRepo.On("GetBySlug", "save-water").Return(articles.Article{ID: "article1", Status: articles.StatusPublished, ViewCount: 4}, nil)
+	articleRepo.On("IncrementViews", "article1").Return(nil)
+
+	article, err := service.GetBySlug("save-water")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, article.ViewCount)
+	articleRepo.AssertExpectations(t)
+}
+
+func TestGetBySlug_ViewNotCounted(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	service := newTestService(articleRepo, new(MockImpactRepo))
+
+	articleRepo.On("GetBySlug", "save-water").Return(articles.Article{ID: "article1", Status: articles.StatusPublished, ViewCount: 4}, nil)
+	articleRepo.On("IncrementViews", "article1").Return(errors.New("db down"))
+
+	article, err := service.GetBySlug("save-water")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, article.ViewCount)
+}
+
+func TestGetBySlug_NotPublished(t *testing.T) {
+	for _, status := range []string{articles.StatusDraft, articles.StatusScheduled} {
+		articleRepo := new(MockArticleRepository)
+		service := newTestService(articleRepo, new(MockImpactRepo))
+		articleRepo.On("GetBySlug", "upcoming").Return(articles.Article{ID: "article1", Status: status}, nil)
+
+		_, err := service.GetBySlug("upcoming")
+
+		assert.Equal(t, constant.ErrArticleNotFound, err)
+		articleRepo.AssertNotCalled(t, "IncrementViews", mock.Anything)
+	}
+}
+
+func TestPublishScheduled(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	service := newTestService(articleRepo, new(MockImpactRepo))
+	articleRepo.On("PublishScheduled", testNow).Return(nil)
+
+	assert.NoError(t, service.PublishScheduled())
+	articleRepo.AssertExpectations(t)
+}
+
+func TestGetDailyTip(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	service := newTestService(articleRepo, new(MockImpactRepo))
+	articleRepo.On("CountTips").Return(7, nil)
+	articleRepo.On("GetTipAt", mock.Anything).Return(articles.EcoTip{ID: "tip"}, nil)
+
+	// 2024-06-01 09:00 UTC is 16:00 in Jakarta, 19875 days after the epoch.
+	_, err := service.GetDailyTip()
+	assert.NoError(t, err)
+
+	// 20:00 UTC is already the next day in Jakarta.
+	service.now = func() time.Time { return testNow.Add(11 * time.Hour) }
+	_, err = service.GetDailyTip()
+	assert.NoError(t, err)
+
+	articleRepo.AssertCalled(t, "GetTipAt", 19875%7)
+	articleRepo.AssertCalled(t, "GetTipAt", 19876%7)
+}
+
+func TestGetDailyTip_NoTips(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	service := newTestService(articleRepo, new(MockImpactRepo))
+	articleRepo.On("CountTips").Return(0, nil)
+
+	_, err := service.GetDailyTip()
+
+	assert.Equal(t, constant.ErrEcoTipNotFound, err)
+	articleRepo.AssertNotCalled(t, "GetTipAt", mock.Anything)
+}
+
+func TestCreateTip(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	service := newTestService(articleRepo, new(MockImpactRepo))
+	articleRepo.On("CreateTip", mock.MatchedBy(func(tip articles.EcoTip) bool {
+		return tip.ID != "" && tip.Content == "Carry a reusable straw."
+	})).Return(nil)
+
+	tip, err := service.CreateTip(articles.EcoTip{Content: "  Carry a reusable straw. "})
+
+	assert.NoError(t, err)
+	assert.Equal(t, testNow, tip.CreatedAt)
+
+	_, err = service.CreateTip(articles.EcoTip{Content: strings.Repeat("a", maxTipLength+1)})
+	assert.Equal(t, constant.ErrEcoTipField, err)
+	articleRepo.AssertNumberOfCalls(t, "CreateTip", 1)
+}
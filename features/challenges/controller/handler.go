@@ -41,6 +41,14 @@ func NewChallengeController(s challenges.ChallengeServiceInterface, j helper.JWT
 // @Param        duration_days    formData  int                  true   "Duration of the challenge in days"
 // @Param        exp              formData  int                  true   "Experience points awarded for completing the challenge"
 // @Param        coin             formData  int                  true   "Coins awarded for completing the challenge"
+// @Param        team_target      formData  int                  false  "Collective action target for team participation (0 disables teams)"
+// @Param        requires_review  formData  bool                 false  "Send task proofs to the admin review queue"
+// @Param        status           formData  string               false  "Draft (default) or Published"
+// @Param        start_at         formData  string               false  "Scheduled start time (RFC3339)"
+// @Param        end_at           formData  string               false  "Event end time (RFC3339)"
+// @Param        enrollment_cap   formData  int                  false  "Maximum number of participants (0 for unlimited)"
+// @Param        grace_period_hours formData int                 false  "Extra hours after a task's local day ends before it fails (max 72)"
+// @Param        season_id        formData  string               false  "Season the challenge belongs to"
 // @Param        impact_categories formData []string                true   "List of impact category IDs"
 // @Success      201  {object}    helper.Response{data=string}   "Challenge created successfully"
 // @Failure      400  {object}    helper.Response{data=string}   "Bad request"
@@ -89,15 +97,28 @@ func (h *ChallengeHandler) Create(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
 	}
 
+	schedule, err := parseChallengeSchedule(challengeRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
 	challenge := challenges.Challenge{
-		Author:       adminId,
-		Title:        challengeRequest.Title,
-		Difficulty:   challengeRequest.Difficulty,
-		ChallengeImg: challengeImgURL,
-		Description:  challengeRequest.Description,
-		DurationDays: challengeRequest.DurationDays,
-		Exp:          challengeRequest.Exp,
-		Coin:         challengeRequest.Coin,
+		Author:           adminId,
+		Title:            challengeRequest.Title,
+		Difficulty:       challengeRequest.Difficulty,
+		ChallengeImg:     challengeImgURL,
+		Description:      challengeRequest.Description,
+		DurationDays:     challengeRequest.DurationDays,
+		Exp:              challengeRequest.Exp,
+		Coin:             challengeRequest.Coin,
+		TeamTarget:       challengeRequest.TeamTarget,
+		RequiresReview:   challengeRequest.RequiresReview,
+		Status:           challengeRequest.Status,
+		StartAt:          schedule.StartAt,
+		EndAt:            schedule.EndAt,
+		EnrollmentCap:    challengeRequest.EnrollmentCap,
+		GracePeriodHours: challengeRequest.GracePeriodHours,
+		SeasonID:         schedule.SeasonID,
 	}
 
 	for _, category := range challengeRequest.ImpactCategories {
@@ -261,15 +282,30 @@ func (h *ChallengeHandler) Update(c echo.Context) error {
 		}
 	}
 
+	schedule, err := parseChallengeSchedule(challengeRequest)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
 	challenge := challenges.Challenge{
-		ID:           challengeID,
-		Title:        challengeRequest.Title,
-		Difficulty:   challengeRequest.Difficulty,
-		ChallengeImg: challengeImgURL,
-		Description:  challengeRequest.Description,
-		DurationDays: challengeRequest.DurationDays,
-		Exp:          challengeRequest.Exp,
-		Coin:         challengeRequest.Coin,
+		ID:               challengeID,
+		Title:            challengeRequest.Title,
+		Difficulty:       challengeRequest.Difficulty,
+		ChallengeImg:     challengeImgURL,
+		Description:      challengeRequest.Description,
+		DurationDays:     challengeRequest.DurationDays,
+		Exp:              challengeRequest.Exp,
+		Coin:             challengeRequest.Coin,
+		TeamTarget:       challengeRequest.TeamTarget,
+		RequiresReview:   challengeRequest.RequiresReview,
+		Status:           challengeRequest.Status,
+		StartAt:          schedule.StartAt,
+		EndAt:            schedule.EndAt,
+		ClearStartAt:     schedule.ClearStartAt,
+		ClearEndAt:       schedule.ClearEndAt,
+		EnrollmentCap:    challengeRequest.EnrollmentCap,
+		GracePeriodHours: challengeRequest.GracePeriodHours,
+		SeasonID:         schedule.SeasonID,
 	}
 
 	for _, category := range challengeRequest.ImpactCategories {
@@ -597,7 +633,7 @@ func (h *ChallengeHandler) CreateChallengeLog(c echo.Context) error {
 
 // UpdateChallengeConfirmationProgress
 // @Summary      Update the progress of a challenge confirmation
-// @Description  Updates the status of a challenge confirmation to "Done" and uploads the confirmation image
+// @Description  Updates the status of a challenge confirmation to "Done" and uploads the confirmation image. The confirmation goes to "Pending Review" instead when the challenge requires review or proof photos are checked by the vision model, which approves the ones it is confident about.
 // @Tags         Challenge Confirmations
 // @Accept       multipart/form-data
 // @Produce      json
@@ -702,6 +738,9 @@ func (h *ChallengeHandler) ClaimRewards(c echo.Context) error {
 		if errors.Is(err, constant.ErrRewardAlreadyClaimed) {
 			return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "Reward already claimed", nil))
 		}
+		if errors.Is(err, constant.ErrUnauthorized) {
+			return helper.UnauthorizedError(c)
+		}
 		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
 	}
 
@@ -907,6 +946,11 @@ func (h *ChallengeHandler) GetChallengeDetailsWithConfirmations(c echo.Context)
 	}
 
 	for _, confirmation := range details.Confirmations {
+		var deadline *time.Time
+		if !confirmation.Deadline.IsZero() {
+			deadline = &confirmation.Deadline
+		}
+
 		response.ChallengeConfirmation = append(response.ChallengeConfirmation, ChallengeConfirmationResponse{
 			ID:     confirmation.ID,
 			UserID: confirmation.UserID,
@@ -918,7 +962,11 @@ func (h *ChallengeHandler) GetChallengeDetailsWithConfirmations(c echo.Context)
 				DayNumber:       confirmation.ChallengeTask.DayNumber,
 				TaskDescription: confirmation.ChallengeTask.TaskDescription,
 			},
-			SubmissionDate: confirmation.SubmissionDate,
+			ChallengeImg:    confirmation.ChallengeImg,
+			SubmissionDate:  confirmation.SubmissionDate,
+			Deadline:        deadline,
+			RejectionReason: confirmation.RejectionReason,
+			ReviewedAt:      confirmation.ReviewedAt,
 		})
 	}
 
@@ -953,3 +1001,491 @@ func (h *ChallengeHandler) GetChallengeDetails(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Challenge details retrieved successfully", details))
 }
+
+// GetPendingConfirmations lists task proofs waiting for review
+// @Summary      Get pending confirmations
+// @Description  Retrieve challenge task confirmations waiting for admin review, oldest first. When proof verification is on, verification holds the model's confidence and explanation; proofs it is still judging have status Pending. Requires admin role.
+// @Tags         Challenges (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        pages          query     int     false  "Page number"
+// @Success      200  {object}  helper.MetadataResponse{data=[]PendingConfirmationResponse} "Pending confirmations retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/challenges/confirmations/pending [get]
+func (h *ChallengeHandler) GetPendingConfirmations(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwt.ExtractUserToken(token)
+	adminRole := adminData[constant.JWT_ROLE].(string)
+	if adminRole != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	page, err := strconv.Atoi(c.QueryParam("pages"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	confirmations, totalPages, err := h.challengeService.GetPendingConfirmations(page)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []PendingConfirmationResponse{}
+	for _, confirmation := range confirmations {
+		response = append(response, new(PendingConfirmationResponse).ToResponse(confirmation))
+	}
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "Pending confirmations retrieved successfully", metadata, response))
+}
+
+// ReviewChallengeConfirmation approves or rejects a task proof
+// @Summary      Review a confirmation
+// @Description  Approve or reject a challenge task confirmation that is pending review. A reason is required when rejecting. Requires admin role.
+// @Tags         Challenges (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string                     true  "Bearer Token"
+// @Param        id             path      string                     true  "Challenge confirmation ID"
+// @Param        request        body      ReviewConfirmationRequest  true  "Review action (approve or reject) and reason"
+// @Success      200  {object}  helper.Response{data=string} "Confirmation reviewed successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Bad request"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Confirmation not found"
+// @Failure      409  {object}  helper.Response{data=string} "Confirmation is not pending review"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/challenges/confirmations/{id}/review [put]
+func (h *ChallengeHandler) ReviewChallengeConfirmation(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwt.ExtractUserToken(token)
+	adminRole := adminData[constant.JWT_ROLE].(string)
+	if adminRole != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+	adminID := adminData[constant.JWT_ID].(string)
+
+	var req ReviewConfirmationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "Invalid request format", nil))
+	}
+
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	err = h.challengeService.ReviewChallengeConfirmation(c.Param("id"), adminID, req.Action, req.Reason)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Confirmation reviewed successfully", nil))
+}
+
+// parseChallengeSchedule converts the optional schedule fields of a challenge
+// form into the pointers stored on the challenge.
+func parseChallengeSchedule(req ChallengeRequest) (challenges.Challenge, error) {
+	schedule := challenges.Challenge{
+		ClearStartAt: req.ClearStartAt,
+		ClearEndAt:   req.ClearEndAt,
+	}
+
+	if req.ClearStartAt && req.StartAt != "" {
+		return schedule, errors.New("start_at cannot be set and cleared at once")
+	}
+	if req.ClearEndAt && req.EndAt != "" {
+		return schedule, errors.New("end_at cannot be set and cleared at once")
+	}
+
+	if req.StartAt != "" {
+		startAt, err := time.Parse(time.RFC3339, req.StartAt)
+		if err != nil {
+			return schedule, errors.New("start_at must be in RFC3339 format")
+		}
+		schedule.StartAt = &startAt
+	}
+
+	if req.EndAt != "" {
+		endAt, err := time.Parse(time.RFC3339, req.EndAt)
+		if err != nil {
+			return schedule, errors.New("end_at must be in RFC3339 format")
+		}
+		schedule.EndAt = &endAt
+	}
+
+	if req.SeasonID != "" {
+		seasonID := req.SeasonID
+		schedule.SeasonID = &seasonID
+	}
+
+	return schedule, nil
+}
+
+// PublishChallenge opens a challenge for enrollment
+// @Summary      Publish a challenge
+// @Description  Publish a challenge. A challenge with a future start date becomes Scheduled and opens automatically. Requires admin role.
+// @Tags         Challenges (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Challenge ID"
+// @Success      200  {object}  helper.Response{data=string} "Challenge published successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Challenge event has already ended"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/challenges/{id}/publish [put]
+func (h *ChallengeHandler) PublishChallenge(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwt.ExtractUserToken(token)
+	adminRole := adminData[constant.JWT_ROLE].(string)
+	if adminRole != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	err = h.challengeService.PublishChallenge(c.Param("id"))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Challenge published successfully", nil))
+}
+
+// UnpublishChallenge moves a challenge back to draft
+// @Summary      Unpublish a challenge
+// @Description  Move a challenge back to Draft so it is hidden from users. Requires admin role.
+// @Tags         Challenges (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Challenge ID"
+// @Success      200  {object}  helper.Response{data=string} "Challenge unpublished successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Challenge event has already ended"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/challenges/{id}/unpublish [put]
+func (h *ChallengeHandler) UnpublishChallenge(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwt.ExtractUserToken(token)
+	adminRole := adminData[constant.JWT_ROLE].(string)
+	if adminRole != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	err = h.challengeService.UnpublishChallenge(c.Param("id"))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Challenge unpublished successfully", nil))
+}
+
+// CreateSeason creates a seasonal event grouping
+// @Summary      Create a challenge season
+// @Description  Create a season that groups limited-time challenges and multiplies their rewards. Requires admin role.
+// @Tags         Challenges (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string                  true  "Bearer Token"
+// @Param        request        body      ChallengeSeasonRequest  true  "Season data"
+// @Success      201  {object}  helper.Response{data=ChallengeSeasonResponse} "Season created successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Bad request"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/challenges/seasons [post]
+func (h *ChallengeHandler) CreateSeason(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwt.ExtractUserToken(token)
+	adminRole := adminData[constant.JWT_ROLE].(string)
+	if adminRole != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	var req ChallengeSeasonRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "Invalid request format", nil))
+	}
+
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	season, err := h.challengeService.CreateSeason(challenges.ChallengeSeason{
+		Name:            req.Name,
+		Description:     req.Description,
+		StartAt:         req.StartAt,
+		EndAt:           req.EndAt,
+		BonusMultiplier: req.BonusMultiplier,
+	})
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusCreated, helper.FormatResponse(true, "Season created successfully", new(ChallengeSeasonResponse).ToResponse(season)))
+}
+
+// GetAllSeasons lists challenge seasons
+// @Summary      Get all challenge seasons
+// @Description  Retrieve all challenge seasons, newest first. Requires admin role.
+// @Tags         Challenges (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Success      200  {object}  helper.Response{data=[]ChallengeSeasonResponse} "Seasons retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/challenges/seasons [get]
+func (h *ChallengeHandler) GetAllSeasons(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwt.ExtractUserToken(token)
+	adminRole := adminData[constant.JWT_ROLE].(string)
+	if adminRole != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	seasons, err := h.challengeService.GetAllSeasons()
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []ChallengeSeasonResponse{}
+	for _, season := range seasons {
+		response = append(response, new(ChallengeSeasonResponse).ToResponse(season))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Seasons retrieved successfully", response))
+}
+
+// GetSeasonByID retrieves a challenge season
+// @Summary      Get a challenge season
+// @Description  Retrieve a challenge season by its ID. Requires admin role.
+// @Tags         Challenges (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Season ID"
+// @Success      200  {object}  helper.Response{data=ChallengeSeasonResponse} "Season retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Season not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/challenges/seasons/{id} [get]
+func (h *ChallengeHandler) GetSeasonByID(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwt.ExtractUserToken(token)
+	adminRole := adminData[constant.JWT_ROLE].(string)
+	if adminRole != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	season, err := h.challengeService.GetSeasonByID(c.Param("id"))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Season retrieved successfully", new(ChallengeSeasonResponse).ToResponse(season)))
+}
+
+// UpdateSeason updates a challenge season
+// @Summary      Update a challenge season
+// @Description  Update the name, window or bonus multiplier of a season. Requires admin role.
+// @Tags         Challenges (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string                  true  "Bearer Token"
+// @Param        id             path      string                  true  "Season ID"
+// @Param        request        body      ChallengeSeasonRequest  true  "Season data"
+// @Success      200  {object}  helper.Response{data=string} "Season updated successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Bad request"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Season not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/challenges/seasons/{id} [put]
+func (h *ChallengeHandler) UpdateSeason(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwt.ExtractUserToken(token)
+	adminRole := adminData[constant.JWT_ROLE].(string)
+	if adminRole != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	var req ChallengeSeasonRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "Invalid request format", nil))
+	}
+
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	err = h.challengeService.UpdateSeason(challenges.ChallengeSeason{
+		ID:              c.Param("id"),
+		Name:            req.Name,
+		Description:     req.Description,
+		StartAt:         req.StartAt,
+		EndAt:           req.EndAt,
+		BonusMultiplier: req.BonusMultiplier,
+	})
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Season updated successfully", nil))
+}
+
+// DeleteSeason deletes a challenge season
+// @Summary      Delete a challenge season
+// @Description  Delete a season. Challenges in the season are kept and detached from it. Requires admin role.
+// @Tags         Challenges (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Season ID"
+// @Success      200  {object}  helper.Response{data=string} "Season deleted successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Season not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/challenges/seasons/{id} [delete]
+func (h *ChallengeHandler) DeleteSeason(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwt.ExtractUserToken(token)
+	adminRole := adminData[constant.JWT_ROLE].(string)
+	if adminRole != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	err = h.challengeService.DeleteSeason(c.Param("id"))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Season deleted successfully", nil))
+}
+
+// GetRecommendedChallenges returns personalized challenge suggestions
+// @Summary      Get recommended challenges
+// @Description  Suggest open challenges based on the user's completed and failed challenges, purchases and EXP. Challenges locked by difficulty progression are left out.
+// @Tags         Challenges (User)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        limit          query     int     false  "Maximum number of suggestions (default 10)"
+// @Success      200  {object}  helper.Response{data=[]ChallengeRecommendationResponse} "Recommended challenges retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /challenges/recommendations [get]
+func (h *ChallengeHandler) GetRecommendedChallenges(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	userData := h.jwt.ExtractUserToken(token)
+	userID := userData[constant.JWT_ID].(string)
+
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	recommendations, err := h.challengeService.GetRecommendedChallenges(userID, limit)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []ChallengeRecommendationResponse{}
+	for _, recommendation := range recommendations {
+		response = append(response, new(ChallengeRecommendationResponse).ToResponse(recommendation))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Recommended challenges retrieved successfully", response))
+}
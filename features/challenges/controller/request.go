@@ -1,5 +1,7 @@
 package controller
 
+import "time"
+
 type ChallengeRequest struct {
 	Title            string   `form:"title" validate:"required"`
 	Difficulty       string   `form:"difficulty" validate:"required"`
@@ -7,6 +9,16 @@ type ChallengeRequest struct {
 	DurationDays     int      `form:"duration_days" validate:"required"`
 	Exp              int      `form:"exp" validate:"required"`
 	Coin             int      `form:"coin" validate:"required"`
+	TeamTarget       int      `form:"team_target" validate:"min=0"`
+	RequiresReview   bool     `form:"requires_review"`
+	Status           string   `form:"status"`
+	StartAt          string   `form:"start_at"`
+	EndAt            string   `form:"end_at"`
+	ClearStartAt     bool     `form:"clear_start_at"`
+	ClearEndAt       bool     `form:"clear_end_at"`
+	EnrollmentCap    int      `form:"enrollment_cap" validate:"min=0"`
+	GracePeriodHours int      `form:"grace_period_hours" validate:"min=0,max=72"`
+	SeasonID         string   `form:"season_id"`
 	ImpactCategories []string `form:"category_impact" validate:"required"`
 }
 
@@ -29,3 +41,16 @@ type ChallengeConfirmationRequest struct {
 type ClaimRewardsRequest struct {
 	ChallengeLogID string `json:"challenge_log_id" validate:"required"`
 }
+
+type ReviewConfirmationRequest struct {
+	Action string `json:"action" validate:"required"`
+	Reason string `json:"reason"`
+}
+
+type ChallengeSeasonRequest struct {
+	Name            string    `json:"name" validate:"required"`
+	Description     string    `json:"description"`
+	StartAt         time.Time `json:"start_at" validate:"required"`
+	EndAt           time.Time `json:"end_at" validate:"required"`
+	BonusMultiplier float64   `json:"bonus_multiplier" validate:"required"`
+}
@@ -15,19 +15,27 @@ type ChallengeResponse struct {
 	DurationDays     int                         `json:"duration_days"`
 	Exp              int                         `json:"exp"`
 	Coin             int                         `json:"coin"`
+	TeamTarget       int                         `json:"team_target"`
+	RequiresReview   bool                        `json:"requires_review"`
+	Status           string                      `json:"status"`
+	StartAt          *time.Time                  `json:"start_at"`
+	EndAt            *time.Time                  `json:"end_at"`
+	EnrollmentCap    int                         `json:"enrollment_cap"`
+	GracePeriodHours int                         `json:"grace_period_hours"`
+	SeasonID         *string                     `json:"season_id"`
 	ImpactCategories []ChallengeImpactCategories `json:"categories"`
 	DeletedAt        *string                     `json:"deleted_at"`
 }
 
 type ChallengeLogResponse struct {
-	ID                      string                        `json:"id"`
-	Challenge               ChallengeResponse             `json:"challenge"`
-	UserID                  string                        `json:"user_id"`
-	RewardsGiven            bool                          `json:"rewards_given"`
-	Status                  string                        `json:"status"`
-	StartDate               time.Time                     `json:"start_date"`
-	Feed                    string                        `json:"feed"`
-	ChallengeConfirmation   []ChallengeConfirmationResponse `json:"challenge_confirmation"`
+	ID                    string                          `json:"id"`
+	Challenge             ChallengeResponse               `json:"challenge"`
+	UserID                string                          `json:"user_id"`
+	RewardsGiven          bool                            `json:"rewards_given"`
+	Status                string                          `json:"status"`
+	StartDate             time.Time                       `json:"start_date"`
+	Feed                  string                          `json:"feed"`
+	ChallengeConfirmation []ChallengeConfirmationResponse `json:"challenge_confirmation"`
 }
 
 type ChallengeConfirmationResponse struct {
@@ -35,7 +43,31 @@ type ChallengeConfirmationResponse struct {
 	ChallengeTask   ChallengeTaskResponse `json:"challenge_task"`
 	UserID          string                `json:"user_id"`
 	Status          string                `json:"status"`
+	ChallengeImg    string                `json:"challenge_img"`
 	SubmissionDate  time.Time             `json:"submission_date"`
+	Deadline        *time.Time            `json:"deadline"`
+	RejectionReason string                `json:"rejection_reason"`
+	ReviewedAt      *time.Time            `json:"reviewed_at"`
+}
+
+type PendingConfirmationResponse struct {
+	ID             string                `json:"id"`
+	UserID         string                `json:"user_id"`
+	Username       string                `json:"username"`
+	ChallengeTitle string                `json:"challenge_title"`
+	ChallengeTask  ChallengeTaskResponse `json:"challenge_task"`
+	ChallengeImg   string                `json:"challenge_img"`
+	SubmissionDate time.Time             `json:"submission_date"`
+	Verification   *VerificationResponse `json:"verification"`
+}
+
+// VerificationResponse is what the vision model made of a proof. It is null
+// for proofs submitted while verification was off.
+type VerificationResponse struct {
+	Status      string     `json:"status"`
+	Confidence  float64    `json:"confidence"`
+	Explanation string     `json:"explanation"`
+	VerifiedAt  *time.Time `json:"verified_at"`
 }
 
 type ChallengeImpactCategories struct {
@@ -81,6 +113,14 @@ func (cr ChallengeResponse) ToResponse(challenge challenges.Challenge) Challenge
 		DurationDays:     challenge.DurationDays,
 		Exp:              challenge.Exp,
 		Coin:             challenge.Coin,
+		TeamTarget:       challenge.TeamTarget,
+		RequiresReview:   challenge.RequiresReview,
+		Status:           challenge.Status,
+		StartAt:          challenge.StartAt,
+		EndAt:            challenge.EndAt,
+		EnrollmentCap:    challenge.EnrollmentCap,
+		GracePeriodHours: challenge.GracePeriodHours,
+		SeasonID:         challenge.SeasonID,
 		ImpactCategories: impactCategories,
 		DeletedAt:        deletedAt,
 	}
@@ -107,3 +147,69 @@ func (ctr ChallengeTaskResponse) FromEntity(task challenges.ChallengeTask) Chall
 		UpdatedAt:       task.UpdatedAt.Format("2006-01-02 15:04:05"),
 	}
 }
+
+func (pcr PendingConfirmationResponse) ToResponse(confirmation challenges.ChallengeConfirmation) PendingConfirmationResponse {
+	response := PendingConfirmationResponse{
+		ID:             confirmation.ID,
+		UserID:         confirmation.UserID,
+		Username:       confirmation.Username,
+		ChallengeTitle: confirmation.ChallengeTitle,
+		ChallengeTask: ChallengeTaskResponse{
+			ID:              confirmation.ChallengeTask.ID,
+			ChallengeID:     confirmation.ChallengeTask.ChallengeID,
+			Name:            confirmation.ChallengeTask.Name,
+			DayNumber:       confirmation.ChallengeTask.DayNumber,
+			TaskDescription: confirmation.ChallengeTask.TaskDescription,
+		},
+		ChallengeImg:   confirmation.ChallengeImg,
+		SubmissionDate: confirmation.SubmissionDate,
+	}
+	if confirmation.Verification.Status != "" {
+		response.Verification = &VerificationResponse{
+			Status:      confirmation.Verification.Status,
+			Confidence:  confirmation.Verification.Confidence,
+			Explanation: confirmation.Verification.Explanation,
+			VerifiedAt:  confirmation.Verification.VerifiedAt,
+		}
+	}
+	return response
+}
+
+type ChallengeSeasonResponse struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	StartAt         time.Time `json:"start_at"`
+	EndAt           time.Time `json:"end_at"`
+	BonusMultiplier float64   `json:"bonus_multiplier"`
+}
+
+func (csr ChallengeSeasonResponse) ToResponse(season challenges.ChallengeSeason) ChallengeSeasonResponse {
+	return ChallengeSeasonResponse{
+		ID:              season.ID,
+		Name:            season.Name,
+		Description:     season.Description,
+		StartAt:         season.StartAt,
+		EndAt:           season.EndAt,
+		BonusMultiplier: season.BonusMultiplier,
+	}
+}
+
+type ChallengeRecommendationResponse struct {
+	Challenge ChallengeResponse `json:"challenge"`
+	Score     float64           `json:"score"`
+	Reasons   []string          `json:"reasons"`
+}
+
+func (crr ChallengeRecommendationResponse) ToResponse(recommendation challenges.ChallengeRecommendation) ChallengeRecommendationResponse {
+	reasons := recommendation.Reasons
+	if reasons == nil {
+		reasons = []string{}
+	}
+
+	return ChallengeRecommendationResponse{
+		Challenge: new(ChallengeResponse).ToResponse(recommendation.Challenge),
+		Score:     recommendation.Score,
+		Reasons:   reasons,
+	}
+}
@@ -1,12 +1,30 @@
 package challenges
 
 import (
+	"context"
 	impactcategory "greenenvironment/features/impacts"
 	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+// Proof verification states. A Pending proof waits for the model; the model
+// then Approved it, Flagged it for an admin, or could not judge it (Error)
+// so an admin has to.
+const (
+	VerificationPending  = "Pending"
+	VerificationApproved = "Approved"
+	VerificationFlagged  = "Flagged"
+	VerificationError    = "Error"
+)
+
+// VerifierReviewer is recorded as the reviewer of proofs the model approved.
+const VerifierReviewer = "ai-verifier"
+
+// MaxVerificationAttempts is how often a proof is sent to the model before
+// it is left to an admin.
+const MaxVerificationAttempts = 3
+
 type Challenge struct {
 	ID               string
 	Author           string
@@ -19,12 +37,35 @@ type Challenge struct {
 	Coin             int
 	ActionCount      int
 	ParticipantCount int
+	TeamTarget       int
+	RequiresReview   bool
+	Status           string
+	StartAt          *time.Time
+	EndAt            *time.Time
+	EnrollmentCap    int
+	GracePeriodHours int
+	SeasonID         *string
+	// ClearStartAt and ClearEndAt remove the stored schedule on Update,
+	// where a nil StartAt or EndAt keeps it.
+	ClearStartAt     bool
+	ClearEndAt       bool
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 	ImpactCategories []ChallengeImpactCategory
 	DeletedAt        *time.Time
 }
 
+type ChallengeSeason struct {
+	ID              string
+	Name            string
+	Description     string
+	StartAt         time.Time
+	EndAt           time.Time
+	BonusMultiplier float64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
 type ChallengeImpactCategory struct {
 	ID               string
 	ChallengeID      string
@@ -66,10 +107,46 @@ type ChallengeConfirmation struct {
 	ID              string
 	ChallengeTaskID string
 	UserID          string
+	Username        string
 	Status          string
 	ChallengeImg    string
 	SubmissionDate  time.Time
+	Deadline        time.Time
+	RejectionReason string
+	ReviewedBy      string
+	ReviewedAt      *time.Time
+	ChallengeTitle  string
 	ChallengeTask   ChallengeTask
+	Verification    ProofVerification
+}
+
+// UndatedTask is a task enrolled before deadlines were stored, with what is
+// needed to work its deadline out.
+type UndatedTask struct {
+	ConfirmationID   string
+	StartDate        time.Time
+	DayNumber        int
+	GracePeriodHours int
+}
+
+// ProofVerification is what the vision model made of a proof photo.
+// Confidence, from 0 to 1, is how sure it is the photo shows the task done.
+type ProofVerification struct {
+	Status      string
+	Confidence  float64
+	Explanation string
+	Attempts    int
+	VerifiedAt  *time.Time
+}
+
+// ProofVerifier judges whether a photo shows a challenge task being done.
+type ProofVerifier interface {
+	Verify(ctx context.Context, imageURL string, task ChallengeTask) (ProofVerdict, error)
+}
+
+type ProofVerdict struct {
+	Confidence  float64
+	Explanation string
 }
 
 type ChallengeLogDetails struct {
@@ -91,6 +168,12 @@ type ChallengeDetails struct {
 	Tasks            []ChallengeTask
 }
 
+type ChallengeRecommendation struct {
+	Challenge Challenge
+	Score     float64
+	Reasons   []string
+}
+
 type ChallengeWithCounts struct {
 	Challenge
 	ActionCount      int
@@ -114,6 +197,8 @@ type ChallengeRepoInterface interface {
 	CreateChallengeLog(ChallengeLog) error
 	CreateChallengeConfirmation(ChallengeConfirmation) error
 	IsChallengeTaken(userID, challengeID string) (bool, error)
+	ReserveChallengeSeat(challengeID string) (bool, error)
+	ReleaseChallengeSeat(challengeID string) error
 	IncrementChallengeCounts(challengeID string, actionCount int, participantIncrement bool) error
 	GetChallengeConfirmationByID(confirmationID string) (ChallengeConfirmation, error)
 	UpdateChallengeConfirmation(ChallengeConfirmation) error
@@ -121,7 +206,14 @@ type ChallengeRepoInterface interface {
 	GetChallengeLogByChallengeIDAndUserID(challengeID, userID string) (ChallengeLog, error)
 	GetConfirmationsByChallengeID(challengeID, userID string) ([]ChallengeConfirmation, error)
 	UpdateChallengeLog(log ChallengeLog) error
-	UpdateTaskAndChallengeStatus() error
+	RecordChallengeImpact(challengeLogID string) error
+	GetUserTimezone(userID string) (string, error)
+	GetUserNameAndEmail(userID string) (string, string, error)
+	GetUndatedTasks() ([]UndatedTask, error)
+	SetTaskDeadline(confirmationID string, deadline time.Time) error
+	FailOverdueTasks(now time.Time) (int, error)
+	GetUpcomingDeadlines(now, until time.Time) ([]ChallengeConfirmation, error)
+	MarkDeadlinesReminded(confirmationIDs []string, remindedAt time.Time) error
 
 	IsRewardClaimed(challengeLogID string) (bool, error)
 	UpdateRewardsGiven(challengeLogID string) error
@@ -134,6 +226,33 @@ type ChallengeRepoInterface interface {
 	GetChallengeLogByID(challengeLogID string) (ChallengeLog, error)
 	GetChallengeByID(challengeID string) (Challenge, error)
 	GetTasksByChallengeIDforUser(challengeID string) ([]ChallengeTask, error)
+
+	// Review
+	GetPendingConfirmations(page, perPage int) ([]ChallengeConfirmation, int, error)
+	UpdateConfirmationReview(confirmation ChallengeConfirmation) error
+	GetUnverifiedConfirmations(limit int) ([]ChallengeConfirmation, error)
+	UpdateConfirmationVerification(confirmationID string, verification ProofVerification) error
+
+	// Publishing
+	UpdateChallengeStatus(challengeID, status string) error
+	PublishScheduledChallenges(now time.Time) error
+	EndExpiredChallenges(now time.Time) ([]Challenge, error)
+	GetEnrolledUserEmails(challengeID string) ([]string, error)
+
+	// Season
+	CreateSeason(season ChallengeSeason) error
+	GetAllSeasons() ([]ChallengeSeason, error)
+	GetSeasonByID(seasonID string) (ChallengeSeason, error)
+	UpdateSeason(season ChallengeSeason) error
+	DeleteSeason(seasonID string) error
+	GetSeasonMultiplier(challengeID string) (float64, error)
+
+	// Recommendation
+	GetChallengeHistory(userID string) ([]ChallengeLog, error)
+	GetAvailableChallenges(userID string) ([]Challenge, error)
+	GetPurchasedCategoryCounts(userID string) (map[string]int, error)
+	GetUserExp(userID string) (int, error)
+	GetChallengeCategoryIDs(challengeID string) ([]string, error)
 }
 
 type ChallengeServiceInterface interface {
@@ -154,11 +273,33 @@ type ChallengeServiceInterface interface {
 	UpdateChallengeConfirmationProgress(confirmationID, challengeImgURL, userID string) error
 	CheckAndUpdateChallengeLogStatusByConfirmation(confirmationID, userID string) error
 	ClaimRewards(challengeLogID, userID string) error
+	SweepOverdueTasks() error
+	RemindUpcomingDeadlines() error
 
 	GetActiveChallenges(userID string, page, perPage int, difficulty, title string) ([]ChallengeLog, int, error)
 	GetUnclaimedChallenges(userID string, isAdmin bool, page, limit int, difficulty, title string) ([]Challenge, int, error)
 	GetChallengeDetailsWithConfirmations(userID, challengeLogID string) (ChallengeLogDetails, error)
 	GetChallengeDetails(challengeID string) (ChallengeDetails, error)
+
+	// Review
+	GetPendingConfirmations(page int) ([]ChallengeConfirmation, int, error)
+	ReviewChallengeConfirmation(confirmationID, reviewerID, action, reason string) error
+	VerifyPendingProofs() error
+
+	// Publishing
+	PublishChallenge(challengeID string) error
+	UnpublishChallenge(challengeID string) error
+	SyncChallengeSchedules() error
+
+	// Season
+	CreateSeason(season ChallengeSeason) (ChallengeSeason, error)
+	GetAllSeasons() ([]ChallengeSeason, error)
+	GetSeasonByID(seasonID string) (ChallengeSeason, error)
+	UpdateSeason(season ChallengeSeason) error
+	DeleteSeason(seasonID string) error
+
+	// Recommendation
+	GetRecommendedChallenges(userID string, limit int) ([]ChallengeRecommendation, error)
 }
 
 type ChallengeControllerInterface interface {
@@ -183,4 +324,22 @@ type ChallengeControllerInterface interface {
 	GetUnclaimedChallenges(c echo.Context) error
 	GetChallengeDetailsWithConfirmations(c echo.Context) error
 	GetChallengeDetails(c echo.Context) error
+
+	// Review
+	GetPendingConfirmations(c echo.Context) error
+	ReviewChallengeConfirmation(c echo.Context) error
+
+	// Publishing
+	PublishChallenge(c echo.Context) error
+	UnpublishChallenge(c echo.Context) error
+
+	// Season
+	CreateSeason(c echo.Context) error
+	GetAllSeasons(c echo.Context) error
+	GetSeasonByID(c echo.Context) error
+	UpdateSeason(c echo.Context) error
+	DeleteSeason(c echo.Context) error
+
+	// Recommendation
+	GetRecommendedChallenges(c echo.Context) error
 }
@@ -22,6 +22,15 @@ type Challenge struct {
 	Coin             int                       `gorm:"type:int;not null;column:coin"`
 	ActionCount      int                       `gorm:"type:int;not null;default:0;column:action_count"`
 	ParticipantCount int                       `gorm:"type:int;not null;default:0;column:participant_count"`
+	TeamTarget       int                       `gorm:"type:int;not null;default:0;column:team_target"`
+	RequiresReview   bool                      `gorm:"type:boolean;default:false;column:requires_review"`
+	Status           string                    `gorm:"type:enum('Draft','Scheduled','Published','Ended');not null;default:'Published';column:status"`
+	StartAt          *time.Time                `gorm:"type:datetime;column:start_at"`
+	EndAt            *time.Time                `gorm:"type:datetime;column:end_at"`
+	EnrollmentCap    int                       `gorm:"type:int;not null;default:0;column:enrollment_cap"`
+	GracePeriodHours int                       `gorm:"type:int;not null;default:0;column:grace_period_hours"`
+	SeasonID         *string                   `gorm:"type:varchar(50);column:season_id"`
+	Season           *ChallengeSeason          `gorm:"foreignKey:SeasonID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	Admin            admin.Admin               `gorm:"foreignKey:Author;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 	ImpactCategories []ChallengeImpactCategory `gorm:"foreignKey:ChallengeID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
@@ -32,7 +41,7 @@ type ChallengeImpactCategory struct {
 	ChallengeID      string                        `gorm:"type:varchar(50);not null;column:challenge_id"`
 	ImpactCategoryID string                        `gorm:"type:varchar(50);not null;column:impact_category_id"`
 	Challenge        Challenge                     `gorm:"foreignKey:ChallengeID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	ImpactCategory   impactcategory.ImpactCategory `gorm:"foreignKey:ImpactCategoryID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ImpactCategory   impactcategory.ImpactCategory `gorm:"foreignKey:ImpactCategoryID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
 }
 
 type ChallengeTask struct {
@@ -60,14 +69,36 @@ type ChallengeLog struct {
 
 type ChallengeConfirmation struct {
 	*gorm.Model
-	ID              string        `gorm:"primaryKey;type:varchar(50);not null;column:id"`
-	ChallengeTaskID string        `gorm:"type:varchar(50);not null;column:challenge_task_id"`
-	UserID          string        `gorm:"type:varchar(50);not null;column:user_id"`
-	Status          string        `gorm:"type:enum('Progress', 'Done', 'Failed');not null;column:status"`
-	ChallengeImg    string        `gorm:"type:varchar(255);not null;column:challenge_img"`
-	SubmissionDate  time.Time     `gorm:"type:datetime;not null;column:submission_date"`
-	User            users.User    `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	ChallengeTask   ChallengeTask `gorm:"foreignKey:ChallengeTaskID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ID                 string        `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	ChallengeTaskID    string        `gorm:"type:varchar(50);not null;column:challenge_task_id"`
+	UserID             string        `gorm:"type:varchar(50);not null;column:user_id"`
+	Status             string        `gorm:"type:enum('Progress', 'Pending Review', 'Done', 'Rejected', 'Failed');not null;column:status"`
+	ChallengeImg       string        `gorm:"type:varchar(255);not null;column:challenge_img"`
+	SubmissionDate     time.Time     `gorm:"type:datetime;not null;column:submission_date"`
+	Deadline           *time.Time    `gorm:"type:datetime;index;column:deadline"`
+	RejectionReason    string        `gorm:"type:text;column:rejection_reason"`
+	ReviewedBy         string        `gorm:"type:varchar(50);column:reviewed_by"`
+	ReviewedAt         *time.Time    `gorm:"type:datetime;column:reviewed_at"`
+	DeadlineRemindedAt *time.Time    `gorm:"type:datetime;column:deadline_reminded_at"`
+	User               users.User    `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ChallengeTask      ChallengeTask `gorm:"foreignKey:ChallengeTaskID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	// The verification columns hold what the vision model made of the proof.
+	VerificationStatus      string     `gorm:"type:varchar(20);index;column:verification_status"`
+	VerificationConfidence  float64    `gorm:"type:double;not null;default:0;column:verification_confidence"`
+	VerificationExplanation string     `gorm:"type:text;column:verification_explanation"`
+	VerificationAttempts    int        `gorm:"not null;default:0;column:verification_attempts"`
+	VerifiedAt              *time.Time `gorm:"type:datetime;column:verified_at"`
+}
+
+type ChallengeSeason struct {
+	*gorm.Model
+	ID              string    `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	Name            string    `gorm:"type:varchar(255);not null;column:name"`
+	Description     string    `gorm:"type:text;column:description"`
+	StartAt         time.Time `gorm:"type:datetime;not null;column:start_at"`
+	EndAt           time.Time `gorm:"type:datetime;not null;column:end_at"`
+	BonusMultiplier float64   `gorm:"type:decimal(4,2);not null;default:1;column:bonus_multiplier"`
 }
 
 func (Challenge) TableName() string {
@@ -89,3 +120,7 @@ func (ChallengeLog) TableName() string {
 func (ChallengeConfirmation) TableName() string {
 	return "challenge_confirmations"
 }
+
+func (ChallengeSeason) TableName() string {
+	return "challenge_seasons"
+}
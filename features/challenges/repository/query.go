@@ -4,11 +4,16 @@ import (
 	"errors"
 	"greenenvironment/constant"
 	"greenenvironment/features/challenges"
+	"greenenvironment/features/impacts"
+	impactcategory "greenenvironment/features/impacts/repository"
+	"greenenvironment/features/transactions"
 	userRepo "greenenvironment/features/users/repository"
 	"log"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ChallengeData struct {
@@ -23,15 +28,23 @@ func NewChallengeRepository(db *gorm.DB) challenges.ChallengeRepoInterface {
 
 func (cd *ChallengeData) Create(challenge challenges.Challenge) error {
 	newChallenge := Challenge{
-		ID:           challenge.ID,
-		Author:       challenge.Author,
-		Title:        challenge.Title,
-		Difficulty:   challenge.Difficulty,
-		ChallengeImg: challenge.ChallengeImg,
-		Description:  challenge.Description,
-		DurationDays: challenge.DurationDays,
-		Exp:          challenge.Exp,
-		Coin:         challenge.Coin,
+		ID:               challenge.ID,
+		Author:           challenge.Author,
+		Title:            challenge.Title,
+		Difficulty:       challenge.Difficulty,
+		ChallengeImg:     challenge.ChallengeImg,
+		Description:      challenge.Description,
+		DurationDays:     challenge.DurationDays,
+		Exp:              challenge.Exp,
+		Coin:             challenge.Coin,
+		TeamTarget:       challenge.TeamTarget,
+		RequiresReview:   challenge.RequiresReview,
+		Status:           challenge.Status,
+		StartAt:          challenge.StartAt,
+		EndAt:            challenge.EndAt,
+		EnrollmentCap:    challenge.EnrollmentCap,
+		GracePeriodHours: challenge.GracePeriodHours,
+		SeasonID:         challenge.SeasonID,
 	}
 
 	for _, impactcategory := range challenge.ImpactCategories {
@@ -94,15 +107,23 @@ func (cd *ChallengeData) GetByID(id string) (challenges.Challenge, error) {
 
 func (cd *ChallengeData) Update(challengeData challenges.Challenge) error {
 	updatedChallenge := Challenge{
-		ID:           challengeData.ID,
-		Author:       challengeData.Author,
-		Title:        challengeData.Title,
-		Difficulty:   challengeData.Difficulty,
-		ChallengeImg: challengeData.ChallengeImg,
-		Description:  challengeData.Description,
-		DurationDays: challengeData.DurationDays,
-		Exp:          challengeData.Exp,
-		Coin:         challengeData.Coin,
+		ID:               challengeData.ID,
+		Author:           challengeData.Author,
+		Title:            challengeData.Title,
+		Difficulty:       challengeData.Difficulty,
+		ChallengeImg:     challengeData.ChallengeImg,
+		Description:      challengeData.Description,
+		DurationDays:     challengeData.DurationDays,
+		Exp:              challengeData.Exp,
+		Coin:             challengeData.Coin,
+		TeamTarget:       challengeData.TeamTarget,
+		RequiresReview:   challengeData.RequiresReview,
+		Status:           challengeData.Status,
+		StartAt:          challengeData.StartAt,
+		EndAt:            challengeData.EndAt,
+		EnrollmentCap:    challengeData.EnrollmentCap,
+		GracePeriodHours: challengeData.GracePeriodHours,
+		SeasonID:         challengeData.SeasonID,
 	}
 
 	for _, impactCategory := range challengeData.ImpactCategories {
@@ -245,6 +266,9 @@ func (cd *ChallengeData) CreateChallengeConfirmation(confirmation challenges.Cha
 		ChallengeImg:    confirmation.ChallengeImg,
 		SubmissionDate:  confirmation.SubmissionDate,
 	}
+	if !confirmation.Deadline.IsZero() {
+		newConfirmation.Deadline = &confirmation.Deadline
+	}
 
 	err := cd.DB.Create(&newConfirmation).Error
 	if err != nil {
@@ -254,6 +278,27 @@ func (cd *ChallengeData) CreateChallengeConfirmation(confirmation challenges.Cha
 	return nil
 }
 
+// ReserveChallengeSeat counts a new participant unless the enrollment cap
+// is reached. The cap is checked in the same statement so two users cannot
+// both take the last seat.
+func (cd *ChallengeData) ReserveChallengeSeat(challengeID string) (bool, error) {
+	result := cd.DB.Model(&Challenge{}).
+		Where("id = ? AND (enrollment_cap = 0 OR participant_count < enrollment_cap)", challengeID).
+		UpdateColumn("participant_count", gorm.Expr("participant_count + ?", 1))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseChallengeSeat gives back a seat whose enrollment did not go
+// through.
+func (cd *ChallengeData) ReleaseChallengeSeat(challengeID string) error {
+	return cd.DB.Model(&Challenge{}).
+		Where("id = ? AND participant_count > 0", challengeID).
+		UpdateColumn("participant_count", gorm.Expr("participant_count - ?", 1)).Error
+}
+
 func (cd *ChallengeData) IncrementChallengeCounts(challengeID string, actionCount int, participantIncrement bool) error {
 	updateQuery := cd.DB.Model(&Challenge{}).Where("id = ?", challengeID)
 	if participantIncrement {
@@ -293,16 +338,28 @@ func (cd *ChallengeData) GetChallengeConfirmationByID(confirmationID string) (ch
 		Status:          confirmation.Status,
 		ChallengeImg:    confirmation.ChallengeImg,
 		SubmissionDate:  confirmation.SubmissionDate,
+		Deadline:        derefTime(confirmation.Deadline),
+		RejectionReason: confirmation.RejectionReason,
+		ReviewedBy:      confirmation.ReviewedBy,
+		ReviewedAt:      confirmation.ReviewedAt,
+		Verification:    toVerificationEntity(confirmation),
 	}, nil
 }
 
+// UpdateChallengeConfirmation stores a submitted proof. Its verification is
+// replaced as well, so a resubmitted photo is judged afresh.
 func (cd *ChallengeData) UpdateChallengeConfirmation(confirmation challenges.ChallengeConfirmation) error {
 	err := cd.DB.Model(&ChallengeConfirmation{}).
 		Where("id = ?", confirmation.ID).
-		Updates(ChallengeConfirmation{
-			Status:         confirmation.Status,
-			ChallengeImg:   confirmation.ChallengeImg,
-			SubmissionDate: confirmation.SubmissionDate,
+		Updates(map[string]interface{}{
+			"status":                   confirmation.Status,
+			"challenge_img":            confirmation.ChallengeImg,
+			"submission_date":          confirmation.SubmissionDate,
+			"verification_status":      confirmation.Verification.Status,
+			"verification_confidence":  confirmation.Verification.Confidence,
+			"verification_explanation": confirmation.Verification.Explanation,
+			"verification_attempts":    confirmation.Verification.Attempts,
+			"verified_at":              confirmation.Verification.VerifiedAt,
 		}).Error
 
 	if err != nil {
@@ -358,12 +415,12 @@ func (cd *ChallengeData) GetConfirmationsByChallengeID(challengeID, userID strin
 		Joins("JOIN challenge_tasks ON challenge_tasks.id = challenge_confirmations.challenge_task_id").
 		Find(&confirmations).Error
 
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, constant.ErrChallengeConfirmationNotFound
-			}
-			return nil, err
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, constant.ErrChallengeConfirmationNotFound
 		}
+		return nil, err
+	}
 
 	var result []challenges.ChallengeConfirmation
 	for _, confirmation := range confirmations {
@@ -374,6 +431,10 @@ func (cd *ChallengeData) GetConfirmationsByChallengeID(challengeID, userID strin
 			Status:          confirmation.Status,
 			ChallengeImg:    confirmation.ChallengeImg,
 			SubmissionDate:  confirmation.SubmissionDate,
+			Deadline:        derefTime(confirmation.Deadline),
+			RejectionReason: confirmation.RejectionReason,
+			ReviewedBy:      confirmation.ReviewedBy,
+			ReviewedAt:      confirmation.ReviewedAt,
 			ChallengeTask: challenges.ChallengeTask{
 				ID:              confirmation.ChallengeTask.ID,
 				ChallengeID:     confirmation.ChallengeTask.ChallengeID,
@@ -401,44 +462,133 @@ func (cd *ChallengeData) UpdateChallengeLog(log challenges.ChallengeLog) error {
 	return nil
 }
 
-func (cd *ChallengeData) UpdateTaskAndChallengeStatus() error {
-	now := time.Now()
-
-	err := cd.DB.Model(&ChallengeConfirmation{}).
-		Where("DATE_ADD(start_date, INTERVAL (day_number - 1) DAY) < ? AND status = ?", now, "Progress").
-		Updates(map[string]interface{}{
-			"status": "Failed",
-		}).Error
+// RecordChallengeImpact credits the user's impact ledger with the impact
+// points of every category of a completed challenge.
+func (cd *ChallengeData) RecordChallengeImpact(challengeLogID string) error {
+	var challengeLog ChallengeLog
+	err := cd.DB.Preload("Challenge.ImpactCategories.ImpactCategory").
+		Where("id = ?", challengeLogID).
+		First(&challengeLog).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return constant.ErrChallengeLogNotFound
+	}
 	if err != nil {
-		log.Printf("Error updating task statuses: %v", err)
 		return err
 	}
 
-	rows, err := cd.DB.Raw(`
-			UPDATE challenge_logs cl
-			SET cl.status = 'Failed'
-			WHERE cl.id IN (
-					SELECT cc.challenge_log_id
-					FROM challenge_confirmations cc
-					WHERE cc.status = 'Failed'
-					GROUP BY cc.challenge_log_id
-					HAVING COUNT(*) = (
-							SELECT COUNT(*)
-							FROM challenge_confirmations
-							WHERE challenge_log_id = cc.challenge_log_id
-					)
-			)
-	`).Rows()
-	if err != nil {
-		log.Printf("Error updating challenge log statuses: %v", err)
-		return err
+	for _, category := range challengeLog.Challenge.ImpactCategories {
+		entry := impactcategory.ImpactLedger{
+			ID:               uuid.New().String(),
+			UserID:           challengeLog.UserID,
+			ImpactCategoryID: category.ImpactCategoryID,
+			SourceType:       "challenge",
+			SourceID:         challengeLog.ID,
+			Points:           category.ImpactCategory.ImpactPoint,
+			OccurredAt:       time.Now(),
+		}
+		err := cd.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&entry).Error
+		if err != nil {
+			return err
+		}
 	}
-	defer rows.Close()
 
-	log.Printf("Challenge and task statuses updated successfully.")
 	return nil
 }
 
+func (cd *ChallengeData) GetUserTimezone(userID string) (string, error) {
+	var timezone string
+	err := cd.DB.Model(&userRepo.User{}).Where("id = ?", userID).Pluck("timezone", &timezone).Error
+	if err != nil {
+		return "", err
+	}
+	return timezone, nil
+}
+
+func (cd *ChallengeData) GetUserNameAndEmail(userID string) (string, string, error) {
+	var user userRepo.User
+	err := cd.DB.Select("name", "email").Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		return "", "", err
+	}
+	return user.Name, user.Email, nil
+}
+
+// openTaskStatuses are the statuses of tasks that can still be submitted
+// and so fail once their deadline passes.
+var openTaskStatuses = []string{"Progress", "Rejected"}
+
+// GetUndatedTasks returns the open tasks enrolled before deadlines were
+// stored.
+func (cd *ChallengeData) GetUndatedTasks() ([]challenges.UndatedTask, error) {
+	var tasks []challenges.UndatedTask
+	err := cd.DB.Table("challenge_confirmations").
+		Select("challenge_confirmations.id AS confirmation_id, challenge_logs.start_date, challenge_tasks.day_number, challenges.grace_period_hours").
+		Joins("JOIN challenge_tasks ON challenge_tasks.id = challenge_confirmations.challenge_task_id").
+		Joins("JOIN challenges ON challenges.id = challenge_tasks.challenge_id").
+		Joins("JOIN challenge_logs ON challenge_logs.challenge_id = challenges.id AND challenge_logs.user_id = challenge_confirmations.user_id AND challenge_logs.deleted_at IS NULL").
+		Where("challenge_confirmations.deadline IS NULL AND challenge_confirmations.status IN ? AND challenge_confirmations.deleted_at IS NULL", openTaskStatuses).
+		Scan(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (cd *ChallengeData) SetTaskDeadline(confirmationID string, deadline time.Time) error {
+	return cd.DB.Model(&ChallengeConfirmation{}).
+		Where("id = ? AND deadline IS NULL", confirmationID).
+		Update("deadline", deadline).Error
+}
+
+// FailOverdueTasks marks tasks still open after their deadline as Failed,
+// together with the challenge logs they belong to, and returns the number
+// of tasks that were failed.
+func (cd *ChallengeData) FailOverdueTasks(now time.Time) (int, error) {
+	var overdue []ChallengeConfirmation
+	err := cd.DB.Preload("ChallengeTask").
+		Where("status IN ? AND deadline IS NOT NULL AND deadline < ?", openTaskStatuses, now).
+		Find(&overdue).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(overdue) == 0 {
+		return 0, nil
+	}
+
+	failed := 0
+	err = cd.DB.Transaction(func(tx *gorm.DB) error {
+		failed = 0
+		for _, confirmation := range overdue {
+			// Re-check the status so a proof submitted since the lookup is not
+			// failed, and neither is its challenge.
+			result := tx.Model(&ChallengeConfirmation{}).
+				Where("id = ? AND status IN ?", confirmation.ID, openTaskStatuses).
+				Update("status", "Failed")
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+
+			err := tx.Model(&ChallengeLog{}).
+				Where("challenge_id = ? AND user_id = ? AND status = ?", confirmation.ChallengeTask.ChallengeID, confirmation.UserID, "Progress").
+				Update("status", "Failed").Error
+			if err != nil {
+				return err
+			}
+			failed++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error failing overdue tasks: %v", err)
+		return 0, constant.ErrUpdateChallengeConfirmation
+	}
+
+	return failed, nil
+}
+
 func (cd *ChallengeData) IsRewardClaimed(challengeLogID string) (bool, error) {
 	var log ChallengeLog
 	err := cd.DB.Select("rewards_given").Where("id = ?", challengeLogID).First(&log).Error
@@ -526,7 +676,8 @@ func (cd *ChallengeData) GetUnclaimedChallenges(userID string, isAdmin bool, pag
 		Preload("ImpactCategories.ImpactCategory")
 
 	if !isAdmin {
-		query = query.Where("challenges.deleted_at IS NULL")
+		query = query.Where("challenges.deleted_at IS NULL").
+			Where("challenges.status = ?", "Published")
 	}
 
 	if len(claimedChallenges) > 0 {
@@ -600,14 +751,23 @@ func (cd *ChallengeData) GetChallengeByID(challengeID string) (challenges.Challe
 	}
 
 	return challenges.Challenge{
-		ID:           challenge.ID,
-		Title:        challenge.Title,
-		Difficulty:   challenge.Difficulty,
-		ChallengeImg: challenge.ChallengeImg,
-		Description:  challenge.Description,
-		DurationDays: challenge.DurationDays,
-		Exp:          challenge.Exp,
-		Coin:         challenge.Coin,
+		ID:               challenge.ID,
+		Title:            challenge.Title,
+		Difficulty:       challenge.Difficulty,
+		ChallengeImg:     challenge.ChallengeImg,
+		Description:      challenge.Description,
+		DurationDays:     challenge.DurationDays,
+		Exp:              challenge.Exp,
+		Coin:             challenge.Coin,
+		TeamTarget:       challenge.TeamTarget,
+		RequiresReview:   challenge.RequiresReview,
+		ParticipantCount: challenge.ParticipantCount,
+		Status:           challenge.Status,
+		StartAt:          challenge.StartAt,
+		EndAt:            challenge.EndAt,
+		EnrollmentCap:    challenge.EnrollmentCap,
+		GracePeriodHours: challenge.GracePeriodHours,
+		SeasonID:         challenge.SeasonID,
 	}, nil
 }
 
@@ -631,3 +791,464 @@ func (cd *ChallengeData) GetTasksByChallengeIDforUser(challengeID string) ([]cha
 
 	return result, nil
 }
+
+// GetUpcomingDeadlines returns the tasks still in progress whose deadline
+// falls between now and until and that have not been reminded about yet.
+func (cd *ChallengeData) GetUpcomingDeadlines(now, until time.Time) ([]challenges.ChallengeConfirmation, error) {
+	var confirmations []ChallengeConfirmation
+	err := cd.DB.Preload("ChallengeTask.Challenge").
+		Where("status = ? AND deadline_reminded_at IS NULL AND deadline >= ? AND deadline < ?", "Progress", now, until).
+		Find(&confirmations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var result []challenges.ChallengeConfirmation
+	for _, confirmation := range confirmations {
+		result = append(result, challenges.ChallengeConfirmation{
+			ID:              confirmation.ID,
+			ChallengeTaskID: confirmation.ChallengeTaskID,
+			UserID:          confirmation.UserID,
+			Status:          confirmation.Status,
+			Deadline:        *confirmation.Deadline,
+			ChallengeTitle:  confirmation.ChallengeTask.Challenge.Title,
+			ChallengeTask: challenges.ChallengeTask{
+				ID:          confirmation.ChallengeTask.ID,
+				ChallengeID: confirmation.ChallengeTask.ChallengeID,
+				Name:        confirmation.ChallengeTask.Name,
+				DayNumber:   confirmation.ChallengeTask.DayNumber,
+			},
+		})
+	}
+	return result, nil
+}
+
+func (cd *ChallengeData) MarkDeadlinesReminded(confirmationIDs []string, remindedAt time.Time) error {
+	if len(confirmationIDs) == 0 {
+		return nil
+	}
+	return cd.DB.Model(&ChallengeConfirmation{}).
+		Where("id IN ?", confirmationIDs).
+		Update("deadline_reminded_at", remindedAt).Error
+}
+
+func (cd *ChallengeData) GetPendingConfirmations(page, perPage int) ([]challenges.ChallengeConfirmation, int, error) {
+	var confirmations []ChallengeConfirmation
+	var totalRecords int64
+
+	query := cd.DB.Model(&ChallengeConfirmation{}).Where("status = ?", "Pending Review")
+	if err := query.Count(&totalRecords).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("User").
+		Preload("ChallengeTask.Challenge").
+		Order("submission_date ASC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&confirmations).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var result []challenges.ChallengeConfirmation
+	for _, confirmation := range confirmations {
+		result = append(result, challenges.ChallengeConfirmation{
+			ID:              confirmation.ID,
+			ChallengeTaskID: confirmation.ChallengeTaskID,
+			UserID:          confirmation.UserID,
+			Username:        confirmation.User.Username,
+			Status:          confirmation.Status,
+			ChallengeImg:    confirmation.ChallengeImg,
+			SubmissionDate:  confirmation.SubmissionDate,
+			ChallengeTitle:  confirmation.ChallengeTask.Challenge.Title,
+			ChallengeTask: challenges.ChallengeTask{
+				ID:              confirmation.ChallengeTask.ID,
+				ChallengeID:     confirmation.ChallengeTask.ChallengeID,
+				Name:            confirmation.ChallengeTask.Name,
+				DayNumber:       confirmation.ChallengeTask.DayNumber,
+				TaskDescription: confirmation.ChallengeTask.TaskDescription,
+			},
+			Verification: toVerificationEntity(confirmation),
+		})
+	}
+
+	totalPages := int((totalRecords + int64(perPage) - 1) / int64(perPage))
+	return result, totalPages, nil
+}
+
+// GetUnverifiedConfirmations returns proofs pending review that the vision
+// model has not judged yet, oldest first.
+func (cd *ChallengeData) GetUnverifiedConfirmations(limit int) ([]challenges.ChallengeConfirmation, error) {
+	var confirmations []ChallengeConfirmation
+	err := cd.DB.Preload("ChallengeTask").
+		Where("status = ? AND verification_status = ?", "Pending Review", challenges.VerificationPending).
+		Order("submission_date ASC").
+		Limit(limit).
+		Find(&confirmations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var result []challenges.ChallengeConfirmation
+	for _, confirmation := range confirmations {
+		result = append(result, challenges.ChallengeConfirmation{
+			ID:              confirmation.ID,
+			ChallengeTaskID: confirmation.ChallengeTaskID,
+			UserID:          confirmation.UserID,
+			Status:          confirmation.Status,
+			ChallengeImg:    confirmation.ChallengeImg,
+			SubmissionDate:  confirmation.SubmissionDate,
+			ChallengeTask: challenges.ChallengeTask{
+				ID:              confirmation.ChallengeTask.ID,
+				ChallengeID:     confirmation.ChallengeTask.ChallengeID,
+				Name:            confirmation.ChallengeTask.Name,
+				DayNumber:       confirmation.ChallengeTask.DayNumber,
+				TaskDescription: confirmation.ChallengeTask.TaskDescription,
+			},
+			Verification: toVerificationEntity(confirmation),
+		})
+	}
+	return result, nil
+}
+
+func (cd *ChallengeData) UpdateConfirmationVerification(confirmationID string, verification challenges.ProofVerification) error {
+	err := cd.DB.Model(&ChallengeConfirmation{}).
+		Where("id = ?", confirmationID).
+		Updates(map[string]interface{}{
+			"verification_status":      verification.Status,
+			"verification_confidence":  verification.Confidence,
+			"verification_explanation": verification.Explanation,
+			"verification_attempts":    verification.Attempts,
+			"verified_at":              verification.VerifiedAt,
+		}).Error
+	if err != nil {
+		return constant.ErrUpdateChallengeConfirmation
+	}
+
+	return nil
+}
+
+func toVerificationEntity(confirmation ChallengeConfirmation) challenges.ProofVerification {
+	return challenges.ProofVerification{
+		Status:      confirmation.VerificationStatus,
+		Confidence:  confirmation.VerificationConfidence,
+		Explanation: confirmation.VerificationExplanation,
+		Attempts:    confirmation.VerificationAttempts,
+		VerifiedAt:  confirmation.VerifiedAt,
+	}
+}
+
+func (cd *ChallengeData) UpdateConfirmationReview(confirmation challenges.ChallengeConfirmation) error {
+	err := cd.DB.Model(&ChallengeConfirmation{}).
+		Where("id = ?", confirmation.ID).
+		Updates(map[string]interface{}{
+			"status":           confirmation.Status,
+			"rejection_reason": confirmation.RejectionReason,
+			"reviewed_by":      confirmation.ReviewedBy,
+			"reviewed_at":      confirmation.ReviewedAt,
+		}).Error
+	if err != nil {
+		return constant.ErrUpdateChallengeConfirmation
+	}
+
+	return nil
+}
+
+func (cd *ChallengeData) UpdateChallengeStatus(challengeID, status string) error {
+	err := cd.DB.Model(&Challenge{}).Where("id = ?", challengeID).Update("status", status).Error
+	if err != nil {
+		return constant.ErrUpdateChallenge
+	}
+	return nil
+}
+
+func (cd *ChallengeData) PublishScheduledChallenges(now time.Time) error {
+	return cd.DB.Model(&Challenge{}).
+		Where("status = ? AND start_at <= ?", "Scheduled", now).
+		Update("status", "Published").Error
+}
+
+func (cd *ChallengeData) EndExpiredChallenges(now time.Time) ([]challenges.Challenge, error) {
+	var expired []Challenge
+	err := cd.DB.Where("status IN ? AND end_at IS NOT NULL AND end_at <= ?", []string{"Scheduled", "Published"}, now).
+		Find(&expired).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var result []challenges.Challenge
+	for _, challenge := range expired {
+		err := cd.DB.Model(&Challenge{}).Where("id = ?", challenge.ID).Update("status", "Ended").Error
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, challenges.Challenge{
+			ID:    challenge.ID,
+			Title: challenge.Title,
+			EndAt: challenge.EndAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (cd *ChallengeData) GetEnrolledUserEmails(challengeID string) ([]string, error) {
+	var emails []string
+	err := cd.DB.Model(&userRepo.User{}).
+		Joins("JOIN challenge_logs ON challenge_logs.user_id = users.id AND challenge_logs.deleted_at IS NULL").
+		Where("challenge_logs.challenge_id = ?", challengeID).
+		Distinct().
+		Pluck("users.email", &emails).Error
+	if err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+func (cd *ChallengeData) CreateSeason(season challenges.ChallengeSeason) error {
+	newSeason := ChallengeSeason{
+		ID:              season.ID,
+		Name:            season.Name,
+		Description:     season.Description,
+		StartAt:         season.StartAt,
+		EndAt:           season.EndAt,
+		BonusMultiplier: season.BonusMultiplier,
+	}
+
+	if err := cd.DB.Create(&newSeason).Error; err != nil {
+		return constant.ErrCreateChallengeSeason
+	}
+	return nil
+}
+
+func (cd *ChallengeData) GetAllSeasons() ([]challenges.ChallengeSeason, error) {
+	var seasons []ChallengeSeason
+	if err := cd.DB.Order("start_at DESC").Find(&seasons).Error; err != nil {
+		return nil, err
+	}
+
+	var result []challenges.ChallengeSeason
+	for _, season := range seasons {
+		result = append(result, toSeasonEntity(season))
+	}
+	return result, nil
+}
+
+func (cd *ChallengeData) GetSeasonByID(seasonID string) (challenges.ChallengeSeason, error) {
+	var season ChallengeSeason
+	err := cd.DB.Where("id = ?", seasonID).First(&season).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return challenges.ChallengeSeason{}, constant.ErrChallengeSeasonNotFound
+	}
+	if err != nil {
+		return challenges.ChallengeSeason{}, err
+	}
+	return toSeasonEntity(season), nil
+}
+
+func (cd *ChallengeData) UpdateSeason(season challenges.ChallengeSeason) error {
+	err := cd.DB.Model(&ChallengeSeason{}).
+		Where("id = ?", season.ID).
+		Updates(map[string]interface{}{
+			"name":             season.Name,
+			"description":      season.Description,
+			"start_at":         season.StartAt,
+			"end_at":           season.EndAt,
+			"bonus_multiplier": season.BonusMultiplier,
+		}).Error
+	if err != nil {
+		return constant.ErrUpdateChallengeSeason
+	}
+	return nil
+}
+
+func (cd *ChallengeData) DeleteSeason(seasonID string) error {
+	tx := cd.DB.Begin()
+
+	if err := tx.Model(&Challenge{}).Where("season_id = ?", seasonID).Update("season_id", nil).Error; err != nil {
+		tx.Rollback()
+		return constant.ErrDeleteChallengeSeason
+	}
+
+	if err := tx.Where("id = ?", seasonID).Delete(&ChallengeSeason{}).Error; err != nil {
+		tx.Rollback()
+		return constant.ErrDeleteChallengeSeason
+	}
+
+	return tx.Commit().Error
+}
+
+// GetSeasonMultiplier returns the bonus multiplier of the season the challenge
+// belongs to, or 1 when the challenge is not part of a season.
+func (cd *ChallengeData) GetSeasonMultiplier(challengeID string) (float64, error) {
+	var multipliers []float64
+	err := cd.DB.Model(&ChallengeSeason{}).
+		Joins("JOIN challenges ON challenges.season_id = challenge_seasons.id").
+		Where("challenges.id = ?", challengeID).
+		Pluck("challenge_seasons.bonus_multiplier", &multipliers).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if len(multipliers) == 0 || multipliers[0] <= 0 {
+		return 1, nil
+	}
+	return multipliers[0], nil
+}
+
+func toSeasonEntity(season ChallengeSeason) challenges.ChallengeSeason {
+	return challenges.ChallengeSeason{
+		ID:              season.ID,
+		Name:            season.Name,
+		Description:     season.Description,
+		StartAt:         season.StartAt,
+		EndAt:           season.EndAt,
+		BonusMultiplier: season.BonusMultiplier,
+		CreatedAt:       season.CreatedAt,
+		UpdatedAt:       season.UpdatedAt,
+	}
+}
+
+func derefTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func (cd *ChallengeData) GetChallengeHistory(userID string) ([]challenges.ChallengeLog, error) {
+	var logs []ChallengeLog
+	err := cd.DB.Preload("Challenge").
+		Preload("Challenge.ImpactCategories.ImpactCategory").
+		Where("user_id = ?", userID).
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var result []challenges.ChallengeLog
+	for _, log := range logs {
+		result = append(result, challenges.ChallengeLog{
+			ID:          log.ID,
+			ChallengeID: log.ChallengeID,
+			UserID:      log.UserID,
+			Status:      log.Status,
+			StartDate:   log.StartDate,
+			Challenge: challenges.Challenge{
+				ID:               log.Challenge.ID,
+				Title:            log.Challenge.Title,
+				Difficulty:       log.Challenge.Difficulty,
+				ImpactCategories: toImpactCategoryEntities(log.Challenge.ImpactCategories),
+			},
+		})
+	}
+
+	return result, nil
+}
+
+func (cd *ChallengeData) GetAvailableChallenges(userID string) ([]challenges.Challenge, error) {
+	now := time.Now()
+
+	var challengeData []Challenge
+	err := cd.DB.Preload("ImpactCategories.ImpactCategory").
+		Where("status = ?", "Published").
+		Where("start_at IS NULL OR start_at <= ?", now).
+		Where("end_at IS NULL OR end_at > ?", now).
+		Where("id NOT IN (?)", cd.DB.Model(&ChallengeLog{}).Select("challenge_id").Where("user_id = ?", userID)).
+		Find(&challengeData).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var result []challenges.Challenge
+	for _, challenge := range challengeData {
+		result = append(result, challenges.Challenge{
+			ID:               challenge.ID,
+			Author:           challenge.Author,
+			Title:            challenge.Title,
+			Difficulty:       challenge.Difficulty,
+			ChallengeImg:     challenge.ChallengeImg,
+			Description:      challenge.Description,
+			DurationDays:     challenge.DurationDays,
+			Exp:              challenge.Exp,
+			Coin:             challenge.Coin,
+			ActionCount:      challenge.ActionCount,
+			ParticipantCount: challenge.ParticipantCount,
+			Status:           challenge.Status,
+			StartAt:          challenge.StartAt,
+			EndAt:            challenge.EndAt,
+			EnrollmentCap:    challenge.EnrollmentCap,
+			ImpactCategories: toImpactCategoryEntities(challenge.ImpactCategories),
+		})
+	}
+
+	return result, nil
+}
+
+func (cd *ChallengeData) GetPurchasedCategoryCounts(userID string) (map[string]int, error) {
+	var rows []struct {
+		ImpactCategoryID string
+		Total            int
+	}
+
+	err := cd.DB.Table("transaction_items").
+		Select("product_impact_categories.impact_category_id, SUM(transaction_items.quantity) AS total").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Joins("JOIN product_impact_categories ON product_impact_categories.product_id = transaction_items.product_id").
+		Where("transactions.user_id = ? AND transactions.status IN ?", userID, transactions.PaidStatuses).
+		Where("transaction_items.deleted_at IS NULL AND product_impact_categories.deleted_at IS NULL").
+		Group("product_impact_categories.impact_category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.ImpactCategoryID] = row.Total
+	}
+	return counts, nil
+}
+
+func (cd *ChallengeData) GetUserExp(userID string) (int, error) {
+	var user userRepo.User
+	err := cd.DB.Select("exp").Where("id = ?", userID).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, constant.UserNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return user.Exp, nil
+}
+
+func (cd *ChallengeData) GetChallengeCategoryIDs(challengeID string) ([]string, error) {
+	var categoryIDs []string
+	err := cd.DB.Model(&ChallengeImpactCategory{}).
+		Where("challenge_id = ?", challengeID).
+		Pluck("impact_category_id", &categoryIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return categoryIDs, nil
+}
+
+func toImpactCategoryEntities(categories []ChallengeImpactCategory) []challenges.ChallengeImpactCategory {
+	var result []challenges.ChallengeImpactCategory
+	for _, category := range categories {
+		result = append(result, challenges.ChallengeImpactCategory{
+			ID:               category.ID,
+			ChallengeID:      category.ChallengeID,
+			ImpactCategoryID: category.ImpactCategoryID,
+			ImpactCategory: impacts.ImpactCategory{
+				ID:          category.ImpactCategory.ID,
+				Name:        category.ImpactCategory.Name,
+				ImpactPoint: category.ImpactCategory.ImpactPoint,
+				Description: category.ImpactCategory.Description,
+			},
+		})
+	}
+	return result
+}
@@ -1,27 +1,89 @@
 package service
 
 import (
+	"fmt"
+	"greenenvironment/configs"
 	"greenenvironment/constant"
 	"greenenvironment/features/challenges"
 	"greenenvironment/features/impacts"
+	"greenenvironment/features/notifications"
+	"greenenvironment/helper"
+	"greenenvironment/utils/mail"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// deadlineReminderWindow is how long before a task deadline its user is
+// reminded to submit proof.
+const deadlineReminderWindow = 6 * time.Hour
+
 type ChallengeService struct {
 	challengeRepo challenges.ChallengeRepoInterface
 	impactRepo    impacts.ImpactRepositoryInterface
+	mailer        helper.MailerInterface
+	notifier      notifications.Notifier
+	// verifier checks proof photos; nil turns verification off.
+	verifier    challenges.ProofVerifier
+	proofConfig configs.ProofVerificationConfig
+	// verifying serialises verification runs so a proof is not sent to the
+	// model twice.
+	verifying sync.Mutex
 }
 
-func NewChallengeService(cr challenges.ChallengeRepoInterface, ir impacts.ImpactRepositoryInterface) challenges.ChallengeServiceInterface {
+func NewChallengeService(cr challenges.ChallengeRepoInterface, ir impacts.ImpactRepositoryInterface, mailer helper.MailerInterface, notifier notifications.Notifier, verifier challenges.ProofVerifier, proofConfig configs.ProofVerificationConfig) challenges.ChallengeServiceInterface {
 	return &ChallengeService{
 		challengeRepo: cr,
 		impactRepo:    ir,
+		mailer:        mailer,
+		notifier:      notifier,
+		verifier:      verifier,
+		proofConfig:   proofConfig,
+	}
+}
+
+// resolvePublishStatus validates the schedule of a challenge and turns a
+// requested status into the stored one. Publishing a challenge whose start
+// date is still ahead makes it Scheduled until the cron job opens it.
+func resolvePublishStatus(challenge challenges.Challenge, now time.Time) (string, error) {
+	if challenge.StartAt != nil && challenge.EndAt != nil && !challenge.EndAt.After(*challenge.StartAt) {
+		return "", constant.ErrInvalidChallengeSchedule
+	}
+
+	switch challenge.Status {
+	case "", "Draft":
+		return "Draft", nil
+	case "Published", "Scheduled":
+		if challenge.EndAt != nil && !challenge.EndAt.After(now) {
+			return "", constant.ErrChallengeEventEnded
+		}
+		if challenge.StartAt != nil && challenge.StartAt.After(now) {
+			return "Scheduled", nil
+		}
+		return "Published", nil
+	default:
+		return "", constant.ErrInvalidChallengeStatus
 	}
 }
 
 func (cs *ChallengeService) Create(challenge challenges.Challenge) error {
+	status, err := resolvePublishStatus(challenge, time.Now())
+	if err != nil {
+		return err
+	}
+	challenge.Status = status
+
+	if challenge.SeasonID != nil {
+		if _, err := cs.challengeRepo.GetSeasonByID(*challenge.SeasonID); err != nil {
+			return err
+		}
+	}
+
 	challenge.ID = uuid.New().String()
 	for i, impact := range challenge.ImpactCategories {
 		data, _ := cs.impactRepo.GetByID(impact.ImpactCategoryID)
@@ -32,7 +94,7 @@ func (cs *ChallengeService) Create(challenge challenges.Challenge) error {
 		challenge.ImpactCategories[i] = impact
 	}
 
-	err := cs.challengeRepo.Create(challenge)
+	err = cs.challengeRepo.Create(challenge)
 	if err != nil {
 		return err
 	}
@@ -72,6 +134,29 @@ func (cs *ChallengeService) Update(challenge challenges.Challenge) error {
 		challenge.ChallengeImg = existingChallenge.ChallengeImg
 	}
 
+	if challenge.StartAt == nil && !challenge.ClearStartAt {
+		challenge.StartAt = existingChallenge.StartAt
+	}
+	if challenge.EndAt == nil && !challenge.ClearEndAt {
+		challenge.EndAt = existingChallenge.EndAt
+	}
+	if challenge.SeasonID == nil {
+		challenge.SeasonID = existingChallenge.SeasonID
+	} else if _, err := cs.challengeRepo.GetSeasonByID(*challenge.SeasonID); err != nil {
+		return err
+	}
+
+	if challenge.Status == "" {
+		challenge.Status = existingChallenge.Status
+	}
+	if challenge.Status != "Ended" {
+		status, err := resolvePublishStatus(challenge, time.Now())
+		if err != nil {
+			return err
+		}
+		challenge.Status = status
+	}
+
 	return cs.challengeRepo.Update(challenge)
 }
 
@@ -156,11 +241,47 @@ func (cs *ChallengeService) CreateChallengeLogWithConfirmation(log challenges.Ch
 		return constant.ErrChallengeAlreadyTaken
 	}
 
+	challenge, err := cs.challengeRepo.GetChallengeByID(log.ChallengeID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if challenge.Status != "Published" ||
+		(challenge.StartAt != nil && now.Before(*challenge.StartAt)) ||
+		(challenge.EndAt != nil && !now.Before(*challenge.EndAt)) {
+		return constant.ErrChallengeNotAvailable
+	}
+
+	if err := cs.checkProgression(log.UserID, challenge); err != nil {
+		return err
+	}
+
+	timezone, err := cs.challengeRepo.GetUserTimezone(log.UserID)
+	if err != nil {
+		return err
+	}
+	loc := helper.LoadTimezone(timezone)
+
 	log.ID = uuid.New().String()
 	log.RewardsGiven = false
+	if log.StartDate.IsZero() {
+		log.StartDate = now
+	}
+
+	reserved, err := cs.challengeRepo.ReserveChallengeSeat(log.ChallengeID)
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		return constant.ErrChallengeEnrollmentFull
+	}
 
 	err = cs.challengeRepo.CreateChallengeLog(log)
 	if err != nil {
+		// The enrollment failed, so a failure to give the seat back only
+		// leaves the count one too high.
+		_ = cs.challengeRepo.ReleaseChallengeSeat(log.ChallengeID)
 		return err
 	}
 
@@ -178,6 +299,7 @@ func (cs *ChallengeService) CreateChallengeLogWithConfirmation(log challenges.Ch
 			Status:          "Progress",
 			ChallengeImg:    "",
 			SubmissionDate:  time.Now(),
+			Deadline:        taskDeadline(log.StartDate, task.DayNumber, challenge.GracePeriodHours, loc),
 		}
 		err := cs.challengeRepo.CreateChallengeConfirmation(confirmation)
 		if err != nil {
@@ -185,8 +307,8 @@ func (cs *ChallengeService) CreateChallengeLogWithConfirmation(log challenges.Ch
 		}
 	}
 
-	// Increment action_count and participant_count
-	err = cs.challengeRepo.IncrementChallengeCounts(log.ChallengeID, actionCount, true)
+	// Increment action_count; participant_count was incremented with the seat.
+	err = cs.challengeRepo.IncrementChallengeCounts(log.ChallengeID, actionCount, false)
 	if err != nil {
 		return err
 	}
@@ -204,7 +326,45 @@ func (cs *ChallengeService) UpdateChallengeConfirmationProgress(confirmationID,
 		return constant.ErrUnauthorized
 	}
 
+	if confirmation.Status == "Done" || confirmation.Status == "Pending Review" {
+		return constant.ErrChallengeConfirmationAlreadySubmitted
+	}
+
+	task, err := cs.challengeRepo.GetChallengeTaskByID(confirmation.ChallengeTaskID)
+	if err != nil {
+		return err
+	}
+
+	challengeLog, err := cs.challengeRepo.GetChallengeLogByChallengeIDAndUserID(task.ChallengeID, userID)
+	if err != nil {
+		return err
+	}
+
+	challenge, err := cs.challengeRepo.GetChallengeByID(task.ChallengeID)
+	if err != nil {
+		return err
+	}
+
+	// A task may be submitted, or resubmitted after a rejection, until its deadline.
+	// Tasks enrolled before deadlines were stored fall back to the server's day.
+	deadline := confirmation.Deadline
+	if deadline.IsZero() {
+		deadline = taskDeadline(challengeLog.StartDate, task.DayNumber, challenge.GracePeriodHours, time.Local)
+	}
+	if confirmation.Status == "Failed" || challengeLog.Status == "Failed" || time.Now().After(deadline) {
+		return constant.ErrChallengeTaskDeadlinePassed
+	}
+
 	confirmation.Status = "Done"
+	confirmation.Verification = challenges.ProofVerification{}
+	if challenge.RequiresReview {
+		confirmation.Status = "Pending Review"
+		// With verification on, the model looks at the proof before an admin
+		// does and approves it when it is confident enough.
+		if cs.verifier != nil {
+			confirmation.Verification.Status = challenges.VerificationPending
+		}
+	}
 	confirmation.ChallengeImg = challengeImgURL
 	confirmation.SubmissionDate = time.Now()
 
@@ -216,6 +376,71 @@ func (cs *ChallengeService) UpdateChallengeConfirmationProgress(confirmationID,
 	return nil
 }
 
+// taskDeadline returns the end of the task's day in the user's timezone plus
+// the challenge grace period. Day 1 is the local day the user enrolled.
+func taskDeadline(startDate time.Time, dayNumber, graceHours int, loc *time.Location) time.Time {
+	local := startDate.In(loc)
+	startOfDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	return startOfDay.AddDate(0, 0, dayNumber).Add(time.Duration(graceHours) * time.Hour)
+}
+
+func (cs *ChallengeService) SweepOverdueTasks() error {
+	// Tasks enrolled before deadlines were stored get the same server-day
+	// deadline a submission falls back to, so they can fail too.
+	undated, err := cs.challengeRepo.GetUndatedTasks()
+	if err != nil {
+		return err
+	}
+	for _, task := range undated {
+		deadline := taskDeadline(task.StartDate, task.DayNumber, task.GracePeriodHours, time.Local)
+		if err := cs.challengeRepo.SetTaskDeadline(task.ConfirmationID, deadline); err != nil {
+			return err
+		}
+	}
+
+	failed, err := cs.challengeRepo.FailOverdueTasks(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		log.Printf("Marked %d overdue challenge tasks as failed", failed)
+	}
+	return nil
+}
+
+func (cs *ChallengeService) RemindUpcomingDeadlines() error {
+	now := time.Now()
+	due, err := cs.challengeRepo.GetUpcomingDeadlines(now, now.Add(deadlineReminderWindow))
+	if err != nil {
+		return err
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(due))
+	for _, confirmation := range due {
+		ids = append(ids, confirmation.ID)
+
+		hoursLeft := int(math.Ceil(confirmation.Deadline.Sub(now).Hours()))
+		err := cs.notifier.Notify(notifications.Notification{
+			UserID:  confirmation.UserID,
+			Type:    notifications.TypeChallengeDeadline,
+			Title:   "Challenge task due soon",
+			Message: fmt.Sprintf("Day %d of \"%s\" is due in %d hours. Submit your proof before the deadline so the challenge is not failed.", confirmation.ChallengeTask.DayNumber, confirmation.ChallengeTitle, hoursLeft),
+			Link:    "/challenges/" + confirmation.ChallengeTask.ChallengeID,
+		})
+		if err != nil {
+			log.Printf("failed to remind %s about task %s: %v", confirmation.UserID, confirmation.ID, err)
+		}
+	}
+
+	// Reminders are sent at most once, even when delivery failed, so a broken
+	// address does not get retried every sweep.
+	return cs.challengeRepo.MarkDeadlinesReminded(ids, now)
+}
+
 func (cs *ChallengeService) CheckAndUpdateChallengeLogStatusByConfirmation(confirmationID, userID string) error {
 	confirmation, err := cs.challengeRepo.GetChallengeConfirmationByID(confirmationID)
 	if err != nil {
@@ -251,12 +476,67 @@ func (cs *ChallengeService) CheckAndUpdateChallengeLogStatusByConfirmation(confi
 		if err != nil {
 			return err
 		}
+
+		err = cs.challengeRepo.RecordChallengeImpact(challengeLog.ID)
+		if err != nil {
+			return err
+		}
+
+		err = cs.notifier.Notify(notifications.Notification{
+			UserID:  userID,
+			Type:    notifications.TypeReward,
+			Title:   "Rewards ready to claim",
+			Message: "You completed every task of your challenge. Your rewards are ready to claim.",
+			Link:    "/challenges/" + task.ChallengeID,
+		})
+		if err != nil {
+			log.Printf("failed to notify %s about rewards of challenge log %s: %v", userID, challengeLog.ID, err)
+		}
+
+		cs.sendChallengeCompleted(userID, task.ChallengeID)
 	}
 
 	return nil
 }
 
+// sendChallengeCompleted emails the user a congratulation. The challenge is
+// already marked done, so failures are only logged.
+func (cs *ChallengeService) sendChallengeCompleted(userID, challengeID string) {
+	challenge, err := cs.challengeRepo.GetChallengeByID(challengeID)
+	if err != nil {
+		log.Printf("failed to load challenge %s for the completion email: %v", challengeID, err)
+		return
+	}
+
+	name, email, err := cs.challengeRepo.GetUserNameAndEmail(userID)
+	if err != nil {
+		log.Printf("failed to load user %s for the completion email: %v", userID, err)
+		return
+	}
+
+	err = cs.mailer.SendTemplate(email, mail.TemplateChallengeCompleted, map[string]interface{}{
+		"Name":           name,
+		"ChallengeTitle": challenge.Title,
+	})
+	if err != nil {
+		log.Printf("failed to queue completion email of challenge %s to %s: %v", challengeID, email, err)
+	}
+}
+
 func (cs *ChallengeService) ClaimRewards(challengeLogID, userID string) error {
+	challengeLog, err := cs.challengeRepo.GetChallengeLogByID(challengeLogID)
+	if err != nil {
+		return err
+	}
+
+	if challengeLog.UserID != userID {
+		return constant.ErrUnauthorized
+	}
+
+	if challengeLog.Status != "Done" {
+		return constant.ErrChallengeNotCompleted
+	}
+
 	claimed, err := cs.challengeRepo.IsRewardClaimed(challengeLogID)
 	if err != nil {
 		return err
@@ -276,6 +556,13 @@ func (cs *ChallengeService) ClaimRewards(challengeLogID, userID string) error {
 		return err
 	}
 
+	multiplier, err := cs.challengeRepo.GetSeasonMultiplier(challengeID)
+	if err != nil {
+		return err
+	}
+	exp = int(math.Round(float64(exp) * multiplier))
+	coin = int(math.Round(float64(coin) * multiplier))
+
 	err = cs.challengeRepo.UpdateRewardsGiven(challengeLogID)
 	if err != nil {
 		return err
@@ -347,3 +634,362 @@ func (cs *ChallengeService) GetChallengeDetails(challengeID string) (challenges.
 
 	return details, nil
 }
+
+func (cs *ChallengeService) GetPendingConfirmations(page int) ([]challenges.ChallengeConfirmation, int, error) {
+	return cs.challengeRepo.GetPendingConfirmations(page, 20)
+}
+
+func (cs *ChallengeService) ReviewChallengeConfirmation(confirmationID, reviewerID, action, reason string) error {
+	if action != "approve" && action != "reject" {
+		return constant.ErrInvalidReviewAction
+	}
+
+	if action == "reject" && reason == "" {
+		return constant.ErrRejectionReasonRequired
+	}
+
+	confirmation, err := cs.challengeRepo.GetChallengeConfirmationByID(confirmationID)
+	if err != nil {
+		return err
+	}
+
+	if confirmation.Status != "Pending Review" {
+		return constant.ErrChallengeConfirmationNotPending
+	}
+
+	reviewedAt := time.Now()
+	confirmation.ReviewedBy = reviewerID
+	confirmation.ReviewedAt = &reviewedAt
+	if action == "approve" {
+		confirmation.Status = "Done"
+		confirmation.RejectionReason = ""
+	} else {
+		confirmation.Status = "Rejected"
+		confirmation.RejectionReason = reason
+	}
+
+	err = cs.challengeRepo.UpdateConfirmationReview(confirmation)
+	if err != nil {
+		return err
+	}
+
+	if confirmation.Status == "Done" {
+		return cs.CheckAndUpdateChallengeLogStatusByConfirmation(confirmationID, confirmation.UserID)
+	}
+
+	return nil
+}
+
+func (cs *ChallengeService) PublishChallenge(challengeID string) error {
+	challenge, err := cs.challengeRepo.GetChallengeByID(challengeID)
+	if err != nil {
+		return err
+	}
+
+	if challenge.Status == "Ended" {
+		return constant.ErrChallengeEventEnded
+	}
+
+	challenge.Status = "Published"
+	status, err := resolvePublishStatus(challenge, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return cs.challengeRepo.UpdateChallengeStatus(challengeID, status)
+}
+
+func (cs *ChallengeService) UnpublishChallenge(challengeID string) error {
+	challenge, err := cs.challengeRepo.GetChallengeByID(challengeID)
+	if err != nil {
+		return err
+	}
+
+	if challenge.Status == "Ended" {
+		return constant.ErrChallengeEventEnded
+	}
+
+	return cs.challengeRepo.UpdateChallengeStatus(challengeID, "Draft")
+}
+
+func (cs *ChallengeService) SyncChallengeSchedules() error {
+	now := time.Now()
+
+	if err := cs.challengeRepo.PublishScheduledChallenges(now); err != nil {
+		return err
+	}
+
+	ended, err := cs.challengeRepo.EndExpiredChallenges(now)
+	if err != nil {
+		return err
+	}
+
+	for _, challenge := range ended {
+		emails, err := cs.challengeRepo.GetEnrolledUserEmails(challenge.ID)
+		if err != nil {
+			return err
+		}
+
+		subject := "Challenge Event Ended"
+		message := fmt.Sprintf("The event \"%s\" has ended. Thank you for taking part! Open Ecomate to check your progress and claim any rewards you have earned.", challenge.Title)
+		for _, email := range emails {
+			// One bad address should not stop the rest of the participants from being notified.
+			if err := cs.mailer.SendNotification(email, subject, message); err != nil {
+				log.Printf("failed to notify %s about ended challenge %s: %v", email, challenge.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (cs *ChallengeService) CreateSeason(season challenges.ChallengeSeason) (challenges.ChallengeSeason, error) {
+	if err := validateSeason(season); err != nil {
+		return challenges.ChallengeSeason{}, err
+	}
+
+	season.ID = uuid.New().String()
+	if err := cs.challengeRepo.CreateSeason(season); err != nil {
+		return challenges.ChallengeSeason{}, err
+	}
+
+	return season, nil
+}
+
+func (cs *ChallengeService) GetAllSeasons() ([]challenges.ChallengeSeason, error) {
+	return cs.challengeRepo.GetAllSeasons()
+}
+
+func (cs *ChallengeService) GetSeasonByID(seasonID string) (challenges.ChallengeSeason, error) {
+	return cs.challengeRepo.GetSeasonByID(seasonID)
+}
+
+func (cs *ChallengeService) UpdateSeason(season challenges.ChallengeSeason) error {
+	if _, err := cs.challengeRepo.GetSeasonByID(season.ID); err != nil {
+		return err
+	}
+
+	if err := validateSeason(season); err != nil {
+		return err
+	}
+
+	return cs.challengeRepo.UpdateSeason(season)
+}
+
+func (cs *ChallengeService) DeleteSeason(seasonID string) error {
+	if _, err := cs.challengeRepo.GetSeasonByID(seasonID); err != nil {
+		return err
+	}
+
+	return cs.challengeRepo.DeleteSeason(seasonID)
+}
+
+func validateSeason(season challenges.ChallengeSeason) error {
+	if season.Name == "" || season.StartAt.IsZero() || season.EndAt.IsZero() {
+		return constant.ErrChallengeSeasonField
+	}
+
+	if !season.EndAt.After(season.StartAt) {
+		return constant.ErrInvalidChallengeSchedule
+	}
+
+	if season.BonusMultiplier < 1 {
+		return constant.ErrInvalidBonusMultiplier
+	}
+
+	return nil
+}
+
+// Difficulty levels used for progression. Challenges with any other
+// difficulty label are never locked.
+var difficultyLevels = map[string]int{
+	"easy":   1,
+	"medium": 2,
+	"hard":   3,
+}
+
+// EXP needed before medium and hard challenges become the suggested level.
+const (
+	mediumExpThreshold = 500
+	hardExpThreshold   = 2000
+)
+
+const defaultRecommendationLimit = 10
+
+func difficultyLevel(difficulty string) int {
+	return difficultyLevels[strings.ToLower(strings.TrimSpace(difficulty))]
+}
+
+// completedLevels returns the highest difficulty level the user completed in
+// each impact category.
+func completedLevels(history []challenges.ChallengeLog) map[string]int {
+	levels := make(map[string]int)
+	for _, log := range history {
+		if log.Status != "Done" {
+			continue
+		}
+		level := difficultyLevel(log.Challenge.Difficulty)
+		for _, category := range log.Challenge.ImpactCategories {
+			if level > levels[category.ImpactCategoryID] {
+				levels[category.ImpactCategoryID] = level
+			}
+		}
+	}
+	return levels
+}
+
+// isUnlocked reports whether a challenge is open to the user. A challenge
+// above easy needs a completed challenge one level below it in at least one
+// of its impact categories.
+func isUnlocked(level int, categoryIDs []string, completed map[string]int) bool {
+	if level <= 1 || len(categoryIDs) == 0 {
+		return true
+	}
+	for _, categoryID := range categoryIDs {
+		if completed[categoryID] >= level-1 {
+			return true
+		}
+	}
+	return false
+}
+
+func (cs *ChallengeService) checkProgression(userID string, challenge challenges.Challenge) error {
+	level := difficultyLevel(challenge.Difficulty)
+	if level <= 1 {
+		return nil
+	}
+
+	categoryIDs, err := cs.challengeRepo.GetChallengeCategoryIDs(challenge.ID)
+	if err != nil {
+		return err
+	}
+
+	history, err := cs.challengeRepo.GetChallengeHistory(userID)
+	if err != nil {
+		return err
+	}
+
+	if !isUnlocked(level, categoryIDs, completedLevels(history)) {
+		return constant.ErrChallengeLocked
+	}
+	return nil
+}
+
+func (cs *ChallengeService) GetRecommendedChallenges(userID string, limit int) ([]challenges.ChallengeRecommendation, error) {
+	if limit <= 0 {
+		limit = defaultRecommendationLimit
+	}
+
+	history, err := cs.challengeRepo.GetChallengeHistory(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := cs.challengeRepo.GetAvailableChallenges(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	purchases, err := cs.challengeRepo.GetPurchasedCategoryCounts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exp, err := cs.challengeRepo.GetUserExp(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	completed := completedLevels(history)
+	doneByCategory := make(map[string]int)
+	failedByCategory := make(map[string]int)
+	done, failed := 0, 0
+	for _, log := range history {
+		for _, category := range log.Challenge.ImpactCategories {
+			switch log.Status {
+			case "Done":
+				doneByCategory[category.ImpactCategoryID]++
+			case "Failed":
+				failedByCategory[category.ImpactCategoryID]++
+			}
+		}
+		switch log.Status {
+		case "Done":
+			done++
+		case "Failed":
+			failed++
+		}
+	}
+
+	// Suggest a level from EXP, and step down when the user fails more than they finish.
+	targetLevel := 1
+	if exp >= hardExpThreshold {
+		targetLevel = 3
+	} else if exp >= mediumExpThreshold {
+		targetLevel = 2
+	}
+	if failed > done && targetLevel > 1 {
+		targetLevel--
+	}
+
+	recommendations := []challenges.ChallengeRecommendation{}
+	for _, challenge := range candidates {
+		level := difficultyLevel(challenge.Difficulty)
+
+		categoryIDs := make([]string, 0, len(challenge.ImpactCategories))
+		for _, category := range challenge.ImpactCategories {
+			categoryIDs = append(categoryIDs, category.ImpactCategoryID)
+		}
+		if !isUnlocked(level, categoryIDs, completed) {
+			continue
+		}
+
+		recommendation := challenges.ChallengeRecommendation{Challenge: challenge}
+
+		switch {
+		case level == 0:
+			recommendation.Score += 1
+		case level == targetLevel:
+			recommendation.Score += 3
+			recommendation.Reasons = append(recommendation.Reasons, "Matches your experience level")
+		case level == targetLevel-1 || level == targetLevel+1:
+			recommendation.Score += 1
+		}
+
+		for _, category := range challenge.ImpactCategories {
+			name := category.ImpactCategory.Name
+
+			if doneByCategory[category.ImpactCategoryID] > 0 {
+				recommendation.Score += 2 * math.Min(float64(doneByCategory[category.ImpactCategoryID]), 3)
+				recommendation.Reasons = append(recommendation.Reasons, fmt.Sprintf("You have completed %s challenges before", name))
+			}
+
+			if level > 1 && completed[category.ImpactCategoryID] == level-1 {
+				recommendation.Score += 2
+				recommendation.Reasons = append(recommendation.Reasons, fmt.Sprintf("Next difficulty in %s", name))
+			}
+
+			if purchases[category.ImpactCategoryID] > 0 {
+				recommendation.Score += 0.5 * math.Min(float64(purchases[category.ImpactCategoryID]), 5)
+				recommendation.Reasons = append(recommendation.Reasons, fmt.Sprintf("Related to %s products you bought", name))
+			}
+
+			// Failed attempts in a category push harder challenges there down the list.
+			if level >= targetLevel {
+				recommendation.Score -= float64(failedByCategory[category.ImpactCategoryID])
+			}
+		}
+
+		recommendation.Score += math.Min(float64(challenge.ParticipantCount), 100) / 100
+		recommendations = append(recommendations, recommendation)
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
+
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+	return recommendations, nil
+}
@@ -1,11 +1,17 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"greenenvironment/configs"
 	"greenenvironment/constant"
 	"greenenvironment/features/challenges"
 	"greenenvironment/features/impacts"
+	"greenenvironment/features/notifications"
+	"greenenvironment/utils/llm"
+	"greenenvironment/utils/mail"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -75,6 +81,16 @@ func (m *MockChallengeRepository) CreateChallengeConfirmation(confirmation chall
 	return args.Error(0)
 }
 
+func (m *MockChallengeRepository) ReserveChallengeSeat(challengeID string) (bool, error) {
+	args := m.Called(challengeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockChallengeRepository) ReleaseChallengeSeat(challengeID string) error {
+	args := m.Called(challengeID)
+	return args.Error(0)
+}
+
 func (m *MockChallengeRepository) IncrementChallengeCounts(challengeID string, actionCount int, participantIncrement bool) error {
 	args := m.Called(challengeID, actionCount, participantIncrement)
 	return args.Error(0)
@@ -115,8 +131,38 @@ func (m *MockChallengeRepository) UpdateChallengeLog(log challenges.ChallengeLog
 	return args.Error(0)
 }
 
-func (m *MockChallengeRepository) UpdateTaskAndChallengeStatus() error {
+func (m *MockChallengeRepository) GetUserTimezone(userID string) (string, error) {
+	args := m.Called(userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockChallengeRepository) GetUserNameAndEmail(userID string) (string, string, error) {
+	args := m.Called(userID)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockChallengeRepository) GetUndatedTasks() ([]challenges.UndatedTask, error) {
 	args := m.Called()
+	return args.Get(0).([]challenges.UndatedTask), args.Error(1)
+}
+
+func (m *MockChallengeRepository) SetTaskDeadline(confirmationID string, deadline time.Time) error {
+	args := m.Called(confirmationID, deadline)
+	return args.Error(0)
+}
+
+func (m *MockChallengeRepository) FailOverdueTasks(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockChallengeRepository) GetUpcomingDeadlines(now, until time.Time) ([]challenges.ChallengeConfirmation, error) {
+	args := m.Called(now, until)
+	return args.Get(0).([]challenges.ChallengeConfirmation), args.Error(1)
+}
+
+func (m *MockChallengeRepository) MarkDeadlinesReminded(confirmationIDs []string, remindedAt time.Time) error {
+	args := m.Called(confirmationIDs, remindedAt)
 	return args.Error(0)
 }
 
@@ -170,6 +216,146 @@ func (m *MockChallengeRepository) GetTasksByChallengeIDforUser(challengeID strin
 	return args.Get(0).([]challenges.ChallengeTask), args.Error(1)
 }
 
+func (m *MockChallengeRepository) GetPendingConfirmations(page, perPage int) ([]challenges.ChallengeConfirmation, int, error) {
+	args := m.Called(page, perPage)
+	return args.Get(0).([]challenges.ChallengeConfirmation), args.Int(1), args.Error(2)
+}
+
+func (m *MockChallengeRepository) UpdateConfirmationReview(confirmation challenges.ChallengeConfirmation) error {
+	args := m.Called(confirmation)
+	return args.Error(0)
+}
+
+func (m *MockChallengeRepository) GetUnverifiedConfirmations(limit int) ([]challenges.ChallengeConfirmation, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]challenges.ChallengeConfirmation), args.Error(1)
+}
+
+func (m *MockChallengeRepository) UpdateConfirmationVerification(confirmationID string, verification challenges.ProofVerification) error {
+	args := m.Called(confirmationID, verification)
+	return args.Error(0)
+}
+
+func (m *MockChallengeRepository) UpdateChallengeStatus(challengeID, status string) error {
+	args := m.Called(challengeID, status)
+	return args.Error(0)
+}
+
+func (m *MockChallengeRepository) PublishScheduledChallenges(now time.Time) error {
+	args := m.Called(now)
+	return args.Error(0)
+}
+
+func (m *MockChallengeRepository) EndExpiredChallenges(now time.Time) ([]challenges.Challenge, error) {
+	args := m.Called(now)
+	return args.Get(0).([]challenges.Challenge), args.Error(1)
+}
+
+func (m *MockChallengeRepository) GetEnrolledUserEmails(challengeID string) ([]string, error) {
+	args := m.Called(challengeID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockChallengeRepository) CreateSeason(season challenges.ChallengeSeason) error {
+	args := m.Called(season)
+	return args.Error(0)
+}
+
+func (m *MockChallengeRepository) GetAllSeasons() ([]challenges.ChallengeSeason, error) {
+	args := m.Called()
+	return args.Get(0).([]challenges.ChallengeSeason), args.Error(1)
+}
+
+func (m *MockChallengeRepository) GetSeasonByID(seasonID string) (challenges.ChallengeSeason, error) {
+	args := m.Called(seasonID)
+	return args.Get(0).(challenges.ChallengeSeason), args.Error(1)
+}
+
+func (m *MockChallengeRepository) UpdateSeason(season challenges.ChallengeSeason) error {
+	args := m.Called(season)
+	return args.Error(0)
+}
+
+func (m *MockChallengeRepository) DeleteSeason(seasonID string) error {
+	args := m.Called(seasonID)
+	return args.Error(0)
+}
+
+func (m *MockChallengeRepository) GetSeasonMultiplier(challengeID string) (float64, error) {
+	args := m.Called(challengeID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockChallengeRepository) GetChallengeHistory(userID string) ([]challenges.ChallengeLog, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]challenges.ChallengeLog), args.Error(1)
+}
+
+func (m *MockChallengeRepository) GetAvailableChallenges(userID string) ([]challenges.Challenge, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]challenges.Challenge), args.Error(1)
+}
+
+func (m *MockChallengeRepository) GetPurchasedCategoryCounts(userID string) (map[string]int, error) {
+	args := m.Called(userID)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockChallengeRepository) GetUserExp(userID string) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockChallengeRepository) GetChallengeCategoryIDs(challengeID string) ([]string, error) {
+	args := m.Called(challengeID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockChallengeRepository) RecordChallengeImpact(challengeLogID string) error {
+	args := m.Called(challengeLogID)
+	return args.Error(0)
+}
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(to string, code string, subject string) error {
+	args := m.Called(to, code, subject)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendNotification(to string, subject string, message string) error {
+	args := m.Called(to, subject, message)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendTemplate(to string, template string, data map[string]interface{}) error {
+	args := m.Called(to, template, data)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendTemplateOnce(to string, template string, key string, data map[string]interface{}) error {
+	args := m.Called(to, template, key, data)
+	return args.Error(0)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(notification notifications.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+// newMockNotifier accepts any notification, for tests that do not check them.
+func newMockNotifier() *MockNotifier {
+	notifier := new(MockNotifier)
+	notifier.On("Notify", mock.Anything).Return(nil).Maybe()
+	return notifier
+}
+
 type MockImpactRepository struct {
 	mock.Mock
 }
@@ -194,10 +380,50 @@ func (m *MockImpactRepository) Delete(category impacts.ImpactCategory) error {
 	return args.Error(0)
 }
 
+func (m *MockImpactRepository) GetUserPointsByCategory(userID string) ([]impacts.CategoryPoints, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]impacts.CategoryPoints), args.Error(1)
+}
+
+func (m *MockImpactRepository) GetUserMonthlyPoints(userID string, since time.Time) ([]impacts.CategoryPoints, error) {
+	args := m.Called(userID, since)
+	return args.Get(0).([]impacts.CategoryPoints), args.Error(1)
+}
+
+func (m *MockImpactRepository) GetConversionFactors(categoryID string) ([]impacts.ConversionFactor, error) {
+	args := m.Called(categoryID)
+	return args.Get(0).([]impacts.ConversionFactor), args.Error(1)
+}
+
+func (m *MockImpactRepository) SaveConversionFactors(categoryID string, factors []impacts.ConversionFactor) error {
+	args := m.Called(categoryID, factors)
+	return args.Error(0)
+}
+
+func (m *MockImpactRepository) Update(category impacts.ImpactCategory) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockImpactRepository) GetChildren(parentID string) ([]impacts.ImpactCategory, error) {
+	args := m.Called(parentID)
+	return args.Get(0).([]impacts.ImpactCategory), args.Error(1)
+}
+
+func (m *MockImpactRepository) GetUsage(categoryID string) (impacts.CategoryUsage, error) {
+	args := m.Called(categoryID)
+	return args.Get(0).(impacts.CategoryUsage), args.Error(1)
+}
+
+func (m *MockImpactRepository) ReassignAndDelete(categoryID, targetID string) error {
+	args := m.Called(categoryID, targetID)
+	return args.Error(0)
+}
+
 func TestCreateChallenge_Success(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	newChallenge := challenges.Challenge{
 		Title:       "New Challenge",
@@ -227,7 +453,7 @@ func TestCreateChallenge_Success(t *testing.T) {
 func TestCreateChallenge_InvalidImpactCategory(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	newChallenge := challenges.Challenge{
 		Title: "New Challenge",
@@ -249,7 +475,7 @@ func TestCreateChallenge_InvalidImpactCategory(t *testing.T) {
 func TestCreateChallenge_RepositoryError(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	newChallenge := challenges.Challenge{
 		Title:       "New Challenge",
@@ -276,7 +502,7 @@ func TestCreateChallenge_RepositoryError(t *testing.T) {
 
 func TestGetChallengeByID_Success(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	expectedChallenge := challenges.Challenge{
 		ID:          "challenge1",
@@ -296,7 +522,7 @@ func TestGetChallengeByID_Success(t *testing.T) {
 
 func TestGetChallengeByID_NotFound(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	mockChallengeRepo.On("GetByID", "invalidID").Return(challenges.Challenge{}, errors.New("not found"))
 
@@ -310,7 +536,7 @@ func TestGetChallengeByID_NotFound(t *testing.T) {
 
 func TestGetAllByPage_Success(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	mockChallengeRepo.On("GetAllByPage", 1).Return([]challenges.Challenge{
 		{
@@ -333,7 +559,7 @@ func TestGetAllByPage_Success(t *testing.T) {
 func TestUpdateChallenge_Success(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	updatedChallenge := challenges.Challenge{
 		ID:          "challenge1",
@@ -373,10 +599,61 @@ func TestUpdateChallenge_Success(t *testing.T) {
 	mockChallengeRepo.AssertCalled(t, "Update", mock.Anything)
 }
 
+func TestUpdateChallenge_ClearsSchedule(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockImpactRepo := new(MockImpactRepository)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	startAt := time.Now().Add(24 * time.Hour)
+	endAt := time.Now().Add(7 * 24 * time.Hour)
+	mockChallengeRepo.On("GetByID", "challenge1").Return(challenges.Challenge{
+		ID:      "challenge1",
+		Status:  "Scheduled",
+		StartAt: &startAt,
+		EndAt:   &endAt,
+	}, nil)
+	mockChallengeRepo.On("Update", mock.MatchedBy(func(challenge challenges.Challenge) bool {
+		return challenge.StartAt == nil && challenge.EndAt == nil && challenge.Status == "Published"
+	})).Return(nil)
+
+	err := service.Update(challenges.Challenge{
+		ID:           "challenge1",
+		Status:       "Published",
+		ClearStartAt: true,
+		ClearEndAt:   true,
+	})
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertExpectations(t)
+}
+
+func TestUpdateChallenge_KeepsScheduleWhenNotGiven(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockImpactRepo := new(MockImpactRepository)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	startAt := time.Now().Add(24 * time.Hour)
+	endAt := time.Now().Add(7 * 24 * time.Hour)
+	mockChallengeRepo.On("GetByID", "challenge1").Return(challenges.Challenge{
+		ID:      "challenge1",
+		Status:  "Scheduled",
+		StartAt: &startAt,
+		EndAt:   &endAt,
+	}, nil)
+	mockChallengeRepo.On("Update", mock.MatchedBy(func(challenge challenges.Challenge) bool {
+		return challenge.StartAt == &startAt && challenge.EndAt == &endAt && challenge.Status == "Scheduled"
+	})).Return(nil)
+
+	err := service.Update(challenges.Challenge{ID: "challenge1", Status: "Published"})
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertExpectations(t)
+}
+
 func TestUpdateChallenge_InvalidImpactCategory(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	invalidChallenge := challenges.Challenge{
 		ID:    "challenge1",
@@ -399,7 +676,7 @@ func TestUpdateChallenge_InvalidImpactCategory(t *testing.T) {
 func TestUpdateChallenge_RepositoryError(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	updateChallenge := challenges.Challenge{
 		ID:    "challenge1",
@@ -426,7 +703,7 @@ func TestUpdateChallenge_RepositoryError(t *testing.T) {
 
 func TestDeleteChallenge_Success(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	mockChallengeRepo.On("GetByID", "challenge1").Return(challenges.Challenge{ID: "challenge1"}, nil)
 	mockChallengeRepo.On("Delete", "challenge1").Return(nil)
@@ -440,7 +717,7 @@ func TestDeleteChallenge_Success(t *testing.T) {
 
 func TestDeleteChallenge_NotFound(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	mockChallengeRepo.On("GetByID", "challenge1").Return(challenges.Challenge{}, constant.ErrChallengeNotFound)
 
@@ -455,7 +732,7 @@ func TestDeleteChallenge_NotFound(t *testing.T) {
 func TestCreateTask_Success(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 	taskName := "Task Name"
@@ -482,7 +759,7 @@ func TestCreateTask_Success(t *testing.T) {
 func TestCreateTask_ErrorOnGetByID(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 	taskName := "Task Name"
@@ -501,7 +778,7 @@ func TestCreateTask_ErrorOnGetByID(t *testing.T) {
 func TestCreateTask_ChallengeNotFound(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 	taskName := "Task Name"
@@ -520,7 +797,7 @@ func TestCreateTask_ChallengeNotFound(t *testing.T) {
 func TestCreateTask_TaskAlreadyExists(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 	existingTask := challenges.ChallengeTask{
@@ -549,7 +826,7 @@ func TestCreateTask_TaskAlreadyExists(t *testing.T) {
 func TestCreateTask_DayNumberInvalid(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 
@@ -567,7 +844,7 @@ func TestCreateTask_DayNumberInvalid(t *testing.T) {
 
 func TestGetAllTasksByChallengeID(t *testing.T) {
 	mockRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockRepo, nil)
+	service := NewChallengeService(mockRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 	expectedTasks := []challenges.ChallengeTask{
@@ -586,7 +863,7 @@ func TestGetAllTasksByChallengeID(t *testing.T) {
 
 func TestGetTaskByID(t *testing.T) {
 	mockRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockRepo, nil)
+	service := NewChallengeService(mockRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	taskID := "task1"
 	existingTask := challenges.ChallengeTask{ID: taskID, TaskDescription: "Task 1"}
@@ -602,7 +879,7 @@ func TestGetTaskByID(t *testing.T) {
 
 func TestGetTaskByID_NotFound(t *testing.T) {
 	mockRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockRepo, nil)
+	service := NewChallengeService(mockRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	taskID := "nonexistent"
 
@@ -618,7 +895,7 @@ func TestGetTaskByID_NotFound(t *testing.T) {
 
 func TestUpdateTask(t *testing.T) {
 	mockRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockRepo, nil)
+	service := NewChallengeService(mockRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	taskID := "task1"
 	taskDescription := "Updated Task Description"
@@ -640,7 +917,7 @@ func TestUpdateTask(t *testing.T) {
 
 func TestUpdateTask_NotFound(t *testing.T) {
 	mockRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockRepo, nil)
+	service := NewChallengeService(mockRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	taskID := "nonexistent"
 	taskDescription := "Updated Task Description"
@@ -656,7 +933,7 @@ func TestUpdateTask_NotFound(t *testing.T) {
 
 func TestDeleteTask(t *testing.T) {
 	mockRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockRepo, nil)
+	service := NewChallengeService(mockRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	taskID := "task1"
 	existingTask := challenges.ChallengeTask{ID: taskID, TaskDescription: "Task to delete"}
@@ -673,7 +950,7 @@ func TestDeleteTask(t *testing.T) {
 
 func TestDeleteTask_NotFound(t *testing.T) {
 	mockRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockRepo, nil)
+	service := NewChallengeService(mockRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	taskID := "nonexistent"
 
@@ -689,7 +966,7 @@ func TestDeleteTask_NotFound(t *testing.T) {
 func TestCreateChallengeLogWithConfirmation_Success(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 	userID := "user1"
@@ -699,6 +976,9 @@ func TestCreateChallengeLogWithConfirmation_Success(t *testing.T) {
 	}
 
 	mockChallengeRepo.On("IsChallengeTaken", userID, challengeID).Return(false, nil)
+	mockChallengeRepo.On("GetChallengeByID", challengeID).Return(challenges.Challenge{ID: challengeID, Status: "Published"}, nil)
+	mockChallengeRepo.On("GetUserTimezone", userID).Return("Asia/Jakarta", nil)
+	mockChallengeRepo.On("ReserveChallengeSeat", challengeID).Return(true, nil)
 	mockChallengeRepo.On("CreateChallengeLog", mock.Anything).Return(nil)
 
 	mockChallengeRepo.On("GetTasksByChallengeID", challengeID).Return([]challenges.ChallengeTask{
@@ -707,7 +987,7 @@ func TestCreateChallengeLogWithConfirmation_Success(t *testing.T) {
 	}, nil)
 
 	mockChallengeRepo.On("CreateChallengeConfirmation", mock.Anything).Return(nil).Twice()
-	mockChallengeRepo.On("IncrementChallengeCounts", challengeID, 2, true).Return(nil)
+	mockChallengeRepo.On("IncrementChallengeCounts", challengeID, 2, false).Return(nil)
 
 	err := service.CreateChallengeLogWithConfirmation(log)
 
@@ -716,13 +996,13 @@ func TestCreateChallengeLogWithConfirmation_Success(t *testing.T) {
 	mockChallengeRepo.AssertCalled(t, "CreateChallengeLog", mock.Anything)
 	mockChallengeRepo.AssertCalled(t, "GetTasksByChallengeID", challengeID)
 	mockChallengeRepo.AssertCalled(t, "CreateChallengeConfirmation", mock.Anything)
-	mockChallengeRepo.AssertCalled(t, "IncrementChallengeCounts", challengeID, 2, true)
+	mockChallengeRepo.AssertCalled(t, "IncrementChallengeCounts", challengeID, 2, false)
 }
 
 func TestCreateChallengeLogWithConfirmation_AlreadyTaken(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 	userID := "user1"
@@ -743,7 +1023,7 @@ func TestCreateChallengeLogWithConfirmation_AlreadyTaken(t *testing.T) {
 func TestCreateChallengeLogWithConfirmation_ErrorCheckingIfTaken(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 	userID := "user1"
@@ -763,7 +1043,7 @@ func TestCreateChallengeLogWithConfirmation_ErrorCheckingIfTaken(t *testing.T) {
 func TestCreateChallengeLogWithConfirmation_ErrorCreatingChallengeLog(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 	userID := "user1"
@@ -773,18 +1053,23 @@ func TestCreateChallengeLogWithConfirmation_ErrorCreatingChallengeLog(t *testing
 	}
 
 	mockChallengeRepo.On("IsChallengeTaken", userID, challengeID).Return(false, nil)
+	mockChallengeRepo.On("GetChallengeByID", challengeID).Return(challenges.Challenge{ID: challengeID, Status: "Published"}, nil)
+	mockChallengeRepo.On("GetUserTimezone", userID).Return("Asia/Jakarta", nil)
+	mockChallengeRepo.On("ReserveChallengeSeat", challengeID).Return(true, nil)
 	mockChallengeRepo.On("CreateChallengeLog", mock.Anything).Return(errors.New("db error"))
+	mockChallengeRepo.On("ReleaseChallengeSeat", challengeID).Return(nil).Once()
 
 	err := service.CreateChallengeLogWithConfirmation(log)
 
 	assert.Error(t, err)
 	assert.Equal(t, "db error", err.Error())
+	mockChallengeRepo.AssertExpectations(t)
 }
 
 func TestCreateChallengeLogWithConfirmation_ErrorCreatingChallengeConfirmation(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 	userID := "user1"
@@ -794,6 +1079,9 @@ func TestCreateChallengeLogWithConfirmation_ErrorCreatingChallengeConfirmation(t
 	}
 
 	mockChallengeRepo.On("IsChallengeTaken", userID, challengeID).Return(false, nil)
+	mockChallengeRepo.On("GetChallengeByID", challengeID).Return(challenges.Challenge{ID: challengeID, Status: "Published"}, nil)
+	mockChallengeRepo.On("GetUserTimezone", userID).Return("Asia/Jakarta", nil)
+	mockChallengeRepo.On("ReserveChallengeSeat", challengeID).Return(true, nil)
 	mockChallengeRepo.On("CreateChallengeLog", mock.Anything).Return(nil)
 	mockChallengeRepo.On("GetTasksByChallengeID", challengeID).Return([]challenges.ChallengeTask{
 		{ID: "task1"},
@@ -810,7 +1098,7 @@ func TestCreateChallengeLogWithConfirmation_ErrorCreatingChallengeConfirmation(t
 func TestCreateChallengeLogWithConfirmation_ErrorIncrementingCounts(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 	userID := "user1"
@@ -820,13 +1108,16 @@ func TestCreateChallengeLogWithConfirmation_ErrorIncrementingCounts(t *testing.T
 	}
 
 	mockChallengeRepo.On("IsChallengeTaken", userID, challengeID).Return(false, nil)
+	mockChallengeRepo.On("GetChallengeByID", challengeID).Return(challenges.Challenge{ID: challengeID, Status: "Published"}, nil)
+	mockChallengeRepo.On("GetUserTimezone", userID).Return("Asia/Jakarta", nil)
+	mockChallengeRepo.On("ReserveChallengeSeat", challengeID).Return(true, nil)
 	mockChallengeRepo.On("CreateChallengeLog", mock.Anything).Return(nil)
 	mockChallengeRepo.On("GetTasksByChallengeID", challengeID).Return([]challenges.ChallengeTask{
 		{ID: "task1"},
 		{ID: "task2"},
 	}, nil)
 	mockChallengeRepo.On("CreateChallengeConfirmation", mock.Anything).Return(nil).Twice()
-	mockChallengeRepo.On("IncrementChallengeCounts", challengeID, 2, true).Return(errors.New("db error"))
+	mockChallengeRepo.On("IncrementChallengeCounts", challengeID, 2, false).Return(errors.New("db error"))
 
 	err := service.CreateChallengeLogWithConfirmation(log)
 
@@ -837,16 +1128,21 @@ func TestCreateChallengeLogWithConfirmation_ErrorIncrementingCounts(t *testing.T
 func TestUpdateChallengeConfirmationProgress_Success(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	confirmationID := "confirmation1"
 	userID := "user1"
 	challengeImgURL := "http://example.com/image.png"
 
 	mockChallengeRepo.On("GetChallengeConfirmationByID", confirmationID).Return(challenges.ChallengeConfirmation{
-		ID:     confirmationID,
-		UserID: userID,
+		ID:              confirmationID,
+		ChallengeTaskID: "task1",
+		UserID:          userID,
+		Status:          "Progress",
 	}, nil)
+	mockChallengeRepo.On("GetChallengeTaskByID", "task1").Return(challenges.ChallengeTask{ID: "task1", ChallengeID: "challenge1", DayNumber: 1}, nil)
+	mockChallengeRepo.On("GetChallengeLogByChallengeIDAndUserID", "challenge1", userID).Return(challenges.ChallengeLog{ID: "log1", StartDate: time.Now()}, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1"}, nil)
 
 	mockChallengeRepo.On("UpdateChallengeConfirmation", mock.Anything).Return(nil)
 
@@ -860,7 +1156,7 @@ func TestUpdateChallengeConfirmationProgress_Success(t *testing.T) {
 func TestUpdateChallengeConfirmationProgress_Unauthorized(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	confirmationID := "confirmation1"
 	userID := "user1"
@@ -880,7 +1176,7 @@ func TestUpdateChallengeConfirmationProgress_Unauthorized(t *testing.T) {
 func TestUpdateChallengeConfirmationProgress_ErrorOnGetChallengeConfirmation(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	confirmationID := "confirmation1"
 	userID := "user1"
@@ -897,16 +1193,21 @@ func TestUpdateChallengeConfirmationProgress_ErrorOnGetChallengeConfirmation(t *
 func TestUpdateChallengeConfirmationProgress_ErrorOnUpdateChallengeConfirmation(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	confirmationID := "confirmation1"
 	userID := "user1"
 	challengeImgURL := "http://example.com/image.png"
 
 	mockChallengeRepo.On("GetChallengeConfirmationByID", confirmationID).Return(challenges.ChallengeConfirmation{
-		ID:     confirmationID,
-		UserID: userID,
+		ID:              confirmationID,
+		ChallengeTaskID: "task1",
+		UserID:          userID,
+		Status:          "Progress",
 	}, nil)
+	mockChallengeRepo.On("GetChallengeTaskByID", "task1").Return(challenges.ChallengeTask{ID: "task1", ChallengeID: "challenge1", DayNumber: 1}, nil)
+	mockChallengeRepo.On("GetChallengeLogByChallengeIDAndUserID", "challenge1", userID).Return(challenges.ChallengeLog{ID: "log1", StartDate: time.Now()}, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1"}, nil)
 
 	mockChallengeRepo.On("UpdateChallengeConfirmation", mock.Anything).Return(errors.New("failed to update confirmation"))
 
@@ -921,7 +1222,8 @@ func TestUpdateChallengeConfirmationProgress_ErrorOnUpdateChallengeConfirmation(
 func TestCheckAndUpdateChallengeLogStatusByConfirmation_AllDone(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	mockMailer := new(MockMailer)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, mockMailer, newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	confirmationID := "confirmation1"
 	userID := "user1"
@@ -947,18 +1249,82 @@ func TestCheckAndUpdateChallengeLogStatusByConfirmation_AllDone(t *testing.T) {
 	}, nil)
 
 	mockChallengeRepo.On("UpdateChallengeLog", mock.Anything).Return(nil)
+	mockChallengeRepo.On("RecordChallengeImpact", challengeID).Return(nil)
+	mockChallengeRepo.On("GetChallengeByID", challengeID).Return(challenges.Challenge{ID: challengeID, Title: "Plastic Free Week"}, nil)
+	mockChallengeRepo.On("GetUserNameAndEmail", userID).Return("User One", "user1@example.com", nil)
+	mockMailer.On("SendTemplate", "user1@example.com", mail.TemplateChallengeCompleted, mock.Anything).Return(nil)
 
 	err := service.CheckAndUpdateChallengeLogStatusByConfirmation(confirmationID, userID)
 
 	assert.NoError(t, err)
 	mockChallengeRepo.AssertCalled(t, "GetConfirmationsByChallengeID", challengeID, userID)
 	mockChallengeRepo.AssertCalled(t, "UpdateChallengeLog", mock.Anything)
+	mockChallengeRepo.AssertCalled(t, "RecordChallengeImpact", challengeID)
+}
+
+func TestCheckAndUpdateChallengeLogStatusByConfirmation_NotifiesRewards(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockNotifier := new(MockNotifier)
+	mockMailer := new(MockMailer)
+	service := NewChallengeService(mockChallengeRepo, nil, mockMailer, mockNotifier, nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{
+		ID:              "confirmation1",
+		ChallengeTaskID: "task1",
+	}, nil)
+	mockChallengeRepo.On("GetChallengeTaskByID", "task1").Return(challenges.ChallengeTask{ID: "task1", ChallengeID: "challenge1"}, nil)
+	mockChallengeRepo.On("GetChallengeLogByChallengeIDAndUserID", "challenge1", "user1").Return(challenges.ChallengeLog{ID: "log1"}, nil)
+	mockChallengeRepo.On("GetConfirmationsByChallengeID", "challenge1", "user1").Return([]challenges.ChallengeConfirmation{
+		{Status: "Done"},
+	}, nil)
+	mockChallengeRepo.On("UpdateChallengeLog", mock.Anything).Return(nil)
+	mockChallengeRepo.On("RecordChallengeImpact", "log1").Return(nil)
+	mockNotifier.On("Notify", mock.MatchedBy(func(n notifications.Notification) bool {
+		return n.UserID == "user1" && n.Type == notifications.TypeReward && n.Link == "/challenges/challenge1"
+	})).Return(nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1", Title: "Plastic Free Week"}, nil)
+	mockChallengeRepo.On("GetUserNameAndEmail", "user1").Return("User One", "user1@example.com", nil)
+	mockMailer.On("SendTemplate", "user1@example.com", mail.TemplateChallengeCompleted, map[string]interface{}{
+		"Name":           "User One",
+		"ChallengeTitle": "Plastic Free Week",
+	}).Return(nil)
+
+	err := service.CheckAndUpdateChallengeLogStatusByConfirmation("confirmation1", "user1")
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
+}
+
+func TestCheckAndUpdateChallengeLogStatusByConfirmation_CompletionEmailFailureIsIgnored(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockMailer := new(MockMailer)
+	service := NewChallengeService(mockChallengeRepo, nil, mockMailer, newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{
+		ID:              "confirmation1",
+		ChallengeTaskID: "task1",
+	}, nil)
+	mockChallengeRepo.On("GetChallengeTaskByID", "task1").Return(challenges.ChallengeTask{ID: "task1", ChallengeID: "challenge1"}, nil)
+	mockChallengeRepo.On("GetChallengeLogByChallengeIDAndUserID", "challenge1", "user1").Return(challenges.ChallengeLog{ID: "log1"}, nil)
+	mockChallengeRepo.On("GetConfirmationsByChallengeID", "challenge1", "user1").Return([]challenges.ChallengeConfirmation{
+		{Status: "Done"},
+	}, nil)
+	mockChallengeRepo.On("UpdateChallengeLog", mock.Anything).Return(nil)
+	mockChallengeRepo.On("RecordChallengeImpact", "log1").Return(nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1", Title: "Plastic Free Week"}, nil)
+	mockChallengeRepo.On("GetUserNameAndEmail", "user1").Return("", "", errors.New("user not found"))
+
+	err := service.CheckAndUpdateChallengeLogStatusByConfirmation("confirmation1", "user1")
+
+	assert.NoError(t, err)
+	mockMailer.AssertNotCalled(t, "SendTemplate", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestCheckAndUpdateChallengeLogStatusByConfirmation_ErrorOnGetChallengeTaskByID(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	confirmationID := "confirmation1"
 	userID := "user1"
@@ -980,7 +1346,7 @@ func TestCheckAndUpdateChallengeLogStatusByConfirmation_ErrorOnGetChallengeTaskB
 func TestCheckAndUpdateChallengeLogStatusByConfirmation_ErrorOnGetChallengeLog(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	confirmationID := "confirmation1"
 	userID := "user1"
@@ -1007,7 +1373,7 @@ func TestCheckAndUpdateChallengeLogStatusByConfirmation_ErrorOnGetChallengeLog(t
 func TestCheckAndUpdateChallengeLogStatusByConfirmation_ErrorOnGetConfirmations(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	confirmationID := "confirmation1"
 	userID := "user1"
@@ -1038,7 +1404,7 @@ func TestCheckAndUpdateChallengeLogStatusByConfirmation_ErrorOnGetConfirmations(
 func TestCheckAndUpdateChallengeLogStatusByConfirmation_ErrorOnUpdateChallengeLog(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	confirmationID := "confirmation1"
 	userID := "user1"
@@ -1076,7 +1442,7 @@ func TestCheckAndUpdateChallengeLogStatusByConfirmation_ErrorOnUpdateChallengeLo
 func TestCheckAndUpdateChallengeLogStatusByConfirmation_NotAllDone(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	confirmationID := "confirmation1"
 	userID := "user1"
@@ -1111,7 +1477,7 @@ func TestCheckAndUpdateChallengeLogStatusByConfirmation_NotAllDone(t *testing.T)
 func TestCheckAndUpdateChallengeLogStatusByConfirmation_ErrorHandling(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	confirmationID := "confirmation1"
 	userID := "user1"
@@ -1127,15 +1493,17 @@ func TestCheckAndUpdateChallengeLogStatusByConfirmation_ErrorHandling(t *testing
 func TestClaimRewards_Success(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeLogID := "log1"
 	userID := "user1"
 	challengeID := "challenge1"
 
+	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{ID: challengeLogID, UserID: "user1", Status: "Done"}, nil)
 	mockChallengeRepo.On("IsRewardClaimed", challengeLogID).Return(false, nil)
 	mockChallengeRepo.On("GetChallengeIDByLogID", challengeLogID).Return(challengeID, nil)
 	mockChallengeRepo.On("GetChallengeRewards", challengeID).Return(100, 50, nil)
+	mockChallengeRepo.On("GetSeasonMultiplier", challengeID).Return(1.0, nil)
 	mockChallengeRepo.On("UpdateRewardsGiven", challengeLogID).Return(nil)
 	mockChallengeRepo.On("AddUserRewards", userID, 100, 50).Return(nil)
 
@@ -1152,10 +1520,11 @@ func TestClaimRewards_Success(t *testing.T) {
 func TestClaimRewards_AlreadyClaimed(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeLogID := "log1"
 
+	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{ID: challengeLogID, UserID: "user1", Status: "Done"}, nil)
 	mockChallengeRepo.On("IsRewardClaimed", challengeLogID).Return(true, nil)
 
 	err := service.ClaimRewards(challengeLogID, "user1")
@@ -1168,10 +1537,11 @@ func TestClaimRewards_AlreadyClaimed(t *testing.T) {
 func TestClaimRewards_ErrorOnIsRewardClaimed(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeLogID := "log1"
 
+	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{ID: challengeLogID, UserID: "user1", Status: "Done"}, nil)
 	mockChallengeRepo.On("IsRewardClaimed", challengeLogID).Return(false, errors.New("database error"))
 
 	err := service.ClaimRewards(challengeLogID, "user1")
@@ -1184,10 +1554,11 @@ func TestClaimRewards_ErrorOnIsRewardClaimed(t *testing.T) {
 func TestClaimRewards_ErrorOnGetChallengeIDByLogID(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeLogID := "log1"
 
+	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{ID: challengeLogID, UserID: "user1", Status: "Done"}, nil)
 	mockChallengeRepo.On("IsRewardClaimed", challengeLogID).Return(false, nil)
 	mockChallengeRepo.On("GetChallengeIDByLogID", challengeLogID).Return("", errors.New("database error"))
 
@@ -1202,11 +1573,12 @@ func TestClaimRewards_ErrorOnGetChallengeIDByLogID(t *testing.T) {
 func TestClaimRewards_ErrorOnGetChallengeRewards(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeLogID := "log1"
 	challengeID := "challenge1"
 
+	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{ID: challengeLogID, UserID: "user1", Status: "Done"}, nil)
 	mockChallengeRepo.On("IsRewardClaimed", challengeLogID).Return(false, nil)
 	mockChallengeRepo.On("GetChallengeIDByLogID", challengeLogID).Return(challengeID, nil)
 	mockChallengeRepo.On("GetChallengeRewards", challengeID).Return(0, 0, errors.New("database error"))
@@ -1223,14 +1595,16 @@ func TestClaimRewards_ErrorOnGetChallengeRewards(t *testing.T) {
 func TestClaimRewards_ErrorOnUpdateRewardsGiven(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeLogID := "log1"
 	challengeID := "challenge1"
 
+	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{ID: challengeLogID, UserID: "user1", Status: "Done"}, nil)
 	mockChallengeRepo.On("IsRewardClaimed", challengeLogID).Return(false, nil)
 	mockChallengeRepo.On("GetChallengeIDByLogID", challengeLogID).Return(challengeID, nil)
 	mockChallengeRepo.On("GetChallengeRewards", challengeID).Return(100, 50, nil)
+	mockChallengeRepo.On("GetSeasonMultiplier", challengeID).Return(1.0, nil)
 	mockChallengeRepo.On("UpdateRewardsGiven", challengeLogID).Return(errors.New("database error"))
 
 	err := service.ClaimRewards(challengeLogID, "user1")
@@ -1246,14 +1620,16 @@ func TestClaimRewards_ErrorOnUpdateRewardsGiven(t *testing.T) {
 func TestClaimRewards_ErrorOnAddUserRewards(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
 	mockImpactRepo := new(MockImpactRepository)
-	service := NewChallengeService(mockChallengeRepo, mockImpactRepo)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeLogID := "log1"
 	challengeID := "challenge1"
 
+	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{ID: challengeLogID, UserID: "user1", Status: "Done"}, nil)
 	mockChallengeRepo.On("IsRewardClaimed", challengeLogID).Return(false, nil)
 	mockChallengeRepo.On("GetChallengeIDByLogID", challengeLogID).Return(challengeID, nil)
 	mockChallengeRepo.On("GetChallengeRewards", challengeID).Return(100, 50, nil)
+	mockChallengeRepo.On("GetSeasonMultiplier", challengeID).Return(1.0, nil)
 	mockChallengeRepo.On("UpdateRewardsGiven", challengeLogID).Return(nil)
 	mockChallengeRepo.On("AddUserRewards", "user1", 100, 50).Return(errors.New("database error"))
 
@@ -1268,117 +1644,763 @@ func TestClaimRewards_ErrorOnAddUserRewards(t *testing.T) {
 	mockChallengeRepo.AssertCalled(t, "AddUserRewards", "user1", 100, 50)
 }
 
-func TestGetActiveChallenges_Success(t *testing.T) {
+func TestClaimRewards_NotCompleted(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
-
-	userID := "user1"
-	page, perPage := 1, 10
-	difficulty := "medium"
-	title := "Save"
+	mockImpactRepo := new(MockImpactRepository)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
-	mockChallengeRepo.On("GetChallengeLogByUserID", userID, page, perPage, difficulty, title).Return([]challenges.ChallengeLog{
-		{ID: "log1", UserID: userID},
-	}, 1, nil)
+	challengeLogID := "log1"
 
-	challenges, total, err := service.GetActiveChallenges(userID, page, perPage, difficulty, title)
+	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{ID: challengeLogID, UserID: "user1", Status: "Progress"}, nil)
 
-	assert.NoError(t, err)
-	assert.Equal(t, 1, total)
-	assert.Len(t, challenges, 1)
+	err := service.ClaimRewards(challengeLogID, "user1")
 
-	mockChallengeRepo.AssertCalled(t, "GetChallengeLogByUserID", userID, page, perPage, difficulty, title)
+	assert.Equal(t, constant.ErrChallengeNotCompleted, err)
+	mockChallengeRepo.AssertNotCalled(t, "AddUserRewards", mock.Anything, mock.Anything, mock.Anything)
 }
 
-
-func TestGetUnclaimedChallenges_Success(t *testing.T) {
+func TestClaimRewards_NotOwner(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
+	mockImpactRepo := new(MockImpactRepository)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
-	userID := "user1"
-	isAdmin := false
-	page, limit := 1, 10
-	difficulty := "easy"
-	title := "Plant"
+	challengeLogID := "log1"
 
-	mockChallengeRepo.On("GetUnclaimedChallenges", userID, isAdmin, page, limit, difficulty, title).Return([]challenges.Challenge{
-		{ID: "challenge1", Title: "Plant Trees"},
-	}, 1, nil)
+	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{ID: challengeLogID, UserID: "anotherUser", Status: "Done"}, nil)
 
-	challenges, total, err := service.GetUnclaimedChallenges(userID, isAdmin, page, limit, difficulty, title)
+	err := service.ClaimRewards(challengeLogID, "user1")
 
-	assert.NoError(t, err)
-	assert.Equal(t, 1, total)
-	assert.Len(t, challenges, 1)
-	mockChallengeRepo.AssertCalled(t, "GetUnclaimedChallenges", userID, isAdmin, page, limit, difficulty, title)
+	assert.Equal(t, constant.ErrUnauthorized, err)
+	mockChallengeRepo.AssertNotCalled(t, "AddUserRewards", mock.Anything, mock.Anything, mock.Anything)
 }
 
-func TestGetChallengeDetailsWithConfirmations_Success(t *testing.T) {
+func TestUpdateChallengeConfirmationProgress_RequiresReview(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
-
-	userID := "user1"
-	challengeLogID := "log1"
-
-	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{
-		ID:          challengeLogID,
-		UserID:      userID,
-		ChallengeID: "challenge1",
-	}, nil)
+	mockImpactRepo := new(MockImpactRepository)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
-	mockChallengeRepo.On("GetConfirmationsByChallengeID", "challenge1", userID).Return([]challenges.ChallengeConfirmation{
-		{ID: "conf1", ChallengeTaskID: "task1", UserID: userID, Status: "Done"},
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{
+		ID:              "confirmation1",
+		ChallengeTaskID: "task1",
+		UserID:          "user1",
+		Status:          "Rejected",
 	}, nil)
+	mockChallengeRepo.On("GetChallengeTaskByID", "task1").Return(challenges.ChallengeTask{ID: "task1", ChallengeID: "challenge1", DayNumber: 2}, nil)
+	mockChallengeRepo.On("GetChallengeLogByChallengeIDAndUserID", "challenge1", "user1").Return(challenges.ChallengeLog{ID: "log1", StartDate: time.Now()}, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1", RequiresReview: true}, nil)
+	mockChallengeRepo.On("UpdateChallengeConfirmation", mock.MatchedBy(func(confirmation challenges.ChallengeConfirmation) bool {
+		return confirmation.Status == "Pending Review"
+	})).Return(nil)
 
-	result, err := service.GetChallengeDetailsWithConfirmations(userID, challengeLogID)
+	err := service.UpdateChallengeConfirmationProgress("confirmation1", "http://example.com/image.png", "user1")
 
 	assert.NoError(t, err)
-	assert.Equal(t, challengeLogID, result.ChallengeLog.ID)
-	assert.Len(t, result.Confirmations, 1)
-	mockChallengeRepo.AssertCalled(t, "GetChallengeLogByID", challengeLogID)
-	mockChallengeRepo.AssertCalled(t, "GetConfirmationsByChallengeID", "challenge1", userID)
+	mockChallengeRepo.AssertExpectations(t)
 }
 
-func TestGetChallengeDetailsWithConfirmations_Unauthorized(t *testing.T) {
+func TestUpdateChallengeConfirmationProgress_AlreadySubmitted(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
-
-	userID := "user1"
-	challengeLogID := "log1"
+	mockImpactRepo := new(MockImpactRepository)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
-	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{
-		ID:     challengeLogID,
-		UserID: "anotherUser",
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{
+		ID:     "confirmation1",
+		UserID: "user1",
+		Status: "Pending Review",
 	}, nil)
 
-	result, err := service.GetChallengeDetailsWithConfirmations(userID, challengeLogID)
+	err := service.UpdateChallengeConfirmationProgress("confirmation1", "http://example.com/image.png", "user1")
 
-	assert.Error(t, err)
-	assert.Equal(t, constant.ErrUnauthorized, err)
-	assert.Empty(t, result)
-	mockChallengeRepo.AssertCalled(t, "GetChallengeLogByID", challengeLogID)
+	assert.Equal(t, constant.ErrChallengeConfirmationAlreadySubmitted, err)
+	mockChallengeRepo.AssertNotCalled(t, "UpdateChallengeConfirmation", mock.Anything)
 }
 
-func TestGetChallengeDetailsWithConfirmations_ErrorOnGetChallengeLog(t *testing.T) {
+func TestUpdateChallengeConfirmationProgress_DeadlinePassed(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
-
-	userID := "user1"
-	challengeLogID := "log1"
+	mockImpactRepo := new(MockImpactRepository)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
-	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{}, errors.New("database error"))
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{
+		ID:              "confirmation1",
+		ChallengeTaskID: "task1",
+		UserID:          "user1",
+		Status:          "Rejected",
+	}, nil)
+	mockChallengeRepo.On("GetChallengeTaskByID", "task1").Return(challenges.ChallengeTask{ID: "task1", ChallengeID: "challenge1", DayNumber: 1}, nil)
+	mockChallengeRepo.On("GetChallengeLogByChallengeIDAndUserID", "challenge1", "user1").Return(challenges.ChallengeLog{ID: "log1", StartDate: time.Now().AddDate(0, 0, -3)}, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1"}, nil)
 
-	result, err := service.GetChallengeDetailsWithConfirmations(userID, challengeLogID)
+	err := service.UpdateChallengeConfirmationProgress("confirmation1", "http://example.com/image.png", "user1")
 
-	assert.Error(t, err)
-	assert.EqualError(t, err, "database error")
-	assert.Empty(t, result)
-	mockChallengeRepo.AssertCalled(t, "GetChallengeLogByID", challengeLogID)
+	assert.Equal(t, constant.ErrChallengeTaskDeadlinePassed, err)
+	mockChallengeRepo.AssertNotCalled(t, "UpdateChallengeConfirmation", mock.Anything)
+}
+
+func TestUpdateChallengeConfirmationProgress_StoredDeadline(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	// The log started long ago, but the stored deadline (which includes the
+	// grace period) has not passed yet.
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{
+		ID:              "confirmation1",
+		ChallengeTaskID: "task1",
+		UserID:          "user1",
+		Status:          "Progress",
+		Deadline:        time.Now().Add(time.Hour),
+	}, nil)
+	mockChallengeRepo.On("GetChallengeTaskByID", "task1").Return(challenges.ChallengeTask{ID: "task1", ChallengeID: "challenge1", DayNumber: 1}, nil)
+	mockChallengeRepo.On("GetChallengeLogByChallengeIDAndUserID", "challenge1", "user1").Return(challenges.ChallengeLog{ID: "log1", Status: "Progress", StartDate: time.Now().AddDate(0, 0, -3)}, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1"}, nil)
+	mockChallengeRepo.On("UpdateChallengeConfirmation", mock.Anything).Return(nil)
+
+	err := service.UpdateChallengeConfirmationProgress("confirmation1", "http://example.com/image.png", "user1")
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertCalled(t, "UpdateChallengeConfirmation", mock.Anything)
+}
+
+func TestUpdateChallengeConfirmationProgress_StoredDeadlinePassed(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{
+		ID:              "confirmation1",
+		ChallengeTaskID: "task1",
+		UserID:          "user1",
+		Status:          "Progress",
+		Deadline:        time.Now().Add(-time.Minute),
+	}, nil)
+	mockChallengeRepo.On("GetChallengeTaskByID", "task1").Return(challenges.ChallengeTask{ID: "task1", ChallengeID: "challenge1", DayNumber: 1}, nil)
+	mockChallengeRepo.On("GetChallengeLogByChallengeIDAndUserID", "challenge1", "user1").Return(challenges.ChallengeLog{ID: "log1", Status: "Progress", StartDate: time.Now()}, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1"}, nil)
+
+	err := service.UpdateChallengeConfirmationProgress("confirmation1", "http://example.com/image.png", "user1")
+
+	assert.Equal(t, constant.ErrChallengeTaskDeadlinePassed, err)
+	mockChallengeRepo.AssertNotCalled(t, "UpdateChallengeConfirmation", mock.Anything)
+}
+
+func TestCreateChallengeLogWithConfirmation_DeadlinesInUserTimezone(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	newYork, _ := time.LoadLocation("America/New_York")
+	startDate := time.Date(2024, 3, 1, 23, 30, 0, 0, newYork)
+	log := challenges.ChallengeLog{ChallengeID: "challenge1", UserID: "user1", StartDate: startDate}
+
+	var deadlines []time.Time
+	mockChallengeRepo.On("IsChallengeTaken", "user1", "challenge1").Return(false, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1", Status: "Published", GracePeriodHours: 2}, nil)
+	mockChallengeRepo.On("GetUserTimezone", "user1").Return("America/New_York", nil)
+	mockChallengeRepo.On("ReserveChallengeSeat", "challenge1").Return(true, nil)
+	mockChallengeRepo.On("CreateChallengeLog", mock.Anything).Return(nil)
+	mockChallengeRepo.On("GetTasksByChallengeID", "challenge1").Return([]challenges.ChallengeTask{
+		{ID: "task1", DayNumber: 1},
+		{ID: "task2", DayNumber: 2},
+	}, nil)
+	mockChallengeRepo.On("CreateChallengeConfirmation", mock.Anything).Run(func(args mock.Arguments) {
+		deadlines = append(deadlines, args.Get(0).(challenges.ChallengeConfirmation).Deadline)
+	}).Return(nil)
+	mockChallengeRepo.On("IncrementChallengeCounts", "challenge1", 2, false).Return(nil)
+
+	err := service.CreateChallengeLogWithConfirmation(log)
+
+	assert.NoError(t, err)
+	assert.Len(t, deadlines, 2)
+	assert.True(t, time.Date(2024, 3, 2, 2, 0, 0, 0, newYork).Equal(deadlines[0]))
+	assert.True(t, time.Date(2024, 3, 3, 2, 0, 0, 0, newYork).Equal(deadlines[1]))
+}
+
+func TestTaskDeadline_UsesLocalDay(t *testing.T) {
+	jakarta, _ := time.LoadLocation("Asia/Jakarta")
+
+	// 20:00 UTC is already the next day in Jakarta (UTC+7).
+	startDate := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+
+	deadline := taskDeadline(startDate, 1, 0, jakarta)
+
+	assert.True(t, time.Date(2024, 3, 3, 0, 0, 0, 0, jakarta).Equal(deadline))
+}
+
+func TestSweepOverdueTasks_Success(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetUndatedTasks").Return([]challenges.UndatedTask{}, nil)
+	mockChallengeRepo.On("FailOverdueTasks", mock.Anything).Return(3, nil)
+
+	err := service.SweepOverdueTasks()
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertCalled(t, "FailOverdueTasks", mock.Anything)
+}
+
+func TestSweepOverdueTasks_Error(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetUndatedTasks").Return([]challenges.UndatedTask{}, nil)
+	mockChallengeRepo.On("FailOverdueTasks", mock.Anything).Return(0, errors.New("db error"))
+
+	err := service.SweepOverdueTasks()
+
+	assert.EqualError(t, err, "db error")
+}
+
+func TestSweepOverdueTasks_BackfillsDeadlines(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	startDate := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+	mockChallengeRepo.On("GetUndatedTasks").Return([]challenges.UndatedTask{
+		{ConfirmationID: "c1", StartDate: startDate, DayNumber: 2, GracePeriodHours: 6},
+	}, nil)
+	mockChallengeRepo.On("SetTaskDeadline", "c1", time.Date(2024, 3, 3, 6, 0, 0, 0, time.Local)).Return(nil)
+	mockChallengeRepo.On("FailOverdueTasks", mock.Anything).Return(1, nil)
+
+	err := service.SweepOverdueTasks()
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertExpectations(t)
+}
+
+func TestRemindUpcomingDeadlines_Success(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockNotifier := new(MockNotifier)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), mockNotifier, nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetUpcomingDeadlines", mock.Anything, mock.Anything).Return([]challenges.ChallengeConfirmation{
+		{ID: "confirmation1", UserID: "user1", Deadline: time.Now().Add(2 * time.Hour), ChallengeTitle: "Zero Waste", ChallengeTask: challenges.ChallengeTask{ChallengeID: "challenge1", DayNumber: 2}},
+		{ID: "confirmation2", UserID: "user2", Deadline: time.Now().Add(5 * time.Hour), ChallengeTitle: "Zero Waste", ChallengeTask: challenges.ChallengeTask{ChallengeID: "challenge1", DayNumber: 1}},
+	}, nil)
+	mockNotifier.On("Notify", mock.MatchedBy(func(n notifications.Notification) bool {
+		return n.UserID == "user1" && n.Type == notifications.TypeChallengeDeadline && n.Link == "/challenges/challenge1"
+	})).Return(nil)
+	mockNotifier.On("Notify", mock.MatchedBy(func(n notifications.Notification) bool {
+		return n.UserID == "user2"
+	})).Return(errors.New("delivery failed"))
+	mockChallengeRepo.On("MarkDeadlinesReminded", []string{"confirmation1", "confirmation2"}, mock.Anything).Return(nil)
+
+	err := service.RemindUpcomingDeadlines()
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+	mockChallengeRepo.AssertExpectations(t)
+}
+
+func TestRemindUpcomingDeadlines_NothingDue(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetUpcomingDeadlines", mock.Anything, mock.Anything).Return([]challenges.ChallengeConfirmation{}, nil)
+
+	err := service.RemindUpcomingDeadlines()
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertNotCalled(t, "MarkDeadlinesReminded", mock.Anything, mock.Anything)
+}
+
+func TestGetPendingConfirmations_Success(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockImpactRepo := new(MockImpactRepository)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetPendingConfirmations", 1, 20).Return([]challenges.ChallengeConfirmation{
+		{ID: "confirmation1", Status: "Pending Review"},
+	}, 1, nil)
+
+	result, totalPages, err := service.GetPendingConfirmations(1)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, 1, totalPages)
+}
+
+func TestReviewChallengeConfirmation_Approve(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockImpactRepo := new(MockImpactRepository)
+	mockMailer := new(MockMailer)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, mockMailer, newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{
+		ID:              "confirmation1",
+		ChallengeTaskID: "task1",
+		UserID:          "user1",
+		Status:          "Pending Review",
+	}, nil)
+	mockChallengeRepo.On("UpdateConfirmationReview", mock.MatchedBy(func(confirmation challenges.ChallengeConfirmation) bool {
+		return confirmation.Status == "Done" && confirmation.ReviewedBy == "admin1" && confirmation.ReviewedAt != nil
+	})).Return(nil)
+	mockChallengeRepo.On("GetChallengeTaskByID", "task1").Return(challenges.ChallengeTask{ID: "task1", ChallengeID: "challenge1"}, nil)
+	mockChallengeRepo.On("GetChallengeLogByChallengeIDAndUserID", "challenge1", "user1").Return(challenges.ChallengeLog{ID: "log1", Status: "Progress"}, nil)
+	mockChallengeRepo.On("GetConfirmationsByChallengeID", "challenge1", "user1").Return([]challenges.ChallengeConfirmation{
+		{ID: "confirmation1", Status: "Done"},
+	}, nil)
+	mockChallengeRepo.On("UpdateChallengeLog", challenges.ChallengeLog{ID: "log1", Status: "Done"}).Return(nil)
+	mockChallengeRepo.On("RecordChallengeImpact", "log1").Return(nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1"}, nil)
+	mockChallengeRepo.On("GetUserNameAndEmail", "user1").Return("User One", "user1@example.com", nil)
+	mockMailer.On("SendTemplate", "user1@example.com", mail.TemplateChallengeCompleted, mock.Anything).Return(nil)
+
+	err := service.ReviewChallengeConfirmation("confirmation1", "admin1", "approve", "")
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertExpectations(t)
+}
+
+func TestReviewChallengeConfirmation_Reject(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockImpactRepo := new(MockImpactRepository)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{
+		ID:     "confirmation1",
+		UserID: "user1",
+		Status: "Pending Review",
+	}, nil)
+	mockChallengeRepo.On("UpdateConfirmationReview", mock.MatchedBy(func(confirmation challenges.ChallengeConfirmation) bool {
+		return confirmation.Status == "Rejected" && confirmation.RejectionReason == "Photo is blurry"
+	})).Return(nil)
+
+	err := service.ReviewChallengeConfirmation("confirmation1", "admin1", "reject", "Photo is blurry")
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertNotCalled(t, "UpdateChallengeLog", mock.Anything)
+}
+
+func TestReviewChallengeConfirmation_RejectWithoutReason(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockImpactRepo := new(MockImpactRepository)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	err := service.ReviewChallengeConfirmation("confirmation1", "admin1", "reject", "")
+
+	assert.Equal(t, constant.ErrRejectionReasonRequired, err)
+	mockChallengeRepo.AssertNotCalled(t, "GetChallengeConfirmationByID", mock.Anything)
+}
+
+func TestReviewChallengeConfirmation_NotPending(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockImpactRepo := new(MockImpactRepository)
+	service := NewChallengeService(mockChallengeRepo, mockImpactRepo, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{
+		ID:     "confirmation1",
+		Status: "Done",
+	}, nil)
+
+	err := service.ReviewChallengeConfirmation("confirmation1", "admin1", "approve", "")
+
+	assert.Equal(t, constant.ErrChallengeConfirmationNotPending, err)
+	mockChallengeRepo.AssertNotCalled(t, "UpdateConfirmationReview", mock.Anything)
+}
+
+func TestClaimRewards_SeasonMultiplier(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	challengeLogID := "log1"
+	userID := "user1"
+	challengeID := "challenge1"
+
+	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{ID: challengeLogID, UserID: userID, Status: "Done"}, nil)
+	mockChallengeRepo.On("IsRewardClaimed", challengeLogID).Return(false, nil)
+	mockChallengeRepo.On("GetChallengeIDByLogID", challengeLogID).Return(challengeID, nil)
+	mockChallengeRepo.On("GetChallengeRewards", challengeID).Return(100, 50, nil)
+	mockChallengeRepo.On("GetSeasonMultiplier", challengeID).Return(1.5, nil)
+	mockChallengeRepo.On("UpdateRewardsGiven", challengeLogID).Return(nil)
+	mockChallengeRepo.On("AddUserRewards", userID, 150, 75).Return(nil)
+
+	err := service.ClaimRewards(challengeLogID, userID)
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertCalled(t, "AddUserRewards", userID, 150, 75)
+}
+
+func TestCreateChallengeLogWithConfirmation_NotPublished(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	log := challenges.ChallengeLog{ChallengeID: "challenge1", UserID: "user1"}
+
+	mockChallengeRepo.On("IsChallengeTaken", "user1", "challenge1").Return(false, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1", Status: "Draft"}, nil)
+
+	err := service.CreateChallengeLogWithConfirmation(log)
+
+	assert.Equal(t, constant.ErrChallengeNotAvailable, err)
+	mockChallengeRepo.AssertNotCalled(t, "CreateChallengeLog", mock.Anything)
+}
+
+func TestCreateChallengeLogWithConfirmation_OutsideEventWindow(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	log := challenges.ChallengeLog{ChallengeID: "challenge1", UserID: "user1"}
+	endAt := time.Now().Add(-time.Hour)
+
+	mockChallengeRepo.On("IsChallengeTaken", "user1", "challenge1").Return(false, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1", Status: "Published", EndAt: &endAt}, nil)
+
+	err := service.CreateChallengeLogWithConfirmation(log)
+
+	assert.Equal(t, constant.ErrChallengeNotAvailable, err)
+}
+
+func TestCreateChallengeLogWithConfirmation_EnrollmentFull(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	log := challenges.ChallengeLog{ChallengeID: "challenge1", UserID: "user1"}
+
+	mockChallengeRepo.On("IsChallengeTaken", "user1", "challenge1").Return(false, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1", Status: "Published", EnrollmentCap: 10, ParticipantCount: 9}, nil)
+	mockChallengeRepo.On("GetUserTimezone", "user1").Return("Asia/Jakarta", nil)
+	// Another user took the last seat after the challenge was loaded.
+	mockChallengeRepo.On("ReserveChallengeSeat", "challenge1").Return(false, nil)
+
+	err := service.CreateChallengeLogWithConfirmation(log)
+
+	assert.Equal(t, constant.ErrChallengeEnrollmentFull, err)
+	mockChallengeRepo.AssertNotCalled(t, "CreateChallengeLog", mock.Anything)
+}
+
+func TestPublishChallenge_Immediate(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1", Status: "Draft"}, nil)
+	mockChallengeRepo.On("UpdateChallengeStatus", "challenge1", "Published").Return(nil)
+
+	err := service.PublishChallenge("challenge1")
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertCalled(t, "UpdateChallengeStatus", "challenge1", "Published")
+}
+
+func TestPublishChallenge_Scheduled(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	startAt := time.Now().Add(24 * time.Hour)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1", Status: "Draft", StartAt: &startAt}, nil)
+	mockChallengeRepo.On("UpdateChallengeStatus", "challenge1", "Scheduled").Return(nil)
+
+	err := service.PublishChallenge("challenge1")
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertCalled(t, "UpdateChallengeStatus", "challenge1", "Scheduled")
+}
+
+func TestPublishChallenge_Ended(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1", Status: "Ended"}, nil)
+
+	err := service.PublishChallenge("challenge1")
+
+	assert.Equal(t, constant.ErrChallengeEventEnded, err)
+	mockChallengeRepo.AssertNotCalled(t, "UpdateChallengeStatus", mock.Anything, mock.Anything)
+}
+
+func TestSyncChallengeSchedules_NotifiesParticipants(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockMailer := new(MockMailer)
+	service := NewChallengeService(mockChallengeRepo, nil, mockMailer, newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("PublishScheduledChallenges", mock.Anything).Return(nil)
+	mockChallengeRepo.On("EndExpiredChallenges", mock.Anything).Return([]challenges.Challenge{{ID: "challenge1", Title: "Plastic Free Week"}}, nil)
+	mockChallengeRepo.On("GetEnrolledUserEmails", "challenge1").Return([]string{"a@mail.com", "b@mail.com"}, nil)
+	mockMailer.On("SendNotification", "a@mail.com", "Challenge Event Ended", mock.Anything).Return(errors.New("smtp error"))
+	mockMailer.On("SendNotification", "b@mail.com", "Challenge Event Ended", mock.Anything).Return(nil)
+
+	err := service.SyncChallengeSchedules()
+
+	assert.NoError(t, err)
+	mockMailer.AssertNumberOfCalls(t, "SendNotification", 2)
+}
+
+func TestCreateSeason_Success(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	season := challenges.ChallengeSeason{
+		Name:            "Earth Month",
+		StartAt:         time.Now(),
+		EndAt:           time.Now().AddDate(0, 1, 0),
+		BonusMultiplier: 2,
+	}
+	mockChallengeRepo.On("CreateSeason", mock.Anything).Return(nil)
+
+	created, err := service.CreateSeason(season)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "Earth Month", created.Name)
+}
+
+func TestCreateSeason_InvalidMultiplier(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	season := challenges.ChallengeSeason{
+		Name:            "Earth Month",
+		StartAt:         time.Now(),
+		EndAt:           time.Now().AddDate(0, 1, 0),
+		BonusMultiplier: 0.5,
+	}
+
+	_, err := service.CreateSeason(season)
+
+	assert.Equal(t, constant.ErrInvalidBonusMultiplier, err)
+	mockChallengeRepo.AssertNotCalled(t, "CreateSeason", mock.Anything)
+}
+
+func TestCreateSeason_InvalidSchedule(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	season := challenges.ChallengeSeason{
+		Name:            "Earth Month",
+		StartAt:         time.Now(),
+		EndAt:           time.Now().AddDate(0, 0, -1),
+		BonusMultiplier: 1,
+	}
+
+	_, err := service.CreateSeason(season)
+
+	assert.Equal(t, constant.ErrInvalidChallengeSchedule, err)
+}
+
+func categorized(difficulty string, categoryIDs ...string) challenges.Challenge {
+	challenge := challenges.Challenge{Difficulty: difficulty}
+	for _, categoryID := range categoryIDs {
+		challenge.ImpactCategories = append(challenge.ImpactCategories, challenges.ChallengeImpactCategory{
+			ImpactCategoryID: categoryID,
+			ImpactCategory:   impacts.ImpactCategory{ID: categoryID, Name: categoryID},
+		})
+	}
+	return challenge
+}
+
+func TestCreateChallengeLogWithConfirmation_Locked(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	log := challenges.ChallengeLog{ChallengeID: "challenge1", UserID: "user1"}
+
+	mockChallengeRepo.On("IsChallengeTaken", "user1", "challenge1").Return(false, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1", Status: "Published", Difficulty: "Hard"}, nil)
+	mockChallengeRepo.On("GetChallengeCategoryIDs", "challenge1").Return([]string{"plastic"}, nil)
+	mockChallengeRepo.On("GetChallengeHistory", "user1").Return([]challenges.ChallengeLog{
+		{Status: "Done", Challenge: categorized("Easy", "plastic")},
+		{Status: "Failed", Challenge: categorized("Medium", "plastic")},
+	}, nil)
+
+	err := service.CreateChallengeLogWithConfirmation(log)
+
+	assert.Equal(t, constant.ErrChallengeLocked, err)
+	mockChallengeRepo.AssertNotCalled(t, "CreateChallengeLog", mock.Anything)
+}
+
+func TestCreateChallengeLogWithConfirmation_UnlockedByEasierChallenge(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	log := challenges.ChallengeLog{ChallengeID: "challenge1", UserID: "user1"}
+
+	mockChallengeRepo.On("IsChallengeTaken", "user1", "challenge1").Return(false, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1", Status: "Published", Difficulty: "medium"}, nil)
+	mockChallengeRepo.On("GetChallengeCategoryIDs", "challenge1").Return([]string{"energy", "plastic"}, nil)
+	mockChallengeRepo.On("GetChallengeHistory", "user1").Return([]challenges.ChallengeLog{
+		{Status: "Done", Challenge: categorized("easy", "plastic")},
+	}, nil)
+	mockChallengeRepo.On("GetUserTimezone", "user1").Return("Asia/Jakarta", nil)
+	mockChallengeRepo.On("ReserveChallengeSeat", "challenge1").Return(true, nil)
+	mockChallengeRepo.On("CreateChallengeLog", mock.Anything).Return(nil)
+	mockChallengeRepo.On("GetTasksByChallengeID", "challenge1").Return([]challenges.ChallengeTask{}, nil)
+	mockChallengeRepo.On("IncrementChallengeCounts", "challenge1", 0, false).Return(nil)
+
+	err := service.CreateChallengeLogWithConfirmation(log)
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertCalled(t, "CreateChallengeLog", mock.Anything)
+}
+
+func TestGetRecommendedChallenges_Success(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	easyPlastic := categorized("Easy", "plastic")
+	easyPlastic.ID = "easy-plastic"
+	mediumPlastic := categorized("Medium", "plastic")
+	mediumPlastic.ID = "medium-plastic"
+	mediumEnergy := categorized("Medium", "energy")
+	mediumEnergy.ID = "medium-energy"
+	easyWater := categorized("Easy", "water")
+	easyWater.ID = "easy-water"
+
+	mockChallengeRepo.On("GetChallengeHistory", "user1").Return([]challenges.ChallengeLog{
+		{Status: "Done", Challenge: categorized("Easy", "plastic")},
+	}, nil)
+	mockChallengeRepo.On("GetAvailableChallenges", "user1").Return([]challenges.Challenge{easyWater, mediumEnergy, easyPlastic, mediumPlastic}, nil)
+	mockChallengeRepo.On("GetPurchasedCategoryCounts", "user1").Return(map[string]int{"water": 2}, nil)
+	mockChallengeRepo.On("GetUserExp", "user1").Return(600, nil)
+
+	recommendations, err := service.GetRecommendedChallenges("user1", 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, recommendations, 3)
+	assert.Equal(t, "medium-plastic", recommendations[0].Challenge.ID)
+	assert.Contains(t, recommendations[0].Reasons, "Next difficulty in plastic")
+	for _, recommendation := range recommendations {
+		assert.NotEqual(t, "medium-energy", recommendation.Challenge.ID)
+	}
+}
+
+func TestGetRecommendedChallenges_Limit(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetChallengeHistory", "user1").Return([]challenges.ChallengeLog{}, nil)
+	mockChallengeRepo.On("GetAvailableChallenges", "user1").Return([]challenges.Challenge{
+		{ID: "c1", Difficulty: "Easy"},
+		{ID: "c2", Difficulty: "Easy"},
+		{ID: "c3", Difficulty: "Easy"},
+	}, nil)
+	mockChallengeRepo.On("GetPurchasedCategoryCounts", "user1").Return(map[string]int{}, nil)
+	mockChallengeRepo.On("GetUserExp", "user1").Return(0, nil)
+
+	recommendations, err := service.GetRecommendedChallenges("user1", 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, recommendations, 2)
+}
+
+func TestGetRecommendedChallenges_ErrorOnHistory(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	mockChallengeRepo.On("GetChallengeHistory", "user1").Return([]challenges.ChallengeLog{}, errors.New("db error"))
+
+	_, err := service.GetRecommendedChallenges("user1", 10)
+
+	assert.EqualError(t, err, "db error")
+}
+
+func TestGetActiveChallenges_Success(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	userID := "user1"
+	page, perPage := 1, 10
+	difficulty := "medium"
+	title := "Save"
+
+	mockChallengeRepo.On("GetChallengeLogByUserID", userID, page, perPage, difficulty, title).Return([]challenges.ChallengeLog{
+		{ID: "log1", UserID: userID},
+	}, 1, nil)
+
+	challenges, total, err := service.GetActiveChallenges(userID, page, perPage, difficulty, title)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Len(t, challenges, 1)
+
+	mockChallengeRepo.AssertCalled(t, "GetChallengeLogByUserID", userID, page, perPage, difficulty, title)
+}
+
+
+func TestGetUnclaimedChallenges_Success(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	userID := "user1"
+	isAdmin := false
+	page, limit := 1, 10
+	difficulty := "easy"
+	title := "Plant"
+
+	mockChallengeRepo.On("GetUnclaimedChallenges", userID, isAdmin, page, limit, difficulty, title).Return([]challenges.Challenge{
+		{ID: "challenge1", Title: "Plant Trees"},
+	}, 1, nil)
+
+	challenges, total, err := service.GetUnclaimedChallenges(userID, isAdmin, page, limit, difficulty, title)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Len(t, challenges, 1)
+	mockChallengeRepo.AssertCalled(t, "GetUnclaimedChallenges", userID, isAdmin, page, limit, difficulty, title)
+}
+
+func TestGetChallengeDetailsWithConfirmations_Success(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	userID := "user1"
+	challengeLogID := "log1"
+
+	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{
+		ID:          challengeLogID,
+		UserID:      userID,
+		ChallengeID: "challenge1",
+	}, nil)
+
+	mockChallengeRepo.On("GetConfirmationsByChallengeID", "challenge1", userID).Return([]challenges.ChallengeConfirmation{
+		{ID: "conf1", ChallengeTaskID: "task1", UserID: userID, Status: "Done"},
+	}, nil)
+
+	result, err := service.GetChallengeDetailsWithConfirmations(userID, challengeLogID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, challengeLogID, result.ChallengeLog.ID)
+	assert.Len(t, result.Confirmations, 1)
+	mockChallengeRepo.AssertCalled(t, "GetChallengeLogByID", challengeLogID)
+	mockChallengeRepo.AssertCalled(t, "GetConfirmationsByChallengeID", "challenge1", userID)
+}
+
+func TestGetChallengeDetailsWithConfirmations_Unauthorized(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	userID := "user1"
+	challengeLogID := "log1"
+
+	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{
+		ID:     challengeLogID,
+		UserID: "anotherUser",
+	}, nil)
+
+	result, err := service.GetChallengeDetailsWithConfirmations(userID, challengeLogID)
+
+	assert.Error(t, err)
+	assert.Equal(t, constant.ErrUnauthorized, err)
+	assert.Empty(t, result)
+	mockChallengeRepo.AssertCalled(t, "GetChallengeLogByID", challengeLogID)
+}
+
+func TestGetChallengeDetailsWithConfirmations_ErrorOnGetChallengeLog(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	userID := "user1"
+	challengeLogID := "log1"
+
+	mockChallengeRepo.On("GetChallengeLogByID", challengeLogID).Return(challenges.ChallengeLog{}, errors.New("database error"))
+
+	result, err := service.GetChallengeDetailsWithConfirmations(userID, challengeLogID)
+
+	assert.Error(t, err)
+	assert.EqualError(t, err, "database error")
+	assert.Empty(t, result)
+	mockChallengeRepo.AssertCalled(t, "GetChallengeLogByID", challengeLogID)
 }
 
 func TestGetChallengeDetailsWithConfirmations_ErrorOnGetConfirmations(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	userID := "user1"
 	challengeLogID := "log1"
@@ -1402,7 +2424,7 @@ func TestGetChallengeDetailsWithConfirmations_ErrorOnGetConfirmations(t *testing
 
 func TestGetChallengeDetails_Success(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 
@@ -1436,7 +2458,7 @@ func TestGetChallengeDetails_Success(t *testing.T) {
 
 func TestGetChallengeDetails_NoTasks(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 
@@ -1457,7 +2479,7 @@ func TestGetChallengeDetails_NoTasks(t *testing.T) {
 
 func TestGetChallengeDetails_ErrorOnGetChallengeByID(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 
@@ -1473,7 +2495,7 @@ func TestGetChallengeDetails_ErrorOnGetChallengeByID(t *testing.T) {
 
 func TestGetChallengeDetails_ErrorOnGetTasks(t *testing.T) {
 	mockChallengeRepo := new(MockChallengeRepository)
-	service := NewChallengeService(mockChallengeRepo, nil)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
 
 	challengeID := "challenge1"
 
@@ -1490,4 +2512,213 @@ func TestGetChallengeDetails_ErrorOnGetTasks(t *testing.T) {
 	assert.Empty(t, details)
 	mockChallengeRepo.AssertCalled(t, "GetChallengeByID", challengeID)
 	mockChallengeRepo.AssertCalled(t, "GetTasksByChallengeIDforUser", challengeID)
-}
\ No newline at end of file
+}
+type MockProofVerifier struct {
+	mock.Mock
+}
+
+func (m *MockProofVerifier) Verify(ctx context.Context, imageURL string, task challenges.ChallengeTask) (challenges.ProofVerdict, error) {
+	args := m.Called(imageURL, task)
+	return args.Get(0).(challenges.ProofVerdict), args.Error(1)
+}
+
+var proofConfig = configs.ProofVerificationConfig{Enabled: true, AutoApproveConfidence: 0.85}
+
+func TestUpdateChallengeConfirmationProgress_QueuesVerification(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), new(MockProofVerifier), proofConfig)
+
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{
+		ID:              "confirmation1",
+		ChallengeTaskID: "task1",
+		UserID:          "user1",
+		Status:          "Rejected",
+		Verification:    challenges.ProofVerification{Status: challenges.VerificationFlagged, Confidence: 0.2, Attempts: 1},
+	}, nil)
+	mockChallengeRepo.On("GetChallengeTaskByID", "task1").Return(challenges.ChallengeTask{ID: "task1", ChallengeID: "challenge1", DayNumber: 1}, nil)
+	mockChallengeRepo.On("GetChallengeLogByChallengeIDAndUserID", "challenge1", "user1").Return(challenges.ChallengeLog{ID: "log1", StartDate: time.Now()}, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1", RequiresReview: true}, nil)
+	mockChallengeRepo.On("UpdateChallengeConfirmation", mock.MatchedBy(func(confirmation challenges.ChallengeConfirmation) bool {
+		return confirmation.Status == "Pending Review" && confirmation.ChallengeImg == "http://example.com/new.png" &&
+			confirmation.Verification == challenges.ProofVerification{Status: challenges.VerificationPending}
+	})).Return(nil)
+
+	err := service.UpdateChallengeConfirmationProgress("confirmation1", "http://example.com/new.png", "user1")
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertExpectations(t)
+}
+
+func TestUpdateChallengeConfirmationProgress_VerificationSkipsChallengesWithoutReview(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), new(MockProofVerifier), proofConfig)
+
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{
+		ID:              "confirmation1",
+		ChallengeTaskID: "task1",
+		UserID:          "user1",
+		Status:          "Progress",
+	}, nil)
+	mockChallengeRepo.On("GetChallengeTaskByID", "task1").Return(challenges.ChallengeTask{ID: "task1", ChallengeID: "challenge1", DayNumber: 1}, nil)
+	mockChallengeRepo.On("GetChallengeLogByChallengeIDAndUserID", "challenge1", "user1").Return(challenges.ChallengeLog{ID: "log1", StartDate: time.Now()}, nil)
+	mockChallengeRepo.On("GetChallengeByID", "challenge1").Return(challenges.Challenge{ID: "challenge1"}, nil)
+	mockChallengeRepo.On("UpdateChallengeConfirmation", mock.MatchedBy(func(confirmation challenges.ChallengeConfirmation) bool {
+		return confirmation.Status == "Done" && confirmation.Verification == challenges.ProofVerification{}
+	})).Return(nil)
+
+	err := service.UpdateChallengeConfirmationProgress("confirmation1", "http://example.com/new.png", "user1")
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertExpectations(t)
+}
+
+func TestVerifyPendingProofs_Disabled(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), nil, configs.ProofVerificationConfig{})
+
+	err := service.VerifyPendingProofs()
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertNotCalled(t, "GetUnverifiedConfirmations", mock.Anything)
+}
+
+func TestVerifyPendingProofs_AutoApprovesConfidentVerdicts(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockMailer := new(MockMailer)
+	mockVerifier := new(MockProofVerifier)
+	service := NewChallengeService(mockChallengeRepo, nil, mockMailer, newMockNotifier(), mockVerifier, proofConfig)
+	task := challenges.ChallengeTask{ID: "task1", ChallengeID: "challenge1", Name: "Plant a tree", TaskDescription: "Plant a seedling"}
+
+	mockChallengeRepo.On("GetUnverifiedConfirmations", verificationBatchSize).Return([]challenges.ChallengeConfirmation{
+		{ID: "confirmation1", ChallengeTaskID: "task1", UserID: "user1", Status: "Pending Review", ChallengeImg: "http://example.com/tree.png", ChallengeTask: task,
+			Verification: challenges.ProofVerification{Status: challenges.VerificationPending}},
+	}, nil)
+	mockVerifier.On("Verify", "http://example.com/tree.png", task).Return(challenges.ProofVerdict{Confidence: 0.93, Explanation: "Terlihat bibit pohon baru ditanam."}, nil)
+	mockChallengeRepo.On("UpdateConfirmationVerification", "confirmation1", mock.MatchedBy(func(verification challenges.ProofVerification) bool {
+		return verification.Status == challenges.VerificationApproved && verification.Confidence == 0.93 &&
+			verification.Explanation == "Terlihat bibit pohon baru ditanam." && verification.Attempts == 1 && verification.VerifiedAt != nil
+	})).Return(nil)
+
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{
+		ID: "confirmation1", ChallengeTaskID: "task1", UserID: "user1", Status: "Pending Review",
+	}, nil)
+	mockChallengeRepo.On("UpdateConfirmationReview", mock.MatchedBy(func(confirmation challenges.ChallengeConfirmation) bool {
+		return confirmation.Status == "Done" && confirmation.ReviewedBy == challenges.VerifierReviewer && confirmation.ReviewedAt != nil
+	})).Return(nil)
+	mockChallengeRepo.On("GetChallengeTaskByID", "task1").Return(task, nil)
+	mockChallengeRepo.On("GetChallengeLogByChallengeIDAndUserID", "challenge1", "user1").Return(challenges.ChallengeLog{ID: "log1", Status: "Progress"}, nil)
+	mockChallengeRepo.On("GetConfirmationsByChallengeID", "challenge1", "user1").Return([]challenges.ChallengeConfirmation{
+		{ID: "confirmation1", Status: "Done"},
+		{ID: "confirmation2", Status: "Progress"},
+	}, nil)
+
+	err := service.VerifyPendingProofs()
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertExpectations(t)
+	mockVerifier.AssertExpectations(t)
+}
+
+func TestVerifyPendingProofs_FlagsUnsureVerdicts(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockVerifier := new(MockProofVerifier)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), mockVerifier, proofConfig)
+
+	mockChallengeRepo.On("GetUnverifiedConfirmations", verificationBatchSize).Return([]challenges.ChallengeConfirmation{
+		{ID: "confirmation1", Status: "Pending Review", ChallengeImg: "http://example.com/blurry.png"},
+	}, nil)
+	mockVerifier.On("Verify", "http://example.com/blurry.png", mock.Anything).Return(challenges.ProofVerdict{Confidence: 0.4, Explanation: "Foto buram."}, nil)
+	mockChallengeRepo.On("UpdateConfirmationVerification", "confirmation1", mock.MatchedBy(func(verification challenges.ProofVerification) bool {
+		return verification.Status == challenges.VerificationFlagged && verification.Confidence == 0.4 && verification.Explanation == "Foto buram."
+	})).Return(nil)
+
+	err := service.VerifyPendingProofs()
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertExpectations(t)
+	mockChallengeRepo.AssertNotCalled(t, "UpdateConfirmationReview", mock.Anything)
+}
+
+func TestVerifyPendingProofs_ModelErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		status   string
+	}{
+		{"retried on the next run", 0, challenges.VerificationPending},
+		{"left to an admin after the last attempt", challenges.MaxVerificationAttempts - 1, challenges.VerificationError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockChallengeRepo := new(MockChallengeRepository)
+			mockVerifier := new(MockProofVerifier)
+			service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), mockVerifier, proofConfig)
+
+			mockChallengeRepo.On("GetUnverifiedConfirmations", verificationBatchSize).Return([]challenges.ChallengeConfirmation{
+				{ID: "confirmation1", Status: "Pending Review", Verification: challenges.ProofVerification{Status: challenges.VerificationPending, Attempts: tt.attempts}},
+			}, nil)
+			mockVerifier.On("Verify", mock.Anything, mock.Anything).Return(challenges.ProofVerdict{}, errors.New("model unavailable"))
+			mockChallengeRepo.On("UpdateConfirmationVerification", "confirmation1", challenges.ProofVerification{
+				Status: tt.status, Attempts: tt.attempts + 1,
+			}).Return(nil)
+
+			err := service.VerifyPendingProofs()
+
+			assert.NoError(t, err)
+			mockChallengeRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVerifyPendingProofs_AdminReviewedFirst(t *testing.T) {
+	mockChallengeRepo := new(MockChallengeRepository)
+	mockVerifier := new(MockProofVerifier)
+	service := NewChallengeService(mockChallengeRepo, nil, new(MockMailer), newMockNotifier(), mockVerifier, proofConfig)
+
+	mockChallengeRepo.On("GetUnverifiedConfirmations", verificationBatchSize).Return([]challenges.ChallengeConfirmation{
+		{ID: "confirmation1", Status: "Pending Review"},
+	}, nil)
+	mockVerifier.On("Verify", mock.Anything, mock.Anything).Return(challenges.ProofVerdict{Confidence: 0.99}, nil)
+	mockChallengeRepo.On("UpdateConfirmationVerification", "confirmation1", mock.Anything).Return(nil)
+	mockChallengeRepo.On("GetChallengeConfirmationByID", "confirmation1").Return(challenges.ChallengeConfirmation{ID: "confirmation1", Status: "Rejected"}, nil)
+
+	err := service.VerifyPendingProofs()
+
+	assert.NoError(t, err)
+	mockChallengeRepo.AssertNotCalled(t, "UpdateConfirmationReview", mock.Anything)
+}
+
+func TestProofVerifier_SendsImageAndTask(t *testing.T) {
+	fake := llm.NewFakeProvider("```json\n{\"confidence\": 0.9, \"explanation\": \" Ada tas belanja kain. \"}\n```")
+	verifier := NewProofVerifier(fake)
+
+	verdict, err := verifier.Verify(context.Background(), "http://example.com/bag.png", challenges.ChallengeTask{
+		Name:            "Reusable bag",
+		TaskDescription: "Use a reusable bag when shopping",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, challenges.ProofVerdict{Confidence: 0.9, Explanation: "Ada tas belanja kain."}, verdict)
+	request := fake.Requests()[0]
+	if assert.Len(t, request, 2) {
+		assert.Equal(t, []string{"http://example.com/bag.png"}, request[1].ImageURLs)
+		assert.Contains(t, request[1].Content, "Use a reusable bag when shopping")
+	}
+}
+
+func TestParseVerdict(t *testing.T) {
+	verdict, err := parseVerdict(`Here you go: {"confidence": 1.7, "explanation": "Jelas."}`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, verdict.Confidence)
+
+	verdict, err = parseVerdict(`{"confidence": -0.2}`)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, verdict.Confidence)
+
+	_, err = parseVerdict(`{"explanation": "Tidak yakin."}`)
+	assert.Error(t, err)
+
+	_, err = parseVerdict("I cannot see the image.")
+	assert.Error(t, err)
+}
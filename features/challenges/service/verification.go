@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"greenenvironment/constant"
+	"greenenvironment/features/challenges"
+	"greenenvironment/utils/llm"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+// verificationBatchSize is how many proofs one verification run sends to the
+// model.
+const verificationBatchSize = 20
+
+const verificationRole = "You check photos that users of an environmental challenge app submit as proof " +
+	"that they completed a task. Judge only what the photo shows. " +
+	"Reply with a JSON object and nothing else: " +
+	`{"confidence": a number from 0 to 1 for how sure you are the photo shows the task being done, ` +
+	`"explanation": one or two sentences in Indonesian explaining why}. ` +
+	"Give a low confidence to photos that are unrelated, unclear, screenshots or stock images."
+
+type llmProofVerifier struct {
+	provider llm.Provider
+}
+
+// NewProofVerifier judges proofs with a vision capable chat model.
+func NewProofVerifier(provider llm.Provider) challenges.ProofVerifier {
+	return &llmProofVerifier{provider: provider}
+}
+
+func (v *llmProofVerifier) Verify(ctx context.Context, imageURL string, task challenges.ChallengeTask) (challenges.ProofVerdict, error) {
+	answer, err := v.provider.Complete(ctx, []llm.Message{
+		{Role: llm.RoleSystem, Content: verificationRole},
+		{
+			Role:      llm.RoleUser,
+			Content:   fmt.Sprintf("Task: %s\nDescription: %s", task.Name, task.TaskDescription),
+			ImageURLs: []string{imageURL},
+		},
+	})
+	if err != nil {
+		return challenges.ProofVerdict{}, err
+	}
+	return parseVerdict(answer)
+}
+
+// parseVerdict reads the JSON object in the model's answer, which may be
+// wrapped in a code fence or some text.
+func parseVerdict(answer string) (challenges.ProofVerdict, error) {
+	start := strings.Index(answer, "{")
+	end := strings.LastIndex(answer, "}")
+	if start < 0 || end < start {
+		return challenges.ProofVerdict{}, fmt.Errorf("verification answer has no JSON object: %q", answer)
+	}
+
+	var verdict struct {
+		Confidence  *float64 `json:"confidence"`
+		Explanation string   `json:"explanation"`
+	}
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &verdict); err != nil {
+		return challenges.ProofVerdict{}, fmt.Errorf("verification answer is not valid JSON: %w", err)
+	}
+	if verdict.Confidence == nil {
+		return challenges.ProofVerdict{}, fmt.Errorf("verification answer has no confidence: %q", answer)
+	}
+
+	return challenges.ProofVerdict{
+		Confidence:  math.Min(math.Max(*verdict.Confidence, 0), 1),
+		Explanation: strings.TrimSpace(verdict.Explanation),
+	}, nil
+}
+
+// VerifyPendingProofs sends proofs waiting for review to the vision model.
+// Those it is confident about are approved; the rest stay in the review
+// queue with its explanation for the admin.
+func (cs *ChallengeService) VerifyPendingProofs() error {
+	if cs.verifier == nil {
+		return nil
+	}
+
+	cs.verifying.Lock()
+	defer cs.verifying.Unlock()
+
+	pending, err := cs.challengeRepo.GetUnverifiedConfirmations(verificationBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, confirmation := range pending {
+		if err := cs.verifyProof(confirmation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cs *ChallengeService) verifyProof(confirmation challenges.ChallengeConfirmation) error {
+	verification := confirmation.Verification
+	verification.Attempts++
+
+	verdict, err := cs.verifier.Verify(context.Background(), confirmation.ChallengeImg, confirmation.ChallengeTask)
+	if err != nil {
+		log.Printf("failed to verify challenge confirmation %s (attempt %d): %v", confirmation.ID, verification.Attempts, err)
+		if verification.Attempts >= challenges.MaxVerificationAttempts {
+			verification.Status = challenges.VerificationError
+		}
+		return cs.challengeRepo.UpdateConfirmationVerification(confirmation.ID, verification)
+	}
+
+	verifiedAt := time.Now()
+	verification.Confidence = verdict.Confidence
+	verification.Explanation = verdict.Explanation
+	verification.VerifiedAt = &verifiedAt
+	verification.Status = challenges.VerificationFlagged
+	if verdict.Confidence >= cs.proofConfig.AutoApproveConfidence {
+		verification.Status = challenges.VerificationApproved
+	}
+
+	if err := cs.challengeRepo.UpdateConfirmationVerification(confirmation.ID, verification); err != nil {
+		return err
+	}
+	if verification.Status != challenges.VerificationApproved {
+		return nil
+	}
+
+	err = cs.ReviewChallengeConfirmation(confirmation.ID, challenges.VerifierReviewer, "approve", "")
+	if err == constant.ErrChallengeConfirmationNotPending {
+		// An admin reviewed it while the model was looking.
+		return nil
+	}
+	return err
+}
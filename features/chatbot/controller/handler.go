@@ -1,9 +1,13 @@
 package controller
 
 import (
+	"encoding/json"
+	"fmt"
+	"greenenvironment/constant"
 	"greenenvironment/features/chatbot"
 	"greenenvironment/helper"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 )
@@ -20,9 +24,29 @@ func NewChatbotController(s chatbot.ChatbotServiceInterface, j helper.JWTInterfa
 	}
 }
 
+func (cc *ChatbotController) userID(c echo.Context) (string, bool) {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	token, err := cc.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return "", false
+	}
+
+	userData := cc.jwtService.ExtractUserToken(token)
+	userID, ok := userData[constant.JWT_ID].(string)
+	return userID, ok && userID != ""
+}
+
+func pageParam(c echo.Context) int {
+	page, err := strconv.Atoi(c.QueryParam("pages"))
+	if err != nil || page < 1 {
+		return 1
+	}
+	return page
+}
+
 // Create Chatbot
 // @Summary      Create a chatbot conversation
-// @Description  Add a user message and get a response from the chatbot
+// @Description  Add a user message and get a response from the chatbot. Leave id empty to start a new conversation; it is titled after the first message. Products and challenges the assistant looked up are returned as cards, and knowledge base articles the answer cites as [n] as sources.
 // @Tags         Chatbot
 // @Accept       json
 // @Produce      json
@@ -31,12 +55,13 @@ func NewChatbotController(s chatbot.ChatbotServiceInterface, j helper.JWTInterfa
 // @Success      201  {object}  helper.Response{data=ChatbotResponse} "Success Create Chatbot"
 // @Failure      400  {object}  helper.Response{data=string} "Invalid request payload"
 // @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Conversation not found"
+// @Failure      429  {object}  helper.Response{data=string} "Daily chatbot limit reached"
 // @Failure      500  {object}  helper.Response{data=string} "Internal server error"
 // @Router       /chatbots [post]
 func (cc *ChatbotController) Create(c echo.Context) error {
-	tokenString := c.Request().Header.Get("Authorization")
-	_, err := cc.jwtService.ValidateToken(tokenString)
-	if err != nil {
+	userID, ok := cc.userID(c)
+	if !ok {
 		return helper.UnauthorizedError(c)
 	}
 
@@ -52,6 +77,7 @@ func (cc *ChatbotController) Create(c echo.Context) error {
 
 	chatBot := chatbot.Chatbot{
 		ChatID:  chatBotRequest.ID,
+		UserID:  userID,
 		Message: chatBotRequest.Message,
 	}
 
@@ -60,50 +86,259 @@ func (cc *ChatbotController) Create(c echo.Context) error {
 		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
 	}
 
-	var chatBotResponse ChatbotResponse
-	chatBotResponse.ID = res.ID
-	chatBotResponse.ChatID = res.ChatID
-	chatBotResponse.Role = res.Role
-	chatBotResponse.Message = res.Message
-	chatBotResponse.CreatedAt = res.CreatedAt.Format("02/01/2006")
+	return c.JSON(http.StatusCreated, helper.FormatResponse(true, "Success Create Chatbot", toChatbotResponse(res)))
+}
+
+// sseWriter relays a streamed answer to the client as Server-Sent Events.
+type sseWriter struct {
+	response *echo.Response
+	started  bool
+}
+
+func (w *sseWriter) Start(chatID string) error {
+	w.response.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.response.Header().Set("Cache-Control", "no-cache")
+	w.response.Header().Set("Connection", "keep-alive")
+	w.response.Header().Set("X-Accel-Buffering", "no")
+	w.response.WriteHeader(http.StatusOK)
+	w.started = true
+	return w.event("start", map[string]string{"chat_id": chatID})
+}
 
-	return c.JSON(http.StatusCreated, helper.FormatResponse(true, "Success Create Chatbot", chatBotResponse))
+func (w *sseWriter) Token(token string) error {
+	return w.event("token", map[string]string{"content": token})
+}
+
+func (w *sseWriter) event(name string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w.response, "event: %s\ndata: %s\n\n", name, payload); err != nil {
+		return err
+	}
+	w.response.Flush()
+	return nil
+}
+
+// Stream Chatbot
+// @Summary      Stream a chatbot answer
+// @Description  Same as creating a chatbot message, but the answer is streamed as Server-Sent Events: start (chat_id), one token event per piece of text (content), then done with the saved message, or error. If the client disconnects, generation stops and the partial answer is saved.
+// @Tags         Chatbot
+// @Accept       json
+// @Produce      text/event-stream
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        body           body      ChatbotRequest  true   "Chatbot message payload"
+// @Success      200  {string}  string "Event stream"
+// @Failure      400  {object}  helper.Response{data=string} "Invalid request payload"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Conversation not found"
+// @Failure      429  {object}  helper.Response{data=string} "Daily chatbot limit reached"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /chatbots/stream [post]
+func (cc *ChatbotController) CreateStream(c echo.Context) error {
+	userID, ok := cc.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	var chatBotRequest ChatbotRequest
+
+	if err := c.Bind(&chatBotRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "error bad request", nil))
+	}
+
+	if err := c.Validate(chatBotRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	chatBot := chatbot.Chatbot{
+		ChatID:  chatBotRequest.ID,
+		UserID:  userID,
+		Message: chatBotRequest.Message,
+	}
+
+	writer := &sseWriter{response: c.Response()}
+	res, err := cc.chatbotService.CreateStream(c.Request().Context(), chatBot, writer)
+	if err != nil && !writer.started {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+	if c.Request().Context().Err() != nil {
+		// The client is gone; the partial answer is already saved.
+		return nil
+	}
+	if err != nil {
+		return writer.event("error", map[string]string{"message": err.Error()})
+	}
+
+	return writer.event("done", toChatbotResponse(res))
 }
 
 // Get Chatbot by ID
 // @Summary      Retrieve chatbot conversation by ChatID
-// @Description  Fetch all messages in a chatbot session using the ChatID
+// @Description  Fetch the messages of one of the authenticated user's conversations. Page 1 holds the newest messages; every page is ordered oldest first.
 // @Tags         Chatbot
 // @Accept       json
 // @Produce      json
 // @Param        Authorization  header    string  true   "Bearer Token"
 // @Param        chatID         path      string  true   "Chat ID"
-// @Success      200  {object}  helper.Response{data=[]ChatbotResponse} "Success Get Chatbot"
+// @Param        pages          query     int     false  "Page number"
+// @Success      200  {object}  helper.MetadataResponse{data=[]ChatbotResponse} "Success Get Chatbot"
 // @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
-// @Failure      404  {object}  helper.Response{data=string} "Chatbot not found"
+// @Failure      404  {object}  helper.Response{data=string} "Conversation not found"
 // @Failure      500  {object}  helper.Response{data=string} "Internal server error"
 // @Router       /chatbots/{chatID} [get]
 func (cc *ChatbotController) GetByID(c echo.Context) error {
-	chatID := c.Param("chatID")
-	tokenString := c.Request().Header.Get("Authorization")
-	_, err := cc.jwtService.ValidateToken(tokenString)
-	if err != nil {
+	userID, ok := cc.userID(c)
+	if !ok {
 		return helper.UnauthorizedError(c)
 	}
 
-	res, err := cc.chatbotService.GetByID(chatID)
+	page := pageParam(c)
+	res, totalPages, err := cc.chatbotService.GetByID(userID, c.Param("chatID"), page)
 	if err != nil {
 		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
 	}
-	var chatBotResponse []ChatbotResponse
+	chatBotResponse := []ChatbotResponse{}
 	for _, v := range res {
-		chatBotResponse = append(chatBotResponse, ChatbotResponse{
-			ID:        v.ID,
-			ChatID:    v.ChatID,
-			Role:      v.Role,
-			Message:   v.Message,
-			CreatedAt: v.CreatedAt.Format("02/01/2006"),
+		chatBotResponse = append(chatBotResponse, toChatbotResponse(v))
+	}
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "Success Get Chatbot", metadata, chatBotResponse))
+}
+
+// Get Conversations
+// @Summary      List chatbot conversations
+// @Description  List the authenticated user's conversations, most recently active first.
+// @Tags         Chatbot
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        pages          query     int     false  "Page number"
+// @Success      200  {object}  helper.MetadataResponse{data=[]ConversationResponse} "Success Get Conversations"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /chatbots [get]
+func (cc *ChatbotController) GetConversations(c echo.Context) error {
+	userID, ok := cc.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	page := pageParam(c)
+	conversations, totalPages, err := cc.chatbotService.GetConversations(userID, page)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []ConversationResponse{}
+	for _, conversation := range conversations {
+		response = append(response, ConversationResponse{
+			ID:        conversation.ID,
+			Title:     conversation.Title,
+			CreatedAt: conversation.CreatedAt.Format("02/01/2006"),
+			UpdatedAt: conversation.UpdatedAt.Format("02/01/2006"),
 		})
 	}
-	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Success Get Chatbot", chatBotResponse))
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "Success Get Conversations", metadata, response))
+}
+
+// Rename Conversation
+// @Summary      Rename a chatbot conversation
+// @Description  Replace the title of one of the authenticated user's conversations.
+// @Tags         Chatbot
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        chatID         path      string  true   "Chat ID"
+// @Param        body           body      ConversationRenameRequest  true  "New title"
+// @Success      200  {object}  helper.Response{data=string} "Success Rename Conversation"
+// @Failure      400  {object}  helper.Response{data=string} "Invalid title"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Conversation not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /chatbots/{chatID} [put]
+func (cc *ChatbotController) RenameConversation(c echo.Context) error {
+	userID, ok := cc.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	var request ConversationRenameRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "error bad request", nil))
+	}
+
+	if err := cc.chatbotService.RenameConversation(userID, c.Param("chatID"), request.Title); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Success Rename Conversation", nil))
+}
+
+// Delete Conversation
+// @Summary      Delete a chatbot conversation
+// @Description  Delete one of the authenticated user's conversations together with its messages.
+// @Tags         Chatbot
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        chatID         path      string  true   "Chat ID"
+// @Success      200  {object}  helper.Response{data=string} "Success Delete Conversation"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Conversation not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /chatbots/{chatID} [delete]
+func (cc *ChatbotController) DeleteConversation(c echo.Context) error {
+	userID, ok := cc.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := cc.chatbotService.DeleteConversation(userID, c.Param("chatID")); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Success Delete Conversation", nil))
+}
+
+// Get Usage
+// @Summary      Get today's chatbot usage
+// @Description  Show how many chatbot messages and tokens the authenticated user has used today, and the daily limits. A limit of 0 means unlimited.
+// @Tags         Chatbot
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Success      200  {object}  helper.Response{data=UsageResponse} "Success Get Chatbot Usage"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /chatbots/usage [get]
+func (cc *ChatbotController) GetUsage(c echo.Context) error {
+	userID, ok := cc.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	usage, err := cc.chatbotService.GetUsage(userID)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := UsageResponse{
+		Day:          usage.Day,
+		Messages:     usage.Messages,
+		Tokens:       usage.Tokens,
+		MessageLimit: usage.MessageLimit,
+		TokenLimit:   usage.TokenLimit,
+	}
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Success Get Chatbot Usage", response))
 }
@@ -4,3 +4,7 @@ type ChatbotRequest struct {
 	ID      string `json:"id"`
 	Message string `json:"message" validate:"required"`
 }
+
+type ConversationRenameRequest struct {
+	Title string `json:"title" validate:"required"`
+}
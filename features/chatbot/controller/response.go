@@ -1,9 +1,72 @@
 package controller
 
+import "greenenvironment/features/chatbot"
+
 type ChatbotResponse struct {
+	ID        string           `json:"id"`
+	ChatID    string           `json:"chat_id"`
+	Role      string           `json:"role"`
+	Message   string           `json:"message"`
+	Cards     []CardResponse   `json:"cards"`
+	Sources   []SourceResponse `json:"sources"`
+	CreatedAt string           `json:"created_at"`
+}
+
+// CardResponse is a product or challenge shown with an answer. Type tells
+// which; fields that do not apply are left out.
+type CardResponse struct {
+	Type        string  `json:"type"`
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	ImageURL    string  `json:"image_url,omitempty"`
+	Price       float64 `json:"price,omitempty"`
+	Coin        int     `json:"coin,omitempty"`
+	Exp         int     `json:"exp,omitempty"`
+	Difficulty  string  `json:"difficulty,omitempty"`
+	Status      string  `json:"status,omitempty"`
+}
+
+// SourceResponse is a knowledge base document the message cites as
+// [number].
+type SourceResponse struct {
+	Number     int    `json:"number"`
+	DocumentID string `json:"document_id"`
+	Title      string `json:"title"`
+	URL        string `json:"url,omitempty"`
+}
+
+func toChatbotResponse(chat chatbot.Chatbot) ChatbotResponse {
+	cards := []CardResponse{}
+	for _, card := range chat.Cards {
+		cards = append(cards, CardResponse(card))
+	}
+	sources := []SourceResponse{}
+	for _, source := range chat.Sources {
+		sources = append(sources, SourceResponse(source))
+	}
+	return ChatbotResponse{
+		ID:        chat.ID,
+		ChatID:    chat.ChatID,
+		Role:      chat.Role,
+		Message:   chat.Message,
+		Cards:     cards,
+		Sources:   sources,
+		CreatedAt: chat.CreatedAt.Format("02/01/2006"),
+	}
+}
+
+type ConversationResponse struct {
 	ID        string `json:"id"`
-	ChatID    string `json:"chat_id"`
-	Role      string `json:"role"`
-	Message   string `json:"message"`
+	Title     string `json:"title"`
 	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type UsageResponse struct {
+	Day          string `json:"day"`
+	Messages     int    `json:"messages"`
+	Tokens       int    `json:"tokens"`
+	MessageLimit int    `json:"message_limit"`
+	TokenLimit   int    `json:"token_limit"`
 }
@@ -1,35 +1,117 @@
 package chatbot
 
 import (
+	"context"
 	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+// MaxTitleLength is the longest title a conversation can have.
+const MaxTitleLength = 100
+
+// Card types.
+const (
+	CardProduct   = "product"
+	CardChallenge = "challenge"
+)
+
 type Chatbot struct {
 	ID        string
 	ChatID    string
+	UserID    string
 	Role      string
 	Message   string
+	Cards     []Card
+	Sources   []Source
 	CreatedAt time.Time
 }
 
-type Message struct {
-	Role    string
-	Message string
+// Card is a product or challenge the assistant looked up while answering,
+// shown with the answer so the user can open it. Status is the user's
+// progress on a challenge they have joined.
+type Card struct {
+	Type        string
+	ID          string
+	Title       string
+	Description string
+	ImageURL    string
+	Price       float64
+	Coin        int
+	Exp         int
+	Difficulty  string
+	Status      string
+}
+
+// Source is a knowledge base document an answer cites as [Number]. URL is
+// where the original can be read, when the document has one.
+type Source struct {
+	Number     int
+	DocumentID string
+	Title      string
+	URL        string
+}
+
+// Conversation groups the messages of one chat and belongs to the user who
+// started it. Summary condenses the messages up to SummarizedUntil, which
+// are no longer sent to the model.
+type Conversation struct {
+	ID              string
+	UserID          string
+	Title           string
+	Summary         string
+	SummarizedUntil time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// Usage is what a user sent to the chatbot on one day. A limit of zero means
+// unlimited.
+type Usage struct {
+	Day          string
+	Messages     int
+	Tokens       int
+	MessageLimit int
+	TokenLimit   int
+}
+
+// StreamWriter receives an answer while it is being generated. Start is
+// called once with the conversation ID, before the first token.
+type StreamWriter interface {
+	Start(chatID string) error
+	Token(token string) error
 }
 
 type ChatbotControllerInterface interface {
 	Create(c echo.Context) error
+	CreateStream(c echo.Context) error
 	GetByID(c echo.Context) error
+	GetConversations(c echo.Context) error
+	RenameConversation(c echo.Context) error
+	DeleteConversation(c echo.Context) error
+	GetUsage(c echo.Context) error
 }
 
 type ChatbotServiceInterface interface {
 	Create(chat Chatbot) (Chatbot, error)
-	GetByID(id string) ([]Chatbot, error)
+	CreateStream(ctx context.Context, chat Chatbot, writer StreamWriter) (Chatbot, error)
+	GetByID(userID, chatID string, page int) ([]Chatbot, int, error)
+	GetConversations(userID string, page int) ([]Conversation, int, error)
+	RenameConversation(userID, chatID, title string) error
+	DeleteConversation(userID, chatID string) error
+	GetUsage(userID string) (Usage, error)
 }
 
 type ChatbotRepositoryInterface interface {
-	Create(chat Chatbot) (Chatbot, error)
-	GetByID(id string) ([]Chatbot, error)
+	SaveTurn(conversation *Conversation, question, answer Chatbot) (Chatbot, error)
+	GetHistory(chatID string, after time.Time) ([]Chatbot, error)
+	GetMessages(chatID string, page int) ([]Chatbot, int, error)
+	GetConversation(chatID string) (Conversation, error)
+	GetConversations(userID string, page int) ([]Conversation, int, error)
+	UpdateConversationTitle(chatID, title string) error
+	DeleteConversation(chatID string) error
+	UpdateConversationSummary(chatID, summary string, until time.Time) error
+	GetUsage(userID, day string) (Usage, error)
+	ReserveUsage(userID, day string, messageLimit, tokenLimit int) (bool, error)
+	AddUsage(userID, day string, messages, tokens int) error
 }
@@ -1,11 +1,68 @@
 package repository
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Chatbot struct {
 	*gorm.Model
-	ID      string `gorm:"column:id;primary_key"`
-	ChatID  string `gorm:"column:chat_id;not null"`
-	Role    string `gorm:"column:role;not null"`
-	Message string `gorm:"column:message;not null"`
+	ID      string   `gorm:"column:id;primary_key"`
+	ChatID  string   `gorm:"column:chat_id;not null"`
+	UserID  string   `gorm:"column:user_id;type:varchar(50);index"`
+	Role    string   `gorm:"column:role;not null"`
+	Message string   `gorm:"column:message;not null"`
+	Cards   []Card   `gorm:"column:cards;type:text;serializer:json"`
+	Sources []Source `gorm:"column:sources;type:text;serializer:json"`
+}
+
+// Card is stored as JSON with the message it was shown with.
+type Card struct {
+	Type        string  `json:"type"`
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	ImageURL    string  `json:"image_url,omitempty"`
+	Price       float64 `json:"price,omitempty"`
+	Coin        int     `json:"coin,omitempty"`
+	Exp         int     `json:"exp,omitempty"`
+	Difficulty  string  `json:"difficulty,omitempty"`
+	Status      string  `json:"status,omitempty"`
+}
+
+// Source is stored as JSON with the answer that cites it.
+type Source struct {
+	Number     int    `json:"number"`
+	DocumentID string `json:"document_id"`
+	Title      string `json:"title"`
+	URL        string `json:"url,omitempty"`
+}
+
+type Conversation struct {
+	*gorm.Model
+	ID     string `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	UserID string `gorm:"type:varchar(50);not null;column:user_id;index:idx_chatbot_conversation_user"`
+	Title  string `gorm:"type:varchar(100);not null;column:title"`
+	// Summary condenses the messages up to SummarizedUntil.
+	Summary         string     `gorm:"type:text;column:summary"`
+	SummarizedUntil *time.Time `gorm:"column:summarized_until"`
+}
+
+func (Conversation) TableName() string {
+	return "chatbot_conversations"
+}
+
+// Usage counts the chatbot messages and tokens of a user per day.
+type Usage struct {
+	*gorm.Model
+	ID       string `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	UserID   string `gorm:"type:varchar(50);not null;column:user_id;uniqueIndex:idx_chatbot_usage_user_day"`
+	Day      string `gorm:"type:varchar(10);not null;column:day;uniqueIndex:idx_chatbot_usage_user_day"`
+	Messages int    `gorm:"not null;default:0;column:messages"`
+	Tokens   int    `gorm:"not null;default:0;column:tokens"`
+}
+
+func (Usage) TableName() string {
+	return "chatbot_usages"
 }
@@ -1,9 +1,21 @@
 package repository
 
 import (
+	"errors"
+	"greenenvironment/constant"
 	"greenenvironment/features/chatbot"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// messagesPerPage and conversationsPerPage size the pages of the history
+// endpoints.
+const (
+	messagesPerPage      = 20
+	conversationsPerPage = 20
 )
 
 type ChatbotRepository struct {
@@ -16,21 +28,264 @@ func NewChatbotRepository(db *gorm.DB) chatbot.ChatbotRepositoryInterface {
 	}
 }
 
-func (cr *ChatbotRepository) Create(chatbots chatbot.Chatbot) (chatbot.Chatbot, error) {
-	result := cr.DB.Create(&chatbots)
-	if result.Error != nil {
-		return chatbot.Chatbot{}, result.Error
+// SaveTurn stores a question and its answer in one transaction, creating the
+// conversation first when it is new, so a turn that gets no answer leaves
+// nothing behind. The conversation is marked active as of the answer.
+func (cr *ChatbotRepository) SaveTurn(conversation *chatbot.Conversation, question, answer chatbot.Chatbot) (chatbot.Chatbot, error) {
+	answerData := toChatbotModel(answer)
+	err := cr.DB.Transaction(func(tx *gorm.DB) error {
+		if conversation != nil {
+			err := tx.Create(&Conversation{
+				ID:     conversation.ID,
+				UserID: conversation.UserID,
+				Title:  conversation.Title,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		questionData := toChatbotModel(question)
+		if err := tx.Create(&questionData).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&answerData).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Conversation{}).Where("id = ?", answer.ChatID).UpdateColumn("updated_at", answer.CreatedAt).Error
+	})
+	if err != nil {
+		return chatbot.Chatbot{}, err
 	}
-	return chatbots, nil
+	return toChatbotEntity(answerData), nil
 }
 
-func (cr *ChatbotRepository) GetByID(id string) ([]chatbot.Chatbot, error) {
-	var chatbots []chatbot.Chatbot
-	result := cr.DB.Where("chat_id = ?", id).
+// GetHistory returns the messages of a chat sent after the given time, oldest
+// first.
+func (cr *ChatbotRepository) GetHistory(chatID string, after time.Time) ([]chatbot.Chatbot, error) {
+	var chatbotData []Chatbot
+	query := cr.DB.Model(&Chatbot{}).Where("chat_id = ?", chatID)
+	if !after.IsZero() {
+		query = query.Where("created_at > ?", after)
+	}
+	result := query.
 		Order("created_at ASC").
-		Find(&chatbots)
+		Find(&chatbotData)
 	if result.Error != nil {
 		return []chatbot.Chatbot{}, result.Error
 	}
-	return chatbots, nil
+	return toChatbotEntities(chatbotData), nil
+}
+
+// GetMessages pages through a chat from the newest messages back. Each page
+// is returned oldest first so it can be rendered as is.
+func (cr *ChatbotRepository) GetMessages(chatID string, page int) ([]chatbot.Chatbot, int, error) {
+	var total int64
+	query := cr.DB.Model(&Chatbot{}).Where("chat_id = ?", chatID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	totalPages := int((total + messagesPerPage - 1) / messagesPerPage)
+
+	var chatbotData []Chatbot
+	err := query.Order("created_at DESC").
+		Offset((page - 1) * messagesPerPage).
+		Limit(messagesPerPage).
+		Find(&chatbotData).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	chatbots := toChatbotEntities(chatbotData)
+
+	for i, j := 0, len(chatbots)-1; i < j; i, j = i+1, j-1 {
+		chatbots[i], chatbots[j] = chatbots[j], chatbots[i]
+	}
+	return chatbots, totalPages, nil
+}
+
+func (cr *ChatbotRepository) GetConversation(chatID string) (chatbot.Conversation, error) {
+	var conversation Conversation
+	err := cr.DB.Where("id = ?", chatID).First(&conversation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return chatbot.Conversation{}, constant.ErrConversationNotFound
+	}
+	if err != nil {
+		return chatbot.Conversation{}, err
+	}
+	return toConversationEntity(conversation), nil
+}
+
+// GetConversations lists the conversations of a user, most recently active
+// first.
+func (cr *ChatbotRepository) GetConversations(userID string, page int) ([]chatbot.Conversation, int, error) {
+	var total int64
+	query := cr.DB.Model(&Conversation{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	totalPages := int((total + conversationsPerPage - 1) / conversationsPerPage)
+
+	var conversationData []Conversation
+	err := query.Order("updated_at DESC").
+		Offset((page - 1) * conversationsPerPage).
+		Limit(conversationsPerPage).
+		Find(&conversationData).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conversations := make([]chatbot.Conversation, 0, len(conversationData))
+	for _, conversation := range conversationData {
+		conversations = append(conversations, toConversationEntity(conversation))
+	}
+	return conversations, totalPages, nil
+}
+
+func (cr *ChatbotRepository) UpdateConversationTitle(chatID, title string) error {
+	return cr.DB.Model(&Conversation{}).Where("id = ?", chatID).Update("title", title).Error
+}
+
+func (cr *ChatbotRepository) DeleteConversation(chatID string) error {
+	return cr.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("chat_id = ?", chatID).Delete(&Chatbot{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", chatID).Delete(&Conversation{}).Error
+	})
+}
+
+func (cr *ChatbotRepository) UpdateConversationSummary(chatID, summary string, until time.Time) error {
+	return cr.DB.Model(&Conversation{}).Where("id = ?", chatID).UpdateColumns(map[string]interface{}{
+		"summary":          summary,
+		"summarized_until": until,
+	}).Error
+}
+
+func (cr *ChatbotRepository) GetUsage(userID, day string) (chatbot.Usage, error) {
+	var usage Usage
+	err := cr.DB.Where("user_id = ? AND day = ?", userID, day).First(&usage).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return chatbot.Usage{Day: day}, nil
+	}
+	if err != nil {
+		return chatbot.Usage{}, err
+	}
+	return chatbot.Usage{
+		Day:      usage.Day,
+		Messages: usage.Messages,
+		Tokens:   usage.Tokens,
+	}, nil
+}
+
+// AddUsage adds to the user's counters for the day in one statement, so
+// concurrent requests do not lose updates.
+func (cr *ChatbotRepository) AddUsage(userID, day string, messages, tokens int) error {
+	return cr.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"messages":   gorm.Expr("messages + ?", messages),
+			"tokens":     gorm.Expr("tokens + ?", tokens),
+			"updated_at": time.Now(),
+		}),
+	}).Create(&Usage{
+		ID:       uuid.New().String(),
+		UserID:   userID,
+		Day:      day,
+		Messages: messages,
+		Tokens:   tokens,
+	}).Error
+}
+
+// ReserveUsage counts a new message of the user for the day, unless the
+// messages or tokens of the day already reached a limit. A limit of zero
+// means unlimited. The check and the count are one statement, so concurrent
+// requests cannot both take the last message.
+func (cr *ChatbotRepository) ReserveUsage(userID, day string, messageLimit, tokenLimit int) (bool, error) {
+	err := cr.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&Usage{
+		ID:     uuid.New().String(),
+		UserID: userID,
+		Day:    day,
+	}).Error
+	if err != nil {
+		return false, err
+	}
+
+	query := cr.DB.Model(&Usage{}).Where("user_id = ? AND day = ?", userID, day)
+	if messageLimit > 0 {
+		query = query.Where("messages < ?", messageLimit)
+	}
+	if tokenLimit > 0 {
+		query = query.Where("tokens < ?", tokenLimit)
+	}
+	result := query.UpdateColumns(map[string]interface{}{
+		"messages":   gorm.Expr("messages + 1"),
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func toChatbotModel(chat chatbot.Chatbot) Chatbot {
+	chatbotData := Chatbot{
+		Model:   &gorm.Model{CreatedAt: chat.CreatedAt},
+		ID:      chat.ID,
+		ChatID:  chat.ChatID,
+		UserID:  chat.UserID,
+		Role:    chat.Role,
+		Message: chat.Message,
+	}
+	for _, card := range chat.Cards {
+		chatbotData.Cards = append(chatbotData.Cards, Card(card))
+	}
+	for _, source := range chat.Sources {
+		chatbotData.Sources = append(chatbotData.Sources, Source(source))
+	}
+	return chatbotData
+}
+
+func toChatbotEntity(chatbotData Chatbot) chatbot.Chatbot {
+	chat := chatbot.Chatbot{
+		ID:      chatbotData.ID,
+		ChatID:  chatbotData.ChatID,
+		UserID:  chatbotData.UserID,
+		Role:    chatbotData.Role,
+		Message: chatbotData.Message,
+	}
+	if chatbotData.Model != nil {
+		chat.CreatedAt = chatbotData.CreatedAt
+	}
+	for _, card := range chatbotData.Cards {
+		chat.Cards = append(chat.Cards, chatbot.Card(card))
+	}
+	for _, source := range chatbotData.Sources {
+		chat.Sources = append(chat.Sources, chatbot.Source(source))
+	}
+	return chat
+}
+
+func toChatbotEntities(chatbotData []Chatbot) []chatbot.Chatbot {
+	chatbots := make([]chatbot.Chatbot, 0, len(chatbotData))
+	for _, chat := range chatbotData {
+		chatbots = append(chatbots, toChatbotEntity(chat))
+	}
+	return chatbots
+}
+
+func toConversationEntity(conversation Conversation) chatbot.Conversation {
+	var summarizedUntil time.Time
+	if conversation.SummarizedUntil != nil {
+		summarizedUntil = *conversation.SummarizedUntil
+	}
+	return chatbot.Conversation{
+		ID:              conversation.ID,
+		UserID:          conversation.UserID,
+		Title:           conversation.Title,
+		Summary:         conversation.Summary,
+		SummarizedUntil: summarizedUntil,
+		CreatedAt:       conversation.CreatedAt,
+		UpdatedAt:       conversation.UpdatedAt,
+	}
 }
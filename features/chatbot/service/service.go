@@ -1,100 +1,513 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"greenenvironment/configs"
+	"greenenvironment/constant"
+	"greenenvironment/features/challenges"
 	"greenenvironment/features/chatbot"
-	openaiService "greenenvironment/utils/openai"
+	"greenenvironment/features/impacts"
+	"greenenvironment/features/knowledge"
+	"greenenvironment/features/products"
+	"greenenvironment/utils/llm"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
-	openai "github.com/sashabaranov/go-openai"
 )
 
+// autoTitleLength is how much of the first message becomes the title of a
+// new conversation.
+const autoTitleLength = 60
+
+const (
+	summaryRole = "Ringkas percakapan berikut antara pengguna dan asisten lingkungan. " +
+		"Pertahankan fakta, preferensi, dan pertanyaan terbuka yang dibutuhkan asisten untuk melanjutkan percakapan. " +
+		"Jawab hanya dengan ringkasannya, paling banyak 200 kata."
+	summaryPrefix = "Ringkasan percakapan sebelumnya: "
+	knowledgeRole = "Gunakan kutipan basis pengetahuan berikut bila relevan dengan pertanyaan. " +
+		"Jika Anda memakainya, sebutkan sumbernya dengan nomor kutipan, misalnya [1]. " +
+		"Jangan menyebut nomor yang tidak ada di daftar."
+)
+
+// citationPattern finds the [n] markers an answer cites passages with.
+var citationPattern = regexp.MustCompile(`\[(\d+)\]`)
+
 type ChatbotService struct {
-	chatbotRepo chatbot.ChatbotRepositoryInterface
-	openAI      openaiService.OpenAIInterface
-	systemRole  string
+	chatbotRepo   chatbot.ChatbotRepositoryInterface
+	productRepo   products.ProductRepositoryInterface
+	challengeRepo challenges.ChallengeRepoInterface
+	impactService impacts.ImpactServiceInterface
+	knowledge     knowledge.KnowledgeServiceInterface
+	llm           llm.Provider
+	config        configs.ChatbotConfig
+	systemRole    string
+	// toolTokens is what the tool definitions add to a prompt.
+	toolTokens int
+	now        func() time.Time
 }
 
-func NewChatbotService(d chatbot.ChatbotRepositoryInterface, openAI openaiService.OpenAIInterface) chatbot.ChatbotServiceInterface {
+func NewChatbotService(d chatbot.ChatbotRepositoryInterface, productRepo products.ProductRepositoryInterface, challengeRepo challenges.ChallengeRepoInterface, impactService impacts.ImpactServiceInterface, knowledgeService knowledge.KnowledgeServiceInterface, provider llm.Provider, config configs.ChatbotConfig) chatbot.ChatbotServiceInterface {
+	toolDefinitions, _ := json.Marshal(chatTools)
 	return &ChatbotService{
-		chatbotRepo: d,
-		openAI:      openAI,
+		chatbotRepo:   d,
+		productRepo:   productRepo,
+		challengeRepo: challengeRepo,
+		impactService: impactService,
+		knowledge:     knowledgeService,
+		llm:           provider,
+		config:        config,
 		systemRole: "Anda adalah seorang ahli dalam penghijauan lingkungan. " +
 			"Anda akan diberikan pertanyaan tentang lingkungan dan harus menjawabnya " +
-			"dengan relevansi tinggi terhadap lingkungan, produk eco-friendly, atau tantangan lingkungan hijau.",
+			"dengan relevansi tinggi terhadap lingkungan, produk eco-friendly, atau tantangan lingkungan hijau. " +
+			"Gunakan alat yang tersedia untuk mencari produk, tantangan, serta tantangan dan dampak pengguna; " +
+			"jangan mengarang produk atau tantangan yang tidak ditemukan.",
+		toolTokens: llm.EstimateTokens(string(toolDefinitions)),
+		now:        time.Now,
 	}
 }
 
+// turn is a user message on its way to the model: the conversation it
+// belongs to, the prompt built for it, the knowledge base passages in that
+// prompt and the tokens spent so far, which include any summary made to fit
+// the prompt. The message and a new conversation are only saved with the
+// answer. reserved is set when the message was already counted against the
+// quota of day.
+type turn struct {
+	chatID          string
+	newConversation *chatbot.Conversation
+	question        chatbot.Chatbot
+	prompt          []llm.Message
+	passages        []knowledge.Passage
+	tokens          int
+	day             string
+	reserved        bool
+}
+
 func (cs *ChatbotService) Create(chatbots chatbot.Chatbot) (chatbot.Chatbot, error) {
-	payloadChatbot := []chatbot.Message{
-		{
-			Role:    "system",
-			Message: cs.systemRole,
-		},
+	ctx := context.Background()
+	t, err := cs.prepare(ctx, chatbots)
+	if err != nil {
+		return chatbot.Chatbot{}, err
+	}
+
+	assistantMessage, cards, tokens, err := cs.answer(ctx, t, chatbots.UserID, nil)
+	if err == nil && assistantMessage == "" {
+		err = constant.ErrChatbotNoAnswer
+	}
+	if err != nil {
+		cs.release(t, chatbots.UserID)
+		return chatbot.Chatbot{}, err
+	}
+
+	t.tokens += tokens
+	return cs.saveAnswer(t, chatbots.UserID, assistantMessage, cards)
+}
+
+// CreateStream is Create with the answer relayed to the writer as it is
+// generated. Whatever was generated is saved even when the stream stops
+// early, so the history matches what the user saw; the error that stopped
+// it is returned alongside.
+func (cs *ChatbotService) CreateStream(ctx context.Context, chatbots chatbot.Chatbot, writer chatbot.StreamWriter) (chatbot.Chatbot, error) {
+	t, err := cs.prepare(ctx, chatbots)
+	if err != nil {
+		return chatbot.Chatbot{}, err
 	}
 
-	var ID string
+	if err := writer.Start(t.chatID); err != nil {
+		cs.release(t, chatbots.UserID)
+		return chatbot.Chatbot{}, err
+	}
+
+	assistantMessage, cards, tokens, streamErr := cs.answer(ctx, t, chatbots.UserID, writer.Token)
+	if assistantMessage == "" {
+		if streamErr == nil {
+			streamErr = constant.ErrChatbotNoAnswer
+		}
+		cs.release(t, chatbots.UserID)
+		return chatbot.Chatbot{}, streamErr
+	}
+
+	t.tokens += tokens
+	res, err := cs.saveAnswer(t, chatbots.UserID, assistantMessage, cards)
+	if err != nil {
+		return chatbot.Chatbot{}, err
+	}
+	return res, streamErr
+}
+
+// prepare reserves the message in the user's quota and builds the prompt
+// from the conversation so far and the knowledge base passages that match
+// it, starting a conversation when the message has no ChatID. The
+// reservation is released when the turn cannot be prepared.
+func (cs *ChatbotService) prepare(ctx context.Context, chatbots chatbot.Chatbot) (turn, error) {
+	day := cs.today()
+	reserved, err := cs.reserveQuota(chatbots.UserID, day)
+	if err != nil {
+		return turn{}, err
+	}
+
+	t, err := cs.buildTurn(ctx, chatbots)
+	t.day = day
+	t.reserved = reserved
+	if err != nil {
+		cs.release(t, chatbots.UserID)
+		return turn{}, err
+	}
+	return t, nil
+}
+
+func (cs *ChatbotService) buildTurn(ctx context.Context, chatbots chatbot.Chatbot) (turn, error) {
+	var conversation chatbot.Conversation
+	var newConversation *chatbot.Conversation
+	var history []chatbot.Chatbot
 
 	if chatbots.ChatID == "" {
-		ID = uuid.New().String()
+		conversation = chatbot.Conversation{
+			ID:     uuid.New().String(),
+			UserID: chatbots.UserID,
+			Title:  conversationTitle(chatbots.Message),
+		}
+		newConversation = &conversation
 	} else {
-		ID = chatbots.ChatID
-		previousChats, err := cs.chatbotRepo.GetByID(ID)
+		var err error
+		conversation, err = cs.ownConversation(chatbots.UserID, chatbots.ChatID)
 		if err != nil {
-			return chatbot.Chatbot{}, err
+			return turn{}, err
 		}
-		for _, v := range previousChats {
-			payloadChatbot = append(payloadChatbot, chatbot.Message{
-				Role:    v.Role,
-				Message: v.Message,
-			})
+		history, err = cs.chatbotRepo.GetHistory(conversation.ID, conversation.SummarizedUntil)
+		if err != nil {
+			return turn{}, err
 		}
 	}
 
 	chatbots.ID = uuid.New().String()
-	chatbots.ChatID = ID
-	chatbots.Role = "user"
+	chatbots.ChatID = conversation.ID
+	chatbots.Role = llm.RoleUser
+	chatbots.CreatedAt = cs.now()
 
-	if _, err := cs.chatbotRepo.Create(chatbots); err != nil {
-		return chatbot.Chatbot{}, err
+	question := llm.Message{Role: llm.RoleUser, Content: chatbots.Message}
+	t := turn{
+		chatID:          conversation.ID,
+		newConversation: newConversation,
+		question:        chatbots,
+		passages:        cs.retrieve(ctx, chatbots.Message),
 	}
+	reference := referenceMessage(t.passages)
 
-	payloadChatbot = append(payloadChatbot, chatbot.Message{
-		Role:    "user",
-		Message: chatbots.Message,
-	})
+	budget := cs.config.ContextTokens
+	if budget > 0 && cs.promptTokens(conversation.Summary, reference, history, question) > budget {
+		// Summarize down to half the budget rather than just below it, so
+		// the next few turns fit without another summary.
+		keep := cs.fitHistory(conversation.Summary, reference, history, question, budget/2)
+		if keep > 0 {
+			tokens, err := cs.summarize(&conversation, history[:keep])
+			if err != nil {
+				log.Printf("failed to summarize conversation %s: %v", conversation.ID, err)
+			} else {
+				history = history[keep:]
+				t.tokens += tokens
+			}
+		}
+		// Whatever still does not fit is left out of this prompt. It stays
+		// in the history and is summarized next time.
+		history = history[cs.fitHistory(conversation.Summary, reference, history, question, budget):]
+	}
+
+	t.prompt = cs.prompt(conversation.Summary, reference, history, question)
+	t.tokens += llm.CountTokens(t.prompt)
+	return t, nil
+}
 
-	openAIPayload := make([]openai.ChatCompletionMessage, len(payloadChatbot))
-	for i, msg := range payloadChatbot {
-		openAIPayload[i] = openai.ChatCompletionMessage{
-			Role:    msg.Role,
-			Content: msg.Message,
+// retrieve looks up the knowledge base passages for a question. The chatbot
+// can answer without them, so a failed search is only logged.
+func (cs *ChatbotService) retrieve(ctx context.Context, question string) []knowledge.Passage {
+	if cs.knowledge == nil {
+		return nil
+	}
+
+	passages, err := cs.knowledge.Search(ctx, question)
+	if err != nil {
+		log.Printf("failed to search the knowledge base: %v", err)
+		return nil
+	}
+	return passages
+}
+
+// referenceMessage lists the passages numbered from 1, the numbers the
+// answer cites them by.
+func referenceMessage(passages []knowledge.Passage) string {
+	if len(passages) == 0 {
+		return ""
+	}
+
+	var reference strings.Builder
+	reference.WriteString(knowledgeRole)
+	for i, passage := range passages {
+		title := passage.Title
+		if passage.Heading != "" {
+			title += " - " + passage.Heading
+		}
+		fmt.Fprintf(&reference, "\n\n[%d] %s\n%s", i+1, title, passage.Content)
+	}
+	return reference.String()
+}
+
+// citedSources returns the passages the answer cites, in the order of their
+// numbers. Numbers that match no passage are ignored.
+func citedSources(message string, passages []knowledge.Passage) []chatbot.Source {
+	cited := map[int]bool{}
+	for _, match := range citationPattern.FindAllStringSubmatch(message, -1) {
+		number, err := strconv.Atoi(match[1])
+		if err == nil && number >= 1 && number <= len(passages) {
+			cited[number] = true
 		}
 	}
 
-	assistantMessage, err := cs.openAI.CreateChatCompletion(openAIPayload)
+	var sources []chatbot.Source
+	for i, passage := range passages {
+		if cited[i+1] {
+			sources = append(sources, chatbot.Source{
+				Number:     i + 1,
+				DocumentID: passage.DocumentID,
+				Title:      passage.Title,
+				URL:        passage.Source,
+			})
+		}
+	}
+	return sources
+}
+
+// prompt puts together the system role, the summary of older turns, the
+// knowledge base passages, the recent history and the new question.
+func (cs *ChatbotService) prompt(summary, reference string, history []chatbot.Chatbot, question llm.Message) []llm.Message {
+	prompt := []llm.Message{
+		{
+			Role:    llm.RoleSystem,
+			Content: cs.systemRole,
+		},
+	}
+	if summary != "" {
+		prompt = append(prompt, llm.Message{
+			Role:    llm.RoleSystem,
+			Content: summaryPrefix + summary,
+		})
+	}
+	if reference != "" {
+		prompt = append(prompt, llm.Message{
+			Role:    llm.RoleSystem,
+			Content: reference,
+		})
+	}
+	for _, v := range history {
+		prompt = append(prompt, llm.Message{
+			Role:    v.Role,
+			Content: v.Message,
+		})
+	}
+	return append(prompt, question)
+}
+
+func (cs *ChatbotService) promptTokens(summary, reference string, history []chatbot.Chatbot, question llm.Message) int {
+	return llm.CountTokens(cs.prompt(summary, reference, history, question))
+}
+
+// fitHistory returns how many of the oldest history messages must be left
+// out for the prompt to fit in budget tokens.
+func (cs *ChatbotService) fitHistory(summary, reference string, history []chatbot.Chatbot, question llm.Message, budget int) int {
+	tokens := cs.promptTokens(summary, reference, nil, question)
+	for i := len(history) - 1; i >= 0; i-- {
+		tokens += llm.MessageTokens(llm.Message{Role: history[i].Role, Content: history[i].Message})
+		if tokens > budget {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// summarize folds older messages into the conversation summary and stores
+// it. It returns the tokens the summary request used.
+func (cs *ChatbotService) summarize(conversation *chatbot.Conversation, older []chatbot.Chatbot) (int, error) {
+	var transcript strings.Builder
+	if conversation.Summary != "" {
+		transcript.WriteString(summaryPrefix + conversation.Summary + "\n\n")
+	}
+	for _, v := range older {
+		transcript.WriteString(v.Role + ": " + v.Message + "\n")
+	}
+
+	request := []llm.Message{
+		{Role: llm.RoleSystem, Content: summaryRole},
+		{Role: llm.RoleUser, Content: transcript.String()},
+	}
+	summary, err := cs.llm.Complete(context.Background(), request)
 	if err != nil {
-		return chatbot.Chatbot{}, err
+		return 0, err
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return 0, llm.ErrNoAnswer
 	}
 
+	until := older[len(older)-1].CreatedAt
+	if err := cs.chatbotRepo.UpdateConversationSummary(conversation.ID, summary, until); err != nil {
+		return 0, err
+	}
+
+	conversation.Summary = summary
+	conversation.SummarizedUntil = until
+	return llm.CountTokens(request) + llm.EstimateTokens(summary), nil
+}
+
+func (cs *ChatbotService) saveAnswer(t turn, userID, message string, cards []chatbot.Card) (chatbot.Chatbot, error) {
 	assistantResponse := chatbot.Chatbot{
-		ID:      uuid.New().String(),
-		ChatID:  ID,
-		Role:    "assistant",
-		Message: assistantMessage,
+		ID:        uuid.New().String(),
+		ChatID:    t.chatID,
+		UserID:    userID,
+		Role:      llm.RoleAssistant,
+		Message:   message,
+		Cards:     cards,
+		Sources:   citedSources(message, t.passages),
+		CreatedAt: cs.now(),
+	}
+	// The history is ordered by time, so the answer must come after the
+	// question even when the clock did not move.
+	if !assistantResponse.CreatedAt.After(t.question.CreatedAt) {
+		assistantResponse.CreatedAt = t.question.CreatedAt.Add(time.Millisecond)
 	}
 
-	res, err := cs.chatbotRepo.Create(assistantResponse)
+	res, err := cs.chatbotRepo.SaveTurn(t.newConversation, t.question, assistantResponse)
 	if err != nil {
+		cs.release(t, userID)
 		return chatbot.Chatbot{}, err
 	}
 
+	// The answer is saved already, so a failure to meter it is only logged.
+	messages := 1
+	if t.reserved {
+		messages = 0
+	}
+	tokens := t.tokens + llm.EstimateTokens(message)
+	if err := cs.chatbotRepo.AddUsage(userID, t.day, messages, tokens); err != nil {
+		log.Printf("failed to record chatbot usage of user %s: %v", userID, err)
+	}
+
 	return res, nil
 }
 
-func (cs *ChatbotService) GetByID(id string) ([]chatbot.Chatbot, error) {
-	res, err := cs.chatbotRepo.GetByID(id)
+func (cs *ChatbotService) today() string {
+	return cs.now().Format("2006-01-02")
+}
+
+// reserveQuota counts the message against the quota of the day before the
+// model is asked, so concurrent requests cannot go past the limit. It
+// reports whether a reservation was made, which is only needed when a quota
+// is set.
+func (cs *ChatbotService) reserveQuota(userID, day string) (bool, error) {
+	if cs.config.DailyMessageQuota <= 0 && cs.config.DailyTokenQuota <= 0 {
+		return false, nil
+	}
+
+	reserved, err := cs.chatbotRepo.ReserveUsage(userID, day, cs.config.DailyMessageQuota, cs.config.DailyTokenQuota)
 	if err != nil {
-		return []chatbot.Chatbot{}, err
+		return false, err
 	}
-	return res, nil
+	if reserved {
+		return true, nil
+	}
+
+	usage, err := cs.chatbotRepo.GetUsage(userID, day)
+	if err != nil {
+		return false, err
+	}
+	if cs.config.DailyMessageQuota > 0 && usage.Messages >= cs.config.DailyMessageQuota {
+		return false, constant.ErrChatbotMessageQuota
+	}
+	return false, constant.ErrChatbotTokenQuota
+}
+
+// release gives back the reserved message of a turn that got no answer.
+func (cs *ChatbotService) release(t turn, userID string) {
+	if !t.reserved {
+		return
+	}
+	if err := cs.chatbotRepo.AddUsage(userID, t.day, -1, 0); err != nil {
+		log.Printf("failed to release chatbot usage of user %s: %v", userID, err)
+	}
+}
+
+// ownConversation loads a conversation of the user. Conversations of other
+// users, and any left without an owner, are reported as not found so their
+// IDs cannot be probed.
+func (cs *ChatbotService) ownConversation(userID, chatID string) (chatbot.Conversation, error) {
+	conversation, err := cs.chatbotRepo.GetConversation(chatID)
+	if err != nil {
+		return chatbot.Conversation{}, err
+	}
+	if conversation.UserID == "" || conversation.UserID != userID {
+		return chatbot.Conversation{}, constant.ErrConversationNotFound
+	}
+	return conversation, nil
+}
+
+// conversationTitle names a new conversation after the start of its first
+// message, cut at a word boundary.
+func conversationTitle(message string) string {
+	title := strings.Join(strings.Fields(message), " ")
+	if utf8.RuneCountInString(title) <= autoTitleLength {
+		return title
+	}
+
+	runes := []rune(title)[:autoTitleLength]
+	title = string(runes)
+	if i := strings.LastIndex(title, " "); i > 0 {
+		title = title[:i]
+	}
+	return title + "..."
+}
+
+func (cs *ChatbotService) GetByID(userID, chatID string, page int) ([]chatbot.Chatbot, int, error) {
+	if _, err := cs.ownConversation(userID, chatID); err != nil {
+		return nil, 0, err
+	}
+	return cs.chatbotRepo.GetMessages(chatID, page)
+}
+
+func (cs *ChatbotService) GetConversations(userID string, page int) ([]chatbot.Conversation, int, error) {
+	return cs.chatbotRepo.GetConversations(userID, page)
+}
+
+func (cs *ChatbotService) RenameConversation(userID, chatID, title string) error {
+	title = strings.TrimSpace(title)
+	if title == "" || utf8.RuneCountInString(title) > chatbot.MaxTitleLength {
+		return constant.ErrConversationTitle
+	}
+
+	if _, err := cs.ownConversation(userID, chatID); err != nil {
+		return err
+	}
+	return cs.chatbotRepo.UpdateConversationTitle(chatID, title)
+}
+
+func (cs *ChatbotService) DeleteConversation(userID, chatID string) error {
+	if _, err := cs.ownConversation(userID, chatID); err != nil {
+		return err
+	}
+	return cs.chatbotRepo.DeleteConversation(chatID)
+}
+
+func (cs *ChatbotService) GetUsage(userID string) (chatbot.Usage, error) {
+	usage, err := cs.chatbotRepo.GetUsage(userID, cs.today())
+	if err != nil {
+		return chatbot.Usage{}, err
+	}
+	usage.MessageLimit = cs.config.DailyMessageQuota
+	usage.TokenLimit = cs.config.DailyTokenQuota
+	return usage, nil
 }
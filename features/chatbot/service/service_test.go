@@ -1,12 +1,22 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"greenenvironment/configs"
+	"greenenvironment/constant"
+	"greenenvironment/features/challenges"
 	"greenenvironment/features/chatbot"
+	"greenenvironment/features/impacts"
+	"greenenvironment/features/knowledge"
+	"greenenvironment/features/products"
+	"greenenvironment/utils/llm"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
-	openai "github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -15,34 +25,215 @@ type MockChatbotRepository struct {
 	mock.Mock
 }
 
-func (m *MockChatbotRepository) Create(chatbotDTO chatbot.Chatbot) (chatbot.Chatbot, error) {
-	args := m.Called(chatbotDTO)
+func (m *MockChatbotRepository) SaveTurn(conversation *chatbot.Conversation, question, answer chatbot.Chatbot) (chatbot.Chatbot, error) {
+	args := m.Called(conversation, question, answer)
 	return args.Get(0).(chatbot.Chatbot), args.Error(1)
 }
 
-func (m *MockChatbotRepository) GetByID(id string) ([]chatbot.Chatbot, error) {
-	args := m.Called(id)
+func (m *MockChatbotRepository) GetHistory(chatID string, after time.Time) ([]chatbot.Chatbot, error) {
+	args := m.Called(chatID, after)
 	return args.Get(0).([]chatbot.Chatbot), args.Error(1)
 }
 
-type MockOpenAIService struct {
+func (m *MockChatbotRepository) GetMessages(chatID string, page int) ([]chatbot.Chatbot, int, error) {
+	args := m.Called(chatID, page)
+	return args.Get(0).([]chatbot.Chatbot), args.Int(1), args.Error(2)
+}
+
+func (m *MockChatbotRepository) GetConversation(chatID string) (chatbot.Conversation, error) {
+	args := m.Called(chatID)
+	return args.Get(0).(chatbot.Conversation), args.Error(1)
+}
+
+func (m *MockChatbotRepository) GetConversations(userID string, page int) ([]chatbot.Conversation, int, error) {
+	args := m.Called(userID, page)
+	return args.Get(0).([]chatbot.Conversation), args.Int(1), args.Error(2)
+}
+
+func (m *MockChatbotRepository) UpdateConversationTitle(chatID, title string) error {
+	args := m.Called(chatID, title)
+	return args.Error(0)
+}
+
+func (m *MockChatbotRepository) DeleteConversation(chatID string) error {
+	args := m.Called(chatID)
+	return args.Error(0)
+}
+
+func (m *MockChatbotRepository) UpdateConversationSummary(chatID, summary string, until time.Time) error {
+	args := m.Called(chatID, summary, until)
+	return args.Error(0)
+}
+
+func (m *MockChatbotRepository) GetUsage(userID, day string) (chatbot.Usage, error) {
+	args := m.Called(userID, day)
+	return args.Get(0).(chatbot.Usage), args.Error(1)
+}
+
+func (m *MockChatbotRepository) ReserveUsage(userID, day string, messageLimit, tokenLimit int) (bool, error) {
+	args := m.Called(userID, day, messageLimit, tokenLimit)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockChatbotRepository) AddUsage(userID, day string, messages, tokens int) error {
+	args := m.Called(userID, day, messages, tokens)
+	return args.Error(0)
+}
+
+type MockProductRepository struct {
 	mock.Mock
 }
 
-func (m *MockOpenAIService) CreateChatCompletion(messages []openai.ChatCompletionMessage) (string, error) {
-	args := m.Called(messages)
-	return args.String(0), args.Error(1)
+func (m *MockProductRepository) Create(product products.Product) error {
+	args := m.Called(product)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) GetAllByPage(page int, search string, sort string) ([]products.Product, int, error) {
+	args := m.Called(page, search, sort)
+	return args.Get(0).([]products.Product), args.Int(1), args.Error(2)
+}
+
+func (m *MockProductRepository) GetById(id string) (products.Product, error) {
+	args := m.Called(id)
+	return args.Get(0).(products.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetByCategory(categoryName string, page int, search string, sort string) ([]products.Product, int, error) {
+	args := m.Called(categoryName, page, search, sort)
+	return args.Get(0).([]products.Product), args.Int(1), args.Error(2)
+}
+
+func (m *MockProductRepository) Update(product products.Product) error {
+	args := m.Called(product)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) Delete(productId string) error {
+	args := m.Called(productId)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) GetTotalProduct() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+// MockChallengeRepository mocks the challenge queries the chatbot tools
+// use. Calling any other method panics on the nil embedded interface.
+type MockChallengeRepository struct {
+	mock.Mock
+	challenges.ChallengeRepoInterface
+}
+
+func (m *MockChallengeRepository) GetAvailableChallenges(userID string) ([]challenges.Challenge, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]challenges.Challenge), args.Error(1)
+}
+
+func (m *MockChallengeRepository) GetChallengeByID(challengeID string) (challenges.Challenge, error) {
+	args := m.Called(challengeID)
+	return args.Get(0).(challenges.Challenge), args.Error(1)
+}
+
+func (m *MockChallengeRepository) GetChallengeHistory(userID string) ([]challenges.ChallengeLog, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]challenges.ChallengeLog), args.Error(1)
+}
+
+type MockImpactService struct {
+	mock.Mock
+}
+
+func (m *MockImpactService) GetAll() ([]impacts.ImpactCategory, error) {
+	args := m.Called()
+	return args.Get(0).([]impacts.ImpactCategory), args.Error(1)
+}
+
+func (m *MockImpactService) GetByID(ID string) (impacts.ImpactCategory, error) {
+	args := m.Called(ID)
+	return args.Get(0).(impacts.ImpactCategory), args.Error(1)
+}
+
+func (m *MockImpactService) Create(category impacts.ImpactCategory) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockImpactService) Update(category impacts.ImpactCategory) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockImpactService) Delete(category impacts.ImpactCategory, reassignTo string) (impacts.CategoryUsage, error) {
+	args := m.Called(category, reassignTo)
+	return args.Get(0).(impacts.CategoryUsage), args.Error(1)
+}
+
+func (m *MockImpactService) GetUserImpact(userID string, months int) (impacts.UserImpactSummary, error) {
+	args := m.Called(userID, months)
+	return args.Get(0).(impacts.UserImpactSummary), args.Error(1)
+}
+
+func (m *MockImpactService) GetConversionFactors(categoryID string) ([]impacts.ConversionFactor, error) {
+	args := m.Called(categoryID)
+	return args.Get(0).([]impacts.ConversionFactor), args.Error(1)
+}
+
+func (m *MockImpactService) UpdateConversionFactors(categoryID string, factors map[string]float64) ([]impacts.ConversionFactor, error) {
+	args := m.Called(categoryID, factors)
+	return args.Get(0).([]impacts.ConversionFactor), args.Error(1)
+}
+
+// MockKnowledgeService only implements Search, the one method the chatbot
+// uses.
+type MockKnowledgeService struct {
+	knowledge.KnowledgeServiceInterface
+	mock.Mock
+}
+
+func (m *MockKnowledgeService) Search(ctx context.Context, query string) ([]knowledge.Passage, error) {
+	args := m.Called(query)
+	return args.Get(0).([]knowledge.Passage), args.Error(1)
+}
+
+type recordingWriter struct {
+	chatID string
+	tokens []string
+}
+
+func (w *recordingWriter) Start(chatID string) error {
+	w.chatID = chatID
+	return nil
+}
+
+func (w *recordingWriter) Token(token string) error {
+	w.tokens = append(w.tokens, token)
+	return nil
+}
+
+// cancellingWriter cancels the request after the first token, like a client
+// that closes the connection mid-answer.
+type cancellingWriter struct {
+	recordingWriter
+	cancel context.CancelFunc
+}
+
+func (w *cancellingWriter) Token(token string) error {
+	w.cancel()
+	return w.recordingWriter.Token(token)
 }
 
 func TestCreateChatbot(t *testing.T) {
 	mockRepo := new(MockChatbotRepository)
-	mockOpenAI := new(MockOpenAIService)
-	service := NewChatbotService(mockRepo, mockOpenAI)
+	fake := llm.NewFakeProvider("Hi there!")
+	service := NewChatbotService(mockRepo, nil, nil, nil, nil, fake, configs.ChatbotConfig{})
 
 	t.Run("success", func(t *testing.T) {
 		chatID := uuid.New().String()
 		chatbotIn := chatbot.Chatbot{
 			ChatID:  chatID,
+			UserID:  "user1",
 			Message: "Hello",
 		}
 		expectedResponse := chatbot.Chatbot{
@@ -52,27 +243,32 @@ func TestCreateChatbot(t *testing.T) {
 			Message: "Hi there!",
 		}
 
-		mockRepo.On("GetByID", chatID).Return([]chatbot.Chatbot{}, nil)
-		mockRepo.On("Create", mock.Anything).Return(chatbotIn, nil).Once()
-		mockOpenAI.On("CreateChatCompletion", mock.Anything).Return("Hi there!", nil).Once()
-		mockRepo.On("Create", mock.Anything).Return(expectedResponse, nil).Once()
+		mockRepo.On("GetConversation", chatID).Return(chatbot.Conversation{ID: chatID, UserID: "user1"}, nil).Once()
+		mockRepo.On("GetHistory", chatID, time.Time{}).Return([]chatbot.Chatbot{}, nil)
+		mockRepo.On("SaveTurn", (*chatbot.Conversation)(nil), mock.MatchedBy(func(chat chatbot.Chatbot) bool {
+			return chat.Role == "user" && chat.Message == "Hello" && chat.ChatID == chatID
+		}), mock.MatchedBy(func(chat chatbot.Chatbot) bool {
+			return chat.Role == "assistant" && chat.Message == "Hi there!" && chat.ChatID == chatID
+		})).Return(expectedResponse, nil).Once()
+		mockRepo.On("AddUsage", mock.Anything, mock.Anything, 1, mock.Anything).Return(nil).Once()
 
 		result, err := service.Create(chatbotIn)
 		assert.NoError(t, err)
 		assert.Equal(t, expectedResponse, result)
 		mockRepo.AssertExpectations(t)
-		mockOpenAI.AssertExpectations(t)
 	})
 
 	t.Run("repository error", func(t *testing.T) {
 		chatID := uuid.New().String()
 		chatbotIn := chatbot.Chatbot{
 			ChatID:  chatID,
+			UserID:  "user1",
 			Message: "Hello",
 		}
 
-		mockRepo.On("GetByID", chatID).Return([]chatbot.Chatbot{}, nil)
-		mockRepo.On("Create", mock.Anything).Return(chatbot.Chatbot{}, errors.New("repository error")).Once()
+		mockRepo.On("GetConversation", chatID).Return(chatbot.Conversation{ID: chatID, UserID: "user1"}, nil).Once()
+		mockRepo.On("GetHistory", chatID, time.Time{}).Return([]chatbot.Chatbot{}, nil)
+		mockRepo.On("SaveTurn", mock.Anything, mock.Anything, mock.Anything).Return(chatbot.Chatbot{}, errors.New("repository error")).Once()
 
 		result, err := service.Create(chatbotIn)
 		assert.Error(t, err)
@@ -80,28 +276,98 @@ func TestCreateChatbot(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("openai error", func(t *testing.T) {
+	t.Run("provider error", func(t *testing.T) {
 		chatID := uuid.New().String()
 		chatbotIn := chatbot.Chatbot{
 			ChatID:  chatID,
+			UserID:  "user1",
 			Message: "Hello",
 		}
 
-		mockRepo.On("GetByID", chatID).Return([]chatbot.Chatbot{}, nil)
-		mockRepo.On("Create", mock.Anything).Return(chatbotIn, nil).Once()
-		mockOpenAI.On("CreateChatCompletion", mock.Anything).Return("", errors.New("openai error")).Once()
+		mockRepo.On("GetConversation", chatID).Return(chatbot.Conversation{ID: chatID, UserID: "user1"}, nil).Once()
+		mockRepo.On("GetHistory", chatID, time.Time{}).Return([]chatbot.Chatbot{}, nil)
+		fake.Fail(errors.New("provider error"))
+		defer fake.Fail(nil)
 
 		result, err := service.Create(chatbotIn)
-		assert.Error(t, err)
+		assert.EqualError(t, err, "provider error")
 		assert.Equal(t, chatbot.Chatbot{}, result)
-		mockRepo.AssertExpectations(t)
-		mockOpenAI.AssertExpectations(t)
+		mockRepo.AssertNumberOfCalls(t, "SaveTurn", 2)
 	})
 }
 
+func TestCreateChatbot_NewConversation(t *testing.T) {
+	mockRepo := new(MockChatbotRepository)
+	fake := llm.NewFakeProvider("Start with a bin.")
+	service := NewChatbotService(mockRepo, nil, nil, nil, nil, fake, configs.ChatbotConfig{})
+
+	var conversationID string
+	mockRepo.On("SaveTurn", mock.MatchedBy(func(conversation *chatbot.Conversation) bool {
+		if conversation == nil {
+			return false
+		}
+		conversationID = conversation.ID
+		return conversation.ID != "" && conversation.UserID == "user1" && conversation.Title == "How do I start composting?"
+	}), mock.MatchedBy(func(chat chatbot.Chatbot) bool {
+		return chat.Role == "user" && chat.UserID == "user1" && chat.ChatID == conversationID
+	}), mock.MatchedBy(func(chat chatbot.Chatbot) bool {
+		return chat.Role == "assistant" && chat.UserID == "user1" && chat.ChatID == conversationID
+	})).Return(chatbot.Chatbot{Role: "assistant", Message: "Start with a bin."}, nil).Once()
+	mockRepo.On("AddUsage", mock.Anything, mock.Anything, 1, mock.Anything).Return(nil).Once()
+
+	result, err := service.Create(chatbot.Chatbot{UserID: "user1", Message: "  How do I start\ncomposting?  "})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Start with a bin.", result.Message)
+	if assert.Len(t, fake.Requests(), 1) {
+		messages := fake.Requests()[0]
+		assert.Equal(t, llm.RoleSystem, messages[0].Role)
+		assert.Equal(t, llm.Message{Role: llm.RoleUser, Content: "  How do I start\ncomposting?  "}, messages[len(messages)-1])
+	}
+	mockRepo.AssertNotCalled(t, "GetHistory", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateChatbot_OtherUsersConversation(t *testing.T) {
+	mockRepo := new(MockChatbotRepository)
+	fake := llm.NewFakeProvider()
+	service := NewChatbotService(mockRepo, nil, nil, nil, nil, fake, configs.ChatbotConfig{})
+
+	mockRepo.On("GetConversation", "chat1").Return(chatbot.Conversation{ID: "chat1", UserID: "owner"}, nil)
+
+	_, err := service.Create(chatbot.Chatbot{ChatID: "chat1", UserID: "intruder", Message: "Hello"})
+
+	assert.Equal(t, constant.ErrConversationNotFound, err)
+	mockRepo.AssertNotCalled(t, "GetHistory", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "SaveTurn", mock.Anything, mock.Anything, mock.Anything)
+	assert.Empty(t, fake.Requests())
+}
+
+func TestRenameConversation_OwnerlessChat(t *testing.T) {
+	mockRepo := new(MockChatbotRepository)
+	service := NewChatbotService(mockRepo, nil, nil, nil, nil, nil, configs.ChatbotConfig{})
+
+	mockRepo.On("GetConversation", "legacy1").Return(chatbot.Conversation{ID: "legacy1"}, nil)
+
+	err := service.RenameConversation("user1", "legacy1", "Old chat")
+
+	assert.Equal(t, constant.ErrConversationNotFound, err)
+	mockRepo.AssertNotCalled(t, "UpdateConversationTitle", mock.Anything, mock.Anything)
+}
+
+func TestConversationTitle(t *testing.T) {
+	assert.Equal(t, "Short question", conversationTitle("Short   question"))
+
+	long := strings.Repeat("recycle ", 20)
+	title := conversationTitle(long)
+	assert.True(t, strings.HasSuffix(title, "..."))
+	assert.LessOrEqual(t, len([]rune(strings.TrimSuffix(title, "..."))), autoTitleLength)
+	assert.False(t, strings.HasSuffix(strings.TrimSuffix(title, "..."), " "))
+}
+
 func TestGetByID(t *testing.T) {
 	mockRepo := new(MockChatbotRepository)
-	service := NewChatbotService(mockRepo, nil)
+	service := NewChatbotService(mockRepo, nil, nil, nil, nil, nil, configs.ChatbotConfig{})
 
 	t.Run("success", func(t *testing.T) {
 		chatID := uuid.New().String()
@@ -114,22 +380,695 @@ func TestGetByID(t *testing.T) {
 			},
 		}
 
-		mockRepo.On("GetByID", chatID).Return(expectedChats, nil).Once()
+		mockRepo.On("GetConversation", chatID).Return(chatbot.Conversation{ID: chatID, UserID: "user1"}, nil).Once()
+		mockRepo.On("GetMessages", chatID, 2).Return(expectedChats, 3, nil).Once()
 
-		result, err := service.GetByID(chatID)
+		result, totalPages, err := service.GetByID("user1", chatID, 2)
 		assert.NoError(t, err)
 		assert.Equal(t, expectedChats, result)
+		assert.Equal(t, 3, totalPages)
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("other user", func(t *testing.T) {
+		chatID := uuid.New().String()
+
+		mockRepo.On("GetConversation", chatID).Return(chatbot.Conversation{ID: chatID, UserID: "user1"}, nil).Once()
+
+		result, _, err := service.GetByID("user2", chatID, 1)
+		assert.Equal(t, constant.ErrConversationNotFound, err)
+		assert.Empty(t, result)
+		mockRepo.AssertNotCalled(t, "GetMessages", chatID, 1)
+	})
+
+	t.Run("conversation not found", func(t *testing.T) {
+		chatID := uuid.New().String()
+
+		mockRepo.On("GetConversation", chatID).Return(chatbot.Conversation{}, constant.ErrConversationNotFound).Once()
+
+		_, _, err := service.GetByID("user1", chatID, 1)
+		assert.Equal(t, constant.ErrConversationNotFound, err)
+	})
+
 	t.Run("repository error", func(t *testing.T) {
 		chatID := uuid.New().String()
 
-		mockRepo.On("GetByID", chatID).Return([]chatbot.Chatbot{}, errors.New("repository error")).Once()
+		mockRepo.On("GetConversation", chatID).Return(chatbot.Conversation{ID: chatID, UserID: "user1"}, nil).Once()
+		mockRepo.On("GetMessages", chatID, 1).Return([]chatbot.Chatbot{}, 0, errors.New("repository error")).Once()
 
-		result, err := service.GetByID(chatID)
+		_, _, err := service.GetByID("user1", chatID, 1)
 		assert.Error(t, err)
-		assert.Equal(t, []chatbot.Chatbot{}, result)
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestGetConversations(t *testing.T) {
+	mockRepo := new(MockChatbotRepository)
+	service := NewChatbotService(mockRepo, nil, nil, nil, nil, nil, configs.ChatbotConfig{})
+
+	expected := []chatbot.Conversation{{ID: "chat1", UserID: "user1", Title: "Composting"}}
+	mockRepo.On("GetConversations", "user1", 1).Return(expected, 1, nil)
+
+	result, totalPages, err := service.GetConversations("user1", 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	assert.Equal(t, 1, totalPages)
+}
+
+func TestRenameConversation(t *testing.T) {
+	mockRepo := new(MockChatbotRepository)
+	service := NewChatbotService(mockRepo, nil, nil, nil, nil, nil, configs.ChatbotConfig{})
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.On("GetConversation", "chat1").Return(chatbot.Conversation{ID: "chat1", UserID: "user1"}, nil).Once()
+		mockRepo.On("UpdateConversationTitle", "chat1", "Zero waste tips").Return(nil).Once()
+
+		err := service.RenameConversation("user1", "chat1", "  Zero waste tips ")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid title", func(t *testing.T) {
+		assert.Equal(t, constant.ErrConversationTitle, service.RenameConversation("user1", "chat1", "   "))
+		assert.Equal(t, constant.ErrConversationTitle, service.RenameConversation("user1", "chat1", strings.Repeat("a", chatbot.MaxTitleLength+1)))
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		mockRepo.On("GetConversation", "chat2").Return(chatbot.Conversation{ID: "chat2", UserID: "user1"}, nil).Once()
+
+		err := service.RenameConversation("user2", "chat2", "Mine now")
+
+		assert.Equal(t, constant.ErrConversationNotFound, err)
+		mockRepo.AssertNotCalled(t, "UpdateConversationTitle", "chat2", "Mine now")
+	})
+}
+
+func TestDeleteConversation(t *testing.T) {
+	mockRepo := new(MockChatbotRepository)
+	service := NewChatbotService(mockRepo, nil, nil, nil, nil, nil, configs.ChatbotConfig{})
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.On("GetConversation", "chat1").Return(chatbot.Conversation{ID: "chat1", UserID: "user1"}, nil).Once()
+		mockRepo.On("DeleteConversation", "chat1").Return(nil).Once()
+
+		err := service.DeleteConversation("user1", "chat1")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		mockRepo.On("GetConversation", "chat2").Return(chatbot.Conversation{ID: "chat2", UserID: "user1"}, nil).Once()
+
+		err := service.DeleteConversation("user2", "chat2")
+
+		assert.Equal(t, constant.ErrConversationNotFound, err)
+		mockRepo.AssertNotCalled(t, "DeleteConversation", "chat2")
+	})
+}
+
+func TestCreateStream(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("relays tokens and saves the answer", func(t *testing.T) {
+		mockRepo := new(MockChatbotRepository)
+		fake := llm.NewFakeProvider("Use a tote.")
+		service := NewChatbotService(mockRepo, nil, nil, nil, nil, fake, configs.ChatbotConfig{})
+		writer := &recordingWriter{}
+
+		mockRepo.On("GetConversation", "chat1").Return(chatbot.Conversation{ID: "chat1", UserID: "user1"}, nil)
+		mockRepo.On("GetHistory", "chat1", time.Time{}).Return([]chatbot.Chatbot{{Role: "user", Message: "Hi"}, {Role: "assistant", Message: "Hello!"}}, nil)
+		mockRepo.On("SaveTurn", (*chatbot.Conversation)(nil), mock.MatchedBy(func(chat chatbot.Chatbot) bool {
+			return chat.Role == "user" && chat.Message == "Any tips?"
+		}), mock.MatchedBy(func(chat chatbot.Chatbot) bool {
+			return chat.Role == "assistant" && chat.Message == "Use a tote." && chat.ChatID == "chat1"
+		})).Return(chatbot.Chatbot{ID: "answer1", ChatID: "chat1", Role: "assistant", Message: "Use a tote."}, nil).Once()
+		mockRepo.On("AddUsage", mock.Anything, mock.Anything, 1, mock.Anything).Return(nil).Once()
+
+		result, err := service.CreateStream(ctx, chatbot.Chatbot{ChatID: "chat1", UserID: "user1", Message: "Any tips?"}, writer)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "answer1", result.ID)
+		assert.Equal(t, "chat1", writer.chatID)
+		assert.Equal(t, []string{"Use ", "a ", "tote."}, writer.tokens)
+		if assert.Len(t, fake.Requests(), 1) {
+			messages := fake.Requests()[0]
+			assert.Len(t, messages, 4)
+			assert.Equal(t, "Any tips?", messages[3].Content)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("saves the partial answer when the client disconnects", func(t *testing.T) {
+		mockRepo := new(MockChatbotRepository)
+		service := NewChatbotService(mockRepo, nil, nil, nil, nil, llm.NewFakeProvider("Use a tote."), configs.ChatbotConfig{})
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		mockRepo.On("GetConversation", "chat1").Return(chatbot.Conversation{ID: "chat1", UserID: "user1"}, nil)
+		mockRepo.On("GetHistory", "chat1", time.Time{}).Return([]chatbot.Chatbot{}, nil)
+		mockRepo.On("SaveTurn", (*chatbot.Conversation)(nil), mock.Anything, mock.MatchedBy(func(chat chatbot.Chatbot) bool {
+			return chat.Role == "assistant" && chat.Message == "Use "
+		})).Return(chatbot.Chatbot{ID: "answer1", Message: "Use "}, nil).Once()
+		mockRepo.On("AddUsage", mock.Anything, mock.Anything, 1, mock.Anything).Return(nil).Once()
+
+		writer := &cancellingWriter{cancel: cancel}
+		result, err := service.CreateStream(ctx, chatbot.Chatbot{ChatID: "chat1", UserID: "user1", Message: "Any tips?"}, writer)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, "answer1", result.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("saves nothing when no text was generated", func(t *testing.T) {
+		mockRepo := new(MockChatbotRepository)
+		fake := llm.NewFakeProvider()
+		fake.Fail(errors.New("rate limited"))
+		service := NewChatbotService(mockRepo, nil, nil, nil, nil, fake, configs.ChatbotConfig{})
+
+		_, err := service.CreateStream(ctx, chatbot.Chatbot{UserID: "user1", Message: "Any tips?"}, &recordingWriter{})
+
+		assert.EqualError(t, err, "rate limited")
+		mockRepo.AssertNotCalled(t, "SaveTurn", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "AddUsage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("empty answer", func(t *testing.T) {
+		mockRepo := new(MockChatbotRepository)
+		service := NewChatbotService(mockRepo, nil, nil, nil, nil, llm.NewFakeProvider(""), configs.ChatbotConfig{})
+
+		_, err := service.CreateStream(ctx, chatbot.Chatbot{UserID: "user1", Message: "Any tips?"}, &recordingWriter{})
+
+		assert.Equal(t, constant.ErrChatbotNoAnswer, err)
+		mockRepo.AssertNotCalled(t, "SaveTurn", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("does not start the stream for another user's conversation", func(t *testing.T) {
+		mockRepo := new(MockChatbotRepository)
+		fake := llm.NewFakeProvider()
+		service := NewChatbotService(mockRepo, nil, nil, nil, nil, fake, configs.ChatbotConfig{})
+		writer := &recordingWriter{}
+
+		mockRepo.On("GetConversation", "chat1").Return(chatbot.Conversation{ID: "chat1", UserID: "owner"}, nil)
+
+		_, err := service.CreateStream(ctx, chatbot.Chatbot{ChatID: "chat1", UserID: "user1", Message: "Hi"}, writer)
+
+		assert.Equal(t, constant.ErrConversationNotFound, err)
+		assert.Empty(t, writer.chatID)
+		assert.Empty(t, fake.Requests())
+	})
+}
+
+// longHistory returns n alternating messages of about 200 tokens each, one
+// minute apart.
+func longHistory(n int) []chatbot.Chatbot {
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	history := make([]chatbot.Chatbot, n)
+	for i := range history {
+		role := llm.RoleUser
+		if i%2 == 1 {
+			role = llm.RoleAssistant
+		}
+		history[i] = chatbot.Chatbot{
+			ChatID:    "chat1",
+			Role:      role,
+			Message:   fmt.Sprintf("message %d %s", i, strings.Repeat("x", 790)),
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	return history
+}
+
+func TestCreateChatbot_SummarizesOlderTurns(t *testing.T) {
+	mockRepo := new(MockChatbotRepository)
+	fake := llm.NewFakeProvider("Short summary.", "Use a tote.")
+	service := NewChatbotService(mockRepo, nil, nil, nil, nil, fake, configs.ChatbotConfig{ContextTokens: 1200})
+	history := longHistory(10)
+
+	mockRepo.On("GetConversation", "chat1").Return(chatbot.Conversation{ID: "chat1", UserID: "user1"}, nil)
+	mockRepo.On("GetHistory", "chat1", time.Time{}).Return(history, nil)
+	mockRepo.On("SaveTurn", (*chatbot.Conversation)(nil), mock.Anything, mock.Anything).Return(chatbot.Chatbot{Message: "Use a tote."}, nil)
+	mockRepo.On("UpdateConversationSummary", "chat1", "Short summary.", history[7].CreatedAt).Return(nil).Once()
+	mockRepo.On("AddUsage", "user1", mock.Anything, 1, mock.Anything).Return(nil)
+
+	_, err := service.Create(chatbot.Chatbot{ChatID: "chat1", UserID: "user1", Message: "Any tips?"})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	requests := fake.Requests()
+	if !assert.Len(t, requests, 2) {
+		return
+	}
+	transcript := requests[0][1].Content
+	assert.Contains(t, transcript, "message 0 ")
+	assert.Contains(t, transcript, "message 7 ")
+	assert.NotContains(t, transcript, "message 8 ")
+
+	prompt := requests[1]
+	assert.LessOrEqual(t, llm.CountTokens(prompt), 1200)
+	if assert.Len(t, prompt, 5) {
+		assert.Equal(t, summaryPrefix+"Short summary.", prompt[1].Content)
+		assert.Equal(t, history[8].Message, prompt[2].Content)
+		assert.Equal(t, history[9].Message, prompt[3].Content)
+		assert.Equal(t, "Any tips?", prompt[4].Content)
+	}
+
+	// Usage includes the summary request as well as the answer.
+	tokens := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Int(3)
+	assert.Greater(t, tokens, llm.CountTokens(prompt)+llm.CountTokens(requests[0]))
+}
+
+func TestCreateChatbot_UsesStoredSummary(t *testing.T) {
+	mockRepo := new(MockChatbotRepository)
+	fake := llm.NewFakeProvider("Use a tote.")
+	service := NewChatbotService(mockRepo, nil, nil, nil, nil, fake, configs.ChatbotConfig{ContextTokens: 4000})
+	until := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	mockRepo.On("GetConversation", "chat1").Return(chatbot.Conversation{
+		ID: "chat1", UserID: "user1", Summary: "The user composts at home.", SummarizedUntil: until,
+	}, nil)
+	mockRepo.On("GetHistory", "chat1", until).Return([]chatbot.Chatbot{{Role: "user", Message: "Hi"}}, nil)
+	mockRepo.On("SaveTurn", (*chatbot.Conversation)(nil), mock.Anything, mock.Anything).Return(chatbot.Chatbot{}, nil)
+	mockRepo.On("AddUsage", "user1", mock.Anything, 1, mock.Anything).Return(nil)
+
+	_, err := service.Create(chatbot.Chatbot{ChatID: "chat1", UserID: "user1", Message: "Any tips?"})
+
+	assert.NoError(t, err)
+	prompt := fake.Requests()[0]
+	if assert.Len(t, prompt, 4) {
+		assert.Equal(t, llm.Message{Role: llm.RoleSystem, Content: summaryPrefix + "The user composts at home."}, prompt[1])
+		assert.Equal(t, "Hi", prompt[2].Content)
+	}
+	mockRepo.AssertNotCalled(t, "UpdateConversationSummary", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateChatbot_SlidingWindowWhenSummaryFails(t *testing.T) {
+	mockRepo := new(MockChatbotRepository)
+	fake := llm.NewFakeProvider("Short summary.", "Use a tote.")
+	service := NewChatbotService(mockRepo, nil, nil, nil, nil, fake, configs.ChatbotConfig{ContextTokens: 1200})
+	history := longHistory(10)
+
+	mockRepo.On("GetConversation", "chat1").Return(chatbot.Conversation{ID: "chat1", UserID: "user1"}, nil)
+	mockRepo.On("GetHistory", "chat1", time.Time{}).Return(history, nil)
+	mockRepo.On("SaveTurn", (*chatbot.Conversation)(nil), mock.Anything, mock.Anything).Return(chatbot.Chatbot{}, nil)
+	mockRepo.On("UpdateConversationSummary", "chat1", mock.Anything, mock.Anything).Return(errors.New("database down"))
+	mockRepo.On("AddUsage", "user1", mock.Anything, 1, mock.Anything).Return(nil)
+
+	_, err := service.Create(chatbot.Chatbot{ChatID: "chat1", UserID: "user1", Message: "Any tips?"})
+
+	assert.NoError(t, err)
+	prompt := fake.Requests()[1]
+	assert.LessOrEqual(t, llm.CountTokens(prompt), 1200)
+	assert.Greater(t, len(prompt), 4)
+	assert.Equal(t, history[9].Message, prompt[len(prompt)-2].Content)
+	for _, message := range prompt {
+		assert.NotContains(t, message.Content, "message 0 ")
+		assert.False(t, strings.HasPrefix(message.Content, summaryPrefix))
+	}
+}
+
+func TestCreateChatbot_Quota(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	config := configs.ChatbotConfig{DailyMessageQuota: 10, DailyTokenQuota: 1000}
+
+	newService := func(repo *MockChatbotRepository, provider llm.Provider) *ChatbotService {
+		service := NewChatbotService(repo, nil, nil, nil, nil, provider, config).(*ChatbotService)
+		service.now = func() time.Time { return now }
+		return service
+	}
+
+	t.Run("message limit reached", func(t *testing.T) {
+		mockRepo := new(MockChatbotRepository)
+		fake := llm.NewFakeProvider()
+		service := newService(mockRepo, fake)
+
+		mockRepo.On("ReserveUsage", "user1", "2024-05-01", 10, 1000).Return(false, nil)
+		mockRepo.On("GetUsage", "user1", "2024-05-01").Return(chatbot.Usage{Day: "2024-05-01", Messages: 10, Tokens: 500}, nil)
+
+		_, err := service.Create(chatbot.Chatbot{UserID: "user1", Message: "Hello"})
+
+		assert.Equal(t, constant.ErrChatbotMessageQuota, err)
+		assert.Empty(t, fake.Requests())
+		mockRepo.AssertNotCalled(t, "SaveTurn", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "AddUsage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("token limit reached", func(t *testing.T) {
+		mockRepo := new(MockChatbotRepository)
+		writer := &recordingWriter{}
+		service := newService(mockRepo, llm.NewFakeProvider())
+
+		mockRepo.On("ReserveUsage", "user1", "2024-05-01", 10, 1000).Return(false, nil)
+		mockRepo.On("GetUsage", "user1", "2024-05-01").Return(chatbot.Usage{Day: "2024-05-01", Messages: 3, Tokens: 1000}, nil)
+
+		_, err := service.CreateStream(context.Background(), chatbot.Chatbot{UserID: "user1", Message: "Hello"}, writer)
+
+		assert.Equal(t, constant.ErrChatbotTokenQuota, err)
+		assert.Empty(t, writer.chatID)
+	})
+
+	t.Run("records usage of the day", func(t *testing.T) {
+		mockRepo := new(MockChatbotRepository)
+		fake := llm.NewFakeProvider("Hi there!")
+		service := newService(mockRepo, fake)
+
+		mockRepo.On("ReserveUsage", "user1", "2024-05-01", 10, 1000).Return(true, nil).Once()
+		mockRepo.On("SaveTurn", mock.Anything, mock.MatchedBy(func(chat chatbot.Chatbot) bool {
+			return chat.CreatedAt.Equal(now)
+		}), mock.MatchedBy(func(chat chatbot.Chatbot) bool {
+			return chat.CreatedAt.Equal(now.Add(time.Millisecond))
+		})).Return(chatbot.Chatbot{}, nil)
+		mockRepo.On("AddUsage", "user1", "2024-05-01", 0, mock.MatchedBy(func(tokens int) bool {
+			return tokens == llm.CountTokens(fake.Requests()[0])+service.toolTokens+llm.EstimateTokens("Hi there!")
+		})).Return(nil).Once()
+
+		_, err := service.Create(chatbot.Chatbot{UserID: "user1", Message: "Hello"})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("usage error does not fail the answer", func(t *testing.T) {
+		mockRepo := new(MockChatbotRepository)
+		service := newService(mockRepo, llm.NewFakeProvider("Hi there!"))
+
+		mockRepo.On("ReserveUsage", "user1", "2024-05-01", 10, 1000).Return(true, nil)
+		mockRepo.On("SaveTurn", mock.Anything, mock.Anything, mock.Anything).Return(chatbot.Chatbot{Message: "Hi there!"}, nil)
+		mockRepo.On("AddUsage", "user1", "2024-05-01", 0, mock.Anything).Return(errors.New("database down"))
+
+		result, err := service.Create(chatbot.Chatbot{UserID: "user1", Message: "Hello"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Hi there!", result.Message)
+	})
+
+	t.Run("releases the message when no answer is saved", func(t *testing.T) {
+		mockRepo := new(MockChatbotRepository)
+		fake := llm.NewFakeProvider()
+		fake.Fail(errors.New("provider error"))
+		service := newService(mockRepo, fake)
+
+		mockRepo.On("ReserveUsage", "user1", "2024-05-01", 10, 1000).Return(true, nil).Once()
+		mockRepo.On("AddUsage", "user1", "2024-05-01", -1, 0).Return(nil).Once()
+
+		_, err := service.Create(chatbot.Chatbot{UserID: "user1", Message: "Hello"})
+
+		assert.EqualError(t, err, "provider error")
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "SaveTurn", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("releases the message for another user's conversation", func(t *testing.T) {
+		mockRepo := new(MockChatbotRepository)
+		service := newService(mockRepo, llm.NewFakeProvider())
+
+		mockRepo.On("ReserveUsage", "user1", "2024-05-01", 10, 1000).Return(true, nil).Once()
+		mockRepo.On("GetConversation", "chat1").Return(chatbot.Conversation{ID: "chat1", UserID: "owner"}, nil)
+		mockRepo.On("AddUsage", "user1", "2024-05-01", -1, 0).Return(nil).Once()
+
+		_, err := service.Create(chatbot.Chatbot{ChatID: "chat1", UserID: "user1", Message: "Hello"})
+
+		assert.Equal(t, constant.ErrConversationNotFound, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetUsage(t *testing.T) {
+	mockRepo := new(MockChatbotRepository)
+	service := NewChatbotService(mockRepo, nil, nil, nil, nil, nil, configs.ChatbotConfig{DailyMessageQuota: 100, DailyTokenQuota: 5000}).(*ChatbotService)
+	service.now = func() time.Time { return time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC) }
+
+	mockRepo.On("GetUsage", "user1", "2024-05-01").Return(chatbot.Usage{Day: "2024-05-01", Messages: 4, Tokens: 1200}, nil)
+
+	usage, err := service.GetUsage("user1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, chatbot.Usage{Day: "2024-05-01", Messages: 4, Tokens: 1200, MessageLimit: 100, TokenLimit: 5000}, usage)
+}
+
+// toolTestService returns a service for a new conversation of user1 whose
+// answer is saved.
+func toolTestService(fake *llm.FakeProvider) (*ChatbotService, *MockChatbotRepository, *MockProductRepository, *MockChallengeRepository, *MockImpactService) {
+	mockRepo := new(MockChatbotRepository)
+	mockProducts := new(MockProductRepository)
+	mockChallenges := new(MockChallengeRepository)
+	mockImpacts := new(MockImpactService)
+	service := NewChatbotService(mockRepo, mockProducts, mockChallenges, mockImpacts, nil, fake, configs.ChatbotConfig{}).(*ChatbotService)
+
+	mockRepo.On("AddUsage", "user1", mock.Anything, 1, mock.Anything).Return(nil)
+	return service, mockRepo, mockProducts, mockChallenges, mockImpacts
+}
+
+// savedAnswer returns what the assistant message was saved with.
+func savedAnswer(mockRepo *MockChatbotRepository) *chatbot.Chatbot {
+	var saved chatbot.Chatbot
+	mockRepo.On("SaveTurn", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { saved = args.Get(2).(chatbot.Chatbot) }).
+		Return(chatbot.Chatbot{}, nil)
+	return &saved
+}
+
+func TestCreateChatbot_SearchProductsTool(t *testing.T) {
+	fake := llm.NewFakeProvider()
+	fake.CallTools(llm.ToolCall{ID: "call1", Name: toolSearchProducts, Arguments: `{"query":"bottle"}`})
+	fake.Reply("Try the Bamboo Bottle.")
+	service, mockRepo, mockProducts, _, _ := toolTestService(fake)
+	saved := savedAnswer(mockRepo)
+
+	mockProducts.On("GetAllByPage", 1, "bottle", "").Return([]products.Product{{
+		ID:     "p1",
+		Name:   "Bamboo Bottle",
+		Price:  50000,
+		Coin:   10,
+		Stock:  3,
+		Images: []products.ProductImage{{AlbumsURL: "https://img/bottle.png"}},
+	}}, 1, nil)
+
+	_, err := service.Create(chatbot.Chatbot{UserID: "user1", Message: "Do you sell bottles?"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Try the Bamboo Bottle.", saved.Message)
+	assert.Equal(t, []chatbot.Card{{
+		Type:     chatbot.CardProduct,
+		ID:       "p1",
+		Title:    "Bamboo Bottle",
+		ImageURL: "https://img/bottle.png",
+		Price:    50000,
+		Coin:     10,
+	}}, saved.Cards)
+
+	requests := fake.Requests()
+	if assert.Len(t, requests, 2) {
+		followUp := requests[1]
+		call := followUp[len(followUp)-2]
+		result := followUp[len(followUp)-1]
+		assert.Equal(t, llm.RoleAssistant, call.Role)
+		assert.Equal(t, "call1", call.ToolCalls[0].ID)
+		assert.Equal(t, llm.RoleTool, result.Role)
+		assert.Equal(t, "call1", result.ToolCallID)
+		assert.Contains(t, result.Content, `"name":"Bamboo Bottle"`)
+		assert.Contains(t, result.Content, `"stock":3`)
+	}
+}
+
+func TestCreateChatbot_SearchProductsByCategory(t *testing.T) {
+	fake := llm.NewFakeProvider()
+	fake.CallTools(llm.ToolCall{ID: "call1", Name: toolSearchProducts, Arguments: `{"category":"Kitchen"}`})
+	fake.Reply("We have nothing in that category yet.")
+	service, mockRepo, mockProducts, _, _ := toolTestService(fake)
+	saved := savedAnswer(mockRepo)
+
+	mockProducts.On("GetByCategory", "Kitchen", 1, "", "").Return([]products.Product(nil), 0, constant.ErrProductEmpty)
+
+	_, err := service.Create(chatbot.Chatbot{UserID: "user1", Message: "Kitchen products?"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, saved.Cards)
+	followUp := fake.Requests()[1]
+	assert.Equal(t, "[]", followUp[len(followUp)-1].Content)
+	mockProducts.AssertNotCalled(t, "GetAllByPage", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateChatbot_UserChallengesAndImpactTools(t *testing.T) {
+	fake := llm.NewFakeProvider()
+	fake.CallTools(
+		llm.ToolCall{ID: "call1", Name: toolMyChallenges},
+		llm.ToolCall{ID: "call2", Name: toolMyImpact, Arguments: "{}"},
+	)
+	fake.Reply("You are doing great.")
+	service, mockRepo, _, mockChallenges, mockImpacts := toolTestService(fake)
+	saved := savedAnswer(mockRepo)
+
+	mockChallenges.On("GetChallengeHistory", "user1").Return([]challenges.ChallengeLog{
+		{ChallengeID: "c1", Status: "Progress"},
+		{ChallengeID: "c2", Status: "Done"},
+	}, nil)
+	mockChallenges.On("GetChallengeByID", "c1").Return(challenges.Challenge{
+		ID: "c1", Title: "Plastic free week", Difficulty: "Easy", ChallengeImg: "https://img/c1.png", Exp: 50, Coin: 5,
+	}, nil)
+	mockImpacts.On("GetUserImpact", "user1", 0).Return(impacts.UserImpactSummary{
+		TotalPoints: 120,
+		Metrics:     impacts.ImpactMetrics{CO2Kg: 4.5},
+		Categories:  []impacts.CategoryImpact{{Name: "Plastic", Points: 120}},
+	}, nil)
+
+	_, err := service.Create(chatbot.Chatbot{UserID: "user1", Message: "How am I doing?"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []chatbot.Card{{
+		Type:       chatbot.CardChallenge,
+		ID:         "c1",
+		Title:      "Plastic free week",
+		ImageURL:   "https://img/c1.png",
+		Coin:       5,
+		Exp:        50,
+		Difficulty: "Easy",
+		Status:     "Progress",
+	}}, saved.Cards)
+	mockChallenges.AssertNotCalled(t, "GetChallengeByID", "c2")
+
+	followUp := fake.Requests()[1]
+	assert.Equal(t, "call1", followUp[len(followUp)-2].ToolCallID)
+	assert.Contains(t, followUp[len(followUp)-2].Content, `"title":"Plastic free week"`)
+	assert.Equal(t, "call2", followUp[len(followUp)-1].ToolCallID)
+	assert.Contains(t, followUp[len(followUp)-1].Content, `"total_points":120`)
+	assert.Contains(t, followUp[len(followUp)-1].Content, `"co2_kg":4.5`)
+}
+
+func TestCreateChatbot_ToolErrorsGoToTheModel(t *testing.T) {
+	fake := llm.NewFakeProvider()
+	fake.CallTools(
+		llm.ToolCall{ID: "call1", Name: toolGetChallenge, Arguments: `{"challenge_id":"draft1"}`},
+		llm.ToolCall{ID: "call2", Name: toolSearchChallenges, Arguments: `{"query":`},
+		llm.ToolCall{ID: "call3", Name: "delete_everything"},
+	)
+	fake.Reply("I could not find that challenge.")
+	service, mockRepo, _, mockChallenges, _ := toolTestService(fake)
+	saved := savedAnswer(mockRepo)
+
+	mockChallenges.On("GetChallengeByID", "draft1").Return(challenges.Challenge{ID: "draft1", Status: "Draft"}, nil)
+
+	_, err := service.Create(chatbot.Chatbot{UserID: "user1", Message: "Tell me about draft1"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, saved.Cards)
+	followUp := fake.Requests()[1]
+	results := followUp[len(followUp)-3:]
+	assert.Equal(t, `{"error":"`+constant.ErrChallengeNotFound.Error()+`"}`, results[0].Content)
+	assert.Contains(t, results[1].Content, "invalid arguments")
+	assert.Contains(t, results[2].Content, "unknown tool")
+	mockChallenges.AssertNotCalled(t, "GetAvailableChallenges", mock.Anything)
+}
+
+func TestCreateChatbot_SearchChallengesTool(t *testing.T) {
+	fake := llm.NewFakeProvider()
+	fake.CallTools(llm.ToolCall{ID: "call1", Name: toolSearchChallenges, Arguments: `{"query":"PLASTIC"}`})
+	fake.Reply("Join Plastic free week.")
+	service, mockRepo, _, mockChallenges, _ := toolTestService(fake)
+	saved := savedAnswer(mockRepo)
+
+	mockChallenges.On("GetAvailableChallenges", "user1").Return([]challenges.Challenge{
+		{ID: "c1", Title: "Plastic free week", Difficulty: "Easy"},
+		{ID: "c2", Title: "Bike to work", Description: "Leave the car at home"},
+		{ID: "c3", Title: "Refill day", Description: "Bring your own plastic-free bottle"},
+	}, nil)
+
+	_, err := service.Create(chatbot.Chatbot{UserID: "user1", Message: "Any plastic challenges?"})
+
+	assert.NoError(t, err)
+	if assert.Len(t, saved.Cards, 2) {
+		assert.Equal(t, "c1", saved.Cards[0].ID)
+		assert.Equal(t, "c3", saved.Cards[1].ID)
+	}
+}
+
+func TestCreateChatbot_ToolRoundsAreLimited(t *testing.T) {
+	fake := llm.NewFakeProvider()
+	for i := 0; i < maxToolRounds+2; i++ {
+		fake.CallTools(llm.ToolCall{ID: fmt.Sprintf("call%d", i), Name: toolSearchProducts, Arguments: `{"query":"bag"}`})
+	}
+	service, _, mockProducts, _, _ := toolTestService(fake)
+
+	mockProducts.On("GetAllByPage", 1, "bag", "").Return([]products.Product{{ID: "p1", Name: "Tote bag"}}, 1, nil)
+
+	_, err := service.Create(chatbot.Chatbot{UserID: "user1", Message: "Bags?"})
+
+	assert.Equal(t, constant.ErrChatbotNoAnswer, err)
+	assert.Len(t, fake.Requests(), maxToolRounds+1)
+	mockProducts.AssertNumberOfCalls(t, "GetAllByPage", maxToolRounds)
+}
+
+func TestCreateStream_WithTools(t *testing.T) {
+	fake := llm.NewFakeProvider()
+	fake.CallTools(llm.ToolCall{ID: "call1", Name: toolSearchProducts, Arguments: `{"query":"bag"}`})
+	fake.Reply("Use a tote.")
+	service, mockRepo, mockProducts, _, _ := toolTestService(fake)
+	saved := savedAnswer(mockRepo)
+	writer := &recordingWriter{}
+
+	mockProducts.On("GetAllByPage", 1, "bag", "").Return([]products.Product{{ID: "p1", Name: "Tote bag"}}, 1, nil)
+
+	_, err := service.CreateStream(context.Background(), chatbot.Chatbot{UserID: "user1", Message: "Bags?"}, writer)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Use ", "a ", "tote."}, writer.tokens)
+	assert.Equal(t, "Use a tote.", saved.Message)
+	if assert.Len(t, saved.Cards, 1) {
+		assert.Equal(t, "p1", saved.Cards[0].ID)
+	}
+}
+
+func knowledgeTestService(fake *llm.FakeProvider) (*ChatbotService, *MockChatbotRepository, *MockKnowledgeService) {
+	mockRepo := new(MockChatbotRepository)
+	mockKnowledge := new(MockKnowledgeService)
+	service := NewChatbotService(mockRepo, nil, nil, nil, mockKnowledge, fake, configs.ChatbotConfig{}).(*ChatbotService)
+
+	mockRepo.On("AddUsage", "user1", mock.Anything, 1, mock.Anything).Return(nil)
+	return service, mockRepo, mockKnowledge
+}
+
+func TestCreateChatbot_CitesKnowledgeBase(t *testing.T) {
+	fake := llm.NewFakeProvider("Rinse plastic before recycling [2] and compost peels [1]. See also [7].")
+	service, mockRepo, mockKnowledge := knowledgeTestService(fake)
+	saved := savedAnswer(mockRepo)
+
+	mockKnowledge.On("Search", "How do I recycle?").Return([]knowledge.Passage{
+		{DocumentID: "doc1", Title: "Composting FAQ", Content: "Fruit peels can be composted.", Score: 0.8},
+		{DocumentID: "doc2", Title: "Recycling guide", Source: "https://example.com/recycling", Heading: "Plastic", Content: "Rinse containers first.", Score: 0.7},
+		{DocumentID: "doc3", Title: "Water saving", Content: "Fix leaking taps.", Score: 0.5},
+	}, nil)
+
+	_, err := service.Create(chatbot.Chatbot{UserID: "user1", Message: "How do I recycle?"})
+
+	assert.NoError(t, err)
+	prompt := fake.Requests()[0]
+	if assert.Len(t, prompt, 3) {
+		assert.Equal(t, llm.RoleSystem, prompt[1].Role)
+		assert.True(t, strings.HasPrefix(prompt[1].Content, knowledgeRole))
+		assert.Contains(t, prompt[1].Content, "[1] Composting FAQ\nFruit peels can be composted.")
+		assert.Contains(t, prompt[1].Content, "[2] Recycling guide - Plastic\nRinse containers first.")
+		assert.Contains(t, prompt[1].Content, "[3] Water saving")
+	}
+	assert.Equal(t, []chatbot.Source{
+		{Number: 1, DocumentID: "doc1", Title: "Composting FAQ"},
+		{Number: 2, DocumentID: "doc2", Title: "Recycling guide", URL: "https://example.com/recycling"},
+	}, saved.Sources)
+}
+
+func TestCreateChatbot_AnswersWhenKnowledgeSearchFails(t *testing.T) {
+	fake := llm.NewFakeProvider("Use a tote [1].")
+	service, mockRepo, mockKnowledge := knowledgeTestService(fake)
+	saved := savedAnswer(mockRepo)
+
+	mockKnowledge.On("Search", "Bags?").Return([]knowledge.Passage(nil), errors.New("embedding service down"))
+
+	_, err := service.Create(chatbot.Chatbot{UserID: "user1", Message: "Bags?"})
+
+	assert.NoError(t, err)
+	assert.Len(t, fake.Requests()[0], 2)
+	assert.Equal(t, "Use a tote [1].", saved.Message)
+	assert.Empty(t, saved.Sources)
+}
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"greenenvironment/constant"
+	"greenenvironment/features/chatbot"
+	"greenenvironment/features/products"
+	"greenenvironment/utils/llm"
+	"log"
+	"strings"
+)
+
+// maxToolRounds is how many times the model may call tools for one answer.
+// After that it is asked to answer with what it has.
+const maxToolRounds = 3
+
+// maxToolResults caps the items one tool call returns.
+const maxToolResults = 5
+
+const (
+	toolSearchProducts   = "search_products"
+	toolSearchChallenges = "search_challenges"
+	toolGetChallenge     = "get_challenge"
+	toolMyChallenges     = "get_my_challenges"
+	toolMyImpact         = "get_my_impact"
+)
+
+var chatTools = []llm.Tool{
+	{
+		Name:        toolSearchProducts,
+		Description: "Search the eco-friendly products sold in the Ecomate store by name, optionally within a category.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query":    map[string]interface{}{"type": "string", "description": "Words from the product name."},
+				"category": map[string]interface{}{"type": "string", "description": "Optional product category name."},
+			},
+		},
+	},
+	{
+		Name:        toolSearchChallenges,
+		Description: "Search the challenges the user can still join by title or description.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{"type": "string", "description": "Words to look for; leave empty to list challenges."},
+			},
+		},
+	},
+	{
+		Name:        toolGetChallenge,
+		Description: "Get the details of one challenge by its ID.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"challenge_id": map[string]interface{}{"type": "string"},
+			},
+			"required": []string{"challenge_id"},
+		},
+	},
+	{
+		Name:        toolMyChallenges,
+		Description: "List the challenges the user is currently taking part in.",
+		Parameters:  map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+	},
+	{
+		Name:        toolMyImpact,
+		Description: "Get the user's environmental impact: total points, CO2, plastic and water saved, per category.",
+		Parameters:  map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+	},
+}
+
+// answer gets the model's answer to the prompt, running the tools it asks
+// for along the way. Text is relayed to onDelta when it is not nil. It
+// returns the answer, the cards of what the tools found and the tokens the
+// extra rounds used; on error the text so far is returned with it.
+func (cs *ChatbotService) answer(ctx context.Context, t turn, userID string, onDelta func(string) error) (string, []chatbot.Card, int, error) {
+	prompt := t.prompt
+	tokens := 0
+	var answer strings.Builder
+	var cards []chatbot.Card
+
+	for round := 0; ; round++ {
+		tools := chatTools
+		if round == maxToolRounds {
+			tools = nil
+		}
+		if round > 0 {
+			tokens += llm.CountTokens(prompt)
+		}
+		if tools != nil {
+			tokens += cs.toolTokens
+		}
+
+		reply, err := cs.llm.Chat(ctx, prompt, tools, onDelta)
+		answer.WriteString(reply.Content)
+		if err != nil || len(reply.ToolCalls) == 0 || tools == nil {
+			return answer.String(), cards, tokens, err
+		}
+		tokens += llm.MessageTokens(reply)
+
+		prompt = append(prompt[:len(prompt):len(prompt)], reply)
+		for _, call := range reply.ToolCalls {
+			result, found := cs.callTool(userID, call)
+			cards = addCards(cards, found)
+			prompt = append(prompt, llm.Message{
+				Role:       llm.RoleTool,
+				Content:    result,
+				ToolCallID: call.ID,
+			})
+		}
+	}
+}
+
+// addCards appends cards that are not shown yet.
+func addCards(cards []chatbot.Card, found []chatbot.Card) []chatbot.Card {
+	for _, card := range found {
+		shown := false
+		for _, existing := range cards {
+			if existing.Type == card.Type && existing.ID == card.ID {
+				shown = true
+				break
+			}
+		}
+		if !shown {
+			cards = append(cards, card)
+		}
+	}
+	return cards
+}
+
+type toolArguments struct {
+	Query       string `json:"query"`
+	Category    string `json:"category"`
+	ChallengeID string `json:"challenge_id"`
+}
+
+// callTool runs a tool for the user and returns its result as JSON for the
+// model. Failures are reported to the model, which can tell the user,
+// rather than ending the answer.
+func (cs *ChatbotService) callTool(userID string, call llm.ToolCall) (string, []chatbot.Card) {
+	var args toolArguments
+	if strings.TrimSpace(call.Arguments) != "" {
+		if err := json.Unmarshal([]byte(call.Arguments), &args); err != nil {
+			return toolError("invalid arguments: " + err.Error()), nil
+		}
+	}
+
+	var result interface{}
+	var cards []chatbot.Card
+	var err error
+	switch call.Name {
+	case toolSearchProducts:
+		result, cards, err = cs.searchProducts(args.Query, args.Category)
+	case toolSearchChallenges:
+		result, cards, err = cs.searchChallenges(userID, args.Query)
+	case toolGetChallenge:
+		result, cards, err = cs.getChallenge(args.ChallengeID)
+	case toolMyChallenges:
+		result, cards, err = cs.myChallenges(userID)
+	case toolMyImpact:
+		result, err = cs.myImpact(userID)
+	default:
+		return toolError(fmt.Sprintf("unknown tool %q", call.Name)), nil
+	}
+	if err != nil {
+		log.Printf("chatbot tool %s failed for user %s: %v", call.Name, userID, err)
+		return toolError(err.Error()), nil
+	}
+
+	content, err := json.Marshal(result)
+	if err != nil {
+		return toolError(err.Error()), nil
+	}
+	return string(content), cards
+}
+
+func toolError(message string) string {
+	content, _ := json.Marshal(map[string]string{"error": message})
+	return string(content)
+}
+
+type productResult struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Price       float64  `json:"price"`
+	Coin        int      `json:"coin"`
+	Stock       int      `json:"stock"`
+	Category    string   `json:"category"`
+	Impacts     []string `json:"impact_categories,omitempty"`
+}
+
+func (cs *ChatbotService) searchProducts(query, category string) (interface{}, []chatbot.Card, error) {
+	query = strings.TrimSpace(query)
+	category = strings.TrimSpace(category)
+
+	var found []products.Product
+	var err error
+	if category != "" {
+		found, _, err = cs.productRepo.GetByCategory(category, 1, query, "")
+	} else {
+		found, _, err = cs.productRepo.GetAllByPage(1, query, "")
+	}
+	if errors.Is(err, constant.ErrProductEmpty) {
+		return []productResult{}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := []productResult{}
+	var cards []chatbot.Card
+	for _, product := range found {
+		if len(results) == maxToolResults {
+			break
+		}
+
+		result := productResult{
+			ID:          product.ID,
+			Name:        product.Name,
+			Description: product.Description,
+			Price:       product.Price,
+			Coin:        product.Coin,
+			Stock:       product.Stock,
+			Category:    product.Category,
+		}
+		for _, impact := range product.ImpactCategories {
+			result.Impacts = append(result.Impacts, impact.ImpactCategory.Name)
+		}
+		results = append(results, result)
+
+		card := chatbot.Card{
+			Type:        chatbot.CardProduct,
+			ID:          product.ID,
+			Title:       product.Name,
+			Description: product.Description,
+			Price:       product.Price,
+			Coin:        product.Coin,
+		}
+		if len(product.Images) > 0 {
+			card.ImageURL = product.Images[0].AlbumsURL
+		}
+		cards = append(cards, card)
+	}
+	return results, cards, nil
+}
+
+type challengeResult struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	Description  string `json:"description,omitempty"`
+	Difficulty   string `json:"difficulty"`
+	DurationDays int    `json:"duration_days,omitempty"`
+	Exp          int    `json:"exp,omitempty"`
+	Coin         int    `json:"coin,omitempty"`
+	Participants int    `json:"participants,omitempty"`
+	Status       string `json:"status,omitempty"`
+}
+
+func (cs *ChatbotService) searchChallenges(userID, query string) (interface{}, []chatbot.Card, error) {
+	available, err := cs.challengeRepo.GetAvailableChallenges(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	results := []challengeResult{}
+	var cards []chatbot.Card
+	for _, challenge := range available {
+		if len(results) == maxToolResults {
+			break
+		}
+		if query != "" &&
+			!strings.Contains(strings.ToLower(challenge.Title), query) &&
+			!strings.Contains(strings.ToLower(challenge.Description), query) {
+			continue
+		}
+
+		results = append(results, challengeResult{
+			ID:           challenge.ID,
+			Title:        challenge.Title,
+			Description:  challenge.Description,
+			Difficulty:   challenge.Difficulty,
+			DurationDays: challenge.DurationDays,
+			Exp:          challenge.Exp,
+			Coin:         challenge.Coin,
+			Participants: challenge.ParticipantCount,
+		})
+		cards = append(cards, chatbot.Card{
+			Type:        chatbot.CardChallenge,
+			ID:          challenge.ID,
+			Title:       challenge.Title,
+			Description: challenge.Description,
+			ImageURL:    challenge.ChallengeImg,
+			Coin:        challenge.Coin,
+			Exp:         challenge.Exp,
+			Difficulty:  challenge.Difficulty,
+		})
+	}
+	return results, cards, nil
+}
+
+func (cs *ChatbotService) getChallenge(challengeID string) (interface{}, []chatbot.Card, error) {
+	challenge, err := cs.challengeRepo.GetChallengeByID(challengeID)
+	// Drafts are not public yet.
+	if err == nil && challenge.Status == "Draft" {
+		err = constant.ErrChallengeNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := challengeResult{
+		ID:           challenge.ID,
+		Title:        challenge.Title,
+		Description:  challenge.Description,
+		Difficulty:   challenge.Difficulty,
+		DurationDays: challenge.DurationDays,
+		Exp:          challenge.Exp,
+		Coin:         challenge.Coin,
+		Participants: challenge.ParticipantCount,
+		Status:       challenge.Status,
+	}
+	card := chatbot.Card{
+		Type:        chatbot.CardChallenge,
+		ID:          challenge.ID,
+		Title:       challenge.Title,
+		Description: challenge.Description,
+		ImageURL:    challenge.ChallengeImg,
+		Coin:        challenge.Coin,
+		Exp:         challenge.Exp,
+		Difficulty:  challenge.Difficulty,
+	}
+	return result, []chatbot.Card{card}, nil
+}
+
+func (cs *ChatbotService) myChallenges(userID string) (interface{}, []chatbot.Card, error) {
+	history, err := cs.challengeRepo.GetChallengeHistory(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := []challengeResult{}
+	var cards []chatbot.Card
+	for _, challengeLog := range history {
+		if challengeLog.Status != "Progress" {
+			continue
+		}
+		if len(results) == maxToolResults {
+			break
+		}
+
+		challenge, err := cs.challengeRepo.GetChallengeByID(challengeLog.ChallengeID)
+		if err != nil {
+			return nil, nil, err
+		}
+		results = append(results, challengeResult{
+			ID:           challenge.ID,
+			Title:        challenge.Title,
+			Description:  challenge.Description,
+			Difficulty:   challenge.Difficulty,
+			DurationDays: challenge.DurationDays,
+			Exp:          challenge.Exp,
+			Coin:         challenge.Coin,
+			Status:       challengeLog.Status,
+		})
+		cards = append(cards, chatbot.Card{
+			Type:        chatbot.CardChallenge,
+			ID:          challenge.ID,
+			Title:       challenge.Title,
+			Description: challenge.Description,
+			ImageURL:    challenge.ChallengeImg,
+			Coin:        challenge.Coin,
+			Exp:         challenge.Exp,
+			Difficulty:  challenge.Difficulty,
+			Status:      challengeLog.Status,
+		})
+	}
+	return results, cards, nil
+}
+
+type impactResult struct {
+	TotalPoints int                    `json:"total_points"`
+	CO2Kg       float64                `json:"co2_kg"`
+	PlasticKg   float64                `json:"plastic_kg"`
+	WaterLiters float64                `json:"water_liters"`
+	Categories  []impactCategoryResult `json:"categories"`
+}
+
+type impactCategoryResult struct {
+	Name   string `json:"name"`
+	Points int    `json:"points"`
+}
+
+func (cs *ChatbotService) myImpact(userID string) (interface{}, error) {
+	summary, err := cs.impactService.GetUserImpact(userID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result := impactResult{
+		TotalPoints: summary.TotalPoints,
+		CO2Kg:       summary.Metrics.CO2Kg,
+		PlasticKg:   summary.Metrics.PlasticKg,
+		WaterLiters: summary.Metrics.WaterLiters,
+		Categories:  []impactCategoryResult{},
+	}
+	for _, category := range summary.Categories {
+		result.Categories = append(result.Categories, impactCategoryResult{
+			Name:   category.Name,
+			Points: category.Points,
+		})
+	}
+	return result, nil
+}
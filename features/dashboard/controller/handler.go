@@ -5,6 +5,7 @@ import (
 	"greenenvironment/features/dashboard"
 	"greenenvironment/helper"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -60,3 +61,113 @@ func (dc *DashboardHandler) GetDashboard(c echo.Context) error {
 	response := DashboardResponse{}.FromEntity(data)
 	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Dashboard data retrieved successfully", response))
 }
+
+// GetImpactReport retrieves platform-wide impact for a date range
+// @Summary      Get impact report for admin
+// @Description  Retrieve aggregate impact points per category, monthly trend, top challenges and products, and participant counts
+// @Tags         Dashboard
+// @Accept       json
+// @Produce      json
+// @Param        Authorization header string true "Bearer Token"
+// @Param        from query string false "Start date (YYYY-MM-DD), defaults to one year before to"
+// @Param        to query string false "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success      200 {object} helper.Response{data=ImpactReportResponse} "Impact report retrieved successfully"
+// @Failure      400 {object} helper.Response{data=string} "Invalid report range"
+// @Failure      401 {object} helper.Response{data=string} "Unauthorized"
+// @Failure      500 {object} helper.Response{data=string} "Internal server error"
+// @Router       /admin/dashboard/impact [get]
+func (dc *DashboardHandler) GetImpactReport(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	token, err := dc.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	adminData := dc.jwtService.ExtractAdminToken(token)
+	role := adminData[constant.JWT_ROLE].(string)
+	if role != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	from, to, err := parseReportRange(c)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	report, err := dc.dashboardService.GetImpactReport(from, to)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := ImpactReportResponse{}.FromEntity(report)
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "Impact report retrieved successfully", response))
+}
+
+// ExportImpactReport downloads the impact report as a file
+// @Summary      Export impact report for admin
+// @Description  Download the impact report for a date range as CSV or PDF
+// @Tags         Dashboard
+// @Produce      text/csv
+// @Produce      application/pdf
+// @Param        Authorization header string true "Bearer Token"
+// @Param        format query string true "Export format (csv, pdf)"
+// @Param        from query string false "Start date (YYYY-MM-DD), defaults to one year before to"
+// @Param        to query string false "End date inclusive (YYYY-MM-DD), defaults to today"
+// @Success      200 {file} file "Impact report file"
+// @Failure      400 {object} helper.Response{data=string} "Invalid report range or format"
+// @Failure      401 {object} helper.Response{data=string} "Unauthorized"
+// @Failure      500 {object} helper.Response{data=string} "Internal server error"
+// @Router       /admin/dashboard/impact/export [get]
+func (dc *DashboardHandler) ExportImpactReport(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	token, err := dc.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	adminData := dc.jwtService.ExtractAdminToken(token)
+	role := adminData[constant.JWT_ROLE].(string)
+	if role != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	from, to, err := parseReportRange(c)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	format := c.QueryParam("format")
+	data, contentType, err := dc.dashboardService.ExportImpactReport(from, to, format)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	filename := "impact-report-" + from.Format("20060102") + "-" + to.AddDate(0, 0, -1).Format("20060102") + "." + format
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	return c.Blob(http.StatusOK, contentType, data)
+}
+
+// parseReportRange reads the from and to dates, treating to as inclusive by
+// returning the start of the following day.
+func parseReportRange(c echo.Context) (time.Time, time.Time, error) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if toParam := c.QueryParam("to"); toParam != "" {
+		parsed, err := time.Parse("2006-01-02", toParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, constant.ErrInvalidReportRange
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	from := to.AddDate(-1, 0, 0)
+	if fromParam := c.QueryParam("from"); fromParam != "" {
+		parsed, err := time.Parse("2006-01-02", fromParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, constant.ErrInvalidReportRange
+		}
+		from = parsed
+	}
+
+	return from, to, nil
+}
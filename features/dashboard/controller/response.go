@@ -77,3 +77,83 @@ func (d DashboardResponse) FromEntity(entity dashboard.DashboardData) DashboardR
 		LastTransactions: lastTransactions,
 	}
 }
+
+type ImpactReportResponse struct {
+	From                  string                         `json:"from"`
+	To                    string                         `json:"to"`
+	TotalPoints           int                            `json:"total_points"`
+	ChallengeParticipants int64                          `json:"challenge_participants"`
+	PurchaseParticipants  int64                          `json:"purchase_participants"`
+	TotalParticipants     int64                          `json:"total_participants"`
+	Categories            []ImpactCategoryPointsResponse `json:"categories"`
+	Trend                 []ImpactTrendPointResponse     `json:"trend"`
+	TopChallenges         []ImpactContributorResponse    `json:"top_challenges"`
+	TopProducts           []ImpactContributorResponse    `json:"top_products"`
+}
+
+type ImpactCategoryPointsResponse struct {
+	ImpactCategoryID string `json:"impact_category_id"`
+	Name             string `json:"name"`
+	Points           int    `json:"points"`
+}
+
+type ImpactTrendPointResponse struct {
+	Month            string `json:"month"`
+	ImpactCategoryID string `json:"impact_category_id"`
+	Name             string `json:"name"`
+	Points           int    `json:"points"`
+}
+
+type ImpactContributorResponse struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Points       int    `json:"points"`
+	Participants int64  `json:"participants"`
+}
+
+func (r ImpactReportResponse) FromEntity(entity dashboard.ImpactReport) ImpactReportResponse {
+	categories := make([]ImpactCategoryPointsResponse, len(entity.Categories))
+	for i, category := range entity.Categories {
+		categories[i] = ImpactCategoryPointsResponse{
+			ImpactCategoryID: category.ImpactCategoryID,
+			Name:             category.Name,
+			Points:           category.Points,
+		}
+	}
+
+	trend := make([]ImpactTrendPointResponse, len(entity.Trend))
+	for i, point := range entity.Trend {
+		trend[i] = ImpactTrendPointResponse{
+			Month:            point.Month,
+			ImpactCategoryID: point.ImpactCategoryID,
+			Name:             point.Name,
+			Points:           point.Points,
+		}
+	}
+
+	return ImpactReportResponse{
+		From:                  entity.From.Format("2006-01-02"),
+		To:                    entity.To.AddDate(0, 0, -1).Format("2006-01-02"),
+		TotalPoints:           entity.TotalPoints,
+		ChallengeParticipants: entity.ChallengeParticipants,
+		PurchaseParticipants:  entity.PurchaseParticipants,
+		TotalParticipants:     entity.TotalParticipants,
+		Categories:            categories,
+		Trend:                 trend,
+		TopChallenges:         toImpactContributorResponses(entity.TopChallenges),
+		TopProducts:           toImpactContributorResponses(entity.TopProducts),
+	}
+}
+
+func toImpactContributorResponses(contributors []dashboard.ImpactContributor) []ImpactContributorResponse {
+	responses := make([]ImpactContributorResponse, len(contributors))
+	for i, contributor := range contributors {
+		responses[i] = ImpactContributorResponse{
+			ID:           contributor.ID,
+			Name:         contributor.Name,
+			Points:       contributor.Points,
+			Participants: contributor.Participants,
+		}
+	}
+	return responses
+}
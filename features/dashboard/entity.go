@@ -38,16 +38,66 @@ type LastTransaction struct {
 	Status          string    `json:"status"`
 }
 
+type ImpactReport struct {
+	From                  time.Time
+	To                    time.Time
+	TotalPoints           int
+	Categories            []ImpactCategoryPoints
+	Trend                 []ImpactTrendPoint
+	TopChallenges         []ImpactContributor
+	TopProducts           []ImpactContributor
+	ChallengeParticipants int64
+	PurchaseParticipants  int64
+	TotalParticipants     int64
+}
+
+type ImpactCategoryPoints struct {
+	ImpactCategoryID string
+	Name             string
+	Points           int
+}
+
+type ImpactTrendPoint struct {
+	Month            string
+	ImpactCategoryID string
+	Name             string
+	Points           int
+}
+
+type ImpactContributor struct {
+	ID           string
+	Name         string
+	Points       int
+	Participants int64
+}
+
+type ImpactParticipants struct {
+	Challenge int64
+	Purchase  int64
+	Total     int64
+}
+
 type DashboardRepositoryInterface interface {
 	GetDashboardData(filter string) (DashboardData, error)
 	GetTopCategories(filter string) ([]TopCategory, error)
 	GetLastTransactions() ([]LastTransaction, error)
+
+	// Impact
+	GetImpactByCategory(from, to time.Time) ([]ImpactCategoryPoints, error)
+	GetImpactTrend(from, to time.Time) ([]ImpactTrendPoint, error)
+	GetTopImpactChallenges(from, to time.Time, limit int) ([]ImpactContributor, error)
+	GetTopImpactProducts(from, to time.Time, limit int) ([]ImpactContributor, error)
+	CountImpactParticipants(from, to time.Time) (ImpactParticipants, error)
 }
 
 type DashboardServiceInterface interface {
 	GetDashboardData(filter string) (DashboardData, error)
+	GetImpactReport(from, to time.Time) (ImpactReport, error)
+	ExportImpactReport(from, to time.Time, format string) ([]byte, string, error)
 }
 
 type DashboardControllerInterface interface {
 	GetDashboard(c echo.Context) error
+	GetImpactReport(c echo.Context) error
+	ExportImpactReport(c echo.Context) error
 }
@@ -166,3 +166,102 @@ func (dd *DashboardData) GetLastTransactions() ([]dashboard.LastTransaction, err
 
 	return lastTransactions, nil
 }
+
+func (dd *DashboardData) GetImpactByCategory(from, to time.Time) ([]dashboard.ImpactCategoryPoints, error) {
+	var categories []dashboard.ImpactCategoryPoints
+
+	err := dd.DB.Table("impact_ledgers").
+		Select("impact_ledgers.impact_category_id, impact_categories.name, SUM(impact_ledgers.points) AS points").
+		Joins("JOIN impact_categories ON impact_categories.id = impact_ledgers.impact_category_id").
+		Where("impact_ledgers.deleted_at IS NULL").
+		Where("impact_ledgers.occurred_at >= ? AND impact_ledgers.occurred_at < ?", from, to).
+		Group("impact_ledgers.impact_category_id, impact_categories.name").
+		Order("points DESC").
+		Scan(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (dd *DashboardData) GetImpactTrend(from, to time.Time) ([]dashboard.ImpactTrendPoint, error) {
+	var trend []dashboard.ImpactTrendPoint
+
+	err := dd.DB.Table("impact_ledgers").
+		Select("DATE_FORMAT(impact_ledgers.occurred_at, '%Y-%m') AS month, impact_ledgers.impact_category_id, impact_categories.name, SUM(impact_ledgers.points) AS points").
+		Joins("JOIN impact_categories ON impact_categories.id = impact_ledgers.impact_category_id").
+		Where("impact_ledgers.deleted_at IS NULL").
+		Where("impact_ledgers.occurred_at >= ? AND impact_ledgers.occurred_at < ?", from, to).
+		Group("month, impact_ledgers.impact_category_id, impact_categories.name").
+		Order("month ASC").
+		Scan(&trend).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return trend, nil
+}
+
+func (dd *DashboardData) GetTopImpactChallenges(from, to time.Time, limit int) ([]dashboard.ImpactContributor, error) {
+	var contributors []dashboard.ImpactContributor
+
+	err := dd.DB.Table("impact_ledgers").
+		Select("challenges.id, challenges.title AS name, SUM(impact_ledgers.points) AS points, COUNT(DISTINCT impact_ledgers.user_id) AS participants").
+		Joins("JOIN challenge_logs ON challenge_logs.id = impact_ledgers.source_id").
+		Joins("JOIN challenges ON challenges.id = challenge_logs.challenge_id").
+		Where("impact_ledgers.source_type = ?", "challenge").
+		Where("impact_ledgers.deleted_at IS NULL").
+		Where("impact_ledgers.occurred_at >= ? AND impact_ledgers.occurred_at < ?", from, to).
+		Group("challenges.id, challenges.title").
+		Order("points DESC").
+		Limit(limit).
+		Scan(&contributors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return contributors, nil
+}
+
+func (dd *DashboardData) GetTopImpactProducts(from, to time.Time, limit int) ([]dashboard.ImpactContributor, error) {
+	var contributors []dashboard.ImpactContributor
+
+	// Purchase ledger rows are recorded per transaction, so the points are
+	// split back out to the products that earned them.
+	err := dd.DB.Table("impact_ledgers").
+		Select("products.id, products.name, SUM(transaction_items.quantity * impact_categories.impact_point) AS points, COUNT(DISTINCT impact_ledgers.user_id) AS participants").
+		Joins("JOIN transaction_items ON transaction_items.transaction_id = impact_ledgers.source_id AND transaction_items.deleted_at IS NULL").
+		Joins("JOIN product_impact_categories ON product_impact_categories.product_id = transaction_items.product_id AND product_impact_categories.impact_category_id = impact_ledgers.impact_category_id AND product_impact_categories.deleted_at IS NULL").
+		Joins("JOIN impact_categories ON impact_categories.id = impact_ledgers.impact_category_id").
+		Joins("JOIN products ON products.id = transaction_items.product_id").
+		Where("impact_ledgers.source_type = ?", "purchase").
+		Where("impact_ledgers.deleted_at IS NULL").
+		Where("impact_ledgers.occurred_at >= ? AND impact_ledgers.occurred_at < ?", from, to).
+		Group("products.id, products.name").
+		Order("points DESC").
+		Limit(limit).
+		Scan(&contributors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return contributors, nil
+}
+
+func (dd *DashboardData) CountImpactParticipants(from, to time.Time) (dashboard.ImpactParticipants, error) {
+	var participants dashboard.ImpactParticipants
+
+	err := dd.DB.Table("impact_ledgers").
+		Select(`COUNT(DISTINCT CASE WHEN source_type = 'challenge' THEN user_id END) AS challenge,
+				COUNT(DISTINCT CASE WHEN source_type = 'purchase' THEN user_id END) AS purchase,
+				COUNT(DISTINCT user_id) AS total`).
+		Where("deleted_at IS NULL").
+		Where("occurred_at >= ? AND occurred_at < ?", from, to).
+		Scan(&participants).Error
+	if err != nil {
+		return participants, err
+	}
+
+	return participants, nil
+}
@@ -1,6 +1,20 @@
 package service
 
-import "greenenvironment/features/dashboard"
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"greenenvironment/constant"
+	"greenenvironment/features/dashboard"
+	"greenenvironment/helper"
+	"strconv"
+	"time"
+)
+
+const (
+	impactReportTopLimit = 10
+	maxImpactReportRange = 3 * 366 * 24 * time.Hour
+)
 
 type DashboardService struct {
 	repo dashboard.DashboardRepositoryInterface
@@ -31,3 +45,138 @@ func (ds *DashboardService) GetDashboardData(filter string) (dashboard.Dashboard
 	return data, nil
 }
 
+func (ds *DashboardService) GetImpactReport(from, to time.Time) (dashboard.ImpactReport, error) {
+	if !from.Before(to) || to.Sub(from) > maxImpactReportRange {
+		return dashboard.ImpactReport{}, constant.ErrInvalidReportRange
+	}
+
+	report := dashboard.ImpactReport{From: from, To: to}
+
+	categories, err := ds.repo.GetImpactByCategory(from, to)
+	if err != nil {
+		return dashboard.ImpactReport{}, constant.ErrGetImpactReport
+	}
+	report.Categories = categories
+	for _, category := range categories {
+		report.TotalPoints += category.Points
+	}
+
+	report.Trend, err = ds.repo.GetImpactTrend(from, to)
+	if err != nil {
+		return dashboard.ImpactReport{}, constant.ErrGetImpactReport
+	}
+
+	report.TopChallenges, err = ds.repo.GetTopImpactChallenges(from, to, impactReportTopLimit)
+	if err != nil {
+		return dashboard.ImpactReport{}, constant.ErrGetImpactReport
+	}
+
+	report.TopProducts, err = ds.repo.GetTopImpactProducts(from, to, impactReportTopLimit)
+	if err != nil {
+		return dashboard.ImpactReport{}, constant.ErrGetImpactReport
+	}
+
+	participants, err := ds.repo.CountImpactParticipants(from, to)
+	if err != nil {
+		return dashboard.ImpactReport{}, constant.ErrGetImpactReport
+	}
+	report.ChallengeParticipants = participants.Challenge
+	report.PurchaseParticipants = participants.Purchase
+	report.TotalParticipants = participants.Total
+
+	return report, nil
+}
+
+func (ds *DashboardService) ExportImpactReport(from, to time.Time, format string) ([]byte, string, error) {
+	if format != "csv" && format != "pdf" {
+		return nil, "", constant.ErrInvalidExportFormat
+	}
+
+	report, err := ds.GetImpactReport(from, to)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if format == "pdf" {
+		return renderImpactReportPDF(report), "application/pdf", nil
+	}
+
+	data, err := renderImpactReportCSV(report)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, "text/csv", nil
+}
+
+// reportLastDay is the last day a report covers. To is exclusive, so a
+// report of December runs up to January 1st.
+func reportLastDay(report dashboard.ImpactReport) string {
+	return report.To.AddDate(0, 0, -1).Format("2006-01-02")
+}
+
+// renderImpactReportCSV writes every section of the report into one sheet,
+// each introduced by a section column so it can be filtered in a spreadsheet.
+func renderImpactReportCSV(report dashboard.ImpactReport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	rows := [][]string{
+		{"section", "key", "name", "points", "participants"},
+		{"period", report.From.Format("2006-01-02"), reportLastDay(report), strconv.Itoa(report.TotalPoints), strconv.FormatInt(report.TotalParticipants, 10)},
+		{"participants", "challenge", "", "", strconv.FormatInt(report.ChallengeParticipants, 10)},
+		{"participants", "purchase", "", "", strconv.FormatInt(report.PurchaseParticipants, 10)},
+	}
+	for _, category := range report.Categories {
+		rows = append(rows, []string{"category", category.ImpactCategoryID, category.Name, strconv.Itoa(category.Points), ""})
+	}
+	for _, point := range report.Trend {
+		rows = append(rows, []string{"trend", point.Month, point.Name, strconv.Itoa(point.Points), ""})
+	}
+	for _, challenge := range report.TopChallenges {
+		rows = append(rows, []string{"challenge", challenge.ID, challenge.Name, strconv.Itoa(challenge.Points), strconv.FormatInt(challenge.Participants, 10)})
+	}
+	for _, product := range report.TopProducts {
+		rows = append(rows, []string{"product", product.ID, product.Name, strconv.Itoa(product.Points), strconv.FormatInt(product.Participants, 10)})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func renderImpactReportPDF(report dashboard.ImpactReport) []byte {
+	doc := helper.NewPDFDocument()
+
+	doc.Heading("Green Environment Impact Report", 18)
+	doc.Text(fmt.Sprintf("Period: %s to %s", report.From.Format("2006-01-02"), reportLastDay(report)), 11)
+	doc.Text(fmt.Sprintf("Total impact points: %d", report.TotalPoints), 11)
+	doc.Text(fmt.Sprintf("Participants: %d (%d through challenges, %d through purchases)",
+		report.TotalParticipants, report.ChallengeParticipants, report.PurchaseParticipants), 11)
+
+	doc.Space(10)
+	doc.Heading("Impact by category", 14)
+	for _, category := range report.Categories {
+		doc.Text(fmt.Sprintf("%s: %d points", category.Name, category.Points), 11)
+	}
+
+	doc.Space(10)
+	doc.Heading("Monthly trend", 14)
+	for _, point := range report.Trend {
+		doc.Text(fmt.Sprintf("%s  %s: %d points", point.Month, point.Name, point.Points), 11)
+	}
+
+	doc.Space(10)
+	doc.Heading("Top challenges", 14)
+	for i, challenge := range report.TopChallenges {
+		doc.Text(fmt.Sprintf("%d. %s: %d points from %d participants", i+1, challenge.Name, challenge.Points, challenge.Participants), 11)
+	}
+
+	doc.Space(10)
+	doc.Heading("Top products", 14)
+	for i, product := range report.TopProducts {
+		doc.Text(fmt.Sprintf("%d. %s: %d points from %d buyers", i+1, product.Name, product.Points, product.Participants), 11)
+	}
+
+	return doc.Bytes()
+}
@@ -1,8 +1,11 @@
 package service
 
 import (
+	"bytes"
 	"errors"
+	"greenenvironment/constant"
 	"greenenvironment/features/dashboard"
+	"strings"
 	"testing"
 	"time"
 
@@ -32,6 +35,36 @@ func (m *MockDashboardRepository) GetLastTransactions() ([]dashboard.LastTransac
 	return args.Get(0).([]dashboard.LastTransaction), args.Error(1)
 }
 
+func (m *MockDashboardRepository) GetImpactByCategory(from, to time.Time) ([]dashboard.ImpactCategoryPoints, error) {
+	args := m.Called(from, to)
+
+	return args.Get(0).([]dashboard.ImpactCategoryPoints), args.Error(1)
+}
+
+func (m *MockDashboardRepository) GetImpactTrend(from, to time.Time) ([]dashboard.ImpactTrendPoint, error) {
+	args := m.Called(from, to)
+
+	return args.Get(0).([]dashboard.ImpactTrendPoint), args.Error(1)
+}
+
+func (m *MockDashboardRepository) GetTopImpactChallenges(from, to time.Time, limit int) ([]dashboard.ImpactContributor, error) {
+	args := m.Called(from, to, limit)
+
+	return args.Get(0).([]dashboard.ImpactContributor), args.Error(1)
+}
+
+func (m *MockDashboardRepository) GetTopImpactProducts(from, to time.Time, limit int) ([]dashboard.ImpactContributor, error) {
+	args := m.Called(from, to, limit)
+
+	return args.Get(0).([]dashboard.ImpactContributor), args.Error(1)
+}
+
+func (m *MockDashboardRepository) CountImpactParticipants(from, to time.Time) (dashboard.ImpactParticipants, error) {
+	args := m.Called(from, to)
+
+	return args.Get(0).(dashboard.ImpactParticipants), args.Error(1)
+}
+
 func TestGetDashboardData(t *testing.T) {
 	mockRepo := new(MockDashboardRepository)
 	service := NewDashboardService(mockRepo)
@@ -180,3 +213,119 @@ func TestGetDashboardData_Empty(t *testing.T) {
 	}, result)
 	mockRepo.AssertExpectations(t)
 }
+
+func mockImpactReport(mockRepo *MockDashboardRepository, from, to time.Time) {
+	mockRepo.On("GetImpactByCategory", from, to).Return([]dashboard.ImpactCategoryPoints{
+		{ImpactCategoryID: "plastic", Name: "Plastic (Reduction)", Points: 120},
+		{ImpactCategoryID: "water", Name: "Water", Points: 30},
+	}, nil)
+	mockRepo.On("GetImpactTrend", from, to).Return([]dashboard.ImpactTrendPoint{
+		{Month: "2024-11", ImpactCategoryID: "plastic", Name: "Plastic (Reduction)", Points: 120},
+		{Month: "2024-12", ImpactCategoryID: "water", Name: "Water", Points: 30},
+	}, nil)
+	mockRepo.On("GetTopImpactChallenges", from, to, 10).Return([]dashboard.ImpactContributor{
+		{ID: "c1", Name: "No Straw Week", Points: 90, Participants: 9},
+	}, nil)
+	mockRepo.On("GetTopImpactProducts", from, to, 10).Return([]dashboard.ImpactContributor{
+		{ID: "p1", Name: "Bamboo, Straw", Points: 60, Participants: 4},
+	}, nil)
+	mockRepo.On("CountImpactParticipants", from, to).Return(dashboard.ImpactParticipants{Challenge: 9, Purchase: 4, Total: 11}, nil)
+}
+
+func TestGetImpactReport(t *testing.T) {
+	mockRepo := new(MockDashboardRepository)
+	service := NewDashboardService(mockRepo)
+
+	from := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	mockImpactReport(mockRepo, from, to)
+
+	report, err := service.GetImpactReport(from, to)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 150, report.TotalPoints)
+	assert.Equal(t, int64(11), report.TotalParticipants)
+	assert.Equal(t, int64(9), report.ChallengeParticipants)
+	assert.Equal(t, int64(4), report.PurchaseParticipants)
+	assert.Len(t, report.Trend, 2)
+	assert.Equal(t, "No Straw Week", report.TopChallenges[0].Name)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetImpactReport_InvalidRange(t *testing.T) {
+	mockRepo := new(MockDashboardRepository)
+	service := NewDashboardService(mockRepo)
+
+	from := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := service.GetImpactReport(from, from)
+	assert.Equal(t, constant.ErrInvalidReportRange, err)
+
+	_, err = service.GetImpactReport(from, from.AddDate(4, 0, 0))
+	assert.Equal(t, constant.ErrInvalidReportRange, err)
+
+	mockRepo.AssertNotCalled(t, "GetImpactByCategory", from, from)
+}
+
+func TestGetImpactReport_Error(t *testing.T) {
+	mockRepo := new(MockDashboardRepository)
+	service := NewDashboardService(mockRepo)
+
+	from := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("GetImpactByCategory", from, to).Return([]dashboard.ImpactCategoryPoints{}, errors.New("db error"))
+
+	_, err := service.GetImpactReport(from, to)
+
+	assert.Equal(t, constant.ErrGetImpactReport, err)
+}
+
+func TestExportImpactReport_CSV(t *testing.T) {
+	mockRepo := new(MockDashboardRepository)
+	service := NewDashboardService(mockRepo)
+
+	from := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	mockImpactReport(mockRepo, from, to)
+
+	data, contentType, err := service.ExportImpactReport(from, to, "csv")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "text/csv", contentType)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, "section,key,name,points,participants", lines[0])
+	assert.Equal(t, "period,2024-11-01,2024-12-31,150,11", lines[1])
+	assert.Contains(t, string(data), "category,plastic,Plastic (Reduction),120,")
+	assert.Contains(t, string(data), "product,p1,\"Bamboo, Straw\",60,4")
+}
+
+func TestExportImpactReport_PDF(t *testing.T) {
+	mockRepo := new(MockDashboardRepository)
+	service := NewDashboardService(mockRepo)
+
+	from := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	mockImpactReport(mockRepo, from, to)
+
+	data, contentType, err := service.ExportImpactReport(from, to, "pdf")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "application/pdf", contentType)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+	assert.Contains(t, string(data), "Plastic \\(Reduction\\): 120 points")
+	assert.Contains(t, string(data), "Period: 2024-11-01 to 2024-12-31")
+}
+
+func TestExportImpactReport_InvalidFormat(t *testing.T) {
+	mockRepo := new(MockDashboardRepository)
+	service := NewDashboardService(mockRepo)
+
+	from := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	_, _, err := service.ExportImpactReport(from, to, "xlsx")
+
+	assert.Equal(t, constant.ErrInvalidExportFormat, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,76 @@
+package emails
+
+import (
+	"greenenvironment/helper"
+	"time"
+)
+
+const (
+	StatusPending = "Pending"
+	StatusSending = "Sending"
+	StatusSent    = "Sent"
+	StatusFailed  = "Failed"
+)
+
+// MaxAttempts is how many times an email is tried before it is marked Failed.
+const MaxAttempts = 6
+
+// RetryBaseDelay is the wait after the first failed attempt. Every further
+// failure doubles it.
+const RetryBaseDelay = time.Minute
+
+// DispatchBatchSize is how many due emails one dispatch run sends.
+const DispatchBatchSize = 50
+
+// SendClaimTimeout is how long an email can stay in sending. Past it the run
+// that claimed it is assumed to have crashed and the email is sent again.
+const SendClaimTimeout = 10 * time.Minute
+
+// OutboxRetention is how long sent and failed emails are kept before they are
+// removed from the outbox.
+const OutboxRetention = 30 * 24 * time.Hour
+
+// Email is one message in the outbox. It is rendered when queued so retries
+// send exactly what was first produced. An email with an IdempotencyKey is
+// queued at most once per key.
+type Email struct {
+	ID             string
+	IdempotencyKey string
+	Recipient      string
+	Template       string
+	Language       string
+	Subject        string
+	Body           string
+	Status         string
+	Attempts       int
+	LastError      string
+	NextAttemptAt  time.Time
+	SentAt         *time.Time
+}
+
+// Recipient is the user an address belongs to. Both fields are empty when
+// nobody has registered the address yet.
+type Recipient struct {
+	Name     string
+	Language string
+}
+
+type EmailRepositoryInterface interface {
+	Enqueue(email Email) error
+	GetDue(now time.Time, limit int) ([]Email, error)
+	Claim(emailID string, claimedAt time.Time) (bool, error)
+	MarkSent(emailID string, sentAt time.Time) error
+	MarkRetry(emailID string, attempts int, lastError string, nextAttemptAt time.Time) error
+	MarkFailed(emailID string, attempts int, lastError string) error
+	DeleteFinished(before time.Time) error
+	GetRecipient(email string) (Recipient, error)
+}
+
+// EmailServiceInterface is the mailer every feature sends through. Emails are
+// queued in the outbox and delivered in the background.
+type EmailServiceInterface interface {
+	helper.MailerInterface
+	Start()
+	DispatchOutbox() error
+	PurgeOutbox() error
+}
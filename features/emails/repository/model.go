@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type EmailOutbox struct {
+	*gorm.Model
+	ID             string     `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	IdempotencyKey *string    `gorm:"type:varchar(150);uniqueIndex;column:idempotency_key"`
+	Recipient      string     `gorm:"type:varchar(255);not null;column:recipient"`
+	Template       string     `gorm:"type:varchar(50);not null;column:template"`
+	Language       string     `gorm:"type:varchar(5);not null;column:language"`
+	Subject        string     `gorm:"type:varchar(255);not null;column:subject"`
+	Body           string     `gorm:"type:mediumtext;not null;column:body"`
+	Status         string     `gorm:"type:enum('Pending','Sending','Sent','Failed');not null;default:'Pending';index:idx_email_outbox_due;column:status"`
+	Attempts       int        `gorm:"type:int;not null;default:0;column:attempts"`
+	LastError      string     `gorm:"type:text;column:last_error"`
+	NextAttemptAt  time.Time  `gorm:"type:datetime;not null;index:idx_email_outbox_due;column:next_attempt_at"`
+	ClaimedAt      *time.Time `gorm:"type:datetime;column:claimed_at"`
+	SentAt         *time.Time `gorm:"type:datetime;column:sent_at"`
+}
+
+func (EmailOutbox) TableName() string {
+	return "email_outbox"
+}
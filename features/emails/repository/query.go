@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"errors"
+	"greenenvironment/features/emails"
+	userRepo "greenenvironment/features/users/repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmailData struct {
+	DB *gorm.DB
+}
+
+func NewEmailRepository(db *gorm.DB) emails.EmailRepositoryInterface {
+	return &EmailData{
+		DB: db,
+	}
+}
+
+// Enqueue adds an email to the outbox. An email whose idempotency key is
+// already queued is dropped.
+func (ed *EmailData) Enqueue(email emails.Email) error {
+	var idempotencyKey *string
+	if email.IdempotencyKey != "" {
+		idempotencyKey = &email.IdempotencyKey
+	}
+	return ed.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&EmailOutbox{
+		ID:             email.ID,
+		IdempotencyKey: idempotencyKey,
+		Recipient:      email.Recipient,
+		Template:       email.Template,
+		Language:       email.Language,
+		Subject:        email.Subject,
+		Body:           email.Body,
+		Status:         email.Status,
+		NextAttemptAt:  email.NextAttemptAt,
+	}).Error
+}
+
+// GetDue returns the pending emails whose attempt is due, and the ones left
+// sending by a run that crashed.
+func (ed *EmailData) GetDue(now time.Time, limit int) ([]emails.Email, error) {
+	var outbox []EmailOutbox
+	err := ed.DB.Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND claimed_at <= ?)",
+		emails.StatusPending, now, emails.StatusSending, now.Add(-emails.SendClaimTimeout)).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&outbox).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var result []emails.Email
+	for _, email := range outbox {
+		result = append(result, emails.Email{
+			ID:            email.ID,
+			Recipient:     email.Recipient,
+			Template:      email.Template,
+			Language:      email.Language,
+			Subject:       email.Subject,
+			Body:          email.Body,
+			Status:        email.Status,
+			Attempts:      email.Attempts,
+			LastError:     email.LastError,
+			NextAttemptAt: email.NextAttemptAt,
+			SentAt:        email.SentAt,
+		})
+	}
+	return result, nil
+}
+
+// Claim marks a due email as sending. It reports false when another run
+// claimed it first.
+func (ed *EmailData) Claim(emailID string, claimedAt time.Time) (bool, error) {
+	result := ed.DB.Model(&EmailOutbox{}).
+		Where("id = ? AND (status = ? OR (status = ? AND claimed_at <= ?))",
+			emailID, emails.StatusPending, emails.StatusSending, claimedAt.Add(-emails.SendClaimTimeout)).
+		Updates(map[string]interface{}{
+			"status":     emails.StatusSending,
+			"claimed_at": claimedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// MarkSent also clears the body, which is not needed once it is delivered.
+func (ed *EmailData) MarkSent(emailID string, sentAt time.Time) error {
+	return ed.DB.Model(&EmailOutbox{}).Where("id = ?", emailID).Updates(map[string]interface{}{
+		"status":     emails.StatusSent,
+		"body":       "",
+		"sent_at":    sentAt,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": "",
+	}).Error
+}
+
+func (ed *EmailData) MarkRetry(emailID string, attempts int, lastError string, nextAttemptAt time.Time) error {
+	return ed.DB.Model(&EmailOutbox{}).Where("id = ?", emailID).Updates(map[string]interface{}{
+		"status":          emails.StatusPending,
+		"attempts":        attempts,
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+}
+
+func (ed *EmailData) MarkFailed(emailID string, attempts int, lastError string) error {
+	return ed.DB.Model(&EmailOutbox{}).Where("id = ?", emailID).Updates(map[string]interface{}{
+		"status":     emails.StatusFailed,
+		"attempts":   attempts,
+		"last_error": lastError,
+	}).Error
+}
+
+// DeleteFinished removes the sent and failed emails last updated before the
+// given time.
+func (ed *EmailData) DeleteFinished(before time.Time) error {
+	return ed.DB.Unscoped().
+		Where("status IN ? AND updated_at < ?", []string{emails.StatusSent, emails.StatusFailed}, before).
+		Delete(&EmailOutbox{}).Error
+}
+
+// GetRecipient returns the name and language of the user with this address,
+// or an empty Recipient when nobody has registered it yet.
+func (ed *EmailData) GetRecipient(email string) (emails.Recipient, error) {
+	var user userRepo.User
+	err := ed.DB.Select("name", "language").Where("email = ?", email).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return emails.Recipient{}, nil
+	}
+	if err != nil {
+		return emails.Recipient{}, err
+	}
+	return emails.Recipient{Name: user.Name, Language: user.Language}, nil
+}
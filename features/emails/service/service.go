@@ -0,0 +1,167 @@
+package service
+
+import (
+	"greenenvironment/features/emails"
+	"greenenvironment/utils/mail"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EmailService struct {
+	emailRepo emails.EmailRepositoryInterface
+	transport mail.Transport
+	// dispatching serialises outbox runs so the worker and the cron job never
+	// send the same email twice from this instance.
+	dispatching sync.Mutex
+	wake        chan struct{}
+	now         func() time.Time
+}
+
+func NewEmailService(er emails.EmailRepositoryInterface, transport mail.Transport) emails.EmailServiceInterface {
+	return &EmailService{
+		emailRepo: er,
+		transport: transport,
+		wake:      make(chan struct{}, 1),
+		now:       time.Now,
+	}
+}
+
+// Start runs the worker that sends newly queued emails right away. Failed
+// emails wait for their retry time and are picked up by DispatchOutbox.
+func (es *EmailService) Start() {
+	go func() {
+		for range es.wake {
+			if err := es.DispatchOutbox(); err != nil {
+				log.Printf("failed to dispatch email outbox: %v", err)
+			}
+		}
+	}()
+}
+
+func (es *EmailService) Send(to string, code string, subject string) error {
+	return es.SendTemplate(to, mail.TemplateOTP, map[string]interface{}{
+		"Code":    code,
+		"Subject": subject,
+	})
+}
+
+func (es *EmailService) SendNotification(to string, subject string, message string) error {
+	return es.SendTemplate(to, mail.TemplateNotification, map[string]interface{}{
+		"Subject": subject,
+		"Message": message,
+	})
+}
+
+// SendTemplate renders a template in the recipient's language and queues it.
+// Templates greet the user by Name, which defaults to the recipient's name.
+func (es *EmailService) SendTemplate(to string, template string, data map[string]interface{}) error {
+	return es.queue(to, template, "", data)
+}
+
+// SendTemplateOnce is SendTemplate for an email that must not be sent twice,
+// such as a receipt. Only the first email queued with the key is sent.
+func (es *EmailService) SendTemplateOnce(to string, template string, key string, data map[string]interface{}) error {
+	return es.queue(to, template, key, data)
+}
+
+func (es *EmailService) queue(to string, template string, key string, data map[string]interface{}) error {
+	recipient, err := es.emailRepo.GetRecipient(to)
+	if err != nil {
+		return err
+	}
+	language := recipient.Language
+	if !mail.IsSupportedLanguage(language) {
+		language = mail.DefaultLanguage
+	}
+
+	if _, ok := data["Name"]; !ok && recipient.Name != "" {
+		values := make(map[string]interface{}, len(data)+1)
+		for key, value := range data {
+			values[key] = value
+		}
+		values["Name"] = recipient.Name
+		data = values
+	}
+
+	subject, body, err := mail.Render(template, language, data)
+	if err != nil {
+		return err
+	}
+
+	err = es.emailRepo.Enqueue(emails.Email{
+		ID:             uuid.New().String(),
+		IdempotencyKey: key,
+		Recipient:      to,
+		Template:       template,
+		Language:       language,
+		Subject:        subject,
+		Body:           body,
+		Status:         emails.StatusPending,
+		NextAttemptAt:  es.now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case es.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// retryDelay is how long to wait after the given number of failed attempts.
+func retryDelay(attempts int) time.Duration {
+	return emails.RetryBaseDelay << (attempts - 1)
+}
+
+// DispatchOutbox sends the due emails. Each email is claimed first so two
+// instances never send the same one.
+func (es *EmailService) DispatchOutbox() error {
+	es.dispatching.Lock()
+	defer es.dispatching.Unlock()
+
+	due, err := es.emailRepo.GetDue(es.now(), emails.DispatchBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, email := range due {
+		claimed, err := es.emailRepo.Claim(email.ID, es.now())
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		deliverErr := es.transport.Deliver(email.Recipient, email.Subject, email.Body)
+		if deliverErr == nil {
+			if err := es.emailRepo.MarkSent(email.ID, es.now()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		attempts := email.Attempts + 1
+		if attempts >= emails.MaxAttempts {
+			log.Printf("giving up on email %s to %s after %d attempts: %v", email.ID, email.Recipient, attempts, deliverErr)
+			err = es.emailRepo.MarkFailed(email.ID, attempts, deliverErr.Error())
+		} else {
+			err = es.emailRepo.MarkRetry(email.ID, attempts, deliverErr.Error(), es.now().Add(retryDelay(attempts)))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PurgeOutbox removes the sent and failed emails past the retention period.
+func (es *EmailService) PurgeOutbox() error {
+	return es.emailRepo.DeleteFinished(es.now().Add(-emails.OutboxRetention))
+}
@@ -0,0 +1,420 @@
+package service
+
+import (
+	"errors"
+	"greenenvironment/features/emails"
+	"greenenvironment/utils/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEmailRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailRepository) Enqueue(email emails.Email) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockEmailRepository) GetDue(now time.Time, limit int) ([]emails.Email, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]emails.Email), args.Error(1)
+}
+
+func (m *MockEmailRepository) Claim(emailID string, claimedAt time.Time) (bool, error) {
+	args := m.Called(emailID, claimedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockEmailRepository) MarkSent(emailID string, sentAt time.Time) error {
+	args := m.Called(emailID, sentAt)
+	return args.Error(0)
+}
+
+func (m *MockEmailRepository) MarkRetry(emailID string, attempts int, lastError string, nextAttemptAt time.Time) error {
+	args := m.Called(emailID, attempts, lastError, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockEmailRepository) MarkFailed(emailID string, attempts int, lastError string) error {
+	args := m.Called(emailID, attempts, lastError)
+	return args.Error(0)
+}
+
+func (m *MockEmailRepository) DeleteFinished(before time.Time) error {
+	args := m.Called(before)
+	return args.Error(0)
+}
+
+func (m *MockEmailRepository) GetRecipient(email string) (emails.Recipient, error) {
+	args := m.Called(email)
+	return args.Get(0).(emails.Recipient), args.Error(1)
+}
+
+type MockTransport struct {
+	mock.Mock
+}
+
+func (m *MockTransport) Deliver(to string, subject string, htmlBody string) error {
+	args := m.Called(to, subject, htmlBody)
+	return args.Error(0)
+}
+
+// memoryOutbox keeps the outbox in memory so a test can follow an email from
+// queue to delivery.
+type memoryOutbox struct {
+	mu     sync.Mutex
+	emails map[string]*emails.Email
+}
+
+func newMemoryOutbox() *memoryOutbox {
+	return &memoryOutbox{emails: map[string]*emails.Email{}}
+}
+
+func (o *memoryOutbox) Enqueue(email emails.Email) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.emails[email.ID] = &email
+	return nil
+}
+
+func (o *memoryOutbox) GetDue(now time.Time, limit int) ([]emails.Email, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var due []emails.Email
+	for _, email := range o.emails {
+		if email.Status == emails.StatusPending && !email.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, *email)
+		}
+	}
+	return due, nil
+}
+
+func (o *memoryOutbox) Claim(emailID string, claimedAt time.Time) (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.emails[emailID].Status != emails.StatusPending {
+		return false, nil
+	}
+	o.emails[emailID].Status = emails.StatusSending
+	return true, nil
+}
+
+func (o *memoryOutbox) MarkSent(emailID string, sentAt time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.emails[emailID].Status = emails.StatusSent
+	o.emails[emailID].Body = ""
+	o.emails[emailID].Attempts++
+	o.emails[emailID].SentAt = &sentAt
+	return nil
+}
+
+func (o *memoryOutbox) MarkRetry(emailID string, attempts int, lastError string, nextAttemptAt time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.emails[emailID].Status = emails.StatusPending
+	o.emails[emailID].Attempts = attempts
+	o.emails[emailID].LastError = lastError
+	o.emails[emailID].NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (o *memoryOutbox) MarkFailed(emailID string, attempts int, lastError string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.emails[emailID].Status = emails.StatusFailed
+	o.emails[emailID].Attempts = attempts
+	o.emails[emailID].LastError = lastError
+	return nil
+}
+
+func (o *memoryOutbox) DeleteFinished(before time.Time) error {
+	return nil
+}
+
+func (o *memoryOutbox) GetRecipient(email string) (emails.Recipient, error) {
+	return emails.Recipient{Name: "Budi", Language: "id"}, nil
+}
+
+func (o *memoryOutbox) get(emailID string) emails.Email {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return *o.emails[emailID]
+}
+
+func newTestService(repo emails.EmailRepositoryInterface, transport mail.Transport, now time.Time) *EmailService {
+	service := NewEmailService(repo, transport).(*EmailService)
+	service.now = func() time.Time { return now }
+	return service
+}
+
+func TestSendTemplate_UsesRecipientLanguage(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	mockRepo := new(MockEmailRepository)
+	service := newTestService(mockRepo, nil, now)
+
+	mockRepo.On("GetRecipient", "budi@example.com").Return(emails.Recipient{Language: "id"}, nil)
+	mockRepo.On("Enqueue", mock.MatchedBy(func(email emails.Email) bool {
+		return email.Recipient == "budi@example.com" && email.Template == mail.TemplateWelcome &&
+			email.Language == "id" && email.Subject == "Selamat datang di Ecomate" &&
+			strings.Contains(email.Body, "Budi") && email.Status == emails.StatusPending &&
+			email.NextAttemptAt.Equal(now) && email.ID != ""
+	})).Return(nil)
+
+	err := service.SendTemplate("budi@example.com", mail.TemplateWelcome, map[string]interface{}{"Name": "Budi"})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendTemplateOnce_QueuesWithTheKey(t *testing.T) {
+	mockRepo := new(MockEmailRepository)
+	service := newTestService(mockRepo, nil, time.Now())
+
+	mockRepo.On("GetRecipient", "budi@example.com").Return(emails.Recipient{Name: "Budi"}, nil)
+	mockRepo.On("Enqueue", mock.MatchedBy(func(email emails.Email) bool {
+		return email.IdempotencyKey == "payment_received:order1" && email.Template == mail.TemplatePaymentReceived
+	})).Return(nil)
+
+	err := service.SendTemplateOnce("budi@example.com", mail.TemplatePaymentReceived, "payment_received:order1", map[string]interface{}{
+		"OrderID":       "order1",
+		"PaymentMethod": "bank_transfer",
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendTemplate_FallsBackToDefaultLanguage(t *testing.T) {
+	mockRepo := new(MockEmailRepository)
+	service := newTestService(mockRepo, nil, time.Now())
+
+	mockRepo.On("GetRecipient", "guest@example.com").Return(emails.Recipient{}, nil)
+	mockRepo.On("Enqueue", mock.MatchedBy(func(email emails.Email) bool {
+		return email.Language == mail.DefaultLanguage && email.Subject == "Ecomate Verification Code" &&
+			strings.Contains(email.Body, "123456")
+	})).Return(nil)
+
+	err := service.Send("guest@example.com", "123456", "Register Account")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendNotification_GreetsByName(t *testing.T) {
+	mockRepo := new(MockEmailRepository)
+	service := newTestService(mockRepo, nil, time.Now())
+
+	mockRepo.On("GetRecipient", "budi@example.com").Return(emails.Recipient{Name: "Budi", Language: "en"}, nil)
+	mockRepo.On("Enqueue", mock.MatchedBy(func(email emails.Email) bool {
+		return strings.Contains(email.Body, "Hello Budi,") && !strings.Contains(email.Body, "budi@example.com")
+	})).Return(nil)
+
+	err := service.SendNotification("budi@example.com", "Your order shipped", "It is on its way.")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendNotification_GreetsGuestsWithoutAName(t *testing.T) {
+	mockRepo := new(MockEmailRepository)
+	service := newTestService(mockRepo, nil, time.Now())
+
+	mockRepo.On("GetRecipient", "guest@example.com").Return(emails.Recipient{}, nil)
+	mockRepo.On("Enqueue", mock.MatchedBy(func(email emails.Email) bool {
+		return strings.Contains(email.Body, "Hello,")
+	})).Return(nil)
+
+	err := service.SendNotification("guest@example.com", "Hello", "World")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSendTemplate_UnknownTemplate(t *testing.T) {
+	mockRepo := new(MockEmailRepository)
+	service := newTestService(mockRepo, nil, time.Now())
+
+	mockRepo.On("GetRecipient", "user@example.com").Return(emails.Recipient{Language: "en"}, nil)
+
+	err := service.SendTemplate("user@example.com", "missing", nil)
+
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "Enqueue", mock.Anything)
+}
+
+func TestSendTemplate_EnqueueError(t *testing.T) {
+	mockRepo := new(MockEmailRepository)
+	service := newTestService(mockRepo, nil, time.Now())
+
+	mockRepo.On("GetRecipient", "user@example.com").Return(emails.Recipient{Language: "en"}, nil)
+	mockRepo.On("Enqueue", mock.Anything).Return(errors.New("database down"))
+
+	err := service.SendNotification("user@example.com", "Hello", "World")
+
+	assert.EqualError(t, err, "database down")
+}
+
+func TestDispatchOutbox_MarksSent(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	mockRepo := new(MockEmailRepository)
+	mockTransport := new(MockTransport)
+	service := newTestService(mockRepo, mockTransport, now)
+
+	mockRepo.On("GetDue", now, emails.DispatchBatchSize).Return([]emails.Email{
+		{ID: "email1", Recipient: "user@example.com", Subject: "Hi", Body: "<p>Hi</p>"},
+	}, nil)
+	mockRepo.On("Claim", "email1", now).Return(true, nil)
+	mockTransport.On("Deliver", "user@example.com", "Hi", "<p>Hi</p>").Return(nil)
+	mockRepo.On("MarkSent", "email1", now).Return(nil)
+
+	err := service.DispatchOutbox()
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockTransport.AssertExpectations(t)
+}
+
+func TestDispatchOutbox_SchedulesRetryWithBackoff(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	mockRepo := new(MockEmailRepository)
+	mockTransport := new(MockTransport)
+	service := newTestService(mockRepo, mockTransport, now)
+
+	mockRepo.On("GetDue", now, emails.DispatchBatchSize).Return([]emails.Email{
+		{ID: "email1", Recipient: "user@example.com", Attempts: 2},
+	}, nil)
+	mockRepo.On("Claim", "email1", now).Return(true, nil)
+	mockTransport.On("Deliver", "user@example.com", "", "").Return(errors.New("connection refused"))
+	mockRepo.On("MarkRetry", "email1", 3, "connection refused", now.Add(4*time.Minute)).Return(nil)
+
+	err := service.DispatchOutbox()
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDispatchOutbox_GivesUpAfterMaxAttempts(t *testing.T) {
+	now := time.Now()
+	mockRepo := new(MockEmailRepository)
+	mockTransport := new(MockTransport)
+	service := newTestService(mockRepo, mockTransport, now)
+
+	mockRepo.On("GetDue", now, emails.DispatchBatchSize).Return([]emails.Email{
+		{ID: "email1", Recipient: "user@example.com", Attempts: emails.MaxAttempts - 1},
+	}, nil)
+	mockRepo.On("Claim", "email1", now).Return(true, nil)
+	mockTransport.On("Deliver", "user@example.com", "", "").Return(errors.New("mailbox unavailable"))
+	mockRepo.On("MarkFailed", "email1", emails.MaxAttempts, "mailbox unavailable").Return(nil)
+
+	err := service.DispatchOutbox()
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "MarkRetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDispatchOutbox_SkipsEmailsClaimedElsewhere(t *testing.T) {
+	now := time.Now()
+	mockRepo := new(MockEmailRepository)
+	mockTransport := new(MockTransport)
+	service := newTestService(mockRepo, mockTransport, now)
+
+	mockRepo.On("GetDue", now, emails.DispatchBatchSize).Return([]emails.Email{
+		{ID: "email1", Recipient: "user@example.com"},
+	}, nil)
+	mockRepo.On("Claim", "email1", now).Return(false, nil)
+
+	err := service.DispatchOutbox()
+
+	assert.NoError(t, err)
+	mockTransport.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "MarkSent", mock.Anything, mock.Anything)
+}
+
+func TestPurgeOutbox(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	mockRepo := new(MockEmailRepository)
+	service := newTestService(mockRepo, nil, now)
+
+	mockRepo.On("DeleteFinished", now.Add(-emails.OutboxRetention)).Return(nil)
+
+	err := service.PurgeOutbox()
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDispatchOutbox_GetDueError(t *testing.T) {
+	now := time.Now()
+	mockRepo := new(MockEmailRepository)
+	service := newTestService(mockRepo, nil, now)
+
+	mockRepo.On("GetDue", now, emails.DispatchBatchSize).Return([]emails.Email{}, errors.New("database down"))
+
+	err := service.DispatchOutbox()
+
+	assert.EqualError(t, err, "database down")
+}
+
+func TestEmailService_DeliversThroughSMTP(t *testing.T) {
+	server, err := mail.NewTestServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	outbox := newMemoryOutbox()
+	service := newTestService(outbox, mail.NewSMTPTransport(server.Config()), now)
+
+	server.Reject(true)
+	err = service.SendTemplate("budi@example.com", mail.TemplatePaymentReceived, map[string]interface{}{
+		"Name":          "Budi",
+		"OrderID":       "order-1",
+		"PaymentMethod": "bank_transfer",
+	})
+	assert.NoError(t, err)
+
+	var emailID string
+	for id := range outbox.emails {
+		emailID = id
+	}
+
+	assert.NoError(t, service.DispatchOutbox())
+	queued := outbox.get(emailID)
+	assert.Equal(t, emails.StatusPending, queued.Status)
+	assert.Equal(t, 1, queued.Attempts)
+	assert.NotEmpty(t, queued.LastError)
+	assert.Equal(t, now.Add(emails.RetryBaseDelay), queued.NextAttemptAt)
+	assert.Empty(t, server.Messages())
+
+	// Not due yet, so nothing is sent even though the server accepts again.
+	server.Reject(false)
+	assert.NoError(t, service.DispatchOutbox())
+	assert.Empty(t, server.Messages())
+
+	service.now = func() time.Time { return now.Add(emails.RetryBaseDelay) }
+	assert.NoError(t, service.DispatchOutbox())
+
+	sent := outbox.get(emailID)
+	assert.Equal(t, emails.StatusSent, sent.Status)
+	assert.Equal(t, 2, sent.Attempts)
+	assert.Empty(t, sent.Body)
+
+	messages := server.Messages()
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, []string{"budi@example.com"}, messages[0].To)
+		assert.Equal(t, server.Config().Username, messages[0].From)
+		assert.Contains(t, messages[0].Data, "Subject: Pembayaran pesanan order-1 diterima")
+		assert.Contains(t, messages[0].Data, "order-1")
+		assert.Contains(t, messages[0].Data, "bank_transfer")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -34,6 +35,10 @@ func NewForumController(s forum.ForumServiceInterface, j helper.JWTInterface, st
 // @Produce      json
 // @Param        Authorization  header    string  true   "Bearer Token"
 // @Param        page           query     int     false  "Page number (default: 1)"
+// @Param        sort           query     string  false  "Sort order: latest (default, by latest activity), most_liked, trending (recent views and replies) or relevance (default when searching)"
+// @Param        category       query     string  false  "Category slug"
+// @Param        tag            query     string  false  "Tag"
+// @Param        search         query     string  false  "Full-text search across titles, descriptions and messages"
 // @Success      200  {object}  helper.MetadataResponse "get all forum successfully"
 // @Failure      400  {object}  helper.Response "Bad Request"
 // @Failure      401  {object}  helper.Response "Unauthorized"
@@ -61,12 +66,22 @@ func (h *ForumController) GetAllForum(c echo.Context) error {
 
 	var totalPages int
 	var forums []forum.ForumGetAll
-	forums, totalPages, err = h.forumService.GetAllByPage(page)
+	filter := forum.ForumFilter{
+		Page:     page,
+		Sort:     c.QueryParam("sort"),
+		Category: c.QueryParam("category"),
+		Tag:      c.QueryParam("tag"),
+		Search:   c.QueryParam("search"),
+	}
+	forums, totalPages, err = h.forumService.GetAllByPage(filter)
 	metadata := MetadataResponse{
 		CurrentPage: page,
 		TotalPage:   totalPages,
 	}
 
+	if err == constant.ErrInvalidForumSort {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
 	if err != nil {
 		code, message := helper.HandleEchoError(err)
 		return c.JSON(code, helper.FormatResponse(false, message, nil))
@@ -83,6 +98,9 @@ func (h *ForumController) GetAllForum(c echo.Context) error {
 			CreatedAt:    f.CreatedAt.Format("02/01/2006 15:04:05"),
 			UpdatedAt:    f.UpdatedAt.Format("02/01/2006 15:04:05"),
 			MessageCount: f.MessageCount,
+			LikeCount:    f.LikeCount,
+			Category:     toCategoryResponse(f.Category),
+			Tags:         toTagNames(f.Tags),
 			Author:       Author{ID: f.User.ID, Name: f.User.Name, Username: f.User.Username, Email: f.User.Email, AvatarURL: f.User.AvatarURL},
 		})
 	}
@@ -97,6 +115,8 @@ func (h *ForumController) GetAllForum(c echo.Context) error {
 // @Param        Authorization  header    string                  true   "Bearer Token"
 // @Param        title          formData  string                  true   "Forum Title"
 // @Param        description    formData  string                  true   "Forum Description"
+// @Param        category_id    formData  string                  false  "Forum Category ID"
+// @Param        tags           formData  string                  false  "Comma separated tags, at most 5"
 // @Param        topic_image    formData  file                    false  "Forum Topic Image"
 // @Success      201  {object}  helper.Response                  "create forum successfully"
 // @Failure      400  {object}  helper.Response                  "Bad Request"
@@ -155,6 +175,12 @@ func (h *ForumController) PostForum(c echo.Context) error {
 		UserID:      userId.(string),
 		TopicImage:  topic_image,
 	}
+	if forumData.CategoryID != "" {
+		newForum.CategoryID = &forumData.CategoryID
+	}
+	for _, tag := range splitTags(forumData.Tags) {
+		newForum.Tags = append(newForum.Tags, forum.ForumTag{Tag: tag})
+	}
 	err = h.forumService.PostForum(newForum)
 	if err != nil {
 		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
@@ -163,7 +189,7 @@ func (h *ForumController) PostForum(c echo.Context) error {
 }
 
 // @Summary      Get Forum by ID
-// @Description  Retrieve detailed information about a forum, including messages
+// @Description  Retrieve detailed information about a forum, including messages and related threads. Each user counts as one view per thread every 30 minutes.
 // @Tags         Forum
 // @Accept       json
 // @Produce      json
@@ -179,51 +205,68 @@ func (h *ForumController) GetForumByID(c echo.Context) error {
 		return helper.UnauthorizedError(c)
 	}
 
-	_, err := h.jwtService.ValidateToken(tokenString)
+	token, err := h.jwtService.ValidateToken(tokenString)
 	if err != nil {
 		return helper.UnauthorizedError(c)
 	}
 
+	userData := h.jwtService.ExtractUserToken(token)
+	isAdmin := userData[constant.JWT_ROLE] == constant.RoleAdmin
+
 	forumID := c.Param("id")
 
 	forum, err := h.forumService.GetForumByID(forumID)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, helper.ObjectFormatResponse(false, err.Error(), nil))
 	}
+	if forum.Hidden && !isAdmin {
+		return c.JSON(http.StatusNotFound, helper.ObjectFormatResponse(false, constant.ErrForumNotFound.Error(), nil))
+	}
+	h.forumService.RecordView(forumID, userData[constant.JWT_ID].(string))
 
 	messages, err := h.forumService.GetMessagesByForumID(forumID)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, helper.FormatResponse(false, err.Error(), nil))
 	}
-
-	var messageResponses []MessageResponse
-	for _, msg := range messages {
-		messageResponses = append(messageResponses, MessageResponse{
-			ID: msg.ID,
-			User: AuthorMessage{
-				ID:        msg.User.ID,
-				Name:      msg.User.Name,
-				Username:  msg.User.Username,
-				Email:     msg.User.Email,
-				AvatarURL: msg.User.AvatarURL,
-			},
-			Message:      msg.Message,
-			MessageImage: msg.MessageImage,
-			CreatedAt:    msg.CreatedAt.Format("02/01/2006"),
-			UpdatedAt:    msg.UpdatedAt.Format("02/01/2006"),
-		})
+	if !isAdmin {
+		messages = maskHiddenMessages(messages)
+	}
+
+	messageResponses := buildMessageTree(messages)
+
+	// Related threads are a suggestion only, so a failed lookup leaves the
+	// list empty instead of failing the page.
+	relatedResponses := []RelatedForumResponse{}
+	related, err := h.forumService.GetRelatedForums(forumID)
+	if err == nil {
+		for _, r := range related {
+			relatedResponses = append(relatedResponses, RelatedForumResponse{
+				ID:         r.ID,
+				Title:      r.Title,
+				TopicImage: r.TopicImage,
+				Category:   toCategoryResponse(r.Category),
+				Tags:       toTagNames(r.Tags),
+				CreatedAt:  r.CreatedAt.Format("02/01/2006"),
+			})
+		}
 	}
 
 	forumResponse := ForumGetDetailResponse{
-		ID:            forum.ID,
-		Title:         forum.Title,
-		Description:   forum.Description,
-		TopicImage:    forum.TopicImage,
-		View:          forum.View,
-		Author:        Author{ID: forum.User.ID, Name: forum.User.Name, Username: forum.User.Username, Email: forum.User.Email, AvatarURL: forum.User.AvatarURL},
-		ForumMessages: messageResponses,
-		CreatedAt:     forum.CreatedAt.Format("02/01/2006"),
-		UpdatedAt:     forum.UpdatedAt.Format("02/01/2006"),
+		ID:             forum.ID,
+		Title:          forum.Title,
+		Description:    forum.Description,
+		TopicImage:     forum.TopicImage,
+		View:           forum.View,
+		LikeCount:      forum.LikeCount,
+		Hidden:         forum.Hidden,
+		Locked:         forum.Locked,
+		Author:         Author{ID: forum.User.ID, Name: forum.User.Name, Username: forum.User.Username, Email: forum.User.Email, AvatarURL: forum.User.AvatarURL},
+		Category:       toCategoryResponse(forum.Category),
+		Tags:           toTagNames(forum.Tags),
+		ForumMessages:  messageResponses,
+		RelatedThreads: relatedResponses,
+		CreatedAt:      forum.CreatedAt.Format("02/01/2006"),
+		UpdatedAt:      forum.UpdatedAt.Format("02/01/2006"),
 	}
 
 	return c.JSON(http.StatusOK, helper.ObjectFormatResponse(true, "get forum successfully", forumResponse))
@@ -238,6 +281,8 @@ func (h *ForumController) GetForumByID(c echo.Context) error {
 // @Param        id             path      string  true   "Forum ID"
 // @Param        title          formData  string  true   "New title of the forum"
 // @Param        description    formData  string  true   "New description of the forum"
+// @Param        category_id    formData  string  false  "New category ID, omit to keep the current one"
+// @Param        tags           formData  string  false  "Comma separated tags replacing the current ones, omit to keep them"
 // @Param        topic_image    formData  file    false  "New topic image for the forum"
 // @Success      200  {object}  helper.Response  "update forum successfully"
 // @Failure      400  {object}  helper.Response             "Bad Request"
@@ -301,10 +346,19 @@ func (h *ForumController) UpdateForum(c echo.Context) error {
 	forumData.Description = strings.TrimSpace(forumData.Description)
 	forumsResponse := forum.EditForum{
 		ID:          forumid,
+		UserID:      userId,
 		Title:       forumData.Title,
 		Description: forumData.Description,
 		TopicImage:  topic_image,
 	}
+	if forumData.CategoryID != "" {
+		forumsResponse.CategoryID = &forumData.CategoryID
+	}
+	if params, err := c.FormParams(); err == nil {
+		if _, ok := params["tags"]; ok {
+			forumsResponse.Tags = splitTags(forumData.Tags)
+		}
+	}
 	if err := h.forumService.UpdateForum(forumsResponse); err != nil {
 		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
 	}
@@ -313,7 +367,7 @@ func (h *ForumController) UpdateForum(c echo.Context) error {
 }
 
 // @Summary      Delete Forum
-// @Description  Delete a forum by its ID. This action is restricted to users with admin role.
+// @Description  Delete a forum by its ID. Only the author or an admin can delete a forum.
 // @Tags         Forum
 // @Accept       json
 // @Produce      json
@@ -340,6 +394,8 @@ func (h *ForumController) DeleteForum(c echo.Context) error {
 	userData := h.jwtService.ExtractUserToken(token)
 	userId := userData[constant.JWT_ID].(string)
 
+	isAdmin := userData[constant.JWT_ROLE] == constant.RoleAdmin
+
 	forumID := c.Param("id")
 
 	existingForum, err := h.forumService.GetForumByID(forumID)
@@ -347,7 +403,7 @@ func (h *ForumController) DeleteForum(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, helper.FormatResponse(false, string(err.Error()), nil))
 	}
 
-	if existingForum.UserID != userId {
+	if existingForum.UserID != userId && !isAdmin {
 		return c.JSON(http.StatusForbidden, helper.FormatResponse(false, "error forbidden", nil))
 	}
 
@@ -410,6 +466,8 @@ func (h *ForumController) GetForumByUserID(c echo.Context) error {
 			Description: forum.Description,
 			TopicImage:  forum.TopicImage,
 			View:        forum.View,
+			Category:    toCategoryResponse(forum.Category),
+			Tags:        toTagNames(forum.Tags),
 			CreatedAt:   forum.CreatedAt.Format("02/01/2006"),
 			UpdatedAt:   forum.UpdatedAt.Format("02/01/2006"),
 			Author:      Author{ID: forum.User.ID, Name: forum.User.Name, Username: forum.User.Username, Email: forum.User.Email, AvatarURL: forum.User.AvatarURL},
@@ -426,7 +484,8 @@ func (h *ForumController) GetForumByUserID(c echo.Context) error {
 // @Produce      json
 // @Param        Authorization   header    string  true   "Bearer Token"
 // @Param        ForumID         formData  string  true   "ID of the forum to post the message to"
-// @Param        Messages        formData  string  true   "Content of the message"
+// @Param        Messages        formData  string  true   "Content of the message, @username mentions notify the mentioned user"
+// @Param        parent_id       formData  string  false  "ID of the message being replied to"
 // @Param        message_image   formData  file    false  "Optional image to include with the message"
 // @Success      201  {object}   helper.Response  "create message successfully"
 // @Failure      400  {object}   helper.Response  "Bad Request"
@@ -483,6 +542,9 @@ func (h *ForumController) PostMessageForum(c echo.Context) error {
 		Message:      forumMessage.Messages,
 		MessageImage: message_image,
 	}
+	if forumMessage.ParentID != "" {
+		forumData.ParentID = &forumMessage.ParentID
+	}
 	_, err = h.forumService.GetForumByID(forumData.ForumID)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, helper.FormatResponse(false, err.Error(), nil))
@@ -614,6 +676,7 @@ func (h *ForumController) UpdateMessageForum(c echo.Context) error {
 
 	messageResponse := forum.EditMessage{
 		ID:           messageId,
+		UserID:       userId,
 		Message:      messageForum.Messages,
 		MessageImage: message_image,
 	}
@@ -642,25 +705,925 @@ func (h *ForumController) GetMessageForumByID(c echo.Context) error {
 		helper.UnauthorizedError(c)
 	}
 
-	_, err := h.jwtService.ValidateToken(tokenString)
+	token, err := h.jwtService.ValidateToken(tokenString)
 	if err != nil {
-		helper.UnauthorizedError(c)
+		return helper.UnauthorizedError(c)
 	}
 
+	userData := h.jwtService.ExtractUserToken(token)
+	isAdmin := userData[constant.JWT_ROLE] == constant.RoleAdmin
+
 	messageId := c.Param("id")
 	message, err := h.forumService.GetMessageForumByID(messageId)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, helper.ObjectFormatResponse(false, "get message not found", nil))
 	}
-
-	messagesResponse := MessageResponse{
-		ID:           message.ID,
-		User:         AuthorMessage{ID: message.UserID, Name: message.User.Name, Username: message.User.Username, Email: message.User.Email, AvatarURL: message.User.AvatarURL},
-		Message:      message.Message,
-		MessageImage: message.MessageImage,
-		CreatedAt:    message.CreatedAt.Format("02/01/2006"),
-		UpdatedAt:    message.UpdatedAt.Format("02/01/2006"),
+	if !isAdmin {
+		message = maskHiddenMessages([]forum.MessageForum{message})[0]
 	}
 
+	messagesResponse := toMessageResponse(message)
+
 	return c.JSON(http.StatusOK, helper.ObjectFormatResponse(true, "get message forum id successfully", messagesResponse))
 }
+
+// @Summary      Like Forum
+// @Description  Like a forum thread as the authenticated user
+// @Tags         Forum
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        id              path      string  true   "Forum ID"
+// @Success      201  {object}   helper.Response  "like forum successfully"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      404  {object}   helper.Response  "Forum not found"
+// @Failure      409  {object}   helper.Response  "Already liked"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /forums/{id}/like [post]
+func (h *ForumController) LikeForum(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	userData := h.jwtService.ExtractUserToken(token)
+	userId := userData[constant.JWT_ID].(string)
+
+	if err := h.forumService.LikeForum(c.Param("id"), userId); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusCreated, helper.FormatResponse(true, "like forum successfully", nil))
+}
+
+// @Summary      Unlike Forum
+// @Description  Remove the authenticated user's like from a forum thread
+// @Tags         Forum
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        id              path      string  true   "Forum ID"
+// @Success      200  {object}   helper.Response  "unlike forum successfully"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      404  {object}   helper.Response  "Like not found"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /forums/{id}/like [delete]
+func (h *ForumController) UnlikeForum(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	userData := h.jwtService.ExtractUserToken(token)
+	userId := userData[constant.JWT_ID].(string)
+
+	if err := h.forumService.UnlikeForum(c.Param("id"), userId); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "unlike forum successfully", nil))
+}
+
+// @Summary      Like Forum Message
+// @Description  Like a forum message as the authenticated user
+// @Tags         Forum
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        id              path      string  true   "Message ID"
+// @Success      201  {object}   helper.Response  "like message successfully"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      404  {object}   helper.Response  "Message not found"
+// @Failure      409  {object}   helper.Response  "Already liked"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /forums/message/{id}/like [post]
+func (h *ForumController) LikeMessage(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	userData := h.jwtService.ExtractUserToken(token)
+	userId := userData[constant.JWT_ID].(string)
+
+	if err := h.forumService.LikeMessage(c.Param("id"), userId); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusCreated, helper.FormatResponse(true, "like message successfully", nil))
+}
+
+// @Summary      Unlike Forum Message
+// @Description  Remove the authenticated user's like from a forum message
+// @Tags         Forum
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        id              path      string  true   "Message ID"
+// @Success      200  {object}   helper.Response  "unlike message successfully"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      404  {object}   helper.Response  "Like not found"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /forums/message/{id}/like [delete]
+func (h *ForumController) UnlikeMessage(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	userData := h.jwtService.ExtractUserToken(token)
+	userId := userData[constant.JWT_ID].(string)
+
+	if err := h.forumService.UnlikeMessage(c.Param("id"), userId); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "unlike message successfully", nil))
+}
+
+// @Summary      Report Forum Content
+// @Description  Flag a forum thread or message for review by a moderator
+// @Tags         Forum
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string         true   "Bearer Token"
+// @Param        request         body      ReportRequest  true   "target_type is forum or message"
+// @Success      201  {object}   helper.Response  "report content successfully"
+// @Failure      400  {object}   helper.Response  "Bad Request"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      404  {object}   helper.Response  "Forum or message not found"
+// @Failure      409  {object}   helper.Response  "Already reported"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /forums/report [post]
+func (h *ForumController) ReportContent(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	userData := h.jwtService.ExtractUserToken(token)
+	userId := userData[constant.JWT_ID].(string)
+
+	var request ReportRequest
+	if err := c.Bind(&request); err != nil {
+		code, message := helper.HandleEchoError(err)
+		return c.JSON(code, helper.FormatResponse(false, message, nil))
+	}
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	report := forum.ForumReport{
+		ReporterID: userId,
+		TargetType: request.TargetType,
+		TargetID:   request.TargetID,
+		Reason:     request.Reason,
+	}
+	if err := h.forumService.ReportContent(report); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusCreated, helper.FormatResponse(true, "report content successfully", nil))
+}
+
+// @Summary      Get Forum Reports
+// @Description  Moderation queue of reported forum content, oldest first
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        status          query     string  false  "Pending, Resolved or Dismissed (default: all)"
+// @Param        pages           query     int     false  "Page number (default: 1)"
+// @Success      200  {object}   helper.MetadataResponse{data=[]ReportResponse}  "get reports successfully"
+// @Failure      400  {object}   helper.Response  "Bad Request"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/reports [get]
+func (h *ForumController) GetReports(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	page, err := strconv.Atoi(c.QueryParam("pages"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	reports, totalPages, err := h.forumService.GetReports(c.QueryParam("status"), page)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []ReportResponse{}
+	for _, report := range reports {
+		response = append(response, toReportResponse(report))
+	}
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "get reports successfully", metadata, response))
+}
+
+// @Summary      Resolve Forum Report
+// @Description  Resolve a report with dismiss, hide or lock (threads only). Every pending report on the same content is closed with it.
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        id              path      string                true  "Report ID"
+// @Param        request         body      ResolveReportRequest  true  "Action and optional note"
+// @Success      200  {object}   helper.Response  "resolve report successfully"
+// @Failure      400  {object}   helper.Response  "Bad Request"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      404  {object}   helper.Response  "Report not found"
+// @Failure      409  {object}   helper.Response  "Report already handled"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/reports/{id} [put]
+func (h *ForumController) ResolveReport(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+	adminId := adminData[constant.JWT_ID].(string)
+
+	var request ResolveReportRequest
+	if err := c.Bind(&request); err != nil {
+		code, message := helper.HandleEchoError(err)
+		return c.JSON(code, helper.FormatResponse(false, message, nil))
+	}
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	if err := h.forumService.ResolveReport(c.Param("id"), adminId, request.Action, strings.TrimSpace(request.Note)); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "resolve report successfully", nil))
+}
+
+// @Summary      Moderate Forum
+// @Description  Hide, unhide, lock or unlock a forum thread. Hidden threads are invisible to users and locked threads accept no new replies.
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        id              path      string             true  "Forum ID"
+// @Param        request         body      ModerationRequest  true  "action: hide, unhide, lock or unlock"
+// @Success      200  {object}   helper.Response  "moderate forum successfully"
+// @Failure      400  {object}   helper.Response  "Bad Request"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      404  {object}   helper.Response  "Forum not found"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/{id}/moderation [put]
+func (h *ForumController) ModerateForum(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+	adminId := adminData[constant.JWT_ID].(string)
+
+	var request ModerationRequest
+	if err := c.Bind(&request); err != nil {
+		code, message := helper.HandleEchoError(err)
+		return c.JSON(code, helper.FormatResponse(false, message, nil))
+	}
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	if err := h.forumService.ModerateForum(c.Param("id"), adminId, request.Action); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "moderate forum successfully", nil))
+}
+
+// @Summary      Moderate Forum Message
+// @Description  Hide or unhide a forum message. Hidden messages are shown to users as a placeholder.
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        id              path      string             true  "Message ID"
+// @Param        request         body      ModerationRequest  true  "action: hide or unhide"
+// @Success      200  {object}   helper.Response  "moderate message successfully"
+// @Failure      400  {object}   helper.Response  "Bad Request"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      404  {object}   helper.Response  "Message not found"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/message/{id}/moderation [put]
+func (h *ForumController) ModerateMessage(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+	adminId := adminData[constant.JWT_ID].(string)
+
+	var request ModerationRequest
+	if err := c.Bind(&request); err != nil {
+		code, message := helper.HandleEchoError(err)
+		return c.JSON(code, helper.FormatResponse(false, message, nil))
+	}
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	if err := h.forumService.ModerateMessage(c.Param("id"), adminId, request.Action); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "moderate message successfully", nil))
+}
+
+// @Summary      Ban User From Forum
+// @Description  Stop a user from posting threads and messages. A duration of 0 hours bans permanently.
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        request         body      BanRequest  true  "User, reason and duration in hours"
+// @Success      201  {object}   helper.Response{data=BanResponse}  "ban user successfully"
+// @Failure      400  {object}   helper.Response  "Bad Request"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/bans [post]
+func (h *ForumController) BanUser(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+	adminId := adminData[constant.JWT_ID].(string)
+
+	var request BanRequest
+	if err := c.Bind(&request); err != nil {
+		code, message := helper.HandleEchoError(err)
+		return c.JSON(code, helper.FormatResponse(false, message, nil))
+	}
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+	if request.DurationHours < 0 {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, constant.ErrBanField.Error(), nil))
+	}
+
+	ban := forum.UserBan{
+		UserID:    request.UserID,
+		Reason:    request.Reason,
+		CreatedBy: adminId,
+	}
+	if request.DurationHours > 0 {
+		expiresAt := time.Now().Add(time.Duration(request.DurationHours) * time.Hour)
+		ban.ExpiresAt = &expiresAt
+	}
+
+	ban, err = h.forumService.BanUser(ban)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusCreated, helper.ObjectFormatResponse(true, "ban user successfully", toBanResponse(ban)))
+}
+
+// @Summary      Revoke Forum Ban
+// @Description  Lift a ban before it expires
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        id              path      string  true  "Ban ID"
+// @Success      200  {object}   helper.Response  "revoke ban successfully"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      404  {object}   helper.Response  "Ban not found"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/bans/{id} [delete]
+func (h *ForumController) RevokeBan(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+	adminId := adminData[constant.JWT_ID].(string)
+
+	if err := h.forumService.RevokeBan(c.Param("id"), adminId); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "revoke ban successfully", nil))
+}
+
+// @Summary      Get Active Forum Bans
+// @Description  List bans that are currently in effect
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Success      200  {object}   helper.Response{data=[]BanResponse}  "get active bans successfully"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/bans [get]
+func (h *ForumController) GetActiveBans(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	bans, err := h.forumService.GetActiveBans()
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []BanResponse{}
+	for _, ban := range bans {
+		response = append(response, toBanResponse(ban))
+	}
+
+	return c.JSON(http.StatusOK, helper.ObjectFormatResponse(true, "get active bans successfully", response))
+}
+
+// @Summary      Get Blocked Words
+// @Description  List the words the forum content filter rejects
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Success      200  {object}   helper.Response{data=[]BlockedWordResponse}  "get blocked words successfully"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/blocked-words [get]
+func (h *ForumController) GetBlockedWords(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	words, err := h.forumService.GetBlockedWords()
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []BlockedWordResponse{}
+	for _, word := range words {
+		response = append(response, BlockedWordResponse{ID: word.ID, Word: word.Word, CreatedAt: word.CreatedAt.Format("02/01/2006 15:04:05")})
+	}
+
+	return c.JSON(http.StatusOK, helper.ObjectFormatResponse(true, "get blocked words successfully", response))
+}
+
+// @Summary      Add Blocked Word
+// @Description  Add a word to the forum content filter. Matching is case-insensitive on whole words.
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        request         body      BlockedWordRequest  true  "Word to block"
+// @Success      201  {object}   helper.Response{data=BlockedWordResponse}  "add blocked word successfully"
+// @Failure      400  {object}   helper.Response  "Bad Request"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      409  {object}   helper.Response  "Word already blocked"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/blocked-words [post]
+func (h *ForumController) AddBlockedWord(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+	adminId := adminData[constant.JWT_ID].(string)
+
+	var request BlockedWordRequest
+	if err := c.Bind(&request); err != nil {
+		code, message := helper.HandleEchoError(err)
+		return c.JSON(code, helper.FormatResponse(false, message, nil))
+	}
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	word, err := h.forumService.AddBlockedWord(request.Word, adminId)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := BlockedWordResponse{ID: word.ID, Word: word.Word, CreatedAt: word.CreatedAt.Format("02/01/2006 15:04:05")}
+	return c.JSON(http.StatusCreated, helper.ObjectFormatResponse(true, "add blocked word successfully", response))
+}
+
+// @Summary      Remove Blocked Word
+// @Description  Remove a word from the forum content filter
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        id              path      string  true  "Blocked word ID"
+// @Success      200  {object}   helper.Response  "remove blocked word successfully"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      404  {object}   helper.Response  "Blocked word not found"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/blocked-words/{id} [delete]
+func (h *ForumController) RemoveBlockedWord(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+	adminId := adminData[constant.JWT_ID].(string)
+
+	if err := h.forumService.RemoveBlockedWord(c.Param("id"), adminId); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "remove blocked word successfully", nil))
+}
+
+// @Summary      Get Moderation Logs
+// @Description  Audit log of moderation actions, newest first. Automatic filter blocks are logged with the system actor.
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        pages           query     int     false  "Page number (default: 1)"
+// @Success      200  {object}   helper.MetadataResponse{data=[]ModerationLogResponse}  "get moderation logs successfully"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/moderation-logs [get]
+func (h *ForumController) GetModerationLogs(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	page, err := strconv.Atoi(c.QueryParam("pages"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	logs, totalPages, err := h.forumService.GetModerationLogs(page)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []ModerationLogResponse{}
+	for _, entry := range logs {
+		response = append(response, ModerationLogResponse{
+			ID:         entry.ID,
+			ActorID:    entry.ActorID,
+			Action:     entry.Action,
+			TargetType: entry.TargetType,
+			TargetID:   entry.TargetID,
+			Details:    entry.Details,
+			CreatedAt:  entry.CreatedAt.Format("02/01/2006 15:04:05"),
+		})
+	}
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "get moderation logs successfully", metadata, response))
+}
+
+// @Summary      Get Forum Categories
+// @Description  List the categories a forum thread can be filed under
+// @Tags         Forum
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Success      200  {object}   helper.Response{data=[]CategoryResponse}  "get forum categories successfully"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /forums/categories [get]
+func (h *ForumController) GetCategories(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	_, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	categories, err := h.forumService.GetCategories()
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []CategoryResponse{}
+	for i := range categories {
+		response = append(response, *toCategoryResponse(&categories[i]))
+	}
+
+	return c.JSON(http.StatusOK, helper.ObjectFormatResponse(true, "get forum categories successfully", response))
+}
+
+// @Summary      Create Forum Category
+// @Description  Create a forum category. The slug is derived from the name when left empty.
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string           true  "Bearer Token"
+// @Param        request         body      CategoryRequest  true  "Category"
+// @Success      201  {object}   helper.Response{data=CategoryResponse}  "create forum category successfully"
+// @Failure      400  {object}   helper.Response  "Bad Request"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      409  {object}   helper.Response  "Slug already used"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/categories [post]
+func (h *ForumController) CreateCategory(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	var request CategoryRequest
+	if err := c.Bind(&request); err != nil {
+		code, message := helper.HandleEchoError(err)
+		return c.JSON(code, helper.FormatResponse(false, message, nil))
+	}
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	category, err := h.forumService.CreateCategory(forum.ForumCategory{
+		Name:        request.Name,
+		Slug:        request.Slug,
+		Description: request.Description,
+	})
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusCreated, helper.ObjectFormatResponse(true, "create forum category successfully", toCategoryResponse(&category)))
+}
+
+// @Summary      Update Forum Category
+// @Description  Rename a forum category or change its slug and description
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string           true  "Bearer Token"
+// @Param        id              path      string           true  "Category ID"
+// @Param        request         body      CategoryRequest  true  "Category"
+// @Success      200  {object}   helper.Response{data=CategoryResponse}  "update forum category successfully"
+// @Failure      400  {object}   helper.Response  "Bad Request"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      404  {object}   helper.Response  "Category not found"
+// @Failure      409  {object}   helper.Response  "Slug already used"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/categories/{id} [put]
+func (h *ForumController) UpdateCategory(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	var request CategoryRequest
+	if err := c.Bind(&request); err != nil {
+		code, message := helper.HandleEchoError(err)
+		return c.JSON(code, helper.FormatResponse(false, message, nil))
+	}
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	category, err := h.forumService.UpdateCategory(forum.ForumCategory{
+		ID:          c.Param("id"),
+		Name:        request.Name,
+		Slug:        request.Slug,
+		Description: request.Description,
+	})
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.ObjectFormatResponse(true, "update forum category successfully", toCategoryResponse(&category)))
+}
+
+// @Summary      Delete Forum Category
+// @Description  Delete a forum category. Its threads are kept without a category.
+// @Tags         Forum (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true  "Bearer Token"
+// @Param        id              path      string  true  "Category ID"
+// @Success      200  {object}   helper.Response  "delete forum category successfully"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      404  {object}   helper.Response  "Category not found"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /admin/forums/categories/{id} [delete]
+func (h *ForumController) DeleteCategory(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	token, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	adminData := h.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := h.forumService.DeleteCategory(c.Param("id")); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "delete forum category successfully", nil))
+}
+
+// @Summary      Get Popular Forum Tags
+// @Description  Most used tags across visible threads
+// @Tags         Forum
+// @Accept       json
+// @Produce      json
+// @Param        Authorization   header    string  true   "Bearer Token"
+// @Param        limit           query     int     false  "Number of tags (default: 20, max: 50)"
+// @Success      200  {object}   helper.Response{data=[]TagResponse}  "get popular tags successfully"
+// @Failure      401  {object}   helper.Response  "Unauthorized"
+// @Failure      500  {object}   helper.Response  "Internal Server Error"
+// @Router       /forums/tags [get]
+func (h *ForumController) GetPopularTags(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+
+	_, err := h.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	tags, err := h.forumService.GetPopularTags(limit)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []TagResponse{}
+	for _, tag := range tags {
+		response = append(response, TagResponse{Tag: tag.Tag, Count: tag.Count})
+	}
+
+	return c.JSON(http.StatusOK, helper.ObjectFormatResponse(true, "get popular tags successfully", response))
+}
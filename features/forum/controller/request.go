@@ -3,15 +3,20 @@ package controller
 type CreateForumRequest struct {
 	Title       string `json:"title" form:"title" validate:"required"`
 	Description string `json:"description" form:"description" validate:"required"`
+	CategoryID  string `json:"category_id" form:"category_id"`
+	Tags        string `json:"tags" form:"tags"`
 }
 
 type EditForumRequest struct {
 	Title       string `json:"title" form:"title" validate:"required"`
 	Description string `json:"description" form:"description"  validate:"required"`
+	CategoryID  string `json:"category_id" form:"category_id"`
+	Tags        string `json:"tags" form:"tags"`
 }
 
 type CreateMessageForumRequest struct {
 	ForumID  string `json:"forum_id" form:"forum_id" validate:"required"`
+	ParentID string `json:"parent_id" form:"parent_id"`
 	Messages string `json:"messages" form:"messages" validate:"required"`
 }
 
@@ -19,3 +24,35 @@ type EditMessageRequest struct {
 	ForumID  string `json:"forum_id" form:"forum_id" validate:"required"`
 	Messages string `json:"messages" form:"messages" validate:"required"`
 }
+
+type ReportRequest struct {
+	TargetType string `json:"target_type" validate:"required"`
+	TargetID   string `json:"target_id" validate:"required"`
+	Reason     string `json:"reason" validate:"required"`
+}
+
+type ResolveReportRequest struct {
+	Action string `json:"action" validate:"required"`
+	Note   string `json:"note"`
+}
+
+type ModerationRequest struct {
+	Action string `json:"action" validate:"required"`
+}
+
+// BanRequest bans a user from posting. A duration of 0 hours is permanent.
+type BanRequest struct {
+	UserID        string `json:"user_id" validate:"required"`
+	Reason        string `json:"reason" validate:"required"`
+	DurationHours int    `json:"duration_hours"`
+}
+
+type BlockedWordRequest struct {
+	Word string `json:"word" validate:"required"`
+}
+
+type CategoryRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+}
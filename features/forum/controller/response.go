@@ -1,27 +1,65 @@
 package controller
 
+import (
+	"greenenvironment/features/forum"
+	"strings"
+	"time"
+)
+
+const hiddenMessagePlaceholder = "This message has been hidden by a moderator."
+
 type ForumGetAllResponse struct {
-	ID           string `json:"id"`
-	Title        string `json:"title"`
-	Description  string `json:"description"`
-	View         int    `json:"views"`
-	TopicImage   string `json:"topic_image"`
-	Author       Author `json:"author"`
-	CreatedAt    string `json:"created_at"`
-	UpdatedAt    string `json:"updated_at"`
-	MessageCount int    `json:"message_count"`
+	ID           string            `json:"id"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description"`
+	View         int               `json:"views"`
+	TopicImage   string            `json:"topic_image"`
+	Author       Author            `json:"author"`
+	CreatedAt    string            `json:"created_at"`
+	UpdatedAt    string            `json:"updated_at"`
+	MessageCount int               `json:"message_count"`
+	LikeCount    int               `json:"like_count"`
+	Category     *CategoryResponse `json:"category"`
+	Tags         []string          `json:"tags"`
 }
 
 type ForumGetDetailResponse struct {
-	ID            string            `json:"id"`
-	Title         string            `json:"title"`
-	Description   string            `json:"description"`
-	TopicImage    string            `json:"topic_image"`
-	View          int               `json:"views"`
-	CreatedAt     string            `json:"created_at"`
-	UpdatedAt     string            `json:"updated_at"`
-	Author        Author            `json:"author"`
-	ForumMessages []MessageResponse `json:"forum_messages"`
+	ID             string                 `json:"id"`
+	Title          string                 `json:"title"`
+	Description    string                 `json:"description"`
+	TopicImage     string                 `json:"topic_image"`
+	View           int                    `json:"views"`
+	LikeCount      int                    `json:"like_count"`
+	Hidden         bool                   `json:"hidden"`
+	Locked         bool                   `json:"locked"`
+	CreatedAt      string                 `json:"created_at"`
+	UpdatedAt      string                 `json:"updated_at"`
+	Author         Author                 `json:"author"`
+	Category       *CategoryResponse      `json:"category"`
+	Tags           []string               `json:"tags"`
+	ForumMessages  []MessageResponse      `json:"forum_messages"`
+	RelatedThreads []RelatedForumResponse `json:"related_threads"`
+}
+
+type CategoryResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+}
+
+type TagResponse struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+type RelatedForumResponse struct {
+	ID         string            `json:"id"`
+	Title      string            `json:"title"`
+	TopicImage string            `json:"topic_image"`
+	Category   *CategoryResponse `json:"category"`
+	Tags       []string          `json:"tags"`
+	CreatedAt  string            `json:"created_at"`
 }
 
 type Author struct {
@@ -33,12 +71,16 @@ type Author struct {
 }
 
 type MessageResponse struct {
-	ID           string        `json:"id"`
-	User         AuthorMessage `json:"user"`
-	Message      string        `json:"message"`
-	MessageImage string        `json:"message_image"`
-	CreatedAt    string        `json:"created_at"`
-	UpdatedAt    string        `json:"updated_at"`
+	ID           string            `json:"id"`
+	ParentID     string            `json:"parent_id"`
+	User         AuthorMessage     `json:"user"`
+	Message      string            `json:"message"`
+	MessageImage string            `json:"message_image"`
+	LikeCount    int               `json:"like_count"`
+	Hidden       bool              `json:"hidden"`
+	CreatedAt    string            `json:"created_at"`
+	UpdatedAt    string            `json:"updated_at"`
+	Replies      []MessageResponse `json:"replies"`
 }
 
 type AuthorMessage struct {
@@ -49,6 +91,48 @@ type AuthorMessage struct {
 	AvatarURL string `json:"avatar_url"`
 }
 
+type ReportResponse struct {
+	ID            string `json:"id"`
+	Reporter      Author `json:"reporter"`
+	TargetType    string `json:"target_type"`
+	TargetID      string `json:"target_id"`
+	TargetTitle   string `json:"target_title"`
+	TargetContent string `json:"target_content"`
+	TargetAuthor  string `json:"target_author"`
+	Reason        string `json:"reason"`
+	Status        string `json:"status"`
+	ResolvedBy    string `json:"resolved_by"`
+	ResolvedAt    string `json:"resolved_at"`
+	Resolution    string `json:"resolution"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type BanResponse struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	Reason    string `json:"reason"`
+	Permanent bool   `json:"permanent"`
+	ExpiresAt string `json:"expires_at"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+}
+
+type BlockedWordResponse struct {
+	ID        string `json:"id"`
+	Word      string `json:"word"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ModerationLogResponse struct {
+	ID         string `json:"id"`
+	ActorID    string `json:"actor_id"`
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	Details    string `json:"details"`
+	CreatedAt  string `json:"created_at"`
+}
+
 type PaginatedResponse struct {
 	Data  interface{} `json:"data"`
 	Page  int         `json:"page"`
@@ -60,3 +144,136 @@ type MetadataResponse struct {
 	CurrentPage int `json:"current_page"`
 	TotalPage   int `json:"total_page"`
 }
+
+func toMessageResponse(message forum.MessageForum) MessageResponse {
+	response := MessageResponse{
+		ID:           message.ID,
+		User:         AuthorMessage{ID: message.UserID, Name: message.User.Name, Username: message.User.Username, Email: message.User.Email, AvatarURL: message.User.AvatarURL},
+		Message:      message.Message,
+		MessageImage: message.MessageImage,
+		LikeCount:    message.LikeCount,
+		Hidden:       message.Hidden,
+		CreatedAt:    message.CreatedAt.Format("02/01/2006"),
+		UpdatedAt:    message.UpdatedAt.Format("02/01/2006"),
+		Replies:      []MessageResponse{},
+	}
+	if message.ParentID != nil {
+		response.ParentID = *message.ParentID
+	}
+	return response
+}
+
+// buildMessageTree nests replies under the message they answer. Replies whose
+// parent is gone (for example deleted) are shown at the top level instead of
+// being dropped.
+func buildMessageTree(messages []forum.MessageForum) []MessageResponse {
+	known := make(map[string]bool, len(messages))
+	children := make(map[string][]forum.MessageForum)
+	var roots []forum.MessageForum
+	for _, message := range messages {
+		known[message.ID] = true
+	}
+	for _, message := range messages {
+		if message.ParentID != nil && known[*message.ParentID] && *message.ParentID != message.ID {
+			children[*message.ParentID] = append(children[*message.ParentID], message)
+			continue
+		}
+		roots = append(roots, message)
+	}
+
+	var build func(message forum.MessageForum) MessageResponse
+	build = func(message forum.MessageForum) MessageResponse {
+		response := toMessageResponse(message)
+		for _, child := range children[message.ID] {
+			response.Replies = append(response.Replies, build(child))
+		}
+		return response
+	}
+
+	responses := []MessageResponse{}
+	for _, root := range roots {
+		responses = append(responses, build(root))
+	}
+	return responses
+}
+
+// maskHiddenMessages keeps hidden messages in place so replies stay threaded,
+// but replaces their content for users who are not moderators.
+func maskHiddenMessages(messages []forum.MessageForum) []forum.MessageForum {
+	masked := make([]forum.MessageForum, len(messages))
+	for i, message := range messages {
+		if message.Hidden {
+			message.Message = hiddenMessagePlaceholder
+			message.MessageImage = ""
+		}
+		masked[i] = message
+	}
+	return masked
+}
+
+func toReportResponse(report forum.ForumReport) ReportResponse {
+	response := ReportResponse{
+		ID:            report.ID,
+		Reporter:      Author{ID: report.Reporter.ID, Name: report.Reporter.Name, Username: report.Reporter.Username, Email: report.Reporter.Email},
+		TargetType:    report.TargetType,
+		TargetID:      report.TargetID,
+		TargetTitle:   report.TargetTitle,
+		TargetContent: report.TargetContent,
+		TargetAuthor:  report.TargetAuthor,
+		Reason:        report.Reason,
+		Status:        report.Status,
+		ResolvedBy:    report.ResolvedBy,
+		Resolution:    report.Resolution,
+		CreatedAt:     report.CreatedAt.Format("02/01/2006 15:04:05"),
+	}
+	if report.ResolvedAt != nil {
+		response.ResolvedAt = report.ResolvedAt.Format("02/01/2006 15:04:05")
+	}
+	return response
+}
+
+func toBanResponse(ban forum.UserBan) BanResponse {
+	response := BanResponse{
+		ID:        ban.ID,
+		UserID:    ban.UserID,
+		Reason:    ban.Reason,
+		Permanent: ban.ExpiresAt == nil,
+		CreatedBy: ban.CreatedBy,
+		CreatedAt: ban.CreatedAt.Format("02/01/2006 15:04:05"),
+	}
+	if ban.ExpiresAt != nil {
+		response.ExpiresAt = ban.ExpiresAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+func toCategoryResponse(category *forum.ForumCategory) *CategoryResponse {
+	if category == nil {
+		return nil
+	}
+	return &CategoryResponse{
+		ID:          category.ID,
+		Name:        category.Name,
+		Slug:        category.Slug,
+		Description: category.Description,
+	}
+}
+
+func toTagNames(tags []forum.ForumTag) []string {
+	names := []string{}
+	for _, tag := range tags {
+		names = append(names, tag.Tag)
+	}
+	return names
+}
+
+// splitTags reads the comma separated tags form field.
+func splitTags(value string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
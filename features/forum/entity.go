@@ -7,6 +7,64 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// Sort orders accepted by GetAllByPage.
+const (
+	SortLatest    = "latest"
+	SortMostLiked = "most_liked"
+	// SortRelevance orders search results by full-text score and is only
+	// valid together with a search term.
+	SortRelevance = "relevance"
+	// SortTrending orders threads by unique views and replies within
+	// TrendingWindow.
+	SortTrending = "trending"
+)
+
+// Trending score: each reply in the window weighs as much as
+// TrendingReplyWeight unique views.
+const (
+	TrendingWindow      = 48 * time.Hour
+	TrendingReplyWeight = 3
+)
+
+// ViewWindow is how long a viewer is counted only once per thread.
+const ViewWindow = 30 * time.Minute
+
+// Limits on the free-form tags a thread can carry.
+const (
+	MaxTagsPerForum = 5
+	MaxTagLength    = 30
+)
+
+// Targets a like, mention or report can point at.
+const (
+	TargetForum   = "forum"
+	TargetMessage = "message"
+)
+
+// Report statuses.
+const (
+	ReportPending   = "Pending"
+	ReportResolved  = "Resolved"
+	ReportDismissed = "Dismissed"
+)
+
+// Moderation actions, used both in requests and in the audit log.
+const (
+	ActionHide          = "hide"
+	ActionUnhide        = "unhide"
+	ActionLock          = "lock"
+	ActionUnlock        = "unlock"
+	ActionDismiss       = "dismiss"
+	ActionBan           = "ban"
+	ActionRevokeBan     = "revoke_ban"
+	ActionAddWord       = "add_blocked_word"
+	ActionRemoveWord    = "remove_blocked_word"
+	ActionFilterBlocked = "filter_blocked"
+)
+
+// SystemActor is recorded as the actor of moderation done automatically.
+const SystemActor = "system"
+
 type Forum struct {
 	ID          string
 	Title       string
@@ -14,7 +72,13 @@ type Forum struct {
 	UserID      string
 	View        int
 	TopicImage  string
+	LikeCount   int
+	Hidden      bool
+	Locked      bool
 	User        users.User
+	CategoryID  *string
+	Category    *ForumCategory
+	Tags        []ForumTag `gorm:"foreignKey:ForumID"`
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
@@ -28,27 +92,137 @@ type ForumGetAll struct {
 	TopicImage   string
 	User         users.User
 	MessageCount int
+	LikeCount    int
+	CategoryID   *string
+	Category     *ForumCategory
+	Tags         []ForumTag `gorm:"foreignKey:ForumID"`
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
 
+// ForumFilter narrows GetAllByPage. Category is a category slug and Search
+// is matched against thread titles, descriptions and messages.
+type ForumFilter struct {
+	Page     int
+	Sort     string
+	Category string
+	Tag      string
+	Search   string
+}
+
+type ForumCategory struct {
+	ID          string
+	Name        string
+	Slug        string
+	Description string
+}
+
+type ForumTag struct {
+	ID      string
+	ForumID string
+	Tag     string
+}
+
+// ForumView is one unique view of a thread. ViewerKey identifies the viewer,
+// which is the user ID since every forum route requires a login.
+type ForumView struct {
+	ID        string
+	ForumID   string
+	ViewerKey string
+	ViewedAt  time.Time
+}
+
+// TagCount is how many visible threads use a tag.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
 type MessageForum struct {
 	ID           string
 	UserID       string
 	User         users.User
 	ForumID      string
+	ParentID     *string
 	Message      string
 	MessageImage string
+	LikeCount    int
+	Hidden       bool
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
 
+type ForumLike struct {
+	ID         string
+	UserID     string
+	TargetType string
+	TargetID   string
+}
+
+type ForumMention struct {
+	ID              string
+	ForumID         string
+	MessageID       *string
+	MentionedUserID string
+	MentionedByID   string
+}
+
+type ForumReport struct {
+	ID         string
+	ReporterID string
+	Reporter   users.User
+	TargetType string
+	TargetID   string
+	Reason     string
+	Status     string
+	ResolvedBy string
+	ResolvedAt *time.Time
+	Resolution string
+	// Preview of the reported content for the moderation queue.
+	TargetTitle   string
+	TargetContent string
+	TargetAuthor  string
+	CreatedAt     time.Time
+}
+
+// UserBan stops a user from posting in the forum. A nil ExpiresAt means the
+// ban is permanent.
+type UserBan struct {
+	ID        string
+	UserID    string
+	Reason    string
+	ExpiresAt *time.Time
+	CreatedBy string
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+type BlockedWord struct {
+	ID        string
+	Word      string
+	CreatedAt time.Time
+}
+
+type ModerationLog struct {
+	ID         string
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	Details    string
+	CreatedAt  time.Time
+}
+
+// EditForum updates a thread. A nil CategoryID keeps the current category
+// and a nil Tags keeps the current tags.
 type EditForum struct {
 	ID          string
 	Title       string
 	UserID      string
 	Description string
 	TopicImage  string
+	CategoryID  *string
+	Tags        []string
 	UpdatedAt   time.Time
 }
 
@@ -72,14 +246,41 @@ type ForumControllerInterface interface {
 	UpdateMessageForum(c echo.Context) error
 	GetMessageForumByID(c echo.Context) error
 	GetForumByUserID(c echo.Context) error
+
+	LikeForum(c echo.Context) error
+	UnlikeForum(c echo.Context) error
+	LikeMessage(c echo.Context) error
+	UnlikeMessage(c echo.Context) error
+
+	// Moderation
+	ReportContent(c echo.Context) error
+	GetReports(c echo.Context) error
+	ResolveReport(c echo.Context) error
+	ModerateForum(c echo.Context) error
+	ModerateMessage(c echo.Context) error
+	BanUser(c echo.Context) error
+	RevokeBan(c echo.Context) error
+	GetActiveBans(c echo.Context) error
+	GetBlockedWords(c echo.Context) error
+	AddBlockedWord(c echo.Context) error
+	RemoveBlockedWord(c echo.Context) error
+	GetModerationLogs(c echo.Context) error
+
+	// Categories and tags
+	GetCategories(c echo.Context) error
+	CreateCategory(c echo.Context) error
+	UpdateCategory(c echo.Context) error
+	DeleteCategory(c echo.Context) error
+	GetPopularTags(c echo.Context) error
 }
 
 type ForumServiceInterface interface {
 	GetAllForum() ([]Forum, error)
-	GetAllByPage(page int) ([]ForumGetAll, int, error)
+	GetAllByPage(filter ForumFilter) ([]ForumGetAll, int, error)
 	GetForumByID(ID string) (Forum, error)
 	PostForum(Forum) error
 	GetForumByUserID(ID string, page int) ([]Forum, int, error)
+	GetVisibleForumByUserID(ID string, page int) ([]Forum, int, error)
 	UpdateForum(EditForum) error
 	DeleteForum(forumID string) error
 
@@ -89,14 +290,46 @@ type ForumServiceInterface interface {
 	UpdateMessageForum(EditMessage) error
 	GetMessagesByForumID(forumID string) ([]MessageForum, error)
 	GetMessagesByForumIDWithPagination(forumID string, page int, pageSize int) ([]MessageForum, error)
+
+	LikeForum(forumID, userID string) error
+	UnlikeForum(forumID, userID string) error
+	LikeMessage(messageID, userID string) error
+	UnlikeMessage(messageID, userID string) error
+
+	// Moderation
+	ReportContent(report ForumReport) error
+	GetReports(status string, page int) ([]ForumReport, int, error)
+	ResolveReport(reportID, adminID, action, note string) error
+	ModerateForum(forumID, adminID, action string) error
+	ModerateMessage(messageID, adminID, action string) error
+	BanUser(ban UserBan) (UserBan, error)
+	RevokeBan(banID, adminID string) error
+	GetActiveBans() ([]UserBan, error)
+	GetBlockedWords() ([]BlockedWord, error)
+	AddBlockedWord(word, adminID string) (BlockedWord, error)
+	RemoveBlockedWord(wordID, adminID string) error
+	GetModerationLogs(page int) ([]ModerationLog, int, error)
+
+	// Categories, tags and related threads
+	GetCategories() ([]ForumCategory, error)
+	CreateCategory(category ForumCategory) (ForumCategory, error)
+	UpdateCategory(category ForumCategory) (ForumCategory, error)
+	DeleteCategory(categoryID string) error
+	GetPopularTags(limit int) ([]TagCount, error)
+	GetRelatedForums(forumID string) ([]Forum, error)
+
+	// Views
+	RecordView(forumID, viewerKey string)
+	FlushViews() error
 }
 
 type ForumRepositoryInterface interface {
 	GetAllForum() ([]Forum, error)
-	GetAllByPage(page int) ([]ForumGetAll, int, error)
+	GetAllByPage(filter ForumFilter) ([]ForumGetAll, int, error)
 	GetForumByID(ID string) (Forum, error)
 	PostForum(Forum) error
 	GetForumByUserID(ID string, page int) ([]Forum, int, error)
+	GetVisibleForumByUserID(ID string, page int) ([]Forum, int, error)
 
 	UpdateForum(EditForum) error
 	DeleteForum(forumID string) error
@@ -107,4 +340,43 @@ type ForumRepositoryInterface interface {
 	UpdateMessageForum(EditMessage) error
 	GetMessagesByForumID(forumID string) ([]MessageForum, error)
 	GetMessagesByForumIDWithPagination(forumID string, page int, pageSize int) ([]MessageForum, error)
+
+	AddLike(like ForumLike) error
+	RemoveLike(userID, targetType, targetID string) error
+	GetUsersByUsernames(usernames []string) ([]users.User, error)
+	SaveMentions(mentions []ForumMention) error
+
+	// Moderation
+	GetForumState(forumID string) (Forum, error)
+	CreateReport(report ForumReport) error
+	HasPendingReport(reporterID, targetType, targetID string) (bool, error)
+	GetReports(status string, page int) ([]ForumReport, int, error)
+	GetReportByID(reportID string) (ForumReport, error)
+	ResolveReports(targetType, targetID, status, adminID, resolution string) error
+	SetForumHidden(forumID string, hidden bool) error
+	SetForumLocked(forumID string, locked bool) error
+	SetMessageHidden(messageID string, hidden bool) error
+	CreateBan(ban UserBan) error
+	RevokeBan(banID string) error
+	GetActiveBan(userID string, now time.Time) (UserBan, error)
+	GetActiveBans(now time.Time) ([]UserBan, error)
+	GetBlockedWords() ([]BlockedWord, error)
+	AddBlockedWord(word BlockedWord) error
+	RemoveBlockedWord(wordID string) (BlockedWord, error)
+	CreateModerationLog(log ModerationLog) error
+	GetModerationLogs(page int) ([]ModerationLog, int, error)
+
+	// Categories, tags and related threads
+	GetCategories() ([]ForumCategory, error)
+	GetCategoryByID(categoryID string) (ForumCategory, error)
+	GetCategoryBySlug(slug string) (ForumCategory, error)
+	CreateCategory(category ForumCategory) error
+	UpdateCategory(category ForumCategory) error
+	DeleteCategory(categoryID string) error
+	GetPopularTags(limit int) ([]TagCount, error)
+	GetRelatedForums(forumID string, limit int) ([]Forum, error)
+
+	// Views
+	SaveViews(counts map[string]int, views []ForumView) error
+	DeleteViewsBefore(before time.Time) error
 }
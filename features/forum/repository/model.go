@@ -9,16 +9,47 @@ import (
 
 type Forum struct {
 	*gorm.Model
-	ID            string     `gorm:"primary_key;type:varchar(50);not null;column:id;"`
-	Title         string     `gorm:"type:varchar(255);not null;column:title"`
-	TopicImage    string     `gorm:"column:topic_image"`
-	View          int        `gorm:"default:0;column:view"`
-	UserID        string     `gorm:"type:varchar(50);not null;column:user_id"`
-	User          users.User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	Description   string     `gorm:"type:text;not null;column:description"`
-	MessageCount  int        `gorm:"-" json:"message_count"`
-	LastMessageAt time.Time  `gorm:"column:last_message_at"`
-	CreatedAt     time.Time  `gorm:"column:created_at"`
+	ID            string         `gorm:"primary_key;type:varchar(50);not null;column:id;"`
+	Title         string         `gorm:"type:varchar(255);not null;column:title;index:idx_forum_search,class:FULLTEXT,option:WITH PARSER ngram"`
+	TopicImage    string         `gorm:"column:topic_image"`
+	View          int            `gorm:"default:0;column:view"`
+	UserID        string         `gorm:"type:varchar(50);not null;column:user_id"`
+	User          users.User     `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Description   string         `gorm:"type:text;not null;column:description;index:idx_forum_search,class:FULLTEXT,option:WITH PARSER ngram"`
+	CategoryID    *string        `gorm:"type:varchar(50);index;column:category_id"`
+	Category      *ForumCategory `gorm:"foreignKey:CategoryID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Hidden        bool           `gorm:"default:false;column:hidden"`
+	Locked        bool           `gorm:"default:false;column:locked"`
+	MessageCount  int            `gorm:"-" json:"message_count"`
+	LastMessageAt time.Time      `gorm:"column:last_message_at"`
+	CreatedAt     time.Time      `gorm:"column:created_at"`
+}
+
+type ForumCategory struct {
+	*gorm.Model
+	ID          string `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	Name        string `gorm:"type:varchar(100);not null;column:name"`
+	Slug        string `gorm:"type:varchar(100);not null;uniqueIndex;column:slug"`
+	Description string `gorm:"type:text;column:description"`
+}
+
+type ForumView struct {
+	*gorm.Model
+	ID        string    `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	ForumID   string    `gorm:"type:varchar(50);not null;index:idx_forum_view_recent;column:forum_id"`
+	ViewerKey string    `gorm:"type:varchar(100);not null;column:viewer_key"`
+	ViewedAt  time.Time `gorm:"type:datetime;not null;index:idx_forum_view_recent;index;column:viewed_at"`
+	Forum     Forum     `gorm:"foreignKey:ForumID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// ForumTag is one free-form tag on a thread. Tags are stored lowercase and
+// replaced as a whole when the thread is edited.
+type ForumTag struct {
+	*gorm.Model
+	ID      string `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	ForumID string `gorm:"type:varchar(50);not null;uniqueIndex:idx_forum_tag;column:forum_id"`
+	Tag     string `gorm:"type:varchar(30);not null;uniqueIndex:idx_forum_tag;index;column:tag"`
+	Forum   Forum  `gorm:"foreignKey:ForumID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
 
 type MessageForum struct {
@@ -26,12 +57,79 @@ type MessageForum struct {
 	ID           string     `gorm:"primary_key;type:varchar(50);not null;column:id"`
 	ForumID      string     `gorm:"type:varchar(50);not null;column:forum_id"`
 	Forum        Forum      `gorm:"foreignKey:ForumID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ParentID     *string    `gorm:"type:varchar(50);index;column:parent_id"`
 	MessageImage string     `gorm:"column:message_image"`
-	Message      string     `gorm:"type:text;not null;column:message"`
+	Message      string     `gorm:"type:text;not null;column:message;index:,class:FULLTEXT,option:WITH PARSER ngram"`
+	Hidden       bool       `gorm:"default:false;column:hidden"`
 	UserID       string     `gorm:"type:varchar(50);not null;column:user_id"`
 	User         users.User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
 
+// ForumLike is one user's like on a forum thread or a message. Unliking
+// removes the row so the same user can like the target again later.
+type ForumLike struct {
+	*gorm.Model
+	ID         string     `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	UserID     string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_forum_like_target;column:user_id"`
+	TargetType string     `gorm:"type:enum('forum','message');not null;uniqueIndex:idx_forum_like_target;column:target_type"`
+	TargetID   string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_forum_like_target;index;column:target_id"`
+	User       users.User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type ForumMention struct {
+	*gorm.Model
+	ID              string     `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	ForumID         string     `gorm:"type:varchar(50);not null;index;column:forum_id"`
+	MessageID       *string    `gorm:"type:varchar(50);index;column:message_id"`
+	MentionedUserID string     `gorm:"type:varchar(50);not null;index;column:mentioned_user_id"`
+	MentionedByID   string     `gorm:"type:varchar(50);not null;column:mentioned_by_id"`
+	Forum           Forum      `gorm:"foreignKey:ForumID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	MentionedUser   users.User `gorm:"foreignKey:MentionedUserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type ForumReport struct {
+	*gorm.Model
+	ID         string     `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	ReporterID string     `gorm:"type:varchar(50);not null;index;column:reporter_id"`
+	TargetType string     `gorm:"type:enum('forum','message');not null;index:idx_forum_report_target;column:target_type"`
+	TargetID   string     `gorm:"type:varchar(50);not null;index:idx_forum_report_target;column:target_id"`
+	Reason     string     `gorm:"type:text;not null;column:reason"`
+	Status     string     `gorm:"type:enum('Pending','Resolved','Dismissed');default:'Pending';index;column:status"`
+	ResolvedBy *string    `gorm:"type:varchar(50);column:resolved_by"`
+	ResolvedAt *time.Time `gorm:"type:datetime;column:resolved_at"`
+	Resolution string     `gorm:"type:text;column:resolution"`
+	Reporter   users.User `gorm:"foreignKey:ReporterID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type UserBan struct {
+	*gorm.Model
+	ID        string     `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	UserID    string     `gorm:"type:varchar(50);not null;index;column:user_id"`
+	Reason    string     `gorm:"type:text;column:reason"`
+	ExpiresAt *time.Time `gorm:"type:datetime;index;column:expires_at"`
+	CreatedBy string     `gorm:"type:varchar(50);not null;column:created_by"`
+	RevokedAt *time.Time `gorm:"type:datetime;column:revoked_at"`
+	User      users.User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type BlockedWord struct {
+	*gorm.Model
+	ID   string `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	Word string `gorm:"type:varchar(100);not null;uniqueIndex;column:word"`
+}
+
+// ModerationLog is append-only. ActorID is a user ID or "system" for
+// automatic actions, so it has no foreign key.
+type ModerationLog struct {
+	*gorm.Model
+	ID         string `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	ActorID    string `gorm:"type:varchar(50);not null;index;column:actor_id"`
+	Action     string `gorm:"type:varchar(50);not null;column:action"`
+	TargetType string `gorm:"type:varchar(20);not null;column:target_type"`
+	TargetID   string `gorm:"type:varchar(50);column:target_id"`
+	Details    string `gorm:"type:text;column:details"`
+}
+
 func (Forum) TableName() string {
 	return "forums"
 }
@@ -39,3 +137,39 @@ func (Forum) TableName() string {
 func (MessageForum) TableName() string {
 	return "message_forums"
 }
+
+func (ForumLike) TableName() string {
+	return "forum_likes"
+}
+
+func (ForumMention) TableName() string {
+	return "forum_mentions"
+}
+
+func (ForumCategory) TableName() string {
+	return "forum_categories"
+}
+
+func (ForumView) TableName() string {
+	return "forum_views"
+}
+
+func (ForumTag) TableName() string {
+	return "forum_tags"
+}
+
+func (ForumReport) TableName() string {
+	return "forum_reports"
+}
+
+func (UserBan) TableName() string {
+	return "user_bans"
+}
+
+func (BlockedWord) TableName() string {
+	return "forum_blocked_words"
+}
+
+func (ModerationLog) TableName() string {
+	return "moderation_logs"
+}
@@ -4,8 +4,22 @@ import (
 	"errors"
 	"greenenvironment/constant"
 	"greenenvironment/features/forum"
+	"greenenvironment/features/users"
+	userData "greenenvironment/features/users/repository"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	forumLikeCountQuery   = "(SELECT COUNT(*) FROM forum_likes WHERE forum_likes.target_type = 'forum' AND forum_likes.target_id = forums.id AND forum_likes.deleted_at IS NULL) AS like_count"
+	messageLikeCountQuery = "(SELECT COUNT(*) FROM forum_likes WHERE forum_likes.target_type = 'message' AND forum_likes.target_id = message_forums.id AND forum_likes.deleted_at IS NULL) AS like_count"
+	lastActivityOrder     = "GREATEST(COALESCE(last_message_at, '2000-01-01'), created_at) DESC"
+	forumSearchMatch      = "MATCH(forums.title, forums.description) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	trendingOrder         = "((SELECT COUNT(*) FROM forum_views WHERE forum_views.forum_id = forums.id AND forum_views.viewed_at >= ?) + ? * (SELECT COUNT(*) FROM message_forums WHERE message_forums.forum_id = forums.id AND message_forums.created_at >= ? AND message_forums.deleted_at IS NULL)) DESC"
+	messageSearchMatch    = "forums.id IN (SELECT message_forums.forum_id FROM message_forums WHERE MATCH(message_forums.message) AGAINST (? IN NATURAL LANGUAGE MODE) AND message_forums.hidden = false AND message_forums.deleted_at IS NULL)"
 )
 
 type ForumRepository struct {
@@ -27,11 +41,12 @@ func (u *ForumRepository) GetAllForum() ([]forum.Forum, error) {
 	return forum, nil
 }
 
-func (u *ForumRepository) GetAllByPage(page int) ([]forum.ForumGetAll, int, error) {
+func (u *ForumRepository) GetAllByPage(filter forum.ForumFilter) ([]forum.ForumGetAll, int, error) {
+	page := filter.Page
 	var forumData []forum.ForumGetAll
 
 	var total int64
-	count := u.DB.Model(&forum.Forum{}).Where("deleted_at IS NULL").Count(&total)
+	count := u.filterForums(u.DB.Model(&Forum{}), filter).Count(&total)
 	if count.Error != nil {
 		return nil, 0, constant.ErrProductEmpty
 	}
@@ -39,7 +54,18 @@ func (u *ForumRepository) GetAllByPage(page int) ([]forum.ForumGetAll, int, erro
 	dataforumPerPage := 20
 	totalPages := int((total + int64(dataforumPerPage) - 1) / int64(dataforumPerPage))
 
-	tx := u.DB.Model(&Forum{}).Preload("User").Select("forums.*, (SELECT COUNT(*) FROM message_forums WHERE message_forums.forum_id = forums.id) AS message_count").Order("GREATEST(COALESCE(last_message_at, '2000-01-01'), created_at) DESC").Offset((page - 1) * dataforumPerPage).Limit(dataforumPerPage).Find(&forumData)
+	tx := u.DB.Model(&Forum{}).Preload("User").Preload("Category").Preload("Tags").Select("forums.*, (SELECT COUNT(*) FROM message_forums WHERE message_forums.forum_id = forums.id) AS message_count, " + forumLikeCountQuery)
+	tx = u.filterForums(tx, filter)
+	switch filter.Sort {
+	case forum.SortMostLiked:
+		tx = tx.Order("like_count DESC")
+	case forum.SortRelevance:
+		tx = tx.Order(clause.Expr{SQL: forumSearchMatch + " DESC", Vars: []interface{}{filter.Search}})
+	case forum.SortTrending:
+		since := time.Now().Add(-forum.TrendingWindow)
+		tx = tx.Order(clause.Expr{SQL: trendingOrder, Vars: []interface{}{since, forum.TrendingReplyWeight, since}})
+	}
+	tx = tx.Order(lastActivityOrder).Offset((page - 1) * dataforumPerPage).Limit(dataforumPerPage).Find(&forumData)
 	if tx.Error != nil {
 		return nil, 0, tx.Error
 	}
@@ -49,41 +75,110 @@ func (u *ForumRepository) GetAllByPage(page int) ([]forum.ForumGetAll, int, erro
 	return forumData, totalPages, nil
 }
 
-func (u *ForumRepository) GetForumByID(ID string) (forum.Forum, error) {
-	var forumData forum.Forum
-	if err := u.DB.Model(&Forum{}).Preload("User").Where("id = ?", ID).First(&forumData).Error; err != nil {
-		return forum.Forum{}, err
+// filterForums applies the visibility rules and the optional category, tag
+// and search filters shared by the page query and its count.
+func (u *ForumRepository) filterForums(tx *gorm.DB, filter forum.ForumFilter) *gorm.DB {
+	tx = tx.Where("forums.deleted_at IS NULL AND forums.hidden = ?", false)
+	if filter.Category != "" {
+		tx = tx.Where("forums.category_id IN (SELECT id FROM forum_categories WHERE slug = ?)", filter.Category)
+	}
+	if filter.Tag != "" {
+		tx = tx.Where("forums.id IN (SELECT forum_id FROM forum_tags WHERE tag = ?)", filter.Tag)
 	}
+	if filter.Search != "" {
+		tx = tx.Where(forumSearchMatch+" OR "+messageSearchMatch, filter.Search, filter.Search)
+	}
+	return tx
+}
 
-	if err := u.DB.Model(&Forum{}).Where("id = ?", ID).Update("view", gorm.Expr("view + ?", 1)).Error; err != nil {
+func (u *ForumRepository) GetForumByID(ID string) (forum.Forum, error) {
+	var forumData forum.Forum
+	if err := u.DB.Model(&Forum{}).Preload("User").Preload("Category").Preload("Tags").Select("forums.*, "+forumLikeCountQuery).Where("id = ?", ID).First(&forumData).Error; err != nil {
 		return forum.Forum{}, err
 	}
-
 	return forumData, nil
 }
 
 func (u *ForumRepository) PostForum(forum forum.Forum) error {
-	if err := u.DB.Create(&forum).Error; err != nil {
+	res := u.DB.Begin()
+	if err := res.Omit("like_count", "Category", "Tags").Create(&forum).Error; err != nil {
+		res.Rollback()
 		return err
 	}
-	return nil
+
+	if err := createTags(res, forum.ID, forum.Tags); err != nil {
+		res.Rollback()
+		return err
+	}
+	return res.Commit().Error
 }
 
 func (u *ForumRepository) UpdateForum(forumData forum.EditForum) error {
-	var existingForum forum.Forum
+	var existingForum Forum
 	if err := u.DB.Where("id = ?", forumData.ID).First(&existingForum).Error; err != nil {
 		return err
 	}
+	if existingForum.Hidden {
+		return constant.ErrForumNotFound
+	}
+	if existingForum.Locked {
+		return constant.ErrForumLocked
+	}
 
-	err := u.DB.Model(&existingForum).Updates(forumData).Error
+	res := u.DB.Begin()
+	err := res.Model(&existingForum).Updates(Forum{
+		Title:       forumData.Title,
+		Description: forumData.Description,
+		TopicImage:  forumData.TopicImage,
+		CategoryID:  forumData.CategoryID,
+	}).Error
 	if err != nil {
+		res.Rollback()
 		return err
 	}
-	return nil
+
+	if forumData.Tags != nil {
+		if err := res.Unscoped().Where("forum_id = ?", forumData.ID).Delete(&ForumTag{}).Error; err != nil {
+			res.Rollback()
+			return err
+		}
+
+		var tags []forum.ForumTag
+		for _, tag := range forumData.Tags {
+			tags = append(tags, forum.ForumTag{ID: uuid.New().String(), ForumID: forumData.ID, Tag: tag})
+		}
+		if err := createTags(res, forumData.ID, tags); err != nil {
+			res.Rollback()
+			return err
+		}
+	}
+	return res.Commit().Error
 }
+
+func createTags(tx *gorm.DB, forumID string, tags []forum.ForumTag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var data []ForumTag
+	for _, tag := range tags {
+		data = append(data, ForumTag{ID: tag.ID, ForumID: forumID, Tag: tag.Tag})
+	}
+	return tx.Create(&data).Error
+}
+
 func (u *ForumRepository) DeleteForum(forumID string) error {
 	res := u.DB.Begin()
 
+	likes := res.Unscoped().
+		Where("(target_type = ? AND target_id = ?) OR (target_type = ? AND target_id IN (?))",
+			forum.TargetForum, forumID, forum.TargetMessage, res.Model(&MessageForum{}).Select("id").Where("forum_id = ?", forumID)).
+		Delete(&ForumLike{})
+	if likes.Error != nil {
+		res.Rollback()
+		return likes.Error
+	}
+
 	result := res.Where("forum_id = ?", forumID).Delete(&MessageForum{})
 	if result.Error != nil {
 		res.Rollback()
@@ -101,21 +196,31 @@ func (u *ForumRepository) DeleteForum(forumID string) error {
 }
 
 func (u *ForumRepository) GetForumByUserID(userID string, page int) ([]forum.Forum, int, error) {
+	return u.getForumByUserID(u.DB.Where("user_id = ?", userID), page)
+}
+
+// GetVisibleForumByUserID lists the threads of a user that moderators did
+// not hide, as other users see them.
+func (u *ForumRepository) GetVisibleForumByUserID(userID string, page int) ([]forum.Forum, int, error) {
+	return u.getForumByUserID(u.DB.Where("user_id = ? AND hidden = ?", userID, false), page)
+}
+
+func (u *ForumRepository) getForumByUserID(scope *gorm.DB, page int) ([]forum.Forum, int, error) {
 	var forum []forum.Forum
 	var total int64
 
 	dataforumPerPage := 20
-	countResult := u.DB.Model(&Forum{}).Where("user_id = ?", userID).Count(&total)
+	countResult := u.DB.Model(&Forum{}).Where(scope).Count(&total)
 	if countResult.Error != nil {
 		return nil, 0, countResult.Error
 	}
 
 	if total == 0 {
-		return nil, 0, errors.New("not found forum")
+		return nil, 0, constant.ErrForumNotFound
 	}
 
 	totalPages := int((total + int64(dataforumPerPage) - 1) / int64(dataforumPerPage))
-	tx := u.DB.Model(&Forum{}).Preload("User").Order("created_at DESC, last_message_at DESC").Offset((page-1)*dataforumPerPage).Limit(dataforumPerPage).Where("user_id = ?", userID).Find(&forum)
+	tx := u.DB.Model(&Forum{}).Preload("User").Preload("Category").Preload("Tags").Order("created_at DESC, last_message_at DESC").Offset((page - 1) * dataforumPerPage).Limit(dataforumPerPage).Where(scope).Find(&forum)
 	if tx.Error != nil {
 		return nil, 0, constant.ErrGetProduct
 	}
@@ -125,7 +230,8 @@ func (u *ForumRepository) GetForumByUserID(userID string, page int) ([]forum.For
 // Message
 func (u *ForumRepository) PostMessageForum(messageForum forum.MessageForum) error {
 	res := u.DB.Begin()
-	if err := res.Create(&messageForum).Error; err != nil {
+	if err := res.Omit("like_count").Create(&messageForum).Error; err != nil {
+		res.Rollback()
 		return err
 	}
 
@@ -157,11 +263,7 @@ func (u *ForumRepository) DeleteMessageForum(messageID string) error {
 
 func (r *ForumRepository) GetMessageForumByID(ID string) (forum.MessageForum, error) {
 	var messageForum forum.MessageForum
-	if err := r.DB.Model(&MessageForum{}).Preload("User").Where("id = ? AND deleted_at IS NULL", ID).First(&messageForum).Error; err != nil {
-		return forum.MessageForum{}, err
-	}
-
-	if err := r.DB.Model(&Forum{}).Where("id = ?", ID).Update("view", gorm.Expr("view + ?", 1)).Error; err != nil {
+	if err := r.DB.Model(&MessageForum{}).Preload("User").Select("message_forums.*, "+messageLikeCountQuery).Where("id = ? AND deleted_at IS NULL", ID).First(&messageForum).Error; err != nil {
 		return forum.MessageForum{}, err
 	}
 	return messageForum, nil
@@ -176,6 +278,13 @@ func (r *ForumRepository) UpdateMessageForum(message forum.EditMessage) error {
 	if err := r.DB.Where("id = ? AND deleted_at IS NULL", messageForum.ForumID).First(&forum).Error; err != nil {
 		return errors.New("not found forum")
 	}
+	// Messages in hidden or locked threads are frozen like the threads.
+	if forum.Hidden {
+		return constant.ErrForumNotFound
+	}
+	if forum.Locked {
+		return constant.ErrForumLocked
+	}
 	err := r.DB.Model(&messageForum).Updates(message).Error
 	if err != nil {
 		return err
@@ -186,7 +295,7 @@ func (r *ForumRepository) UpdateMessageForum(message forum.EditMessage) error {
 
 func (u *ForumRepository) GetMessagesByForumID(forumID string) ([]forum.MessageForum, error) {
 	var messages []forum.MessageForum
-	if err := u.DB.Model(&MessageForum{}).Preload("User").Where("forum_id = ? AND deleted_at IS NULL", forumID).Find(&messages).Error; err != nil {
+	if err := u.DB.Model(&MessageForum{}).Preload("User").Select("message_forums.*, "+messageLikeCountQuery).Where("forum_id = ? AND deleted_at IS NULL", forumID).Order("created_at ASC").Find(&messages).Error; err != nil {
 		return nil, err
 	}
 	return messages, nil
@@ -204,3 +313,550 @@ func (u *ForumRepository) GetMessagesByForumIDWithPagination(forumID string, pag
 	}
 	return messages, nil
 }
+
+// Likes
+func (u *ForumRepository) AddLike(like forum.ForumLike) error {
+	var targets int64
+	if like.TargetType == forum.TargetForum {
+		if err := u.DB.Model(&Forum{}).Where("id = ?", like.TargetID).Count(&targets).Error; err != nil {
+			return err
+		}
+		if targets == 0 {
+			return constant.ErrForumNotFound
+		}
+	} else {
+		if err := u.DB.Model(&MessageForum{}).Where("id = ?", like.TargetID).Count(&targets).Error; err != nil {
+			return err
+		}
+		if targets == 0 {
+			return constant.ErrForumMessageNotFound
+		}
+	}
+
+	var existing int64
+	err := u.DB.Model(&ForumLike{}).
+		Where("user_id = ? AND target_type = ? AND target_id = ?", like.UserID, like.TargetType, like.TargetID).
+		Count(&existing).Error
+	if err != nil {
+		return err
+	}
+	if existing > 0 {
+		return constant.ErrForumAlreadyLiked
+	}
+
+	return u.DB.Create(&ForumLike{
+		ID:         like.ID,
+		UserID:     like.UserID,
+		TargetType: like.TargetType,
+		TargetID:   like.TargetID,
+	}).Error
+}
+
+func (u *ForumRepository) RemoveLike(userID, targetType, targetID string) error {
+	result := u.DB.Unscoped().
+		Where("user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).
+		Delete(&ForumLike{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrForumLikeNotFound
+	}
+	return nil
+}
+
+// Mentions
+func (u *ForumRepository) GetUsersByUsernames(usernames []string) ([]users.User, error) {
+	var result []users.User
+	err := u.DB.Model(&userData.User{}).
+		Select("id, name, username, email").
+		Where("username IN ?", usernames).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (u *ForumRepository) SaveMentions(mentions []forum.ForumMention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	var data []ForumMention
+	for _, mention := range mentions {
+		data = append(data, ForumMention{
+			ID:              uuid.New().String(),
+			ForumID:         mention.ForumID,
+			MessageID:       mention.MessageID,
+			MentionedUserID: mention.MentionedUserID,
+			MentionedByID:   mention.MentionedByID,
+		})
+	}
+	return u.DB.Create(&data).Error
+}
+
+// Moderation
+func (u *ForumRepository) GetForumState(forumID string) (forum.Forum, error) {
+	var forumData forum.Forum
+	err := u.DB.Model(&Forum{}).Select("id, user_id, title, hidden, locked").Where("id = ?", forumID).First(&forumData).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return forum.Forum{}, constant.ErrForumNotFound
+		}
+		return forum.Forum{}, err
+	}
+	return forumData, nil
+}
+
+func (u *ForumRepository) CreateReport(report forum.ForumReport) error {
+	return u.DB.Create(&ForumReport{
+		ID:         report.ID,
+		ReporterID: report.ReporterID,
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		Reason:     report.Reason,
+		Status:     report.Status,
+	}).Error
+}
+
+func (u *ForumRepository) HasPendingReport(reporterID, targetType, targetID string) (bool, error) {
+	var total int64
+	err := u.DB.Model(&ForumReport{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?", reporterID, targetType, targetID, forum.ReportPending).
+		Count(&total).Error
+	if err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+func (u *ForumRepository) GetReports(status string, page int) ([]forum.ForumReport, int, error) {
+	query := func() *gorm.DB {
+		tx := u.DB.Model(&ForumReport{})
+		if status != "" {
+			tx = tx.Where("status = ?", status)
+		}
+		return tx
+	}
+
+	var total int64
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	reportsPerPage := 20
+	totalPages := int((total + int64(reportsPerPage) - 1) / int64(reportsPerPage))
+
+	var data []ForumReport
+	err := query().Preload("Reporter").Order("created_at ASC").Offset((page - 1) * reportsPerPage).Limit(reportsPerPage).Find(&data).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var forumIDs, messageIDs []string
+	for _, report := range data {
+		if report.TargetType == forum.TargetForum {
+			forumIDs = append(forumIDs, report.TargetID)
+		} else {
+			messageIDs = append(messageIDs, report.TargetID)
+		}
+	}
+
+	forums := make(map[string]Forum)
+	if len(forumIDs) > 0 {
+		var targets []Forum
+		if err := u.DB.Preload("User").Where("id IN ?", forumIDs).Find(&targets).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, target := range targets {
+			forums[target.ID] = target
+		}
+	}
+
+	messages := make(map[string]MessageForum)
+	if len(messageIDs) > 0 {
+		var targets []MessageForum
+		if err := u.DB.Preload("User").Where("id IN ?", messageIDs).Find(&targets).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, target := range targets {
+			messages[target.ID] = target
+		}
+	}
+
+	var reports []forum.ForumReport
+	for _, report := range data {
+		result := toForumReportEntity(report)
+		if target, ok := forums[report.TargetID]; ok && report.TargetType == forum.TargetForum {
+			result.TargetTitle = target.Title
+			result.TargetContent = target.Description
+			result.TargetAuthor = target.User.Username
+		}
+		if target, ok := messages[report.TargetID]; ok && report.TargetType == forum.TargetMessage {
+			result.TargetContent = target.Message
+			result.TargetAuthor = target.User.Username
+		}
+		reports = append(reports, result)
+	}
+	return reports, totalPages, nil
+}
+
+func (u *ForumRepository) GetReportByID(reportID string) (forum.ForumReport, error) {
+	var report ForumReport
+	if err := u.DB.Where("id = ?", reportID).First(&report).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return forum.ForumReport{}, constant.ErrReportNotFound
+		}
+		return forum.ForumReport{}, err
+	}
+	return toForumReportEntity(report), nil
+}
+
+// ResolveReports closes every pending report on the target, so one decision
+// clears the whole queue for that post.
+func (u *ForumRepository) ResolveReports(targetType, targetID, status, adminID, resolution string) error {
+	now := time.Now()
+	return u.DB.Model(&ForumReport{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, forum.ReportPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"resolved_by": adminID,
+			"resolved_at": now,
+			"resolution":  resolution,
+		}).Error
+}
+
+func (u *ForumRepository) SetForumHidden(forumID string, hidden bool) error {
+	return u.DB.Model(&Forum{}).Where("id = ?", forumID).Update("hidden", hidden).Error
+}
+
+func (u *ForumRepository) SetForumLocked(forumID string, locked bool) error {
+	return u.DB.Model(&Forum{}).Where("id = ?", forumID).Update("locked", locked).Error
+}
+
+func (u *ForumRepository) SetMessageHidden(messageID string, hidden bool) error {
+	return u.DB.Model(&MessageForum{}).Where("id = ?", messageID).Update("hidden", hidden).Error
+}
+
+func (u *ForumRepository) CreateBan(ban forum.UserBan) error {
+	return u.DB.Create(&UserBan{
+		ID:        ban.ID,
+		UserID:    ban.UserID,
+		Reason:    ban.Reason,
+		ExpiresAt: ban.ExpiresAt,
+		CreatedBy: ban.CreatedBy,
+	}).Error
+}
+
+func (u *ForumRepository) RevokeBan(banID string) error {
+	result := u.DB.Model(&UserBan{}).Where("id = ? AND revoked_at IS NULL", banID).Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrBanNotFound
+	}
+	return nil
+}
+
+func (u *ForumRepository) activeBans(now time.Time) *gorm.DB {
+	return u.DB.Model(&UserBan{}).Where("revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", now)
+}
+
+func (u *ForumRepository) GetActiveBan(userID string, now time.Time) (forum.UserBan, error) {
+	var ban UserBan
+	err := u.activeBans(now).Where("user_id = ?", userID).Order("expires_at IS NULL DESC, expires_at DESC").First(&ban).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return forum.UserBan{}, constant.ErrBanNotFound
+		}
+		return forum.UserBan{}, err
+	}
+	return toUserBanEntity(ban), nil
+}
+
+func (u *ForumRepository) GetActiveBans(now time.Time) ([]forum.UserBan, error) {
+	var data []UserBan
+	if err := u.activeBans(now).Order("created_at DESC").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	var bans []forum.UserBan
+	for _, ban := range data {
+		bans = append(bans, toUserBanEntity(ban))
+	}
+	return bans, nil
+}
+
+func (u *ForumRepository) GetBlockedWords() ([]forum.BlockedWord, error) {
+	var data []BlockedWord
+	if err := u.DB.Order("word ASC").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	var words []forum.BlockedWord
+	for _, word := range data {
+		words = append(words, forum.BlockedWord{ID: word.ID, Word: word.Word, CreatedAt: word.CreatedAt})
+	}
+	return words, nil
+}
+
+func (u *ForumRepository) AddBlockedWord(word forum.BlockedWord) error {
+	var existing int64
+	if err := u.DB.Model(&BlockedWord{}).Where("word = ?", word.Word).Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return constant.ErrBlockedWordExists
+	}
+	return u.DB.Create(&BlockedWord{ID: word.ID, Word: word.Word}).Error
+}
+
+func (u *ForumRepository) RemoveBlockedWord(wordID string) (forum.BlockedWord, error) {
+	var word BlockedWord
+	if err := u.DB.Where("id = ?", wordID).First(&word).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return forum.BlockedWord{}, constant.ErrBlockedWordNotFound
+		}
+		return forum.BlockedWord{}, err
+	}
+
+	// Hard delete so the word can be added again later without hitting the
+	// unique index.
+	if err := u.DB.Unscoped().Delete(&word).Error; err != nil {
+		return forum.BlockedWord{}, err
+	}
+	return forum.BlockedWord{ID: word.ID, Word: word.Word, CreatedAt: word.CreatedAt}, nil
+}
+
+func (u *ForumRepository) CreateModerationLog(log forum.ModerationLog) error {
+	return u.DB.Create(&ModerationLog{
+		ID:         log.ID,
+		ActorID:    log.ActorID,
+		Action:     log.Action,
+		TargetType: log.TargetType,
+		TargetID:   log.TargetID,
+		Details:    log.Details,
+	}).Error
+}
+
+func (u *ForumRepository) GetModerationLogs(page int) ([]forum.ModerationLog, int, error) {
+	var total int64
+	if err := u.DB.Model(&ModerationLog{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	logsPerPage := 20
+	totalPages := int((total + int64(logsPerPage) - 1) / int64(logsPerPage))
+
+	var data []ModerationLog
+	if err := u.DB.Order("created_at DESC").Offset((page - 1) * logsPerPage).Limit(logsPerPage).Find(&data).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []forum.ModerationLog
+	for _, entry := range data {
+		logs = append(logs, forum.ModerationLog{
+			ID:         entry.ID,
+			ActorID:    entry.ActorID,
+			Action:     entry.Action,
+			TargetType: entry.TargetType,
+			TargetID:   entry.TargetID,
+			Details:    entry.Details,
+			CreatedAt:  entry.CreatedAt,
+		})
+	}
+	return logs, totalPages, nil
+}
+
+// Categories
+func (u *ForumRepository) GetCategories() ([]forum.ForumCategory, error) {
+	var data []ForumCategory
+	if err := u.DB.Order("name ASC").Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	var categories []forum.ForumCategory
+	for _, category := range data {
+		categories = append(categories, toForumCategoryEntity(category))
+	}
+	return categories, nil
+}
+
+func (u *ForumRepository) GetCategoryByID(categoryID string) (forum.ForumCategory, error) {
+	var category ForumCategory
+	if err := u.DB.Where("id = ?", categoryID).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return forum.ForumCategory{}, constant.ErrForumCategoryNotFound
+		}
+		return forum.ForumCategory{}, err
+	}
+	return toForumCategoryEntity(category), nil
+}
+
+func (u *ForumRepository) GetCategoryBySlug(slug string) (forum.ForumCategory, error) {
+	var category ForumCategory
+	if err := u.DB.Where("slug = ?", slug).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return forum.ForumCategory{}, constant.ErrForumCategoryNotFound
+		}
+		return forum.ForumCategory{}, err
+	}
+	return toForumCategoryEntity(category), nil
+}
+
+func (u *ForumRepository) CreateCategory(category forum.ForumCategory) error {
+	return u.DB.Create(&ForumCategory{
+		ID:          category.ID,
+		Name:        category.Name,
+		Slug:        category.Slug,
+		Description: category.Description,
+	}).Error
+}
+
+func (u *ForumRepository) UpdateCategory(category forum.ForumCategory) error {
+	return u.DB.Model(&ForumCategory{}).Where("id = ?", category.ID).Updates(map[string]interface{}{
+		"name":        category.Name,
+		"slug":        category.Slug,
+		"description": category.Description,
+	}).Error
+}
+
+// DeleteCategory removes the row for good so the foreign key moves the
+// category's threads to no category.
+func (u *ForumRepository) DeleteCategory(categoryID string) error {
+	result := u.DB.Unscoped().Where("id = ?", categoryID).Delete(&ForumCategory{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrForumCategoryNotFound
+	}
+	return nil
+}
+
+// Tags
+func (u *ForumRepository) GetPopularTags(limit int) ([]forum.TagCount, error) {
+	var tags []forum.TagCount
+	err := u.DB.Model(&ForumTag{}).
+		Select("forum_tags.tag AS tag, COUNT(*) AS count").
+		Joins("JOIN forums ON forums.id = forum_tags.forum_id AND forums.deleted_at IS NULL AND forums.hidden = ?", false).
+		Group("forum_tags.tag").
+		Order("count DESC, tag ASC").
+		Limit(limit).
+		Scan(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetRelatedForums ranks other visible threads by how many tags they share
+// with the given thread, then by being in the same category.
+func (u *ForumRepository) GetRelatedForums(forumID string, limit int) ([]forum.Forum, error) {
+	var related []forum.Forum
+	err := u.DB.Model(&Forum{}).Preload("User").Preload("Category").Preload("Tags").
+		Select("forums.*, "+
+			"(SELECT COUNT(*) FROM forum_tags WHERE forum_tags.forum_id = forums.id AND forum_tags.tag IN (SELECT source_tags.tag FROM forum_tags AS source_tags WHERE source_tags.forum_id = ?)) AS shared_tags, "+
+			"(forums.category_id IS NOT NULL AND forums.category_id = (SELECT source.category_id FROM forums AS source WHERE source.id = ?)) AS same_category",
+			forumID, forumID).
+		Where("forums.id <> ? AND forums.hidden = ? AND forums.deleted_at IS NULL", forumID, false).
+		Having("shared_tags > 0 OR same_category = 1").
+		Order("shared_tags DESC, same_category DESC").
+		Order(lastActivityOrder).
+		Limit(limit).
+		Find(&related).Error
+	if err != nil {
+		return nil, err
+	}
+	return related, nil
+}
+
+// Views
+
+// SaveViews applies a batch of buffered views: the counters are bumped once
+// per thread and each unique view is kept for the trending score.
+func (u *ForumRepository) SaveViews(counts map[string]int, views []forum.ForumView) error {
+	res := u.DB.Begin()
+	for forumID, count := range counts {
+		err := res.Model(&Forum{}).Where("id = ?", forumID).UpdateColumn("view", gorm.Expr("view + ?", count)).Error
+		if err != nil {
+			res.Rollback()
+			return err
+		}
+	}
+
+	if len(views) > 0 {
+		var data []ForumView
+		for _, view := range views {
+			data = append(data, ForumView{
+				ID:        view.ID,
+				ForumID:   view.ForumID,
+				ViewerKey: view.ViewerKey,
+				ViewedAt:  view.ViewedAt,
+			})
+		}
+		if err := res.CreateInBatches(&data, 100).Error; err != nil {
+			res.Rollback()
+			return err
+		}
+	}
+	return res.Commit().Error
+}
+
+// DeleteViewsBefore drops views too old to count towards trending. The
+// counter on the thread keeps the total.
+func (u *ForumRepository) DeleteViewsBefore(before time.Time) error {
+	return u.DB.Unscoped().Where("viewed_at < ?", before).Delete(&ForumView{}).Error
+}
+
+func toForumCategoryEntity(category ForumCategory) forum.ForumCategory {
+	return forum.ForumCategory{
+		ID:          category.ID,
+		Name:        category.Name,
+		Slug:        category.Slug,
+		Description: category.Description,
+	}
+}
+
+func toForumReportEntity(report ForumReport) forum.ForumReport {
+	result := forum.ForumReport{
+		ID:         report.ID,
+		ReporterID: report.ReporterID,
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		Reason:     report.Reason,
+		Status:     report.Status,
+		ResolvedAt: report.ResolvedAt,
+		Resolution: report.Resolution,
+		Reporter: users.User{
+			ID:       report.Reporter.ID,
+			Name:     report.Reporter.Name,
+			Username: report.Reporter.Username,
+			Email:    report.Reporter.Email,
+		},
+	}
+	if report.ResolvedBy != nil {
+		result.ResolvedBy = *report.ResolvedBy
+	}
+	if report.Model != nil {
+		result.CreatedAt = report.CreatedAt
+	}
+	return result
+}
+
+func toUserBanEntity(ban UserBan) forum.UserBan {
+	result := forum.UserBan{
+		ID:        ban.ID,
+		UserID:    ban.UserID,
+		Reason:    ban.Reason,
+		ExpiresAt: ban.ExpiresAt,
+		CreatedBy: ban.CreatedBy,
+		RevokedAt: ban.RevokedAt,
+	}
+	if ban.Model != nil {
+		result.CreatedAt = ban.CreatedAt
+	}
+	return result
+}
@@ -1,19 +1,53 @@
 package service
 
 import (
+	"fmt"
 	"greenenvironment/constant"
 	"greenenvironment/features/forum"
+	"greenenvironment/features/notifications"
 	"greenenvironment/helper"
+	"greenenvironment/utils/realtime"
+	"log"
+	"regexp"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_.\-]+)`)
+
+var linkPattern = regexp.MustCompile(`(?i)https?://|www\.`)
+
+// slugPattern is the shape of both tags and category slugs.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// relatedForumLimit is how many related threads GetForumByID shows.
+const relatedForumLimit = 5
+
+// maxLinksPerPost is the most links a post may carry before it is treated as
+// spam.
+const maxLinksPerPost = 3
+
+// maxMentionsPerPost is the most users one post can notify, so a post cannot
+// be used to ping everyone.
+const maxMentionsPerPost = 10
+
 type ForumService struct {
 	forumRepo forum.ForumRepositoryInterface
+	notifier  notifications.Notifier
+	publisher realtime.Publisher
+	views     *viewBuffer
 }
 
-func NewForumService(data forum.ForumRepositoryInterface) forum.ForumServiceInterface {
+func NewForumService(data forum.ForumRepositoryInterface, notifier notifications.Notifier, publisher realtime.Publisher) forum.ForumServiceInterface {
 	return &ForumService{
 		forumRepo: data,
+		notifier:  notifier,
+		publisher: publisher,
+		views:     newViewBuffer(forum.ViewWindow),
 	}
 }
 
@@ -21,15 +55,43 @@ func (s *ForumService) GetAllForum() ([]forum.Forum, error) {
 	return s.forumRepo.GetAllForum()
 }
 
-func (s *ForumService) GetAllByPage(page int) ([]forum.ForumGetAll, int, error) {
-	return s.forumRepo.GetAllByPage(page)
+func (s *ForumService) GetAllByPage(filter forum.ForumFilter) ([]forum.ForumGetAll, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	filter.Search = strings.TrimSpace(filter.Search)
+	filter.Category = strings.ToLower(strings.TrimSpace(filter.Category))
+	filter.Tag = normalizeTag(filter.Tag)
+
+	if filter.Sort == "" {
+		filter.Sort = forum.SortLatest
+		if filter.Search != "" {
+			filter.Sort = forum.SortRelevance
+		}
+	}
+	switch filter.Sort {
+	case forum.SortLatest, forum.SortMostLiked, forum.SortTrending:
+	case forum.SortRelevance:
+		if filter.Search == "" {
+			return nil, 0, constant.ErrInvalidForumSort
+		}
+	default:
+		return nil, 0, constant.ErrInvalidForumSort
+	}
+	return s.forumRepo.GetAllByPage(filter)
 }
 
 func (s *ForumService) GetForumByID(ID string) (forum.Forum, error) {
 	if ID == "" {
 		return forum.Forum{}, constant.ErrFieldData
 	}
-	return s.forumRepo.GetForumByID(ID)
+
+	data, err := s.forumRepo.GetForumByID(ID)
+	if err != nil {
+		return forum.Forum{}, err
+	}
+	data.View += s.views.pending(ID)
+	return data, nil
 }
 
 func (s *ForumService) PostForum(forum forum.Forum) error {
@@ -39,7 +101,35 @@ func (s *ForumService) PostForum(forum forum.Forum) error {
 	if !helper.IsValidInput(forum.Title) || !helper.IsValidInput(forum.Description) {
 		return constant.ErrFieldData
 	}
-	return s.forumRepo.PostForum(forum)
+	if err := s.checkNotBanned(forum.UserID); err != nil {
+		return err
+	}
+
+	var names []string
+	for _, tag := range forum.Tags {
+		names = append(names, tag.Tag)
+	}
+	tags, err := normalizeTags(names)
+	if err != nil {
+		return err
+	}
+	forum.Tags = nil
+	for _, tag := range tags {
+		forum.Tags = append(forum.Tags, forumTag(forum.ID, tag))
+	}
+
+	if forum.CategoryID, err = s.checkCategory(forum.CategoryID); err != nil {
+		return err
+	}
+	if err := s.filterContent(forum.UserID, forum.Title, forum.Description, strings.Join(tags, " ")); err != nil {
+		return err
+	}
+	if err := s.forumRepo.PostForum(forum); err != nil {
+		return err
+	}
+
+	s.notifyMentions(forum.Description, forum.UserID, forum, nil)
+	return nil
 }
 
 func (s *ForumService) UpdateForum(forum forum.EditForum) error {
@@ -53,6 +143,29 @@ func (s *ForumService) UpdateForum(forum forum.EditForum) error {
 	if !helper.IsValidInput(forum.Title) || !helper.IsValidInput(forum.Description) {
 		return constant.ErrFieldData
 	}
+
+	if err := s.checkNotBanned(forum.UserID); err != nil {
+		return err
+	}
+	if _, err := s.checkThreadOpen(forum.ID); err != nil {
+		return err
+	}
+
+	if forum.Tags != nil {
+		tags, err := normalizeTags(forum.Tags)
+		if err != nil {
+			return err
+		}
+		forum.Tags = tags
+	}
+
+	var err error
+	if forum.CategoryID, err = s.checkCategory(forum.CategoryID); err != nil {
+		return err
+	}
+	if err := s.filterContent(forum.UserID, forum.Title, forum.Description, strings.Join(forum.Tags, " ")); err != nil {
+		return err
+	}
 	return s.forumRepo.UpdateForum(forum)
 }
 
@@ -71,7 +184,69 @@ func (s *ForumService) PostMessageForum(messageForum forum.MessageForum) error {
 	if !helper.IsValidInput(messageForum.Message) {
 		return constant.ErrFieldData
 	}
-	return s.forumRepo.PostMessageForum(messageForum)
+
+	if err := s.checkNotBanned(messageForum.UserID); err != nil {
+		return err
+	}
+	thread, err := s.checkThreadOpen(messageForum.ForumID)
+	if err != nil {
+		return err
+	}
+
+	if messageForum.ParentID != nil && *messageForum.ParentID == "" {
+		messageForum.ParentID = nil
+	}
+	// A reply notifies the author of the message it answers, anything else
+	// the owner of the thread.
+	recipientID := thread.UserID
+	if messageForum.ParentID != nil {
+		parent, err := s.forumRepo.GetMessageForumByID(*messageForum.ParentID)
+		if err != nil || parent.ForumID != messageForum.ForumID {
+			return constant.ErrInvalidParentMessage
+		}
+		recipientID = parent.UserID
+	}
+
+	if err := s.filterContent(messageForum.UserID, messageForum.Message); err != nil {
+		return err
+	}
+
+	if err := s.forumRepo.PostMessageForum(messageForum); err != nil {
+		return err
+	}
+
+	s.publisher.Publish(realtime.ForumTopic(messageForum.ForumID), realtime.Event{
+		Type: realtime.EventForumMessage,
+		Data: map[string]interface{}{
+			"id":        messageForum.ID,
+			"forum_id":  messageForum.ForumID,
+			"parent_id": messageForum.ParentID,
+			"user_id":   messageForum.UserID,
+			"message":   messageForum.Message,
+		},
+	})
+
+	messageID := messageForum.ID
+	s.notifyMentions(messageForum.Message, messageForum.UserID, thread, &messageID)
+	s.notifyReply(recipientID, messageForum.UserID, thread)
+	return nil
+}
+
+func (s *ForumService) notifyReply(recipientID, authorID string, thread forum.Forum) {
+	if recipientID == "" || recipientID == authorID {
+		return
+	}
+
+	err := s.notifier.Notify(notifications.Notification{
+		UserID:  recipientID,
+		Type:    notifications.TypeForumReply,
+		Title:   "New reply in the forum",
+		Message: fmt.Sprintf("Someone replied to you in \"%s\".", thread.Title),
+		Link:    "/forums/" + thread.ID,
+	})
+	if err != nil {
+		log.Printf("failed to notify %s about a reply in forum %s: %v", recipientID, thread.ID, err)
+	}
 }
 
 func (s *ForumService) DeleteMessageForum(productId string) error {
@@ -96,6 +271,21 @@ func (s *ForumService) UpdateMessageForum(message forum.EditMessage) error {
 	if !helper.IsValidInput(message.Message) {
 		return constant.ErrFieldData
 	}
+
+	if err := s.checkNotBanned(message.UserID); err != nil {
+		return err
+	}
+	existing, err := s.forumRepo.GetMessageForumByID(message.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.checkThreadOpen(existing.ForumID); err != nil {
+		return err
+	}
+
+	if err := s.filterContent(message.UserID, message.Message); err != nil {
+		return err
+	}
 	return s.forumRepo.UpdateMessageForum(message)
 }
 
@@ -103,6 +293,10 @@ func (s *ForumService) GetForumByUserID(ID string, page int) ([]forum.Forum, int
 	return s.forumRepo.GetForumByUserID(ID, page)
 }
 
+func (s *ForumService) GetVisibleForumByUserID(ID string, page int) ([]forum.Forum, int, error) {
+	return s.forumRepo.GetVisibleForumByUserID(ID, page)
+}
+
 func (s *ForumService) GetMessagesByForumID(forumID string) ([]forum.MessageForum, error) {
 	return s.forumRepo.GetMessagesByForumID(forumID)
 }
@@ -110,3 +304,511 @@ func (s *ForumService) GetMessagesByForumID(forumID string) ([]forum.MessageForu
 func (s *ForumService) GetMessagesByForumIDWithPagination(forumID string, page int, pageSize int) ([]forum.MessageForum, error) {
 	return s.forumRepo.GetMessagesByForumIDWithPagination(forumID, page, pageSize)
 }
+
+func (s *ForumService) LikeForum(forumID, userID string) error {
+	return s.forumRepo.AddLike(forum.ForumLike{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		TargetType: forum.TargetForum,
+		TargetID:   forumID,
+	})
+}
+
+func (s *ForumService) UnlikeForum(forumID, userID string) error {
+	return s.forumRepo.RemoveLike(userID, forum.TargetForum, forumID)
+}
+
+func (s *ForumService) LikeMessage(messageID, userID string) error {
+	return s.forumRepo.AddLike(forum.ForumLike{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		TargetType: forum.TargetMessage,
+		TargetID:   messageID,
+	})
+}
+
+func (s *ForumService) UnlikeMessage(messageID, userID string) error {
+	return s.forumRepo.RemoveLike(userID, forum.TargetMessage, messageID)
+}
+
+func (s *ForumService) ReportContent(report forum.ForumReport) error {
+	report.Reason = strings.TrimSpace(report.Reason)
+	if report.TargetID == "" || report.Reason == "" {
+		return constant.ErrReportField
+	}
+
+	switch report.TargetType {
+	case forum.TargetForum:
+		thread, err := s.forumRepo.GetForumState(report.TargetID)
+		if err != nil {
+			return err
+		}
+		if thread.Hidden {
+			return constant.ErrForumNotFound
+		}
+	case forum.TargetMessage:
+		if _, err := s.forumRepo.GetMessageForumByID(report.TargetID); err != nil {
+			return constant.ErrForumMessageNotFound
+		}
+	default:
+		return constant.ErrReportField
+	}
+
+	pending, err := s.forumRepo.HasPendingReport(report.ReporterID, report.TargetType, report.TargetID)
+	if err != nil {
+		return err
+	}
+	if pending {
+		return constant.ErrReportExists
+	}
+
+	report.ID = uuid.New().String()
+	report.Status = forum.ReportPending
+	return s.forumRepo.CreateReport(report)
+}
+
+func (s *ForumService) GetReports(status string, page int) ([]forum.ForumReport, int, error) {
+	if status != "" && status != forum.ReportPending && status != forum.ReportResolved && status != forum.ReportDismissed {
+		return nil, 0, constant.ErrInvalidReportStatus
+	}
+	if page < 1 {
+		page = 1
+	}
+	return s.forumRepo.GetReports(status, page)
+}
+
+// ResolveReport closes a report, and with it every other pending report on
+// the same content. Dismiss leaves the content as it is, hide hides it and
+// lock (threads only) stops new replies.
+func (s *ForumService) ResolveReport(reportID, adminID, action, note string) error {
+	report, err := s.forumRepo.GetReportByID(reportID)
+	if err != nil {
+		return err
+	}
+	if report.Status != forum.ReportPending {
+		return constant.ErrReportNotPending
+	}
+
+	status := forum.ReportResolved
+	switch {
+	case action == forum.ActionDismiss:
+		status = forum.ReportDismissed
+	case action == forum.ActionHide && report.TargetType == forum.TargetForum:
+		err = s.forumRepo.SetForumHidden(report.TargetID, true)
+	case action == forum.ActionHide && report.TargetType == forum.TargetMessage:
+		err = s.forumRepo.SetMessageHidden(report.TargetID, true)
+	case action == forum.ActionLock && report.TargetType == forum.TargetForum:
+		err = s.forumRepo.SetForumLocked(report.TargetID, true)
+	default:
+		return constant.ErrInvalidModerationAction
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.forumRepo.ResolveReports(report.TargetType, report.TargetID, status, adminID, note); err != nil {
+		return err
+	}
+
+	s.logModeration(adminID, action, report.TargetType, report.TargetID, fmt.Sprintf("report %s: %s", report.ID, note))
+	return nil
+}
+
+func (s *ForumService) ModerateForum(forumID, adminID, action string) error {
+	if _, err := s.forumRepo.GetForumState(forumID); err != nil {
+		return err
+	}
+
+	var err error
+	switch action {
+	case forum.ActionHide:
+		err = s.forumRepo.SetForumHidden(forumID, true)
+	case forum.ActionUnhide:
+		err = s.forumRepo.SetForumHidden(forumID, false)
+	case forum.ActionLock:
+		err = s.forumRepo.SetForumLocked(forumID, true)
+	case forum.ActionUnlock:
+		err = s.forumRepo.SetForumLocked(forumID, false)
+	default:
+		return constant.ErrInvalidModerationAction
+	}
+	if err != nil {
+		return err
+	}
+
+	s.logModeration(adminID, action, forum.TargetForum, forumID, "")
+	return nil
+}
+
+func (s *ForumService) ModerateMessage(messageID, adminID, action string) error {
+	if action != forum.ActionHide && action != forum.ActionUnhide {
+		return constant.ErrInvalidModerationAction
+	}
+	if _, err := s.forumRepo.GetMessageForumByID(messageID); err != nil {
+		return constant.ErrForumMessageNotFound
+	}
+
+	if err := s.forumRepo.SetMessageHidden(messageID, action == forum.ActionHide); err != nil {
+		return err
+	}
+
+	s.logModeration(adminID, action, forum.TargetMessage, messageID, "")
+	return nil
+}
+
+func (s *ForumService) BanUser(ban forum.UserBan) (forum.UserBan, error) {
+	ban.Reason = strings.TrimSpace(ban.Reason)
+	if ban.UserID == "" || ban.Reason == "" {
+		return forum.UserBan{}, constant.ErrBanField
+	}
+	if ban.ExpiresAt != nil && !ban.ExpiresAt.After(time.Now()) {
+		return forum.UserBan{}, constant.ErrBanField
+	}
+
+	ban.ID = uuid.New().String()
+	ban.CreatedAt = time.Now()
+	if err := s.forumRepo.CreateBan(ban); err != nil {
+		return forum.UserBan{}, err
+	}
+
+	details := "permanent: " + ban.Reason
+	if ban.ExpiresAt != nil {
+		details = fmt.Sprintf("until %s: %s", ban.ExpiresAt.Format(time.RFC3339), ban.Reason)
+	}
+	s.logModeration(ban.CreatedBy, forum.ActionBan, "user", ban.UserID, details)
+	return ban, nil
+}
+
+func (s *ForumService) RevokeBan(banID, adminID string) error {
+	if err := s.forumRepo.RevokeBan(banID); err != nil {
+		return err
+	}
+
+	s.logModeration(adminID, forum.ActionRevokeBan, "ban", banID, "")
+	return nil
+}
+
+func (s *ForumService) GetActiveBans() ([]forum.UserBan, error) {
+	return s.forumRepo.GetActiveBans(time.Now())
+}
+
+func (s *ForumService) GetBlockedWords() ([]forum.BlockedWord, error) {
+	return s.forumRepo.GetBlockedWords()
+}
+
+func (s *ForumService) AddBlockedWord(word, adminID string) (forum.BlockedWord, error) {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" {
+		return forum.BlockedWord{}, constant.ErrBlockedWordField
+	}
+
+	blocked := forum.BlockedWord{
+		ID:        uuid.New().String(),
+		Word:      word,
+		CreatedAt: time.Now(),
+	}
+	if err := s.forumRepo.AddBlockedWord(blocked); err != nil {
+		return forum.BlockedWord{}, err
+	}
+
+	s.logModeration(adminID, forum.ActionAddWord, "blocked_word", blocked.ID, word)
+	return blocked, nil
+}
+
+func (s *ForumService) RemoveBlockedWord(wordID, adminID string) error {
+	removed, err := s.forumRepo.RemoveBlockedWord(wordID)
+	if err != nil {
+		return err
+	}
+
+	s.logModeration(adminID, forum.ActionRemoveWord, "blocked_word", wordID, removed.Word)
+	return nil
+}
+
+func (s *ForumService) GetModerationLogs(page int) ([]forum.ModerationLog, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	return s.forumRepo.GetModerationLogs(page)
+}
+
+func (s *ForumService) GetCategories() ([]forum.ForumCategory, error) {
+	return s.forumRepo.GetCategories()
+}
+
+func (s *ForumService) CreateCategory(category forum.ForumCategory) (forum.ForumCategory, error) {
+	category, err := s.validateCategory(category)
+	if err != nil {
+		return forum.ForumCategory{}, err
+	}
+
+	category.ID = uuid.New().String()
+	if err := s.forumRepo.CreateCategory(category); err != nil {
+		return forum.ForumCategory{}, err
+	}
+	return category, nil
+}
+
+func (s *ForumService) UpdateCategory(category forum.ForumCategory) (forum.ForumCategory, error) {
+	if _, err := s.forumRepo.GetCategoryByID(category.ID); err != nil {
+		return forum.ForumCategory{}, err
+	}
+
+	category, err := s.validateCategory(category)
+	if err != nil {
+		return forum.ForumCategory{}, err
+	}
+
+	if err := s.forumRepo.UpdateCategory(category); err != nil {
+		return forum.ForumCategory{}, err
+	}
+	return category, nil
+}
+
+func (s *ForumService) DeleteCategory(categoryID string) error {
+	return s.forumRepo.DeleteCategory(categoryID)
+}
+
+func (s *ForumService) GetPopularTags(limit int) ([]forum.TagCount, error) {
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+	return s.forumRepo.GetPopularTags(limit)
+}
+
+func (s *ForumService) GetRelatedForums(forumID string) ([]forum.Forum, error) {
+	return s.forumRepo.GetRelatedForums(forumID, relatedForumLimit)
+}
+
+// RecordView counts a unique view of the thread. The count is buffered until
+// the next FlushViews.
+func (s *ForumService) RecordView(forumID, viewerKey string) {
+	if forumID == "" || viewerKey == "" {
+		return
+	}
+	s.views.record(forumID, viewerKey, time.Now())
+}
+
+// FlushViews writes the buffered views and drops views that no longer count
+// towards trending. It runs on a schedule.
+func (s *ForumService) FlushViews() error {
+	now := time.Now()
+	counts, views := s.views.drain(now)
+	if len(counts) > 0 {
+		if err := s.forumRepo.SaveViews(counts, views); err != nil {
+			s.views.restore(counts, views)
+			return err
+		}
+	}
+	return s.forumRepo.DeleteViewsBefore(now.Add(-forum.TrendingWindow))
+}
+
+// validateCategory trims the category, derives the slug from the name when
+// none is given and makes sure no other category uses the slug.
+func (s *ForumService) validateCategory(category forum.ForumCategory) (forum.ForumCategory, error) {
+	category.Name = strings.TrimSpace(category.Name)
+	category.Description = strings.TrimSpace(category.Description)
+	category.Slug = slugify(category.Slug)
+	if category.Slug == "" {
+		category.Slug = slugify(category.Name)
+	}
+	if category.Name == "" || category.Slug == "" {
+		return forum.ForumCategory{}, constant.ErrForumCategoryField
+	}
+
+	existing, err := s.forumRepo.GetCategoryBySlug(category.Slug)
+	if err == nil && existing.ID != category.ID {
+		return forum.ForumCategory{}, constant.ErrForumCategoryExists
+	}
+	if err != nil && err != constant.ErrForumCategoryNotFound {
+		return forum.ForumCategory{}, err
+	}
+	return category, nil
+}
+
+// checkCategory treats an empty category as none and makes sure any other
+// category exists.
+func (s *ForumService) checkCategory(categoryID *string) (*string, error) {
+	if categoryID == nil || *categoryID == "" {
+		return nil, nil
+	}
+	if _, err := s.forumRepo.GetCategoryByID(*categoryID); err != nil {
+		return nil, err
+	}
+	return categoryID, nil
+}
+
+func (s *ForumService) checkNotBanned(userID string) error {
+	_, err := s.forumRepo.GetActiveBan(userID, time.Now())
+	if err == nil {
+		return constant.ErrUserBanned
+	}
+	if err != constant.ErrBanNotFound {
+		return err
+	}
+	return nil
+}
+
+// checkThreadOpen rejects posting to or editing in a hidden or locked
+// thread. Hidden threads look like they do not exist.
+func (s *ForumService) checkThreadOpen(forumID string) (forum.Forum, error) {
+	thread, err := s.forumRepo.GetForumState(forumID)
+	if err != nil {
+		return forum.Forum{}, err
+	}
+	if thread.Hidden {
+		return forum.Forum{}, constant.ErrForumNotFound
+	}
+	if thread.Locked {
+		return forum.Forum{}, constant.ErrForumLocked
+	}
+	return thread, nil
+}
+
+// filterContent rejects posts that contain a blocked word or too many links.
+// Blocked posts are recorded in the moderation log under the system actor.
+func (s *ForumService) filterContent(authorID string, texts ...string) error {
+	words, err := s.forumRepo.GetBlockedWords()
+	if err != nil {
+		return err
+	}
+
+	content := strings.Join(texts, "\n")
+	for _, word := range words {
+		pattern, err := regexp.Compile(`(?i)\b` + regexp.QuoteMeta(word.Word) + `\b`)
+		if err != nil {
+			continue
+		}
+		if pattern.MatchString(content) {
+			s.logModeration(forum.SystemActor, forum.ActionFilterBlocked, "user", authorID, "blocked word: "+word.Word)
+			return constant.ErrContentBlocked
+		}
+	}
+
+	if len(linkPattern.FindAllStringIndex(content, -1)) > maxLinksPerPost {
+		s.logModeration(forum.SystemActor, forum.ActionFilterBlocked, "user", authorID, "too many links")
+		return constant.ErrContentSpam
+	}
+	return nil
+}
+
+// logModeration writes to the audit log. The action has already happened, so
+// a failure here is only logged.
+func (s *ForumService) logModeration(actorID, action, targetType, targetID, details string) {
+	entry := forum.ModerationLog{
+		ID:         uuid.New().String(),
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Details:    details,
+	}
+	if err := s.forumRepo.CreateModerationLog(entry); err != nil {
+		log.Printf("failed to write moderation log for %s %s: %v", action, targetID, err)
+	}
+}
+
+// notifyMentions records the first maxMentionsPerPost @usernames in the
+// text and notifies the mentioned users. The post is already saved at this
+// point, so failures are only logged.
+func (s *ForumService) notifyMentions(text, authorID string, thread forum.Forum, messageID *string) {
+	usernames := extractMentions(text)
+	if len(usernames) == 0 {
+		return
+	}
+	if len(usernames) > maxMentionsPerPost {
+		usernames = usernames[:maxMentionsPerPost]
+	}
+
+	mentioned, err := s.forumRepo.GetUsersByUsernames(usernames)
+	if err != nil {
+		log.Printf("failed to look up mentioned users in forum %s: %v", thread.ID, err)
+		return
+	}
+
+	var mentions []forum.ForumMention
+	for _, user := range mentioned {
+		if user.ID == authorID {
+			continue
+		}
+		mentions = append(mentions, forum.ForumMention{
+			ForumID:         thread.ID,
+			MessageID:       messageID,
+			MentionedUserID: user.ID,
+			MentionedByID:   authorID,
+		})
+
+		err := s.notifier.Notify(notifications.Notification{
+			UserID:  user.ID,
+			Type:    notifications.TypeForumMention,
+			Title:   "You were mentioned in the forum",
+			Message: fmt.Sprintf("Someone mentioned you in \"%s\".", thread.Title),
+			Link:    "/forums/" + thread.ID,
+		})
+		if err != nil {
+			log.Printf("failed to notify %s about a mention in forum %s: %v", user.ID, thread.ID, err)
+		}
+	}
+
+	if err := s.forumRepo.SaveMentions(mentions); err != nil {
+		log.Printf("failed to save mentions in forum %s: %v", thread.ID, err)
+	}
+}
+
+func forumTag(forumID, tag string) forum.ForumTag {
+	return forum.ForumTag{
+		ID:      uuid.New().String(),
+		ForumID: forumID,
+		Tag:     tag,
+	}
+}
+
+// normalizeTags lowercases and dedupes tags. A nil input stays nil so
+// callers can tell "no change" from "remove all tags".
+func normalizeTags(raw []string) ([]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, tag := range raw {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > forum.MaxTagLength || !slugPattern.MatchString(tag) {
+			return nil, constant.ErrInvalidForumTags
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > forum.MaxTagsPerForum {
+		return nil, constant.ErrInvalidForumTags
+	}
+	return tags, nil
+}
+
+// normalizeTag turns "#Zero Waste" into "zero-waste".
+func normalizeTag(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	return strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+}
+
+func slugify(text string) string {
+	return strings.Trim(nonSlugCharacters.ReplaceAllString(strings.ToLower(text), "-"), "-")
+}
+
+func extractMentions(text string) []string {
+	seen := make(map[string]bool)
+	var usernames []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		username := strings.TrimRight(match[1], ".-")
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
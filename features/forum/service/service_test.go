@@ -2,9 +2,15 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"greenenvironment/constant"
 	"greenenvironment/features/forum"
+	"greenenvironment/features/notifications"
+	"greenenvironment/features/users"
+	"greenenvironment/utils/realtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -20,8 +26,8 @@ func (m *MockForumRepository) GetAllForum() ([]forum.Forum, error) {
 	return args.Get(0).([]forum.Forum), args.Error(1)
 }
 
-func (m *MockForumRepository) GetAllByPage(page int) ([]forum.ForumGetAll, int, error) {
-	args := m.Called(page)
+func (m *MockForumRepository) GetAllByPage(filter forum.ForumFilter) ([]forum.ForumGetAll, int, error) {
+	args := m.Called(filter)
 	return args.Get(0).([]forum.ForumGetAll), args.Int(1), args.Error(2)
 }
 
@@ -70,6 +76,11 @@ func (m *MockForumRepository) GetForumByUserID(ID string, page int) ([]forum.For
 	return args.Get(0).([]forum.Forum), args.Int(1), args.Error(2)
 }
 
+func (m *MockForumRepository) GetVisibleForumByUserID(ID string, page int) ([]forum.Forum, int, error) {
+	args := m.Called(ID, page)
+	return args.Get(0).([]forum.Forum), args.Int(1), args.Error(2)
+}
+
 func (m *MockForumRepository) GetMessagesByForumID(forumID string) ([]forum.MessageForum, error) {
 	args := m.Called(forumID)
 	return args.Get(0).([]forum.MessageForum), args.Error(1)
@@ -80,11 +91,203 @@ func (m *MockForumRepository) GetMessagesByForumIDWithPagination(forumID string,
 	return args.Get(0).([]forum.MessageForum), args.Error(1)
 }
 
+func (m *MockForumRepository) AddLike(like forum.ForumLike) error {
+	args := m.Called(like)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) RemoveLike(userID, targetType, targetID string) error {
+	args := m.Called(userID, targetType, targetID)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) GetUsersByUsernames(usernames []string) ([]users.User, error) {
+	args := m.Called(usernames)
+	return args.Get(0).([]users.User), args.Error(1)
+}
+
+func (m *MockForumRepository) SaveMentions(mentions []forum.ForumMention) error {
+	args := m.Called(mentions)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) GetForumState(forumID string) (forum.Forum, error) {
+	args := m.Called(forumID)
+	return args.Get(0).(forum.Forum), args.Error(1)
+}
+
+func (m *MockForumRepository) CreateReport(report forum.ForumReport) error {
+	args := m.Called(report)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) HasPendingReport(reporterID, targetType, targetID string) (bool, error) {
+	args := m.Called(reporterID, targetType, targetID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockForumRepository) GetReports(status string, page int) ([]forum.ForumReport, int, error) {
+	args := m.Called(status, page)
+	return args.Get(0).([]forum.ForumReport), args.Int(1), args.Error(2)
+}
+
+func (m *MockForumRepository) GetReportByID(reportID string) (forum.ForumReport, error) {
+	args := m.Called(reportID)
+	return args.Get(0).(forum.ForumReport), args.Error(1)
+}
+
+func (m *MockForumRepository) ResolveReports(targetType, targetID, status, adminID, resolution string) error {
+	args := m.Called(targetType, targetID, status, adminID, resolution)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) SetForumHidden(forumID string, hidden bool) error {
+	args := m.Called(forumID, hidden)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) SetForumLocked(forumID string, locked bool) error {
+	args := m.Called(forumID, locked)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) SetMessageHidden(messageID string, hidden bool) error {
+	args := m.Called(messageID, hidden)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) CreateBan(ban forum.UserBan) error {
+	args := m.Called(ban)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) RevokeBan(banID string) error {
+	args := m.Called(banID)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) GetActiveBan(userID string, now time.Time) (forum.UserBan, error) {
+	args := m.Called(userID, now)
+	return args.Get(0).(forum.UserBan), args.Error(1)
+}
+
+func (m *MockForumRepository) GetActiveBans(now time.Time) ([]forum.UserBan, error) {
+	args := m.Called(now)
+	return args.Get(0).([]forum.UserBan), args.Error(1)
+}
+
+func (m *MockForumRepository) GetBlockedWords() ([]forum.BlockedWord, error) {
+	args := m.Called()
+	return args.Get(0).([]forum.BlockedWord), args.Error(1)
+}
+
+func (m *MockForumRepository) AddBlockedWord(word forum.BlockedWord) error {
+	args := m.Called(word)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) RemoveBlockedWord(wordID string) (forum.BlockedWord, error) {
+	args := m.Called(wordID)
+	return args.Get(0).(forum.BlockedWord), args.Error(1)
+}
+
+func (m *MockForumRepository) CreateModerationLog(log forum.ModerationLog) error {
+	args := m.Called(log)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) GetModerationLogs(page int) ([]forum.ModerationLog, int, error) {
+	args := m.Called(page)
+	return args.Get(0).([]forum.ModerationLog), args.Int(1), args.Error(2)
+}
+
+func (m *MockForumRepository) GetCategories() ([]forum.ForumCategory, error) {
+	args := m.Called()
+	return args.Get(0).([]forum.ForumCategory), args.Error(1)
+}
+
+func (m *MockForumRepository) GetCategoryByID(categoryID string) (forum.ForumCategory, error) {
+	args := m.Called(categoryID)
+	return args.Get(0).(forum.ForumCategory), args.Error(1)
+}
+
+func (m *MockForumRepository) GetCategoryBySlug(slug string) (forum.ForumCategory, error) {
+	args := m.Called(slug)
+	return args.Get(0).(forum.ForumCategory), args.Error(1)
+}
+
+func (m *MockForumRepository) CreateCategory(category forum.ForumCategory) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) UpdateCategory(category forum.ForumCategory) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) DeleteCategory(categoryID string) error {
+	args := m.Called(categoryID)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) GetPopularTags(limit int) ([]forum.TagCount, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]forum.TagCount), args.Error(1)
+}
+
+func (m *MockForumRepository) GetRelatedForums(forumID string, limit int) ([]forum.Forum, error) {
+	args := m.Called(forumID, limit)
+	return args.Get(0).([]forum.Forum), args.Error(1)
+}
+
+func (m *MockForumRepository) SaveViews(counts map[string]int, views []forum.ForumView) error {
+	args := m.Called(counts, views)
+	return args.Error(0)
+}
+
+func (m *MockForumRepository) DeleteViewsBefore(before time.Time) error {
+	args := m.Called(before)
+	return args.Error(0)
+}
+
+// allowPosting stubs the ban, lock and content filter checks so a post goes
+// through to the repository.
+func allowPosting(m *MockForumRepository) {
+	m.On("GetActiveBan", mock.Anything, mock.Anything).Return(forum.UserBan{}, constant.ErrBanNotFound).Maybe()
+	m.On("GetForumState", mock.Anything).Return(forum.Forum{ID: "1"}, nil).Maybe()
+	m.On("GetBlockedWords").Return([]forum.BlockedWord{}, nil).Maybe()
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(notification notifications.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+type MockPublisher struct {
+	mock.Mock
+}
+
+func (m *MockPublisher) Publish(topic string, event realtime.Event) {
+	m.Called(topic, event)
+}
+
+// newMockPublisher accepts any event, for tests that do not check them.
+func newMockPublisher() *MockPublisher {
+	publisher := new(MockPublisher)
+	publisher.On("Publish", mock.Anything, mock.Anything).Return().Maybe()
+	return publisher
+}
+
 func TestGetAllForum(t *testing.T) {
 	mockRepo := new(MockForumRepository)
 	mockRepo.On("GetAllForum").Return([]forum.Forum{}, nil)
 
-	service := NewForumService(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
 	forums, err := service.GetAllForum()
 
 	assert.NoError(t, err)
@@ -96,7 +299,7 @@ func TestGetForumByID(t *testing.T) {
 	mockRepo := new(MockForumRepository)
 	mockRepo.On("GetForumByID", "1").Return(forum.Forum{ID: "1"}, nil)
 
-	service := NewForumService(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
 	forum, err := service.GetForumByID("1")
 
 	assert.NoError(t, err)
@@ -106,10 +309,11 @@ func TestGetForumByID(t *testing.T) {
 
 func TestPostForum(t *testing.T) {
 	mockRepo := new(MockForumRepository)
+	allowPosting(mockRepo)
 	mockForum := forum.Forum{Title: "Test", Description: "Test Description"}
 	mockRepo.On("PostForum", mockForum).Return(nil)
 
-	service := NewForumService(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
 	err := service.PostForum(mockForum)
 
 	assert.NoError(t, err)
@@ -118,21 +322,55 @@ func TestPostForum(t *testing.T) {
 
 func TestUpdateForum(t *testing.T) {
 	mockRepo := new(MockForumRepository)
+	allowPosting(mockRepo)
 	mockForum := forum.EditForum{ID: "1", Title: "Updated Title", Description: "Updated Description"}
 	mockRepo.On("UpdateForum", mockForum).Return(nil)
 
-	service := NewForumService(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
 	err := service.UpdateForum(mockForum)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUpdateForum_ClosedThreadsAndBannedUsers(t *testing.T) {
+	edit := forum.EditForum{ID: "1", UserID: "user1", Title: "Updated Title", Description: "Updated Description"}
+
+	t.Run("banned", func(t *testing.T) {
+		mockRepo := new(MockForumRepository)
+		mockRepo.On("GetActiveBan", "user1", mock.Anything).Return(forum.UserBan{ID: "ban1"}, nil)
+		service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+		assert.Equal(t, constant.ErrUserBanned, service.UpdateForum(edit))
+		mockRepo.AssertNotCalled(t, "UpdateForum", mock.Anything)
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		mockRepo := new(MockForumRepository)
+		mockRepo.On("GetActiveBan", "user1", mock.Anything).Return(forum.UserBan{}, constant.ErrBanNotFound)
+		mockRepo.On("GetForumState", "1").Return(forum.Forum{ID: "1", Locked: true}, nil)
+		service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+		assert.Equal(t, constant.ErrForumLocked, service.UpdateForum(edit))
+		mockRepo.AssertNotCalled(t, "UpdateForum", mock.Anything)
+	})
+
+	t.Run("hidden", func(t *testing.T) {
+		mockRepo := new(MockForumRepository)
+		mockRepo.On("GetActiveBan", "user1", mock.Anything).Return(forum.UserBan{}, constant.ErrBanNotFound)
+		mockRepo.On("GetForumState", "1").Return(forum.Forum{ID: "1", Hidden: true}, nil)
+		service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+		assert.Equal(t, constant.ErrForumNotFound, service.UpdateForum(edit))
+		mockRepo.AssertNotCalled(t, "UpdateForum", mock.Anything)
+	})
+}
+
 func TestDeleteForum(t *testing.T) {
 	mockRepo := new(MockForumRepository)
 	mockRepo.On("DeleteForum", "1").Return(nil)
 
-	service := NewForumService(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
 	err := service.DeleteForum("1")
 
 	assert.NoError(t, err)
@@ -141,14 +379,14 @@ func TestDeleteForum(t *testing.T) {
 
 func TestGetAllByPage(t *testing.T) {
 	mockRepo := new(MockForumRepository)
-	service := NewForumService(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
 
 	expectedForums := []forum.ForumGetAll{{ID: "1", Title: "Forum 1"}, {ID: "2", Title: "Forum 2"}}
 
 	// Positive case
-	mockRepo.On("GetAllByPage", 1).Return(expectedForums, 2, nil)
+	mockRepo.On("GetAllByPage", forum.ForumFilter{Page: 1, Sort: forum.SortLatest}).Return(expectedForums, 2, nil)
 
-	result, totalPages, err := service.GetAllByPage(1)
+	result, totalPages, err := service.GetAllByPage(forum.ForumFilter{Page: 1})
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedForums, result)
@@ -158,7 +396,8 @@ func TestGetAllByPage(t *testing.T) {
 
 func TestPostMessageForum(t *testing.T) {
 	mockRepo := new(MockForumRepository)
-	service := NewForumService(mockRepo)
+	allowPosting(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
 
 	validMessage := forum.MessageForum{ForumID: "1", Message: "This is a message"}
 	invalidMessage := forum.MessageForum{ForumID: "", Message: ""}
@@ -178,7 +417,7 @@ func TestPostMessageForum(t *testing.T) {
 
 func TestDeleteMessageForum(t *testing.T) {
 	mockRepo := new(MockForumRepository)
-	service := NewForumService(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
 
 	// Positive case
 	mockRepo.On("DeleteMessageForum", "1").Return(nil)
@@ -197,7 +436,7 @@ func TestDeleteMessageForum(t *testing.T) {
 
 func TestGetMessageForumByID(t *testing.T) {
 	mockRepo := new(MockForumRepository)
-	service := NewForumService(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
 
 	expectedMessage := forum.MessageForum{ID: "1", ForumID: "1", Message: "This is a message"}
 
@@ -218,12 +457,14 @@ func TestGetMessageForumByID(t *testing.T) {
 
 func TestUpdateMessageForum(t *testing.T) {
 	mockRepo := new(MockForumRepository)
-	service := NewForumService(mockRepo)
+	allowPosting(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
 
 	validMessage := forum.EditMessage{ID: "1", Message: "Updated message"}
 	invalidMessage := forum.EditMessage{ID: "", Message: ""}
 
 	// Positive case
+	mockRepo.On("GetMessageForumByID", "1").Return(forum.MessageForum{ID: "1", ForumID: "1"}, nil)
 	mockRepo.On("UpdateMessageForum", validMessage).Return(nil)
 	err := service.UpdateMessageForum(validMessage)
 
@@ -236,9 +477,44 @@ func TestUpdateMessageForum(t *testing.T) {
 	assert.Equal(t, constant.ErrFieldData, err)
 }
 
+func TestUpdateMessageForum_ClosedThreadsAndBannedUsers(t *testing.T) {
+	edit := forum.EditMessage{ID: "m1", UserID: "user1", Message: "Updated message"}
+
+	t.Run("banned", func(t *testing.T) {
+		mockRepo := new(MockForumRepository)
+		mockRepo.On("GetActiveBan", "user1", mock.Anything).Return(forum.UserBan{ID: "ban1"}, nil)
+		service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+		assert.Equal(t, constant.ErrUserBanned, service.UpdateMessageForum(edit))
+		mockRepo.AssertNotCalled(t, "UpdateMessageForum", mock.Anything)
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		mockRepo := new(MockForumRepository)
+		mockRepo.On("GetActiveBan", "user1", mock.Anything).Return(forum.UserBan{}, constant.ErrBanNotFound)
+		mockRepo.On("GetMessageForumByID", "m1").Return(forum.MessageForum{ID: "m1", ForumID: "f1"}, nil)
+		mockRepo.On("GetForumState", "f1").Return(forum.Forum{ID: "f1", Locked: true}, nil)
+		service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+		assert.Equal(t, constant.ErrForumLocked, service.UpdateMessageForum(edit))
+		mockRepo.AssertNotCalled(t, "UpdateMessageForum", mock.Anything)
+	})
+
+	t.Run("hidden", func(t *testing.T) {
+		mockRepo := new(MockForumRepository)
+		mockRepo.On("GetActiveBan", "user1", mock.Anything).Return(forum.UserBan{}, constant.ErrBanNotFound)
+		mockRepo.On("GetMessageForumByID", "m1").Return(forum.MessageForum{ID: "m1", ForumID: "f1"}, nil)
+		mockRepo.On("GetForumState", "f1").Return(forum.Forum{ID: "f1", Hidden: true}, nil)
+		service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+		assert.Equal(t, constant.ErrForumNotFound, service.UpdateMessageForum(edit))
+		mockRepo.AssertNotCalled(t, "UpdateMessageForum", mock.Anything)
+	})
+}
+
 func TestGetForumByUserID(t *testing.T) {
 	mockRepo := new(MockForumRepository)
-	service := NewForumService(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
 
 	expectedForums := []forum.Forum{{ID: "1", Title: "User Forum 1"}, {ID: "2", Title: "User Forum 2"}}
 
@@ -255,7 +531,7 @@ func TestGetForumByUserID(t *testing.T) {
 
 func TestGetMessagesByForumID(t *testing.T) {
 	mockRepo := new(MockForumRepository)
-	service := NewForumService(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
 	mockRepo.On("GetMessagesByForumID", "123").Return([]forum.MessageForum{
 		{ID: "1", Message: "Message 1"},
 		{ID: "2", Message: "Message 2"},
@@ -273,7 +549,7 @@ func TestGetMessagesByForumID(t *testing.T) {
 
 func TestGetMessagesByForumIDWithPagination(t *testing.T) {
 	mockRepo := new(MockForumRepository)
-	service := NewForumService(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
 
 	mockRepo.On("GetMessagesByForumIDWithPagination", "123", 1, 10).Return([]forum.MessageForum{
 		{ID: "1", Message: "Message 1"},
@@ -289,3 +565,636 @@ func TestGetMessagesByForumIDWithPagination(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestGetAllByPage_MostLiked(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	filter := forum.ForumFilter{Page: 1, Sort: forum.SortMostLiked}
+	mockRepo.On("GetAllByPage", filter).Return([]forum.ForumGetAll{{ID: "1", LikeCount: 5}}, 1, nil)
+
+	result, _, err := service.GetAllByPage(forum.ForumFilter{Page: 0, Sort: forum.SortMostLiked})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, result[0].LikeCount)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAllByPage_InvalidSort(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	_, _, err := service.GetAllByPage(forum.ForumFilter{Page: 1, Sort: "oldest"})
+
+	assert.Equal(t, constant.ErrInvalidForumSort, err)
+	mockRepo.AssertNotCalled(t, "GetAllByPage", mock.Anything)
+}
+
+func TestPostMessageForum_Reply(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	allowPosting(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	parentID := "parent1"
+	reply := forum.MessageForum{ID: "reply1", ForumID: "1", ParentID: &parentID, Message: "Agreed"}
+	mockRepo.On("GetMessageForumByID", "parent1").Return(forum.MessageForum{ID: "parent1", ForumID: "1"}, nil)
+	mockRepo.On("PostMessageForum", reply).Return(nil)
+
+	err := service.PostMessageForum(reply)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPostMessageForum_ReplyToOtherForum(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	allowPosting(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	parentID := "parent1"
+	reply := forum.MessageForum{ID: "reply1", ForumID: "1", ParentID: &parentID, Message: "Agreed"}
+	mockRepo.On("GetMessageForumByID", "parent1").Return(forum.MessageForum{ID: "parent1", ForumID: "2"}, nil)
+
+	err := service.PostMessageForum(reply)
+
+	assert.Equal(t, constant.ErrInvalidParentMessage, err)
+	mockRepo.AssertNotCalled(t, "PostMessageForum", mock.Anything)
+}
+
+func TestPostMessageForum_NotifiesThreadOwner(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	mockRepo.On("GetActiveBan", mock.Anything, mock.Anything).Return(forum.UserBan{}, constant.ErrBanNotFound)
+	mockRepo.On("GetForumState", "1").Return(forum.Forum{ID: "1", UserID: "owner", Title: "Composting tips"}, nil)
+	mockRepo.On("GetBlockedWords").Return([]forum.BlockedWord{}, nil)
+	mockNotifier := new(MockNotifier)
+	service := NewForumService(mockRepo, mockNotifier, newMockPublisher())
+
+	message := forum.MessageForum{ID: "m1", ForumID: "1", UserID: "author", Message: "Nice"}
+	mockRepo.On("PostMessageForum", message).Return(nil)
+	mockNotifier.On("Notify", notifications.Notification{
+		UserID:  "owner",
+		Type:    notifications.TypeForumReply,
+		Title:   "New reply in the forum",
+		Message: "Someone replied to you in \"Composting tips\".",
+		Link:    "/forums/1",
+	}).Return(nil)
+
+	err := service.PostMessageForum(message)
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestPostMessageForum_PublishesToForum(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	allowPosting(mockRepo)
+	mockPublisher := new(MockPublisher)
+	service := NewForumService(mockRepo, new(MockNotifier), mockPublisher)
+
+	message := forum.MessageForum{ID: "m1", ForumID: "1", UserID: "author", Message: "Hello"}
+	mockRepo.On("PostMessageForum", message).Return(nil)
+	mockPublisher.On("Publish", "forum:1", mock.MatchedBy(func(e realtime.Event) bool {
+		data := e.Data.(map[string]interface{})
+		return e.Type == realtime.EventForumMessage && data["id"] == "m1" && data["message"] == "Hello"
+	})).Return()
+
+	err := service.PostMessageForum(message)
+
+	assert.NoError(t, err)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestPostMessageForum_NotifiesParentAuthor(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	mockRepo.On("GetActiveBan", mock.Anything, mock.Anything).Return(forum.UserBan{}, constant.ErrBanNotFound)
+	mockRepo.On("GetForumState", "1").Return(forum.Forum{ID: "1", UserID: "owner"}, nil)
+	mockRepo.On("GetBlockedWords").Return([]forum.BlockedWord{}, nil)
+	mockNotifier := new(MockNotifier)
+	service := NewForumService(mockRepo, mockNotifier, newMockPublisher())
+
+	parentID := "parent1"
+	reply := forum.MessageForum{ID: "reply1", ForumID: "1", UserID: "author", ParentID: &parentID, Message: "Agreed"}
+	mockRepo.On("GetMessageForumByID", "parent1").Return(forum.MessageForum{ID: "parent1", ForumID: "1", UserID: "parent-author"}, nil)
+	mockRepo.On("PostMessageForum", reply).Return(nil)
+	mockNotifier.On("Notify", mock.MatchedBy(func(n notifications.Notification) bool {
+		return n.UserID == "parent-author"
+	})).Return(nil)
+
+	err := service.PostMessageForum(reply)
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestPostMessageForum_OwnReplyIsNotNotified(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	mockRepo.On("GetActiveBan", mock.Anything, mock.Anything).Return(forum.UserBan{}, constant.ErrBanNotFound)
+	mockRepo.On("GetForumState", "1").Return(forum.Forum{ID: "1", UserID: "owner"}, nil)
+	mockRepo.On("GetBlockedWords").Return([]forum.BlockedWord{}, nil)
+	mockNotifier := new(MockNotifier)
+	service := NewForumService(mockRepo, mockNotifier, newMockPublisher())
+
+	message := forum.MessageForum{ID: "m1", ForumID: "1", UserID: "owner", Message: "Update"}
+	mockRepo.On("PostMessageForum", message).Return(nil)
+
+	err := service.PostMessageForum(message)
+
+	assert.NoError(t, err)
+	mockNotifier.AssertNotCalled(t, "Notify", mock.Anything)
+}
+
+func TestPostMessageForum_Mentions(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	allowPosting(mockRepo)
+	mockNotifier := new(MockNotifier)
+	service := NewForumService(mockRepo, mockNotifier, newMockPublisher())
+
+	message := forum.MessageForum{ID: "m1", ForumID: "1", UserID: "author", Message: "Thanks @greenjane and @author, ping @greenjane. Mail me at me@example.com"}
+	mockRepo.On("PostMessageForum", message).Return(nil)
+	mockRepo.On("GetUsersByUsernames", []string{"greenjane", "author"}).Return([]users.User{
+		{ID: "jane", Username: "greenjane", Email: "jane@example.com"},
+		{ID: "author", Username: "author", Email: "author@example.com"},
+	}, nil)
+	messageID := "m1"
+	mockRepo.On("SaveMentions", []forum.ForumMention{
+		{ForumID: "1", MessageID: &messageID, MentionedUserID: "jane", MentionedByID: "author"},
+	}).Return(nil)
+	mockNotifier.On("Notify", notifications.Notification{
+		UserID:  "jane",
+		Type:    notifications.TypeForumMention,
+		Title:   "You were mentioned in the forum",
+		Message: "Someone mentioned you in \"\".",
+		Link:    "/forums/1",
+	}).Return(nil)
+
+	err := service.PostMessageForum(message)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
+	mockNotifier.AssertNumberOfCalls(t, "Notify", 1)
+}
+
+func TestPostForum_MentionsAreCapped(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	allowPosting(mockRepo)
+	mockNotifier := new(MockNotifier)
+	service := NewForumService(mockRepo, mockNotifier, newMockPublisher())
+
+	var usernames []string
+	for i := 0; i < maxMentionsPerPost+5; i++ {
+		usernames = append(usernames, fmt.Sprintf("user%d", i))
+	}
+	thread := forum.Forum{ID: "1", Title: "Composting", Description: "Hi @" + strings.Join(usernames, " @"), UserID: "author"}
+	mockRepo.On("PostForum", thread).Return(nil)
+	mockRepo.On("GetUsersByUsernames", usernames[:maxMentionsPerPost]).Return([]users.User{{ID: "user0", Username: "user0"}}, nil)
+	mockRepo.On("SaveMentions", mock.Anything).Return(nil)
+	mockNotifier.On("Notify", mock.MatchedBy(func(notification notifications.Notification) bool {
+		return notification.UserID == "user0" && notification.Message == "Someone mentioned you in \"Composting\"."
+	})).Return(nil)
+
+	err := service.PostForum(thread)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestLikeForum(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	mockRepo.On("AddLike", mock.MatchedBy(func(like forum.ForumLike) bool {
+		return like.ID != "" && like.UserID == "user1" && like.TargetType == forum.TargetForum && like.TargetID == "forum1"
+	})).Return(nil)
+
+	err := service.LikeForum("forum1", "user1")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLikeMessage_AlreadyLiked(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	mockRepo.On("AddLike", mock.Anything).Return(constant.ErrForumAlreadyLiked)
+
+	err := service.LikeMessage("message1", "user1")
+
+	assert.Equal(t, constant.ErrForumAlreadyLiked, err)
+}
+
+func TestUnlikeMessage(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	mockRepo.On("RemoveLike", "user1", forum.TargetMessage, "message1").Return(nil)
+
+	err := service.UnlikeMessage("message1", "user1")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPostForum_Banned(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	post := forum.Forum{ID: "f1", UserID: "u1", Title: "Hello", Description: "World"}
+	mockRepo.On("GetActiveBan", "u1", mock.Anything).Return(forum.UserBan{ID: "b1", UserID: "u1"}, nil)
+
+	err := service.PostForum(post)
+
+	assert.Equal(t, constant.ErrUserBanned, err)
+	mockRepo.AssertNotCalled(t, "PostForum", mock.Anything)
+}
+
+func TestPostMessageForum_LockedForum(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	message := forum.MessageForum{ID: "m1", ForumID: "1", UserID: "u1", Message: "Hello"}
+	mockRepo.On("GetActiveBan", "u1", mock.Anything).Return(forum.UserBan{}, constant.ErrBanNotFound)
+	mockRepo.On("GetForumState", "1").Return(forum.Forum{ID: "1", Locked: true}, nil)
+
+	err := service.PostMessageForum(message)
+
+	assert.Equal(t, constant.ErrForumLocked, err)
+	mockRepo.AssertNotCalled(t, "PostMessageForum", mock.Anything)
+}
+
+func TestPostForum_BlockedWord(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	post := forum.Forum{ID: "f1", UserID: "u1", Title: "Hello", Description: "What a Scam this is"}
+	mockRepo.On("GetActiveBan", "u1", mock.Anything).Return(forum.UserBan{}, constant.ErrBanNotFound)
+	mockRepo.On("GetBlockedWords").Return([]forum.BlockedWord{{ID: "w1", Word: "scam"}}, nil)
+	mockRepo.On("CreateModerationLog", mock.MatchedBy(func(entry forum.ModerationLog) bool {
+		return entry.ActorID == forum.SystemActor && entry.Action == forum.ActionFilterBlocked && entry.TargetID == "u1"
+	})).Return(nil)
+
+	err := service.PostForum(post)
+
+	assert.Equal(t, constant.ErrContentBlocked, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "PostForum", mock.Anything)
+}
+
+func TestPostForum_BlockedWordOnlyMatchesWholeWords(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	post := forum.Forum{ID: "f1", UserID: "u1", Title: "Hello", Description: "Scampi recipes"}
+	mockRepo.On("GetActiveBan", "u1", mock.Anything).Return(forum.UserBan{}, constant.ErrBanNotFound)
+	mockRepo.On("GetBlockedWords").Return([]forum.BlockedWord{{ID: "w1", Word: "scam"}}, nil)
+	mockRepo.On("PostForum", post).Return(nil)
+
+	err := service.PostForum(post)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPostMessageForum_Spam(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	message := forum.MessageForum{ID: "m1", ForumID: "1", UserID: "u1", Message: "http://a.com http://b.com https://c.com www.d.com"}
+	allowPosting(mockRepo)
+	mockRepo.On("CreateModerationLog", mock.Anything).Return(nil)
+
+	err := service.PostMessageForum(message)
+
+	assert.Equal(t, constant.ErrContentSpam, err)
+	mockRepo.AssertNotCalled(t, "PostMessageForum", mock.Anything)
+}
+
+func TestReportContent_Duplicate(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	mockRepo.On("GetMessageForumByID", "m1").Return(forum.MessageForum{ID: "m1"}, nil)
+	mockRepo.On("HasPendingReport", "u1", forum.TargetMessage, "m1").Return(true, nil)
+
+	err := service.ReportContent(forum.ForumReport{ReporterID: "u1", TargetType: forum.TargetMessage, TargetID: "m1", Reason: "rude"})
+
+	assert.Equal(t, constant.ErrReportExists, err)
+	mockRepo.AssertNotCalled(t, "CreateReport", mock.Anything)
+}
+
+func TestReportContent_InvalidTarget(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	err := service.ReportContent(forum.ForumReport{ReporterID: "u1", TargetType: "user", TargetID: "x", Reason: "rude"})
+
+	assert.Equal(t, constant.ErrReportField, err)
+}
+
+func TestResolveReport_Hide(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	mockRepo.On("GetReportByID", "r1").Return(forum.ForumReport{ID: "r1", TargetType: forum.TargetMessage, TargetID: "m1", Status: forum.ReportPending}, nil)
+	mockRepo.On("SetMessageHidden", "m1", true).Return(nil)
+	mockRepo.On("ResolveReports", forum.TargetMessage, "m1", forum.ReportResolved, "admin1", "offensive").Return(nil)
+	mockRepo.On("CreateModerationLog", mock.MatchedBy(func(entry forum.ModerationLog) bool {
+		return entry.ActorID == "admin1" && entry.Action == forum.ActionHide && entry.TargetID == "m1"
+	})).Return(nil)
+
+	err := service.ResolveReport("r1", "admin1", forum.ActionHide, "offensive")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResolveReport_AlreadyHandled(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	mockRepo.On("GetReportByID", "r1").Return(forum.ForumReport{ID: "r1", TargetType: forum.TargetForum, TargetID: "f1", Status: forum.ReportDismissed}, nil)
+
+	err := service.ResolveReport("r1", "admin1", forum.ActionHide, "")
+
+	assert.Equal(t, constant.ErrReportNotPending, err)
+}
+
+func TestResolveReport_LockMessage(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	mockRepo.On("GetReportByID", "r1").Return(forum.ForumReport{ID: "r1", TargetType: forum.TargetMessage, TargetID: "m1", Status: forum.ReportPending}, nil)
+
+	err := service.ResolveReport("r1", "admin1", forum.ActionLock, "")
+
+	assert.Equal(t, constant.ErrInvalidModerationAction, err)
+}
+
+func TestModerateForum_Lock(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	mockRepo.On("GetForumState", "f1").Return(forum.Forum{ID: "f1"}, nil)
+	mockRepo.On("SetForumLocked", "f1", true).Return(nil)
+	mockRepo.On("CreateModerationLog", mock.Anything).Return(nil)
+
+	err := service.ModerateForum("f1", "admin1", forum.ActionLock)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBanUser(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	mockRepo.On("CreateBan", mock.MatchedBy(func(ban forum.UserBan) bool {
+		return ban.UserID == "u1" && ban.ExpiresAt != nil && ban.ExpiresAt.Equal(expiresAt)
+	})).Return(nil)
+	mockRepo.On("CreateModerationLog", mock.Anything).Return(nil)
+
+	ban, err := service.BanUser(forum.UserBan{UserID: "u1", Reason: "spam", CreatedBy: "admin1", ExpiresAt: &expiresAt})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ban.ID)
+	mockRepo.AssertExpectations(t)
+
+	expired := time.Now().Add(-time.Hour)
+	_, err = service.BanUser(forum.UserBan{UserID: "u1", Reason: "spam", CreatedBy: "admin1", ExpiresAt: &expired})
+	assert.Equal(t, constant.ErrBanField, err)
+}
+
+func TestAddBlockedWord_Normalizes(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	mockRepo.On("AddBlockedWord", mock.MatchedBy(func(word forum.BlockedWord) bool {
+		return word.Word == "scam"
+	})).Return(nil)
+	mockRepo.On("CreateModerationLog", mock.Anything).Return(nil)
+
+	word, err := service.AddBlockedWord("  SCAM ", "admin1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "scam", word.Word)
+	mockRepo.AssertExpectations(t)
+
+	_, err = service.AddBlockedWord("   ", "admin1")
+	assert.Equal(t, constant.ErrBlockedWordField, err)
+}
+
+func TestGetAllByPage_SearchDefaultsToRelevance(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	expected := forum.ForumFilter{Page: 1, Sort: forum.SortRelevance, Category: "composting", Tag: "zero-waste", Search: "worm bin"}
+	mockRepo.On("GetAllByPage", expected).Return([]forum.ForumGetAll{{ID: "1"}}, 1, nil)
+
+	_, _, err := service.GetAllByPage(forum.ForumFilter{Category: " Composting ", Tag: "#Zero Waste", Search: "  worm bin "})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAllByPage_RelevanceWithoutSearch(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	_, _, err := service.GetAllByPage(forum.ForumFilter{Sort: forum.SortRelevance})
+
+	assert.Equal(t, constant.ErrInvalidForumSort, err)
+	mockRepo.AssertNotCalled(t, "GetAllByPage", mock.Anything)
+}
+
+func TestPostForum_CategoryAndTags(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	allowPosting(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	categoryID := "cat1"
+	post := forum.Forum{
+		ID:          "f1",
+		UserID:      "u1",
+		Title:       "Compost tips",
+		Description: "How do you start?",
+		CategoryID:  &categoryID,
+		Tags:        []forum.ForumTag{{Tag: "#Compost"}, {Tag: "zero waste"}, {Tag: "compost"}},
+	}
+	mockRepo.On("GetCategoryByID", "cat1").Return(forum.ForumCategory{ID: "cat1"}, nil)
+	mockRepo.On("PostForum", mock.MatchedBy(func(f forum.Forum) bool {
+		return len(f.Tags) == 2 && f.Tags[0].Tag == "compost" && f.Tags[1].Tag == "zero-waste" &&
+			f.Tags[0].ForumID == "f1" && f.Tags[0].ID != "" && *f.CategoryID == "cat1"
+	})).Return(nil)
+
+	err := service.PostForum(post)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPostForum_InvalidTags(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	allowPosting(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	tooMany := forum.Forum{ID: "f1", UserID: "u1", Title: "Hello", Description: "World"}
+	for _, tag := range []string{"a", "b", "c", "d", "e", "f"} {
+		tooMany.Tags = append(tooMany.Tags, forum.ForumTag{Tag: tag})
+	}
+	assert.Equal(t, constant.ErrInvalidForumTags, service.PostForum(tooMany))
+
+	badCharacters := forum.Forum{ID: "f1", UserID: "u1", Title: "Hello", Description: "World", Tags: []forum.ForumTag{{Tag: "eco!"}}}
+	assert.Equal(t, constant.ErrInvalidForumTags, service.PostForum(badCharacters))
+
+	mockRepo.AssertNotCalled(t, "PostForum", mock.Anything)
+}
+
+func TestPostForum_UnknownCategory(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	allowPosting(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	categoryID := "missing"
+	mockRepo.On("GetCategoryByID", "missing").Return(forum.ForumCategory{}, constant.ErrForumCategoryNotFound)
+
+	err := service.PostForum(forum.Forum{ID: "f1", UserID: "u1", Title: "Hello", Description: "World", CategoryID: &categoryID})
+
+	assert.Equal(t, constant.ErrForumCategoryNotFound, err)
+	mockRepo.AssertNotCalled(t, "PostForum", mock.Anything)
+}
+
+func TestUpdateForum_ClearTags(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	allowPosting(mockRepo)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	edit := forum.EditForum{ID: "f1", Title: "Hello", Description: "World", Tags: []string{}}
+	mockRepo.On("UpdateForum", mock.MatchedBy(func(e forum.EditForum) bool {
+		return e.Tags != nil && len(e.Tags) == 0
+	})).Return(nil)
+
+	err := service.UpdateForum(edit)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateCategory_SlugFromName(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	mockRepo.On("GetCategoryBySlug", "product-talk").Return(forum.ForumCategory{}, constant.ErrForumCategoryNotFound)
+	mockRepo.On("CreateCategory", mock.MatchedBy(func(category forum.ForumCategory) bool {
+		return category.ID != "" && category.Name == "Product Talk" && category.Slug == "product-talk"
+	})).Return(nil)
+
+	category, err := service.CreateCategory(forum.ForumCategory{Name: " Product Talk "})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "product-talk", category.Slug)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateCategory_SlugTaken(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	mockRepo.On("GetCategoryByID", "cat1").Return(forum.ForumCategory{ID: "cat1", Slug: "recycling"}, nil)
+	mockRepo.On("GetCategoryBySlug", "composting").Return(forum.ForumCategory{ID: "cat2", Slug: "composting"}, nil)
+
+	_, err := service.UpdateCategory(forum.ForumCategory{ID: "cat1", Name: "Composting"})
+
+	assert.Equal(t, constant.ErrForumCategoryExists, err)
+	mockRepo.AssertNotCalled(t, "UpdateCategory", mock.Anything)
+}
+
+func TestGetRelatedForums(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	mockRepo.On("GetRelatedForums", "f1", 5).Return([]forum.Forum{{ID: "f2"}}, nil)
+
+	related, err := service.GetRelatedForums("f1")
+
+	assert.NoError(t, err)
+	assert.Len(t, related, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRecordView_CountsEachViewerOncePerWindow(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	service.RecordView("f1", "u1")
+	service.RecordView("f1", "u1")
+	service.RecordView("f1", "u2")
+	service.RecordView("f2", "u1")
+
+	mockRepo.On("GetForumByID", "f1").Return(forum.Forum{ID: "f1", View: 10}, nil)
+	result, err := service.GetForumByID("f1")
+	assert.NoError(t, err)
+	assert.Equal(t, 12, result.View)
+
+	mockRepo.On("SaveViews", map[string]int{"f1": 2, "f2": 1}, mock.MatchedBy(func(views []forum.ForumView) bool {
+		return len(views) == 3 && views[0].ForumID == "f1" && views[0].ViewerKey == "u1"
+	})).Return(nil).Once()
+	mockRepo.On("DeleteViewsBefore", mock.Anything).Return(nil)
+
+	assert.NoError(t, service.FlushViews())
+	mockRepo.AssertExpectations(t)
+
+	// Still inside the window, so flushing again has nothing to save.
+	service.RecordView("f1", "u1")
+	assert.NoError(t, service.FlushViews())
+	mockRepo.AssertNumberOfCalls(t, "SaveViews", 1)
+}
+
+func TestFlushViews_KeepsBatchOnFailure(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	service.RecordView("f1", "u1")
+	mockRepo.On("SaveViews", map[string]int{"f1": 1}, mock.Anything).Return(errors.New("db down")).Once()
+
+	assert.Error(t, service.FlushViews())
+
+	service.RecordView("f1", "u2")
+	mockRepo.On("SaveViews", map[string]int{"f1": 2}, mock.MatchedBy(func(views []forum.ForumView) bool {
+		return len(views) == 2 && views[0].ViewerKey == "u1" && views[1].ViewerKey == "u2"
+	})).Return(nil).Once()
+	mockRepo.On("DeleteViewsBefore", mock.Anything).Return(nil)
+
+	assert.NoError(t, service.FlushViews())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestViewBuffer_CountsAgainAfterWindow(t *testing.T) {
+	buffer := newViewBuffer(30 * time.Minute)
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.True(t, buffer.record("f1", "u1", start))
+	assert.False(t, buffer.record("f1", "u1", start.Add(29*time.Minute)))
+	assert.True(t, buffer.record("f1", "u1", start.Add(31*time.Minute)))
+	assert.Equal(t, 2, buffer.pending("f1"))
+
+	counts, views := buffer.drain(start.Add(2 * time.Hour))
+	assert.Equal(t, map[string]int{"f1": 2}, counts)
+	assert.Len(t, views, 2)
+	assert.Empty(t, buffer.seen)
+	assert.Equal(t, 0, buffer.pending("f1"))
+}
+
+func TestGetAllByPage_Trending(t *testing.T) {
+	mockRepo := new(MockForumRepository)
+	service := NewForumService(mockRepo, new(MockNotifier), newMockPublisher())
+
+	mockRepo.On("GetAllByPage", forum.ForumFilter{Page: 1, Sort: forum.SortTrending}).Return([]forum.ForumGetAll{{ID: "1"}}, 1, nil)
+
+	_, _, err := service.GetAllByPage(forum.ForumFilter{Sort: forum.SortTrending})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
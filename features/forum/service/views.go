@@ -0,0 +1,88 @@
+package service
+
+import (
+	"greenenvironment/features/forum"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// viewBuffer collects unique thread views in memory so a busy thread costs
+// one write per flush instead of one per request. Deduplication lives in the
+// process, so behind several instances a viewer can be counted once per
+// instance within the window.
+type viewBuffer struct {
+	mu     sync.Mutex
+	window time.Duration
+	seen   map[string]time.Time
+	counts map[string]int
+	views  []forum.ForumView
+}
+
+func newViewBuffer(window time.Duration) *viewBuffer {
+	return &viewBuffer{
+		window: window,
+		seen:   make(map[string]time.Time),
+		counts: make(map[string]int),
+	}
+}
+
+// record counts the view unless the same viewer was counted for the thread
+// within the window. It reports whether the view was counted.
+func (b *viewBuffer) record(forumID, viewerKey string, now time.Time) bool {
+	key := viewerKey + "|" + forumID
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if last, ok := b.seen[key]; ok && now.Sub(last) < b.window {
+		return false
+	}
+	b.seen[key] = now
+	b.counts[forumID]++
+	b.views = append(b.views, forum.ForumView{
+		ID:        uuid.New().String(),
+		ForumID:   forumID,
+		ViewerKey: viewerKey,
+		ViewedAt:  now,
+	})
+	return true
+}
+
+// pending is the number of views counted for the thread but not flushed yet.
+func (b *viewBuffer) pending(forumID string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.counts[forumID]
+}
+
+// drain hands over everything buffered so far and forgets viewers whose
+// window has passed.
+func (b *viewBuffer) drain(now time.Time) (map[string]int, []forum.ForumView) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for key, last := range b.seen {
+		if now.Sub(last) >= b.window {
+			delete(b.seen, key)
+		}
+	}
+
+	counts, views := b.counts, b.views
+	b.counts = make(map[string]int)
+	b.views = nil
+	return counts, views
+}
+
+// restore puts a drained batch back after a failed flush so the next flush
+// retries it.
+func (b *viewBuffer) restore(counts map[string]int, views []forum.ForumView) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for forumID, count := range counts {
+		b.counts[forumID] += count
+	}
+	b.views = append(views, b.views...)
+}
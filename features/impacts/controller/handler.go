@@ -5,6 +5,7 @@ import (
 	"greenenvironment/features/impacts"
 	"greenenvironment/helper"
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -51,12 +52,7 @@ func (ic *ImpactController) GetAll(c echo.Context) error {
 
 	var impactCategoryResponses []ImpactCategoryResponse
 	for _, impactCategory := range impactCategories {
-		impactCategoryResponses = append(impactCategoryResponses, ImpactCategoryResponse{
-			ID:          impactCategory.ID,
-			Name:        impactCategory.Name,
-			ImpactPoint: impactCategory.ImpactPoint,
-			Description: impactCategory.Description,
-		})
+		impactCategoryResponses = append(impactCategoryResponses, toImpactCategoryResponse(impactCategory))
 	}
 
 	return c.JSON(http.StatusOK, helper.FormatResponse(true, "get all impacts successfully", []interface{}{impactCategoryResponses}))
@@ -91,12 +87,7 @@ func (ic *ImpactController) GetByID(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, helper.ObjectFormatResponse(false, err.Error(), nil))
 	}
 
-	impactCategoryResponse := ImpactCategoryResponse{
-		ID:          impactCategory.ID,
-		Name:        impactCategory.Name,
-		ImpactPoint: impactCategory.ImpactPoint,
-		Description: impactCategory.Description,
-	}
+	impactCategoryResponse := toImpactCategoryResponse(impactCategory)
 
 	return c.JSON(http.StatusOK, helper.ObjectFormatResponse(true, "get impact successfully", impactCategoryResponse))
 }
@@ -139,28 +130,92 @@ func (ic *ImpactController) Create(c echo.Context) error {
 		Name:        impactRequest.Name,
 		ImpactPoint: impactRequest.ImpactPoint,
 		Description: impactRequest.Description,
+		Icon:        impactRequest.Icon,
+		Color:       impactRequest.Color,
+		ParentID:    impactRequest.ParentID,
 	}
 
 	err = ic.impactService.Create(impact)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, helper.FormatResponse(false, err.Error(), nil))
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
 	}
 
 	return c.JSON(http.StatusCreated, helper.ObjectFormatResponse(true, "create impact successfully", nil))
 }
 
+// Update Impact
+// @Summary      Update an impact
+// @Description  Update the name, points, description, icon, color or parent of an impact category
+// @Tags         Impact
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        id             path      string  true   "Impact ID"
+// @Param        request        body      UpdateImpactRequest  true  "Update impact payload"
+// @Success      200  {object}  helper.Response{data=ImpactCategoryResponse} "Impact updated successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Invalid input, color or parent"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /impacts/{id} [put]
+func (ic *ImpactController) Update(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+	token, err := ic.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+	adminData := ic.jwtService.ExtractUserToken(token)
+	role := adminData[constant.JWT_ROLE]
+	if role != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	var impactRequest UpdateImpactRequest
+	if err := c.Bind(&impactRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "error bad request", nil))
+	}
+	if err := c.Validate(impactRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	impact := impacts.ImpactCategory{
+		ID:          c.Param("id"),
+		Name:        impactRequest.Name,
+		ImpactPoint: impactRequest.ImpactPoint,
+		Description: impactRequest.Description,
+		Icon:        impactRequest.Icon,
+		Color:       impactRequest.Color,
+		ParentID:    impactRequest.ParentID,
+	}
+	if err := ic.impactService.Update(impact); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	updated, err := ic.impactService.GetByID(impact.ID)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.ObjectFormatResponse(true, "update impact successfully", toImpactCategoryResponse(updated)))
+}
+
 // Delete Impact
 // @Summary      Delete an impact
-// @Description  Remove an impact category by its ID
+// @Description  Soft delete an impact category by its ID. A category still linked to products, challenges, articles or child categories is only deleted when reassign_to names the category that takes those links over; otherwise the linked items are returned with a 409.
 // @Tags         Impact
 // @Accept       json
 // @Produce      json
 // @Param        Authorization  header    string  true   "Bearer Token"
 // @Param        id             path      string  true   "Impact ID"
+// @Param        reassign_to    query     string  false  "Impact ID that receives the linked products, challenges, articles and child categories"
 // @Success      200  {object}  helper.Response{data=string} "Impact deleted successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Invalid reassignment target"
 // @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
 // @Failure      403  {object}  helper.Response{data=string} "Forbidden"
 // @Failure      404  {object}  helper.Response{data=string} "Impact not found"
+// @Failure      409  {object}  helper.Response{data=CategoryUsageResponse} "Impact category in use"
 // @Failure      500  {object}  helper.Response{data=string} "Internal server error"
 // @Router       /impacts/{id} [delete]
 func (ic *ImpactController) Delete(c echo.Context) error {
@@ -181,10 +236,134 @@ func (ic *ImpactController) Delete(c echo.Context) error {
 	impactId := c.Param("id")
 	var impactCategory impacts.ImpactCategory
 	impactCategory.ID = impactId
-	err = ic.impactService.Delete(impactCategory)
+	usage, err := ic.impactService.Delete(impactCategory, c.QueryParam("reassign_to"))
+	if err == constant.ErrImpactCategoryInUse {
+		return c.JSON(http.StatusConflict, helper.FormatResponse(false, err.Error(), toCategoryUsageResponse(usage)))
+	}
 	if err != nil {
 		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
 	}
 
 	return c.JSON(http.StatusOK, helper.FormatResponse(true, "delete impact successfully", nil))
 }
+
+// Get My Impact
+// @Summary      Retrieve the user's environmental impact
+// @Description  Get the authenticated user's cumulative impact points from settled purchases and completed challenges, converted into kg CO2 avoided, kg plastic saved and liters of water saved, with a monthly trend
+// @Tags         Impact
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        months         query     int     false  "Number of months in the trend (default 12, max 36)"
+// @Success      200  {object}  helper.Response{data=UserImpactResponse} "Retrieve user impact successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /impacts/me [get]
+func (ic *ImpactController) GetMyImpact(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+	token, err := ic.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+
+	userData := ic.jwtService.ExtractUserToken(token)
+	userID := userData[constant.JWT_ID].(string)
+
+	months, _ := strconv.Atoi(c.QueryParam("months"))
+
+	summary, err := ic.impactService.GetUserImpact(userID, months)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.ObjectFormatResponse(true, "get user impact successfully", new(UserImpactResponse).ToResponse(summary)))
+}
+
+// Get Conversion Factors
+// @Summary      Retrieve impact conversion factors
+// @Description  Get how many kg CO2, kg plastic and liters of water one impact point of a category stands for
+// @Tags         Impact
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        id             path      string  true   "Impact ID"
+// @Success      200  {object}  helper.Response{data=[]ConversionFactorResponse} "Retrieve conversion factors successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /impacts/{id}/conversions [get]
+func (ic *ImpactController) GetConversionFactors(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+	token, err := ic.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+	adminData := ic.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	factors, err := ic.impactService.GetConversionFactors(c.Param("id"))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "get conversion factors successfully", toConversionFactorResponses(factors)))
+}
+
+// Update Conversion Factors
+// @Summary      Update impact conversion factors
+// @Description  Set how many kg CO2, kg plastic and liters of water one impact point of a category stands for. Only the metrics sent are changed.
+// @Tags         Impact
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string                         true  "Bearer Token"
+// @Param        id             path      string                         true  "Impact ID"
+// @Param        request        body      UpdateConversionFactorRequest  true  "Conversion factors per impact point"
+// @Success      200  {object}  helper.Response{data=[]ConversionFactorResponse} "Conversion factors updated successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Invalid input or validation error"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /impacts/{id}/conversions [put]
+func (ic *ImpactController) UpdateConversionFactors(c echo.Context) error {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return helper.UnauthorizedError(c)
+	}
+	token, err := ic.jwtService.ValidateToken(tokenString)
+	if err != nil {
+		return helper.UnauthorizedError(c)
+	}
+	adminData := ic.jwtService.ExtractUserToken(token)
+	if adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	var request UpdateConversionFactorRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "error bad request", nil))
+	}
+
+	factors := make(map[string]float64)
+	if request.CO2Kg != nil {
+		factors[impacts.MetricCO2] = *request.CO2Kg
+	}
+	if request.PlasticKg != nil {
+		factors[impacts.MetricPlastic] = *request.PlasticKg
+	}
+	if request.WaterLiters != nil {
+		factors[impacts.MetricWater] = *request.WaterLiters
+	}
+
+	updated, err := ic.impactService.UpdateConversionFactors(c.Param("id"), factors)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "update conversion factors successfully", toConversionFactorResponses(updated)))
+}
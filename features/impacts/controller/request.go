@@ -4,4 +4,22 @@ type CreateImpactRequest struct {
 	Name        string `json:"name" validate:"required,min=1"`
 	ImpactPoint int    `json:"impact_point" validate:"required,min=0"`
 	Description string `json:"description" validate:"required,min=1"`
+	Icon        string `json:"icon"`
+	Color       string `json:"color"`
+	ParentID    string `json:"parent_id"`
+}
+
+type UpdateImpactRequest struct {
+	Name        string `json:"name" validate:"required,min=1"`
+	ImpactPoint int    `json:"impact_point" validate:"required,min=0"`
+	Description string `json:"description" validate:"required,min=1"`
+	Icon        string `json:"icon"`
+	Color       string `json:"color"`
+	ParentID    string `json:"parent_id"`
+}
+
+type UpdateConversionFactorRequest struct {
+	CO2Kg       *float64 `json:"co2_kg"`
+	PlasticKg   *float64 `json:"plastic_kg"`
+	WaterLiters *float64 `json:"water_liters"`
 }
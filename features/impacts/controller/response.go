@@ -1,8 +1,137 @@
 package controller
 
+import "greenenvironment/features/impacts"
+
 type ImpactCategoryResponse struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	ImpactPoint int    `json:"impact_point"`
-	Description string `json:"description"`
+	ID          string                   `json:"id"`
+	Name        string                   `json:"name"`
+	ImpactPoint int                      `json:"impact_point"`
+	Description string                   `json:"description"`
+	Icon        string                   `json:"icon"`
+	Color       string                   `json:"color"`
+	ParentID    string                   `json:"parent_id"`
+	Children    []ImpactCategoryResponse `json:"children,omitempty"`
+}
+
+type UsageItemResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type CategoryUsageResponse struct {
+	Products   []UsageItemResponse `json:"products"`
+	Challenges []UsageItemResponse `json:"challenges"`
+	Articles   []UsageItemResponse `json:"articles"`
+	Children   []UsageItemResponse `json:"children"`
+}
+
+type ImpactMetricsResponse struct {
+	CO2Kg       float64 `json:"co2_kg"`
+	PlasticKg   float64 `json:"plastic_kg"`
+	WaterLiters float64 `json:"water_liters"`
+}
+
+type CategoryImpactResponse struct {
+	ImpactCategoryID string                `json:"impact_category_id"`
+	Name             string                `json:"name"`
+	Points           int                   `json:"points"`
+	Metrics          ImpactMetricsResponse `json:"metrics"`
+}
+
+type MonthlyImpactResponse struct {
+	Month   string                `json:"month"`
+	Points  int                   `json:"points"`
+	Metrics ImpactMetricsResponse `json:"metrics"`
+}
+
+type UserImpactResponse struct {
+	TotalPoints int                      `json:"total_points"`
+	Metrics     ImpactMetricsResponse    `json:"metrics"`
+	Categories  []CategoryImpactResponse `json:"categories"`
+	Monthly     []MonthlyImpactResponse  `json:"monthly"`
+}
+
+type ConversionFactorResponse struct {
+	Metric   string  `json:"metric"`
+	PerPoint float64 `json:"per_point"`
+}
+
+func toImpactCategoryResponse(category impacts.ImpactCategory) ImpactCategoryResponse {
+	response := ImpactCategoryResponse{
+		ID:          category.ID,
+		Name:        category.Name,
+		ImpactPoint: category.ImpactPoint,
+		Description: category.Description,
+		Icon:        category.Icon,
+		Color:       category.Color,
+		ParentID:    category.ParentID,
+	}
+	for _, child := range category.Children {
+		response.Children = append(response.Children, toImpactCategoryResponse(child))
+	}
+	return response
+}
+
+func toUsageItemResponses(items []impacts.UsageItem) []UsageItemResponse {
+	response := []UsageItemResponse{}
+	for _, item := range items {
+		response = append(response, UsageItemResponse{ID: item.ID, Name: item.Name})
+	}
+	return response
+}
+
+func toCategoryUsageResponse(usage impacts.CategoryUsage) CategoryUsageResponse {
+	return CategoryUsageResponse{
+		Products:   toUsageItemResponses(usage.Products),
+		Challenges: toUsageItemResponses(usage.Challenges),
+		Articles:   toUsageItemResponses(usage.Articles),
+		Children:   toUsageItemResponses(usage.Children),
+	}
+}
+
+func toMetricsResponse(metrics impacts.ImpactMetrics) ImpactMetricsResponse {
+	return ImpactMetricsResponse{
+		CO2Kg:       metrics.CO2Kg,
+		PlasticKg:   metrics.PlasticKg,
+		WaterLiters: metrics.WaterLiters,
+	}
+}
+
+func (uir UserImpactResponse) ToResponse(summary impacts.UserImpactSummary) UserImpactResponse {
+	response := UserImpactResponse{
+		TotalPoints: summary.TotalPoints,
+		Metrics:     toMetricsResponse(summary.Metrics),
+		Categories:  []CategoryImpactResponse{},
+		Monthly:     []MonthlyImpactResponse{},
+	}
+
+	for _, category := range summary.Categories {
+		response.Categories = append(response.Categories, CategoryImpactResponse{
+			ImpactCategoryID: category.ImpactCategoryID,
+			Name:             category.Name,
+			Points:           category.Points,
+			Metrics:          toMetricsResponse(category.Metrics),
+		})
+	}
+
+	for _, month := range summary.Monthly {
+		response.Monthly = append(response.Monthly, MonthlyImpactResponse{
+			Month:   month.Month,
+			Points:  month.Points,
+			Metrics: toMetricsResponse(month.Metrics),
+		})
+	}
+
+	return response
+}
+
+func toConversionFactorResponses(factors []impacts.ConversionFactor) []ConversionFactorResponse {
+	response := []ConversionFactorResponse{}
+	for _, factor := range factors {
+		response = append(response, ConversionFactorResponse{
+			Metric:   factor.Metric,
+			PerPoint: factor.PerPoint,
+		})
+	}
+	return response
 }
@@ -1,31 +1,127 @@
 package impacts
 
-import "github.com/labstack/echo/v4"
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Metrics the impact points of a category can be converted into.
+const (
+	MetricCO2     = "co2_kg"
+	MetricPlastic = "plastic_kg"
+	MetricWater   = "water_liters"
+)
+
+var Metrics = []string{MetricCO2, MetricPlastic, MetricWater}
 
 type ImpactCategory struct {
 	ID          string
 	Name        string
 	ImpactPoint int
 	Description string
+	Icon        string
+	Color       string
+	ParentID    string
+	Children    []ImpactCategory
+}
+
+// UsageItem is a product, challenge, article or child category still linked
+// to an impact category.
+type UsageItem struct {
+	ID   string
+	Name string
+}
+
+type CategoryUsage struct {
+	Products   []UsageItem
+	Challenges []UsageItem
+	Articles   []UsageItem
+	Children   []UsageItem
+}
+
+func (u CategoryUsage) InUse() bool {
+	return len(u.Products) > 0 || len(u.Challenges) > 0 || len(u.Articles) > 0 || len(u.Children) > 0
+}
+
+type ConversionFactor struct {
+	ID               string
+	ImpactCategoryID string
+	Metric           string
+	PerPoint         float64
+}
+
+type ImpactMetrics struct {
+	CO2Kg       float64
+	PlasticKg   float64
+	WaterLiters float64
+}
+
+type CategoryImpact struct {
+	ImpactCategoryID string
+	Name             string
+	Points           int
+	Metrics          ImpactMetrics
+}
+
+type MonthlyImpact struct {
+	Month   string
+	Points  int
+	Metrics ImpactMetrics
+}
+
+// CategoryPoints is the sum of ledger points for one category, optionally
+// within one month (formatted as YYYY-MM).
+type CategoryPoints struct {
+	ImpactCategoryID string
+	Name             string
+	Month            string
+	Points           int
+}
+
+type UserImpactSummary struct {
+	TotalPoints int
+	Metrics     ImpactMetrics
+	Categories  []CategoryImpact
+	Monthly     []MonthlyImpact
 }
 
 type ImpactServiceInterface interface {
 	GetAll() ([]ImpactCategory, error)
 	GetByID(ID string) (ImpactCategory, error)
 	Create(ImpactCategory) error
-	Delete(ImpactCategory) error
+	Update(ImpactCategory) error
+	Delete(category ImpactCategory, reassignTo string) (CategoryUsage, error)
+
+	GetUserImpact(userID string, months int) (UserImpactSummary, error)
+	GetConversionFactors(categoryID string) ([]ConversionFactor, error)
+	UpdateConversionFactors(categoryID string, factors map[string]float64) ([]ConversionFactor, error)
 }
 
 type ImpactRepositoryInterface interface {
 	GetAll() ([]ImpactCategory, error)
 	GetByID(ID string) (ImpactCategory, error)
 	Create(ImpactCategory) error
+	Update(ImpactCategory) error
 	Delete(ImpactCategory) error
+	GetChildren(parentID string) ([]ImpactCategory, error)
+	GetUsage(categoryID string) (CategoryUsage, error)
+	ReassignAndDelete(categoryID, targetID string) error
+
+	GetUserPointsByCategory(userID string) ([]CategoryPoints, error)
+	GetUserMonthlyPoints(userID string, since time.Time) ([]CategoryPoints, error)
+	GetConversionFactors(categoryID string) ([]ConversionFactor, error)
+	SaveConversionFactors(categoryID string, factors []ConversionFactor) error
 }
 
 type ImpactControllerInterface interface {
 	GetAll(c echo.Context) error
 	GetByID(c echo.Context) error
 	Create(c echo.Context) error
+	Update(c echo.Context) error
 	Delete(c echo.Context) error
+
+	GetMyImpact(c echo.Context) error
+	GetConversionFactors(c echo.Context) error
+	UpdateConversionFactors(c echo.Context) error
 }
@@ -1,15 +1,56 @@
 package repository
 
-import "gorm.io/gorm"
+import (
+	users "greenenvironment/features/users/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type ImpactCategory struct {
 	*gorm.Model
-	ID          string `gorm:"primary_key;type:varchar(50);not null;column:id"`
-	Name        string `gorm:"type:varchar(255);not null;column:name"`
-	ImpactPoint int    `gorm:"type:int;not null;column:impact_point"`
-	Description string `gorm:"type:TEXT;column:description"`
+	ID          string          `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	Name        string          `gorm:"type:varchar(255);not null;column:name"`
+	ImpactPoint int             `gorm:"type:int;not null;column:impact_point"`
+	Description string          `gorm:"type:TEXT;column:description"`
+	Icon        string          `gorm:"type:varchar(255);column:icon"`
+	Color       string          `gorm:"type:varchar(7);column:color"`
+	ParentID    *string         `gorm:"type:varchar(50);index;column:parent_id"`
+	Parent      *ImpactCategory `gorm:"foreignKey:ParentID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+}
+
+// ImpactLedger records the impact points a user earned from one purchase or
+// completed challenge. A source is only ever credited once per category.
+type ImpactLedger struct {
+	*gorm.Model
+	ID               string         `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	UserID           string         `gorm:"type:varchar(50);not null;index;column:user_id"`
+	ImpactCategoryID string         `gorm:"type:varchar(50);not null;uniqueIndex:idx_impact_ledger_source;column:impact_category_id"`
+	SourceType       string         `gorm:"type:enum('purchase','challenge');not null;uniqueIndex:idx_impact_ledger_source;column:source_type"`
+	SourceID         string         `gorm:"type:varchar(50);not null;uniqueIndex:idx_impact_ledger_source;column:source_id"`
+	Points           int            `gorm:"type:int;not null;column:points"`
+	OccurredAt       time.Time      `gorm:"type:datetime;not null;column:occurred_at"`
+	User             users.User     `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ImpactCategory   ImpactCategory `gorm:"foreignKey:ImpactCategoryID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type ImpactConversionFactor struct {
+	*gorm.Model
+	ID               string         `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	ImpactCategoryID string         `gorm:"type:varchar(50);not null;uniqueIndex:idx_conversion_category_metric;column:impact_category_id"`
+	Metric           string         `gorm:"type:varchar(50);not null;uniqueIndex:idx_conversion_category_metric;column:metric"`
+	PerPoint         float64        `gorm:"type:decimal(10,4);not null;default:0;column:per_point"`
+	ImpactCategory   ImpactCategory `gorm:"foreignKey:ImpactCategoryID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
 
 func (ImpactCategory) TableName() string {
 	return "impact_categories"
 }
+
+func (ImpactLedger) TableName() string {
+	return "impact_ledgers"
+}
+
+func (ImpactConversionFactor) TableName() string {
+	return "impact_conversion_factors"
+}
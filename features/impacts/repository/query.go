@@ -3,7 +3,9 @@ package repository
 import (
 	"greenenvironment/constant"
 	"greenenvironment/features/impacts"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -17,20 +19,20 @@ func NewImpactRepository(db *gorm.DB) impacts.ImpactRepositoryInterface {
 	}
 }
 func (ir *ImpactRepository) GetAll() ([]impacts.ImpactCategory, error) {
-	var categories []impacts.ImpactCategory
+	var categories []ImpactCategory
 	err := ir.DB.Model(&ImpactCategory{}).Find(&categories).Error
 	if err != nil {
 		return nil, constant.ErrImpactCategoryNotFound
 	}
-	return categories, nil
+	return toImpactCategoryEntities(categories), nil
 }
 func (ir *ImpactRepository) GetByID(ID string) (impacts.ImpactCategory, error) {
-	var category impacts.ImpactCategory
+	var category ImpactCategory
 	err := ir.DB.First(&category, "id = ?", ID).Error
 	if err != nil {
 		return impacts.ImpactCategory{}, constant.ErrImpactCategoryNotFound
 	}
-	return category, nil
+	return toImpactCategoryEntity(category), nil
 }
 func (ir *ImpactRepository) Create(category impacts.ImpactCategory) error {
 	impactData := ImpactCategory{
@@ -38,6 +40,9 @@ func (ir *ImpactRepository) Create(category impacts.ImpactCategory) error {
 		Name:        category.Name,
 		ImpactPoint: category.ImpactPoint,
 		Description: category.Description,
+		Icon:        category.Icon,
+		Color:       category.Color,
+		ParentID:    nullableString(category.ParentID),
 	}
 	err := ir.DB.Create(&impactData).Error
 	if err != nil {
@@ -45,6 +50,23 @@ func (ir *ImpactRepository) Create(category impacts.ImpactCategory) error {
 	}
 	return nil
 }
+func (ir *ImpactRepository) Update(category impacts.ImpactCategory) error {
+	result := ir.DB.Model(&ImpactCategory{}).Where("id = ?", category.ID).Updates(map[string]interface{}{
+		"name":         category.Name,
+		"impact_point": category.ImpactPoint,
+		"description":  category.Description,
+		"icon":         category.Icon,
+		"color":        category.Color,
+		"parent_id":    nullableString(category.ParentID),
+	})
+	if result.Error != nil {
+		return constant.ErrUpdateImpactCategory
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrImpactCategoryNotFound
+	}
+	return nil
+}
 func (ir *ImpactRepository) Delete(category impacts.ImpactCategory) error {
 	result := ir.DB.Where("id = ?", category.ID).Delete(&ImpactCategory{})
 	if result.Error != nil {
@@ -55,3 +77,230 @@ func (ir *ImpactRepository) Delete(category impacts.ImpactCategory) error {
 	}
 	return nil
 }
+
+func (ir *ImpactRepository) GetChildren(parentID string) ([]impacts.ImpactCategory, error) {
+	var categories []ImpactCategory
+	err := ir.DB.Where("parent_id = ?", parentID).Order("name").Find(&categories).Error
+	if err != nil {
+		return nil, constant.ErrImpactCategoryNotFound
+	}
+	return toImpactCategoryEntities(categories), nil
+}
+
+func (ir *ImpactRepository) GetUsage(categoryID string) (impacts.CategoryUsage, error) {
+	usage := impacts.CategoryUsage{
+		Products:   []impacts.UsageItem{},
+		Challenges: []impacts.UsageItem{},
+		Articles:   []impacts.UsageItem{},
+		Children:   []impacts.UsageItem{},
+	}
+
+	err := ir.DB.Table("product_impact_categories").
+		Select("products.id, products.name").
+		Joins("JOIN products ON products.id = product_impact_categories.product_id AND products.deleted_at IS NULL").
+		Where("product_impact_categories.impact_category_id = ? AND product_impact_categories.deleted_at IS NULL", categoryID).
+		Scan(&usage.Products).Error
+	if err != nil {
+		return impacts.CategoryUsage{}, constant.ErrImpactCategoryNotFound
+	}
+
+	err = ir.DB.Table("challenge_impact_categories").
+		Select("challenges.id, challenges.title AS name").
+		Joins("JOIN challenges ON challenges.id = challenge_impact_categories.challenge_id AND challenges.deleted_at IS NULL").
+		Where("challenge_impact_categories.impact_category_id = ? AND challenge_impact_categories.deleted_at IS NULL", categoryID).
+		Scan(&usage.Challenges).Error
+	if err != nil {
+		return impacts.CategoryUsage{}, constant.ErrImpactCategoryNotFound
+	}
+
+	err = ir.DB.Table("article_impact_categories").
+		Select("articles.id, articles.title AS name").
+		Joins("JOIN articles ON articles.id = article_impact_categories.article_id AND articles.deleted_at IS NULL").
+		Where("article_impact_categories.impact_category_id = ? AND article_impact_categories.deleted_at IS NULL", categoryID).
+		Scan(&usage.Articles).Error
+	if err != nil {
+		return impacts.CategoryUsage{}, constant.ErrImpactCategoryNotFound
+	}
+
+	err = ir.DB.Model(&ImpactCategory{}).
+		Select("id, name").
+		Where("parent_id = ?", categoryID).
+		Scan(&usage.Children).Error
+	if err != nil {
+		return impacts.CategoryUsage{}, constant.ErrImpactCategoryNotFound
+	}
+
+	return usage, nil
+}
+
+// ReassignAndDelete moves every product, challenge and article linked to the
+// category over to the target, hands its child categories to its own parent
+// and then soft deletes it. Items already linked to the target just lose the old link.
+func (ir *ImpactRepository) ReassignAndDelete(categoryID, targetID string) error {
+	err := ir.DB.Transaction(func(tx *gorm.DB) error {
+		var category ImpactCategory
+		if err := tx.First(&category, "id = ?", categoryID).Error; err != nil {
+			return constant.ErrImpactCategoryNotFound
+		}
+
+		links := []struct {
+			table  string
+			column string
+		}{
+			{"product_impact_categories", "product_id"},
+			{"challenge_impact_categories", "challenge_id"},
+			{"article_impact_categories", "article_id"},
+		}
+		for _, link := range links {
+			var linked []string
+			err := tx.Table(link.table).
+				Where("impact_category_id = ? AND deleted_at IS NULL", targetID).
+				Pluck(link.column, &linked).Error
+			if err != nil {
+				return err
+			}
+
+			if len(linked) > 0 {
+				err = tx.Table(link.table).
+					Where("impact_category_id = ? AND deleted_at IS NULL AND "+link.column+" IN ?", categoryID, linked).
+					Update("deleted_at", time.Now()).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			err = tx.Table(link.table).
+				Where("impact_category_id = ? AND deleted_at IS NULL", categoryID).
+				Update("impact_category_id", targetID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err := tx.Model(&ImpactCategory{}).
+			Where("parent_id = ?", categoryID).
+			Update("parent_id", category.ParentID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&category).Error
+	})
+	if err == constant.ErrImpactCategoryNotFound {
+		return err
+	}
+	if err != nil {
+		return constant.ErrDeleteImpactCategory
+	}
+	return nil
+}
+
+func (ir *ImpactRepository) GetUserPointsByCategory(userID string) ([]impacts.CategoryPoints, error) {
+	var points []impacts.CategoryPoints
+	err := ir.DB.Model(&ImpactLedger{}).
+		Select("impact_ledgers.impact_category_id, impact_categories.name, SUM(impact_ledgers.points) AS points").
+		Joins("JOIN impact_categories ON impact_categories.id = impact_ledgers.impact_category_id").
+		Where("impact_ledgers.user_id = ?", userID).
+		Group("impact_ledgers.impact_category_id, impact_categories.name").
+		Order("points DESC").
+		Scan(&points).Error
+	if err != nil {
+		return nil, constant.ErrGetUserImpact
+	}
+	return points, nil
+}
+
+func (ir *ImpactRepository) GetUserMonthlyPoints(userID string, since time.Time) ([]impacts.CategoryPoints, error) {
+	var points []impacts.CategoryPoints
+	err := ir.DB.Model(&ImpactLedger{}).
+		Select("impact_category_id, DATE_FORMAT(occurred_at, '%Y-%m') AS month, SUM(points) AS points").
+		Where("user_id = ? AND occurred_at >= ?", userID, since).
+		Group("impact_category_id, month").
+		Order("month").
+		Scan(&points).Error
+	if err != nil {
+		return nil, constant.ErrGetUserImpact
+	}
+	return points, nil
+}
+
+func (ir *ImpactRepository) GetConversionFactors(categoryID string) ([]impacts.ConversionFactor, error) {
+	query := ir.DB.Model(&ImpactConversionFactor{})
+	if categoryID != "" {
+		query = query.Where("impact_category_id = ?", categoryID)
+	}
+
+	var factors []ImpactConversionFactor
+	if err := query.Find(&factors).Error; err != nil {
+		return nil, constant.ErrGetConversionFactor
+	}
+
+	var result []impacts.ConversionFactor
+	for _, factor := range factors {
+		result = append(result, impacts.ConversionFactor{
+			ID:               factor.ID,
+			ImpactCategoryID: factor.ImpactCategoryID,
+			Metric:           factor.Metric,
+			PerPoint:         factor.PerPoint,
+		})
+	}
+	return result, nil
+}
+
+func (ir *ImpactRepository) SaveConversionFactors(categoryID string, factors []impacts.ConversionFactor) error {
+	err := ir.DB.Transaction(func(tx *gorm.DB) error {
+		for _, factor := range factors {
+			err := tx.Unscoped().
+				Where("impact_category_id = ? AND metric = ?", categoryID, factor.Metric).
+				Delete(&ImpactConversionFactor{}).Error
+			if err != nil {
+				return err
+			}
+
+			err = tx.Create(&ImpactConversionFactor{
+				ID:               uuid.New().String(),
+				ImpactCategoryID: categoryID,
+				Metric:           factor.Metric,
+				PerPoint:         factor.PerPoint,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return constant.ErrUpdateConversionFactor
+	}
+	return nil
+}
+
+func toImpactCategoryEntity(category ImpactCategory) impacts.ImpactCategory {
+	entity := impacts.ImpactCategory{
+		ID:          category.ID,
+		Name:        category.Name,
+		ImpactPoint: category.ImpactPoint,
+		Description: category.Description,
+		Icon:        category.Icon,
+		Color:       category.Color,
+	}
+	if category.ParentID != nil {
+		entity.ParentID = *category.ParentID
+	}
+	return entity
+}
+
+func toImpactCategoryEntities(categories []ImpactCategory) []impacts.ImpactCategory {
+	var result []impacts.ImpactCategory
+	for _, category := range categories {
+		result = append(result, toImpactCategoryEntity(category))
+	}
+	return result
+}
+
+func nullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -3,8 +3,14 @@ package service
 import (
 	"greenenvironment/constant"
 	"greenenvironment/features/impacts"
+	"regexp"
+	"time"
 )
 
+const defaultImpactTrendMonths = 12
+
+var hexColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
 type ImpactService struct {
 	impactRepo impacts.ImpactRepositoryInterface
 }
@@ -23,14 +29,208 @@ func (is *ImpactService) GetByID(ID string) (impacts.ImpactCategory, error) {
 	if ID == "" {
 		return impacts.ImpactCategory{}, constant.ErrImpactCategoryNotFound
 	}
-	return is.impactRepo.GetByID(ID)
+	category, err := is.impactRepo.GetByID(ID)
+	if err != nil {
+		return impacts.ImpactCategory{}, err
+	}
+
+	category.Children, err = is.impactRepo.GetChildren(ID)
+	if err != nil {
+		return impacts.ImpactCategory{}, err
+	}
+	return category, nil
 }
 
 func (is *ImpactService) Create(category impacts.ImpactCategory) error {
-
+	if err := is.validateCategory(category); err != nil {
+		return err
+	}
 	return is.impactRepo.Create(category)
 }
 
-func (is *ImpactService) Delete(category impacts.ImpactCategory) error {
-	return is.impactRepo.Delete(category)
+func (is *ImpactService) Update(category impacts.ImpactCategory) error {
+	if _, err := is.impactRepo.GetByID(category.ID); err != nil {
+		return err
+	}
+	if err := is.validateCategory(category); err != nil {
+		return err
+	}
+	return is.impactRepo.Update(category)
+}
+
+// Delete refuses to remove a category that is still linked to products,
+// challenges or child categories and returns what is linked, unless a
+// reassignment target is given to take those links over.
+func (is *ImpactService) Delete(category impacts.ImpactCategory, reassignTo string) (impacts.CategoryUsage, error) {
+	if _, err := is.impactRepo.GetByID(category.ID); err != nil {
+		return impacts.CategoryUsage{}, err
+	}
+
+	if reassignTo != "" {
+		if reassignTo == category.ID {
+			return impacts.CategoryUsage{}, constant.ErrInvalidReassignTarget
+		}
+		if _, err := is.impactRepo.GetByID(reassignTo); err != nil {
+			return impacts.CategoryUsage{}, constant.ErrInvalidReassignTarget
+		}
+		return impacts.CategoryUsage{}, is.impactRepo.ReassignAndDelete(category.ID, reassignTo)
+	}
+
+	usage, err := is.impactRepo.GetUsage(category.ID)
+	if err != nil {
+		return impacts.CategoryUsage{}, err
+	}
+	if usage.InUse() {
+		return usage, constant.ErrImpactCategoryInUse
+	}
+
+	return impacts.CategoryUsage{}, is.impactRepo.Delete(category)
+}
+
+func (is *ImpactService) validateCategory(category impacts.ImpactCategory) error {
+	if category.Color != "" && !hexColorPattern.MatchString(category.Color) {
+		return constant.ErrInvalidImpactCategoryColor
+	}
+	if category.ParentID == "" {
+		return nil
+	}
+
+	// Walk up from the new parent; meeting the category itself on the way
+	// means the change would create a cycle.
+	visited := map[string]bool{category.ID: true}
+	parentID := category.ParentID
+	for parentID != "" {
+		if visited[parentID] {
+			return constant.ErrInvalidImpactCategoryParent
+		}
+		visited[parentID] = true
+		parent, err := is.impactRepo.GetByID(parentID)
+		if err != nil {
+			return constant.ErrInvalidImpactCategoryParent
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+func (is *ImpactService) GetUserImpact(userID string, months int) (impacts.UserImpactSummary, error) {
+	if months <= 0 || months > 36 {
+		months = defaultImpactTrendMonths
+	}
+
+	totals, err := is.impactRepo.GetUserPointsByCategory(userID)
+	if err != nil {
+		return impacts.UserImpactSummary{}, err
+	}
+
+	factors, err := is.impactRepo.GetConversionFactors("")
+	if err != nil {
+		return impacts.UserImpactSummary{}, err
+	}
+	perPoint := make(map[string]map[string]float64)
+	for _, factor := range factors {
+		if perPoint[factor.ImpactCategoryID] == nil {
+			perPoint[factor.ImpactCategoryID] = make(map[string]float64)
+		}
+		perPoint[factor.ImpactCategoryID][factor.Metric] = factor.PerPoint
+	}
+
+	summary := impacts.UserImpactSummary{
+		Categories: []impacts.CategoryImpact{},
+		Monthly:    []impacts.MonthlyImpact{},
+	}
+	for _, total := range totals {
+		metrics := convertPoints(total.Points, perPoint[total.ImpactCategoryID])
+		summary.TotalPoints += total.Points
+		summary.Metrics = addMetrics(summary.Metrics, metrics)
+		summary.Categories = append(summary.Categories, impacts.CategoryImpact{
+			ImpactCategoryID: total.ImpactCategoryID,
+			Name:             total.Name,
+			Points:           total.Points,
+			Metrics:          metrics,
+		})
+	}
+
+	// Every month in the window is listed, including the ones without activity.
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -(months - 1), 0)
+	monthIndex := make(map[string]int, months)
+	for i := 0; i < months; i++ {
+		month := start.AddDate(0, i, 0).Format("2006-01")
+		monthIndex[month] = i
+		summary.Monthly = append(summary.Monthly, impacts.MonthlyImpact{Month: month})
+	}
+
+	monthly, err := is.impactRepo.GetUserMonthlyPoints(userID, start)
+	if err != nil {
+		return impacts.UserImpactSummary{}, err
+	}
+	for _, entry := range monthly {
+		i, ok := monthIndex[entry.Month]
+		if !ok {
+			continue
+		}
+		summary.Monthly[i].Points += entry.Points
+		summary.Monthly[i].Metrics = addMetrics(summary.Monthly[i].Metrics, convertPoints(entry.Points, perPoint[entry.ImpactCategoryID]))
+	}
+
+	return summary, nil
+}
+
+func (is *ImpactService) GetConversionFactors(categoryID string) ([]impacts.ConversionFactor, error) {
+	if _, err := is.impactRepo.GetByID(categoryID); err != nil {
+		return nil, err
+	}
+	return is.impactRepo.GetConversionFactors(categoryID)
+}
+
+func (is *ImpactService) UpdateConversionFactors(categoryID string, factors map[string]float64) ([]impacts.ConversionFactor, error) {
+	if len(factors) == 0 {
+		return nil, constant.ErrInvalidConversionFactor
+	}
+
+	var updates []impacts.ConversionFactor
+	for _, metric := range impacts.Metrics {
+		perPoint, ok := factors[metric]
+		if !ok {
+			continue
+		}
+		if perPoint < 0 {
+			return nil, constant.ErrInvalidConversionFactor
+		}
+		updates = append(updates, impacts.ConversionFactor{
+			ImpactCategoryID: categoryID,
+			Metric:           metric,
+			PerPoint:         perPoint,
+		})
+	}
+	if len(updates) != len(factors) {
+		return nil, constant.ErrInvalidConversionFactor
+	}
+
+	if _, err := is.impactRepo.GetByID(categoryID); err != nil {
+		return nil, err
+	}
+
+	if err := is.impactRepo.SaveConversionFactors(categoryID, updates); err != nil {
+		return nil, err
+	}
+
+	return is.impactRepo.GetConversionFactors(categoryID)
+}
+
+func convertPoints(points int, perPoint map[string]float64) impacts.ImpactMetrics {
+	return impacts.ImpactMetrics{
+		CO2Kg:       float64(points) * perPoint[impacts.MetricCO2],
+		PlasticKg:   float64(points) * perPoint[impacts.MetricPlastic],
+		WaterLiters: float64(points) * perPoint[impacts.MetricWater],
+	}
+}
+
+func addMetrics(a, b impacts.ImpactMetrics) impacts.ImpactMetrics {
+	return impacts.ImpactMetrics{
+		CO2Kg:       a.CO2Kg + b.CO2Kg,
+		PlasticKg:   a.PlasticKg + b.PlasticKg,
+		WaterLiters: a.WaterLiters + b.WaterLiters,
+	}
 }
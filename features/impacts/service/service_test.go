@@ -3,7 +3,9 @@ package service
 import (
 	"errors"
 	"testing"
+	"time"
 
+	"greenenvironment/constant"
 	"greenenvironment/features/impacts"
 
 	"github.com/stretchr/testify/assert"
@@ -34,6 +36,46 @@ func (m *MockImpactRepository) Delete(category impacts.ImpactCategory) error {
 	return args.Error(0)
 }
 
+func (m *MockImpactRepository) GetUserPointsByCategory(userID string) ([]impacts.CategoryPoints, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]impacts.CategoryPoints), args.Error(1)
+}
+
+func (m *MockImpactRepository) GetUserMonthlyPoints(userID string, since time.Time) ([]impacts.CategoryPoints, error) {
+	args := m.Called(userID, since)
+	return args.Get(0).([]impacts.CategoryPoints), args.Error(1)
+}
+
+func (m *MockImpactRepository) GetConversionFactors(categoryID string) ([]impacts.ConversionFactor, error) {
+	args := m.Called(categoryID)
+	return args.Get(0).([]impacts.ConversionFactor), args.Error(1)
+}
+
+func (m *MockImpactRepository) SaveConversionFactors(categoryID string, factors []impacts.ConversionFactor) error {
+	args := m.Called(categoryID, factors)
+	return args.Error(0)
+}
+
+func (m *MockImpactRepository) Update(category impacts.ImpactCategory) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockImpactRepository) GetChildren(parentID string) ([]impacts.ImpactCategory, error) {
+	args := m.Called(parentID)
+	return args.Get(0).([]impacts.ImpactCategory), args.Error(1)
+}
+
+func (m *MockImpactRepository) GetUsage(categoryID string) (impacts.CategoryUsage, error) {
+	args := m.Called(categoryID)
+	return args.Get(0).(impacts.CategoryUsage), args.Error(1)
+}
+
+func (m *MockImpactRepository) ReassignAndDelete(categoryID, targetID string) error {
+	args := m.Called(categoryID, targetID)
+	return args.Error(0)
+}
+
 func TestGetAll(t *testing.T) {
 	mockRepo := new(MockImpactRepository)
 	mockRepo.On("GetAll").Return([]impacts.ImpactCategory{}, nil)
@@ -50,12 +92,14 @@ func TestGetByID(t *testing.T) {
 	mockRepo := new(MockImpactRepository)
 	mockCategory := impacts.ImpactCategory{ID: "1", Name: "Test"}
 	mockRepo.On("GetByID", "1").Return(mockCategory, nil)
+	mockRepo.On("GetChildren", "1").Return([]impacts.ImpactCategory{{ID: "2", Name: "Child", ParentID: "1"}}, nil)
 
 	service := NewNewImpactService(mockRepo)
 	category, err := service.GetByID("1")
 
 	assert.NoError(t, err)
 	assert.Equal(t, "1", category.ID)
+	assert.Len(t, category.Children, 1)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -86,11 +130,198 @@ func TestCreate(t *testing.T) {
 func TestDelete(t *testing.T) {
 	mockRepo := new(MockImpactRepository)
 	mockCategory := impacts.ImpactCategory{ID: "1", Name: "Test"}
-	mockRepo.On("Delete", mockCategory).Return(nil)
+	mockRepo.On("GetByID", "1").Return(mockCategory, nil)
+	mockRepo.On("GetUsage", "1").Return(impacts.CategoryUsage{}, nil)
+	mockRepo.On("Delete", impacts.ImpactCategory{ID: "1"}).Return(nil)
+
+	service := NewNewImpactService(mockRepo)
+	_, err := service.Delete(impacts.ImpactCategory{ID: "1"}, "")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDelete_InUse(t *testing.T) {
+	mockRepo := new(MockImpactRepository)
+	usage := impacts.CategoryUsage{
+		Products:   []impacts.UsageItem{{ID: "p1", Name: "Bamboo Straw"}},
+		Challenges: []impacts.UsageItem{{ID: "c1", Name: "No Straw Week"}},
+	}
+	mockRepo.On("GetByID", "1").Return(impacts.ImpactCategory{ID: "1"}, nil)
+	mockRepo.On("GetUsage", "1").Return(usage, nil)
+
+	service := NewNewImpactService(mockRepo)
+	result, err := service.Delete(impacts.ImpactCategory{ID: "1"}, "")
+
+	assert.Equal(t, constant.ErrImpactCategoryInUse, err)
+	assert.Equal(t, usage, result)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestDelete_Reassign(t *testing.T) {
+	mockRepo := new(MockImpactRepository)
+	mockRepo.On("GetByID", "1").Return(impacts.ImpactCategory{ID: "1"}, nil)
+	mockRepo.On("GetByID", "2").Return(impacts.ImpactCategory{ID: "2"}, nil)
+	mockRepo.On("ReassignAndDelete", "1", "2").Return(nil)
+
+	service := NewNewImpactService(mockRepo)
+	_, err := service.Delete(impacts.ImpactCategory{ID: "1"}, "2")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetUsage", mock.Anything)
+}
+
+func TestDelete_InvalidReassignTarget(t *testing.T) {
+	mockRepo := new(MockImpactRepository)
+	mockRepo.On("GetByID", "1").Return(impacts.ImpactCategory{ID: "1"}, nil)
+	mockRepo.On("GetByID", "missing").Return(impacts.ImpactCategory{}, constant.ErrImpactCategoryNotFound)
+
+	service := NewNewImpactService(mockRepo)
+
+	_, err := service.Delete(impacts.ImpactCategory{ID: "1"}, "1")
+	assert.Equal(t, constant.ErrInvalidReassignTarget, err)
+
+	_, err = service.Delete(impacts.ImpactCategory{ID: "1"}, "missing")
+	assert.Equal(t, constant.ErrInvalidReassignTarget, err)
+
+	mockRepo.AssertNotCalled(t, "ReassignAndDelete", mock.Anything, mock.Anything)
+}
+
+func TestUpdate(t *testing.T) {
+	mockRepo := new(MockImpactRepository)
+	updated := impacts.ImpactCategory{ID: "2", Name: "Ocean Plastic", Color: "#1E88E5", Icon: "wave", ParentID: "1"}
+	mockRepo.On("GetByID", "2").Return(impacts.ImpactCategory{ID: "2"}, nil)
+	mockRepo.On("GetByID", "1").Return(impacts.ImpactCategory{ID: "1"}, nil)
+	mockRepo.On("Update", updated).Return(nil)
 
 	service := NewNewImpactService(mockRepo)
-	err := service.Delete(mockCategory)
+	err := service.Update(updated)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestUpdate_InvalidColor(t *testing.T) {
+	mockRepo := new(MockImpactRepository)
+	mockRepo.On("GetByID", "1").Return(impacts.ImpactCategory{ID: "1"}, nil)
+
+	service := NewNewImpactService(mockRepo)
+	err := service.Update(impacts.ImpactCategory{ID: "1", Name: "Test", Color: "green"})
+
+	assert.Equal(t, constant.ErrInvalidImpactCategoryColor, err)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestUpdate_ParentCycle(t *testing.T) {
+	mockRepo := new(MockImpactRepository)
+	// 1 is the parent of 2, so 1 cannot be moved under 2.
+	mockRepo.On("GetByID", "1").Return(impacts.ImpactCategory{ID: "1"}, nil)
+	mockRepo.On("GetByID", "2").Return(impacts.ImpactCategory{ID: "2", ParentID: "1"}, nil)
+
+	service := NewNewImpactService(mockRepo)
+
+	err := service.Update(impacts.ImpactCategory{ID: "1", Name: "Test", ParentID: "2"})
+	assert.Equal(t, constant.ErrInvalidImpactCategoryParent, err)
+
+	err = service.Update(impacts.ImpactCategory{ID: "1", Name: "Test", ParentID: "1"})
+	assert.Equal(t, constant.ErrInvalidImpactCategoryParent, err)
+
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestCreate_MissingParent(t *testing.T) {
+	mockRepo := new(MockImpactRepository)
+	mockRepo.On("GetByID", "missing").Return(impacts.ImpactCategory{}, constant.ErrImpactCategoryNotFound)
+
+	service := NewNewImpactService(mockRepo)
+	err := service.Create(impacts.ImpactCategory{ID: "1", Name: "Test", ParentID: "missing"})
+
+	assert.Equal(t, constant.ErrInvalidImpactCategoryParent, err)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestGetUserImpact(t *testing.T) {
+	mockRepo := new(MockImpactRepository)
+	currentMonth := time.Now().Format("2006-01")
+
+	mockRepo.On("GetUserPointsByCategory", "user1").Return([]impacts.CategoryPoints{
+		{ImpactCategoryID: "plastic", Name: "Plastic", Points: 40},
+		{ImpactCategoryID: "water", Name: "Water", Points: 10},
+	}, nil)
+	mockRepo.On("GetConversionFactors", "").Return([]impacts.ConversionFactor{
+		{ImpactCategoryID: "plastic", Metric: impacts.MetricPlastic, PerPoint: 0.05},
+		{ImpactCategoryID: "plastic", Metric: impacts.MetricCO2, PerPoint: 0.1},
+		{ImpactCategoryID: "water", Metric: impacts.MetricWater, PerPoint: 3},
+	}, nil)
+	mockRepo.On("GetUserMonthlyPoints", "user1", mock.Anything).Return([]impacts.CategoryPoints{
+		{ImpactCategoryID: "plastic", Month: currentMonth, Points: 20},
+		{ImpactCategoryID: "water", Month: currentMonth, Points: 10},
+	}, nil)
+
+	service := NewNewImpactService(mockRepo)
+	summary, err := service.GetUserImpact("user1", 6)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 50, summary.TotalPoints)
+	assert.InDelta(t, 4.0, summary.Metrics.CO2Kg, 0.0001)
+	assert.InDelta(t, 2.0, summary.Metrics.PlasticKg, 0.0001)
+	assert.InDelta(t, 30.0, summary.Metrics.WaterLiters, 0.0001)
+	assert.Len(t, summary.Categories, 2)
+	assert.Len(t, summary.Monthly, 6)
+
+	last := summary.Monthly[len(summary.Monthly)-1]
+	assert.Equal(t, currentMonth, last.Month)
+	assert.Equal(t, 30, last.Points)
+	assert.InDelta(t, 30.0, last.Metrics.WaterLiters, 0.0001)
+	assert.Equal(t, 0, summary.Monthly[0].Points)
+}
+
+func TestGetUserImpact_Error(t *testing.T) {
+	mockRepo := new(MockImpactRepository)
+	mockRepo.On("GetUserPointsByCategory", "user1").Return([]impacts.CategoryPoints{}, constant.ErrGetUserImpact)
+
+	service := NewNewImpactService(mockRepo)
+	_, err := service.GetUserImpact("user1", 12)
+
+	assert.Equal(t, constant.ErrGetUserImpact, err)
+}
+
+func TestUpdateConversionFactors(t *testing.T) {
+	mockRepo := new(MockImpactRepository)
+	mockRepo.On("GetByID", "plastic").Return(impacts.ImpactCategory{ID: "plastic"}, nil)
+	mockRepo.On("SaveConversionFactors", "plastic", []impacts.ConversionFactor{
+		{ImpactCategoryID: "plastic", Metric: impacts.MetricCO2, PerPoint: 0.1},
+		{ImpactCategoryID: "plastic", Metric: impacts.MetricPlastic, PerPoint: 0.05},
+	}).Return(nil)
+	mockRepo.On("GetConversionFactors", "plastic").Return([]impacts.ConversionFactor{
+		{ImpactCategoryID: "plastic", Metric: impacts.MetricCO2, PerPoint: 0.1},
+		{ImpactCategoryID: "plastic", Metric: impacts.MetricPlastic, PerPoint: 0.05},
+	}, nil)
+
+	service := NewNewImpactService(mockRepo)
+	factors, err := service.UpdateConversionFactors("plastic", map[string]float64{
+		impacts.MetricPlastic: 0.05,
+		impacts.MetricCO2:     0.1,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, factors, 2)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateConversionFactors_Invalid(t *testing.T) {
+	mockRepo := new(MockImpactRepository)
+	service := NewNewImpactService(mockRepo)
+
+	_, err := service.UpdateConversionFactors("plastic", map[string]float64{impacts.MetricWater: -1})
+	assert.Equal(t, constant.ErrInvalidConversionFactor, err)
+
+	_, err = service.UpdateConversionFactors("plastic", map[string]float64{"trees": 1})
+	assert.Equal(t, constant.ErrInvalidConversionFactor, err)
+
+	_, err = service.UpdateConversionFactors("plastic", map[string]float64{})
+	assert.Equal(t, constant.ErrInvalidConversionFactor, err)
+
+	mockRepo.AssertNotCalled(t, "SaveConversionFactors", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,262 @@
+package controller
+
+import (
+	"errors"
+	"greenenvironment/constant"
+	"greenenvironment/features/knowledge"
+	"greenenvironment/helper"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type KnowledgeHandler struct {
+	knowledgeService knowledge.KnowledgeServiceInterface
+	jwt              helper.JWTInterface
+}
+
+func NewKnowledgeController(s knowledge.KnowledgeServiceInterface, j helper.JWTInterface) knowledge.KnowledgeControllerInterface {
+	return &KnowledgeHandler{
+		knowledgeService: s,
+		jwt:              j,
+	}
+}
+
+func (h *KnowledgeHandler) isAdmin(c echo.Context) bool {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return false
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return false
+	}
+
+	return h.jwt.ExtractUserToken(token)[constant.JWT_ROLE] == constant.RoleAdmin
+}
+
+// readUpload returns the content and format of the uploaded file, picking
+// the format from its extension.
+func readUpload(c echo.Context) (string, string, error) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return "", "", err
+	}
+
+	var format string
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".md", ".markdown":
+		format = knowledge.FormatMarkdown
+	case ".txt":
+		format = knowledge.FormatText
+	default:
+		return "", "", constant.ErrKnowledgeFormat
+	}
+	if file.Size > knowledge.MaxDocumentSize {
+		return "", "", constant.ErrKnowledgeTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", "", err
+	}
+	defer src.Close()
+
+	content, err := io.ReadAll(io.LimitReader(src, knowledge.MaxDocumentSize+1))
+	if err != nil {
+		return "", "", err
+	}
+	return string(content), format, nil
+}
+
+// Create Knowledge Document
+// @Summary      Upload a knowledge document
+// @Description  Upload an article or FAQ for the chatbot to answer from. Attach a markdown (.md) or text (.txt) file, or send the text in content with a format of markdown or text. The document is split into passages and indexed right away.
+// @Tags         Knowledge (Admin)
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        title          formData  string  true   "Title of the document"
+// @Param        source         formData  string  false  "Where the original can be found, such as a URL"
+// @Param        file           formData  file    false  "Markdown or text file, at most 1 MB"
+// @Param        content        formData  string  false  "Document text, used when no file is attached"
+// @Param        format         formData  string  false  "markdown (default) or text, used with content"
+// @Success      201  {object}  helper.Response{data=DocumentResponse} "Document uploaded successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Bad request"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/knowledge [post]
+func (h *KnowledgeHandler) Create(c echo.Context) error {
+	if !h.isAdmin(c) {
+		return helper.UnauthorizedError(c)
+	}
+
+	var request DocumentRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "Error bad request", nil))
+	}
+
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	document := knowledge.Document{
+		Title:   request.Title,
+		Source:  request.Source,
+		Format:  request.Format,
+		Content: request.Content,
+	}
+
+	content, format, err := readUpload(c)
+	switch {
+	case err == nil:
+		document.Content = content
+		document.Format = format
+	case errors.Is(err, http.ErrMissingFile), errors.Is(err, http.ErrNotMultipart):
+	case err == constant.ErrKnowledgeFormat, err == constant.ErrKnowledgeTooLarge:
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	default:
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "Failed to read the uploaded file", nil))
+	}
+
+	created, err := h.knowledgeService.Create(c.Request().Context(), document)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusCreated, helper.FormatResponse(true, "document uploaded successfully", new(DocumentResponse).ToResponse(created)))
+}
+
+// Get All Knowledge Documents
+// @Summary      Get knowledge documents
+// @Description  List the documents of the knowledge base, newest first.
+// @Tags         Knowledge (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        pages          query     int     false  "Page number"
+// @Success      200  {object}  helper.MetadataResponse{data=[]DocumentResponse} "Documents retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/knowledge [get]
+func (h *KnowledgeHandler) GetAll(c echo.Context) error {
+	if !h.isAdmin(c) {
+		return helper.UnauthorizedError(c)
+	}
+
+	page, err := strconv.Atoi(c.QueryParam("pages"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	documents, totalPages, err := h.knowledgeService.GetAll(page)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []DocumentResponse{}
+	for _, document := range documents {
+		response = append(response, new(DocumentResponse).ToResponse(document))
+	}
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "documents retrieved successfully", metadata, response))
+}
+
+// Get Knowledge Document
+// @Summary      Get a knowledge document
+// @Description  Get a document with its content and the passages it was split into.
+// @Tags         Knowledge (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Document ID"
+// @Success      200  {object}  helper.Response{data=DocumentDetailResponse} "Document retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Document not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/knowledge/{id} [get]
+func (h *KnowledgeHandler) GetByID(c echo.Context) error {
+	if !h.isAdmin(c) {
+		return helper.UnauthorizedError(c)
+	}
+
+	document, err := h.knowledgeService.GetByID(c.Param("id"))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "document retrieved successfully", new(DocumentDetailResponse).ToResponse(document)))
+}
+
+// Delete Knowledge Document
+// @Summary      Delete a knowledge document
+// @Description  Delete a document and remove its passages from the index, so the chatbot no longer cites it.
+// @Tags         Knowledge (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Document ID"
+// @Success      200  {object}  helper.Response{data=string} "Document deleted successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Document not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/knowledge/{id} [delete]
+func (h *KnowledgeHandler) Delete(c echo.Context) error {
+	if !h.isAdmin(c) {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := h.knowledgeService.Delete(c.Param("id")); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "document deleted successfully", nil))
+}
+
+// Search Knowledge Base
+// @Summary      Search the knowledge base
+// @Description  Return the passages the chatbot would be given for a question, to check what a document contributes.
+// @Tags         Knowledge (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        q              query     string  true  "Question to search for"
+// @Success      200  {object}  helper.Response{data=[]PassageResponse} "Passages retrieved successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Bad request"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/knowledge/search [get]
+func (h *KnowledgeHandler) Search(c echo.Context) error {
+	if !h.isAdmin(c) {
+		return helper.UnauthorizedError(c)
+	}
+
+	passages, err := h.knowledgeService.Search(c.Request().Context(), c.QueryParam("q"))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []PassageResponse{}
+	for _, passage := range passages {
+		response = append(response, PassageResponse{
+			DocumentID: passage.DocumentID,
+			Title:      passage.Title,
+			Source:     passage.Source,
+			Heading:    passage.Heading,
+			Content:    passage.Content,
+			Score:      passage.Score,
+		})
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "passages retrieved successfully", response))
+}
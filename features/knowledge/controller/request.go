@@ -0,0 +1,10 @@
+package controller
+
+// DocumentRequest holds the form fields of an upload. Content and Format are
+// used when no file is attached.
+type DocumentRequest struct {
+	Title   string `form:"title" validate:"required"`
+	Source  string `form:"source"`
+	Format  string `form:"format"`
+	Content string `form:"content"`
+}
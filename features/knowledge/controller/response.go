@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"greenenvironment/features/knowledge"
+)
+
+type DocumentResponse struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Source     string `json:"source"`
+	Format     string `json:"format"`
+	ChunkCount int    `json:"chunk_count"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func (d DocumentResponse) ToResponse(document knowledge.Document) DocumentResponse {
+	return DocumentResponse{
+		ID:         document.ID,
+		Title:      document.Title,
+		Source:     document.Source,
+		Format:     document.Format,
+		ChunkCount: document.ChunkCount,
+		CreatedAt:  document.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+type ChunkResponse struct {
+	ID       string `json:"id"`
+	Position int    `json:"position"`
+	Heading  string `json:"heading"`
+	Content  string `json:"content"`
+}
+
+type DocumentDetailResponse struct {
+	DocumentResponse
+	Content string          `json:"content"`
+	Chunks  []ChunkResponse `json:"chunks"`
+}
+
+func (d DocumentDetailResponse) ToResponse(document knowledge.Document) DocumentDetailResponse {
+	response := DocumentDetailResponse{
+		DocumentResponse: new(DocumentResponse).ToResponse(document),
+		Content:          document.Content,
+		Chunks:           []ChunkResponse{},
+	}
+	for _, chunk := range document.Chunks {
+		response.Chunks = append(response.Chunks, ChunkResponse{
+			ID:       chunk.ID,
+			Position: chunk.Position,
+			Heading:  chunk.Heading,
+			Content:  chunk.Content,
+		})
+	}
+	return response
+}
+
+type PassageResponse struct {
+	DocumentID string  `json:"document_id"`
+	Title      string  `json:"title"`
+	Source     string  `json:"source"`
+	Heading    string  `json:"heading"`
+	Content    string  `json:"content"`
+	Score      float64 `json:"score"`
+}
@@ -0,0 +1,85 @@
+package knowledge
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+)
+
+// MaxDocumentSize is the largest document, in bytes, that can be uploaded.
+const MaxDocumentSize = 1 << 20
+
+// Document is an article or FAQ uploaded by an admin for the chatbot to
+// answer from. Source is where readers can find the original, such as a
+// URL, and is shown when the chatbot cites it.
+type Document struct {
+	ID         string
+	Title      string
+	Source     string
+	Format     string
+	Content    string
+	ChunkCount int
+	Chunks     []Chunk
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Chunk is a passage of a document small enough to put in a prompt. Its
+// embedding is kept so the index can be rebuilt without calling the
+// embedding model again, unless the model changed.
+type Chunk struct {
+	ID             string
+	DocumentID     string
+	Position       int
+	Heading        string
+	Content        string
+	Embedding      []float32
+	EmbeddingModel string
+	Document       Document
+}
+
+// Passage is a chunk found for a question, with the document it cites.
+type Passage struct {
+	ChunkID    string
+	DocumentID string
+	Title      string
+	Source     string
+	Heading    string
+	Content    string
+	Score      float64
+}
+
+type KnowledgeControllerInterface interface {
+	Create(c echo.Context) error
+	GetAll(c echo.Context) error
+	GetByID(c echo.Context) error
+	Delete(c echo.Context) error
+	Search(c echo.Context) error
+}
+
+// KnowledgeServiceInterface manages the documents and their index. Search is
+// what the chatbot uses to find passages for a question.
+type KnowledgeServiceInterface interface {
+	Load(ctx context.Context) error
+	Create(ctx context.Context, document Document) (Document, error)
+	GetAll(page int) ([]Document, int, error)
+	GetByID(documentID string) (Document, error)
+	Delete(documentID string) error
+	Search(ctx context.Context, query string) ([]Passage, error)
+}
+
+type KnowledgeRepositoryInterface interface {
+	Create(document Document) error
+	GetAll(page int) ([]Document, int, error)
+	GetByID(documentID string) (Document, error)
+	Delete(documentID string) error
+	GetAllChunks() ([]Chunk, error)
+	GetChunksByIDs(chunkIDs []string) ([]Chunk, error)
+	UpdateEmbeddings(chunks []Chunk) error
+}
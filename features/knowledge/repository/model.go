@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+type Document struct {
+	*gorm.Model
+	ID         string  `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	Title      string  `gorm:"type:varchar(255);not null;column:title"`
+	Source     string  `gorm:"type:varchar(255);column:source"`
+	Format     string  `gorm:"type:varchar(20);not null;column:format"`
+	Content    string  `gorm:"type:mediumtext;not null;column:content"`
+	ChunkCount int     `gorm:"not null;default:0;column:chunk_count"`
+	Chunks     []Chunk `gorm:"foreignKey:DocumentID;references:ID"`
+}
+
+func (Document) TableName() string {
+	return "knowledge_documents"
+}
+
+// Chunk keeps its embedding as JSON so the index can be rebuilt at startup.
+type Chunk struct {
+	*gorm.Model
+	ID             string    `gorm:"primary_key;type:varchar(50);not null;column:id"`
+	DocumentID     string    `gorm:"type:varchar(50);not null;column:document_id;index:idx_knowledge_chunk_document"`
+	Position       int       `gorm:"not null;column:position"`
+	Heading        string    `gorm:"type:varchar(255);column:heading"`
+	Content        string    `gorm:"type:text;not null;column:content"`
+	Embedding      []float32 `gorm:"type:mediumtext;column:embedding;serializer:json"`
+	EmbeddingModel string    `gorm:"type:varchar(100);column:embedding_model"`
+	Document       Document  `gorm:"foreignKey:DocumentID;references:ID"`
+}
+
+func (Chunk) TableName() string {
+	return "knowledge_chunks"
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"errors"
+	"greenenvironment/constant"
+	"greenenvironment/features/knowledge"
+
+	"gorm.io/gorm"
+)
+
+// documentsPerPage sizes the pages of the document list.
+const documentsPerPage = 20
+
+type KnowledgeRepository struct {
+	DB *gorm.DB
+}
+
+func NewKnowledgeRepository(db *gorm.DB) knowledge.KnowledgeRepositoryInterface {
+	return &KnowledgeRepository{
+		DB: db,
+	}
+}
+
+// Create stores a document together with its chunks.
+func (kr *KnowledgeRepository) Create(document knowledge.Document) error {
+	documentData := Document{
+		ID:         document.ID,
+		Title:      document.Title,
+		Source:     document.Source,
+		Format:     document.Format,
+		Content:    document.Content,
+		ChunkCount: len(document.Chunks),
+	}
+
+	chunks := make([]Chunk, 0, len(document.Chunks))
+	for _, chunk := range document.Chunks {
+		chunks = append(chunks, Chunk{
+			ID:             chunk.ID,
+			DocumentID:     document.ID,
+			Position:       chunk.Position,
+			Heading:        chunk.Heading,
+			Content:        chunk.Content,
+			Embedding:      chunk.Embedding,
+			EmbeddingModel: chunk.EmbeddingModel,
+		})
+	}
+
+	return kr.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Chunks").Create(&documentData).Error; err != nil {
+			return err
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+		return tx.Omit("Document").CreateInBatches(&chunks, 100).Error
+	})
+}
+
+func (kr *KnowledgeRepository) GetAll(page int) ([]knowledge.Document, int, error) {
+	var total int64
+	if err := kr.DB.Model(&Document{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	totalPages := int((total + documentsPerPage - 1) / documentsPerPage)
+
+	var documentData []Document
+	err := kr.DB.Omit("content").
+		Order("created_at DESC").
+		Offset((page - 1) * documentsPerPage).
+		Limit(documentsPerPage).
+		Find(&documentData).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	documents := make([]knowledge.Document, 0, len(documentData))
+	for _, document := range documentData {
+		documents = append(documents, toDocumentEntity(document))
+	}
+	return documents, totalPages, nil
+}
+
+func (kr *KnowledgeRepository) GetByID(documentID string) (knowledge.Document, error) {
+	var document Document
+	err := kr.DB.Preload("Chunks", func(db *gorm.DB) *gorm.DB {
+		return db.Omit("embedding").Order("position ASC")
+	}).Where("id = ?", documentID).First(&document).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return knowledge.Document{}, constant.ErrKnowledgeNotFound
+	}
+	if err != nil {
+		return knowledge.Document{}, err
+	}
+	return toDocumentEntity(document), nil
+}
+
+func (kr *KnowledgeRepository) Delete(documentID string) error {
+	return kr.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("document_id = ?", documentID).Delete(&Chunk{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", documentID).Delete(&Document{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return constant.ErrKnowledgeNotFound
+		}
+		return nil
+	})
+}
+
+// GetAllChunks returns every chunk with its embedding, for building the
+// index.
+func (kr *KnowledgeRepository) GetAllChunks() ([]knowledge.Chunk, error) {
+	var chunkData []Chunk
+	if err := kr.DB.Order("document_id ASC, position ASC").Find(&chunkData).Error; err != nil {
+		return nil, err
+	}
+
+	chunks := make([]knowledge.Chunk, 0, len(chunkData))
+	for _, chunk := range chunkData {
+		chunks = append(chunks, toChunkEntity(chunk))
+	}
+	return chunks, nil
+}
+
+// GetChunksByIDs returns the chunks with the document they belong to, but
+// without their embeddings.
+func (kr *KnowledgeRepository) GetChunksByIDs(chunkIDs []string) ([]knowledge.Chunk, error) {
+	var chunkData []Chunk
+	err := kr.DB.Preload("Document", func(db *gorm.DB) *gorm.DB {
+		return db.Omit("content")
+	}).Omit("embedding").Where("id IN ?", chunkIDs).Find(&chunkData).Error
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]knowledge.Chunk, 0, len(chunkData))
+	for _, chunk := range chunkData {
+		chunks = append(chunks, toChunkEntity(chunk))
+	}
+	return chunks, nil
+}
+
+func (kr *KnowledgeRepository) UpdateEmbeddings(chunks []knowledge.Chunk) error {
+	return kr.DB.Transaction(func(tx *gorm.DB) error {
+		for _, chunk := range chunks {
+			err := tx.Model(&Chunk{}).Where("id = ?", chunk.ID).Updates(&Chunk{
+				Embedding:      chunk.Embedding,
+				EmbeddingModel: chunk.EmbeddingModel,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func toDocumentEntity(document Document) knowledge.Document {
+	documentEntity := knowledge.Document{
+		ID:         document.ID,
+		Title:      document.Title,
+		Source:     document.Source,
+		Format:     document.Format,
+		Content:    document.Content,
+		ChunkCount: document.ChunkCount,
+	}
+	if document.Model != nil {
+		documentEntity.CreatedAt = document.CreatedAt
+		documentEntity.UpdatedAt = document.UpdatedAt
+	}
+	for _, chunk := range document.Chunks {
+		documentEntity.Chunks = append(documentEntity.Chunks, toChunkEntity(chunk))
+	}
+	return documentEntity
+}
+
+func toChunkEntity(chunk Chunk) knowledge.Chunk {
+	return knowledge.Chunk{
+		ID:             chunk.ID,
+		DocumentID:     chunk.DocumentID,
+		Position:       chunk.Position,
+		Heading:        chunk.Heading,
+		Content:        chunk.Content,
+		Embedding:      chunk.Embedding,
+		EmbeddingModel: chunk.EmbeddingModel,
+		Document:       toDocumentEntity(chunk.Document),
+	}
+}
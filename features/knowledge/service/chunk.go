@@ -0,0 +1,149 @@
+package service
+
+import (
+	"greenenvironment/features/knowledge"
+	"greenenvironment/utils/llm"
+	"strings"
+)
+
+// maxChunkTokens is the size a chunk is kept under, so a few of them fit in
+// a prompt next to the conversation.
+const maxChunkTokens = 200
+
+// paragraph is a block of text and the markdown heading it falls under.
+type paragraph struct {
+	heading string
+	text    string
+}
+
+// splitDocument cuts a document into chunks. Paragraphs under the same
+// heading are packed together up to maxChunkTokens; a paragraph longer than
+// that is cut between words.
+func splitDocument(format, content string) []knowledge.Chunk {
+	var chunks []knowledge.Chunk
+	var heading string
+	var current []string
+	tokens := 0
+
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, knowledge.Chunk{
+				Position: len(chunks),
+				Heading:  heading,
+				Content:  strings.Join(current, "\n\n"),
+			})
+		}
+		current = nil
+		tokens = 0
+	}
+
+	for _, p := range paragraphs(format, content) {
+		if p.heading != heading {
+			flush()
+			heading = p.heading
+		}
+		for _, piece := range splitLong(p.text) {
+			pieceTokens := llm.EstimateTokens(piece)
+			if tokens > 0 && tokens+pieceTokens > maxChunkTokens {
+				flush()
+			}
+			current = append(current, piece)
+			tokens += pieceTokens
+		}
+	}
+	flush()
+	return chunks
+}
+
+// paragraphs splits content at blank lines. In markdown, headings start a
+// new section and are not part of any paragraph; fenced code blocks are kept
+// whole.
+func paragraphs(format, content string) []paragraph {
+	var result []paragraph
+	var heading string
+	var lines []string
+	inFence := false
+
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(lines, "\n"))
+		if text != "" {
+			result = append(result, paragraph{heading: heading, text: text})
+		}
+		lines = nil
+	}
+
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if format == knowledge.FormatMarkdown {
+			if strings.HasPrefix(trimmed, "```") {
+				inFence = !inFence
+				lines = append(lines, line)
+				continue
+			}
+			if !inFence {
+				if title, ok := markdownHeading(trimmed); ok {
+					flush()
+					heading = title
+					continue
+				}
+			}
+		}
+		if trimmed == "" && !inFence {
+			flush()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return result
+}
+
+// markdownHeading reports whether line is an ATX heading such as "## Tips"
+// and returns its text.
+func markdownHeading(line string) (string, bool) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level == len(line) || line[level] != ' ' {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimRight(line[level:], "# ")), true
+}
+
+// splitLong cuts text that is over maxChunkTokens into pieces between
+// words.
+func splitLong(text string) []string {
+	if llm.EstimateTokens(text) <= maxChunkTokens {
+		return []string{text}
+	}
+
+	var pieces []string
+	var piece []string
+	tokens := 0
+	for _, word := range strings.Fields(text) {
+		wordTokens := llm.EstimateTokens(word + " ")
+		if tokens > 0 && tokens+wordTokens > maxChunkTokens {
+			pieces = append(pieces, strings.Join(piece, " "))
+			piece = nil
+			tokens = 0
+		}
+		piece = append(piece, word)
+		tokens += wordTokens
+	}
+	if len(piece) > 0 {
+		pieces = append(pieces, strings.Join(piece, " "))
+	}
+	return pieces
+}
+
+// embeddingText is what gets embedded for a chunk. The title and heading
+// tell the model what the passage is about when its text alone does not.
+func embeddingText(title string, chunk knowledge.Chunk) string {
+	parts := []string{title}
+	if chunk.Heading != "" {
+		parts = append(parts, chunk.Heading)
+	}
+	return strings.Join(append(parts, chunk.Content), "\n")
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"greenenvironment/configs"
+	"greenenvironment/constant"
+	"greenenvironment/features/knowledge"
+	"greenenvironment/utils/llm"
+	"greenenvironment/utils/vectorstore"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// KnowledgeService keeps an index of every chunk in memory. Each instance
+// builds its own at startup with Load, so a document uploaded to one
+// instance is found by the others after their next restart.
+type KnowledgeService struct {
+	knowledgeRepo knowledge.KnowledgeRepositoryInterface
+	embedder      llm.Embedder
+	index         *vectorstore.Store
+	config        configs.KnowledgeConfig
+}
+
+func NewKnowledgeService(kr knowledge.KnowledgeRepositoryInterface, embedder llm.Embedder, config configs.KnowledgeConfig) knowledge.KnowledgeServiceInterface {
+	return &KnowledgeService{
+		knowledgeRepo: kr,
+		embedder:      embedder,
+		index:         vectorstore.New(),
+		config:        config,
+	}
+}
+
+// Load indexes the stored chunks. Chunks embedded by another model than the
+// current one are embedded again, since their vectors cannot be compared.
+func (ks *KnowledgeService) Load(ctx context.Context) error {
+	chunks, err := ks.knowledgeRepo.GetAllChunks()
+	if err != nil {
+		return err
+	}
+
+	var stale []knowledge.Chunk
+	for _, chunk := range chunks {
+		if chunk.EmbeddingModel != ks.embedder.Model() || len(chunk.Embedding) == 0 {
+			stale = append(stale, chunk)
+			continue
+		}
+		ks.index.Add(chunk.ID, chunk.DocumentID, chunk.Embedding)
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	titles := map[string]string{}
+	for _, chunk := range stale {
+		if _, ok := titles[chunk.DocumentID]; ok {
+			continue
+		}
+		document, err := ks.knowledgeRepo.GetByID(chunk.DocumentID)
+		if err != nil {
+			return err
+		}
+		titles[chunk.DocumentID] = document.Title
+	}
+
+	texts := make([]string, len(stale))
+	for i, chunk := range stale {
+		texts[i] = embeddingText(titles[chunk.DocumentID], chunk)
+	}
+	if err := ks.embed(ctx, stale, texts); err != nil {
+		return err
+	}
+	if err := ks.knowledgeRepo.UpdateEmbeddings(stale); err != nil {
+		return err
+	}
+
+	log.Printf("re-embedded %d knowledge chunks with %s", len(stale), ks.embedder.Model())
+	for _, chunk := range stale {
+		ks.index.Add(chunk.ID, chunk.DocumentID, chunk.Embedding)
+	}
+	return nil
+}
+
+// embed sets the embedding of every chunk from the matching text.
+func (ks *KnowledgeService) embed(ctx context.Context, chunks []knowledge.Chunk, texts []string) error {
+	vectors, err := ks.embedder.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if len(vectors) != len(chunks) {
+		return llm.ErrNoAnswer
+	}
+	for i := range chunks {
+		chunks[i].Embedding = llm.Normalize(vectors[i])
+		chunks[i].EmbeddingModel = ks.embedder.Model()
+	}
+	return nil
+}
+
+// Create chunks, embeds and stores a document, then adds it to the index.
+func (ks *KnowledgeService) Create(ctx context.Context, document knowledge.Document) (knowledge.Document, error) {
+	document.Title = strings.TrimSpace(document.Title)
+	document.Source = strings.TrimSpace(document.Source)
+	if document.Format == "" {
+		document.Format = knowledge.FormatMarkdown
+	}
+	if document.Format != knowledge.FormatMarkdown && document.Format != knowledge.FormatText {
+		return knowledge.Document{}, constant.ErrKnowledgeFormat
+	}
+	if len(document.Content) > knowledge.MaxDocumentSize {
+		return knowledge.Document{}, constant.ErrKnowledgeTooLarge
+	}
+
+	document.ID = uuid.New().String()
+	document.Chunks = splitDocument(document.Format, document.Content)
+	if document.Title == "" || len(document.Chunks) == 0 {
+		return knowledge.Document{}, constant.ErrKnowledgeField
+	}
+
+	texts := make([]string, len(document.Chunks))
+	for i := range document.Chunks {
+		document.Chunks[i].ID = uuid.New().String()
+		document.Chunks[i].DocumentID = document.ID
+		texts[i] = embeddingText(document.Title, document.Chunks[i])
+	}
+	if err := ks.embed(ctx, document.Chunks, texts); err != nil {
+		return knowledge.Document{}, err
+	}
+
+	if err := ks.knowledgeRepo.Create(document); err != nil {
+		return knowledge.Document{}, err
+	}
+
+	for _, chunk := range document.Chunks {
+		ks.index.Add(chunk.ID, document.ID, chunk.Embedding)
+	}
+	document.ChunkCount = len(document.Chunks)
+	return document, nil
+}
+
+func (ks *KnowledgeService) GetAll(page int) ([]knowledge.Document, int, error) {
+	return ks.knowledgeRepo.GetAll(page)
+}
+
+func (ks *KnowledgeService) GetByID(documentID string) (knowledge.Document, error) {
+	return ks.knowledgeRepo.GetByID(documentID)
+}
+
+func (ks *KnowledgeService) Delete(documentID string) error {
+	if err := ks.knowledgeRepo.Delete(documentID); err != nil {
+		return err
+	}
+	ks.index.RemoveGroup(documentID)
+	return nil
+}
+
+// Search returns the passages most similar to the query, best first, up to
+// the configured number and above the configured score.
+func (ks *KnowledgeService) Search(ctx context.Context, query string) ([]knowledge.Passage, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, constant.ErrKnowledgeQuery
+	}
+	if ks.index.Len() == 0 || ks.config.Results <= 0 {
+		return nil, nil
+	}
+
+	vectors, err := ks.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != 1 {
+		return nil, llm.ErrNoAnswer
+	}
+
+	matches := ks.index.Search(llm.Normalize(vectors[0]), ks.config.Results, ks.config.MinScore)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	chunkIDs := make([]string, len(matches))
+	for i, match := range matches {
+		chunkIDs[i] = match.ID
+	}
+	chunks, err := ks.knowledgeRepo.GetChunksByIDs(chunkIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]knowledge.Chunk, len(chunks))
+	for _, chunk := range chunks {
+		byID[chunk.ID] = chunk
+	}
+
+	passages := make([]knowledge.Passage, 0, len(matches))
+	for _, match := range matches {
+		// A chunk deleted by another instance may still be in this index.
+		chunk, ok := byID[match.ID]
+		if !ok {
+			continue
+		}
+		passages = append(passages, knowledge.Passage{
+			ChunkID:    chunk.ID,
+			DocumentID: chunk.DocumentID,
+			Title:      chunk.Document.Title,
+			Source:     chunk.Document.Source,
+			Heading:    chunk.Heading,
+			Content:    chunk.Content,
+			Score:      match.Score,
+		})
+	}
+	return passages, nil
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"greenenvironment/configs"
+	"greenenvironment/constant"
+	"greenenvironment/features/knowledge"
+	"greenenvironment/utils/llm"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockKnowledgeRepository struct {
+	mock.Mock
+}
+
+func (m *MockKnowledgeRepository) Create(document knowledge.Document) error {
+	args := m.Called(document)
+	return args.Error(0)
+}
+
+func (m *MockKnowledgeRepository) GetAll(page int) ([]knowledge.Document, int, error) {
+	args := m.Called(page)
+	return args.Get(0).([]knowledge.Document), args.Int(1), args.Error(2)
+}
+
+func (m *MockKnowledgeRepository) GetByID(documentID string) (knowledge.Document, error) {
+	args := m.Called(documentID)
+	return args.Get(0).(knowledge.Document), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) Delete(documentID string) error {
+	args := m.Called(documentID)
+	return args.Error(0)
+}
+
+func (m *MockKnowledgeRepository) GetAllChunks() ([]knowledge.Chunk, error) {
+	args := m.Called()
+	return args.Get(0).([]knowledge.Chunk), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) GetChunksByIDs(chunkIDs []string) ([]knowledge.Chunk, error) {
+	args := m.Called(chunkIDs)
+	return args.Get(0).([]knowledge.Chunk), args.Error(1)
+}
+
+func (m *MockKnowledgeRepository) UpdateEmbeddings(chunks []knowledge.Chunk) error {
+	args := m.Called(chunks)
+	return args.Error(0)
+}
+
+// memoryKnowledge keeps documents in memory so a test can upload a document
+// and search it.
+type memoryKnowledge struct {
+	documents map[string]knowledge.Document
+	updated   []knowledge.Chunk
+}
+
+func newMemoryKnowledge(documents ...knowledge.Document) *memoryKnowledge {
+	repo := &memoryKnowledge{documents: map[string]knowledge.Document{}}
+	for _, document := range documents {
+		repo.documents[document.ID] = document
+	}
+	return repo
+}
+
+func (r *memoryKnowledge) Create(document knowledge.Document) error {
+	r.documents[document.ID] = document
+	return nil
+}
+
+func (r *memoryKnowledge) GetAll(page int) ([]knowledge.Document, int, error) {
+	var documents []knowledge.Document
+	for _, document := range r.documents {
+		documents = append(documents, document)
+	}
+	return documents, 1, nil
+}
+
+func (r *memoryKnowledge) GetByID(documentID string) (knowledge.Document, error) {
+	document, ok := r.documents[documentID]
+	if !ok {
+		return knowledge.Document{}, constant.ErrKnowledgeNotFound
+	}
+	return document, nil
+}
+
+func (r *memoryKnowledge) Delete(documentID string) error {
+	if _, ok := r.documents[documentID]; !ok {
+		return constant.ErrKnowledgeNotFound
+	}
+	delete(r.documents, documentID)
+	return nil
+}
+
+func (r *memoryKnowledge) GetAllChunks() ([]knowledge.Chunk, error) {
+	var chunks []knowledge.Chunk
+	for _, document := range r.documents {
+		chunks = append(chunks, document.Chunks...)
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].ID < chunks[j].ID })
+	return chunks, nil
+}
+
+func (r *memoryKnowledge) GetChunksByIDs(chunkIDs []string) ([]knowledge.Chunk, error) {
+	wanted := map[string]bool{}
+	for _, id := range chunkIDs {
+		wanted[id] = true
+	}
+
+	var chunks []knowledge.Chunk
+	for _, document := range r.documents {
+		for _, chunk := range document.Chunks {
+			if wanted[chunk.ID] {
+				chunk.Document = knowledge.Document{ID: document.ID, Title: document.Title, Source: document.Source}
+				chunks = append(chunks, chunk)
+			}
+		}
+	}
+	return chunks, nil
+}
+
+func (r *memoryKnowledge) UpdateEmbeddings(chunks []knowledge.Chunk) error {
+	r.updated = append(r.updated, chunks...)
+	return nil
+}
+
+// failingEmbedder is an embedding service that is down.
+type failingEmbedder struct{}
+
+func (failingEmbedder) Model() string {
+	return "failing"
+}
+
+func (failingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, errors.New("embedding service down")
+}
+
+var testConfig = configs.KnowledgeConfig{Results: 2, MinScore: 0.1}
+
+const recyclingGuide = `# Recycling guide
+
+Sort your waste before it leaves the house.
+
+## Plastic
+
+Rinse plastic bottles and containers before recycling them.
+Remove the caps, they are made of a different plastic.
+
+## Paper
+
+Keep paper and cardboard dry. Wet or greasy cardboard such as pizza boxes belongs in the compost.
+`
+
+func TestCreate_ChunksAndIndexesDocument(t *testing.T) {
+	repo := newMemoryKnowledge()
+	service := NewKnowledgeService(repo, llm.NewHashEmbedder(), testConfig)
+
+	document, err := service.Create(context.Background(), knowledge.Document{
+		Title:   " Recycling guide ",
+		Source:  "https://example.com/recycling",
+		Content: recyclingGuide,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Recycling guide", document.Title)
+	assert.Equal(t, knowledge.FormatMarkdown, document.Format)
+	assert.Equal(t, 3, document.ChunkCount)
+
+	stored := repo.documents[document.ID]
+	if assert.Len(t, stored.Chunks, 3) {
+		assert.Equal(t, "Recycling guide", stored.Chunks[0].Heading)
+		assert.Equal(t, "Plastic", stored.Chunks[1].Heading)
+		assert.Equal(t, 1, stored.Chunks[1].Position)
+		assert.True(t, strings.HasPrefix(stored.Chunks[1].Content, "Rinse plastic bottles"))
+		for _, chunk := range stored.Chunks {
+			assert.Equal(t, document.ID, chunk.DocumentID)
+			assert.NotEmpty(t, chunk.ID)
+			assert.Equal(t, "hash-256", chunk.EmbeddingModel)
+			assert.Len(t, chunk.Embedding, 256)
+		}
+	}
+
+	passages, err := service.Search(context.Background(), "How do I recycle plastic bottles?")
+
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, passages) {
+		assert.Equal(t, "Plastic", passages[0].Heading)
+		assert.Equal(t, "Recycling guide", passages[0].Title)
+		assert.Equal(t, "https://example.com/recycling", passages[0].Source)
+		assert.Equal(t, document.ID, passages[0].DocumentID)
+	}
+	assert.LessOrEqual(t, len(passages), testConfig.Results)
+	for i := 1; i < len(passages); i++ {
+		assert.GreaterOrEqual(t, passages[i-1].Score, passages[i].Score)
+	}
+}
+
+func TestCreate_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		document knowledge.Document
+		err      error
+	}{
+		{"missing title", knowledge.Document{Content: "Some text"}, constant.ErrKnowledgeField},
+		{"blank content", knowledge.Document{Title: "FAQ", Content: " \n\n "}, constant.ErrKnowledgeField},
+		{"unknown format", knowledge.Document{Title: "FAQ", Format: "pdf", Content: "Some text"}, constant.ErrKnowledgeFormat},
+		{"too large", knowledge.Document{Title: "FAQ", Content: strings.Repeat("a", knowledge.MaxDocumentSize+1)}, constant.ErrKnowledgeTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockKnowledgeRepository)
+			service := NewKnowledgeService(mockRepo, llm.NewHashEmbedder(), testConfig)
+
+			_, err := service.Create(context.Background(), tt.document)
+
+			assert.Equal(t, tt.err, err)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestCreate_EmbeddingError(t *testing.T) {
+	mockRepo := new(MockKnowledgeRepository)
+	service := NewKnowledgeService(mockRepo, failingEmbedder{}, testConfig)
+
+	_, err := service.Create(context.Background(), knowledge.Document{Title: "FAQ", Content: "Some text"})
+
+	assert.EqualError(t, err, "embedding service down")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestSearch(t *testing.T) {
+	t.Run("empty query", func(t *testing.T) {
+		service := NewKnowledgeService(newMemoryKnowledge(), llm.NewHashEmbedder(), testConfig)
+
+		_, err := service.Search(context.Background(), "  ")
+
+		assert.Equal(t, constant.ErrKnowledgeQuery, err)
+	})
+
+	t.Run("empty index does not embed", func(t *testing.T) {
+		service := NewKnowledgeService(newMemoryKnowledge(), failingEmbedder{}, testConfig)
+
+		passages, err := service.Search(context.Background(), "plastic")
+
+		assert.NoError(t, err)
+		assert.Empty(t, passages)
+	})
+
+	t.Run("nothing similar enough", func(t *testing.T) {
+		service := NewKnowledgeService(newMemoryKnowledge(), llm.NewHashEmbedder(), testConfig)
+		_, err := service.Create(context.Background(), knowledge.Document{Title: "Recycling guide", Content: recyclingGuide})
+		assert.NoError(t, err)
+
+		passages, err := service.Search(context.Background(), "zebra")
+
+		assert.NoError(t, err)
+		assert.Empty(t, passages)
+	})
+}
+
+func TestDelete_RemovesFromIndex(t *testing.T) {
+	repo := newMemoryKnowledge()
+	service := NewKnowledgeService(repo, llm.NewHashEmbedder(), testConfig)
+	document, err := service.Create(context.Background(), knowledge.Document{Title: "Recycling guide", Content: recyclingGuide})
+	assert.NoError(t, err)
+
+	assert.NoError(t, service.Delete(document.ID))
+
+	passages, err := service.Search(context.Background(), "recycling plastic bottles")
+	assert.NoError(t, err)
+	assert.Empty(t, passages)
+	assert.Equal(t, constant.ErrKnowledgeNotFound, service.Delete(document.ID))
+}
+
+func TestLoad_ReembedsChunksOfAnotherModel(t *testing.T) {
+	embedder := llm.NewHashEmbedder()
+	vectors, _ := embedder.Embed(context.Background(), []string{"Composting\nFruit peels and coffee grounds can be composted."})
+	repo := newMemoryKnowledge(
+		knowledge.Document{ID: "doc1", Title: "Composting", Chunks: []knowledge.Chunk{
+			{ID: "chunk1", DocumentID: "doc1", Content: "Fruit peels and coffee grounds can be composted.", Embedding: vectors[0], EmbeddingModel: embedder.Model()},
+		}},
+		knowledge.Document{ID: "doc2", Title: "Water saving", Chunks: []knowledge.Chunk{
+			{ID: "chunk2", DocumentID: "doc2", Content: "Fix leaking taps and take shorter showers.", Embedding: []float32{1, 0}, EmbeddingModel: "text-embedding-3-small"},
+		}},
+	)
+	service := NewKnowledgeService(repo, embedder, testConfig)
+
+	assert.NoError(t, service.Load(context.Background()))
+
+	if assert.Len(t, repo.updated, 1) {
+		assert.Equal(t, "chunk2", repo.updated[0].ID)
+		assert.Equal(t, "hash-256", repo.updated[0].EmbeddingModel)
+		assert.Len(t, repo.updated[0].Embedding, 256)
+	}
+
+	passages, err := service.Search(context.Background(), "leaking taps")
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, passages) {
+		assert.Equal(t, "chunk2", passages[0].ChunkID)
+	}
+
+	passages, err = service.Search(context.Background(), "coffee grounds compost")
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, passages) {
+		assert.Equal(t, "chunk1", passages[0].ChunkID)
+	}
+}
+
+func TestLoad_Error(t *testing.T) {
+	mockRepo := new(MockKnowledgeRepository)
+	service := NewKnowledgeService(mockRepo, llm.NewHashEmbedder(), testConfig)
+
+	mockRepo.On("GetAllChunks").Return([]knowledge.Chunk{}, errors.New("database down"))
+
+	assert.EqualError(t, service.Load(context.Background()), "database down")
+}
+
+func TestSplitDocument(t *testing.T) {
+	t.Run("long paragraphs are cut between words", func(t *testing.T) {
+		content := strings.Repeat("compost ", 300)
+
+		chunks := splitDocument(knowledge.FormatText, content)
+
+		assert.Greater(t, len(chunks), 1)
+		for i, chunk := range chunks {
+			assert.Equal(t, i, chunk.Position)
+			assert.LessOrEqual(t, llm.EstimateTokens(chunk.Content), maxChunkTokens)
+			assert.NotContains(t, chunk.Content, "compostcompost")
+		}
+	})
+
+	t.Run("short paragraphs are packed together", func(t *testing.T) {
+		chunks := splitDocument(knowledge.FormatText, "First tip.\n\nSecond tip.\n\n\nThird tip.")
+
+		if assert.Len(t, chunks, 1) {
+			assert.Equal(t, "First tip.\n\nSecond tip.\n\nThird tip.", chunks[0].Content)
+			assert.Empty(t, chunks[0].Heading)
+		}
+	})
+
+	t.Run("headings are ignored in text", func(t *testing.T) {
+		chunks := splitDocument(knowledge.FormatText, "# Not a heading\nJust text.")
+
+		if assert.Len(t, chunks, 1) {
+			assert.Equal(t, "# Not a heading\nJust text.", chunks[0].Content)
+		}
+	})
+
+	t.Run("code fences are kept whole", func(t *testing.T) {
+		content := "## Setup\n\n```\n# a comment, not a heading\n\nmore code\n```\n"
+
+		chunks := splitDocument(knowledge.FormatMarkdown, content)
+
+		if assert.Len(t, chunks, 1) {
+			assert.Equal(t, "Setup", chunks[0].Heading)
+			assert.Equal(t, "```\n# a comment, not a heading\n\nmore code\n```", chunks[0].Content)
+		}
+	})
+}
@@ -0,0 +1,269 @@
+package controller
+
+import (
+	"greenenvironment/constant"
+	"greenenvironment/features/notifications"
+	"greenenvironment/helper"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type NotificationHandler struct {
+	notificationService notifications.NotificationServiceInterface
+	jwt                 helper.JWTInterface
+}
+
+func NewNotificationController(s notifications.NotificationServiceInterface, j helper.JWTInterface) notifications.NotificationControllerInterface {
+	return &NotificationHandler{
+		notificationService: s,
+		jwt:                 j,
+	}
+}
+
+func (h *NotificationHandler) userData(c echo.Context) (map[string]interface{}, bool) {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return nil, false
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return nil, false
+	}
+
+	return h.jwt.ExtractUserToken(token), true
+}
+
+func (h *NotificationHandler) userID(c echo.Context) (string, bool) {
+	userData, ok := h.userData(c)
+	if !ok {
+		return "", false
+	}
+	return userData[constant.JWT_ID].(string), true
+}
+
+// Get Notifications
+// @Summary      Get notifications
+// @Description  Retrieve the notifications of the authenticated user, newest first.
+// @Tags         Notifications
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        pages          query     int     false  "Page number"
+// @Param        unread         query     bool    false  "Only return unread notifications"
+// @Success      200  {object}  helper.MetadataResponse{data=[]NotificationResponse} "Notifications retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /notifications [get]
+func (h *NotificationHandler) GetNotifications(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	page, err := strconv.Atoi(c.QueryParam("pages"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	unreadOnly, _ := strconv.ParseBool(c.QueryParam("unread"))
+
+	notificationList, totalPages, err := h.notificationService.GetNotifications(userID, unreadOnly, page)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	response := []NotificationResponse{}
+	for _, notification := range notificationList {
+		response = append(response, new(NotificationResponse).ToResponse(notification))
+	}
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "notifications retrieved successfully", metadata, response))
+}
+
+// Count Unread Notifications
+// @Summary      Count unread notifications
+// @Description  Return how many notifications of the authenticated user are still unread.
+// @Tags         Notifications
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Success      200  {object}  helper.Response{data=UnreadCountResponse} "Unread count retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /notifications/unread-count [get]
+func (h *NotificationHandler) CountUnread(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	unread, err := h.notificationService.CountUnread(userID)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "unread count retrieved successfully", UnreadCountResponse{Unread: unread}))
+}
+
+// Mark Notification Read
+// @Summary      Mark a notification as read
+// @Description  Mark one notification of the authenticated user as read.
+// @Tags         Notifications
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Notification ID"
+// @Success      200  {object}  helper.Response{data=string} "Notification marked as read"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Notification not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /notifications/{id}/read [put]
+func (h *NotificationHandler) MarkRead(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := h.notificationService.MarkRead(userID, c.Param("id")); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "notification marked as read", nil))
+}
+
+// Mark All Notifications Read
+// @Summary      Mark all notifications as read
+// @Description  Mark every unread notification of the authenticated user as read.
+// @Tags         Notifications
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Success      200  {object}  helper.Response{data=string} "All notifications marked as read"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /notifications/read-all [put]
+func (h *NotificationHandler) MarkAllRead(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := h.notificationService.MarkAllRead(userID); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "all notifications marked as read", nil))
+}
+
+// Get Notification Preferences
+// @Summary      Get notification preferences
+// @Description  Return the in-app and email setting of the authenticated user for every notification type.
+// @Tags         Notifications
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Success      200  {object}  helper.Response{data=[]PreferenceResponse} "Preferences retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /notifications/preferences [get]
+func (h *NotificationHandler) GetPreferences(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	preferences, err := h.notificationService.GetPreferences(userID)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "preferences retrieved successfully", toPreferenceResponses(preferences)))
+}
+
+// Update Notification Preferences
+// @Summary      Update notification preferences
+// @Description  Change how the authenticated user receives some notification types. Types left out keep their current setting.
+// @Tags         Notifications
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string                    true  "Bearer Token"
+// @Param        request        body      UpdatePreferencesRequest  true  "Preferences per type"
+// @Success      200  {object}  helper.Response{data=[]PreferenceResponse} "Preferences updated successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Bad request"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /notifications/preferences [put]
+func (h *NotificationHandler) UpdatePreferences(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	var request UpdatePreferencesRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "Error bad request", nil))
+	}
+
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	var preferences []notifications.Preference
+	for _, preference := range request.Preferences {
+		preferences = append(preferences, notifications.Preference{
+			Type:  preference.Type,
+			InApp: preference.InApp,
+			Email: preference.Email,
+		})
+	}
+
+	updated, err := h.notificationService.UpdatePreferences(userID, preferences)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "preferences updated successfully", toPreferenceResponses(updated)))
+}
+
+// Send Announcement
+// @Summary      Send an announcement
+// @Description  Send an announcement notification to every user, honouring their announcement preferences.
+// @Tags         Notifications (Admin)
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string               true  "Bearer Token"
+// @Param        request        body      AnnouncementRequest  true  "Announcement"
+// @Success      201  {object}  helper.Response{data=AnnouncementResponse} "Announcement sent successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Bad request"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /admin/notifications/announcements [post]
+func (h *NotificationHandler) Announce(c echo.Context) error {
+	adminData, ok := h.userData(c)
+	if !ok || adminData[constant.JWT_ROLE] != constant.RoleAdmin {
+		return helper.UnauthorizedError(c)
+	}
+
+	var request AnnouncementRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "Error bad request", nil))
+	}
+
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	delivered, err := h.notificationService.Announce(request.Title, request.Message, request.Link)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusCreated, helper.FormatResponse(true, "announcement sent successfully", AnnouncementResponse{Delivered: delivered}))
+}
@@ -0,0 +1,17 @@
+package controller
+
+type PreferenceRequest struct {
+	Type  string `json:"type" validate:"required"`
+	InApp bool   `json:"in_app"`
+	Email bool   `json:"email"`
+}
+
+type UpdatePreferencesRequest struct {
+	Preferences []PreferenceRequest `json:"preferences" validate:"required,dive"`
+}
+
+type AnnouncementRequest struct {
+	Title   string `json:"title" validate:"required"`
+	Message string `json:"message" validate:"required"`
+	Link    string `json:"link"`
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"greenenvironment/features/notifications"
+	"time"
+)
+
+type NotificationResponse struct {
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	Link      string     `json:"link"`
+	IsRead    bool       `json:"is_read"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt string     `json:"created_at"`
+}
+
+func (n NotificationResponse) ToResponse(notification notifications.Notification) NotificationResponse {
+	return NotificationResponse{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Title:     notification.Title,
+		Message:   notification.Message,
+		Link:      notification.Link,
+		IsRead:    notification.ReadAt != nil,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+type UnreadCountResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+type PreferenceResponse struct {
+	Type  string `json:"type"`
+	InApp bool   `json:"in_app"`
+	Email bool   `json:"email"`
+}
+
+type AnnouncementResponse struct {
+	Delivered int64 `json:"delivered"`
+}
+
+func toPreferenceResponses(preferences []notifications.Preference) []PreferenceResponse {
+	response := []PreferenceResponse{}
+	for _, preference := range preferences {
+		response = append(response, PreferenceResponse{
+			Type:  preference.Type,
+			InApp: preference.InApp,
+			Email: preference.Email,
+		})
+	}
+	return response
+}
@@ -0,0 +1,96 @@
+package notifications
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	TypeTransaction       = "transaction"
+	TypeForumReply        = "forum_reply"
+	TypeForumMention      = "forum_mention"
+	TypeChallengeDeadline = "challenge_deadline"
+	TypeReward            = "reward"
+	TypeAnnouncement      = "announcement"
+	TypeFollower          = "new_follower"
+	TypeAccount           = "account"
+)
+
+// Types lists every notification type a user can set a preference for.
+var Types = []string{
+	TypeTransaction,
+	TypeForumReply,
+	TypeForumMention,
+	TypeChallengeDeadline,
+	TypeReward,
+	TypeAnnouncement,
+	TypeFollower,
+	TypeAccount,
+}
+
+type Notification struct {
+	ID        string
+	UserID    string
+	Type      string
+	Title     string
+	Message   string
+	Link      string
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
+
+// Preference controls how a user receives one type of notification. Types
+// without a stored preference are delivered in-app only.
+type Preference struct {
+	UserID string
+	Type   string
+	InApp  bool
+	Email  bool
+}
+
+// Notifier is how other features raise notifications without depending on
+// the rest of the notification service.
+type Notifier interface {
+	Notify(notification Notification) error
+}
+
+type NotificationRepositoryInterface interface {
+	Create(notification Notification) error
+	GetByUserID(userID string, unreadOnly bool, page int) ([]Notification, int, error)
+	CountUnread(userID string) (int64, error)
+	MarkRead(userID, notificationID string, readAt time.Time) error
+	MarkAllRead(userID string, readAt time.Time) error
+
+	GetPreferences(userID string) ([]Preference, error)
+	SavePreferences(preferences []Preference) error
+
+	GetUserEmail(userID string) (string, error)
+	Broadcast(notification Notification) ([]Notification, error)
+	GetEmailSubscribers(notificationType string) ([]string, error)
+}
+
+type NotificationServiceInterface interface {
+	Notifier
+	GetNotifications(userID string, unreadOnly bool, page int) ([]Notification, int, error)
+	CountUnread(userID string) (int64, error)
+	MarkRead(userID, notificationID string) error
+	MarkAllRead(userID string) error
+
+	GetPreferences(userID string) ([]Preference, error)
+	UpdatePreferences(userID string, preferences []Preference) ([]Preference, error)
+
+	Announce(title, message, link string) (int64, error)
+}
+
+type NotificationControllerInterface interface {
+	GetNotifications(c echo.Context) error
+	CountUnread(c echo.Context) error
+	MarkRead(c echo.Context) error
+	MarkAllRead(c echo.Context) error
+
+	GetPreferences(c echo.Context) error
+	UpdatePreferences(c echo.Context) error
+
+	Announce(c echo.Context) error
+}
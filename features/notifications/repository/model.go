@@ -0,0 +1,38 @@
+package repository
+
+import (
+	users "greenenvironment/features/users/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Notification struct {
+	*gorm.Model
+	ID      string     `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	UserID  string     `gorm:"type:varchar(50);not null;index:idx_notification_user_read;column:user_id"`
+	Type    string     `gorm:"type:varchar(50);not null;column:type"`
+	Title   string     `gorm:"type:varchar(255);not null;column:title"`
+	Message string     `gorm:"type:text;not null;column:message"`
+	Link    string     `gorm:"type:varchar(255);column:link"`
+	ReadAt  *time.Time `gorm:"type:datetime;index:idx_notification_user_read;column:read_at"`
+	User    users.User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+type NotificationPreference struct {
+	*gorm.Model
+	ID     string     `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	UserID string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_notification_preference;column:user_id"`
+	Type   string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_notification_preference;column:type"`
+	InApp  bool       `gorm:"not null;default:true;column:in_app"`
+	Email  bool       `gorm:"not null;default:false;column:email"`
+	User   users.User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
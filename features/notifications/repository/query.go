@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"errors"
+	"greenenvironment/constant"
+	"greenenvironment/features/notifications"
+	userRepo "greenenvironment/features/users/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationData struct {
+	DB *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) notifications.NotificationRepositoryInterface {
+	return &NotificationData{
+		DB: db,
+	}
+}
+
+func (nd *NotificationData) Create(notification notifications.Notification) error {
+	return nd.DB.Create(&Notification{
+		ID:      notification.ID,
+		UserID:  notification.UserID,
+		Type:    notification.Type,
+		Title:   notification.Title,
+		Message: notification.Message,
+		Link:    notification.Link,
+	}).Error
+}
+
+func (nd *NotificationData) GetByUserID(userID string, unreadOnly bool, page int) ([]notifications.Notification, int, error) {
+	var notificationData []Notification
+	var total int64
+
+	query := nd.DB.Model(&Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	notificationsPerPage := 20
+	totalPages := int((total + int64(notificationsPerPage) - 1) / int64(notificationsPerPage))
+
+	err := query.Order("created_at DESC").
+		Offset((page - 1) * notificationsPerPage).
+		Limit(notificationsPerPage).
+		Find(&notificationData).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var result []notifications.Notification
+	for _, notification := range notificationData {
+		result = append(result, notifications.Notification{
+			ID:        notification.ID,
+			UserID:    notification.UserID,
+			Type:      notification.Type,
+			Title:     notification.Title,
+			Message:   notification.Message,
+			Link:      notification.Link,
+			ReadAt:    notification.ReadAt,
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+	return result, totalPages, nil
+}
+
+func (nd *NotificationData) CountUnread(userID string) (int64, error) {
+	var count int64
+	err := nd.DB.Model(&Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+func (nd *NotificationData) MarkRead(userID, notificationID string, readAt time.Time) error {
+	var notification Notification
+	err := nd.DB.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return constant.ErrNotificationNotFound
+		}
+		return err
+	}
+
+	// Reading a notification twice keeps the time it was first read.
+	if notification.ReadAt != nil {
+		return nil
+	}
+	return nd.DB.Model(&Notification{}).Where("id = ?", notificationID).Update("read_at", readAt).Error
+}
+
+func (nd *NotificationData) MarkAllRead(userID string, readAt time.Time) error {
+	return nd.DB.Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", readAt).Error
+}
+
+func (nd *NotificationData) GetPreferences(userID string) ([]notifications.Preference, error) {
+	var preferenceData []NotificationPreference
+	if err := nd.DB.Where("user_id = ?", userID).Find(&preferenceData).Error; err != nil {
+		return nil, err
+	}
+
+	var result []notifications.Preference
+	for _, preference := range preferenceData {
+		result = append(result, notifications.Preference{
+			UserID: preference.UserID,
+			Type:   preference.Type,
+			InApp:  preference.InApp,
+			Email:  preference.Email,
+		})
+	}
+	return result, nil
+}
+
+func (nd *NotificationData) SavePreferences(preferences []notifications.Preference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+
+	var preferenceData []NotificationPreference
+	for _, preference := range preferences {
+		preferenceData = append(preferenceData, NotificationPreference{
+			ID:     uuid.New().String(),
+			UserID: preference.UserID,
+			Type:   preference.Type,
+			InApp:  preference.InApp,
+			Email:  preference.Email,
+		})
+	}
+
+	return nd.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"in_app", "email", "updated_at"}),
+	}).Create(&preferenceData).Error
+}
+
+func (nd *NotificationData) GetUserEmail(userID string) (string, error) {
+	var user userRepo.User
+	err := nd.DB.Select("email").Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", constant.UserNotFound
+		}
+		return "", err
+	}
+	return user.Email, nil
+}
+
+// broadcastBatchSize is how many notifications one insert of a broadcast
+// writes.
+const broadcastBatchSize = 500
+
+// Broadcast copies the notification to every user who has not turned off
+// in-app delivery for its type and returns the copies that were created.
+func (nd *NotificationData) Broadcast(notification notifications.Notification) ([]notifications.Notification, error) {
+	var sent []notifications.Notification
+	err := nd.DB.Transaction(func(tx *gorm.DB) error {
+		var recipients []string
+		err := tx.Model(&userRepo.User{}).
+			Where(`NOT EXISTS (
+				SELECT 1 FROM notification_preferences
+				WHERE notification_preferences.user_id = users.id
+				AND notification_preferences.type = ?
+				AND notification_preferences.in_app = false
+				AND notification_preferences.deleted_at IS NULL
+			)`, notification.Type).
+			Pluck("users.id", &recipients).Error
+		if err != nil {
+			return err
+		}
+		if len(recipients) == 0 {
+			return nil
+		}
+
+		rows := make([]Notification, 0, len(recipients))
+		for _, userID := range recipients {
+			rows = append(rows, Notification{
+				ID:      uuid.New().String(),
+				UserID:  userID,
+				Type:    notification.Type,
+				Title:   notification.Title,
+				Message: notification.Message,
+				Link:    notification.Link,
+			})
+		}
+		if err := tx.CreateInBatches(&rows, broadcastBatchSize).Error; err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			sent = append(sent, notifications.Notification{
+				ID:      row.ID,
+				UserID:  row.UserID,
+				Type:    row.Type,
+				Title:   row.Title,
+				Message: row.Message,
+				Link:    row.Link,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sent, nil
+}
+
+func (nd *NotificationData) GetEmailSubscribers(notificationType string) ([]string, error) {
+	var emails []string
+	err := nd.DB.Table("notification_preferences").
+		Joins("JOIN users ON users.id = notification_preferences.user_id AND users.deleted_at IS NULL").
+		Where("notification_preferences.type = ? AND notification_preferences.email = ? AND notification_preferences.deleted_at IS NULL", notificationType, true).
+		Pluck("users.email", &emails).Error
+	return emails, err
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"greenenvironment/constant"
+	"greenenvironment/features/notifications"
+	"greenenvironment/helper"
+	"greenenvironment/utils/realtime"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationService struct {
+	notificationRepo notifications.NotificationRepositoryInterface
+	mailer           helper.MailerInterface
+	publisher        realtime.Publisher
+}
+
+func NewNotificationService(nr notifications.NotificationRepositoryInterface, mailer helper.MailerInterface, publisher realtime.Publisher) notifications.NotificationServiceInterface {
+	return &NotificationService{
+		notificationRepo: nr,
+		mailer:           mailer,
+		publisher:        publisher,
+	}
+}
+
+func isValidType(notificationType string) bool {
+	for _, t := range notifications.Types {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultPreference(userID, notificationType string) notifications.Preference {
+	return notifications.Preference{
+		UserID: userID,
+		Type:   notificationType,
+		InApp:  true,
+		Email:  false,
+	}
+}
+
+// preference returns the stored preference of a user for one type, falling
+// back to the default when none was saved.
+func (ns *NotificationService) preference(userID, notificationType string) (notifications.Preference, error) {
+	preferences, err := ns.notificationRepo.GetPreferences(userID)
+	if err != nil {
+		return notifications.Preference{}, err
+	}
+
+	for _, preference := range preferences {
+		if preference.Type == notificationType {
+			return preference, nil
+		}
+	}
+	return defaultPreference(userID, notificationType), nil
+}
+
+func (ns *NotificationService) Notify(notification notifications.Notification) error {
+	if !isValidType(notification.Type) {
+		return constant.ErrInvalidNotificationType
+	}
+	if notification.UserID == "" || notification.Title == "" {
+		return constant.ErrFieldData
+	}
+
+	preference, err := ns.preference(notification.UserID, notification.Type)
+	if err != nil {
+		return err
+	}
+
+	if preference.InApp {
+		notification.ID = uuid.New().String()
+		if err := ns.notificationRepo.Create(notification); err != nil {
+			return err
+		}
+		ns.publisher.Publish(realtime.UserTopic(notification.UserID), realtime.Event{
+			Type: realtime.EventNotification,
+			Data: map[string]interface{}{
+				"id":      notification.ID,
+				"type":    notification.Type,
+				"title":   notification.Title,
+				"message": notification.Message,
+				"link":    notification.Link,
+			},
+		})
+	}
+
+	if preference.Email {
+		email, err := ns.notificationRepo.GetUserEmail(notification.UserID)
+		if err != nil {
+			return err
+		}
+		if err := ns.mailer.SendNotification(email, notification.Title, notification.Message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ns *NotificationService) GetNotifications(userID string, unreadOnly bool, page int) ([]notifications.Notification, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	return ns.notificationRepo.GetByUserID(userID, unreadOnly, page)
+}
+
+func (ns *NotificationService) CountUnread(userID string) (int64, error) {
+	return ns.notificationRepo.CountUnread(userID)
+}
+
+func (ns *NotificationService) MarkRead(userID, notificationID string) error {
+	if notificationID == "" {
+		return constant.ErrNotificationNotFound
+	}
+	return ns.notificationRepo.MarkRead(userID, notificationID, time.Now())
+}
+
+func (ns *NotificationService) MarkAllRead(userID string) error {
+	return ns.notificationRepo.MarkAllRead(userID, time.Now())
+}
+
+// GetPreferences returns one preference per notification type, filling in
+// the defaults for types the user never changed.
+func (ns *NotificationService) GetPreferences(userID string) ([]notifications.Preference, error) {
+	stored, err := ns.notificationRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string]notifications.Preference, len(stored))
+	for _, preference := range stored {
+		byType[preference.Type] = preference
+	}
+
+	result := make([]notifications.Preference, 0, len(notifications.Types))
+	for _, notificationType := range notifications.Types {
+		if preference, ok := byType[notificationType]; ok {
+			result = append(result, preference)
+			continue
+		}
+		result = append(result, defaultPreference(userID, notificationType))
+	}
+	return result, nil
+}
+
+func (ns *NotificationService) UpdatePreferences(userID string, preferences []notifications.Preference) ([]notifications.Preference, error) {
+	for i, preference := range preferences {
+		if !isValidType(preference.Type) {
+			return nil, constant.ErrInvalidNotificationType
+		}
+		preferences[i].UserID = userID
+	}
+
+	if err := ns.notificationRepo.SavePreferences(preferences); err != nil {
+		return nil, err
+	}
+	return ns.GetPreferences(userID)
+}
+
+func (ns *NotificationService) Announce(title, message, link string) (int64, error) {
+	title = strings.TrimSpace(title)
+	message = strings.TrimSpace(message)
+	if title == "" || message == "" {
+		return 0, constant.ErrAnnouncementField
+	}
+
+	sent, err := ns.notificationRepo.Broadcast(notifications.Notification{
+		Type:    notifications.TypeAnnouncement,
+		Title:   title,
+		Message: message,
+		Link:    link,
+	})
+	if err != nil {
+		return 0, err
+	}
+	// Only the users who received the announcement in-app get it pushed.
+	for _, notification := range sent {
+		ns.publisher.Publish(realtime.UserTopic(notification.UserID), realtime.Event{
+			Type: realtime.EventNotification,
+			Data: map[string]interface{}{
+				"id":      notification.ID,
+				"type":    notification.Type,
+				"title":   notification.Title,
+				"message": notification.Message,
+				"link":    notification.Link,
+			},
+		})
+	}
+	delivered := int64(len(sent))
+
+	emails, err := ns.notificationRepo.GetEmailSubscribers(notifications.TypeAnnouncement)
+	if err != nil {
+		log.Printf("failed to look up announcement email subscribers: %v", err)
+		return delivered, nil
+	}
+	for _, email := range emails {
+		// One bad address should not stop the rest of the subscribers from being emailed.
+		if err := ns.mailer.SendNotification(email, title, message); err != nil {
+			log.Printf("failed to email announcement to %s: %v", email, err)
+		}
+	}
+
+	return delivered, nil
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"greenenvironment/constant"
+	"greenenvironment/features/notifications"
+	"greenenvironment/utils/realtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) Create(notification notifications.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetByUserID(userID string, unreadOnly bool, page int) ([]notifications.Notification, int, error) {
+	args := m.Called(userID, unreadOnly, page)
+	return args.Get(0).([]notifications.Notification), args.Int(1), args.Error(2)
+}
+
+func (m *MockNotificationRepository) CountUnread(userID string) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkRead(userID, notificationID string, readAt time.Time) error {
+	args := m.Called(userID, notificationID, readAt)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) MarkAllRead(userID string, readAt time.Time) error {
+	args := m.Called(userID, readAt)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetPreferences(userID string) ([]notifications.Preference, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]notifications.Preference), args.Error(1)
+}
+
+func (m *MockNotificationRepository) SavePreferences(preferences []notifications.Preference) error {
+	args := m.Called(preferences)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetUserEmail(userID string) (string, error) {
+	args := m.Called(userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) Broadcast(notification notifications.Notification) ([]notifications.Notification, error) {
+	args := m.Called(notification)
+	return args.Get(0).([]notifications.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetEmailSubscribers(notificationType string) ([]string, error) {
+	args := m.Called(notificationType)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(to string, code string, subject string) error {
+	args := m.Called(to, code, subject)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendNotification(to string, subject string, message string) error {
+	args := m.Called(to, subject, message)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendTemplate(to string, template string, data map[string]interface{}) error {
+	args := m.Called(to, template, data)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendTemplateOnce(to string, template string, key string, data map[string]interface{}) error {
+	args := m.Called(to, template, key, data)
+	return args.Error(0)
+}
+
+type MockPublisher struct {
+	mock.Mock
+}
+
+func (m *MockPublisher) Publish(topic string, event realtime.Event) {
+	m.Called(topic, event)
+}
+
+func TestNotify_DefaultsToInApp(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	mockMailer := new(MockMailer)
+	mockPublisher := new(MockPublisher)
+	service := NewNotificationService(mockRepo, mockMailer, mockPublisher)
+
+	mockRepo.On("GetPreferences", "user-1").Return([]notifications.Preference{}, nil)
+	mockRepo.On("Create", mock.MatchedBy(func(n notifications.Notification) bool {
+		return n.ID != "" && n.UserID == "user-1" && n.Type == notifications.TypeReward
+	})).Return(nil)
+	mockPublisher.On("Publish", "user:user-1", mock.MatchedBy(func(e realtime.Event) bool {
+		return e.Type == realtime.EventNotification
+	})).Return()
+
+	err := service.Notify(notifications.Notification{
+		UserID: "user-1",
+		Type:   notifications.TypeReward,
+		Title:  "Rewards ready",
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+	mockMailer.AssertNotCalled(t, "SendNotification", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotify_EmailOnly(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	mockMailer := new(MockMailer)
+	service := NewNotificationService(mockRepo, mockMailer, new(MockPublisher))
+
+	mockRepo.On("GetPreferences", "user-1").Return([]notifications.Preference{
+		{UserID: "user-1", Type: notifications.TypeTransaction, InApp: false, Email: true},
+	}, nil)
+	mockRepo.On("GetUserEmail", "user-1").Return("user@example.com", nil)
+	mockMailer.On("SendNotification", "user@example.com", "Payment received", "Thanks").Return(nil)
+
+	err := service.Notify(notifications.Notification{
+		UserID:  "user-1",
+		Type:    notifications.TypeTransaction,
+		Title:   "Payment received",
+		Message: "Thanks",
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockMailer.AssertExpectations(t)
+}
+
+func TestNotify_InvalidType(t *testing.T) {
+	service := NewNotificationService(new(MockNotificationRepository), new(MockMailer), new(MockPublisher))
+
+	err := service.Notify(notifications.Notification{UserID: "user-1", Type: "unknown", Title: "Hi"})
+
+	assert.Equal(t, constant.ErrInvalidNotificationType, err)
+}
+
+func TestMarkRead_EmptyID(t *testing.T) {
+	service := NewNotificationService(new(MockNotificationRepository), new(MockMailer), new(MockPublisher))
+
+	err := service.MarkRead("user-1", "")
+
+	assert.Equal(t, constant.ErrNotificationNotFound, err)
+}
+
+func TestGetPreferences_FillsDefaults(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	service := NewNotificationService(mockRepo, new(MockMailer), new(MockPublisher))
+
+	mockRepo.On("GetPreferences", "user-1").Return([]notifications.Preference{
+		{UserID: "user-1", Type: notifications.TypeForumReply, InApp: false, Email: true},
+	}, nil)
+
+	preferences, err := service.GetPreferences("user-1")
+
+	assert.NoError(t, err)
+	assert.Len(t, preferences, len(notifications.Types))
+	for _, preference := range preferences {
+		if preference.Type == notifications.TypeForumReply {
+			assert.False(t, preference.InApp)
+			assert.True(t, preference.Email)
+		} else {
+			assert.True(t, preference.InApp)
+			assert.False(t, preference.Email)
+		}
+	}
+}
+
+func TestUpdatePreferences_InvalidType(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	service := NewNotificationService(mockRepo, new(MockMailer), new(MockPublisher))
+
+	_, err := service.UpdatePreferences("user-1", []notifications.Preference{{Type: "unknown"}})
+
+	assert.Equal(t, constant.ErrInvalidNotificationType, err)
+	mockRepo.AssertNotCalled(t, "SavePreferences", mock.Anything)
+}
+
+func TestUpdatePreferences_SetsUser(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	service := NewNotificationService(mockRepo, new(MockMailer), new(MockPublisher))
+
+	mockRepo.On("SavePreferences", []notifications.Preference{
+		{UserID: "user-1", Type: notifications.TypeAnnouncement, InApp: false},
+	}).Return(nil)
+	mockRepo.On("GetPreferences", "user-1").Return([]notifications.Preference{}, nil)
+
+	_, err := service.UpdatePreferences("user-1", []notifications.Preference{
+		{UserID: "someone-else", Type: notifications.TypeAnnouncement, InApp: false},
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAnnounce(t *testing.T) {
+	mockRepo := new(MockNotificationRepository)
+	mockMailer := new(MockMailer)
+	mockPublisher := new(MockPublisher)
+	service := NewNotificationService(mockRepo, mockMailer, mockPublisher)
+
+	announcement := notifications.Notification{
+		Type:    notifications.TypeAnnouncement,
+		Title:   "Maintenance",
+		Message: "Back soon",
+	}
+	first, second := announcement, announcement
+	first.ID, first.UserID = "n1", "user1"
+	second.ID, second.UserID = "n2", "user2"
+	mockRepo.On("Broadcast", announcement).Return([]notifications.Notification{first, second}, nil)
+	mockPublisher.On("Publish", "user:user1", mock.MatchedBy(func(event realtime.Event) bool {
+		return event.Data.(map[string]interface{})["id"] == "n1"
+	})).Return().Once()
+	mockPublisher.On("Publish", "user:user2", mock.MatchedBy(func(event realtime.Event) bool {
+		return event.Data.(map[string]interface{})["id"] == "n2"
+	})).Return().Once()
+	mockRepo.On("GetEmailSubscribers", notifications.TypeAnnouncement).Return([]string{"a@example.com", "b@example.com"}, nil)
+	mockMailer.On("SendNotification", "a@example.com", "Maintenance", "Back soon").Return(assert.AnError)
+	mockMailer.On("SendNotification", "b@example.com", "Maintenance", "Back soon").Return(nil)
+
+	delivered, err := service.Announce(" Maintenance ", "Back soon", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), delivered)
+	mockMailer.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestAnnounce_MissingFields(t *testing.T) {
+	service := NewNotificationService(new(MockNotificationRepository), new(MockMailer), new(MockPublisher))
+
+	_, err := service.Announce("", "Back soon", "")
+
+	assert.Equal(t, constant.ErrAnnouncementField, err)
+}
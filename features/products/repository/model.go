@@ -34,7 +34,7 @@ type ProductImpactCategory struct {
 	ProductID        string                        `gorm:"type:varchar(50);not null;column:product_id"`
 	ImpactCategoryID string                        `gorm:"type:varchar(50);not null;column:impact_category_id"`
 	Product          Product                       `gorm:"foreignKey:ProductID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	ImpactCategory   impactcategory.ImpactCategory `gorm:"foreignKey:ImpactCategoryID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ImpactCategory   impactcategory.ImpactCategory `gorm:"foreignKey:ImpactCategoryID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
 }
 
 type ProductLog struct {
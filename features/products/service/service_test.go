@@ -5,6 +5,7 @@ import (
 	"greenenvironment/features/impacts"
 	"greenenvironment/features/products"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -73,6 +74,46 @@ func (m *MockImpactRepo) Delete(category impacts.ImpactCategory) error {
 	return args.Error(0)
 }
 
+func (m *MockImpactRepo) GetUserPointsByCategory(userID string) ([]impacts.CategoryPoints, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]impacts.CategoryPoints), args.Error(1)
+}
+
+func (m *MockImpactRepo) GetUserMonthlyPoints(userID string, since time.Time) ([]impacts.CategoryPoints, error) {
+	args := m.Called(userID, since)
+	return args.Get(0).([]impacts.CategoryPoints), args.Error(1)
+}
+
+func (m *MockImpactRepo) GetConversionFactors(categoryID string) ([]impacts.ConversionFactor, error) {
+	args := m.Called(categoryID)
+	return args.Get(0).([]impacts.ConversionFactor), args.Error(1)
+}
+
+func (m *MockImpactRepo) SaveConversionFactors(categoryID string, factors []impacts.ConversionFactor) error {
+	args := m.Called(categoryID, factors)
+	return args.Error(0)
+}
+
+func (m *MockImpactRepo) Update(category impacts.ImpactCategory) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockImpactRepo) GetChildren(parentID string) ([]impacts.ImpactCategory, error) {
+	args := m.Called(parentID)
+	return args.Get(0).([]impacts.ImpactCategory), args.Error(1)
+}
+
+func (m *MockImpactRepo) GetUsage(categoryID string) (impacts.CategoryUsage, error) {
+	args := m.Called(categoryID)
+	return args.Get(0).(impacts.CategoryUsage), args.Error(1)
+}
+
+func (m *MockImpactRepo) ReassignAndDelete(categoryID, targetID string) error {
+	args := m.Called(categoryID, targetID)
+	return args.Error(0)
+}
+
 func TestCreateProduct(t *testing.T) {
 	mockProductRepo := new(MockProductRepo)
 	mockImpactRepo := new(MockImpactRepo)
@@ -0,0 +1,373 @@
+package controller
+
+import (
+	"greenenvironment/constant"
+	"greenenvironment/features/profiles"
+	"greenenvironment/helper"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ProfileHandler struct {
+	profileService profiles.ProfileServiceInterface
+	jwt            helper.JWTInterface
+}
+
+func NewProfileController(s profiles.ProfileServiceInterface, j helper.JWTInterface) profiles.ProfileControllerInterface {
+	return &ProfileHandler{
+		profileService: s,
+		jwt:            j,
+	}
+}
+
+// viewer returns who is making the request. Requests without a valid token
+// come from a visitor who is not logged in.
+func (h *ProfileHandler) viewer(c echo.Context) profiles.Viewer {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return profiles.Viewer{}
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return profiles.Viewer{}
+	}
+
+	userData := h.jwt.ExtractUserToken(token)
+	id, _ := userData[constant.JWT_ID].(string)
+	return profiles.Viewer{
+		ID:      id,
+		IsAdmin: userData[constant.JWT_ROLE] == constant.RoleAdmin,
+	}
+}
+
+// userID returns the ID of the user making the request, or false when the
+// request is not from a logged in user.
+func (h *ProfileHandler) userID(c echo.Context) (string, bool) {
+	viewer := h.viewer(c)
+	return viewer.ID, viewer.ID != "" && !viewer.IsAdmin
+}
+
+func pageParam(c echo.Context) int {
+	page, err := strconv.Atoi(c.QueryParam("pages"))
+	if err != nil || page < 1 {
+		return 1
+	}
+	return page
+}
+
+// Get Profile
+// @Summary      Get a public profile
+// @Description  Get the profile of a user as the caller is allowed to see it. Each section follows the privacy settings of the user; sections the caller may not see are null and listed in hidden. The user and admins see everything.
+// @Tags         Profiles
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  false  "Bearer Token"
+// @Param        username       path      string  true   "Username"
+// @Param        pages          query     int     false  "Page of forum posts"
+// @Success      200  {object}  helper.Response{data=ProfileResponse} "Profile retrieved successfully"
+// @Failure      404  {object}  helper.Response{data=string} "User not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /profiles/{username} [get]
+func (h *ProfileHandler) GetProfile(c echo.Context) error {
+	profile, err := h.profileService.GetProfile(c.Param("username"), h.viewer(c), pageParam(c))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "profile retrieved successfully", new(ProfileResponse).ToResponse(profile)))
+}
+
+// Get Followers
+// @Summary      Get the followers of a user
+// @Description  List who follows a user, the most recent first.
+// @Tags         Profiles
+// @Accept       json
+// @Produce      json
+// @Param        username  path   string  true   "Username"
+// @Param        pages     query  int     false  "Page number"
+// @Success      200  {object}  helper.MetadataResponse{data=[]FollowResponse} "Followers retrieved successfully"
+// @Failure      404  {object}  helper.Response{data=string} "User not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /profiles/{username}/followers [get]
+func (h *ProfileHandler) GetFollowers(c echo.Context) error {
+	page := pageParam(c)
+	follows, totalPages, err := h.profileService.GetFollowers(c.Param("username"), page)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "followers retrieved successfully", metadata, toFollowResponses(follows)))
+}
+
+// Get Following
+// @Summary      Get who a user follows
+// @Description  List the users a user follows, the most recently followed first.
+// @Tags         Profiles
+// @Accept       json
+// @Produce      json
+// @Param        username  path   string  true   "Username"
+// @Param        pages     query  int     false  "Page number"
+// @Success      200  {object}  helper.MetadataResponse{data=[]FollowResponse} "Following retrieved successfully"
+// @Failure      404  {object}  helper.Response{data=string} "User not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /profiles/{username}/following [get]
+func (h *ProfileHandler) GetFollowing(c echo.Context) error {
+	page := pageParam(c)
+	follows, totalPages, err := h.profileService.GetFollowing(c.Param("username"), page)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "following retrieved successfully", metadata, toFollowResponses(follows)))
+}
+
+// Follow User
+// @Summary      Ask to follow a user
+// @Description  Send a follow request to a user. They are notified and the follow counts once they accept it.
+// @Tags         Profiles
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        username       path      string  true  "Username"
+// @Success      201  {object}  helper.Response{data=string} "Follow request sent successfully"
+// @Failure      400  {object}  helper.Response{data=string} "You cannot follow yourself"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "User not found"
+// @Failure      409  {object}  helper.Response{data=string} "Already following this user or follow request already sent"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /profiles/{username}/follow [post]
+func (h *ProfileHandler) Follow(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := h.profileService.Follow(userID, c.Param("username")); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusCreated, helper.FormatResponse(true, "follow request sent successfully", nil))
+}
+
+// Unfollow User
+// @Summary      Unfollow a user
+// @Description  Stop following a user, or withdraw a follow request.
+// @Tags         Profiles
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        username       path      string  true  "Username"
+// @Success      200  {object}  helper.Response{data=string} "User unfollowed successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Not following this user"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /profiles/{username}/follow [delete]
+func (h *ProfileHandler) Unfollow(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := h.profileService.Unfollow(userID, c.Param("username")); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "user unfollowed successfully", nil))
+}
+
+// Get Follow Requests
+// @Summary      Get my follow requests
+// @Description  List who asked to follow the caller, the most recent first.
+// @Tags         Profiles
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        pages          query     int     false  "Page number"
+// @Success      200  {object}  helper.MetadataResponse{data=[]FollowResponse} "Follow requests retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /users/follow-requests [get]
+func (h *ProfileHandler) GetFollowRequests(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	page := pageParam(c)
+	follows, totalPages, err := h.profileService.GetFollowRequests(userID, page)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "follow requests retrieved successfully", metadata, toFollowResponses(follows)))
+}
+
+// Accept Follow Request
+// @Summary      Accept a follow request
+// @Description  Let a user who asked to follow the caller follow them. The user is notified.
+// @Tags         Profiles
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        username       path      string  true  "Username of the user who asked"
+// @Success      200  {object}  helper.Response{data=string} "Follow request accepted successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "User or follow request not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /users/follow-requests/{username} [post]
+func (h *ProfileHandler) AcceptFollowRequest(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := h.profileService.AcceptFollowRequest(userID, c.Param("username")); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "follow request accepted successfully", nil))
+}
+
+// Decline Follow Request
+// @Summary      Decline a follow request
+// @Description  Remove a request to follow the caller. The user who asked is not notified.
+// @Tags         Profiles
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        username       path      string  true  "Username of the user who asked"
+// @Success      200  {object}  helper.Response{data=string} "Follow request declined successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "User or follow request not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /users/follow-requests/{username} [delete]
+func (h *ProfileHandler) DeclineFollowRequest(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := h.profileService.DeclineFollowRequest(userID, c.Param("username")); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "follow request declined successfully", nil))
+}
+
+// Get Privacy Settings
+// @Summary      Get my privacy settings
+// @Description  Get who can see each section of the caller's profile: public, followers or private.
+// @Tags         Profiles
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Success      200  {object}  helper.Response{data=PrivacyResponse} "Privacy settings retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /users/privacy [get]
+func (h *ProfileHandler) GetPrivacy(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	settings, err := h.profileService.GetPrivacy(userID)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "privacy settings retrieved successfully", new(PrivacyResponse).ToResponse(settings)))
+}
+
+// Update Privacy Settings
+// @Summary      Update my privacy settings
+// @Description  Set who can see each section of the caller's profile: public, followers or private. The name, username and follower counts are always public.
+// @Tags         Profiles
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string          true  "Bearer Token"
+// @Param        request        body      PrivacyRequest  true  "Privacy settings"
+// @Success      200  {object}  helper.Response{data=PrivacyResponse} "Privacy settings updated successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Bad request"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /users/privacy [put]
+func (h *ProfileHandler) UpdatePrivacy(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	var request PrivacyRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "Error bad request", nil))
+	}
+
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	settings, err := h.profileService.UpdatePrivacy(profiles.PrivacySettings{
+		UserID:     userID,
+		Avatar:     request.Avatar,
+		Badges:     request.Badges,
+		Challenges: request.Challenges,
+		Impact:     request.Impact,
+		ForumPosts: request.ForumPosts,
+	})
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "privacy settings updated successfully", new(PrivacyResponse).ToResponse(settings)))
+}
+
+// Get Feed
+// @Summary      Get my activity feed
+// @Description  List the challenges completed and forum threads started by the people the caller follows, newest first. Sections a user made private stay out of the feed.
+// @Tags         Profiles
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        pages          query     int     false  "Page number"
+// @Success      200  {object}  helper.MetadataResponse{data=[]ActivityResponse} "Feed retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /users/feed [get]
+func (h *ProfileHandler) GetFeed(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	page := pageParam(c)
+	activities, totalPages, err := h.profileService.GetFeed(userID, page)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "feed retrieved successfully", metadata, toActivityResponses(activities)))
+}
@@ -0,0 +1,9 @@
+package controller
+
+type PrivacyRequest struct {
+	Avatar     string `json:"avatar" validate:"required"`
+	Badges     string `json:"badges" validate:"required"`
+	Challenges string `json:"challenges" validate:"required"`
+	Impact     string `json:"impact" validate:"required"`
+	ForumPosts string `json:"forum_posts" validate:"required"`
+}
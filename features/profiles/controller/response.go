@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"greenenvironment/features/impacts"
+	"greenenvironment/features/profiles"
+	"time"
+)
+
+type ProfileUserResponse struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	AvatarURL string    `json:"avatar_url"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+func toProfileUserResponse(user profiles.ProfileUser) ProfileUserResponse {
+	return ProfileUserResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Name:      user.Name,
+		AvatarURL: user.AvatarURL,
+		JoinedAt:  user.CreatedAt,
+	}
+}
+
+type BadgeResponse struct {
+	ImpactCategoryID string `json:"impact_category_id"`
+	Name             string `json:"name"`
+	Icon             string `json:"icon"`
+	Color            string `json:"color"`
+	Tier             string `json:"tier"`
+	Completed        int    `json:"completed"`
+}
+
+type ImpactCategoryResponse struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Icon  string `json:"icon"`
+	Color string `json:"color"`
+}
+
+type CompletedChallengeResponse struct {
+	ID               string                   `json:"id"`
+	Title            string                   `json:"title"`
+	Difficulty       string                   `json:"difficulty"`
+	ChallengeImg     string                   `json:"challenge_img"`
+	ImpactCategories []ImpactCategoryResponse `json:"impact_categories"`
+	CompletedAt      time.Time                `json:"completed_at"`
+}
+
+type ImpactMetricsResponse struct {
+	CO2Kg       float64 `json:"co2_kg"`
+	PlasticKg   float64 `json:"plastic_kg"`
+	WaterLiters float64 `json:"water_liters"`
+}
+
+type CategoryImpactResponse struct {
+	ImpactCategoryID string                `json:"impact_category_id"`
+	Name             string                `json:"name"`
+	Points           int                   `json:"points"`
+	Metrics          ImpactMetricsResponse `json:"metrics"`
+}
+
+type ImpactTotalsResponse struct {
+	TotalPoints int                      `json:"total_points"`
+	Metrics     ImpactMetricsResponse    `json:"metrics"`
+	Categories  []CategoryImpactResponse `json:"categories"`
+}
+
+type ForumPostResponse struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	TopicImage  string    `json:"topic_image"`
+	View        int       `json:"view"`
+	LikeCount   int       `json:"like_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ProfileResponse leaves the sections listed in hidden out, as null.
+type ProfileResponse struct {
+	User                ProfileUserResponse          `json:"user"`
+	FollowerCount       int                          `json:"follower_count"`
+	FollowingCount      int                          `json:"following_count"`
+	IsSelf              bool                         `json:"is_self"`
+	IsFollowing         bool                         `json:"is_following"`
+	IsRequested         bool                         `json:"is_requested"`
+	Badges              []BadgeResponse              `json:"badges"`
+	CompletedChallenges []CompletedChallengeResponse `json:"completed_challenges"`
+	Impact              *ImpactTotalsResponse        `json:"impact"`
+	ForumPosts          []ForumPostResponse          `json:"forum_posts"`
+	ForumPostPages      int                          `json:"forum_post_pages"`
+	Hidden              []string                     `json:"hidden"`
+}
+
+func toMetricsResponse(metrics impacts.ImpactMetrics) ImpactMetricsResponse {
+	return ImpactMetricsResponse{
+		CO2Kg:       metrics.CO2Kg,
+		PlasticKg:   metrics.PlasticKg,
+		WaterLiters: metrics.WaterLiters,
+	}
+}
+
+func (pr ProfileResponse) ToResponse(profile profiles.Profile) ProfileResponse {
+	response := ProfileResponse{
+		User:           toProfileUserResponse(profile.User),
+		FollowerCount:  profile.FollowerCount,
+		FollowingCount: profile.FollowingCount,
+		IsSelf:         profile.IsSelf,
+		IsFollowing:    profile.IsFollowing,
+		IsRequested:    profile.IsRequested,
+		ForumPostPages: profile.ForumPostPages,
+		Hidden:         []string{},
+	}
+	hidden := make(map[string]bool)
+	for _, section := range profile.Hidden {
+		hidden[section] = true
+		response.Hidden = append(response.Hidden, section)
+	}
+
+	if !hidden[profiles.SectionBadges] {
+		response.Badges = []BadgeResponse{}
+		for _, badge := range profile.Badges {
+			response.Badges = append(response.Badges, BadgeResponse{
+				ImpactCategoryID: badge.ImpactCategoryID,
+				Name:             badge.Name,
+				Icon:             badge.Icon,
+				Color:            badge.Color,
+				Tier:             badge.Tier,
+				Completed:        badge.Completed,
+			})
+		}
+	}
+
+	if !hidden[profiles.SectionChallenges] {
+		response.CompletedChallenges = []CompletedChallengeResponse{}
+		for _, challenge := range profile.CompletedChallenges {
+			challengeResponse := CompletedChallengeResponse{
+				ID:               challenge.ChallengeID,
+				Title:            challenge.Title,
+				Difficulty:       challenge.Difficulty,
+				ChallengeImg:     challenge.ChallengeImg,
+				ImpactCategories: []ImpactCategoryResponse{},
+				CompletedAt:      challenge.CompletedAt,
+			}
+			for _, category := range challenge.ImpactCategories {
+				challengeResponse.ImpactCategories = append(challengeResponse.ImpactCategories, ImpactCategoryResponse{
+					ID:    category.ID,
+					Name:  category.Name,
+					Icon:  category.Icon,
+					Color: category.Color,
+				})
+			}
+			response.CompletedChallenges = append(response.CompletedChallenges, challengeResponse)
+		}
+	}
+
+	if profile.Impact != nil {
+		response.Impact = &ImpactTotalsResponse{
+			TotalPoints: profile.Impact.TotalPoints,
+			Metrics:     toMetricsResponse(profile.Impact.Metrics),
+			Categories:  []CategoryImpactResponse{},
+		}
+		for _, category := range profile.Impact.Categories {
+			response.Impact.Categories = append(response.Impact.Categories, CategoryImpactResponse{
+				ImpactCategoryID: category.ImpactCategoryID,
+				Name:             category.Name,
+				Points:           category.Points,
+				Metrics:          toMetricsResponse(category.Metrics),
+			})
+		}
+	}
+
+	if !hidden[profiles.SectionForumPosts] {
+		response.ForumPosts = []ForumPostResponse{}
+		for _, post := range profile.ForumPosts {
+			response.ForumPosts = append(response.ForumPosts, ForumPostResponse{
+				ID:          post.ID,
+				Title:       post.Title,
+				Description: post.Description,
+				TopicImage:  post.TopicImage,
+				View:        post.View,
+				LikeCount:   post.LikeCount,
+				CreatedAt:   post.CreatedAt,
+			})
+		}
+	}
+
+	return response
+}
+
+type FollowResponse struct {
+	User       ProfileUserResponse `json:"user"`
+	FollowedAt time.Time           `json:"followed_at"`
+}
+
+func toFollowResponses(follows []profiles.Follow) []FollowResponse {
+	response := []FollowResponse{}
+	for _, follow := range follows {
+		response = append(response, FollowResponse{
+			User:       toProfileUserResponse(follow.User),
+			FollowedAt: follow.FollowedAt,
+		})
+	}
+	return response
+}
+
+type PrivacyResponse struct {
+	Avatar     string `json:"avatar"`
+	Badges     string `json:"badges"`
+	Challenges string `json:"challenges"`
+	Impact     string `json:"impact"`
+	ForumPosts string `json:"forum_posts"`
+}
+
+func (pr PrivacyResponse) ToResponse(settings profiles.PrivacySettings) PrivacyResponse {
+	return PrivacyResponse{
+		Avatar:     settings.Avatar,
+		Badges:     settings.Badges,
+		Challenges: settings.Challenges,
+		Impact:     settings.Impact,
+		ForumPosts: settings.ForumPosts,
+	}
+}
+
+type ActivityResponse struct {
+	Type      string              `json:"type"`
+	User      ProfileUserResponse `json:"user"`
+	TargetID  string              `json:"target_id"`
+	Title     string              `json:"title"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+func toActivityResponses(activities []profiles.Activity) []ActivityResponse {
+	response := []ActivityResponse{}
+	for _, activity := range activities {
+		response = append(response, ActivityResponse{
+			Type:      activity.Type,
+			User:      toProfileUserResponse(activity.User),
+			TargetID:  activity.TargetID,
+			Title:     activity.Title,
+			CreatedAt: activity.CreatedAt,
+		})
+	}
+	return response
+}
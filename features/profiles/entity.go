@@ -0,0 +1,217 @@
+package profiles
+
+import (
+	"greenenvironment/features/forum"
+	"greenenvironment/features/impacts"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Who can see a section of a profile. Followers also covers the user and
+// admins, who see every section.
+const (
+	VisibilityPublic    = "public"
+	VisibilityFollowers = "followers"
+	VisibilityPrivate   = "private"
+)
+
+// Follow states. A follow starts as a request the followed user approves,
+// since followers see the sections a user shares with followers only.
+const (
+	FollowPending  = "pending"
+	FollowAccepted = "accepted"
+)
+
+// Profile sections a user can set the visibility of. The name, username and
+// follower counts are always public.
+const (
+	SectionAvatar     = "avatar"
+	SectionBadges     = "badges"
+	SectionChallenges = "challenges"
+	SectionImpact     = "impact"
+	SectionForumPosts = "forum_posts"
+)
+
+// Badge tiers, earned by the hardest challenge completed in an impact
+// category.
+const (
+	TierBronze = "bronze"
+	TierSilver = "silver"
+	TierGold   = "gold"
+)
+
+// Activity types in the feed.
+const (
+	ActivityChallengeCompleted = "challenge_completed"
+	ActivityForumPost          = "forum_post"
+)
+
+// RecentChallengesLimit is how many completed challenges a profile lists.
+const RecentChallengesLimit = 10
+
+// PrivacySettings holds the visibility of each profile section. Users who
+// never saved theirs get DefaultPrivacy.
+type PrivacySettings struct {
+	UserID     string
+	Avatar     string
+	Badges     string
+	Challenges string
+	Impact     string
+	ForumPosts string
+}
+
+// DefaultPrivacy shows what the forum already makes public and keeps the
+// challenges and impact of a user to their followers.
+func DefaultPrivacy(userID string) PrivacySettings {
+	return PrivacySettings{
+		UserID:     userID,
+		Avatar:     VisibilityPublic,
+		Badges:     VisibilityPublic,
+		Challenges: VisibilityFollowers,
+		Impact:     VisibilityFollowers,
+		ForumPosts: VisibilityPublic,
+	}
+}
+
+// Visibility returns the visibility of one section.
+func (p PrivacySettings) Visibility(section string) string {
+	switch section {
+	case SectionAvatar:
+		return p.Avatar
+	case SectionBadges:
+		return p.Badges
+	case SectionChallenges:
+		return p.Challenges
+	case SectionImpact:
+		return p.Impact
+	case SectionForumPosts:
+		return p.ForumPosts
+	}
+	return VisibilityPrivate
+}
+
+// Viewer is who is looking at a profile. ID is empty for visitors who are
+// not logged in.
+type Viewer struct {
+	ID      string
+	IsAdmin bool
+}
+
+// ProfileUser is the part of a user that is shown to other users.
+type ProfileUser struct {
+	ID        string
+	Username  string
+	Name      string
+	AvatarURL string
+	CreatedAt time.Time
+}
+
+type Badge struct {
+	ImpactCategoryID string
+	Name             string
+	Icon             string
+	Color            string
+	Tier             string
+	Completed        int
+}
+
+type CompletedChallenge struct {
+	ChallengeID      string
+	Title            string
+	Difficulty       string
+	ChallengeImg     string
+	ImpactCategories []impacts.ImpactCategory
+	CompletedAt      time.Time
+}
+
+// Profile is a user as a viewer is allowed to see them. Sections the viewer
+// may not see are left empty and listed in Hidden. IsRequested is set while
+// the follow request of the viewer waits for approval.
+type Profile struct {
+	User                ProfileUser
+	FollowerCount       int
+	FollowingCount      int
+	IsSelf              bool
+	IsFollowing         bool
+	IsRequested         bool
+	Badges              []Badge
+	CompletedChallenges []CompletedChallenge
+	Impact              *impacts.UserImpactSummary
+	ForumPosts          []forum.Forum
+	ForumPostPages      int
+	Hidden              []string
+}
+
+// Follow is a user in a follower, following or follow request list.
+type Follow struct {
+	User       ProfileUser
+	FollowedAt time.Time
+}
+
+// Activity is an entry in the feed. TargetID is the challenge or forum
+// thread the activity is about.
+type Activity struct {
+	Type      string
+	User      ProfileUser
+	TargetID  string
+	Title     string
+	CreatedAt time.Time
+}
+
+// ForumPostReader is the part of the forum service a profile lists posts
+// from. Other users only get the threads that are not hidden.
+type ForumPostReader interface {
+	GetForumByUserID(ID string, page int) ([]forum.Forum, int, error)
+	GetVisibleForumByUserID(ID string, page int) ([]forum.Forum, int, error)
+}
+
+type ProfileControllerInterface interface {
+	GetProfile(c echo.Context) error
+	GetFollowers(c echo.Context) error
+	GetFollowing(c echo.Context) error
+	Follow(c echo.Context) error
+	Unfollow(c echo.Context) error
+	GetFollowRequests(c echo.Context) error
+	AcceptFollowRequest(c echo.Context) error
+	DeclineFollowRequest(c echo.Context) error
+
+	GetPrivacy(c echo.Context) error
+	UpdatePrivacy(c echo.Context) error
+	GetFeed(c echo.Context) error
+}
+
+type ProfileServiceInterface interface {
+	GetProfile(username string, viewer Viewer, forumPage int) (Profile, error)
+	GetFollowers(username string, page int) ([]Follow, int, error)
+	GetFollowing(username string, page int) ([]Follow, int, error)
+	Follow(followerID, username string) error
+	Unfollow(followerID, username string) error
+	GetFollowRequests(userID string, page int) ([]Follow, int, error)
+	AcceptFollowRequest(userID, username string) error
+	DeclineFollowRequest(userID, username string) error
+
+	GetPrivacy(userID string) (PrivacySettings, error)
+	UpdatePrivacy(settings PrivacySettings) (PrivacySettings, error)
+	GetFeed(userID string, page int) ([]Activity, int, error)
+}
+
+type ProfileRepositoryInterface interface {
+	GetUserByUsername(username string) (ProfileUser, error)
+	GetPrivacies(userIDs []string) ([]PrivacySettings, error)
+	SavePrivacy(settings PrivacySettings) error
+
+	Follow(followerID, followingID string) error
+	Unfollow(followerID, followingID string) error
+	GetFollowStatus(followerID, followingID string) (string, error)
+	AcceptFollow(followerID, followingID string) error
+	DeclineFollow(followerID, followingID string) error
+	CountFollows(userID string) (followers int, following int, err error)
+	GetFollowers(userID string, page int) ([]Follow, int, error)
+	GetFollowing(userID string, page int) ([]Follow, int, error)
+	GetFollowRequests(userID string, page int) ([]Follow, int, error)
+	GetFollowingIDs(userID string) ([]string, error)
+
+	GetCompletedChallenges(userID string) ([]CompletedChallenge, error)
+	GetFeed(challengeUserIDs, forumUserIDs []string, page int) ([]Activity, int, error)
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	users "greenenvironment/features/users/repository"
+
+	"gorm.io/gorm"
+)
+
+// UserFollow is a follow or a request to follow. Follows made before
+// requests existed come out of the migration pending, so users approve the
+// followers who see their followers-only sections.
+type UserFollow struct {
+	*gorm.Model
+	ID          string     `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	FollowerID  string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_follow;column:follower_id"`
+	FollowingID string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_follow;index;column:following_id"`
+	Status      string     `gorm:"type:enum('pending','accepted');not null;default:'pending';column:status"`
+	Follower    users.User `gorm:"foreignKey:FollowerID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Following   users.User `gorm:"foreignKey:FollowingID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type ProfilePrivacy struct {
+	*gorm.Model
+	UserID     string     `gorm:"primaryKey;type:varchar(50);not null;column:user_id"`
+	Avatar     string     `gorm:"type:enum('public','followers','private');not null;default:'public';column:avatar"`
+	Badges     string     `gorm:"type:enum('public','followers','private');not null;default:'public';column:badges"`
+	Challenges string     `gorm:"type:enum('public','followers','private');not null;default:'followers';column:challenges"`
+	Impact     string     `gorm:"type:enum('public','followers','private');not null;default:'followers';column:impact"`
+	ForumPosts string     `gorm:"type:enum('public','followers','private');not null;default:'public';column:forum_posts"`
+	User       users.User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (UserFollow) TableName() string {
+	return "user_follows"
+}
+
+func (ProfilePrivacy) TableName() string {
+	return "profile_privacies"
+}
@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"errors"
+	"greenenvironment/constant"
+	challenges "greenenvironment/features/challenges/repository"
+	"greenenvironment/features/impacts"
+	"greenenvironment/features/profiles"
+	users "greenenvironment/features/users/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Page sizes of the follow lists and the feed.
+const (
+	followsPerPage  = 20
+	activityPerPage = 20
+)
+
+type ProfileRepository struct {
+	DB *gorm.DB
+}
+
+func NewProfileRepository(db *gorm.DB) profiles.ProfileRepositoryInterface {
+	return &ProfileRepository{
+		DB: db,
+	}
+}
+
+// followRow is a user in a follow list or the feed, with the time of the
+// follow or the activity.
+type followRow struct {
+	ID            string
+	Username      string
+	Name          string
+	AvatarURL     string
+	UserCreatedAt time.Time
+	CreatedAt     time.Time
+}
+
+func (r followRow) user() profiles.ProfileUser {
+	return profiles.ProfileUser{
+		ID:        r.ID,
+		Username:  r.Username,
+		Name:      r.Name,
+		AvatarURL: r.AvatarURL,
+		CreatedAt: r.UserCreatedAt,
+	}
+}
+
+func (pr *ProfileRepository) GetUserByUsername(username string) (profiles.ProfileUser, error) {
+	var user users.User
+	err := pr.DB.Select("id", "username", "name", "avatar_url", "created_at").
+		Where("username = ?", username).
+		First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return profiles.ProfileUser{}, constant.UserNotFound
+	}
+	if err != nil {
+		return profiles.ProfileUser{}, err
+	}
+
+	profileUser := profiles.ProfileUser{
+		ID:        user.ID,
+		Username:  user.Username,
+		Name:      user.Name,
+		AvatarURL: user.AvatarURL,
+	}
+	if user.Model != nil {
+		profileUser.CreatedAt = user.CreatedAt
+	}
+	return profileUser, nil
+}
+
+// GetPrivacies returns the stored settings of the users that saved any.
+func (pr *ProfileRepository) GetPrivacies(userIDs []string) ([]profiles.PrivacySettings, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	var privacyData []ProfilePrivacy
+	if err := pr.DB.Where("user_id IN ?", userIDs).Find(&privacyData).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]profiles.PrivacySettings, 0, len(privacyData))
+	for _, privacy := range privacyData {
+		result = append(result, profiles.PrivacySettings{
+			UserID:     privacy.UserID,
+			Avatar:     privacy.Avatar,
+			Badges:     privacy.Badges,
+			Challenges: privacy.Challenges,
+			Impact:     privacy.Impact,
+			ForumPosts: privacy.ForumPosts,
+		})
+	}
+	return result, nil
+}
+
+func (pr *ProfileRepository) SavePrivacy(settings profiles.PrivacySettings) error {
+	return pr.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"avatar", "badges", "challenges", "impact", "forum_posts", "updated_at"}),
+	}).Create(&ProfilePrivacy{
+		UserID:     settings.UserID,
+		Avatar:     settings.Avatar,
+		Badges:     settings.Badges,
+		Challenges: settings.Challenges,
+		Impact:     settings.Impact,
+		ForumPosts: settings.ForumPosts,
+	}).Error
+}
+
+// Follow asks to follow a user. The follow counts once AcceptFollow
+// approves it.
+func (pr *ProfileRepository) Follow(followerID, followingID string) error {
+	return pr.DB.Omit("Follower", "Following").Create(&UserFollow{
+		ID:          uuid.New().String(),
+		FollowerID:  followerID,
+		FollowingID: followingID,
+		Status:      profiles.FollowPending,
+	}).Error
+}
+
+// Unfollow removes the follow, or withdraws the request, for good so the
+// pair can follow again later without hitting the unique index.
+func (pr *ProfileRepository) Unfollow(followerID, followingID string) error {
+	result := pr.DB.Unscoped().
+		Where("follower_id = ? AND following_id = ?", followerID, followingID).
+		Delete(&UserFollow{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrNotFollowing
+	}
+	return nil
+}
+
+// GetFollowStatus returns the state of the follow, or an empty string when
+// the follower neither follows nor asked to.
+func (pr *ProfileRepository) GetFollowStatus(followerID, followingID string) (string, error) {
+	var statuses []string
+	err := pr.DB.Model(&UserFollow{}).
+		Where("follower_id = ? AND following_id = ?", followerID, followingID).
+		Limit(1).
+		Pluck("status", &statuses).Error
+	if err != nil || len(statuses) == 0 {
+		return "", err
+	}
+	return statuses[0], nil
+}
+
+func (pr *ProfileRepository) AcceptFollow(followerID, followingID string) error {
+	result := pr.DB.Model(&UserFollow{}).
+		Where("follower_id = ? AND following_id = ? AND status = ?", followerID, followingID, profiles.FollowPending).
+		Update("status", profiles.FollowAccepted)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrFollowRequestNotFound
+	}
+	return nil
+}
+
+// DeclineFollow removes a pending request for good, like Unfollow.
+func (pr *ProfileRepository) DeclineFollow(followerID, followingID string) error {
+	result := pr.DB.Unscoped().
+		Where("follower_id = ? AND following_id = ? AND status = ?", followerID, followingID, profiles.FollowPending).
+		Delete(&UserFollow{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrFollowRequestNotFound
+	}
+	return nil
+}
+
+func (pr *ProfileRepository) CountFollows(userID string) (int, int, error) {
+	var followers, following int64
+	if err := pr.follows("following_id", userID, "follower_id", profiles.FollowAccepted).Count(&followers).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := pr.follows("follower_id", userID, "following_id", profiles.FollowAccepted).Count(&following).Error; err != nil {
+		return 0, 0, err
+	}
+	return int(followers), int(following), nil
+}
+
+func (pr *ProfileRepository) GetFollowers(userID string, page int) ([]profiles.Follow, int, error) {
+	return pr.getFollows("following_id", userID, "follower_id", profiles.FollowAccepted, page)
+}
+
+func (pr *ProfileRepository) GetFollowing(userID string, page int) ([]profiles.Follow, int, error) {
+	return pr.getFollows("follower_id", userID, "following_id", profiles.FollowAccepted, page)
+}
+
+// GetFollowRequests lists who asked to follow the user, with the time they
+// asked.
+func (pr *ProfileRepository) GetFollowRequests(userID string, page int) ([]profiles.Follow, int, error) {
+	return pr.getFollows("following_id", userID, "follower_id", profiles.FollowPending, page)
+}
+
+func (pr *ProfileRepository) getFollows(column, userID, userColumn, status string, page int) ([]profiles.Follow, int, error) {
+	var total int64
+	if err := pr.follows(column, userID, userColumn, status).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	totalPages := int((total + followsPerPage - 1) / followsPerPage)
+
+	var rows []followRow
+	err := pr.follows(column, userID, userColumn, status).
+		Select("users.id, users.username, users.name, users.avatar_url, users.created_at AS user_created_at, user_follows.created_at").
+		Order("user_follows.created_at DESC").
+		Offset((page - 1) * followsPerPage).
+		Limit(followsPerPage).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]profiles.Follow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, profiles.Follow{
+			User:       row.user(),
+			FollowedAt: row.CreatedAt,
+		})
+	}
+	return result, totalPages, nil
+}
+
+// follows selects the follows in the given state whose column is userID,
+// joined with the user on the other side of each follow. Follows of deleted
+// users are left out.
+func (pr *ProfileRepository) follows(column, userID, userColumn, status string) *gorm.DB {
+	return pr.DB.Model(&UserFollow{}).
+		Joins("JOIN users ON users.id = user_follows."+userColumn+" AND users.deleted_at IS NULL").
+		Where("user_follows."+column+" = ? AND user_follows.status = ?", userID, status)
+}
+
+func (pr *ProfileRepository) GetFollowingIDs(userID string) ([]string, error) {
+	var ids []string
+	err := pr.DB.Model(&UserFollow{}).
+		Where("follower_id = ? AND status = ?", userID, profiles.FollowAccepted).
+		Pluck("following_id", &ids).Error
+	return ids, err
+}
+
+// GetCompletedChallenges lists the challenges the user completed, the most
+// recent first. A log is last updated when it is marked Done, so that is
+// taken as the completion time.
+func (pr *ProfileRepository) GetCompletedChallenges(userID string) ([]profiles.CompletedChallenge, error) {
+	var logs []challenges.ChallengeLog
+	err := pr.DB.Preload("Challenge").
+		Preload("Challenge.ImpactCategories.ImpactCategory").
+		Where("user_id = ? AND status = ?", userID, "Done").
+		Order("updated_at DESC").
+		Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var result []profiles.CompletedChallenge
+	for _, log := range logs {
+		if log.Challenge.ID == "" {
+			continue
+		}
+
+		completed := profiles.CompletedChallenge{
+			ChallengeID:  log.Challenge.ID,
+			Title:        log.Challenge.Title,
+			Difficulty:   log.Challenge.Difficulty,
+			ChallengeImg: log.Challenge.ChallengeImg,
+		}
+		if log.Model != nil {
+			completed.CompletedAt = log.UpdatedAt
+		}
+		for _, category := range log.Challenge.ImpactCategories {
+			completed.ImpactCategories = append(completed.ImpactCategories, impacts.ImpactCategory{
+				ID:          category.ImpactCategory.ID,
+				Name:        category.ImpactCategory.Name,
+				ImpactPoint: category.ImpactCategory.ImpactPoint,
+				Description: category.ImpactCategory.Description,
+				Icon:        category.ImpactCategory.Icon,
+				Color:       category.ImpactCategory.Color,
+			})
+		}
+		result = append(result, completed)
+	}
+	return result, nil
+}
+
+// activitySQL merges the challenges completed by challengeUserIDs with the
+// visible forum threads started by forumUserIDs.
+const activitySQL = `
+	SELECT 'challenge_completed' AS type, challenge_logs.user_id, challenges.id AS target_id, challenges.title, challenge_logs.updated_at AS created_at
+	FROM challenge_logs
+	JOIN challenges ON challenges.id = challenge_logs.challenge_id AND challenges.deleted_at IS NULL
+	WHERE challenge_logs.status = 'Done' AND challenge_logs.deleted_at IS NULL AND challenge_logs.user_id IN @challengeUsers
+	UNION ALL
+	SELECT 'forum_post' AS type, forums.user_id, forums.id AS target_id, forums.title, forums.created_at
+	FROM forums
+	WHERE forums.hidden = false AND forums.deleted_at IS NULL AND forums.user_id IN @forumUsers
+`
+
+// feedFrom joins the activity with its users, leaving out deleted users.
+const feedFrom = `
+	FROM (` + activitySQL + `) activity
+	JOIN users ON users.id = activity.user_id AND users.deleted_at IS NULL
+`
+
+type activityRow struct {
+	followRow
+	Type     string
+	TargetID string
+	Title    string
+}
+
+func (pr *ProfileRepository) GetFeed(challengeUserIDs, forumUserIDs []string, page int) ([]profiles.Activity, int, error) {
+	if len(challengeUserIDs) == 0 && len(forumUserIDs) == 0 {
+		return []profiles.Activity{}, 0, nil
+	}
+	args := map[string]interface{}{
+		"challengeUsers": emptyAsNone(challengeUserIDs),
+		"forumUsers":     emptyAsNone(forumUserIDs),
+	}
+
+	var total int64
+	if err := pr.DB.Raw("SELECT COUNT(*)"+feedFrom, args).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	totalPages := int((total + activityPerPage - 1) / activityPerPage)
+
+	args["limit"] = activityPerPage
+	args["offset"] = (page - 1) * activityPerPage
+	var rows []activityRow
+	err := pr.DB.Raw(`
+		SELECT activity.type, activity.target_id, activity.title, activity.created_at,
+			users.id, users.username, users.name, users.avatar_url, users.created_at AS user_created_at`+
+		feedFrom+`
+		ORDER BY activity.created_at DESC
+		LIMIT @limit OFFSET @offset`, args).Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]profiles.Activity, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, profiles.Activity{
+			Type:      row.Type,
+			User:      row.user(),
+			TargetID:  row.TargetID,
+			Title:     row.Title,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+	return result, totalPages, nil
+}
+
+// emptyAsNone keeps an IN list valid SQL when there is nobody to match.
+func emptyAsNone(ids []string) []string {
+	if len(ids) == 0 {
+		return []string{""}
+	}
+	return ids
+}
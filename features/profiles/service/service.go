@@ -0,0 +1,387 @@
+package service
+
+import (
+	"errors"
+	"greenenvironment/constant"
+	"greenenvironment/features/impacts"
+	"greenenvironment/features/notifications"
+	"greenenvironment/features/profiles"
+	"log"
+	"sort"
+	"strings"
+)
+
+// tiers maps challenge difficulties to the badge tier they earn.
+var tiers = map[string]string{
+	"easy":   profiles.TierBronze,
+	"medium": profiles.TierSilver,
+	"hard":   profiles.TierGold,
+}
+
+var tierLevels = map[string]int{
+	profiles.TierBronze: 1,
+	profiles.TierSilver: 2,
+	profiles.TierGold:   3,
+}
+
+type ProfileService struct {
+	profileRepo   profiles.ProfileRepositoryInterface
+	impactService impacts.ImpactServiceInterface
+	forumPosts    profiles.ForumPostReader
+	notifier      notifications.Notifier
+}
+
+func NewProfileService(pr profiles.ProfileRepositoryInterface, is impacts.ImpactServiceInterface, fp profiles.ForumPostReader, notifier notifications.Notifier) profiles.ProfileServiceInterface {
+	return &ProfileService{
+		profileRepo:   pr,
+		impactService: is,
+		forumPosts:    fp,
+		notifier:      notifier,
+	}
+}
+
+// canSee reports whether a viewer may see a section with the given
+// visibility on someone's profile.
+func canSee(visibility string, isSelf, isAdmin, isFollower bool) bool {
+	if isSelf || isAdmin {
+		return true
+	}
+	switch visibility {
+	case profiles.VisibilityPublic:
+		return true
+	case profiles.VisibilityFollowers:
+		return isFollower
+	}
+	return false
+}
+
+func (ps *ProfileService) GetProfile(username string, viewer profiles.Viewer, forumPage int) (profiles.Profile, error) {
+	user, err := ps.profileRepo.GetUserByUsername(username)
+	if err != nil {
+		return profiles.Profile{}, err
+	}
+
+	profile := profiles.Profile{
+		User:   user,
+		IsSelf: viewer.ID == user.ID,
+	}
+	profile.FollowerCount, profile.FollowingCount, err = ps.profileRepo.CountFollows(user.ID)
+	if err != nil {
+		return profiles.Profile{}, err
+	}
+	if viewer.ID != "" && !profile.IsSelf {
+		status, err := ps.profileRepo.GetFollowStatus(viewer.ID, user.ID)
+		if err != nil {
+			return profiles.Profile{}, err
+		}
+		profile.IsFollowing = status == profiles.FollowAccepted
+		profile.IsRequested = status == profiles.FollowPending
+	}
+
+	privacy, err := ps.privacyOf(user.ID)
+	if err != nil {
+		return profiles.Profile{}, err
+	}
+	visible := func(section string) bool {
+		if canSee(privacy.Visibility(section), profile.IsSelf, viewer.IsAdmin, profile.IsFollowing) {
+			return true
+		}
+		profile.Hidden = append(profile.Hidden, section)
+		return false
+	}
+
+	if !visible(profiles.SectionAvatar) {
+		profile.User.AvatarURL = ""
+	}
+
+	showBadges := visible(profiles.SectionBadges)
+	showChallenges := visible(profiles.SectionChallenges)
+	if showBadges || showChallenges {
+		completed, err := ps.profileRepo.GetCompletedChallenges(user.ID)
+		if err != nil {
+			return profiles.Profile{}, err
+		}
+		if showBadges {
+			profile.Badges = badges(completed)
+		}
+		if showChallenges {
+			if len(completed) > profiles.RecentChallengesLimit {
+				completed = completed[:profiles.RecentChallengesLimit]
+			}
+			profile.CompletedChallenges = completed
+		}
+	}
+
+	if visible(profiles.SectionImpact) {
+		summary, err := ps.impactService.GetUserImpact(user.ID, 0)
+		if err != nil {
+			return profiles.Profile{}, err
+		}
+		profile.Impact = &summary
+	}
+
+	if visible(profiles.SectionForumPosts) {
+		getPosts := ps.forumPosts.GetVisibleForumByUserID
+		if profile.IsSelf || viewer.IsAdmin {
+			getPosts = ps.forumPosts.GetForumByUserID
+		}
+		posts, pages, err := getPosts(user.ID, forumPage)
+		if err != nil && !errors.Is(err, constant.ErrForumNotFound) {
+			return profiles.Profile{}, err
+		}
+		profile.ForumPosts = posts
+		profile.ForumPostPages = pages
+	}
+
+	return profile, nil
+}
+
+// badges gives one badge per impact category the user completed a challenge
+// in, tiered by the hardest of those challenges.
+func badges(completed []profiles.CompletedChallenge) []profiles.Badge {
+	byCategory := make(map[string]*profiles.Badge)
+	var order []string
+	for _, challenge := range completed {
+		tier, ok := tiers[strings.ToLower(strings.TrimSpace(challenge.Difficulty))]
+		if !ok {
+			continue
+		}
+		for _, category := range challenge.ImpactCategories {
+			badge, exists := byCategory[category.ID]
+			if !exists {
+				badge = &profiles.Badge{
+					ImpactCategoryID: category.ID,
+					Name:             category.Name,
+					Icon:             category.Icon,
+					Color:            category.Color,
+				}
+				byCategory[category.ID] = badge
+				order = append(order, category.ID)
+			}
+			badge.Completed++
+			if tierLevels[tier] > tierLevels[badge.Tier] {
+				badge.Tier = tier
+			}
+		}
+	}
+
+	result := make([]profiles.Badge, 0, len(order))
+	for _, categoryID := range order {
+		result = append(result, *byCategory[categoryID])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return tierLevels[result[i].Tier] > tierLevels[result[j].Tier]
+	})
+	return result
+}
+
+func (ps *ProfileService) GetFollowers(username string, page int) ([]profiles.Follow, int, error) {
+	user, err := ps.profileRepo.GetUserByUsername(username)
+	if err != nil {
+		return nil, 0, err
+	}
+	follows, totalPages, err := ps.profileRepo.GetFollowers(user.ID, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return follows, totalPages, ps.hidePrivateAvatars(follows)
+}
+
+func (ps *ProfileService) GetFollowing(username string, page int) ([]profiles.Follow, int, error) {
+	user, err := ps.profileRepo.GetUserByUsername(username)
+	if err != nil {
+		return nil, 0, err
+	}
+	follows, totalPages, err := ps.profileRepo.GetFollowing(user.ID, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return follows, totalPages, ps.hidePrivateAvatars(follows)
+}
+
+// hidePrivateAvatars clears the avatars in a follow list that are not public,
+// since anyone can see the list.
+func (ps *ProfileService) hidePrivateAvatars(follows []profiles.Follow) error {
+	userIDs := make([]string, 0, len(follows))
+	for _, follow := range follows {
+		userIDs = append(userIDs, follow.User.ID)
+	}
+	privacies, err := ps.privaciesOf(userIDs)
+	if err != nil {
+		return err
+	}
+	for i := range follows {
+		if privacies[follows[i].User.ID].Avatar != profiles.VisibilityPublic {
+			follows[i].User.AvatarURL = ""
+		}
+	}
+	return nil
+}
+
+// Follow asks to follow a user, who is notified and approves or declines
+// the request.
+func (ps *ProfileService) Follow(followerID, username string) error {
+	user, err := ps.profileRepo.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	if user.ID == followerID {
+		return constant.ErrFollowSelf
+	}
+
+	status, err := ps.profileRepo.GetFollowStatus(followerID, user.ID)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case profiles.FollowAccepted:
+		return constant.ErrAlreadyFollowing
+	case profiles.FollowPending:
+		return constant.ErrFollowRequestPending
+	}
+	if err := ps.profileRepo.Follow(followerID, user.ID); err != nil {
+		return err
+	}
+
+	err = ps.notifier.Notify(notifications.Notification{
+		UserID:  user.ID,
+		Type:    notifications.TypeFollower,
+		Title:   "New follow request",
+		Message: "Someone asked to follow you.",
+		Link:    "/users/follow-requests",
+	})
+	if err != nil {
+		log.Printf("failed to notify %s about a follow request: %v", user.ID, err)
+	}
+	return nil
+}
+
+func (ps *ProfileService) Unfollow(followerID, username string) error {
+	user, err := ps.profileRepo.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	return ps.profileRepo.Unfollow(followerID, user.ID)
+}
+
+func (ps *ProfileService) GetFollowRequests(userID string, page int) ([]profiles.Follow, int, error) {
+	follows, totalPages, err := ps.profileRepo.GetFollowRequests(userID, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return follows, totalPages, ps.hidePrivateAvatars(follows)
+}
+
+// AcceptFollowRequest lets the user with the given username follow userID
+// and tells them so.
+func (ps *ProfileService) AcceptFollowRequest(userID, username string) error {
+	follower, err := ps.profileRepo.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	if err := ps.profileRepo.AcceptFollow(follower.ID, userID); err != nil {
+		return err
+	}
+
+	err = ps.notifier.Notify(notifications.Notification{
+		UserID:  follower.ID,
+		Type:    notifications.TypeFollower,
+		Title:   "Follow request accepted",
+		Message: "Your follow request was accepted.",
+		Link:    "/users/feed",
+	})
+	if err != nil {
+		log.Printf("failed to notify %s about an accepted follow request: %v", follower.ID, err)
+	}
+	return nil
+}
+
+func (ps *ProfileService) DeclineFollowRequest(userID, username string) error {
+	follower, err := ps.profileRepo.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	return ps.profileRepo.DeclineFollow(follower.ID, userID)
+}
+
+func (ps *ProfileService) GetPrivacy(userID string) (profiles.PrivacySettings, error) {
+	return ps.privacyOf(userID)
+}
+
+func (ps *ProfileService) UpdatePrivacy(settings profiles.PrivacySettings) (profiles.PrivacySettings, error) {
+	for _, visibility := range []*string{&settings.Avatar, &settings.Badges, &settings.Challenges, &settings.Impact, &settings.ForumPosts} {
+		*visibility = strings.ToLower(strings.TrimSpace(*visibility))
+		switch *visibility {
+		case profiles.VisibilityPublic, profiles.VisibilityFollowers, profiles.VisibilityPrivate:
+		default:
+			return profiles.PrivacySettings{}, constant.ErrInvalidVisibility
+		}
+	}
+
+	if err := ps.profileRepo.SavePrivacy(settings); err != nil {
+		return profiles.PrivacySettings{}, err
+	}
+	return settings, nil
+}
+
+// GetFeed lists what the people the user follows did recently. Completed
+// challenges and forum threads only show up when their author lets
+// followers see those sections.
+func (ps *ProfileService) GetFeed(userID string, page int) ([]profiles.Activity, int, error) {
+	followingIDs, err := ps.profileRepo.GetFollowingIDs(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	privacies, err := ps.privaciesOf(followingIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var challengeUserIDs, forumUserIDs []string
+	for _, followingID := range followingIDs {
+		privacy := privacies[followingID]
+		if privacy.Challenges != profiles.VisibilityPrivate {
+			challengeUserIDs = append(challengeUserIDs, followingID)
+		}
+		if privacy.ForumPosts != profiles.VisibilityPrivate {
+			forumUserIDs = append(forumUserIDs, followingID)
+		}
+	}
+
+	activities, totalPages, err := ps.profileRepo.GetFeed(challengeUserIDs, forumUserIDs, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range activities {
+		if privacies[activities[i].User.ID].Avatar == profiles.VisibilityPrivate {
+			activities[i].User.AvatarURL = ""
+		}
+	}
+	return activities, totalPages, nil
+}
+
+func (ps *ProfileService) privacyOf(userID string) (profiles.PrivacySettings, error) {
+	privacies, err := ps.privaciesOf([]string{userID})
+	if err != nil {
+		return profiles.PrivacySettings{}, err
+	}
+	return privacies[userID], nil
+}
+
+// privaciesOf returns the settings of each user, with the defaults for the
+// users that never saved theirs.
+func (ps *ProfileService) privaciesOf(userIDs []string) (map[string]profiles.PrivacySettings, error) {
+	stored, err := ps.profileRepo.GetPrivacies(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]profiles.PrivacySettings, len(userIDs))
+	for _, userID := range userIDs {
+		result[userID] = profiles.DefaultPrivacy(userID)
+	}
+	for _, privacy := range stored {
+		result[privacy.UserID] = privacy
+	}
+	return result, nil
+}
@@ -0,0 +1,590 @@
+package service
+
+import (
+	"errors"
+	"greenenvironment/constant"
+	"greenenvironment/features/forum"
+	"greenenvironment/features/impacts"
+	"greenenvironment/features/notifications"
+	"greenenvironment/features/profiles"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProfileRepository struct {
+	mock.Mock
+}
+
+func (m *MockProfileRepository) GetUserByUsername(username string) (profiles.ProfileUser, error) {
+	args := m.Called(username)
+	return args.Get(0).(profiles.ProfileUser), args.Error(1)
+}
+
+func (m *MockProfileRepository) GetPrivacies(userIDs []string) ([]profiles.PrivacySettings, error) {
+	args := m.Called(userIDs)
+	return args.Get(0).([]profiles.PrivacySettings), args.Error(1)
+}
+
+func (m *MockProfileRepository) SavePrivacy(settings profiles.PrivacySettings) error {
+	args := m.Called(settings)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepository) Follow(followerID, followingID string) error {
+	args := m.Called(followerID, followingID)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepository) Unfollow(followerID, followingID string) error {
+	args := m.Called(followerID, followingID)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepository) GetFollowStatus(followerID, followingID string) (string, error) {
+	args := m.Called(followerID, followingID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockProfileRepository) AcceptFollow(followerID, followingID string) error {
+	args := m.Called(followerID, followingID)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepository) DeclineFollow(followerID, followingID string) error {
+	args := m.Called(followerID, followingID)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepository) CountFollows(userID string) (int, int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockProfileRepository) GetFollowers(userID string, page int) ([]profiles.Follow, int, error) {
+	args := m.Called(userID, page)
+	return args.Get(0).([]profiles.Follow), args.Int(1), args.Error(2)
+}
+
+func (m *MockProfileRepository) GetFollowing(userID string, page int) ([]profiles.Follow, int, error) {
+	args := m.Called(userID, page)
+	return args.Get(0).([]profiles.Follow), args.Int(1), args.Error(2)
+}
+
+func (m *MockProfileRepository) GetFollowRequests(userID string, page int) ([]profiles.Follow, int, error) {
+	args := m.Called(userID, page)
+	return args.Get(0).([]profiles.Follow), args.Int(1), args.Error(2)
+}
+
+func (m *MockProfileRepository) GetFollowingIDs(userID string) ([]string, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockProfileRepository) GetCompletedChallenges(userID string) ([]profiles.CompletedChallenge, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]profiles.CompletedChallenge), args.Error(1)
+}
+
+func (m *MockProfileRepository) GetFeed(challengeUserIDs, forumUserIDs []string, page int) ([]profiles.Activity, int, error) {
+	args := m.Called(challengeUserIDs, forumUserIDs, page)
+	return args.Get(0).([]profiles.Activity), args.Int(1), args.Error(2)
+}
+
+// MockImpactService only implements GetUserImpact, the one method profiles
+// use.
+type MockImpactService struct {
+	impacts.ImpactServiceInterface
+	mock.Mock
+}
+
+func (m *MockImpactService) GetUserImpact(userID string, months int) (impacts.UserImpactSummary, error) {
+	args := m.Called(userID, months)
+	return args.Get(0).(impacts.UserImpactSummary), args.Error(1)
+}
+
+type MockForumPostReader struct {
+	mock.Mock
+}
+
+func (m *MockForumPostReader) GetForumByUserID(ID string, page int) ([]forum.Forum, int, error) {
+	args := m.Called(ID, page)
+	return args.Get(0).([]forum.Forum), args.Int(1), args.Error(2)
+}
+
+func (m *MockForumPostReader) GetVisibleForumByUserID(ID string, page int) ([]forum.Forum, int, error) {
+	args := m.Called(ID, page)
+	return args.Get(0).([]forum.Forum), args.Int(1), args.Error(2)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(notification notifications.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+func newTestService() (*ProfileService, *MockProfileRepository, *MockImpactService, *MockForumPostReader, *MockNotifier) {
+	repo := new(MockProfileRepository)
+	impactService := new(MockImpactService)
+	forumPosts := new(MockForumPostReader)
+	notifier := new(MockNotifier)
+	service := NewProfileService(repo, impactService, forumPosts, notifier).(*ProfileService)
+	return service, repo, impactService, forumPosts, notifier
+}
+
+var alice = profiles.ProfileUser{ID: "user-1", Username: "alice", Name: "Alice", AvatarURL: "https://example.com/alice.png"}
+
+var (
+	recycling = impacts.ImpactCategory{ID: "cat-1", Name: "Recycling", Icon: "recycle", Color: "#00aa00"}
+	water     = impacts.ImpactCategory{ID: "cat-2", Name: "Water", Icon: "drop", Color: "#0000aa"}
+)
+
+func completedChallenges() []profiles.CompletedChallenge {
+	return []profiles.CompletedChallenge{
+		{ChallengeID: "ch-1", Title: "Sort your waste", Difficulty: "Easy", ImpactCategories: []impacts.ImpactCategory{recycling}},
+		{ChallengeID: "ch-2", Title: "Zero waste week", Difficulty: "Hard", ImpactCategories: []impacts.ImpactCategory{recycling, water}},
+		{ChallengeID: "ch-3", Title: "Shorter showers", Difficulty: "medium", ImpactCategories: []impacts.ImpactCategory{water}},
+	}
+}
+
+func TestBadges(t *testing.T) {
+	result := badges(completedChallenges())
+
+	assert.Equal(t, []profiles.Badge{
+		{ImpactCategoryID: "cat-1", Name: "Recycling", Icon: "recycle", Color: "#00aa00", Tier: profiles.TierGold, Completed: 2},
+		{ImpactCategoryID: "cat-2", Name: "Water", Icon: "drop", Color: "#0000aa", Tier: profiles.TierGold, Completed: 2},
+	}, result)
+}
+
+func TestBadges_TierOrderAndUnknownDifficulty(t *testing.T) {
+	result := badges([]profiles.CompletedChallenge{
+		{ChallengeID: "ch-1", Difficulty: "easy", ImpactCategories: []impacts.ImpactCategory{recycling}},
+		{ChallengeID: "ch-2", Difficulty: "medium", ImpactCategories: []impacts.ImpactCategory{water}},
+		{ChallengeID: "ch-3", Difficulty: "legendary", ImpactCategories: []impacts.ImpactCategory{recycling}},
+	})
+
+	assert.Len(t, result, 2)
+	assert.Equal(t, "cat-2", result[0].ImpactCategoryID)
+	assert.Equal(t, profiles.TierSilver, result[0].Tier)
+	assert.Equal(t, "cat-1", result[1].ImpactCategoryID)
+	assert.Equal(t, profiles.TierBronze, result[1].Tier)
+	assert.Equal(t, 1, result[1].Completed)
+}
+
+func TestGetProfile_VisitorSeesDefaultPublicSections(t *testing.T) {
+	service, repo, impactService, forumPosts, _ := newTestService()
+
+	repo.On("GetUserByUsername", "alice").Return(alice, nil)
+	repo.On("CountFollows", "user-1").Return(3, 2, nil)
+	repo.On("GetPrivacies", []string{"user-1"}).Return([]profiles.PrivacySettings{}, nil)
+	repo.On("GetCompletedChallenges", "user-1").Return(completedChallenges(), nil)
+	forumPosts.On("GetVisibleForumByUserID", "user-1", 1).Return([]forum.Forum{
+		{ID: "forum-1", Title: "Composting tips"},
+	}, 1, nil)
+
+	profile, err := service.GetProfile("alice", profiles.Viewer{}, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, alice, profile.User)
+	assert.Equal(t, 3, profile.FollowerCount)
+	assert.Equal(t, 2, profile.FollowingCount)
+	assert.False(t, profile.IsFollowing)
+	assert.Len(t, profile.Badges, 2)
+	assert.Nil(t, profile.CompletedChallenges)
+	assert.Nil(t, profile.Impact)
+	assert.Len(t, profile.ForumPosts, 1)
+	assert.Equal(t, "forum-1", profile.ForumPosts[0].ID)
+	assert.Equal(t, 1, profile.ForumPostPages)
+	assert.Equal(t, []string{profiles.SectionChallenges, profiles.SectionImpact}, profile.Hidden)
+	forumPosts.AssertNotCalled(t, "GetForumByUserID", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "GetFollowStatus", mock.Anything, mock.Anything)
+	impactService.AssertNotCalled(t, "GetUserImpact", mock.Anything, mock.Anything)
+}
+
+func TestGetProfile_FollowerSeesFollowerSections(t *testing.T) {
+	service, repo, impactService, forumPosts, _ := newTestService()
+
+	repo.On("GetUserByUsername", "alice").Return(alice, nil)
+	repo.On("CountFollows", "user-1").Return(1, 0, nil)
+	repo.On("GetFollowStatus", "user-2", "user-1").Return(profiles.FollowAccepted, nil)
+	repo.On("GetPrivacies", []string{"user-1"}).Return([]profiles.PrivacySettings{}, nil)
+	repo.On("GetCompletedChallenges", "user-1").Return(completedChallenges(), nil)
+	impactService.On("GetUserImpact", "user-1", 0).Return(impacts.UserImpactSummary{TotalPoints: 120}, nil)
+	forumPosts.On("GetVisibleForumByUserID", "user-1", 1).Return([]forum.Forum(nil), 0, constant.ErrForumNotFound)
+
+	profile, err := service.GetProfile("alice", profiles.Viewer{ID: "user-2"}, 1)
+
+	assert.NoError(t, err)
+	assert.True(t, profile.IsFollowing)
+	assert.Len(t, profile.CompletedChallenges, 3)
+	assert.Equal(t, 120, profile.Impact.TotalPoints)
+	assert.Empty(t, profile.ForumPosts)
+	assert.Empty(t, profile.Hidden)
+}
+
+func TestGetProfile_PrivateSections(t *testing.T) {
+	service, repo, impactService, forumPosts, _ := newTestService()
+
+	repo.On("GetUserByUsername", "alice").Return(alice, nil)
+	repo.On("CountFollows", "user-1").Return(1, 0, nil)
+	repo.On("GetFollowStatus", "user-2", "user-1").Return(profiles.FollowAccepted, nil)
+	repo.On("GetPrivacies", []string{"user-1"}).Return([]profiles.PrivacySettings{{
+		UserID:     "user-1",
+		Avatar:     profiles.VisibilityPrivate,
+		Badges:     profiles.VisibilityPrivate,
+		Challenges: profiles.VisibilityPrivate,
+		Impact:     profiles.VisibilityPrivate,
+		ForumPosts: profiles.VisibilityPrivate,
+	}}, nil)
+
+	profile, err := service.GetProfile("alice", profiles.Viewer{ID: "user-2"}, 1)
+
+	assert.NoError(t, err)
+	assert.Empty(t, profile.User.AvatarURL)
+	assert.Equal(t, "Alice", profile.User.Name)
+	assert.Equal(t, []string{
+		profiles.SectionAvatar,
+		profiles.SectionBadges,
+		profiles.SectionChallenges,
+		profiles.SectionImpact,
+		profiles.SectionForumPosts,
+	}, profile.Hidden)
+	repo.AssertNotCalled(t, "GetCompletedChallenges", mock.Anything)
+	impactService.AssertNotCalled(t, "GetUserImpact", mock.Anything, mock.Anything)
+	forumPosts.AssertNotCalled(t, "GetForumByUserID", mock.Anything, mock.Anything)
+	forumPosts.AssertNotCalled(t, "GetVisibleForumByUserID", mock.Anything, mock.Anything)
+}
+
+func TestGetProfile_SelfAndAdminSeeEverything(t *testing.T) {
+	private := profiles.PrivacySettings{
+		UserID:     "user-1",
+		Avatar:     profiles.VisibilityPrivate,
+		Badges:     profiles.VisibilityPrivate,
+		Challenges: profiles.VisibilityPrivate,
+		Impact:     profiles.VisibilityPrivate,
+		ForumPosts: profiles.VisibilityPrivate,
+	}
+
+	tests := []struct {
+		name   string
+		viewer profiles.Viewer
+		isSelf bool
+	}{
+		{name: "self", viewer: profiles.Viewer{ID: "user-1"}, isSelf: true},
+		{name: "admin", viewer: profiles.Viewer{ID: "admin-1", IsAdmin: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, impactService, forumPosts, _ := newTestService()
+
+			repo.On("GetUserByUsername", "alice").Return(alice, nil)
+			repo.On("CountFollows", "user-1").Return(0, 0, nil)
+			repo.On("GetFollowStatus", tt.viewer.ID, "user-1").Return("", nil)
+			repo.On("GetPrivacies", []string{"user-1"}).Return([]profiles.PrivacySettings{private}, nil)
+			repo.On("GetCompletedChallenges", "user-1").Return(completedChallenges(), nil)
+			impactService.On("GetUserImpact", "user-1", 0).Return(impacts.UserImpactSummary{TotalPoints: 40}, nil)
+			forumPosts.On("GetForumByUserID", "user-1", 1).Return([]forum.Forum{{ID: "forum-2", Hidden: true}}, 1, nil)
+
+			profile, err := service.GetProfile("alice", tt.viewer, 1)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.isSelf, profile.IsSelf)
+			assert.Equal(t, alice.AvatarURL, profile.User.AvatarURL)
+			assert.Len(t, profile.Badges, 2)
+			assert.Len(t, profile.CompletedChallenges, 3)
+			assert.NotNil(t, profile.Impact)
+			assert.Len(t, profile.ForumPosts, 1)
+			assert.Empty(t, profile.Hidden)
+		})
+	}
+}
+
+func TestGetProfile_LimitsCompletedChallenges(t *testing.T) {
+	service, repo, impactService, forumPosts, _ := newTestService()
+
+	var completed []profiles.CompletedChallenge
+	for i := 0; i < profiles.RecentChallengesLimit+5; i++ {
+		completed = append(completed, profiles.CompletedChallenge{Difficulty: "easy", ImpactCategories: []impacts.ImpactCategory{recycling}})
+	}
+	repo.On("GetUserByUsername", "alice").Return(alice, nil)
+	repo.On("CountFollows", "user-1").Return(0, 0, nil)
+	repo.On("GetPrivacies", []string{"user-1"}).Return([]profiles.PrivacySettings{}, nil)
+	repo.On("GetCompletedChallenges", "user-1").Return(completed, nil)
+	impactService.On("GetUserImpact", "user-1", 0).Return(impacts.UserImpactSummary{}, nil)
+	forumPosts.On("GetForumByUserID", "user-1", 1).Return([]forum.Forum{}, 0, nil)
+
+	profile, err := service.GetProfile("alice", profiles.Viewer{ID: "user-1"}, 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, profile.CompletedChallenges, profiles.RecentChallengesLimit)
+	assert.Equal(t, profiles.RecentChallengesLimit+5, profile.Badges[0].Completed)
+}
+
+func TestGetProfile_UserNotFound(t *testing.T) {
+	service, repo, _, _, _ := newTestService()
+
+	repo.On("GetUserByUsername", "nobody").Return(profiles.ProfileUser{}, constant.UserNotFound)
+
+	_, err := service.GetProfile("nobody", profiles.Viewer{}, 1)
+
+	assert.Equal(t, constant.UserNotFound, err)
+}
+
+func TestGetProfile_ForumError(t *testing.T) {
+	service, repo, _, forumPosts, _ := newTestService()
+
+	repo.On("GetUserByUsername", "alice").Return(alice, nil)
+	repo.On("CountFollows", "user-1").Return(0, 0, nil)
+	repo.On("GetPrivacies", []string{"user-1"}).Return([]profiles.PrivacySettings{}, nil)
+	repo.On("GetCompletedChallenges", "user-1").Return([]profiles.CompletedChallenge{}, nil)
+	forumPosts.On("GetVisibleForumByUserID", "user-1", 1).Return([]forum.Forum(nil), 0, errors.New("db down"))
+
+	_, err := service.GetProfile("alice", profiles.Viewer{}, 1)
+
+	assert.EqualError(t, err, "db down")
+}
+
+func TestFollow(t *testing.T) {
+	service, repo, _, _, notifier := newTestService()
+
+	repo.On("GetUserByUsername", "alice").Return(alice, nil)
+	repo.On("GetFollowStatus", "user-2", "user-1").Return("", nil)
+	repo.On("Follow", "user-2", "user-1").Return(nil)
+	notifier.On("Notify", mock.MatchedBy(func(n notifications.Notification) bool {
+		return n.UserID == "user-1" && n.Type == notifications.TypeFollower && n.Title == "New follow request" && n.Link == "/users/follow-requests"
+	})).Return(nil)
+
+	err := service.Follow("user-2", "alice")
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestFollow_NotifyErrorIsIgnored(t *testing.T) {
+	service, repo, _, _, notifier := newTestService()
+
+	repo.On("GetUserByUsername", "alice").Return(alice, nil)
+	repo.On("GetFollowStatus", "user-2", "user-1").Return("", nil)
+	repo.On("Follow", "user-2", "user-1").Return(nil)
+	notifier.On("Notify", mock.Anything).Return(errors.New("notify failed"))
+
+	err := service.Follow("user-2", "alice")
+
+	assert.NoError(t, err)
+}
+
+func TestFollow_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		followerID string
+		status     string
+		want       error
+	}{
+		{name: "self", followerID: "user-1", want: constant.ErrFollowSelf},
+		{name: "already following", followerID: "user-2", status: profiles.FollowAccepted, want: constant.ErrAlreadyFollowing},
+		{name: "already requested", followerID: "user-2", status: profiles.FollowPending, want: constant.ErrFollowRequestPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, _, _, notifier := newTestService()
+
+			repo.On("GetUserByUsername", "alice").Return(alice, nil)
+			repo.On("GetFollowStatus", tt.followerID, "user-1").Return(tt.status, nil)
+
+			err := service.Follow(tt.followerID, "alice")
+
+			assert.Equal(t, tt.want, err)
+			repo.AssertNotCalled(t, "Follow", mock.Anything, mock.Anything)
+			notifier.AssertNotCalled(t, "Notify", mock.Anything)
+		})
+	}
+}
+
+func TestUnfollow(t *testing.T) {
+	service, repo, _, _, _ := newTestService()
+
+	repo.On("GetUserByUsername", "alice").Return(alice, nil)
+	repo.On("Unfollow", "user-2", "user-1").Return(constant.ErrNotFollowing)
+
+	err := service.Unfollow("user-2", "alice")
+
+	assert.Equal(t, constant.ErrNotFollowing, err)
+}
+
+func TestGetProfile_PendingRequestSeesPublicSectionsOnly(t *testing.T) {
+	service, repo, impactService, forumPosts, _ := newTestService()
+
+	repo.On("GetUserByUsername", "alice").Return(alice, nil)
+	repo.On("CountFollows", "user-1").Return(0, 0, nil)
+	repo.On("GetFollowStatus", "user-2", "user-1").Return(profiles.FollowPending, nil)
+	repo.On("GetPrivacies", []string{"user-1"}).Return([]profiles.PrivacySettings{}, nil)
+	repo.On("GetCompletedChallenges", "user-1").Return(completedChallenges(), nil)
+	forumPosts.On("GetVisibleForumByUserID", "user-1", 1).Return([]forum.Forum{}, 0, nil)
+
+	profile, err := service.GetProfile("alice", profiles.Viewer{ID: "user-2"}, 1)
+
+	assert.NoError(t, err)
+	assert.False(t, profile.IsFollowing)
+	assert.True(t, profile.IsRequested)
+	assert.Equal(t, []string{profiles.SectionChallenges, profiles.SectionImpact}, profile.Hidden)
+	impactService.AssertNotCalled(t, "GetUserImpact", mock.Anything, mock.Anything)
+}
+
+func TestAcceptFollowRequest(t *testing.T) {
+	service, repo, _, _, notifier := newTestService()
+
+	bob := profiles.ProfileUser{ID: "user-2", Username: "bob"}
+	repo.On("GetUserByUsername", "bob").Return(bob, nil)
+	repo.On("AcceptFollow", "user-2", "user-1").Return(nil)
+	notifier.On("Notify", mock.MatchedBy(func(n notifications.Notification) bool {
+		return n.UserID == "user-2" && n.Type == notifications.TypeFollower && n.Title == "Follow request accepted"
+	})).Return(nil)
+
+	err := service.AcceptFollowRequest("user-1", "bob")
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestAcceptFollowRequest_NotFound(t *testing.T) {
+	service, repo, _, _, notifier := newTestService()
+
+	repo.On("GetUserByUsername", "bob").Return(profiles.ProfileUser{ID: "user-2", Username: "bob"}, nil)
+	repo.On("AcceptFollow", "user-2", "user-1").Return(constant.ErrFollowRequestNotFound)
+
+	err := service.AcceptFollowRequest("user-1", "bob")
+
+	assert.Equal(t, constant.ErrFollowRequestNotFound, err)
+	notifier.AssertNotCalled(t, "Notify", mock.Anything)
+}
+
+func TestDeclineFollowRequest(t *testing.T) {
+	service, repo, _, _, notifier := newTestService()
+
+	repo.On("GetUserByUsername", "bob").Return(profiles.ProfileUser{ID: "user-2", Username: "bob"}, nil)
+	repo.On("DeclineFollow", "user-2", "user-1").Return(nil)
+
+	err := service.DeclineFollowRequest("user-1", "bob")
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+	notifier.AssertNotCalled(t, "Notify", mock.Anything)
+}
+
+func TestGetFollowers_HidesNonPublicAvatars(t *testing.T) {
+	service, repo, _, _, _ := newTestService()
+
+	followedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	repo.On("GetUserByUsername", "alice").Return(alice, nil)
+	repo.On("GetFollowers", "user-1", 1).Return([]profiles.Follow{
+		{User: profiles.ProfileUser{ID: "user-2", AvatarURL: "bob.png"}, FollowedAt: followedAt},
+		{User: profiles.ProfileUser{ID: "user-3", AvatarURL: "carol.png"}, FollowedAt: followedAt},
+	}, 1, nil)
+	repo.On("GetPrivacies", []string{"user-2", "user-3"}).Return([]profiles.PrivacySettings{
+		{UserID: "user-3", Avatar: profiles.VisibilityFollowers},
+	}, nil)
+
+	follows, totalPages, err := service.GetFollowers("alice", 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, totalPages)
+	assert.Equal(t, "bob.png", follows[0].User.AvatarURL)
+	assert.Empty(t, follows[1].User.AvatarURL)
+}
+
+func TestGetPrivacy_Default(t *testing.T) {
+	service, repo, _, _, _ := newTestService()
+
+	repo.On("GetPrivacies", []string{"user-1"}).Return([]profiles.PrivacySettings{}, nil)
+
+	settings, err := service.GetPrivacy("user-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, profiles.DefaultPrivacy("user-1"), settings)
+}
+
+func TestUpdatePrivacy(t *testing.T) {
+	service, repo, _, _, _ := newTestService()
+
+	expected := profiles.PrivacySettings{
+		UserID:     "user-1",
+		Avatar:     profiles.VisibilityPublic,
+		Badges:     profiles.VisibilityFollowers,
+		Challenges: profiles.VisibilityPrivate,
+		Impact:     profiles.VisibilityPrivate,
+		ForumPosts: profiles.VisibilityPublic,
+	}
+	repo.On("SavePrivacy", expected).Return(nil)
+
+	settings, err := service.UpdatePrivacy(profiles.PrivacySettings{
+		UserID:     "user-1",
+		Avatar:     " Public ",
+		Badges:     "followers",
+		Challenges: "PRIVATE",
+		Impact:     "private",
+		ForumPosts: "public",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, settings)
+}
+
+func TestUpdatePrivacy_InvalidVisibility(t *testing.T) {
+	service, repo, _, _, _ := newTestService()
+
+	_, err := service.UpdatePrivacy(profiles.PrivacySettings{
+		UserID:     "user-1",
+		Avatar:     profiles.VisibilityPublic,
+		Badges:     "friends",
+		Challenges: profiles.VisibilityPublic,
+		Impact:     profiles.VisibilityPublic,
+		ForumPosts: profiles.VisibilityPublic,
+	})
+
+	assert.Equal(t, constant.ErrInvalidVisibility, err)
+	repo.AssertNotCalled(t, "SavePrivacy", mock.Anything)
+}
+
+func TestGetFeed_RespectsPrivacy(t *testing.T) {
+	service, repo, _, _, _ := newTestService()
+
+	repo.On("GetFollowingIDs", "user-1").Return([]string{"user-2", "user-3", "user-4"}, nil)
+	repo.On("GetPrivacies", []string{"user-2", "user-3", "user-4"}).Return([]profiles.PrivacySettings{
+		{UserID: "user-3", Avatar: profiles.VisibilityPrivate, Challenges: profiles.VisibilityPrivate, ForumPosts: profiles.VisibilityPublic},
+		{UserID: "user-4", Avatar: profiles.VisibilityFollowers, Challenges: profiles.VisibilityFollowers, ForumPosts: profiles.VisibilityPrivate},
+	}, nil)
+	repo.On("GetFeed", []string{"user-2", "user-4"}, []string{"user-2", "user-3"}, 2).Return([]profiles.Activity{
+		{Type: profiles.ActivityForumPost, User: profiles.ProfileUser{ID: "user-3", AvatarURL: "carol.png"}},
+		{Type: profiles.ActivityChallengeCompleted, User: profiles.ProfileUser{ID: "user-4", AvatarURL: "dan.png"}},
+	}, 3, nil)
+
+	activities, totalPages, err := service.GetFeed("user-1", 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, totalPages)
+	assert.Empty(t, activities[0].User.AvatarURL)
+	assert.Equal(t, "dan.png", activities[1].User.AvatarURL)
+}
+
+func TestGetFeed_NotFollowingAnyone(t *testing.T) {
+	service, repo, _, _, _ := newTestService()
+
+	repo.On("GetFollowingIDs", "user-1").Return([]string{}, nil)
+	repo.On("GetPrivacies", []string{}).Return([]profiles.PrivacySettings{}, nil)
+	repo.On("GetFeed", []string(nil), []string(nil), 1).Return([]profiles.Activity{}, 0, nil)
+
+	activities, totalPages, err := service.GetFeed("user-1", 1)
+
+	assert.NoError(t, err)
+	assert.Empty(t, activities)
+	assert.Equal(t, 0, totalPages)
+}
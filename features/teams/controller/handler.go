@@ -0,0 +1,324 @@
+package controller
+
+import (
+	"greenenvironment/constant"
+	"greenenvironment/features/teams"
+	"greenenvironment/helper"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TeamHandler struct {
+	teamService teams.TeamServiceInterface
+	jwt         helper.JWTInterface
+}
+
+func NewTeamController(s teams.TeamServiceInterface, j helper.JWTInterface) teams.TeamControllerInterface {
+	return &TeamHandler{
+		teamService: s,
+		jwt:         j,
+	}
+}
+
+func (h *TeamHandler) userID(c echo.Context) (string, bool) {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" {
+		return "", false
+	}
+
+	token, err := h.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return "", false
+	}
+
+	userData := h.jwt.ExtractUserToken(token)
+	return userData[constant.JWT_ID].(string), true
+}
+
+// Create Team
+// @Summary      Create a team
+// @Description  Create a new team with the authenticated user as its leader.
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string       true  "Bearer Token"
+// @Param        request        body      TeamRequest  true  "Team data"
+// @Success      201  {object}  helper.Response{data=TeamResponse} "Team created successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Bad request"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      409  {object}  helper.Response{data=string} "User already belongs to a team"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /teams [post]
+func (h *TeamHandler) CreateTeam(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	var teamRequest TeamRequest
+	if err := c.Bind(&teamRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "Error bad request", nil))
+	}
+
+	if err := c.Validate(teamRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	team, err := h.teamService.CreateTeam(teams.Team{
+		Name:        teamRequest.Name,
+		Description: teamRequest.Description,
+		LeaderID:    userID,
+	})
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusCreated, helper.FormatResponse(true, "team created successfully", new(TeamResponse).ToResponse(team)))
+}
+
+// Get All Teams
+// @Summary      Get all teams
+// @Description  Retrieve teams with pagination, optionally filtered by name.
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true   "Bearer Token"
+// @Param        pages          query     int     false  "Page number"
+// @Param        name           query     string  false  "Filter by team name"
+// @Success      200  {object}  helper.MetadataResponse{data=[]TeamResponse} "Teams retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /teams [get]
+func (h *TeamHandler) GetAllTeams(c echo.Context) error {
+	if _, ok := h.userID(c); !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	page, err := strconv.Atoi(c.QueryParam("pages"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	teamList, totalPages, err := h.teamService.GetAllByPage(page, c.QueryParam("name"))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	var response []TeamResponse
+	for _, team := range teamList {
+		response = append(response, new(TeamResponse).ToResponse(team))
+	}
+
+	metadata := map[string]interface{}{
+		"TotalPage": totalPages,
+		"Page":      page,
+	}
+
+	return c.JSON(http.StatusOK, helper.MetadataFormatResponse(true, "get all teams successfully", metadata, response))
+}
+
+// Get Team by ID
+// @Summary      Get a team by ID
+// @Description  Retrieve a team together with its members.
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Team ID"
+// @Success      200  {object}  helper.Response{data=TeamResponse} "Team retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Team not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /teams/{id} [get]
+func (h *TeamHandler) GetTeamByID(c echo.Context) error {
+	if _, ok := h.userID(c); !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	team, err := h.teamService.GetTeamByID(c.Param("id"))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "get team successfully", new(TeamResponse).ToResponse(team)))
+}
+
+// Get My Team
+// @Summary      Get my team
+// @Description  Retrieve the team the authenticated user belongs to.
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Success      200  {object}  helper.Response{data=TeamResponse} "Team retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Team not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /teams/me [get]
+func (h *TeamHandler) GetMyTeam(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	team, err := h.teamService.GetMyTeam(userID)
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "get my team successfully", new(TeamResponse).ToResponse(team)))
+}
+
+// Join Team
+// @Summary      Join a team
+// @Description  Join a team. A user can belong to only one team at a time.
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Team ID"
+// @Success      200  {object}  helper.Response{data=string} "Joined team successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Team not found"
+// @Failure      409  {object}  helper.Response{data=string} "Already in a team or team is full"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /teams/{id}/join [post]
+func (h *TeamHandler) JoinTeam(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := h.teamService.JoinTeam(c.Param("id"), userID); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "joined team successfully", nil))
+}
+
+// Leave Team
+// @Summary      Leave a team
+// @Description  Leave a team. The leader can only leave as the last member, which deletes the team.
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Team ID"
+// @Success      200  {object}  helper.Response{data=string} "Left team successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Leader cannot leave"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      403  {object}  helper.Response{data=string} "Not a team member"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /teams/{id}/leave [post]
+func (h *TeamHandler) LeaveTeam(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	if err := h.teamService.LeaveTeam(c.Param("id"), userID); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "left team successfully", nil))
+}
+
+// Enroll Team Challenge
+// @Summary      Enroll a team in a challenge
+// @Description  Enroll the team in a challenge that has a team target. Only the team leader can enroll.
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string                true  "Bearer Token"
+// @Param        id             path      string                true  "Team ID"
+// @Param        request        body      TeamChallengeRequest  true  "Challenge to enroll"
+// @Success      201  {object}  helper.Response{data=string} "Team enrolled successfully"
+// @Failure      400  {object}  helper.Response{data=string} "Challenge is not available for teams"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      403  {object}  helper.Response{data=string} "Not the team leader"
+// @Failure      409  {object}  helper.Response{data=string} "Already enrolled"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /teams/{id}/challenges [post]
+func (h *TeamHandler) EnrollChallenge(c echo.Context) error {
+	userID, ok := h.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	var request TeamChallengeRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, "Error bad request", nil))
+	}
+
+	if err := c.Validate(request); err != nil {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	if err := h.teamService.EnrollChallenge(c.Param("id"), request.ChallengeID, userID); err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	return c.JSON(http.StatusCreated, helper.FormatResponse(true, "team enrolled in challenge successfully", nil))
+}
+
+// Get Team Challenges
+// @Summary      Get team challenges
+// @Description  Retrieve the challenges a team is enrolled in with their collective progress.
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Param        id             path      string  true  "Team ID"
+// @Success      200  {object}  helper.Response{data=[]TeamChallengeResponse} "Team challenges retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      404  {object}  helper.Response{data=string} "Team not found"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /teams/{id}/challenges [get]
+func (h *TeamHandler) GetTeamChallenges(c echo.Context) error {
+	if _, ok := h.userID(c); !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	teamChallenges, err := h.teamService.GetTeamChallenges(c.Param("id"))
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	var response []TeamChallengeResponse
+	for _, teamChallenge := range teamChallenges {
+		response = append(response, new(TeamChallengeResponse).ToResponse(teamChallenge))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "get team challenges successfully", response))
+}
+
+// Get Team Leaderboard
+// @Summary      Get team leaderboard
+// @Description  Retrieve the top teams ranked by completed challenges and collective progress.
+// @Tags         Teams
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string  true  "Bearer Token"
+// @Success      200  {object}  helper.Response{data=[]TeamLeaderboardResponse} "Team leaderboard retrieved successfully"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Failure      500  {object}  helper.Response{data=string} "Internal server error"
+// @Router       /teams/leaderboard [get]
+func (h *TeamHandler) GetTeamLeaderboard(c echo.Context) error {
+	if _, ok := h.userID(c); !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	leaderboard, err := h.teamService.GetTeamLeaderboard()
+	if err != nil {
+		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
+	}
+
+	var response []TeamLeaderboardResponse
+	for _, entry := range leaderboard {
+		response = append(response, new(TeamLeaderboardResponse).ToResponse(entry))
+	}
+
+	return c.JSON(http.StatusOK, helper.FormatResponse(true, "get team leaderboard successfully", response))
+}
@@ -0,0 +1,10 @@
+package controller
+
+type TeamRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+}
+
+type TeamChallengeRequest struct {
+	ChallengeID string `json:"challenge_id" validate:"required"`
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"greenenvironment/features/teams"
+	"time"
+)
+
+type TeamResponse struct {
+	ID          string               `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	LeaderID    string               `json:"leader_id"`
+	MemberCount int                  `json:"member_count"`
+	Members     []TeamMemberResponse `json:"members,omitempty"`
+	CreatedAt   string               `json:"created_at"`
+}
+
+type TeamMemberResponse struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	AvatarURL string    `json:"avatar_url"`
+	Role      string    `json:"role"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+type TeamChallengeResponse struct {
+	ID             string     `json:"id"`
+	ChallengeID    string     `json:"challenge_id"`
+	ChallengeTitle string     `json:"challenge_title"`
+	TargetCount    int        `json:"target_count"`
+	Progress       int        `json:"progress"`
+	Status         string     `json:"status"`
+	RewardsGiven   bool       `json:"rewards_given"`
+	StartDate      time.Time  `json:"start_date"`
+	CompletedAt    *time.Time `json:"completed_at"`
+}
+
+type TeamLeaderboardResponse struct {
+	Rank                int    `json:"rank"`
+	ID                  string `json:"id"`
+	Name                string `json:"name"`
+	MemberCount         int    `json:"member_count"`
+	CompletedChallenges int    `json:"completed_challenges"`
+	TotalProgress       int    `json:"total_progress"`
+}
+
+func (tr TeamResponse) ToResponse(team teams.Team) TeamResponse {
+	response := TeamResponse{
+		ID:          team.ID,
+		Name:        team.Name,
+		Description: team.Description,
+		LeaderID:    team.LeaderID,
+		MemberCount: team.MemberCount,
+		CreatedAt:   team.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	for _, member := range team.Members {
+		response.Members = append(response.Members, TeamMemberResponse{
+			UserID:    member.UserID,
+			Username:  member.User.Username,
+			Name:      member.User.Name,
+			AvatarURL: member.User.AvatarURL,
+			Role:      member.Role,
+			JoinedAt:  member.JoinedAt,
+		})
+	}
+	return response
+}
+
+func (tcr TeamChallengeResponse) ToResponse(teamChallenge teams.TeamChallenge) TeamChallengeResponse {
+	return TeamChallengeResponse{
+		ID:             teamChallenge.ID,
+		ChallengeID:    teamChallenge.ChallengeID,
+		ChallengeTitle: teamChallenge.ChallengeTitle,
+		TargetCount:    teamChallenge.TargetCount,
+		Progress:       teamChallenge.Progress,
+		Status:         teamChallenge.Status,
+		RewardsGiven:   teamChallenge.RewardsGiven,
+		StartDate:      teamChallenge.StartDate,
+		CompletedAt:    teamChallenge.CompletedAt,
+	}
+}
+
+func (tlr TeamLeaderboardResponse) ToResponse(leaderboard teams.TeamLeaderboard) TeamLeaderboardResponse {
+	return TeamLeaderboardResponse{
+		Rank:                leaderboard.Rank,
+		ID:                  leaderboard.ID,
+		Name:                leaderboard.Name,
+		MemberCount:         leaderboard.MemberCount,
+		CompletedChallenges: leaderboard.CompletedChallenges,
+		TotalProgress:       leaderboard.TotalProgress,
+	}
+}
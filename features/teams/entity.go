@@ -0,0 +1,113 @@
+package teams
+
+import (
+	"greenenvironment/features/users"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const MaxTeamMembers = 20
+
+type Team struct {
+	ID          string
+	Name        string
+	Description string
+	LeaderID    string
+	MemberCount int
+	Members     []TeamMember
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type TeamMember struct {
+	ID       string
+	TeamID   string
+	UserID   string
+	Role     string
+	JoinedAt time.Time
+	User     users.User
+}
+
+type TeamChallenge struct {
+	ID             string
+	TeamID         string
+	ChallengeID    string
+	ChallengeTitle string
+	TargetCount    int
+	Progress       int
+	Status         string
+	RewardsGiven   bool
+	StartDate      time.Time
+	CompletedAt    *time.Time
+}
+
+type ChallengeGoal struct {
+	ChallengeID string
+	Title       string
+	TeamTarget  int
+	Exp         int
+	Coin        int
+}
+
+type TeamLeaderboard struct {
+	Rank                int
+	ID                  string
+	Name                string
+	MemberCount         int
+	CompletedChallenges int
+	TotalProgress       int
+}
+
+type TeamRepositoryInterface interface {
+	CreateTeam(team Team) error
+	GetAllByPage(page int, name string) ([]Team, int, error)
+	GetTeamByID(teamID string) (Team, error)
+	GetTeamByUserID(userID string) (Team, error)
+	DeleteTeam(teamID string) error
+
+	AddMember(member TeamMember, maxMembers int) error
+	RemoveMember(teamID, userID string) error
+	CountMembers(teamID string) (int, error)
+	IsMember(teamID, userID string) (bool, error)
+
+	GetChallengeGoal(challengeID string) (ChallengeGoal, error)
+	IsChallengeEnrolled(teamID, challengeID string) (bool, error)
+	CreateTeamChallenge(teamChallenge TeamChallenge) error
+	GetTeamChallenges(teamID string) ([]TeamChallenge, error)
+	GetActiveTeamChallenges() ([]TeamChallenge, error)
+	CountTeamProgress(teamID, challengeID string, since time.Time) (int, error)
+	UpdateTeamChallengeProgress(teamChallengeID string, progress int, status string) error
+	DistributeRewards(teamChallengeID, teamID string, exp, coin int) error
+
+	GetTeamLeaderboard(limit int) ([]TeamLeaderboard, error)
+}
+
+type TeamServiceInterface interface {
+	CreateTeam(team Team) (Team, error)
+	GetAllByPage(page int, name string) ([]Team, int, error)
+	GetTeamByID(teamID string) (Team, error)
+	GetMyTeam(userID string) (Team, error)
+	JoinTeam(teamID, userID string) error
+	LeaveTeam(teamID, userID string) error
+
+	EnrollChallenge(teamID, challengeID, userID string) error
+	GetTeamChallenges(teamID string) ([]TeamChallenge, error)
+	SyncActiveTeamChallenges() error
+
+	GetTeamLeaderboard() ([]TeamLeaderboard, error)
+}
+
+type TeamControllerInterface interface {
+	CreateTeam(c echo.Context) error
+	GetAllTeams(c echo.Context) error
+	GetTeamByID(c echo.Context) error
+	GetMyTeam(c echo.Context) error
+	JoinTeam(c echo.Context) error
+	LeaveTeam(c echo.Context) error
+
+	EnrollChallenge(c echo.Context) error
+	GetTeamChallenges(c echo.Context) error
+
+	GetTeamLeaderboard(c echo.Context) error
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	challenges "greenenvironment/features/challenges/repository"
+	users "greenenvironment/features/users/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Team struct {
+	*gorm.Model
+	ID          string       `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	Name        string       `gorm:"type:varchar(255);not null;unique;column:name"`
+	Description string       `gorm:"type:text;column:description"`
+	LeaderID    string       `gorm:"type:varchar(50);not null;column:leader_id"`
+	Leader      users.User   `gorm:"foreignKey:LeaderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Members     []TeamMember `gorm:"foreignKey:TeamID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type TeamMember struct {
+	*gorm.Model
+	ID       string     `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	TeamID   string     `gorm:"type:varchar(50);not null;column:team_id"`
+	UserID   string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_team_members_user;column:user_id"`
+	Role     string     `gorm:"type:enum('Leader','Member');not null;default:'Member';column:role"`
+	JoinedAt time.Time  `gorm:"type:datetime;not null;column:joined_at"`
+	User     users.User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+type TeamChallenge struct {
+	*gorm.Model
+	ID           string               `gorm:"primaryKey;type:varchar(50);not null;column:id"`
+	TeamID       string               `gorm:"type:varchar(50);not null;column:team_id"`
+	ChallengeID  string               `gorm:"type:varchar(50);not null;column:challenge_id"`
+	TargetCount  int                  `gorm:"type:int;not null;column:target_count"`
+	Progress     int                  `gorm:"type:int;not null;default:0;column:progress"`
+	Status       string               `gorm:"type:enum('Progress','Done');not null;default:'Progress';column:status"`
+	RewardsGiven bool                 `gorm:"type:boolean;default:false;column:rewards_given"`
+	StartDate    time.Time            `gorm:"type:datetime;not null;column:start_date"`
+	CompletedAt  *time.Time           `gorm:"type:datetime;column:completed_at"`
+	Team         Team                 `gorm:"foreignKey:TeamID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Challenge    challenges.Challenge `gorm:"foreignKey:ChallengeID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+func (Team) TableName() string {
+	return "teams"
+}
+
+func (TeamMember) TableName() string {
+	return "team_members"
+}
+
+func (TeamChallenge) TableName() string {
+	return "team_challenges"
+}
@@ -0,0 +1,415 @@
+package repository
+
+import (
+	"errors"
+	"greenenvironment/constant"
+	challengeRepo "greenenvironment/features/challenges/repository"
+	"greenenvironment/features/teams"
+	"greenenvironment/features/users"
+	userRepo "greenenvironment/features/users/repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TeamData struct {
+	DB *gorm.DB
+}
+
+func NewTeamRepository(db *gorm.DB) teams.TeamRepositoryInterface {
+	return &TeamData{
+		DB: db,
+	}
+}
+
+func (td *TeamData) CreateTeam(team teams.Team) error {
+	newTeam := Team{
+		ID:          team.ID,
+		Name:        team.Name,
+		Description: team.Description,
+		LeaderID:    team.LeaderID,
+	}
+
+	for _, member := range team.Members {
+		newTeam.Members = append(newTeam.Members, TeamMember{
+			ID:       member.ID,
+			TeamID:   team.ID,
+			UserID:   member.UserID,
+			Role:     member.Role,
+			JoinedAt: member.JoinedAt,
+		})
+	}
+
+	if err := td.DB.Create(&newTeam).Error; err != nil {
+		return constant.ErrCreateTeam
+	}
+	return nil
+}
+
+func (td *TeamData) GetAllByPage(page int, name string) ([]teams.Team, int, error) {
+	var teamData []Team
+	var totalTeams int64
+
+	query := td.DB.Model(&Team{})
+	if name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+
+	if err := query.Count(&totalTeams).Error; err != nil {
+		return nil, 0, constant.ErrGetTeam
+	}
+
+	teamsPerPage := 20
+	totalPages := int((totalTeams + int64(teamsPerPage) - 1) / int64(teamsPerPage))
+
+	err := query.Preload("Members").
+		Order("created_at DESC").
+		Offset((page - 1) * teamsPerPage).
+		Limit(teamsPerPage).
+		Find(&teamData).Error
+	if err != nil {
+		return nil, 0, constant.ErrGetTeam
+	}
+
+	var result []teams.Team
+	for _, team := range teamData {
+		result = append(result, teams.Team{
+			ID:          team.ID,
+			Name:        team.Name,
+			Description: team.Description,
+			LeaderID:    team.LeaderID,
+			MemberCount: len(team.Members),
+			CreatedAt:   team.CreatedAt,
+			UpdatedAt:   team.UpdatedAt,
+		})
+	}
+
+	return result, totalPages, nil
+}
+
+func (td *TeamData) GetTeamByID(teamID string) (teams.Team, error) {
+	var team Team
+	err := td.DB.Preload("Members.User").Where("id = ?", teamID).First(&team).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return teams.Team{}, constant.ErrTeamNotFound
+	}
+	if err != nil {
+		return teams.Team{}, err
+	}
+
+	return toTeamEntity(team), nil
+}
+
+func (td *TeamData) GetTeamByUserID(userID string) (teams.Team, error) {
+	var member TeamMember
+	err := td.DB.Where("user_id = ?", userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return teams.Team{}, constant.ErrTeamNotFound
+	}
+	if err != nil {
+		return teams.Team{}, err
+	}
+
+	return td.GetTeamByID(member.TeamID)
+}
+
+// DeleteTeam removes a team for good, so its name can be taken again and its
+// members can join other teams.
+func (td *TeamData) DeleteTeam(teamID string) error {
+	tx := td.DB.Begin()
+
+	if err := tx.Unscoped().Where("team_id = ?", teamID).Delete(&TeamChallenge{}).Error; err != nil {
+		tx.Rollback()
+		return constant.ErrDeleteTeam
+	}
+
+	if err := tx.Unscoped().Where("team_id = ?", teamID).Delete(&TeamMember{}).Error; err != nil {
+		tx.Rollback()
+		return constant.ErrDeleteTeam
+	}
+
+	if err := tx.Unscoped().Where("id = ?", teamID).Delete(&Team{}).Error; err != nil {
+		tx.Rollback()
+		return constant.ErrDeleteTeam
+	}
+
+	return tx.Commit().Error
+}
+
+// AddMember adds a member unless the team already has maxMembers. The team
+// row stays locked from the count to the insert, so two users cannot take
+// the last place together. A user is kept to one team by the unique index on
+// team_members.user_id.
+func (td *TeamData) AddMember(member teams.TeamMember, maxMembers int) error {
+	newMember := TeamMember{
+		ID:       member.ID,
+		TeamID:   member.TeamID,
+		UserID:   member.UserID,
+		Role:     member.Role,
+		JoinedAt: member.JoinedAt,
+	}
+
+	return td.DB.Transaction(func(tx *gorm.DB) error {
+		var team Team
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", member.TeamID).
+			First(&team).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return constant.ErrTeamNotFound
+		}
+		if err != nil {
+			return constant.ErrJoinTeam
+		}
+
+		var count int64
+		if err := tx.Model(&TeamMember{}).Where("team_id = ?", member.TeamID).Count(&count).Error; err != nil {
+			return constant.ErrJoinTeam
+		}
+		if int(count) >= maxMembers {
+			return constant.ErrTeamFull
+		}
+
+		if err := tx.Create(&newMember).Error; err != nil {
+			return constant.ErrJoinTeam
+		}
+		return nil
+	})
+}
+
+// RemoveMember deletes the membership for good, so the user can join another
+// team.
+func (td *TeamData) RemoveMember(teamID, userID string) error {
+	result := td.DB.Unscoped().Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&TeamMember{})
+	if result.Error != nil {
+		return constant.ErrLeaveTeam
+	}
+	if result.RowsAffected == 0 {
+		return constant.ErrNotTeamMember
+	}
+	return nil
+}
+
+func (td *TeamData) CountMembers(teamID string) (int, error) {
+	var count int64
+	err := td.DB.Model(&TeamMember{}).Where("team_id = ?", teamID).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (td *TeamData) IsMember(teamID, userID string) (bool, error) {
+	var count int64
+	err := td.DB.Model(&TeamMember{}).
+		Where("team_id = ? AND user_id = ?", teamID, userID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (td *TeamData) GetChallengeGoal(challengeID string) (teams.ChallengeGoal, error) {
+	var challenge challengeRepo.Challenge
+	err := td.DB.Select("id", "title", "team_target", "exp", "coin").
+		Where("id = ?", challengeID).
+		First(&challenge).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return teams.ChallengeGoal{}, constant.ErrChallengeNotFound
+	}
+	if err != nil {
+		return teams.ChallengeGoal{}, err
+	}
+
+	return teams.ChallengeGoal{
+		ChallengeID: challenge.ID,
+		Title:       challenge.Title,
+		TeamTarget:  challenge.TeamTarget,
+		Exp:         challenge.Exp,
+		Coin:        challenge.Coin,
+	}, nil
+}
+
+func (td *TeamData) IsChallengeEnrolled(teamID, challengeID string) (bool, error) {
+	var count int64
+	err := td.DB.Model(&TeamChallenge{}).
+		Where("team_id = ? AND challenge_id = ?", teamID, challengeID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (td *TeamData) CreateTeamChallenge(teamChallenge teams.TeamChallenge) error {
+	newTeamChallenge := TeamChallenge{
+		ID:          teamChallenge.ID,
+		TeamID:      teamChallenge.TeamID,
+		ChallengeID: teamChallenge.ChallengeID,
+		TargetCount: teamChallenge.TargetCount,
+		Progress:    teamChallenge.Progress,
+		Status:      teamChallenge.Status,
+		StartDate:   teamChallenge.StartDate,
+	}
+
+	if err := td.DB.Create(&newTeamChallenge).Error; err != nil {
+		return constant.ErrEnrollTeamChallenge
+	}
+	return nil
+}
+
+func (td *TeamData) GetTeamChallenges(teamID string) ([]teams.TeamChallenge, error) {
+	var teamChallenges []TeamChallenge
+	err := td.DB.Preload("Challenge").
+		Where("team_id = ?", teamID).
+		Order("start_date DESC").
+		Find(&teamChallenges).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return toTeamChallengeEntities(teamChallenges), nil
+}
+
+func (td *TeamData) GetActiveTeamChallenges() ([]teams.TeamChallenge, error) {
+	var teamChallenges []TeamChallenge
+	err := td.DB.Where("status = ? OR rewards_given = ?", "Progress", false).
+		Find(&teamChallenges).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return toTeamChallengeEntities(teamChallenges), nil
+}
+
+// CountTeamProgress counts the approved task confirmations submitted by the
+// current members of a team for the given challenge since the team enrolled.
+func (td *TeamData) CountTeamProgress(teamID, challengeID string, since time.Time) (int, error) {
+	var count int64
+	err := td.DB.Table("challenge_confirmations").
+		Joins("JOIN challenge_tasks ON challenge_tasks.id = challenge_confirmations.challenge_task_id").
+		Joins("JOIN team_members ON team_members.user_id = challenge_confirmations.user_id AND team_members.deleted_at IS NULL").
+		Where("team_members.team_id = ?", teamID).
+		Where("challenge_tasks.challenge_id = ?", challengeID).
+		Where("challenge_confirmations.status = ?", "Done").
+		Where("challenge_confirmations.submission_date >= ?", since).
+		Where("challenge_confirmations.deleted_at IS NULL").
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (td *TeamData) UpdateTeamChallengeProgress(teamChallengeID string, progress int, status string) error {
+	updates := map[string]interface{}{
+		"progress": progress,
+		"status":   status,
+	}
+	if status == "Done" {
+		updates["completed_at"] = gorm.Expr("COALESCE(completed_at, ?)", time.Now())
+	}
+
+	err := td.DB.Model(&TeamChallenge{}).Where("id = ?", teamChallengeID).Updates(updates).Error
+	if err != nil {
+		return constant.ErrUpdateTeamChallenge
+	}
+	return nil
+}
+
+// DistributeRewards marks the team challenge as rewarded and credits every
+// member in a single transaction, so a reward is never handed out twice.
+func (td *TeamData) DistributeRewards(teamChallengeID, teamID string, exp, coin int) error {
+	return td.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&TeamChallenge{}).
+			Where("id = ? AND rewards_given = ?", teamChallengeID, false).
+			Update("rewards_given", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return constant.ErrRewardAlreadyClaimed
+		}
+
+		memberIDs := tx.Model(&TeamMember{}).Select("user_id").Where("team_id = ?", teamID)
+		return tx.Model(&userRepo.User{}).
+			Where("id IN (?)", memberIDs).
+			Updates(map[string]interface{}{
+				"exp":  gorm.Expr("exp + ?", exp),
+				"coin": gorm.Expr("coin + ?", coin),
+			}).Error
+	})
+}
+
+func (td *TeamData) GetTeamLeaderboard(limit int) ([]teams.TeamLeaderboard, error) {
+	var leaderboard []teams.TeamLeaderboard
+	query := `
+			SELECT t.id, t.name,
+				(SELECT COUNT(*) FROM team_members tm WHERE tm.team_id = t.id AND tm.deleted_at IS NULL) AS member_count,
+				(SELECT COUNT(*) FROM team_challenges tc WHERE tc.team_id = t.id AND tc.status = 'Done' AND tc.deleted_at IS NULL) AS completed_challenges,
+				(SELECT COALESCE(SUM(tc.progress), 0) FROM team_challenges tc WHERE tc.team_id = t.id AND tc.deleted_at IS NULL) AS total_progress
+			FROM teams t
+			WHERE t.deleted_at IS NULL
+			ORDER BY completed_challenges DESC, total_progress DESC, t.name ASC
+			LIMIT ?
+	`
+	if err := td.DB.Raw(query, limit).Scan(&leaderboard).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range leaderboard {
+		leaderboard[i].Rank = i + 1
+	}
+	return leaderboard, nil
+}
+
+func toTeamEntity(team Team) teams.Team {
+	result := teams.Team{
+		ID:          team.ID,
+		Name:        team.Name,
+		Description: team.Description,
+		LeaderID:    team.LeaderID,
+		MemberCount: len(team.Members),
+		CreatedAt:   team.CreatedAt,
+		UpdatedAt:   team.UpdatedAt,
+	}
+
+	for _, member := range team.Members {
+		result.Members = append(result.Members, teams.TeamMember{
+			ID:       member.ID,
+			TeamID:   member.TeamID,
+			UserID:   member.UserID,
+			Role:     member.Role,
+			JoinedAt: member.JoinedAt,
+			User: users.User{
+				ID:        member.User.ID,
+				Name:      member.User.Name,
+				Username:  member.User.Username,
+				AvatarURL: member.User.AvatarURL,
+				Exp:       member.User.Exp,
+			},
+		})
+	}
+	return result
+}
+
+func toTeamChallengeEntities(teamChallenges []TeamChallenge) []teams.TeamChallenge {
+	var result []teams.TeamChallenge
+	for _, teamChallenge := range teamChallenges {
+		result = append(result, teams.TeamChallenge{
+			ID:             teamChallenge.ID,
+			TeamID:         teamChallenge.TeamID,
+			ChallengeID:    teamChallenge.ChallengeID,
+			ChallengeTitle: teamChallenge.Challenge.Title,
+			TargetCount:    teamChallenge.TargetCount,
+			Progress:       teamChallenge.Progress,
+			Status:         teamChallenge.Status,
+			RewardsGiven:   teamChallenge.RewardsGiven,
+			StartDate:      teamChallenge.StartDate,
+			CompletedAt:    teamChallenge.CompletedAt,
+		})
+	}
+	return result
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"greenenvironment/constant"
+	"greenenvironment/features/teams"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TeamService struct {
+	teamRepo teams.TeamRepositoryInterface
+}
+
+func NewTeamService(tr teams.TeamRepositoryInterface) teams.TeamServiceInterface {
+	return &TeamService{
+		teamRepo: tr,
+	}
+}
+
+func (ts *TeamService) CreateTeam(team teams.Team) (teams.Team, error) {
+	if team.Name == "" {
+		return teams.Team{}, constant.ErrTeamField
+	}
+
+	existing, err := ts.teamRepo.GetTeamByUserID(team.LeaderID)
+	if err != nil && err != constant.ErrTeamNotFound {
+		return teams.Team{}, err
+	}
+	if existing.ID != "" {
+		return teams.Team{}, constant.ErrUserAlreadyInTeam
+	}
+
+	team.ID = uuid.New().String()
+	team.Members = []teams.TeamMember{
+		{
+			ID:       uuid.New().String(),
+			TeamID:   team.ID,
+			UserID:   team.LeaderID,
+			Role:     "Leader",
+			JoinedAt: time.Now(),
+		},
+	}
+
+	if err := ts.teamRepo.CreateTeam(team); err != nil {
+		return teams.Team{}, err
+	}
+
+	return ts.teamRepo.GetTeamByID(team.ID)
+}
+
+func (ts *TeamService) GetAllByPage(page int, name string) ([]teams.Team, int, error) {
+	return ts.teamRepo.GetAllByPage(page, name)
+}
+
+func (ts *TeamService) GetTeamByID(teamID string) (teams.Team, error) {
+	return ts.teamRepo.GetTeamByID(teamID)
+}
+
+func (ts *TeamService) GetMyTeam(userID string) (teams.Team, error) {
+	return ts.teamRepo.GetTeamByUserID(userID)
+}
+
+func (ts *TeamService) JoinTeam(teamID, userID string) error {
+	if _, err := ts.teamRepo.GetTeamByID(teamID); err != nil {
+		return err
+	}
+
+	existing, err := ts.teamRepo.GetTeamByUserID(userID)
+	if err != nil && err != constant.ErrTeamNotFound {
+		return err
+	}
+	if existing.ID != "" {
+		return constant.ErrUserAlreadyInTeam
+	}
+
+	member := teams.TeamMember{
+		ID:       uuid.New().String(),
+		TeamID:   teamID,
+		UserID:   userID,
+		Role:     "Member",
+		JoinedAt: time.Now(),
+	}
+	return ts.teamRepo.AddMember(member, teams.MaxTeamMembers)
+}
+
+func (ts *TeamService) LeaveTeam(teamID, userID string) error {
+	team, err := ts.teamRepo.GetTeamByID(teamID)
+	if err != nil {
+		return err
+	}
+
+	isMember, err := ts.teamRepo.IsMember(teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return constant.ErrNotTeamMember
+	}
+
+	if team.LeaderID == userID {
+		count, err := ts.teamRepo.CountMembers(teamID)
+		if err != nil {
+			return err
+		}
+		if count > 1 {
+			return constant.ErrTeamLeaderCannotLeave
+		}
+		// The leader is the last member, so the team goes with them.
+		return ts.teamRepo.DeleteTeam(teamID)
+	}
+
+	return ts.teamRepo.RemoveMember(teamID, userID)
+}
+
+func (ts *TeamService) EnrollChallenge(teamID, challengeID, userID string) error {
+	team, err := ts.teamRepo.GetTeamByID(teamID)
+	if err != nil {
+		return err
+	}
+	if team.LeaderID != userID {
+		return constant.ErrNotTeamLeader
+	}
+
+	goal, err := ts.teamRepo.GetChallengeGoal(challengeID)
+	if err != nil {
+		return err
+	}
+	if goal.TeamTarget <= 0 {
+		return constant.ErrNotTeamChallenge
+	}
+
+	enrolled, err := ts.teamRepo.IsChallengeEnrolled(teamID, challengeID)
+	if err != nil {
+		return err
+	}
+	if enrolled {
+		return constant.ErrTeamChallengeAlreadyEnrolled
+	}
+
+	teamChallenge := teams.TeamChallenge{
+		ID:          uuid.New().String(),
+		TeamID:      teamID,
+		ChallengeID: challengeID,
+		TargetCount: goal.TeamTarget,
+		Progress:    0,
+		Status:      "Progress",
+		StartDate:   time.Now(),
+	}
+	return ts.teamRepo.CreateTeamChallenge(teamChallenge)
+}
+
+func (ts *TeamService) GetTeamChallenges(teamID string) ([]teams.TeamChallenge, error) {
+	if _, err := ts.teamRepo.GetTeamByID(teamID); err != nil {
+		return nil, err
+	}
+	return ts.teamRepo.GetTeamChallenges(teamID)
+}
+
+func (ts *TeamService) SyncActiveTeamChallenges() error {
+	teamChallenges, err := ts.teamRepo.GetActiveTeamChallenges()
+	if err != nil {
+		return err
+	}
+
+	for _, teamChallenge := range teamChallenges {
+		if err := ts.syncTeamChallenge(teamChallenge); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ts *TeamService) syncTeamChallenge(teamChallenge teams.TeamChallenge) error {
+	if teamChallenge.Status != "Done" {
+		progress, err := ts.teamRepo.CountTeamProgress(teamChallenge.TeamID, teamChallenge.ChallengeID, teamChallenge.StartDate)
+		if err != nil {
+			return err
+		}
+
+		status := "Progress"
+		if progress >= teamChallenge.TargetCount {
+			status = "Done"
+		}
+
+		if err := ts.teamRepo.UpdateTeamChallengeProgress(teamChallenge.ID, progress, status); err != nil {
+			return err
+		}
+		teamChallenge.Status = status
+	}
+
+	if teamChallenge.Status != "Done" || teamChallenge.RewardsGiven {
+		return nil
+	}
+
+	goal, err := ts.teamRepo.GetChallengeGoal(teamChallenge.ChallengeID)
+	if err != nil {
+		return err
+	}
+
+	err = ts.teamRepo.DistributeRewards(teamChallenge.ID, teamChallenge.TeamID, goal.Exp, goal.Coin)
+	if err != nil && err != constant.ErrRewardAlreadyClaimed {
+		return err
+	}
+	return nil
+}
+
+func (ts *TeamService) GetTeamLeaderboard() ([]teams.TeamLeaderboard, error) {
+	return ts.teamRepo.GetTeamLeaderboard(10)
+}
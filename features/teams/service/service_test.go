@@ -0,0 +1,306 @@
+package service
+
+import (
+	"greenenvironment/constant"
+	"greenenvironment/features/teams"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTeamRepository struct {
+	mock.Mock
+}
+
+func (m *MockTeamRepository) CreateTeam(team teams.Team) error {
+	args := m.Called(team)
+	return args.Error(0)
+}
+
+func (m *MockTeamRepository) GetAllByPage(page int, name string) ([]teams.Team, int, error) {
+	args := m.Called(page, name)
+	return args.Get(0).([]teams.Team), args.Int(1), args.Error(2)
+}
+
+func (m *MockTeamRepository) GetTeamByID(teamID string) (teams.Team, error) {
+	args := m.Called(teamID)
+	return args.Get(0).(teams.Team), args.Error(1)
+}
+
+func (m *MockTeamRepository) GetTeamByUserID(userID string) (teams.Team, error) {
+	args := m.Called(userID)
+	return args.Get(0).(teams.Team), args.Error(1)
+}
+
+func (m *MockTeamRepository) DeleteTeam(teamID string) error {
+	args := m.Called(teamID)
+	return args.Error(0)
+}
+
+func (m *MockTeamRepository) AddMember(member teams.TeamMember, maxMembers int) error {
+	args := m.Called(member, maxMembers)
+	return args.Error(0)
+}
+
+func (m *MockTeamRepository) RemoveMember(teamID, userID string) error {
+	args := m.Called(teamID, userID)
+	return args.Error(0)
+}
+
+func (m *MockTeamRepository) CountMembers(teamID string) (int, error) {
+	args := m.Called(teamID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTeamRepository) IsMember(teamID, userID string) (bool, error) {
+	args := m.Called(teamID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTeamRepository) GetChallengeGoal(challengeID string) (teams.ChallengeGoal, error) {
+	args := m.Called(challengeID)
+	return args.Get(0).(teams.ChallengeGoal), args.Error(1)
+}
+
+func (m *MockTeamRepository) IsChallengeEnrolled(teamID, challengeID string) (bool, error) {
+	args := m.Called(teamID, challengeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTeamRepository) CreateTeamChallenge(teamChallenge teams.TeamChallenge) error {
+	args := m.Called(teamChallenge)
+	return args.Error(0)
+}
+
+func (m *MockTeamRepository) GetTeamChallenges(teamID string) ([]teams.TeamChallenge, error) {
+	args := m.Called(teamID)
+	return args.Get(0).([]teams.TeamChallenge), args.Error(1)
+}
+
+func (m *MockTeamRepository) GetActiveTeamChallenges() ([]teams.TeamChallenge, error) {
+	args := m.Called()
+	return args.Get(0).([]teams.TeamChallenge), args.Error(1)
+}
+
+func (m *MockTeamRepository) CountTeamProgress(teamID, challengeID string, since time.Time) (int, error) {
+	args := m.Called(teamID, challengeID, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTeamRepository) UpdateTeamChallengeProgress(teamChallengeID string, progress int, status string) error {
+	args := m.Called(teamChallengeID, progress, status)
+	return args.Error(0)
+}
+
+func (m *MockTeamRepository) DistributeRewards(teamChallengeID, teamID string, exp, coin int) error {
+	args := m.Called(teamChallengeID, teamID, exp, coin)
+	return args.Error(0)
+}
+
+func (m *MockTeamRepository) GetTeamLeaderboard(limit int) ([]teams.TeamLeaderboard, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]teams.TeamLeaderboard), args.Error(1)
+}
+
+func TestCreateTeam_Success(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	mockTeamRepo.On("GetTeamByUserID", "user1").Return(teams.Team{}, constant.ErrTeamNotFound)
+	mockTeamRepo.On("CreateTeam", mock.MatchedBy(func(team teams.Team) bool {
+		return len(team.Members) == 1 && team.Members[0].UserID == "user1" && team.Members[0].Role == "Leader"
+	})).Return(nil)
+	mockTeamRepo.On("GetTeamByID", mock.Anything).Return(teams.Team{ID: "team1", Name: "Green Squad", LeaderID: "user1", MemberCount: 1}, nil)
+
+	team, err := service.CreateTeam(teams.Team{Name: "Green Squad", LeaderID: "user1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Green Squad", team.Name)
+	mockTeamRepo.AssertExpectations(t)
+}
+
+func TestCreateTeam_EmptyName(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	_, err := service.CreateTeam(teams.Team{LeaderID: "user1"})
+
+	assert.Equal(t, constant.ErrTeamField, err)
+	mockTeamRepo.AssertNotCalled(t, "CreateTeam", mock.Anything)
+}
+
+func TestCreateTeam_UserAlreadyInTeam(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	mockTeamRepo.On("GetTeamByUserID", "user1").Return(teams.Team{ID: "team1"}, nil)
+
+	_, err := service.CreateTeam(teams.Team{Name: "Green Squad", LeaderID: "user1"})
+
+	assert.Equal(t, constant.ErrUserAlreadyInTeam, err)
+	mockTeamRepo.AssertNotCalled(t, "CreateTeam", mock.Anything)
+}
+
+func TestJoinTeam_Success(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	mockTeamRepo.On("GetTeamByID", "team1").Return(teams.Team{ID: "team1"}, nil)
+	mockTeamRepo.On("GetTeamByUserID", "user2").Return(teams.Team{}, constant.ErrTeamNotFound)
+	mockTeamRepo.On("AddMember", mock.MatchedBy(func(member teams.TeamMember) bool {
+		return member.TeamID == "team1" && member.UserID == "user2" && member.Role == "Member"
+	}), teams.MaxTeamMembers).Return(nil)
+
+	err := service.JoinTeam("team1", "user2")
+
+	assert.NoError(t, err)
+	mockTeamRepo.AssertExpectations(t)
+}
+
+func TestJoinTeam_AlreadyInTeam(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	mockTeamRepo.On("GetTeamByID", "team1").Return(teams.Team{ID: "team1"}, nil)
+	mockTeamRepo.On("GetTeamByUserID", "user2").Return(teams.Team{ID: "team2"}, nil)
+
+	err := service.JoinTeam("team1", "user2")
+
+	assert.Equal(t, constant.ErrUserAlreadyInTeam, err)
+	mockTeamRepo.AssertNotCalled(t, "AddMember", mock.Anything, mock.Anything)
+}
+
+func TestJoinTeam_TeamFull(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	mockTeamRepo.On("GetTeamByID", "team1").Return(teams.Team{ID: "team1"}, nil)
+	mockTeamRepo.On("GetTeamByUserID", "user2").Return(teams.Team{}, constant.ErrTeamNotFound)
+	mockTeamRepo.On("AddMember", mock.Anything, teams.MaxTeamMembers).Return(constant.ErrTeamFull)
+
+	err := service.JoinTeam("team1", "user2")
+
+	assert.Equal(t, constant.ErrTeamFull, err)
+}
+
+func TestLeaveTeam_Member(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	mockTeamRepo.On("GetTeamByID", "team1").Return(teams.Team{ID: "team1", LeaderID: "user1"}, nil)
+	mockTeamRepo.On("IsMember", "team1", "user2").Return(true, nil)
+	mockTeamRepo.On("RemoveMember", "team1", "user2").Return(nil)
+
+	err := service.LeaveTeam("team1", "user2")
+
+	assert.NoError(t, err)
+	mockTeamRepo.AssertCalled(t, "RemoveMember", "team1", "user2")
+}
+
+func TestLeaveTeam_LeaderWithMembers(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	mockTeamRepo.On("GetTeamByID", "team1").Return(teams.Team{ID: "team1", LeaderID: "user1"}, nil)
+	mockTeamRepo.On("IsMember", "team1", "user1").Return(true, nil)
+	mockTeamRepo.On("CountMembers", "team1").Return(2, nil)
+
+	err := service.LeaveTeam("team1", "user1")
+
+	assert.Equal(t, constant.ErrTeamLeaderCannotLeave, err)
+	mockTeamRepo.AssertNotCalled(t, "DeleteTeam", mock.Anything)
+}
+
+func TestLeaveTeam_LastLeaderDeletesTeam(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	mockTeamRepo.On("GetTeamByID", "team1").Return(teams.Team{ID: "team1", LeaderID: "user1"}, nil)
+	mockTeamRepo.On("IsMember", "team1", "user1").Return(true, nil)
+	mockTeamRepo.On("CountMembers", "team1").Return(1, nil)
+	mockTeamRepo.On("DeleteTeam", "team1").Return(nil)
+
+	err := service.LeaveTeam("team1", "user1")
+
+	assert.NoError(t, err)
+	mockTeamRepo.AssertCalled(t, "DeleteTeam", "team1")
+}
+
+func TestEnrollChallenge_Success(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	mockTeamRepo.On("GetTeamByID", "team1").Return(teams.Team{ID: "team1", LeaderID: "user1"}, nil)
+	mockTeamRepo.On("GetChallengeGoal", "challenge1").Return(teams.ChallengeGoal{ChallengeID: "challenge1", TeamTarget: 50}, nil)
+	mockTeamRepo.On("IsChallengeEnrolled", "team1", "challenge1").Return(false, nil)
+	mockTeamRepo.On("CreateTeamChallenge", mock.MatchedBy(func(tc teams.TeamChallenge) bool {
+		return tc.TargetCount == 50 && tc.Status == "Progress"
+	})).Return(nil)
+
+	err := service.EnrollChallenge("team1", "challenge1", "user1")
+
+	assert.NoError(t, err)
+	mockTeamRepo.AssertExpectations(t)
+}
+
+func TestEnrollChallenge_NotLeader(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	mockTeamRepo.On("GetTeamByID", "team1").Return(teams.Team{ID: "team1", LeaderID: "user1"}, nil)
+
+	err := service.EnrollChallenge("team1", "challenge1", "user2")
+
+	assert.Equal(t, constant.ErrNotTeamLeader, err)
+}
+
+func TestEnrollChallenge_NotTeamChallenge(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	mockTeamRepo.On("GetTeamByID", "team1").Return(teams.Team{ID: "team1", LeaderID: "user1"}, nil)
+	mockTeamRepo.On("GetChallengeGoal", "challenge1").Return(teams.ChallengeGoal{ChallengeID: "challenge1"}, nil)
+
+	err := service.EnrollChallenge("team1", "challenge1", "user1")
+
+	assert.Equal(t, constant.ErrNotTeamChallenge, err)
+	mockTeamRepo.AssertNotCalled(t, "CreateTeamChallenge", mock.Anything)
+}
+
+func TestSyncActiveTeamChallenges_CompletesAndRewards(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	start := time.Now().Add(-48 * time.Hour)
+	mockTeamRepo.On("GetActiveTeamChallenges").Return([]teams.TeamChallenge{
+		{ID: "tc1", TeamID: "team1", ChallengeID: "challenge1", TargetCount: 10, Status: "Progress", StartDate: start},
+	}, nil)
+	mockTeamRepo.On("CountTeamProgress", "team1", "challenge1", start).Return(12, nil)
+	mockTeamRepo.On("UpdateTeamChallengeProgress", "tc1", 12, "Done").Return(nil)
+	mockTeamRepo.On("GetChallengeGoal", "challenge1").Return(teams.ChallengeGoal{Exp: 100, Coin: 50}, nil)
+	mockTeamRepo.On("DistributeRewards", "tc1", "team1", 100, 50).Return(nil)
+
+	err := service.SyncActiveTeamChallenges()
+
+	assert.NoError(t, err)
+	mockTeamRepo.AssertExpectations(t)
+}
+
+func TestSyncActiveTeamChallenges_StillInProgress(t *testing.T) {
+	mockTeamRepo := new(MockTeamRepository)
+	service := NewTeamService(mockTeamRepo)
+
+	start := time.Now().Add(-24 * time.Hour)
+	mockTeamRepo.On("GetActiveTeamChallenges").Return([]teams.TeamChallenge{
+		{ID: "tc1", TeamID: "team1", ChallengeID: "challenge1", TargetCount: 10, Status: "Progress", StartDate: start},
+	}, nil)
+	mockTeamRepo.On("CountTeamProgress", "team1", "challenge1", start).Return(4, nil)
+	mockTeamRepo.On("UpdateTeamChallengeProgress", "tc1", 4, "Progress").Return(nil)
+
+	err := service.SyncActiveTeamChallenges()
+
+	assert.NoError(t, err)
+	mockTeamRepo.AssertNotCalled(t, "DistributeRewards", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -9,6 +9,21 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// PaidStatuses are the Midtrans statuses of a transaction that was paid for.
+// Card payments are captured first and settled later, other payment methods
+// settle straight away.
+var PaidStatuses = []string{"capture", "settlement"}
+
+// IsPaid reports whether a transaction in the given status was paid for.
+func IsPaid(status string) bool {
+	for _, paid := range PaidStatuses {
+		if status == paid {
+			return true
+		}
+	}
+	return false
+}
+
 type Transaction struct {
 	ID            string
 	UserID        string
@@ -3,7 +3,10 @@ package service
 import (
 	"errors"
 	"greenenvironment/features/transactions"
+	"greenenvironment/helper"
+	"greenenvironment/utils/mail"
 	midtrasService "greenenvironment/utils/midtrans"
+	"log"
 
 	"github.com/google/uuid"
 	"github.com/midtrans/midtrans-go"
@@ -12,10 +15,11 @@ import (
 type TransactionService struct {
 	transactionRepo transactions.TransactionRepositoryInterface
 	midtransService midtrasService.PaymentGatewayInterface
+	mailer          helper.MailerInterface
 }
 
-func NewTransactionService(transactionRepo transactions.TransactionRepositoryInterface, midtrans midtrasService.PaymentGatewayInterface) transactions.TransactionServiceInterface {
-	return &TransactionService{transactionRepo: transactionRepo, midtransService: midtrans}
+func NewTransactionService(transactionRepo transactions.TransactionRepositoryInterface, midtrans midtrasService.PaymentGatewayInterface, mailer helper.MailerInterface) transactions.TransactionServiceInterface {
+	return &TransactionService{transactionRepo: transactionRepo, midtransService: midtrans, mailer: mailer}
 }
 
 func (ts *TransactionService) GetUserTransaction(userId string, page int) ([]transactions.TransactionData, int, int, error) {
@@ -52,6 +56,7 @@ func (ts *TransactionService) CreateTransaction(transaction transactions.CreateT
 	var totalPrice float64
 	items := []midtrans.ItemDetails{}
 	itemsData := []transactions.TransactionItems{}
+	orderItems := []mail.OrderItem{}
 
 	for _, cart := range cartData {
 		totalPrice += float64(int64(cart.Product.Price)) * float64(cart.Quantity)
@@ -73,6 +78,7 @@ func (ts *TransactionService) CreateTransaction(transaction transactions.CreateT
 		}
 		items = append(items, item)
 		itemsData = append(itemsData, itemData)
+		orderItems = append(orderItems, mail.OrderItem{Name: cart.Product.Name, Quantity: cart.Quantity})
 	}
 
 	transactionData.Total = totalPrice
@@ -128,6 +134,17 @@ func (ts *TransactionService) CreateTransaction(transaction transactions.CreateT
 		return transactions.Transaction{}, err
 	}
 
+	err = ts.mailer.SendTemplate(userData.Email, mail.TemplateOrderConfirmation, map[string]interface{}{
+		"Name":       userData.Name,
+		"OrderID":    transactionData.ID,
+		"Items":      orderItems,
+		"Total":      int64(transactionData.Total),
+		"PaymentURL": transactionData.SnapURL,
+	})
+	if err != nil {
+		log.Printf("failed to queue order confirmation for transaction %s: %v", transactionData.ID, err)
+	}
+
 	return transactionData, nil
 }
 func (ts *TransactionService) DeleteTransaction(transactionId string) error {
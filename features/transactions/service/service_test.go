@@ -7,6 +7,7 @@ import (
 	products "greenenvironment/features/products/repository"
 	"greenenvironment/features/transactions"
 	users "greenenvironment/features/users/repository"
+	"greenenvironment/utils/mail"
 	"greenenvironment/utils/midtrans"
 
 	"github.com/stretchr/testify/assert"
@@ -94,10 +95,35 @@ func (m *MockMidtransService) CancelTransaction(orderId string) error {
 	args := m.Called(orderId)
 	return args.Error(0)
 }
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(to string, code string, subject string) error {
+	args := m.Called(to, code, subject)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendNotification(to string, subject string, message string) error {
+	args := m.Called(to, subject, message)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendTemplate(to string, template string, data map[string]interface{}) error {
+	args := m.Called(to, template, data)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendTemplateOnce(to string, template string, key string, data map[string]interface{}) error {
+	args := m.Called(to, template, key, data)
+	return args.Error(0)
+}
+
 func TestGetUserTransaction(t *testing.T) {
 	mockRepo := new(MockTransactionRepo)
 	mockMidtrans := new(MockMidtransService)
-	service := NewTransactionService(mockRepo, mockMidtrans)
+	service := NewTransactionService(mockRepo, mockMidtrans, new(MockMailer))
 
 	mockRepo.On("GetUserTransaction", "user1", 1).Return([]transactions.TransactionData{}, 1, 1, nil)
 
@@ -113,9 +139,10 @@ func TestGetUserTransaction(t *testing.T) {
 func TestCreateTransaction(t *testing.T) {
 	mockRepo := new(MockTransactionRepo)
 	mockMidtrans := new(MockMidtransService)
-	service := NewTransactionService(mockRepo, mockMidtrans)
+	mockMailer := new(MockMailer)
+	service := NewTransactionService(mockRepo, mockMidtrans, mockMailer)
 
-	mockRepo.On("GetUserData", "user1").Return(users.User{Address: "address"}, nil)
+	mockRepo.On("GetUserData", "user1").Return(users.User{Name: "User One", Email: "user1@example.com", Address: "address"}, nil)
 	mockRepo.On("GetDataCartTransaction", []string{"cart1"}, "user1").Return([]cart.Cart{
 		{ID: "1", ProductID: "1", Product: products.Product{ID: "1", Price: 1000, Name: "product1"}, Quantity: 1},
 	}, nil)
@@ -124,6 +151,11 @@ func TestCreateTransaction(t *testing.T) {
 	mockRepo.On("CreateTransactionItems", mock.Anything).Return(nil)
 	mockMidtrans.On("InitializeClientMidtrans").Return()
 	mockMidtrans.On("CreateTransaction", mock.Anything).Return("snap_url")
+	mockMailer.On("SendTemplate", "user1@example.com", mail.TemplateOrderConfirmation, mock.MatchedBy(func(data map[string]interface{}) bool {
+		items := data["Items"].([]mail.OrderItem)
+		return data["Name"] == "User One" && data["Total"] == int64(1000) && data["PaymentURL"] == "snap_url" &&
+			len(items) == 1 && items[0] == mail.OrderItem{Name: "product1", Quantity: 1}
+	})).Return(nil)
 
 	transaction := transactions.CreateTransaction{
 		UserID: "user1",
@@ -137,12 +169,13 @@ func TestCreateTransaction(t *testing.T) {
 	assert.Equal(t, "snap_url", result.SnapURL)
 	mockRepo.AssertExpectations(t)
 	mockMidtrans.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
 }
 
 func TestDeleteTransaction(t *testing.T) {
 	mockRepo := new(MockTransactionRepo)
 	mockMidtrans := new(MockMidtransService)
-	service := NewTransactionService(mockRepo, mockMidtrans)
+	service := NewTransactionService(mockRepo, mockMidtrans, new(MockMailer))
 
 	mockRepo.On("GetTransactionByID", "transaction1").Return(transactions.TransactionData{}, nil)
 	mockRepo.On("DeleteTransaction", "transaction1").Return(nil)
@@ -156,7 +189,7 @@ func TestDeleteTransaction(t *testing.T) {
 func TestGetAllTransaction(t *testing.T) {
 	mockRepo := new(MockTransactionRepo)
 	mockMidtrans := new(MockMidtransService)
-	service := NewTransactionService(mockRepo, mockMidtrans)
+	service := NewTransactionService(mockRepo, mockMidtrans, new(MockMailer))
 
 	mockRepo.On("GetAllTransaction", 1).Return([]transactions.TransactionData{}, 1, 1, nil)
 
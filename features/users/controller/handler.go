@@ -245,11 +245,13 @@ func (h *UserHandler) UpdateUserInfo(c echo.Context) error {
 	}
 
 	user := users.UserUpdate{
-		ID:      userId.(string),
-		Name:    UserUpdateRequest.Name,
-		Address: UserUpdateRequest.Address,
-		Gender:  UserUpdateRequest.Gender,
-		Phone:   UserUpdateRequest.Phone,
+		ID:       userId.(string),
+		Name:     UserUpdateRequest.Name,
+		Address:  UserUpdateRequest.Address,
+		Gender:   UserUpdateRequest.Gender,
+		Phone:    UserUpdateRequest.Phone,
+		Timezone: UserUpdateRequest.Timezone,
+		Language: UserUpdateRequest.Language,
 	}
 
 	err = h.userService.UpdateUserInfo(user)
@@ -387,44 +389,11 @@ func (h *UserHandler) GetUserData(c echo.Context) error {
 	response.Exp = user.Exp
 	response.Is_Membership = user.Is_Membership
 	response.AvatarURL = user.AvatarURL
+	response.Timezone = user.Timezone
+	response.Language = user.Language
 	return c.JSON(http.StatusOK, helper.ObjectFormatResponse(true, constant.UserSuccessGetUser, response))
 }
 
-// Delete User
-// @Summary      Delete user account
-// @Description  Delete the authenticated user's account
-// @Tags         Users
-// @Accept       json
-// @Produce      json
-// @Param        Authorization  header    string  true  "Bearer token"
-// @Success      200            {object}  helper.Response{data=string}
-// @Failure      401            {object}  helper.Response{data=string} "Unauthorized"
-// @Failure      500            {object}  helper.Response{data=string} "Internal server error"
-// @Router       /users/delete [delete]
-func (h *UserHandler) Delete(c echo.Context) error {
-	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
-	if tokenString == "" {
-		helper.UnauthorizedError(c)
-	}
-
-	token, err := h.jwt.ValidateToken(tokenString)
-	if err != nil {
-		helper.UnauthorizedError(c)
-	}
-
-	userData := h.jwt.ExtractUserToken(token)
-	userId := userData[constant.JWT_ID]
-	var user users.User
-	user.ID = userId.(string)
-
-	err = h.userService.Delete(user)
-	if err != nil {
-		return c.JSON(helper.ConvertResponseCode(err), helper.FormatResponse(false, err.Error(), nil))
-	}
-
-	return c.JSON(http.StatusOK, helper.FormatResponse(true, constant.UserSuccessDelete, nil))
-}
-
 // Google Login
 // @Summary      Google Login
 // @Description  Redirect to Google's OAuth 2.0 authentication page
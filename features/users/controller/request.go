@@ -16,10 +16,12 @@ type UserLoginRequest struct {
 }
 
 type UserUpdateRequest struct {
-	Name    string `json:"name" validate:"required"`
-	Address string `json:"address" validate:"required"`
-	Gender  string `json:"gender" validate:"required"`
-	Phone   string `json:"phone" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+	Address  string `json:"address" validate:"required"`
+	Gender   string `json:"gender" validate:"required"`
+	Phone    string `json:"phone" validate:"required"`
+	Timezone string `json:"timezone"`
+	Language string `json:"language"`
 }
 
 type UserPasswordUpdateRequest struct {
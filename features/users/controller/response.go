@@ -30,6 +30,8 @@ type UserInfoResponse struct {
 	Exp           int    `json:"exp"`
 	Is_Membership bool   `json:"is_membership"`
 	AvatarURL     string `json:"avatar_url"`
+	Timezone      string `json:"timezone"`
+	Language      string `json:"language"`
 }
 
 type UserUpdateResponse struct {
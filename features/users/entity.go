@@ -19,6 +19,8 @@ type User struct {
 	Coin          int
 	AvatarURL     string
 	Is_Membership bool
+	Timezone      string
+	Language      string
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
@@ -39,6 +41,8 @@ type UserUpdate struct {
 	Gender    string
 	Phone     string
 	AvatarURL string
+	Timezone  string
+	Language  string
 	Token     string
 }
 
@@ -91,7 +95,6 @@ type UserRepoInterface interface {
 	DeleteVerifyOTPByEmail(email string) error
 	Login(User) (User, error)
 	UpdateUserInfo(user UserUpdate) (User, error)
-	Delete(User) error
 	IsEmailExist(email string) bool
 	GetUserByID(id string) (User, error)
 	GetUserByEmail(email string) (User, error)
@@ -120,7 +123,6 @@ type UserServiceInterface interface {
 	RegisterOrLoginGoogle(User) (User, error)
 	UpdateUserInfo(user UserUpdate) error
 	GetUserData(User) (User, error)
-	Delete(User) error
 	UpdateAvatar(userID, avatarURL string) error
 
 	RequestPasswordUpdateOTP(email string) error
@@ -144,7 +146,6 @@ type UserControllerInterface interface {
 	GoogleCallback(c echo.Context) error
 	UpdateUserInfo(c echo.Context) error
 	GetUserData(c echo.Context) error
-	Delete(c echo.Context) error
 	UpdateAvatar(c echo.Context) error
 
 	RequestPasswordUpdateOTP(c echo.Context) error
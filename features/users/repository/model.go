@@ -20,6 +20,8 @@ type User struct {
 	Coin         int    `gorm:"type:int;not null;column:coin"`
 	AvatarURL    string `gorm:"type:varchar(255);column:avatar_url"`
 	IsMembership bool   `gorm:"type:boolean;column:is_membership;default:false"`
+	Timezone     string `gorm:"type:varchar(64);not null;column:timezone;default:'Asia/Jakarta'"`
+	Language     string `gorm:"type:varchar(5);not null;column:language;default:'en'"`
 }
 
 type VerifyOTP struct {
@@ -124,12 +124,20 @@ func (u *UserData) Login(user users.User) (users.User, error) {
 }
 
 func (u *UserData) UpdateUserInfo(user users.UserUpdate) (users.User, error) {
-	err := u.DB.Model(&users.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+	updates := map[string]interface{}{
 		"name":    user.Name,
 		"address": user.Address,
 		"gender":  user.Gender,
 		"phone":   user.Phone,
-	}).Error
+	}
+	if user.Timezone != "" {
+		updates["timezone"] = user.Timezone
+	}
+	if user.Language != "" {
+		updates["language"] = user.Language
+	}
+
+	err := u.DB.Model(&users.User{}).Where("id = ?", user.ID).Updates(updates).Error
 	if err != nil {
 		return users.User{}, constant.ErrUpdateUser
 	}
@@ -137,17 +145,6 @@ func (u *UserData) UpdateUserInfo(user users.UserUpdate) (users.User, error) {
 	return u.GetUserByID(user.ID)
 }
 
-func (u *UserData) Delete(user users.User) error {
-	_, err := u.GetUserByID(user.ID)
-	if err != nil {
-		return err
-	}
-	if err := u.DB.Where("id = ?", user.ID).Delete(&user).Error; err != nil {
-		return constant.ErrDeleteUser
-	}
-	return nil
-}
-
 func (u *UserData) IsUsernameExist(username string) bool {
 	var user User
 	if err := u.DB.Where("username = ?", username).First(&user).Error; err != nil {
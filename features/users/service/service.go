@@ -4,7 +4,10 @@ import (
 	"greenenvironment/constant"
 	"greenenvironment/features/users"
 	"greenenvironment/helper"
+	"greenenvironment/utils/mail"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -106,6 +109,13 @@ func (s *UserService) VerifyRegisterOTP(otp string) (users.User, error) {
 		return users.User{}, err
 	}
 
+	err = s.mailer.SendTemplate(newUser.Email, mail.TemplateWelcome, map[string]interface{}{
+		"Name": newUser.Name,
+	})
+	if err != nil {
+		log.Printf("failed to queue welcome email to %s: %v", newUser.Email, err)
+	}
+
 	return createdUser, nil
 }
 
@@ -167,9 +177,22 @@ func (s *UserService) ResetPassword(newPassword string) error {
 		return err
 	}
 
+	s.sendPasswordChanged(email, "")
 	return nil
 }
 
+// sendPasswordChanged warns the owner of an account about a password change.
+// The password is already changed, so a failure is only logged.
+func (s *UserService) sendPasswordChanged(email, name string) {
+	err := s.mailer.SendTemplate(email, mail.TemplatePasswordChanged, map[string]interface{}{
+		"Name":      name,
+		"ChangedAt": time.Now().UTC().Format("2 January 2006 15:04 UTC"),
+	})
+	if err != nil {
+		log.Printf("failed to queue password changed email to %s: %v", email, err)
+	}
+}
+
 func (s *UserService) Login(user users.User) (users.UserLogin, error) {
 	user.Email = strings.ToLower(user.Email)
 
@@ -211,6 +234,14 @@ func (s *UserService) UpdateUserInfo(user users.UserUpdate) error {
 		user.Phone = trimmedPhone
 	}
 
+	if user.Timezone != "" && !helper.ValidateTimezone(user.Timezone) {
+		return constant.ErrInvalidTimezone
+	}
+
+	if user.Language != "" && !mail.IsSupportedLanguage(user.Language) {
+		return constant.ErrInvalidLanguage
+	}
+
 	_, err := s.userRepo.UpdateUserInfo(user)
 	if err != nil {
 		return err
@@ -278,6 +309,7 @@ func (s *UserService) UpdatePassword(update users.PasswordUpdate) error {
 		return err
 	}
 
+	s.sendPasswordChanged(update.Email, existingUser.Name)
 	return nil
 }
 
@@ -285,13 +317,6 @@ func (s *UserService) GetUserData(user users.User) (users.User, error) {
 	return s.userRepo.GetUserByID(user.ID)
 }
 
-func (s *UserService) Delete(user users.User) error {
-	if user.ID == "" {
-		return constant.ErrDeleteUser
-	}
-	return s.userRepo.Delete(user)
-}
-
 func (s *UserService) RegisterOrLoginGoogle(user users.User) (users.User, error) {
 	existingUser, err := s.userRepo.GetUserByEmail(user.Email)
 	if err != nil && err != gorm.ErrRecordNotFound {
@@ -5,6 +5,7 @@ import (
 	"greenenvironment/constant"
 	"greenenvironment/features/users"
 	"greenenvironment/helper"
+	"greenenvironment/utils/mail"
 	"testing"
 	"time"
 
@@ -73,11 +74,6 @@ func (m *MockUserData) UpdateUserInfo(user users.UserUpdate) (users.User, error)
 	return args.Get(0).(users.User), args.Error(1)
 }
 
-func (m *MockUserData) Delete(user users.User) error {
-	args := m.Called(user)
-	return args.Error(0)
-}
-
 func (m *MockUserData) IsUsernameExist(username string) bool {
 	args := m.Called(username)
 	return args.Bool(0)
@@ -209,6 +205,21 @@ func (m *MockMailerInterface) Send(email, otpCode, subject string) error {
 	return args.Error(0)
 }
 
+func (m *MockMailerInterface) SendNotification(email, subject, message string) error {
+	args := m.Called(email, subject, message)
+	return args.Error(0)
+}
+
+func (m *MockMailerInterface) SendTemplate(to string, template string, data map[string]interface{}) error {
+	args := m.Called(to, template, data)
+	return args.Error(0)
+}
+
+func (m *MockMailerInterface) SendTemplateOnce(to string, template string, key string, data map[string]interface{}) error {
+	args := m.Called(to, template, key, data)
+	return args.Error(0)
+}
+
 type MockOTPInterface struct {
 	mock.Mock
 }
@@ -291,9 +302,10 @@ func TestRequestRegisterOTP(t *testing.T) {
 
 func TestVerifyRegisterOTP(t *testing.T) {
 	mockRepo := new(MockUserData)
+	mockMailer := new(MockMailerInterface)
 	mockOTP := new(MockOTPInterface)
 
-	svc := NewUserService(mockRepo, nil, nil, mockOTP)
+	svc := NewUserService(mockRepo, nil, mockMailer, mockOTP)
 
 	t.Run("success", func(t *testing.T) {
 		mockRepo.On("GetVerifyOTP", "123456").Return(users.VerifyOTP{Email: "test@example.com"}, nil).Once()
@@ -312,12 +324,33 @@ func TestVerifyRegisterOTP(t *testing.T) {
 		}, nil).Once()
 		mockRepo.On("DeleteTemporaryUserByEmail", "test@example.com").Return(nil).Once()
 		mockRepo.On("DeleteVerifyOTP", "123456").Return(nil).Once()
+		mockMailer.On("SendTemplate", "test@example.com", mail.TemplateWelcome, map[string]interface{}{
+			"Name": "Test User",
+		}).Return(nil).Once()
 
 		result, err := svc.VerifyRegisterOTP("123456")
 		assert.NoError(t, err)
 		assert.Equal(t, "test@example.com", result.Email)
 
 		mockRepo.AssertExpectations(t)
+		mockMailer.AssertExpectations(t)
+	})
+
+	t.Run("welcome email failure does not fail registration", func(t *testing.T) {
+		mockRepo.On("GetVerifyOTP", "654321").Return(users.VerifyOTP{Email: "new@example.com"}, nil).Once()
+		mockRepo.On("GetTemporaryUserByEmail", "new@example.com").Return(users.TemporaryUser{
+			ID:    "2",
+			Name:  "New User",
+			Email: "new@example.com",
+		}, nil).Once()
+		mockRepo.On("Register", mock.Anything).Return(users.User{ID: "2", Email: "new@example.com"}, nil).Once()
+		mockRepo.On("DeleteTemporaryUserByEmail", "new@example.com").Return(nil).Once()
+		mockRepo.On("DeleteVerifyOTP", "654321").Return(nil).Once()
+		mockMailer.On("SendTemplate", "new@example.com", mail.TemplateWelcome, mock.Anything).Return(errors.New("smtp down")).Once()
+
+		result, err := svc.VerifyRegisterOTP("654321")
+		assert.NoError(t, err)
+		assert.Equal(t, "2", result.ID)
 	})
 
 	t.Run("error invalid input", func(t *testing.T) {
@@ -620,6 +653,48 @@ func TestUserService_UpdateUserInfo(t *testing.T) {
 		assert.Error(t, err)
 		assert.Equal(t, constant.ErrInvalidPhone, err)
 	})
+
+	t.Run("invalid timezone", func(t *testing.T) {
+		mockUserUpdate.Phone = "08123456789"
+		mockUserUpdate.Timezone = "Mars/Olympus_Mons"
+
+		err := service.UpdateUserInfo(mockUserUpdate)
+
+		assert.Equal(t, constant.ErrInvalidTimezone, err)
+		mockUserRepo.AssertNotCalled(t, "UpdateUserInfo", mock.MatchedBy(func(user users.UserUpdate) bool {
+			return user.Timezone == "Mars/Olympus_Mons"
+		}))
+	})
+
+	t.Run("valid timezone", func(t *testing.T) {
+		mockUserUpdate.Timezone = "Asia/Makassar"
+		mockUserRepo.On("UpdateUserInfo", mock.MatchedBy(func(user users.UserUpdate) bool {
+			return user.Timezone == "Asia/Makassar"
+		})).Return(users.User{}, nil).Once()
+
+		err := service.UpdateUserInfo(mockUserUpdate)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("unsupported language", func(t *testing.T) {
+		mockUserUpdate.Language = "fr"
+
+		err := service.UpdateUserInfo(mockUserUpdate)
+
+		assert.Equal(t, constant.ErrInvalidLanguage, err)
+	})
+
+	t.Run("supported language", func(t *testing.T) {
+		mockUserUpdate.Language = "id"
+		mockUserRepo.On("UpdateUserInfo", mock.MatchedBy(func(user users.UserUpdate) bool {
+			return user.Language == "id"
+		})).Return(users.User{}, nil).Once()
+
+		err := service.UpdateUserInfo(mockUserUpdate)
+
+		assert.NoError(t, err)
+	})
 }
 
 func TestUserService_RequestPasswordUpdateOTP(t *testing.T) {
@@ -717,35 +792,6 @@ func TestUserService_GetUserData(t *testing.T) {
 	})
 }
 
-func TestUserService_Delete(t *testing.T) {
-	mockUserRepo := new(MockUserData)
-	service := &UserService{
-		userRepo: mockUserRepo,
-	}
-
-	mockUser := users.User{
-		ID: "1",
-	}
-
-	t.Run("success", func(t *testing.T) {
-		mockUserRepo.On("Delete", mockUser).Return(nil).Once()
-
-		err := service.Delete(mockUser)
-
-		assert.NoError(t, err)
-		mockUserRepo.AssertExpectations(t)
-	})
-
-	t.Run("missing user ID", func(t *testing.T) {
-		mockUser.ID = ""
-
-		err := service.Delete(mockUser)
-
-		assert.Error(t, err)
-		assert.Equal(t, constant.ErrDeleteUser, err)
-	})
-}
-
 func TestUserService_GetUserByIDForAdmin(t *testing.T) {
 	mockUserRepo := new(MockUserData)
 	service := &UserService{
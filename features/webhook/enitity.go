@@ -20,6 +20,13 @@ type PaymentNotification struct {
 	SettlementTime    string `json:"settlement_time,omitempty"`
 }
 
+// TransactionBuyer is the user a transaction belongs to.
+type TransactionBuyer struct {
+	UserID string
+	Name   string
+	Email  string
+}
+
 type MidtransNotificationController interface {
 	HandleNotification(c echo.Context) error
 }
@@ -29,7 +36,7 @@ type MidtransNotificationService interface {
 }
 
 type MidtransNotificationRepository interface {
-	HandleNotification(notification PaymentNotification, transaction transactions.Transaction) error
-	InsertUserCoin(transactionID string) error
+	HandleNotification(notification PaymentNotification, transaction transactions.Transaction) (firstPaid bool, err error)
 	UpdateStockFailedTransaction(transactionId string) error
+	GetTransactionBuyer(transactionID string) (TransactionBuyer, error)
 }
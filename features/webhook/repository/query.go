@@ -1,14 +1,18 @@
 package repository
 
 import (
+	impactData "greenenvironment/features/impacts/repository"
 	productData "greenenvironment/features/products/repository"
 	transactionsEntity "greenenvironment/features/transactions"
 	transactionsData "greenenvironment/features/transactions/repository"
 	userData "greenenvironment/features/users/repository"
 	"greenenvironment/features/webhook"
 
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type WebhookRepository struct {
@@ -21,7 +25,9 @@ func NewWebhookRepository(db *gorm.DB) webhook.MidtransNotificationRepository {
 	}
 }
 
-func (w *WebhookRepository) HandleNotification(notification webhook.PaymentNotification, transaction transactionsData.Transaction) error {
+// HandleNotification reports whether the notification moved the transaction
+// to a paid status for the first time.
+func (w *WebhookRepository) HandleNotification(notification webhook.PaymentNotification, transaction transactionsData.Transaction) (bool, error) {
 	transactionUpdate := transactionsEntity.UpdateTransaction{
 		ID:            transaction.ID,
 		Status:        transaction.Status,
@@ -42,62 +48,124 @@ func (w *WebhookRepository) HandleNotification(notification webhook.PaymentNotif
 		Currency:          notification.Currency,
 		SettlementTime:    notification.SettlementTime,
 	}
-	tx := w.DB.Begin()
 
-	err := w.DB.Model(&transactionsData.Transaction{}).Where("id = ?", transaction.ID).Updates(&transactionUpdate).Error
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-	err = w.DB.Model(&PaymentNotification{}).Create(&paymentNotif).Error
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-	if notification.TransactionStatus == "settlement" {
-		err = w.InsertUserCoin(transaction.ID)
+	firstPaid := false
+	err := w.DB.Transaction(func(tx *gorm.DB) error {
+		// The buyer is credited when the transaction first becomes paid, so a
+		// capture followed by its settlement, or a resent notification, only
+		// credits once.
+		var previous []string
+		err := tx.Model(&transactionsData.Transaction{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", transaction.ID).
+			Pluck("status", &previous).Error
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
-	}
+		wasPaid := len(previous) > 0 && transactionsEntity.IsPaid(previous[0])
 
-	return tx.Commit().Error
-}
+		err = tx.Model(&transactionsData.Transaction{}).Where("id = ?", transaction.ID).Updates(&transactionUpdate).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&PaymentNotification{}).Create(&paymentNotif).Error
+		if err != nil {
+			return err
+		}
 
-func (w *WebhookRepository) InsertUserCoin(transactionId string) error {
-	var transaction transactionsData.Transaction
-	err := w.DB.Where("id = ?", transactionId).First(&transaction).Error
+		if !transactionsEntity.IsPaid(transaction.Status) || wasPaid {
+			return nil
+		}
+		if err := insertUserCoin(tx, transaction.ID); err != nil {
+			return err
+		}
+		if err := insertUserImpact(tx, transaction.ID); err != nil {
+			return err
+		}
+		firstPaid = true
+		return nil
+	})
 	if err != nil {
-		return err
+		return false, err
 	}
-	var user userData.User
-	err = w.DB.Where("id = ?", transaction.UserID).First(&user).Error
+	return firstPaid, nil
+}
+
+// insertUserCoin credits the buyer with the coins of the purchased
+// products, times the quantity.
+func insertUserCoin(tx *gorm.DB, transactionId string) error {
+	var transaction transactionsData.Transaction
+	err := tx.Where("id = ?", transactionId).First(&transaction).Error
 	if err != nil {
 		return err
 	}
 	var transactionItem []transactionsData.TransactionItem
-	err = w.DB.Where("transaction_id = ?", transaction.ID).Find(&transactionItem).Error
+	err = tx.Where("transaction_id = ?", transaction.ID).Find(&transactionItem).Error
 	if err != nil {
 		return err
 	}
 	var totalCoinxQty int
 	for _, item := range transactionItem {
 		var product productData.Product
-		err = w.DB.Where("id = ?", item.ProductID).First(&product).Error
+		err = tx.Where("id = ?", item.ProductID).First(&product).Error
 		totalCoinxQty += product.Coin * item.Quantity
 		if err != nil {
 			return err
 		}
 	}
-	userUpdate := userData.User{
-		Coin: user.Coin + totalCoinxQty,
+
+	result := tx.Model(&userData.User{}).Where("id = ?", transaction.UserID).Update("coin", gorm.Expr("coin + ?", totalCoinxQty))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// insertUserImpact credits the buyer's impact ledger with the impact points
+// of every category linked to the purchased products, times the quantity.
+func insertUserImpact(tx *gorm.DB, transactionId string) error {
+	var transaction transactionsData.Transaction
+	err := tx.Where("id = ?", transactionId).First(&transaction).Error
+	if err != nil {
+		return err
 	}
 
-	err = w.DB.Model(&userData.User{}).Where("id = ?", user.ID).Updates(&userUpdate).Error
+	var rows []struct {
+		ImpactCategoryID string
+		Points           int
+	}
+	err = tx.Table("transaction_items").
+		Select("product_impact_categories.impact_category_id, SUM(transaction_items.quantity * impact_categories.impact_point) AS points").
+		Joins("JOIN product_impact_categories ON product_impact_categories.product_id = transaction_items.product_id AND product_impact_categories.deleted_at IS NULL").
+		Joins("JOIN impact_categories ON impact_categories.id = product_impact_categories.impact_category_id").
+		Where("transaction_items.transaction_id = ? AND transaction_items.deleted_at IS NULL", transaction.ID).
+		Group("product_impact_categories.impact_category_id").
+		Scan(&rows).Error
 	if err != nil {
 		return err
 	}
+
+	for _, row := range rows {
+		entry := impactData.ImpactLedger{
+			ID:               uuid.New().String(),
+			UserID:           transaction.UserID,
+			ImpactCategoryID: row.ImpactCategoryID,
+			SourceType:       "purchase",
+			SourceID:         transaction.ID,
+			Points:           row.Points,
+			OccurredAt:       time.Now(),
+		}
+		// The ledger keeps the first entry of a purchase should it ever be
+		// credited twice.
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entry).Error
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -117,3 +185,16 @@ func (w *WebhookRepository) UpdateStockFailedTransaction(transactionId string) e
 
 	return nil
 }
+
+func (w *WebhookRepository) GetTransactionBuyer(transactionID string) (webhook.TransactionBuyer, error) {
+	var buyer webhook.TransactionBuyer
+	err := w.DB.Model(&transactionsData.Transaction{}).
+		Select("transactions.user_id, users.name, users.email").
+		Joins("JOIN users ON users.id = transactions.user_id").
+		Where("transactions.id = ?", transactionID).
+		Take(&buyer).Error
+	if err != nil {
+		return webhook.TransactionBuyer{}, err
+	}
+	return buyer, nil
+}
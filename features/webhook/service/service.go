@@ -1,17 +1,29 @@
 package service
 
 import (
+	"greenenvironment/features/notifications"
+	transactionsEntity "greenenvironment/features/transactions"
 	transactions "greenenvironment/features/transactions/repository"
 	"greenenvironment/features/webhook"
+	"greenenvironment/helper"
+	"greenenvironment/utils/mail"
+	"greenenvironment/utils/realtime"
+	"log"
 )
 
 type WebhookService struct {
-	d webhook.MidtransNotificationRepository
+	d         webhook.MidtransNotificationRepository
+	notifier  notifications.Notifier
+	publisher realtime.Publisher
+	mailer    helper.MailerInterface
 }
 
-func NewWebhookService(data webhook.MidtransNotificationRepository) webhook.MidtransNotificationService {
+func NewWebhookService(data webhook.MidtransNotificationRepository, notifier notifications.Notifier, publisher realtime.Publisher, mailer helper.MailerInterface) webhook.MidtransNotificationService {
 	return &WebhookService{
-		d: data,
+		d:         data,
+		notifier:  notifier,
+		publisher: publisher,
+		mailer:    mailer,
 	}
 }
 
@@ -37,5 +49,80 @@ func (s *WebhookService) HandleNotification(notification webhook.PaymentNotifica
 	} else if transactionStatus == "pending" {
 		transactionData.Status = transactionStatus
 	}
-	return s.d.HandleNotification(notification, transactionData)
+	firstPaid, err := s.d.HandleNotification(notification, transactionData)
+	if err != nil {
+		return err
+	}
+
+	s.notifyBuyer(transactionData, firstPaid)
+	return nil
+}
+
+// notifyBuyer pushes the new status of a transaction to the buyer's open
+// streams, notifies them and emails a receipt once the payment is in.
+// A capture followed by its settlement, or a resent notification, is only
+// announced when the transaction first becomes paid. Midtrans only retries
+// on an error response, so a failed notification is logged rather than
+// returned.
+func (s *WebhookService) notifyBuyer(transaction transactions.Transaction, firstPaid bool) {
+	if transaction.Status == "" {
+		return
+	}
+	if transactionsEntity.IsPaid(transaction.Status) && !firstPaid {
+		return
+	}
+
+	buyer, err := s.d.GetTransactionBuyer(transaction.ID)
+	if err != nil {
+		log.Printf("failed to find the buyer of transaction %s: %v", transaction.ID, err)
+		return
+	}
+	userID := buyer.UserID
+
+	s.publisher.Publish(realtime.UserTopic(userID), realtime.Event{
+		Type: realtime.EventTransactionStatus,
+		Data: map[string]interface{}{
+			"transaction_id": transaction.ID,
+			"status":         transaction.Status,
+			"payment_method": transaction.PaymentMethod,
+		},
+	})
+
+	var title, message string
+	switch transaction.Status {
+	case "capture", "settlement":
+		title = "Payment received"
+		message = "Your payment for order " + transaction.ID + " has been received. Thank you for shopping at Ecomate!"
+	case "pending":
+		title = "Waiting for payment"
+		message = "Order " + transaction.ID + " is waiting for your payment."
+	case "cancel", "deny", "expire":
+		title = "Payment failed"
+		message = "The payment for order " + transaction.ID + " was not completed (" + transaction.Status + ")."
+	default:
+		return
+	}
+
+	err = s.notifier.Notify(notifications.Notification{
+		UserID:  userID,
+		Type:    notifications.TypeTransaction,
+		Title:   title,
+		Message: message,
+		Link:    "/transactions/" + transaction.ID,
+	})
+	if err != nil {
+		log.Printf("failed to notify %s about transaction %s: %v", userID, transaction.ID, err)
+	}
+
+	if !transactionsEntity.IsPaid(transaction.Status) {
+		return
+	}
+	err = s.mailer.SendTemplateOnce(buyer.Email, mail.TemplatePaymentReceived, mail.TemplatePaymentReceived+":"+transaction.ID, map[string]interface{}{
+		"Name":          buyer.Name,
+		"OrderID":       transaction.ID,
+		"PaymentMethod": transaction.PaymentMethod,
+	})
+	if err != nil {
+		log.Printf("failed to queue payment receipt for transaction %s: %v", transaction.ID, err)
+	}
 }
@@ -3,8 +3,12 @@ package service
 import (
 	"testing"
 
+	"greenenvironment/features/notifications"
+	transactionsEntity "greenenvironment/features/transactions"
 	"greenenvironment/features/transactions/repository"
 	"greenenvironment/features/webhook"
+	"greenenvironment/utils/mail"
+	"greenenvironment/utils/realtime"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -14,9 +18,9 @@ type MockMidtransNotificationRepository struct {
 	mock.Mock
 }
 
-func (m *MockMidtransNotificationRepository) HandleNotification(notification webhook.PaymentNotification, transactionData repository.Transaction) error {
+func (m *MockMidtransNotificationRepository) HandleNotification(notification webhook.PaymentNotification, transactionData repository.Transaction) (bool, error) {
 	args := m.Called(notification, transactionData)
-	return args.Error(0)
+	return args.Bool(0), args.Error(1)
 }
 
 func (m *MockMidtransNotificationRepository) UpdateStockFailedTransaction(orderID string) error {
@@ -24,13 +28,60 @@ func (m *MockMidtransNotificationRepository) UpdateStockFailedTransaction(orderI
 	return args.Error(0)
 }
 
-func (m *MockMidtransNotificationRepository) InsertUserCoin(transactionID string) error {
+func (m *MockMidtransNotificationRepository) GetTransactionBuyer(transactionID string) (webhook.TransactionBuyer, error) {
 	args := m.Called(transactionID)
+	return args.Get(0).(webhook.TransactionBuyer), args.Error(1)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(notification notifications.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+type MockPublisher struct {
+	mock.Mock
+}
+
+func (m *MockPublisher) Publish(topic string, event realtime.Event) {
+	m.Called(topic, event)
+}
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(to string, code string, subject string) error {
+	args := m.Called(to, code, subject)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendNotification(to string, subject string, message string) error {
+	args := m.Called(to, subject, message)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendTemplate(to string, template string, data map[string]interface{}) error {
+	args := m.Called(to, template, data)
+	return args.Error(0)
+}
+
+func (m *MockMailer) SendTemplateOnce(to string, template string, key string, data map[string]interface{}) error {
+	args := m.Called(to, template, key, data)
 	return args.Error(0)
 }
+
+var buyer = webhook.TransactionBuyer{UserID: "user-1", Name: "Buyer", Email: "buyer@example.com"}
+
 func TestHandleNotification(t *testing.T) {
 	mockRepo := new(MockMidtransNotificationRepository)
-	service := NewWebhookService(mockRepo)
+	mockNotifier := new(MockNotifier)
+	mockPublisher := new(MockPublisher)
+	mockMailer := new(MockMailer)
+	service := NewWebhookService(mockRepo, mockNotifier, mockPublisher, mockMailer)
 
 	tests := []struct {
 		name           string
@@ -105,7 +156,26 @@ func TestHandleNotification(t *testing.T) {
 			}
 
 			// Always expect HandleNotification to be called
-			mockRepo.On("HandleNotification", tt.notification, transactionData).Return(tt.mockError).Once()
+			mockRepo.On("HandleNotification", tt.notification, transactionData).Return(transactionsEntity.IsPaid(tt.expectedStatus), tt.mockError).Once()
+			mockRepo.On("GetTransactionBuyer", tt.notification.OrderID).Return(buyer, nil).Once()
+			mockPublisher.On("Publish", "user:user-1", realtime.Event{
+				Type: realtime.EventTransactionStatus,
+				Data: map[string]interface{}{
+					"transaction_id": tt.notification.OrderID,
+					"status":         tt.expectedStatus,
+					"payment_method": tt.notification.PaymentType,
+				},
+			}).Return().Once()
+			mockNotifier.On("Notify", mock.MatchedBy(func(n notifications.Notification) bool {
+				return n.UserID == "user-1" && n.Type == notifications.TypeTransaction && n.Link == "/transactions/"+tt.notification.OrderID
+			})).Return(nil).Once()
+			if tt.expectedStatus == "capture" || tt.expectedStatus == "settlement" {
+				mockMailer.On("SendTemplateOnce", "buyer@example.com", mail.TemplatePaymentReceived, "payment_received:"+tt.notification.OrderID, map[string]interface{}{
+					"Name":          "Buyer",
+					"OrderID":       tt.notification.OrderID,
+					"PaymentMethod": tt.notification.PaymentType,
+				}).Return(nil).Once()
+			}
 
 			// Call the function under test
 			err := service.HandleNotification(tt.notification)
@@ -119,6 +189,105 @@ func TestHandleNotification(t *testing.T) {
 
 			// Assert that all expectations were met
 			mockRepo.AssertExpectations(t)
+			mockMailer.AssertExpectations(t)
 		})
 	}
 }
+
+func TestHandleNotification_NotifyFailureIsIgnored(t *testing.T) {
+	mockRepo := new(MockMidtransNotificationRepository)
+	mockNotifier := new(MockNotifier)
+	mockPublisher := new(MockPublisher)
+	mockMailer := new(MockMailer)
+	service := NewWebhookService(mockRepo, mockNotifier, mockPublisher, mockMailer)
+
+	notification := webhook.PaymentNotification{
+		TransactionStatus: "settlement",
+		OrderID:           "order200",
+		PaymentType:       "bank_transfer",
+	}
+	mockRepo.On("HandleNotification", notification, repository.Transaction{
+		ID:            "order200",
+		PaymentMethod: "bank_transfer",
+		Status:        "settlement",
+	}).Return(true, nil)
+	mockRepo.On("GetTransactionBuyer", "order200").Return(buyer, nil)
+	mockPublisher.On("Publish", "user:user-1", mock.Anything).Return()
+	mockNotifier.On("Notify", mock.Anything).Return(assert.AnError)
+	mockMailer.On("SendTemplateOnce", "buyer@example.com", mail.TemplatePaymentReceived, "payment_received:order200", mock.Anything).Return(assert.AnError)
+
+	err := service.HandleNotification(notification)
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+	mockMailer.AssertExpectations(t)
+}
+
+func TestHandleNotification_UnacceptedCaptureIsNotNotified(t *testing.T) {
+	mockRepo := new(MockMidtransNotificationRepository)
+	mockNotifier := new(MockNotifier)
+	mockPublisher := new(MockPublisher)
+	mockMailer := new(MockMailer)
+	service := NewWebhookService(mockRepo, mockNotifier, mockPublisher, mockMailer)
+
+	notification := webhook.PaymentNotification{
+		TransactionStatus: "capture",
+		FraudStatus:       "challenge",
+		OrderID:           "order201",
+		PaymentType:       "credit_card",
+	}
+	mockRepo.On("HandleNotification", notification, repository.Transaction{
+		ID:            "order201",
+		PaymentMethod: "credit_card",
+	}).Return(false, nil)
+
+	err := service.HandleNotification(notification)
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "GetTransactionBuyer", mock.Anything)
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	mockNotifier.AssertNotCalled(t, "Notify", mock.Anything)
+	mockMailer.AssertNotCalled(t, "SendTemplateOnce", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandleNotification_CaptureThenSettlementSendsOneReceipt(t *testing.T) {
+	mockRepo := new(MockMidtransNotificationRepository)
+	mockNotifier := new(MockNotifier)
+	mockPublisher := new(MockPublisher)
+	mockMailer := new(MockMailer)
+	service := NewWebhookService(mockRepo, mockNotifier, mockPublisher, mockMailer)
+
+	capture := webhook.PaymentNotification{
+		TransactionStatus: "capture",
+		FraudStatus:       "accept",
+		OrderID:           "order202",
+		PaymentType:       "credit_card",
+	}
+	settlement := webhook.PaymentNotification{
+		TransactionStatus: "settlement",
+		OrderID:           "order202",
+		PaymentType:       "credit_card",
+	}
+	mockRepo.On("HandleNotification", capture, repository.Transaction{
+		ID:            "order202",
+		PaymentMethod: "credit_card",
+		Status:        "capture",
+	}).Return(true, nil).Once()
+	mockRepo.On("HandleNotification", settlement, repository.Transaction{
+		ID:            "order202",
+		PaymentMethod: "credit_card",
+		Status:        "settlement",
+	}).Return(false, nil).Once()
+	mockRepo.On("GetTransactionBuyer", "order202").Return(buyer, nil).Once()
+	mockPublisher.On("Publish", "user:user-1", mock.Anything).Return().Once()
+	mockNotifier.On("Notify", mock.Anything).Return(nil).Once()
+	mockMailer.On("SendTemplateOnce", "buyer@example.com", mail.TemplatePaymentReceived, "payment_received:order202", mock.Anything).Return(nil).Once()
+
+	assert.NoError(t, service.HandleNotification(capture))
+	assert.NoError(t, service.HandleNotification(settlement))
+
+	mockRepo.AssertExpectations(t)
+	mockPublisher.AssertNumberOfCalls(t, "Publish", 1)
+	mockNotifier.AssertNumberOfCalls(t, "Notify", 1)
+	mockMailer.AssertNumberOfCalls(t, "SendTemplateOnce", 1)
+}
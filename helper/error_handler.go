@@ -72,6 +72,206 @@ func ConvertResponseCode(err error) int {
 		return http.StatusBadRequest
 	case constant.ErrInvalidEmail:
 		return http.StatusBadRequest
+	case constant.ErrInvalidTimezone:
+		return http.StatusBadRequest
+	case constant.ErrInvalidLanguage:
+		return http.StatusBadRequest
+
+	// Impact Error
+	case constant.ErrInvalidConversionFactor:
+		return http.StatusBadRequest
+	case constant.ErrImpactCategoryInUse:
+		return http.StatusConflict
+	case constant.ErrInvalidImpactCategoryParent:
+		return http.StatusBadRequest
+	case constant.ErrInvalidImpactCategoryColor:
+		return http.StatusBadRequest
+	case constant.ErrInvalidReassignTarget:
+		return http.StatusBadRequest
+
+	// Challenge Error
+	case constant.ErrChallengeNotCompleted:
+		return http.StatusBadRequest
+	case constant.ErrChallengeConfirmationAlreadySubmitted:
+		return http.StatusConflict
+	case constant.ErrChallengeTaskDeadlinePassed:
+		return http.StatusBadRequest
+	case constant.ErrChallengeConfirmationNotFound:
+		return http.StatusNotFound
+	case constant.ErrChallengeConfirmationNotPending:
+		return http.StatusConflict
+	case constant.ErrInvalidReviewAction:
+		return http.StatusBadRequest
+	case constant.ErrRejectionReasonRequired:
+		return http.StatusBadRequest
+	case constant.ErrChallengeNotAvailable:
+		return http.StatusBadRequest
+	case constant.ErrChallengeEnrollmentFull:
+		return http.StatusConflict
+	case constant.ErrInvalidChallengeSchedule:
+		return http.StatusBadRequest
+	case constant.ErrInvalidChallengeStatus:
+		return http.StatusBadRequest
+	case constant.ErrChallengeEventEnded:
+		return http.StatusBadRequest
+	case constant.ErrChallengeLocked:
+		return http.StatusForbidden
+	case constant.ErrChallengeSeasonNotFound:
+		return http.StatusNotFound
+	case constant.ErrChallengeSeasonField:
+		return http.StatusBadRequest
+	case constant.ErrInvalidBonusMultiplier:
+		return http.StatusBadRequest
+
+	// Team Error
+	case constant.ErrTeamNotFound:
+		return http.StatusNotFound
+	case constant.ErrTeamField:
+		return http.StatusBadRequest
+	case constant.ErrUserAlreadyInTeam:
+		return http.StatusConflict
+	case constant.ErrNotTeamMember:
+		return http.StatusForbidden
+	case constant.ErrTeamFull:
+		return http.StatusConflict
+	case constant.ErrTeamLeaderCannotLeave:
+		return http.StatusBadRequest
+	case constant.ErrNotTeamLeader:
+		return http.StatusForbidden
+	case constant.ErrNotTeamChallenge:
+		return http.StatusBadRequest
+	case constant.ErrTeamChallengeAlreadyEnrolled:
+		return http.StatusConflict
+
+	// Forum Error
+	case constant.ErrForumNotFound:
+		return http.StatusNotFound
+	case constant.ErrForumMessageNotFound:
+		return http.StatusNotFound
+	case constant.ErrInvalidParentMessage:
+		return http.StatusBadRequest
+	case constant.ErrInvalidForumSort:
+		return http.StatusBadRequest
+	case constant.ErrForumAlreadyLiked:
+		return http.StatusConflict
+	case constant.ErrForumLikeNotFound:
+		return http.StatusNotFound
+	case constant.ErrForumLocked:
+		return http.StatusForbidden
+	case constant.ErrUserBanned:
+		return http.StatusForbidden
+	case constant.ErrContentBlocked:
+		return http.StatusBadRequest
+	case constant.ErrContentSpam:
+		return http.StatusBadRequest
+	case constant.ErrReportField:
+		return http.StatusBadRequest
+	case constant.ErrReportExists:
+		return http.StatusConflict
+	case constant.ErrReportNotFound:
+		return http.StatusNotFound
+	case constant.ErrReportNotPending:
+		return http.StatusConflict
+	case constant.ErrInvalidReportStatus:
+		return http.StatusBadRequest
+	case constant.ErrInvalidModerationAction:
+		return http.StatusBadRequest
+	case constant.ErrBanNotFound:
+		return http.StatusNotFound
+	case constant.ErrBanField:
+		return http.StatusBadRequest
+	case constant.ErrBlockedWordField:
+		return http.StatusBadRequest
+	case constant.ErrBlockedWordExists:
+		return http.StatusConflict
+	case constant.ErrBlockedWordNotFound:
+		return http.StatusNotFound
+	case constant.ErrForumCategoryNotFound:
+		return http.StatusNotFound
+	case constant.ErrForumCategoryField:
+		return http.StatusBadRequest
+	case constant.ErrForumCategoryExists:
+		return http.StatusConflict
+	case constant.ErrInvalidForumTags:
+		return http.StatusBadRequest
+
+	// Dashboard Error
+	case constant.ErrInvalidReportRange:
+		return http.StatusBadRequest
+	case constant.ErrInvalidExportFormat:
+		return http.StatusBadRequest
+
+	// Notification Error
+	case constant.ErrNotificationNotFound:
+		return http.StatusNotFound
+	case constant.ErrInvalidNotificationType:
+		return http.StatusBadRequest
+	case constant.ErrAnnouncementField:
+		return http.StatusBadRequest
+
+	// Chatbot Error
+	case constant.ErrConversationNotFound:
+		return http.StatusNotFound
+	case constant.ErrConversationTitle:
+		return http.StatusBadRequest
+	case constant.ErrChatbotMessageQuota:
+		return http.StatusTooManyRequests
+	case constant.ErrChatbotTokenQuota:
+		return http.StatusTooManyRequests
+
+	// Knowledge Error
+	case constant.ErrKnowledgeNotFound:
+		return http.StatusNotFound
+	case constant.ErrKnowledgeField:
+		return http.StatusBadRequest
+	case constant.ErrKnowledgeFormat:
+		return http.StatusBadRequest
+	case constant.ErrKnowledgeTooLarge:
+		return http.StatusBadRequest
+	case constant.ErrKnowledgeQuery:
+		return http.StatusBadRequest
+
+	// Article Error
+	case constant.ErrArticleNotFound:
+		return http.StatusNotFound
+	case constant.ErrArticleField:
+		return http.StatusBadRequest
+	case constant.ErrInvalidArticleStatus:
+		return http.StatusBadRequest
+	case constant.ErrArticleImpactCategory:
+		return http.StatusBadRequest
+	case constant.ErrEcoTipNotFound:
+		return http.StatusNotFound
+	case constant.ErrEcoTipField:
+		return http.StatusBadRequest
+
+	// Profile Error
+	case constant.ErrFollowSelf:
+		return http.StatusBadRequest
+	case constant.ErrAlreadyFollowing:
+		return http.StatusConflict
+	case constant.ErrNotFollowing:
+		return http.StatusNotFound
+	case constant.ErrFollowRequestPending:
+		return http.StatusConflict
+	case constant.ErrFollowRequestNotFound:
+		return http.StatusNotFound
+	case constant.ErrInvalidVisibility:
+		return http.StatusBadRequest
+
+	// Account Error
+	case constant.ErrExportNotFound:
+		return http.StatusNotFound
+	case constant.ErrExportInProgress:
+		return http.StatusConflict
+	case constant.ErrExportNotReady:
+		return http.StatusConflict
+	case constant.ErrExportExpired:
+		return http.StatusGone
+	case constant.ErrDeletionNotFound:
+		return http.StatusNotFound
+	case constant.ErrDeletionScheduled:
+		return http.StatusConflict
 
 
 	// Default
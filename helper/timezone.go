@@ -0,0 +1,31 @@
+package helper
+
+import (
+	"time"
+
+	// Embed the IANA database so user timezones resolve even on slim images.
+	_ "time/tzdata"
+)
+
+const DefaultTimezone = "Asia/Jakarta"
+
+func ValidateTimezone(timezone string) bool {
+	if timezone == "" {
+		return false
+	}
+	_, err := time.LoadLocation(timezone)
+	return err == nil
+}
+
+// LoadTimezone returns the location for a user's timezone, falling back to
+// DefaultTimezone when it is empty or unknown.
+func LoadTimezone(timezone string) *time.Location {
+	if loc, err := time.LoadLocation(timezone); err == nil && timezone != "" {
+		return loc
+	}
+	loc, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -1,17 +1,32 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"greenenvironment/configs"
 	_ "greenenvironment/docs"
 	"greenenvironment/helper"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	AccountController "greenenvironment/features/accounts/controller"
+	AccountRepository "greenenvironment/features/accounts/repository"
+	AccountService "greenenvironment/features/accounts/service"
 	AdminContoller "greenenvironment/features/admin/controller"
 	AdminRepository "greenenvironment/features/admin/repository"
 	AdminService "greenenvironment/features/admin/service"
+	ArticleController "greenenvironment/features/articles/controller"
+	ArticleRepository "greenenvironment/features/articles/repository"
+	ArticleService "greenenvironment/features/articles/service"
 	CartController "greenenvironment/features/cart/controller"
 	CartRepository "greenenvironment/features/cart/repository"
 	CartService "greenenvironment/features/cart/service"
+	"greenenvironment/features/challenges"
 	ChallengeController "greenenvironment/features/challenges/controller"
 	ChallengeRepository "greenenvironment/features/challenges/repository"
 	ChallengeService "greenenvironment/features/challenges/service"
@@ -21,21 +36,35 @@ import (
 	DashboardController "greenenvironment/features/dashboard/controller"
 	DashboardRepository "greenenvironment/features/dashboard/repository"
 	DashboardService "greenenvironment/features/dashboard/service"
+	EmailRepository "greenenvironment/features/emails/repository"
+	EmailService "greenenvironment/features/emails/service"
 	ForumController "greenenvironment/features/forum/controller"
 	ForumRepository "greenenvironment/features/forum/repository"
 	ForumService "greenenvironment/features/forum/service"
 	ImpactController "greenenvironment/features/impacts/controller"
 	ImpactRepository "greenenvironment/features/impacts/repository"
 	ImpactService "greenenvironment/features/impacts/service"
+	KnowledgeController "greenenvironment/features/knowledge/controller"
+	KnowledgeRepository "greenenvironment/features/knowledge/repository"
+	KnowledgeService "greenenvironment/features/knowledge/service"
 	LeaderboardController "greenenvironment/features/leaderboard/controller"
 	LeaderboardRepository "greenenvironment/features/leaderboard/repository"
 	LeaderboardService "greenenvironment/features/leaderboard/service"
+	NotificationController "greenenvironment/features/notifications/controller"
+	NotificationRepository "greenenvironment/features/notifications/repository"
+	NotificationService "greenenvironment/features/notifications/service"
 	ProductController "greenenvironment/features/products/controller"
 	ProductRepository "greenenvironment/features/products/repository"
 	ProductService "greenenvironment/features/products/service"
+	ProfileController "greenenvironment/features/profiles/controller"
+	ProfileRepository "greenenvironment/features/profiles/repository"
+	ProfileService "greenenvironment/features/profiles/service"
 	ReviewController "greenenvironment/features/review_products/controller"
 	ReviewRepository "greenenvironment/features/review_products/repository"
 	ReviewService "greenenvironment/features/review_products/service"
+	TeamController "greenenvironment/features/teams/controller"
+	TeamRepository "greenenvironment/features/teams/repository"
+	TeamService "greenenvironment/features/teams/service"
 	TransactionController "greenenvironment/features/transactions/controller"
 	TransactionRepository "greenenvironment/features/transactions/repository"
 	TransactionService "greenenvironment/features/transactions/service"
@@ -48,8 +77,10 @@ import (
 
 	"greenenvironment/routes"
 	"greenenvironment/utils/databases"
+	"greenenvironment/utils/llm"
+	"greenenvironment/utils/mail"
 	"greenenvironment/utils/midtrans"
-	OpenAIservice "greenenvironment/utils/openai"
+	"greenenvironment/utils/realtime"
 	"greenenvironment/utils/storages"
 
 	"github.com/go-playground/validator"
@@ -60,6 +91,13 @@ import (
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
+// shutdownTimeout is how long open requests get to finish when the server
+// is stopped, and cronStopTimeout how long the running cron jobs get.
+const (
+	shutdownTimeout = 10 * time.Second
+	cronStopTimeout = time.Minute
+)
+
 // @title Ecomate Rest API
 // @version 1.0
 // @description API Documentation for Ecomate.
@@ -86,13 +124,26 @@ func main() {
 		logrus.Error("terjadi kesalahan pada database, error:", err.Error())
 	}
 
-	databases.Migrate(db)
+	if err := databases.Migrate(db); err != nil {
+		logrus.Fatal("database migration failed: ", err.Error())
+	}
 	jwt := helper.NewJWT(cfg.JWT_Secret)
-	mailer := helper.NewMailer(cfg.SMTP)
+	emailRepo := EmailRepository.NewEmailRepository(db)
+	mailer := EmailService.NewEmailService(emailRepo, mail.NewSMTPTransport(cfg.SMTP))
+	mailer.Start()
 	otp := helper.NewOTP()
 	storage := storages.NewStorage(cfg.Cloudinary)
 	midtransService := midtrans.NewPaymentGateway(cfg.Midtrans)
-	openAIservice := OpenAIservice.NewOpenAIService(cfg.OpenAi.ApiKey)
+	llmProvider, err := llm.NewProvider(cfg.LLM)
+	if err != nil {
+		logrus.Fatal("invalid LLM configuration: ", err.Error())
+	}
+	embedder, err := llm.NewEmbedder(cfg.LLM)
+	if err != nil {
+		logrus.Fatal("invalid embedding configuration: ", err.Error())
+	}
+	broker := realtime.NewMemoryBroker()
+	realtimeHandler := realtime.NewRealtime(broker, jwt)
 
 	e := echo.New()
 	e.Validator = &helper.CustomValidator{Validator: validator.New()}
@@ -101,6 +152,10 @@ func main() {
 		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
 	}))
 
+	notificationRepo := NotificationRepository.NewNotificationRepository(db)
+	notificationService := NotificationService.NewNotificationService(notificationRepo, mailer, broker)
+	notificationController := NotificationController.NewNotificationController(notificationService, jwt)
+
 	userRepo := UserRepository.NewUserRepository(db)
 	userService := UserService.NewUserService(userRepo, jwt, mailer, otp)
 	userController := UserController.NewUserController(userService, jwt, storage)
@@ -122,29 +177,52 @@ func main() {
 	cartController := CartController.NewCartController(cartService, jwt)
 
 	transactionRepo := TransactionRepository.NewTransactionRepository(db)
-	transactionService := TransactionService.NewTransactionService(transactionRepo, midtransService)
+	transactionService := TransactionService.NewTransactionService(transactionRepo, midtransService, mailer)
 	transactionController := TransactionController.NewTransactionController(transactionService, jwt)
 
 	webhookRepo := WebHookRepository.NewWebhookRepository(db)
-	webhookService := WebhookService.NewWebhookService(webhookRepo)
+	webhookService := WebhookService.NewWebhookService(webhookRepo, notificationService, broker, mailer)
 	webhookController := WebhookController.NewWebhookRequest(webhookService)
 
 	reviewRepo := ReviewRepository.NewReviewProductRepository(db)
 	reviewService := ReviewService.NewReviewProductService(reviewRepo)
 	reviewController := ReviewController.NewReviewProductController(reviewService, jwt)
 
-	chatbotRepo := ChatbotRepository.NewChatbotRepository(db)
-	chatbotService := ChatbotService.NewChatbotService(chatbotRepo, openAIservice)
-	chatbotController := ChatbotController.NewChatbotController(chatbotService, jwt)
-
 	forumRepo := ForumRepository.NewForumRepository(db)
-	forumService := ForumService.NewForumService(forumRepo)
+	forumService := ForumService.NewForumService(forumRepo, notificationService, broker)
 	forumController := ForumController.NewForumController(forumService, jwt, storage)
 
 	challengeRepo := ChallengeRepository.NewChallengeRepository(db)
-	challengeService := ChallengeService.NewChallengeService(challengeRepo, impactRepo)
+	var proofVerifier challenges.ProofVerifier
+	if cfg.Proof.Enabled {
+		visionConfig := cfg.LLM
+		if cfg.Proof.Model != "" {
+			visionConfig.Model = cfg.Proof.Model
+		}
+		visionProvider, err := llm.NewProvider(visionConfig)
+		if err != nil {
+			logrus.Fatal("invalid proof verification configuration: ", err.Error())
+		}
+		proofVerifier = ChallengeService.NewProofVerifier(visionProvider)
+	}
+	challengeService := ChallengeService.NewChallengeService(challengeRepo, impactRepo, mailer, notificationService, proofVerifier, cfg.Proof)
 	challengeController := ChallengeController.NewChallengeController(challengeService, jwt, storage)
 
+	knowledgeRepo := KnowledgeRepository.NewKnowledgeRepository(db)
+	knowledgeService := KnowledgeService.NewKnowledgeService(knowledgeRepo, embedder, cfg.Knowledge)
+	knowledgeController := KnowledgeController.NewKnowledgeController(knowledgeService, jwt)
+	if err := knowledgeService.Load(context.Background()); err != nil {
+		log.Printf("Error loading knowledge base index: %v", err)
+	}
+
+	articleRepo := ArticleRepository.NewArticleRepository(db)
+	articleService := ArticleService.NewArticleService(articleRepo, impactRepo)
+	articleController := ArticleController.NewArticleController(articleService, jwt, storage)
+
+	chatbotRepo := ChatbotRepository.NewChatbotRepository(db)
+	chatbotService := ChatbotService.NewChatbotService(chatbotRepo, productRepo, challengeRepo, impactService, knowledgeService, llmProvider, cfg.Chatbot)
+	chatbotController := ChatbotController.NewChatbotController(chatbotService, jwt)
+
 	dashboardRepo := DashboardRepository.NewDashboardRepository(db)
 	dashboardService := DashboardService.NewDashboardService(dashboardRepo)
 	dashboardController := DashboardController.NewDashboardController(dashboardService, jwt)
@@ -153,18 +231,91 @@ func main() {
 	leaderboardService := LeaderboardService.NewLeaderboardService(leaderboardRepo)
 	leaderboardController := LeaderboardController.NewLeaderboardController(leaderboardService, jwt)
 
+	profileRepo := ProfileRepository.NewProfileRepository(db)
+	profileService := ProfileService.NewProfileService(profileRepo, impactService, forumService, notificationService)
+	profileController := ProfileController.NewProfileController(profileService, jwt)
+
+	accountRepo := AccountRepository.NewAccountRepository(db)
+	accountService := AccountService.NewAccountService(accountRepo, mailer, notificationService, storage)
+	accountController := AccountController.NewAccountController(accountService, jwt)
+
+	teamRepo := TeamRepository.NewTeamRepository(db)
+	teamService := TeamService.NewTeamService(teamRepo)
+	teamController := TeamController.NewTeamController(teamService, jwt)
+
 	c := cron.New()
+	c.AddFunc("@every 15m", func() {
+		err := challengeService.SweepOverdueTasks()
+		if err != nil {
+			log.Printf("Error sweeping overdue challenge tasks: %v", err)
+		}
+	})
+	c.AddFunc("@every 15m", func() {
+		err := challengeService.RemindUpcomingDeadlines()
+		if err != nil {
+			log.Printf("Error reminding upcoming challenge deadlines: %v", err)
+		}
+	})
+	c.AddFunc("@every 5m", func() {
+		err := challengeService.SyncChallengeSchedules()
+		if err != nil {
+			log.Printf("Error syncing challenge schedules: %v", err)
+		}
+	})
+	c.AddFunc("@every 5m", func() {
+		err := articleService.PublishScheduled()
+		if err != nil {
+			log.Printf("Error publishing scheduled articles: %v", err)
+		}
+	})
+	c.AddFunc("@every 1m", func() {
+		err := challengeService.VerifyPendingProofs()
+		if err != nil {
+			log.Printf("Error verifying challenge proofs: %v", err)
+		}
+	})
+	c.AddFunc("@every 1m", func() {
+		err := mailer.DispatchOutbox()
+		if err != nil {
+			log.Printf("Error dispatching email outbox: %v", err)
+		}
+	})
 	c.AddFunc("@daily", func() {
-		log.Println("Updating challenge and task statuses...")
-		err := challengeRepo.UpdateTaskAndChallengeStatus()
+		err := mailer.PurgeOutbox()
+		if err != nil {
+			log.Printf("Error purging email outbox: %v", err)
+		}
+	})
+	c.AddFunc("@every 1m", func() {
+		err := forumService.FlushViews()
+		if err != nil {
+			log.Printf("Error flushing forum views: %v", err)
+		}
+	})
+	c.AddFunc("@every 1m", func() {
+		err := accountService.ProcessPendingExports()
 		if err != nil {
-			log.Printf("Error updating statuses: %v", err)
+			log.Printf("Error processing data exports: %v", err)
+		}
+	})
+	c.AddFunc("@hourly", func() {
+		err := accountService.ExecuteDueDeletions()
+		if err != nil {
+			log.Printf("Error deleting accounts: %v", err)
+		}
+	})
+	c.AddFunc("@hourly", func() {
+		log.Println("Syncing team challenge progress...")
+		err := teamService.SyncActiveTeamChallenges()
+		if err != nil {
+			log.Printf("Error syncing team challenges: %v", err)
 		}
 	})
 	c.Start()
-	defer c.Stop()
 
 	routes.RouteUser(e, userController, *cfg)
+	routes.RouteProfile(e, profileController, *cfg)
+	routes.RouteAccount(e, accountController, *cfg)
 	routes.RouteAdmin(e, adminController, *cfg)
 	routes.RoutesProducts(e, productController, *cfg)
 	routes.RouteImpacts(e, impactController, *cfg)
@@ -174,11 +325,51 @@ func main() {
 	routes.PaymentNotification(e, webhookController)
 	routes.RouteReviewProduct(e, reviewController, *cfg)
 	routes.RouteChatbot(e, chatbotController, *cfg)
+	routes.RouteKnowledge(e, knowledgeController, *cfg)
+	routes.RouteArticle(e, articleController, *cfg)
 	routes.RouteForum(e, forumController, *cfg)
 	routes.RouteChallenge(e, challengeController, *cfg)
 	routes.RouteDashboard(e, dashboardController, *cfg)
 	routes.RouteLeaderboard(e, leaderboardController, *cfg)
+	routes.RouteTeam(e, teamController, *cfg)
+	routes.RouteNotification(e, notificationController, *cfg)
+	routes.RouteRealtime(e, realtimeHandler)
 
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
-	e.Logger.Fatal(e.Start(cfg.APP_PORT))
+
+	// Streams only end when their request context is done, which
+	// Shutdown does not do by itself, so every request context is derived
+	// from one that is cancelled as soon as shutdown starts.
+	serverCtx, cancelRequests := context.WithCancel(context.Background())
+	e.Server.BaseContext = func(net.Listener) context.Context { return serverCtx }
+	e.Server.RegisterOnShutdown(cancelRequests)
+
+	go func() {
+		if err := e.Start(cfg.APP_PORT); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.Logger.Fatal(err)
+		}
+	}()
+
+	// On SIGINT or SIGTERM stop taking requests, let the running cron jobs
+	// finish and write the forum views still buffered in memory.
+	quit, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-quit.Done()
+
+	cronStopped := c.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		log.Printf("Error shutting down the server: %v", err)
+	}
+
+	select {
+	case <-cronStopped.Done():
+	case <-time.After(cronStopTimeout):
+		log.Println("Gave up waiting for cron jobs to finish")
+	}
+	if err := forumService.FlushViews(); err != nil {
+		log.Printf("Error flushing forum views: %v", err)
+	}
 }
@@ -3,20 +3,27 @@ package routes
 import (
 	"greenenvironment/configs"
 	"greenenvironment/constant/route"
+	"greenenvironment/features/accounts"
 	"greenenvironment/features/admin"
+	"greenenvironment/features/articles"
 	"greenenvironment/features/cart"
 	"greenenvironment/features/challenges"
 	"greenenvironment/features/chatbot"
 	"greenenvironment/features/dashboard"
 	"greenenvironment/features/forum"
 	"greenenvironment/features/impacts"
+	"greenenvironment/features/knowledge"
 	"greenenvironment/features/leaderboard"
+	"greenenvironment/features/notifications"
 	"greenenvironment/features/products"
+	"greenenvironment/features/profiles"
 	reviewproducts "greenenvironment/features/review_products"
+	"greenenvironment/features/teams"
 	"greenenvironment/features/transactions"
 	"greenenvironment/features/users"
 	"greenenvironment/features/webhook"
 	"greenenvironment/helper"
+	"greenenvironment/utils/realtime"
 	"greenenvironment/utils/storages"
 
 	echojwt "github.com/labstack/echo-jwt"
@@ -43,7 +50,6 @@ func RouteUser(e *echo.Echo, uh users.UserControllerInterface, cfg configs.GECon
 	e.GET(route.UserProfile, uh.GetUserData, echojwt.WithConfig(jwtConfig))
 	e.PUT(route.UserUpdate, uh.UpdateUserInfo, echojwt.WithConfig(jwtConfig))
 	e.PUT(route.UserUpdateAvatar, uh.UpdateAvatar, echojwt.WithConfig(jwtConfig))
-	e.DELETE(route.UserPath, uh.Delete, echojwt.WithConfig(jwtConfig))
 
 	e.POST(route.UserRequestUpdateOTP, uh.RequestPasswordUpdateOTP, echojwt.WithConfig(jwtConfig))
 	e.PUT(route.UserUpdatePassword, uh.UpdateUserPassword, echojwt.WithConfig(jwtConfig))
@@ -55,6 +61,41 @@ func RouteUser(e *echo.Echo, uh users.UserControllerInterface, cfg configs.GECon
 	e.DELETE(route.AdminManageUserByID, uh.DeleteUserForAdmin, echojwt.WithConfig(jwtConfig))
 }
 
+func RouteProfile(e *echo.Echo, ph profiles.ProfileControllerInterface, cfg configs.GEConfig) {
+	jwtConfig := echojwt.Config{
+		SigningKey:   []byte(cfg.JWT_Secret),
+		ErrorHandler: helper.JWTErrorHandler,
+	}
+
+	e.GET(route.ProfileByUsername, ph.GetProfile)
+	e.GET(route.ProfileFollowers, ph.GetFollowers)
+	e.GET(route.ProfileFollowing, ph.GetFollowing)
+	e.POST(route.ProfileFollow, ph.Follow, echojwt.WithConfig(jwtConfig))
+	e.DELETE(route.ProfileFollow, ph.Unfollow, echojwt.WithConfig(jwtConfig))
+	e.GET(route.UserFollowRequests, ph.GetFollowRequests, echojwt.WithConfig(jwtConfig))
+	e.POST(route.UserFollowRequest, ph.AcceptFollowRequest, echojwt.WithConfig(jwtConfig))
+	e.DELETE(route.UserFollowRequest, ph.DeclineFollowRequest, echojwt.WithConfig(jwtConfig))
+
+	e.GET(route.UserPrivacy, ph.GetPrivacy, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.UserPrivacy, ph.UpdatePrivacy, echojwt.WithConfig(jwtConfig))
+	e.GET(route.UserFeed, ph.GetFeed, echojwt.WithConfig(jwtConfig))
+}
+
+func RouteAccount(e *echo.Echo, ac accounts.AccountControllerInterface, cfg configs.GEConfig) {
+	jwtConfig := echojwt.Config{
+		SigningKey:   []byte(cfg.JWT_Secret),
+		ErrorHandler: helper.JWTErrorHandler,
+	}
+
+	e.POST(route.UserDataExport, ac.RequestExport, echojwt.WithConfig(jwtConfig))
+	e.GET(route.UserDataExport, ac.GetLatestExport, echojwt.WithConfig(jwtConfig))
+	e.GET(route.UserDataExportDownload, ac.DownloadExport, echojwt.WithConfig(jwtConfig))
+
+	e.DELETE(route.UserPath, ac.RequestDeletion, echojwt.WithConfig(jwtConfig))
+	e.GET(route.UserDeletion, ac.GetDeletion, echojwt.WithConfig(jwtConfig))
+	e.DELETE(route.UserDeletion, ac.CancelDeletion, echojwt.WithConfig(jwtConfig))
+}
+
 func RouteAdmin(e *echo.Echo, ah admin.AdminControllerInterface, cfg configs.GEConfig) {
 	jwtConfig := echojwt.Config{
 		SigningKey:   []byte(cfg.JWT_Secret),
@@ -90,7 +131,13 @@ func RouteImpacts(e *echo.Echo, ic impacts.ImpactControllerInterface, cfg config
 	e.POST(route.ImpactCategoryPath, ic.Create, echojwt.WithConfig(jwtConfig))
 	e.GET(route.ImpactCategoryPath, ic.GetAll, echojwt.WithConfig(jwtConfig))
 	e.GET(route.ImpactCategoryByID, ic.GetByID, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.ImpactCategoryByID, ic.Update, echojwt.WithConfig(jwtConfig))
 	e.DELETE(route.ImpactCategoryByID, ic.Delete, echojwt.WithConfig(jwtConfig))
+
+	// Impact Ledger
+	e.GET(route.MyImpact, ic.GetMyImpact, echojwt.WithConfig(jwtConfig))
+	e.GET(route.ImpactConversionFactor, ic.GetConversionFactors, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.ImpactConversionFactor, ic.UpdateConversionFactors, echojwt.WithConfig(jwtConfig))
 }
 
 func RouteStorage(e *echo.Echo, sc storages.StorageInterface, cfg configs.GEConfig) {
@@ -148,7 +195,46 @@ func RouteChatbot(e *echo.Echo, ch chatbot.ChatbotControllerInterface, cfg confi
 	}
 
 	e.POST(route.ChatbotPath, ch.Create, echojwt.WithConfig(jwtConfig))
+	e.POST(route.ChatbotStreamPath, ch.CreateStream, echojwt.WithConfig(jwtConfig))
+	e.GET(route.ChatbotPath, ch.GetConversations, echojwt.WithConfig(jwtConfig))
+	e.GET(route.ChatbotUsagePath, ch.GetUsage, echojwt.WithConfig(jwtConfig))
 	e.GET(route.ChatbotPathByID, ch.GetByID, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.ChatbotPathByID, ch.RenameConversation, echojwt.WithConfig(jwtConfig))
+	e.DELETE(route.ChatbotPathByID, ch.DeleteConversation, echojwt.WithConfig(jwtConfig))
+}
+
+func RouteKnowledge(e *echo.Echo, kh knowledge.KnowledgeControllerInterface, cfg configs.GEConfig) {
+	jwtConfig := echojwt.Config{
+		SigningKey:   []byte(cfg.JWT_Secret),
+		ErrorHandler: helper.JWTErrorHandler,
+	}
+
+	e.POST(route.AdminKnowledgePath, kh.Create, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminKnowledgePath, kh.GetAll, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminKnowledgeSearch, kh.Search, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminKnowledgeByID, kh.GetByID, echojwt.WithConfig(jwtConfig))
+	e.DELETE(route.AdminKnowledgeByID, kh.Delete, echojwt.WithConfig(jwtConfig))
+}
+
+func RouteArticle(e *echo.Echo, ah articles.ArticleControllerInterface, cfg configs.GEConfig) {
+	jwtConfig := echojwt.Config{
+		SigningKey:   []byte(cfg.JWT_Secret),
+		ErrorHandler: helper.JWTErrorHandler,
+	}
+
+	e.GET(route.ArticlePath, ah.GetAll)
+	e.GET(route.ArticleBySlug, ah.GetBySlug)
+	e.GET(route.DailyTipPath, ah.GetDailyTip)
+
+	// Admin
+	e.POST(route.AdminArticlePath, ah.Create, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminArticlePath, ah.GetAllForAdmin, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminArticleByID, ah.GetByID, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.AdminArticleByID, ah.Update, echojwt.WithConfig(jwtConfig))
+	e.DELETE(route.AdminArticleByID, ah.Delete, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminTipPath, ah.GetTips, echojwt.WithConfig(jwtConfig))
+	e.POST(route.AdminTipPath, ah.CreateTip, echojwt.WithConfig(jwtConfig))
+	e.DELETE(route.AdminTipByID, ah.DeleteTip, echojwt.WithConfig(jwtConfig))
 }
 
 func RouteForum(e *echo.Echo, fh forum.ForumControllerInterface, cfg configs.GEConfig) {
@@ -168,6 +254,30 @@ func RouteForum(e *echo.Echo, fh forum.ForumControllerInterface, cfg configs.GEC
 	e.POST(route.ForumMessage, fh.PostMessageForum, echojwt.WithConfig(jwtConfig))
 	e.DELETE(route.ForumMessageByID, fh.DeleteMessageForum, echojwt.WithConfig(jwtConfig))
 	e.PUT(route.ForumMessageByID, fh.UpdateMessageForum, echojwt.WithConfig(jwtConfig))
+
+	e.POST(route.ForumLike, fh.LikeForum, echojwt.WithConfig(jwtConfig))
+	e.DELETE(route.ForumLike, fh.UnlikeForum, echojwt.WithConfig(jwtConfig))
+	e.POST(route.ForumMessageLike, fh.LikeMessage, echojwt.WithConfig(jwtConfig))
+	e.DELETE(route.ForumMessageLike, fh.UnlikeMessage, echojwt.WithConfig(jwtConfig))
+
+	e.POST(route.ForumReport, fh.ReportContent, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminForumReport, fh.GetReports, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.AdminForumReportByID, fh.ResolveReport, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.AdminForumModeration, fh.ModerateForum, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.AdminForumMessageModeration, fh.ModerateMessage, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminForumBan, fh.GetActiveBans, echojwt.WithConfig(jwtConfig))
+	e.POST(route.AdminForumBan, fh.BanUser, echojwt.WithConfig(jwtConfig))
+	e.DELETE(route.AdminForumBanByID, fh.RevokeBan, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminForumBlockedWord, fh.GetBlockedWords, echojwt.WithConfig(jwtConfig))
+	e.POST(route.AdminForumBlockedWord, fh.AddBlockedWord, echojwt.WithConfig(jwtConfig))
+	e.DELETE(route.AdminForumBlockedWordByID, fh.RemoveBlockedWord, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminForumModerationLog, fh.GetModerationLogs, echojwt.WithConfig(jwtConfig))
+
+	e.GET(route.ForumCategory, fh.GetCategories, echojwt.WithConfig(jwtConfig))
+	e.GET(route.ForumTags, fh.GetPopularTags, echojwt.WithConfig(jwtConfig))
+	e.POST(route.AdminForumCategory, fh.CreateCategory, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.AdminForumCategoryByID, fh.UpdateCategory, echojwt.WithConfig(jwtConfig))
+	e.DELETE(route.AdminForumCategoryByID, fh.DeleteCategory, echojwt.WithConfig(jwtConfig))
 }
 
 func RouteChallenge(e *echo.Echo, cc challenges.ChallengeControllerInterface, cfg configs.GEConfig) {
@@ -188,12 +298,26 @@ func RouteChallenge(e *echo.Echo, cc challenges.ChallengeControllerInterface, cf
 	e.PUT(route.AdminChallengeTaskByID, cc.UpdateTask, echojwt.WithConfig(jwtConfig))
 	e.DELETE(route.AdminChallengeTaskByID, cc.DeleteTask, echojwt.WithConfig(jwtConfig))
 
+	// Challenge Review
+	e.GET(route.AdminPendingConfirmation, cc.GetPendingConfirmations, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.AdminReviewConfirmation, cc.ReviewChallengeConfirmation, echojwt.WithConfig(jwtConfig))
+
+	// Challenge Publishing & Season
+	e.PUT(route.AdminPublishChallenge, cc.PublishChallenge, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.AdminUnpublishChallenge, cc.UnpublishChallenge, echojwt.WithConfig(jwtConfig))
+	e.POST(route.AdminChallengeSeason, cc.CreateSeason, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminChallengeSeason, cc.GetAllSeasons, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminChallengeSeasonByID, cc.GetSeasonByID, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.AdminChallengeSeasonByID, cc.UpdateSeason, echojwt.WithConfig(jwtConfig))
+	e.DELETE(route.AdminChallengeSeasonByID, cc.DeleteSeason, echojwt.WithConfig(jwtConfig))
+
 	// User
 	e.POST(route.TakeChallenge, cc.CreateChallengeLog, echojwt.WithConfig(jwtConfig))
 	e.PUT(route.TaskConfirmationProgress, cc.UpdateChallengeConfirmationProgress, echojwt.WithConfig(jwtConfig))
 	e.POST(route.ClaimRewards, cc.ClaimRewards, echojwt.WithConfig(jwtConfig))
 	e.GET(route.ActiveChallenge, cc.GetActiveChallenges, echojwt.WithConfig(jwtConfig))
 	e.GET(route.UnclaimedChallenge, cc.GetUnclaimedChallenges, echojwt.WithConfig(jwtConfig))
+	e.GET(route.RecommendedChallenge, cc.GetRecommendedChallenges, echojwt.WithConfig(jwtConfig))
 	e.GET(route.UserChallengeDetails, cc.GetChallengeDetailsWithConfirmations, echojwt.WithConfig(jwtConfig))
 	e.GET(route.UserUnclaimedChallengeDetails, cc.GetChallengeDetails, echojwt.WithConfig(jwtConfig))
 }
@@ -204,6 +328,8 @@ func RouteDashboard(e *echo.Echo, dc dashboard.DashboardControllerInterface, cfg
 		ErrorHandler: helper.JWTErrorHandler,
 	}
 	e.GET(route.AdminDashboard, dc.GetDashboard, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminImpactReport, dc.GetImpactReport, echojwt.WithConfig(jwtConfig))
+	e.GET(route.AdminImpactReportExport, dc.ExportImpactReport, echojwt.WithConfig(jwtConfig))
 }
 
 func RouteLeaderboard(e *echo.Echo, lc leaderboard.LeaderboardControllerInterface, cfg configs.GEConfig) {
@@ -213,3 +339,39 @@ func RouteLeaderboard(e *echo.Echo, lc leaderboard.LeaderboardControllerInterfac
 	}
 	e.GET(route.LeaderboardPath, lc.GetLeaderboard, echojwt.WithConfig(jwtConfig))
 }
+
+func RouteTeam(e *echo.Echo, tc teams.TeamControllerInterface, cfg configs.GEConfig) {
+	jwtConfig := echojwt.Config{
+		SigningKey:   []byte(cfg.JWT_Secret),
+		ErrorHandler: helper.JWTErrorHandler,
+	}
+	e.POST(route.TeamPath, tc.CreateTeam, echojwt.WithConfig(jwtConfig))
+	e.GET(route.TeamPath, tc.GetAllTeams, echojwt.WithConfig(jwtConfig))
+	e.GET(route.MyTeam, tc.GetMyTeam, echojwt.WithConfig(jwtConfig))
+	e.GET(route.TeamLeaderboard, tc.GetTeamLeaderboard, echojwt.WithConfig(jwtConfig))
+	e.GET(route.TeamByID, tc.GetTeamByID, echojwt.WithConfig(jwtConfig))
+	e.POST(route.TeamJoin, tc.JoinTeam, echojwt.WithConfig(jwtConfig))
+	e.POST(route.TeamLeave, tc.LeaveTeam, echojwt.WithConfig(jwtConfig))
+	e.POST(route.TeamChallenges, tc.EnrollChallenge, echojwt.WithConfig(jwtConfig))
+	e.GET(route.TeamChallenges, tc.GetTeamChallenges, echojwt.WithConfig(jwtConfig))
+}
+
+func RouteNotification(e *echo.Echo, nc notifications.NotificationControllerInterface, cfg configs.GEConfig) {
+	jwtConfig := echojwt.Config{
+		SigningKey:   []byte(cfg.JWT_Secret),
+		ErrorHandler: helper.JWTErrorHandler,
+	}
+	e.GET(route.NotificationPath, nc.GetNotifications, echojwt.WithConfig(jwtConfig))
+	e.GET(route.NotificationUnreadCount, nc.CountUnread, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.NotificationReadAll, nc.MarkAllRead, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.NotificationRead, nc.MarkRead, echojwt.WithConfig(jwtConfig))
+	e.GET(route.NotificationPreferences, nc.GetPreferences, echojwt.WithConfig(jwtConfig))
+	e.PUT(route.NotificationPreferences, nc.UpdatePreferences, echojwt.WithConfig(jwtConfig))
+	e.POST(route.AdminAnnouncement, nc.Announce, echojwt.WithConfig(jwtConfig))
+}
+
+// RouteRealtime registers the event stream without the JWT middleware because
+// the handler also accepts the token as a query parameter.
+func RouteRealtime(e *echo.Echo, rt realtime.RealtimeInterface) {
+	e.GET(route.RealtimeStream, rt.Stream)
+}
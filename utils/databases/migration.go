@@ -1,19 +1,27 @@
 package databases
 
 import (
+	DataAccount "greenenvironment/features/accounts/repository"
 	DataAdmin "greenenvironment/features/admin/repository"
+	DataArticle "greenenvironment/features/articles/repository"
 	DataCart "greenenvironment/features/cart/repository"
+	DataEmail "greenenvironment/features/emails/repository"
 	DataChatbot "greenenvironment/features/chatbot/repository"
 	DataForum "greenenvironment/features/forum/repository"
 	DataChallenge "greenenvironment/features/challenges/repository"
 	DataImpact "greenenvironment/features/impacts/repository"
+	DataKnowledge "greenenvironment/features/knowledge/repository"
+	DataNotification "greenenvironment/features/notifications/repository"
 	DataProduct "greenenvironment/features/products/repository"
+	DataProfile "greenenvironment/features/profiles/repository"
 	DataReview "greenenvironment/features/review_products/repository"
+	DataTeam "greenenvironment/features/teams/repository"
 	DataTransaction "greenenvironment/features/transactions/repository"
 	DataUser "greenenvironment/features/users/repository"
 	DataWebhook "greenenvironment/features/webhook/repository"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func Migrate(db *gorm.DB) error {
@@ -22,6 +30,8 @@ func Migrate(db *gorm.DB) error {
 	db.AutoMigrate(&DataUser.TemporaryUser{})
 	db.AutoMigrate(&DataAdmin.Admin{})
 	db.AutoMigrate(&DataImpact.ImpactCategory{})
+	db.AutoMigrate(&DataImpact.ImpactConversionFactor{})
+	db.AutoMigrate(&DataImpact.ImpactLedger{})
 	db.AutoMigrate(&DataProduct.Product{})
 	db.AutoMigrate(&DataProduct.ProductImage{})
 	db.AutoMigrate(&DataProduct.ProductImpactCategory{})
@@ -31,14 +41,142 @@ func Migrate(db *gorm.DB) error {
 	db.AutoMigrate(&DataTransaction.TransactionItem{})
 	db.AutoMigrate(&DataReview.ReviewProduct{})
 	db.AutoMigrate(&DataChatbot.Chatbot{})
+	db.AutoMigrate(&DataChatbot.Conversation{})
+	db.AutoMigrate(&DataChatbot.Usage{})
+	db.AutoMigrate(&DataKnowledge.Document{})
+	db.AutoMigrate(&DataKnowledge.Chunk{})
+	db.AutoMigrate(&DataArticle.Article{})
+	db.AutoMigrate(&DataArticle.ArticleImpactCategory{})
+	db.AutoMigrate(&DataArticle.EcoTip{})
 	db.AutoMigrate(&DataWebhook.PaymentNotification{})
+	db.AutoMigrate(&DataForum.ForumCategory{})
 	db.AutoMigrate(&DataForum.Forum{})
+	db.AutoMigrate(&DataForum.ForumTag{})
+	db.AutoMigrate(&DataForum.ForumView{})
 	db.AutoMigrate(&DataForum.MessageForum{})
+	db.AutoMigrate(&DataForum.ForumLike{})
+	db.AutoMigrate(&DataForum.ForumMention{})
+	db.AutoMigrate(&DataForum.ForumReport{})
+	db.AutoMigrate(&DataForum.UserBan{})
+	db.AutoMigrate(&DataForum.BlockedWord{})
+	db.AutoMigrate(&DataForum.ModerationLog{})
+	db.AutoMigrate(&DataChallenge.ChallengeSeason{})
 	db.AutoMigrate(&DataChallenge.Challenge{})
 	db.AutoMigrate(&DataChallenge.ChallengeImpactCategory{})
 	db.AutoMigrate(&DataChallenge.ChallengeTask{})
 	db.AutoMigrate(&DataChallenge.ChallengeLog{})
 	db.AutoMigrate(&DataChallenge.ChallengeConfirmation{})
+	db.AutoMigrate(&DataTeam.Team{})
+	db.AutoMigrate(&DataTeam.TeamMember{})
+	db.AutoMigrate(&DataTeam.TeamChallenge{})
+	db.AutoMigrate(&DataNotification.Notification{})
+	db.AutoMigrate(&DataNotification.NotificationPreference{})
+	db.AutoMigrate(&DataEmail.EmailOutbox{})
+	db.AutoMigrate(&DataProfile.UserFollow{})
+	db.AutoMigrate(&DataProfile.ProfilePrivacy{})
+	db.AutoMigrate(&DataAccount.DataExport{})
+	db.AutoMigrate(&DataAccount.AccountDeletion{})
 
+	if err := purgeDeletedTeams(db); err != nil {
+		return err
+	}
+	if err := dropOwnerlessChatbotChats(db); err != nil {
+		return err
+	}
+	if err := restrictImpactCategoryDeletes(db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// purgeDeletedTeams removes the teams and memberships that were soft deleted
+// before they were deleted for good, which kept names taken and users in
+// more than one team row. Where a user still belongs to several teams, only
+// the earliest membership is kept. The unique index on team_members.user_id
+// could not be created over those rows, so it is created here once they are
+// gone.
+func purgeDeletedTeams(db *gorm.DB) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		queries := []string{
+			"DELETE FROM team_challenges WHERE deleted_at IS NOT NULL OR team_id IN (SELECT id FROM teams WHERE deleted_at IS NOT NULL)",
+			"DELETE FROM team_members WHERE deleted_at IS NOT NULL OR team_id IN (SELECT id FROM teams WHERE deleted_at IS NOT NULL)",
+			"DELETE FROM teams WHERE deleted_at IS NOT NULL",
+			`DELETE later FROM team_members later
+				JOIN team_members earlier ON earlier.user_id = later.user_id
+					AND (earlier.joined_at < later.joined_at OR (earlier.joined_at = later.joined_at AND earlier.id < later.id))`,
+		}
+		for _, query := range queries {
+			if err := tx.Exec(query).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if db.Migrator().HasIndex(&DataTeam.TeamMember{}, "idx_team_members_user") {
+		return nil
+	}
+	return db.Migrator().CreateIndex(&DataTeam.TeamMember{}, "idx_team_members_user")
+}
+
+// dropOwnerlessChatbotChats deletes the chats from before conversations had
+// owners. Their messages do not record who sent them, so they cannot be
+// given back to anyone and would otherwise be readable by whoever has the
+// chat ID.
+func dropOwnerlessChatbotChats(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`DELETE chatbots FROM chatbots
+			LEFT JOIN chatbot_conversations ON chatbot_conversations.id = chatbots.chat_id AND chatbot_conversations.user_id <> ''
+			WHERE chatbot_conversations.id IS NULL`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM chatbot_conversations WHERE user_id = ''").Error
+	})
+}
+
+// restrictImpactCategoryDeletes rebuilds the foreign keys from challenges and
+// products to impact categories that were created with ON DELETE CASCADE, so
+// deleting a category in use is refused instead of silently unlinking it.
+// AutoMigrate only creates missing constraints and never changes existing
+// ones.
+func restrictImpactCategoryDeletes(db *gorm.DB) error {
+	models := []interface{}{
+		&DataChallenge.ChallengeImpactCategory{},
+		&DataProduct.ProductImpactCategory{},
+	}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+
+		var cascading []string
+		err := db.Raw(`SELECT rc.constraint_name
+			FROM information_schema.referential_constraints rc
+			JOIN information_schema.key_column_usage kcu
+				ON kcu.constraint_schema = rc.constraint_schema AND kcu.constraint_name = rc.constraint_name AND kcu.table_name = rc.table_name
+			WHERE rc.constraint_schema = DATABASE() AND rc.table_name = ? AND kcu.column_name = 'impact_category_id' AND rc.delete_rule = 'CASCADE'`,
+			stmt.Table).Scan(&cascading).Error
+		if err != nil {
+			return err
+		}
+		if len(cascading) == 0 {
+			continue
+		}
+
+		for _, name := range cascading {
+			if err := db.Exec("ALTER TABLE ? DROP FOREIGN KEY ?", clause.Table{Name: stmt.Table}, clause.Column{Name: name}).Error; err != nil {
+				return err
+			}
+		}
+		if err := db.Migrator().CreateConstraint(model, "ImpactCategory"); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,11 @@
+package seeds
+
+import (
+	DataArticle "greenenvironment/features/articles/repository"
+
+	"gorm.io/gorm"
+)
+
+func CreateEcoTip(db *gorm.DB, tip DataArticle.EcoTip) error {
+	return db.Where("id = ?", tip.ID).FirstOrCreate(&tip).Error
+}
@@ -0,0 +1,11 @@
+package seeds
+
+import (
+	DataForum "greenenvironment/features/forum/repository"
+
+	"gorm.io/gorm"
+)
+
+func CreateForumCategory(db *gorm.DB, category DataForum.ForumCategory) error {
+	return db.Where("slug = ?", category.Slug).FirstOrCreate(&category).Error
+}
@@ -3,6 +3,8 @@ package seeds
 import (
 	"fmt"
 	"greenenvironment/features/admin"
+	DataArticle "greenenvironment/features/articles/repository"
+	DataForum "greenenvironment/features/forum/repository"
 	"greenenvironment/utils/databases/seed"
 
 	"gorm.io/gorm"
@@ -34,6 +36,113 @@ func seeds() []seed.Seed {
 				})
 			},
 		},
+		{
+			Name: "CreateForumCategory01",
+			Run: func(db *gorm.DB) error {
+				return CreateForumCategory(db, DataForum.ForumCategory{
+					ID:          "5b0f3c2e-8d7a-4e51-9c36-2f1a7b9d4e01",
+					Name:        "Recycling",
+					Slug:        "recycling",
+					Description: "Sorting, collecting and reusing waste",
+				})
+			},
+		},
+		{
+			Name: "CreateForumCategory02",
+			Run: func(db *gorm.DB) error {
+				return CreateForumCategory(db, DataForum.ForumCategory{
+					ID:          "5b0f3c2e-8d7a-4e51-9c36-2f1a7b9d4e02",
+					Name:        "Composting",
+					Slug:        "composting",
+					Description: "Turning organic waste into compost",
+				})
+			},
+		},
+		{
+			Name: "CreateForumCategory03",
+			Run: func(db *gorm.DB) error {
+				return CreateForumCategory(db, DataForum.ForumCategory{
+					ID:          "5b0f3c2e-8d7a-4e51-9c36-2f1a7b9d4e03",
+					Name:        "Product Talk",
+					Slug:        "product-talk",
+					Description: "Reviews and questions about eco-friendly products",
+				})
+			},
+		},
+		{
+			Name: "CreateForumCategory04",
+			Run: func(db *gorm.DB) error {
+				return CreateForumCategory(db, DataForum.ForumCategory{
+					ID:          "5b0f3c2e-8d7a-4e51-9c36-2f1a7b9d4e04",
+					Name:        "General",
+					Slug:        "general",
+					Description: "Everything else about living green",
+				})
+			},
+		},
+		{
+			Name: "CreateEcoTip01",
+			Run: func(db *gorm.DB) error {
+				return CreateEcoTip(db, DataArticle.EcoTip{
+					ID:      "7c2e9a41-3f6d-4b8a-9e15-6d0b2a8c5f01",
+					Content: "Bring your own bottle and refill it instead of buying bottled water.",
+				})
+			},
+		},
+		{
+			Name: "CreateEcoTip02",
+			Run: func(db *gorm.DB) error {
+				return CreateEcoTip(db, DataArticle.EcoTip{
+					ID:      "7c2e9a41-3f6d-4b8a-9e15-6d0b2a8c5f02",
+					Content: "Unplug chargers and appliances you are not using, they still draw power on standby.",
+				})
+			},
+		},
+		{
+			Name: "CreateEcoTip03",
+			Run: func(db *gorm.DB) error {
+				return CreateEcoTip(db, DataArticle.EcoTip{
+					ID:      "7c2e9a41-3f6d-4b8a-9e15-6d0b2a8c5f03",
+					Content: "Keep a cloth bag in your backpack so you never need a plastic one at checkout.",
+				})
+			},
+		},
+		{
+			Name: "CreateEcoTip04",
+			Run: func(db *gorm.DB) error {
+				return CreateEcoTip(db, DataArticle.EcoTip{
+					ID:      "7c2e9a41-3f6d-4b8a-9e15-6d0b2a8c5f04",
+					Content: "Collect fruit and vegetable scraps for compost instead of throwing them away.",
+				})
+			},
+		},
+		{
+			Name: "CreateEcoTip05",
+			Run: func(db *gorm.DB) error {
+				return CreateEcoTip(db, DataArticle.EcoTip{
+					ID:      "7c2e9a41-3f6d-4b8a-9e15-6d0b2a8c5f05",
+					Content: "Turn off the tap while brushing your teeth to save up to six liters of water a minute.",
+				})
+			},
+		},
+		{
+			Name: "CreateEcoTip06",
+			Run: func(db *gorm.DB) error {
+				return CreateEcoTip(db, DataArticle.EcoTip{
+					ID:      "7c2e9a41-3f6d-4b8a-9e15-6d0b2a8c5f06",
+					Content: "Rinse and sort plastic, paper and cans before putting them out for recycling.",
+				})
+			},
+		},
+		{
+			Name: "CreateEcoTip07",
+			Run: func(db *gorm.DB) error {
+				return CreateEcoTip(db, DataArticle.EcoTip{
+					ID:      "7c2e9a41-3f6d-4b8a-9e15-6d0b2a8c5f07",
+					Content: "Walk or cycle for trips under two kilometers instead of taking a motorbike.",
+				})
+			},
+		},
 	}
 	return seeds
 }
@@ -0,0 +1,189 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"greenenvironment/configs"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+const (
+	EmbeddingProviderOpenAI = "openai"
+	EmbeddingProviderLocal  = "local"
+)
+
+// hashDimensions is the vector size of the local embedder.
+const hashDimensions = 256
+
+// embeddingBatchSize is how many texts go into one embeddings request.
+const embeddingBatchSize = 100
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// related the texts are. Vectors from different models cannot be compared,
+// so Model names the one in use.
+type Embedder interface {
+	Model() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewEmbedder builds the embedder selected in the config. The local one is
+// the default when the chat provider is the fake, so development works
+// offline.
+func NewEmbedder(config configs.LLMConfig) (Embedder, error) {
+	provider := config.EmbeddingProvider
+	if provider == "" {
+		provider = EmbeddingProviderOpenAI
+		if config.Provider == ProviderFake {
+			provider = EmbeddingProviderLocal
+		}
+	}
+
+	switch provider {
+	case EmbeddingProviderOpenAI:
+		return &resilientEmbedder{
+			retrier:  retrier{maxRetries: config.MaxRetries, breaker: NewBreaker(config.BreakerThreshold, config.BreakerCooldown)},
+			embedder: NewOpenAIEmbedder(config),
+		}, nil
+	case EmbeddingProviderLocal:
+		return NewHashEmbedder(), nil
+	default:
+		return nil, fmt.Errorf("llm: unknown embedding provider %q", provider)
+	}
+}
+
+type openAIEmbedder struct {
+	provider *openAIProvider
+	model    string
+}
+
+// NewOpenAIEmbedder uses the embeddings endpoint of the OpenAI API or of the
+// compatible server at BaseURL.
+func NewOpenAIEmbedder(config configs.LLMConfig) Embedder {
+	return &openAIEmbedder{
+		provider: NewOpenAIProvider(config).(*openAIProvider),
+		model:    config.EmbeddingModel,
+	}
+}
+
+func (o *openAIEmbedder) Model() string {
+	return o.model
+}
+
+func (o *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	ctx, cancel := o.provider.withTimeout(ctx)
+	defer cancel()
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		end := start + embeddingBatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+
+		resp, err := o.provider.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+			Input: texts[start:end],
+			Model: openai.EmbeddingModel(o.model),
+		})
+		if err != nil {
+			return nil, convertError(err)
+		}
+		if len(resp.Data) != end-start {
+			return nil, ErrNoAnswer
+		}
+
+		batch := make([][]float32, end-start)
+		for _, embedding := range resp.Data {
+			if embedding.Index < 0 || embedding.Index >= len(batch) {
+				return nil, ErrNoAnswer
+			}
+			batch[embedding.Index] = embedding.Embedding
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+type resilientEmbedder struct {
+	retrier
+	embedder Embedder
+}
+
+func (r *resilientEmbedder) Model() string {
+	return r.embedder.Model()
+}
+
+func (r *resilientEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var vectors [][]float32
+	err := r.do(ctx, func() (bool, bool, error) {
+		var err error
+		vectors, err = r.embedder.Embed(ctx, texts)
+		return false, true, err
+	})
+	return vectors, err
+}
+
+// HashEmbedder is a deterministic embedder that needs no model: every word
+// is hashed into one of a fixed number of dimensions. Texts that share words
+// get similar vectors, which is enough for tests and offline development.
+type HashEmbedder struct{}
+
+func NewHashEmbedder() *HashEmbedder {
+	return &HashEmbedder{}
+}
+
+func (h *HashEmbedder) Model() string {
+	return fmt.Sprintf("hash-%d", hashDimensions)
+}
+
+func (h *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, hashDimensions)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		for _, word := range words {
+			hash := fnv.New32a()
+			hash.Write([]byte(word))
+			sum := hash.Sum32()
+			// The top bit picks the sign so collisions cancel out rather
+			// than always adding up.
+			if sum&(1<<31) != 0 {
+				vector[sum%hashDimensions]--
+			} else {
+				vector[sum%hashDimensions]++
+			}
+		}
+		vectors[i] = Normalize(vector)
+	}
+	return vectors, nil
+}
+
+// Normalize scales vector to unit length, so the dot product of two
+// normalized vectors is their cosine similarity. A zero vector is returned
+// as is.
+func Normalize(vector []float32) []float32 {
+	var sum float64
+	for _, value := range vector {
+		sum += float64(value) * float64(value)
+	}
+	if sum == 0 {
+		return vector
+	}
+
+	norm := float32(math.Sqrt(sum))
+	normalized := make([]float32, len(vector))
+	for i, value := range vector {
+		normalized[i] = value / norm
+	}
+	return normalized
+}
@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// FakeProvider is a deterministic provider for tests and offline
+// development. It answers with the queued replies in order and, once they
+// run out, echoes the last user message. Streams are sent word by word.
+type FakeProvider struct {
+	mu       sync.Mutex
+	replies  []Message
+	err      error
+	requests [][]Message
+}
+
+func NewFakeProvider(replies ...string) *FakeProvider {
+	f := &FakeProvider{}
+	for _, reply := range replies {
+		f.replies = append(f.replies, Message{Role: RoleAssistant, Content: reply})
+	}
+	return f
+}
+
+// Reply queues an answer after the ones already queued.
+func (f *FakeProvider) Reply(content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.replies = append(f.replies, Message{Role: RoleAssistant, Content: content})
+}
+
+// CallTools queues a reply that asks for the given tool calls.
+func (f *FakeProvider) CallTools(calls ...ToolCall) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.replies = append(f.replies, Message{Role: RoleAssistant, ToolCalls: calls})
+}
+
+// Fail makes every call fail with err, until Fail(nil).
+func (f *FakeProvider) Fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// Requests returns the messages of every call so far.
+func (f *FakeProvider) Requests() [][]Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]Message(nil), f.requests...)
+}
+
+func (f *FakeProvider) reply(messages []Message) (Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, append([]Message(nil), messages...))
+	if f.err != nil {
+		return Message{}, f.err
+	}
+	if len(f.replies) > 0 {
+		reply := f.replies[0]
+		f.replies = f.replies[1:]
+		return reply, nil
+	}
+
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == RoleUser {
+			return Message{Role: RoleAssistant, Content: "You said: " + messages[i].Content}, nil
+		}
+	}
+	return Message{Role: RoleAssistant, Content: "Hello!"}, nil
+}
+
+func (f *FakeProvider) Complete(ctx context.Context, messages []Message) (string, error) {
+	reply, err := f.Chat(ctx, messages, nil, nil)
+	return reply.Content, err
+}
+
+func (f *FakeProvider) Stream(ctx context.Context, messages []Message, onDelta func(delta string) error) (string, error) {
+	reply, err := f.Chat(ctx, messages, nil, onDelta)
+	return reply.Content, err
+}
+
+// Chat returns queued tool calls as they are, whether or not tools were
+// offered.
+func (f *FakeProvider) Chat(ctx context.Context, messages []Message, tools []Tool, onDelta func(delta string) error) (Message, error) {
+	if err := ctx.Err(); err != nil {
+		return Message{}, err
+	}
+	reply, err := f.reply(messages)
+	if err != nil || onDelta == nil {
+		return reply, err
+	}
+
+	var answer strings.Builder
+	partial := func() Message {
+		return Message{Role: RoleAssistant, Content: answer.String(), ToolCalls: reply.ToolCalls}
+	}
+	for _, delta := range strings.SplitAfter(reply.Content, " ") {
+		if delta == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return partial(), err
+		}
+		answer.WriteString(delta)
+		if err := onDelta(delta); err != nil {
+			return partial(), err
+		}
+	}
+	return partial(), nil
+}
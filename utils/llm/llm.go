@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"greenenvironment/configs"
+	"net/http"
+)
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+const (
+	ProviderOpenAI = "openai"
+	ProviderFake   = "fake"
+)
+
+// Message is one turn of a prompt. An assistant message may ask for
+// ToolCalls instead of answering; each result goes back in a RoleTool
+// message with the ToolCallID it answers. ImageURLs attach pictures to a
+// user message for models that can see.
+type Message struct {
+	Role       string
+	Content    string
+	ImageURLs  []string
+	ToolCalls  []ToolCall
+	ToolCallID string
+}
+
+// Tool is a function the model may call. Parameters is the JSON schema of
+// its arguments.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{}
+}
+
+// ToolCall asks for a tool to be run. Arguments is a JSON object.
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// Provider is a chat model. Implementations must be safe for concurrent use.
+type Provider interface {
+	Complete(ctx context.Context, messages []Message) (string, error)
+	// Stream passes each piece of the answer to onDelta as it is generated
+	// and returns the whole answer. When the stream stops early, because ctx
+	// was cancelled, onDelta failed or the API broke off, the text received
+	// so far is returned together with the error.
+	Stream(ctx context.Context, messages []Message, onDelta func(delta string) error) (string, error)
+	// Chat offers the model tools. Its reply either answers, streamed to
+	// onDelta like Stream unless onDelta is nil, or asks for ToolCalls.
+	Chat(ctx context.Context, messages []Message, tools []Tool, onDelta func(delta string) error) (Message, error)
+}
+
+var ErrNoAnswer = errors.New("llm: the model returned no answer")
+
+// APIError is an error response from the model API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("llm: api error %d: %s", e.StatusCode, e.Message)
+}
+
+// Temporary reports whether the same request may succeed later.
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// NewProvider builds the provider selected in the config, wrapped with
+// retries and a circuit breaker.
+func NewProvider(config configs.LLMConfig) (Provider, error) {
+	var provider Provider
+	switch config.Provider {
+	case ProviderOpenAI, "":
+		provider = NewOpenAIProvider(config)
+	case ProviderFake:
+		return NewFakeProvider(), nil
+	default:
+		return nil, fmt.Errorf("llm: unknown provider %q", config.Provider)
+	}
+
+	return NewResilientProvider(provider, config.MaxRetries, NewBreaker(config.BreakerThreshold, config.BreakerCooldown)), nil
+}
@@ -0,0 +1,199 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"greenenvironment/configs"
+	"io"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+type openAIProvider struct {
+	client      *openai.Client
+	model       string
+	temperature float32
+	maxTokens   int
+	timeout     time.Duration
+}
+
+// NewOpenAIProvider talks to the OpenAI API, or to any OpenAI compatible
+// server such as a local model runner when BaseURL is set.
+func NewOpenAIProvider(config configs.LLMConfig) Provider {
+	clientConfig := openai.DefaultConfig(config.ApiKey)
+	if config.BaseURL != "" {
+		clientConfig.BaseURL = config.BaseURL
+	}
+
+	return &openAIProvider{
+		client:      openai.NewClientWithConfig(clientConfig),
+		model:       config.Model,
+		temperature: config.Temperature,
+		maxTokens:   config.MaxTokens,
+		timeout:     config.Timeout,
+	}
+}
+
+func (o *openAIProvider) request(messages []Message, tools []Tool, stream bool) openai.ChatCompletionRequest {
+	chatMessages := make([]openai.ChatCompletionMessage, len(messages))
+	for i, message := range messages {
+		chatMessages[i] = openai.ChatCompletionMessage{
+			Role:       message.Role,
+			Content:    message.Content,
+			ToolCallID: message.ToolCallID,
+		}
+		if len(message.ImageURLs) > 0 {
+			// Text and images go in parts; Content must then be empty.
+			chatMessages[i].Content = ""
+			chatMessages[i].MultiContent = []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: message.Content},
+			}
+			for _, url := range message.ImageURLs {
+				chatMessages[i].MultiContent = append(chatMessages[i].MultiContent, openai.ChatMessagePart{
+					Type:     openai.ChatMessagePartTypeImageURL,
+					ImageURL: &openai.ChatMessageImageURL{URL: url, Detail: openai.ImageURLDetailAuto},
+				})
+			}
+		}
+		for _, call := range message.ToolCalls {
+			chatMessages[i].ToolCalls = append(chatMessages[i].ToolCalls, openai.ToolCall{
+				ID:   call.ID,
+				Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name:      call.Name,
+					Arguments: call.Arguments,
+				},
+			})
+		}
+	}
+
+	var chatTools []openai.Tool
+	for _, tool := range tools {
+		chatTools = append(chatTools, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+
+	return openai.ChatCompletionRequest{
+		Model:       o.model,
+		Messages:    chatMessages,
+		Tools:       chatTools,
+		Temperature: o.temperature,
+		MaxTokens:   o.maxTokens,
+		Stream:      stream,
+	}
+}
+
+func (o *openAIProvider) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, o.timeout)
+}
+
+func (o *openAIProvider) Complete(ctx context.Context, messages []Message) (string, error) {
+	reply, err := o.Chat(ctx, messages, nil, nil)
+	return reply.Content, err
+}
+
+func (o *openAIProvider) Stream(ctx context.Context, messages []Message, onDelta func(delta string) error) (string, error) {
+	reply, err := o.Chat(ctx, messages, nil, onDelta)
+	return reply.Content, err
+}
+
+func (o *openAIProvider) Chat(ctx context.Context, messages []Message, tools []Tool, onDelta func(delta string) error) (Message, error) {
+	ctx, cancel := o.withTimeout(ctx)
+	defer cancel()
+
+	if onDelta == nil {
+		resp, err := o.client.CreateChatCompletion(ctx, o.request(messages, tools, false))
+		if err != nil {
+			return Message{}, convertError(err)
+		}
+		if len(resp.Choices) == 0 {
+			return Message{}, ErrNoAnswer
+		}
+
+		reply := Message{Role: RoleAssistant, Content: resp.Choices[0].Message.Content}
+		for _, call := range resp.Choices[0].Message.ToolCalls {
+			reply.ToolCalls = append(reply.ToolCalls, ToolCall{
+				ID:        call.ID,
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			})
+		}
+		return reply, nil
+	}
+
+	stream, err := o.client.CreateChatCompletionStream(ctx, o.request(messages, tools, true))
+	if err != nil {
+		return Message{}, convertError(err)
+	}
+	defer stream.Close()
+
+	var answer strings.Builder
+	// Tool calls arrive in pieces, keyed by their index in the reply.
+	var calls []ToolCall
+	reply := func() Message {
+		return Message{Role: RoleAssistant, Content: answer.String(), ToolCalls: calls}
+	}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return reply(), nil
+		}
+		if err != nil {
+			return reply(), convertError(err)
+		}
+		if len(resp.Choices) == 0 {
+			continue
+		}
+
+		for _, call := range resp.Choices[0].Delta.ToolCalls {
+			index := len(calls)
+			if call.Index != nil {
+				index = *call.Index
+			}
+			for len(calls) <= index {
+				calls = append(calls, ToolCall{})
+			}
+			if call.ID != "" {
+				calls[index].ID = call.ID
+			}
+			calls[index].Name += call.Function.Name
+			calls[index].Arguments += call.Function.Arguments
+		}
+
+		delta := resp.Choices[0].Delta.Content
+		if delta == "" {
+			continue
+		}
+		answer.WriteString(delta)
+		if err := onDelta(delta); err != nil {
+			return reply(), err
+		}
+	}
+}
+
+// convertError turns HTTP failures of the client into APIErrors so callers
+// can tell temporary failures from bad requests.
+func convertError(err error) error {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return &APIError{StatusCode: apiErr.HTTPStatusCode, Message: apiErr.Message}
+	}
+
+	var requestErr *openai.RequestError
+	if errors.As(err, &requestErr) && requestErr.HTTPStatusCode != 0 {
+		return &APIError{StatusCode: requestErr.HTTPStatusCode, Message: requestErr.Error()}
+	}
+
+	return err
+}
@@ -0,0 +1,179 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// retryBaseDelay is the wait before the first retry. Every further retry
+// doubles it.
+const retryBaseDelay = 500 * time.Millisecond
+
+var ErrCircuitOpen = errors.New("llm: provider unavailable, try again later")
+
+// Breaker stops calls to a provider after threshold failures in a row. Once
+// cooldown has passed a single trial call is let through; its result closes
+// the breaker or opens it for another cooldown.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// NewBreaker returns a breaker. A threshold of zero or less never opens.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return true
+	}
+	if b.now().Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// Release ends a call that says nothing about the provider's health, such as
+// one the caller cancelled.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// retrier retries temporary failures with exponential backoff and fails fast
+// while its breaker is open.
+type retrier struct {
+	maxRetries int
+	breaker    *Breaker
+}
+
+type resilientProvider struct {
+	retrier
+	provider Provider
+}
+
+// NewResilientProvider retries temporary failures of provider with
+// exponential backoff, up to maxRetries times, and fails fast while the
+// breaker is open.
+func NewResilientProvider(provider Provider, maxRetries int, breaker *Breaker) Provider {
+	return &resilientProvider{
+		retrier:  retrier{maxRetries: maxRetries, breaker: breaker},
+		provider: provider,
+	}
+}
+
+func (r *resilientProvider) Complete(ctx context.Context, messages []Message) (string, error) {
+	var answer string
+	err := r.do(ctx, func() (bool, bool, error) {
+		var err error
+		answer, err = r.provider.Complete(ctx, messages)
+		return false, true, err
+	})
+	return answer, err
+}
+
+func (r *resilientProvider) Stream(ctx context.Context, messages []Message, onDelta func(delta string) error) (string, error) {
+	reply, err := r.Chat(ctx, messages, nil, onDelta)
+	return reply.Content, err
+}
+
+func (r *resilientProvider) Chat(ctx context.Context, messages []Message, tools []Tool, onDelta func(delta string) error) (Message, error) {
+	var reply Message
+	err := r.do(ctx, func() (bool, bool, error) {
+		started := false
+		var deltaErr error
+		relay := onDelta
+		if onDelta != nil {
+			relay = func(delta string) error {
+				started = true
+				deltaErr = onDelta(delta)
+				return deltaErr
+			}
+		}
+		var err error
+		reply, err = r.provider.Chat(ctx, messages, tools, relay)
+		// Text that already reached the caller would be repeated by a retry.
+		return deltaErr != nil, !started, err
+	})
+	return reply, err
+}
+
+// do runs call until it succeeds, fails for good or runs out of retries. call
+// reports whether the caller aborted it, whether it may be retried and its
+// error.
+func (r retrier) do(ctx context.Context, call func() (aborted bool, retryable bool, err error)) error {
+	for attempt := 0; ; attempt++ {
+		if !r.breaker.Allow() {
+			return ErrCircuitOpen
+		}
+
+		aborted, retryable, err := call()
+		switch {
+		case err == nil:
+			r.breaker.Success()
+			return nil
+		case aborted || ctx.Err() != nil:
+			r.breaker.Release()
+			return err
+		case !temporary(err):
+			// The provider answered, so it is up; the request was at fault.
+			r.breaker.Success()
+			return err
+		}
+
+		r.breaker.Failure()
+		if !retryable || attempt >= r.maxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(retryBaseDelay << attempt):
+		}
+	}
+}
+
+func temporary(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	// Timeouts and network failures are worth another try; an empty answer
+	// will most likely be empty again.
+	return !errors.Is(err, ErrNoAnswer)
+}
@@ -0,0 +1,187 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// step is one scripted call: the deltas streamed before it ends with err.
+type step struct {
+	deltas []string
+	err    error
+}
+
+// scriptedProvider plays its steps in order, one per call.
+type scriptedProvider struct {
+	steps []step
+	calls int
+}
+
+func (p *scriptedProvider) Complete(ctx context.Context, messages []Message) (string, error) {
+	reply, err := p.Chat(ctx, messages, nil, nil)
+	return reply.Content, err
+}
+
+func (p *scriptedProvider) Stream(ctx context.Context, messages []Message, onDelta func(delta string) error) (string, error) {
+	reply, err := p.Chat(ctx, messages, nil, onDelta)
+	return reply.Content, err
+}
+
+func (p *scriptedProvider) Chat(ctx context.Context, messages []Message, tools []Tool, onDelta func(delta string) error) (Message, error) {
+	s := p.steps[p.calls]
+	p.calls++
+
+	reply := Message{Role: RoleAssistant}
+	for _, delta := range s.deltas {
+		reply.Content += delta
+		if onDelta != nil {
+			if err := onDelta(delta); err != nil {
+				return reply, err
+			}
+		}
+	}
+	return reply, s.err
+}
+
+func unavailable() error {
+	return &APIError{StatusCode: http.StatusServiceUnavailable, Message: "overloaded"}
+}
+
+func testBreaker(threshold int, cooldown time.Duration, now *time.Time) *Breaker {
+	breaker := NewBreaker(threshold, cooldown)
+	breaker.now = func() time.Time { return *now }
+	return breaker
+}
+
+func TestBreaker_OpensAfterThresholdFailures(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	breaker := testBreaker(2, time.Minute, &now)
+
+	assert.True(t, breaker.Allow())
+	breaker.Failure()
+	assert.True(t, breaker.Allow())
+	breaker.Failure()
+
+	assert.False(t, breaker.Allow())
+	now = now.Add(59 * time.Second)
+	assert.False(t, breaker.Allow())
+}
+
+func TestBreaker_LetsOneProbeThroughAfterCooldown(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	breaker := testBreaker(1, time.Minute, &now)
+	breaker.Failure()
+
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow(), "only one probe at a time")
+
+	// A failed probe opens the breaker for another cooldown.
+	breaker.Failure()
+	assert.False(t, breaker.Allow())
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.Allow())
+
+	// A successful probe closes it.
+	breaker.Success()
+	assert.True(t, breaker.Allow())
+	assert.True(t, breaker.Allow())
+}
+
+func TestBreaker_ReleaseFreesTheProbe(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	breaker := testBreaker(1, time.Minute, &now)
+	breaker.Failure()
+	now = now.Add(time.Minute)
+
+	assert.True(t, breaker.Allow())
+	breaker.Release()
+
+	// The breaker is still open, but another probe may be sent.
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow())
+}
+
+func TestBreaker_ZeroThresholdNeverOpens(t *testing.T) {
+	breaker := NewBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		breaker.Failure()
+	}
+	assert.True(t, breaker.Allow())
+}
+
+func TestResilientProvider_RetriesTemporaryFailures(t *testing.T) {
+	provider := &scriptedProvider{steps: []step{{err: unavailable()}, {deltas: []string{"Hello"}}}}
+	resilient := NewResilientProvider(provider, 1, NewBreaker(5, time.Minute))
+
+	answer, err := resilient.Complete(context.Background(), nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", answer)
+	assert.Equal(t, 2, provider.calls)
+}
+
+func TestResilientProvider_DoesNotRetryBadRequests(t *testing.T) {
+	badRequest := &APIError{StatusCode: http.StatusBadRequest, Message: "invalid"}
+	provider := &scriptedProvider{steps: []step{{err: badRequest}}}
+	breaker := NewBreaker(1, time.Minute)
+	resilient := NewResilientProvider(provider, 3, breaker)
+
+	_, err := resilient.Complete(context.Background(), nil)
+
+	assert.Equal(t, badRequest, err)
+	assert.Equal(t, 1, provider.calls)
+	assert.True(t, breaker.Allow(), "a bad request says the provider is up")
+}
+
+func TestResilientProvider_DoesNotRetryAfterTextWasStreamed(t *testing.T) {
+	provider := &scriptedProvider{steps: []step{{deltas: []string{"Use ", "a "}, err: unavailable()}}}
+	resilient := NewResilientProvider(provider, 3, NewBreaker(5, time.Minute))
+	var streamed []string
+
+	answer, err := resilient.Stream(context.Background(), nil, func(delta string) error {
+		streamed = append(streamed, delta)
+		return nil
+	})
+
+	assert.Equal(t, unavailable(), err)
+	assert.Equal(t, "Use a ", answer)
+	assert.Equal(t, []string{"Use ", "a "}, streamed)
+	assert.Equal(t, 1, provider.calls)
+}
+
+func TestResilientProvider_ReleasesTheBreakerWhenTheCallerAborts(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	breaker := testBreaker(1, time.Minute, &now)
+	breaker.Failure()
+	now = now.Add(time.Minute)
+
+	provider := &scriptedProvider{steps: []step{{deltas: []string{"Use "}}}}
+	resilient := NewResilientProvider(provider, 3, breaker)
+	aborted := errors.New("client went away")
+
+	_, err := resilient.Stream(context.Background(), nil, func(delta string) error {
+		return aborted
+	})
+
+	assert.Equal(t, aborted, err)
+	assert.Equal(t, 1, provider.calls)
+	assert.True(t, breaker.Allow(), "an aborted probe is not counted")
+}
+
+func TestResilientProvider_FailsFastWhileOpen(t *testing.T) {
+	provider := &scriptedProvider{steps: []step{{err: unavailable()}}}
+	resilient := NewResilientProvider(provider, 0, NewBreaker(1, time.Minute))
+
+	_, err := resilient.Complete(context.Background(), nil)
+	assert.Equal(t, unavailable(), err)
+
+	_, err = resilient.Complete(context.Background(), nil)
+	assert.Equal(t, ErrCircuitOpen, err)
+	assert.Equal(t, 1, provider.calls)
+}
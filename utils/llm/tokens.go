@@ -0,0 +1,43 @@
+package llm
+
+import "unicode/utf8"
+
+// Token counts are estimates: about four characters per token, which is
+// close enough for English and Indonesian text to size prompts and meter
+// usage without shipping a model specific tokenizer.
+const (
+	charsPerToken = 4
+	// messageOverhead covers the role and separators every message adds.
+	messageOverhead = 4
+	// replyOverhead covers the tokens that prime the model's answer.
+	replyOverhead = 3
+	// imageTokens is what a picture costs at high detail for a typical
+	// photo.
+	imageTokens = 765
+)
+
+// EstimateTokens estimates how many tokens text takes.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// CountTokens estimates how many tokens a prompt made of messages takes.
+func CountTokens(messages []Message) int {
+	if len(messages) == 0 {
+		return 0
+	}
+	total := replyOverhead
+	for _, message := range messages {
+		total += MessageTokens(message)
+	}
+	return total
+}
+
+// MessageTokens estimates the tokens one message adds to a prompt.
+func MessageTokens(message Message) int {
+	tokens := messageOverhead + EstimateTokens(message.Content) + imageTokens*len(message.ImageURLs)
+	for _, call := range message.ToolCalls {
+		tokens += EstimateTokens(call.Name) + EstimateTokens(call.Arguments)
+	}
+	return tokens
+}
@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"greenenvironment/configs"
+	"mime"
+	"net/smtp"
+)
+
+// Transport hands a rendered email to the mail server.
+type Transport interface {
+	Deliver(to string, subject string, htmlBody string) error
+}
+
+type smtpTransport struct {
+	config configs.SMTPConfig
+}
+
+func NewSMTPTransport(config configs.SMTPConfig) Transport {
+	return &smtpTransport{
+		config: config,
+	}
+}
+
+func (t *smtpTransport) Deliver(to string, subject string, htmlBody string) error {
+	from := t.config.Username
+
+	headers := [][2]string{
+		{"From", from},
+		{"To", to},
+		{"Subject", mime.QEncoding.Encode("UTF-8", subject)},
+		{"MIME-version", "1.0"},
+		{"Content-Type", "text/html; charset=\"UTF-8\""},
+	}
+
+	header := ""
+	for _, h := range headers {
+		header += h[0] + ": " + h[1] + "\r\n"
+	}
+
+	msg := header + "\r\n" + htmlBody
+
+	return smtp.SendMail(t.config.Host+":"+t.config.Port,
+		smtp.PlainAuth("", from, t.config.Password, t.config.Host),
+		from, []string{to}, []byte(msg))
+}
@@ -0,0 +1,163 @@
+package mail
+
+import (
+	"bufio"
+	"greenenvironment/configs"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// Message is an email received by the TestServer.
+type Message struct {
+	From string
+	To   []string
+	Data string
+}
+
+// TestServer is a local SMTP stand-in for tests. It accepts any login and
+// keeps every message in memory instead of delivering it. Reject(true) makes
+// it refuse messages, to exercise retries.
+type TestServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	messages []Message
+	reject   bool
+	wg       sync.WaitGroup
+}
+
+func NewTestServer() (*TestServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	server := &TestServer{listener: listener}
+	server.wg.Add(1)
+	go server.serve()
+	return server, nil
+}
+
+// Config returns SMTP settings that point at the server. net/smtp only
+// allows plain auth without TLS against localhost, which this is.
+func (s *TestServer) Config() configs.SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return configs.SMTPConfig{
+		Host:     host,
+		Port:     port,
+		Username: "ecomate@localhost",
+		Password: "secret",
+	}
+}
+
+func (s *TestServer) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+func (s *TestServer) Reject(reject bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reject = reject
+}
+
+func (s *TestServer) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *TestServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *TestServer) handle(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+
+	var message Message
+	text.PrintfLine("220 localhost ESMTP test server")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			text.PrintfLine("250-localhost")
+			text.PrintfLine("250 AUTH PLAIN")
+		case strings.HasPrefix(command, "AUTH"):
+			text.PrintfLine("235 2.7.0 Authentication successful")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			message = Message{From: address(line[len("MAIL FROM:"):])}
+			text.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			message.To = append(message.To, address(line[len("RCPT TO:"):]))
+			text.PrintfLine("250 OK")
+		case command == "DATA":
+			text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := readData(text.R)
+			if err != nil {
+				return
+			}
+			message.Data = data
+
+			s.mu.Lock()
+			reject := s.reject
+			if !reject {
+				s.messages = append(s.messages, message)
+			}
+			s.mu.Unlock()
+
+			if reject {
+				text.PrintfLine("554 Transaction failed")
+			} else {
+				text.PrintfLine("250 OK")
+			}
+		case command == "RSET", command == "NOOP":
+			text.PrintfLine("250 OK")
+		case command == "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func address(value string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.IndexByte(value, ' '); i >= 0 {
+		value = value[:i]
+	}
+	return strings.Trim(value, "<>")
+}
+
+func readData(r *bufio.Reader) (string, error) {
+	var data strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		if line == ".\r\n" || line == ".\n" {
+			return data.String(), nil
+		}
+		data.WriteString(strings.TrimPrefix(line, "."))
+	}
+}
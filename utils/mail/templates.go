@@ -0,0 +1,95 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+)
+
+const (
+	TemplateOTP                = "otp"
+	TemplateNotification       = "notification"
+	TemplateWelcome            = "welcome"
+	TemplatePasswordChanged    = "password_changed"
+	TemplateOrderConfirmation  = "order_confirmation"
+	TemplatePaymentReceived    = "payment_received"
+	TemplateShipment           = "shipment"
+	TemplateChallengeCompleted = "challenge_completed"
+)
+
+const DefaultLanguage = "en"
+
+// Languages lists every language with a full set of templates.
+var Languages = []string{"en", "id"}
+
+// OrderItem is one line of the order confirmation email.
+type OrderItem struct {
+	Name     string
+	Quantity int
+}
+
+//go:embed templates
+var templateFiles embed.FS
+
+// templates holds every template, keyed by language and then by name.
+var templates = mustParseTemplates()
+
+func mustParseTemplates() map[string]map[string]*template.Template {
+	layout := template.Must(template.ParseFS(templateFiles, "templates/layout.html"))
+
+	parsed := make(map[string]map[string]*template.Template, len(Languages))
+	for _, language := range Languages {
+		files, err := templateFiles.ReadDir("templates/" + language)
+		if err != nil {
+			panic(err)
+		}
+
+		parsed[language] = make(map[string]*template.Template, len(files))
+		for _, file := range files {
+			name := strings.TrimSuffix(file.Name(), ".html")
+			base := template.Must(layout.Clone())
+			parsed[language][name] = template.Must(base.ParseFS(templateFiles, "templates/"+language+"/"+file.Name()))
+		}
+	}
+	return parsed
+}
+
+func IsSupportedLanguage(language string) bool {
+	_, ok := templates[language]
+	return ok
+}
+
+// Render builds the subject and HTML body of a template. Unknown languages
+// fall back to English.
+func Render(name, language string, data map[string]interface{}) (string, string, error) {
+	if !IsSupportedLanguage(language) {
+		language = DefaultLanguage
+	}
+
+	tmpl, ok := templates[language][name]
+	if !ok {
+		return "", "", fmt.Errorf("mail: unknown template %q", name)
+	}
+
+	values := make(map[string]interface{}, len(data)+1)
+	for key, value := range data {
+		values[key] = value
+	}
+	values["Lang"] = language
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", values); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.ExecuteTemplate(&body, "layout.html", values); err != nil {
+		return "", "", err
+	}
+
+	// The subject is a header, not HTML, so undo the escaping html/template
+	// applied and keep it on one line.
+	plainSubject := strings.Join(strings.Fields(html.UnescapeString(subject.String())), " ")
+	return plainSubject, body.String(), nil
+}
@@ -0,0 +1,118 @@
+package realtime
+
+import "sync"
+
+const (
+	EventTransactionStatus = "transaction.status"
+	EventForumMessage      = "forum.message"
+	EventNotification      = "notification"
+)
+
+// subscriptionBuffer is how many events a subscriber may fall behind before
+// new events for it are dropped.
+const subscriptionBuffer = 32
+
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Publisher is the side of the broker services use to push events.
+type Publisher interface {
+	Publish(topic string, event Event)
+}
+
+// Broker fans events out to subscribers by topic. The in-process broker only
+// reaches clients connected to the same instance; running several instances
+// needs a Broker backed by a shared pub/sub such as Redis.
+type Broker interface {
+	Publisher
+	Subscribe(topics ...string) Subscription
+}
+
+type Subscription interface {
+	Events() <-chan Event
+	Close()
+}
+
+// BroadcastTopic reaches every open stream.
+const BroadcastTopic = "broadcast"
+
+func UserTopic(userID string) string {
+	return "user:" + userID
+}
+
+func ForumTopic(forumID string) string {
+	return "forum:" + forumID
+}
+
+type memoryBroker struct {
+	mu     sync.RWMutex
+	topics map[string]map[*memorySubscription]struct{}
+}
+
+func NewMemoryBroker() Broker {
+	return &memoryBroker{
+		topics: make(map[string]map[*memorySubscription]struct{}),
+	}
+}
+
+func (b *memoryBroker) Publish(topic string, event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for subscription := range b.topics[topic] {
+		// A slow client must not hold up the publisher, so it misses the event instead.
+		select {
+		case subscription.events <- event:
+		default:
+		}
+	}
+}
+
+func (b *memoryBroker) Subscribe(topics ...string) Subscription {
+	subscription := &memorySubscription{
+		broker: b,
+		topics: topics,
+		events: make(chan Event, subscriptionBuffer),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, topic := range topics {
+		if b.topics[topic] == nil {
+			b.topics[topic] = make(map[*memorySubscription]struct{})
+		}
+		b.topics[topic][subscription] = struct{}{}
+	}
+	return subscription
+}
+
+func (b *memoryBroker) unsubscribe(subscription *memorySubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, topic := range subscription.topics {
+		delete(b.topics[topic], subscription)
+		if len(b.topics[topic]) == 0 {
+			delete(b.topics, topic)
+		}
+	}
+	close(subscription.events)
+}
+
+type memorySubscription struct {
+	broker *memoryBroker
+	topics []string
+	events chan Event
+	once   sync.Once
+}
+
+func (s *memorySubscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *memorySubscription) Close() {
+	s.once.Do(func() {
+		s.broker.unsubscribe(s)
+	})
+}
@@ -0,0 +1,101 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// receive waits briefly for the next event of a subscription.
+func receive(t *testing.T, subscription Subscription) (Event, bool) {
+	t.Helper()
+	select {
+	case event, ok := <-subscription.Events():
+		return event, ok
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return Event{}, false
+	}
+}
+
+func assertNoEvent(t *testing.T, subscription Subscription) {
+	t.Helper()
+	select {
+	case event := <-subscription.Events():
+		t.Fatalf("unexpected event %v", event)
+	default:
+	}
+}
+
+func TestMemoryBroker_FansOutByTopic(t *testing.T) {
+	broker := NewMemoryBroker()
+	first := broker.Subscribe(UserTopic("user1"), BroadcastTopic)
+	second := broker.Subscribe(UserTopic("user1"))
+	other := broker.Subscribe(UserTopic("user2"))
+	defer first.Close()
+	defer second.Close()
+	defer other.Close()
+
+	broker.Publish(UserTopic("user1"), Event{Type: EventNotification, Data: "hello"})
+
+	for _, subscription := range []Subscription{first, second} {
+		event, ok := receive(t, subscription)
+		assert.True(t, ok)
+		assert.Equal(t, Event{Type: EventNotification, Data: "hello"}, event)
+	}
+	assertNoEvent(t, other)
+
+	broker.Publish(BroadcastTopic, Event{Type: EventNotification, Data: "everyone"})
+
+	event, _ := receive(t, first)
+	assert.Equal(t, "everyone", event.Data)
+	assertNoEvent(t, second)
+	assertNoEvent(t, other)
+}
+
+func TestMemoryBroker_Unsubscribe(t *testing.T) {
+	broker := NewMemoryBroker()
+	subscription := broker.Subscribe(ForumTopic("forum1"), UserTopic("user1"))
+	staying := broker.Subscribe(ForumTopic("forum1"))
+	defer staying.Close()
+
+	subscription.Close()
+	subscription.Close()
+
+	_, ok := <-subscription.Events()
+	assert.False(t, ok)
+
+	memory := broker.(*memoryBroker)
+	assert.NotContains(t, memory.topics, UserTopic("user1"))
+	assert.Len(t, memory.topics[ForumTopic("forum1")], 1)
+
+	broker.Publish(ForumTopic("forum1"), Event{Type: EventForumMessage})
+	event, ok := receive(t, staying)
+	assert.True(t, ok)
+	assert.Equal(t, EventForumMessage, event.Type)
+}
+
+func TestMemoryBroker_DropsEventsForSlowSubscribers(t *testing.T) {
+	broker := NewMemoryBroker()
+	subscription := broker.Subscribe(UserTopic("user1"))
+	defer subscription.Close()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < subscriptionBuffer+10; i++ {
+			broker.Publish(UserTopic("user1"), Event{Type: EventTransactionStatus, Data: i})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publishing blocked on a full subscriber")
+	}
+
+	assert.Len(t, subscription.Events(), subscriptionBuffer)
+	event, _ := receive(t, subscription)
+	assert.Equal(t, 0, event.Data)
+}
@@ -0,0 +1,135 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"greenenvironment/constant"
+	"greenenvironment/helper"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxForumSubscriptions caps how many forums one stream may follow.
+const maxForumSubscriptions = 20
+
+// keepAliveInterval keeps idle streams from being closed by proxies.
+const keepAliveInterval = 25 * time.Second
+
+type RealtimeInterface interface {
+	Stream(c echo.Context) error
+}
+
+type realtime struct {
+	broker Broker
+	jwt    helper.JWTInterface
+}
+
+func NewRealtime(broker Broker, jwt helper.JWTInterface) RealtimeInterface {
+	return &realtime{
+		broker: broker,
+		jwt:    jwt,
+	}
+}
+
+// userID authenticates the stream. Browsers cannot set headers on an
+// EventSource, so the token may also be passed in the token query parameter.
+func (r *realtime) userID(c echo.Context) (string, bool) {
+	tokenString := c.Request().Header.Get(constant.HeaderAuthorization)
+	if tokenString == "" && c.QueryParam("token") != "" {
+		tokenString = "Bearer " + c.QueryParam("token")
+	}
+	if tokenString == "" {
+		return "", false
+	}
+
+	token, err := r.jwt.ValidateToken(tokenString)
+	if err != nil {
+		return "", false
+	}
+
+	userData := r.jwt.ExtractUserToken(token)
+	userID, ok := userData[constant.JWT_ID].(string)
+	return userID, ok && userID != ""
+}
+
+func forumIDs(c echo.Context) []string {
+	var ids []string
+	for _, value := range c.QueryParams()["forum"] {
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// Stream Events
+// @Summary      Stream real-time events
+// @Description  Open a Server-Sent Events stream with announcements and the authenticated user's transaction status changes and notifications, plus new messages of the forums passed in forum.
+// @Tags         Realtime
+// @Produce      text/event-stream
+// @Param        Authorization  header    string  false  "Bearer Token"
+// @Param        token          query     string  false  "JWT, for clients that cannot set headers"
+// @Param        forum          query     string  false  "Forum IDs to follow, comma separated"
+// @Success      200  {string}  string "Event stream"
+// @Failure      400  {object}  helper.Response{data=string} "Too many forums"
+// @Failure      401  {object}  helper.Response{data=string} "Unauthorized"
+// @Router       /realtime/stream [get]
+func (r *realtime) Stream(c echo.Context) error {
+	userID, ok := r.userID(c)
+	if !ok {
+		return helper.UnauthorizedError(c)
+	}
+
+	forums := forumIDs(c)
+	if len(forums) > maxForumSubscriptions {
+		return c.JSON(http.StatusBadRequest, helper.FormatResponse(false, fmt.Sprintf("a stream can follow at most %d forums", maxForumSubscriptions), nil))
+	}
+
+	topics := []string{BroadcastTopic, UserTopic(userID)}
+	for _, forumID := range forums {
+		topics = append(topics, ForumTopic(forumID))
+	}
+
+	subscription := r.broker.Subscribe(topics...)
+	defer subscription.Close()
+
+	response := c.Response()
+	response.Header().Set(echo.HeaderContentType, "text/event-stream")
+	response.Header().Set("Cache-Control", "no-cache")
+	response.Header().Set("Connection", "keep-alive")
+	response.Header().Set("X-Accel-Buffering", "no")
+	response.WriteHeader(http.StatusOK)
+	response.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(response, ": ping\n\n"); err != nil {
+				return nil
+			}
+			response.Flush()
+		case event, open := <-subscription.Events():
+			if !open {
+				return nil
+			}
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(response, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return nil
+			}
+			response.Flush()
+		}
+	}
+}
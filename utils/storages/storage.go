@@ -7,6 +7,8 @@ import (
 	"greenenvironment/helper"
 	"mime/multipart"
 	"net/http"
+	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 type StorageInterface interface {
 	ImageValidation(files *multipart.FileHeader) (multipart.File, error)
 	UploadImageToCloudinary(file interface{}, folderPath string) (string, error)
+	DeleteImageFromCloudinary(imageURL string) error
 	UploadFileHandler(c echo.Context) error
 }
 
@@ -71,6 +74,45 @@ func (s *storage) UploadImageToCloudinary(file interface{}, folderPath string) (
 	return resp.SecureURL, nil
 }
 
+// DeleteImageFromCloudinary removes an uploaded image by the URL it was
+// uploaded to. URLs that do not point to Cloudinary are ignored.
+func (s *storage) DeleteImageFromCloudinary(imageURL string) error {
+	publicID := cloudinaryPublicID(imageURL)
+	if publicID == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cld, err := cloudinary.NewFromParams(s.conf.CloudName, s.conf.ApiKeyStorage, s.conf.ApiSecretStorage)
+	if err != nil {
+		return err
+	}
+
+	_, err = cld.Upload.Destroy(ctx, uploader.DestroyParams{PublicID: publicID})
+	return err
+}
+
+// cloudinaryPublicID takes the public ID out of a delivery URL such as
+// https://res.cloudinary.com/demo/image/upload/v1712345678/ecomate/avatars/abc.jpg,
+// which is the path after the version without the extension.
+func cloudinaryPublicID(imageURL string) string {
+	if !strings.Contains(imageURL, "res.cloudinary.com/") {
+		return ""
+	}
+	_, publicID, found := strings.Cut(imageURL, "/upload/")
+	if !found {
+		return ""
+	}
+	if version, rest, found := strings.Cut(publicID, "/"); found && strings.HasPrefix(version, "v") {
+		if _, err := strconv.Atoi(version[1:]); err == nil {
+			publicID = rest
+		}
+	}
+	return strings.TrimSuffix(publicID, path.Ext(publicID))
+}
+
 // Upload Image
 // @Summary      Upload Image
 // @Description  Upload an image to Cloudinary and return the image URL
@@ -0,0 +1,98 @@
+package vectorstore
+
+import (
+	"sort"
+	"sync"
+)
+
+// Match is a stored vector found by Search and its cosine similarity to the
+// query, from -1 to 1.
+type Match struct {
+	ID    string
+	Score float64
+}
+
+type entry struct {
+	id     string
+	group  string
+	vector []float32
+}
+
+// Store keeps vectors in memory and finds the ones closest to a query by
+// brute force, which is plenty for a few thousand entries. Vectors are
+// expected to be normalized, so the dot product is the cosine similarity.
+// Entries belong to a group, such as the document they were cut from, so
+// they can be removed together.
+type Store struct {
+	mu      sync.RWMutex
+	entries []entry
+}
+
+func New() *Store {
+	return &Store{}
+}
+
+// Add stores vector under id, replacing an earlier vector with the same id.
+func (s *Store) Add(id string, group string, vector []float32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.entries {
+		if s.entries[i].id == id {
+			s.entries[i] = entry{id: id, group: group, vector: vector}
+			return
+		}
+	}
+	s.entries = append(s.entries, entry{id: id, group: group, vector: vector})
+}
+
+func (s *Store) RemoveGroup(group string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.entries[:0]
+	for _, e := range s.entries {
+		if e.group != group {
+			kept = append(kept, e)
+		}
+	}
+	for i := len(kept); i < len(s.entries); i++ {
+		s.entries[i] = entry{}
+	}
+	s.entries = kept
+}
+
+// Search returns at most k entries with a score of at least minScore, best
+// first. Vectors of a different length than the query are skipped.
+func (s *Store) Search(vector []float32, k int, minScore float64) []Match {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []Match
+	for _, e := range s.entries {
+		if len(e.vector) != len(vector) {
+			continue
+		}
+		var score float64
+		for i := range vector {
+			score += float64(vector[i]) * float64(e.vector[i])
+		}
+		if score >= minScore {
+			matches = append(matches, Match{ID: e.id, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if k >= 0 && len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches
+}
+
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.entries)
+}